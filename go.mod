@@ -0,0 +1,18 @@
+module github.com/OpenBanking-Brasil/OFB-CI-CD
+
+go 1.26.0
+
+require (
+	github.com/pb33f/libopenapi v0.25.9
+	golang.org/x/text v0.42.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/bahlo/generic-list-go v0.2.0 // indirect
+	github.com/buger/jsonparser v1.1.1 // indirect
+	github.com/kr/pretty v0.3.1 // indirect
+	github.com/pb33f/ordered-map/v2 v2.3.0 // indirect
+	github.com/speakeasy-api/jsonpath v0.6.2 // indirect
+	go.yaml.in/yaml/v4 v4.0.0-rc.2 // indirect
+)
@@ -0,0 +1,425 @@
+package validator
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Flags do subcomando aggregate
+type aggregateOptions struct {
+	markdown string
+	json     string
+	previous string
+}
+
+func (o *aggregateOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.markdown, "markdown", "", "arquivo do relatório da frota em Markdown (padrão: saída padrão, quando --json não é informado)")
+	fs.StringVar(&o.json, "json", "", "arquivo do relatório da frota em JSON, usado como --previous na próxima execução")
+	fs.StringVar(&o.previous, "previous", "", "relatório da frota anterior (JSON de aggregate), para mostrar a tendência")
+	registerEncodingFlag(fs)
+}
+
+var aggregateCommand = &command{
+	Name:    "aggregate",
+	Args:    "<relatório.json>...",
+	Summary: "combina relatórios JSON de várias execuções em um relatório da frota",
+	Description: `Combina os relatórios JSON das execuções de cada repositório (validate
+--format json ou o --report de um manifesto) em um relatório único da frota:
+resultado de cada API (aprovada com zero erros, reprovada ou com falha na
+execução), totais de erros e avisos e as violações de cada regra somadas em
+todas as APIs, com a quantidade de APIs em que a regra aparece.
+
+Cada API é identificada pelo nome do arquivo do relatório (contas.json vira
+contas) e, nos relatórios de manifesto, também pelo nome da API no manifesto
+(plataforma/accounts). Com --previous, o relatório da frota anterior (o JSON
+gravado por --json) é comparado com o atual: cada número ganha a diferença e
+as APIs que entraram ou saíram da frota são listadas.
+
+Os relatórios trazem a versão do formato em schemaVersion (os gerados antes
+do campo contam como versão 1). Relatórios com versões diferentes entre si ou
+diferentes da versão desta ferramenta não são somados: a execução falha
+listando a versão de cada arquivo.`,
+	Examples: []string{
+		programName + " aggregate relatorios/*.json",
+		programName + " aggregate --markdown frota.md --json frota.json relatorios/*.json",
+		programName + " aggregate --previous frota-ontem.json --json frota.json --markdown frota.md relatorios/*.json",
+	},
+	Flags: func(fs *flag.FlagSet) { new(aggregateOptions).register(fs) },
+	Run:   runAggregate,
+}
+
+// Relatório da frota
+type fleetReport struct {
+	SchemaVersion int         `json:"schemaVersion"`
+	Reports       []string    `json:"reports"`
+	Passed        int         `json:"passed"`
+	Failed        int         `json:"failed"`
+	Errored       int         `json:"errored"`
+	Errors        int         `json:"errors"`
+	Warnings      int         `json:"warnings"`
+	APIs          []fleetAPI  `json:"apis"`
+	Rules         []fleetRule `json:"rules"`
+	Trend         *fleetTrend `json:"trend,omitempty"` // com --previous
+}
+
+// Resultado de uma API na frota
+type fleetAPI struct {
+	Name     string `json:"name"`
+	Report   string `json:"report"`
+	Spec     string `json:"spec,omitempty"`
+	Status   string `json:"status"` // passed, failed ou error
+	Error    string `json:"error,omitempty"`
+	Errors   int    `json:"errors"`
+	Warnings int    `json:"warnings"`
+
+	PreviousStatus string `json:"previousStatus,omitempty"` // com --previous; vazio para APIs novas
+	ErrorsDelta    int    `json:"errorsDelta,omitempty"`
+	WarningsDelta  int    `json:"warningsDelta,omitempty"`
+}
+
+// Violações de uma regra somadas na frota
+type fleetRule struct {
+	Rule       string         `json:"rule"`
+	Violations int            `json:"violations"`
+	Severities map[string]int `json:"severities"`
+	APIs       int            `json:"apis"` // APIs com pelo menos uma violação da regra
+	Delta      int            `json:"delta,omitempty"`
+}
+
+// Diferenças em relação ao relatório da frota anterior
+type fleetTrend struct {
+	Previous      string   `json:"previous"`
+	PassedDelta   int      `json:"passedDelta"`
+	FailedDelta   int      `json:"failedDelta"`
+	ErroredDelta  int      `json:"erroredDelta"`
+	ErrorsDelta   int      `json:"errorsDelta"`
+	WarningsDelta int      `json:"warningsDelta"`
+	NewAPIs       []string `json:"newApis"`
+	RemovedAPIs   []string `json:"removedApis"`
+}
+
+// Relatório de entrada, em qualquer um dos dois formatos: o de uma especificação
+// (validation) ou o de um manifesto (apis)
+type aggregateInput struct {
+	SchemaVersion *int              `json:"schemaVersion"`
+	Validation    *validationReport `json:"validation"`
+	APIs          []apiReport       `json:"apis"`
+}
+
+// Subcomando aggregate: relatório da frota a partir dos relatórios das execuções
+func runAggregate(c *command, args []string) int {
+	opts := &aggregateOptions{}
+	fs := c.newFlagSet()
+	opts.register(fs)
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	if err := checkEncodingFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if fs.NArg() == 0 {
+		return c.usageError("esperado pelo menos um relatório JSON")
+	}
+
+	// Ler todos os relatórios antes de somar, para relatar de uma vez as versões incompatíveis
+	inputs := make([]*aggregateInput, 0, fs.NArg())
+	versions := map[int][]string{}
+	for _, file := range fs.Args() {
+		input, err := readAggregateInput(file)
+		if err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+		version := 1
+		if input.SchemaVersion != nil {
+			version = *input.SchemaVersion
+		}
+		versions[version] = append(versions[version], file)
+		inputs = append(inputs, input)
+	}
+	if _, ok := versions[reportSchemaVersion]; len(versions) > 1 || !ok {
+		fmt.Fprintf(stdout, "❌ Os relatórios usam versões de formato diferentes (esta ferramenta usa a versão %d); nada foi somado:\n", reportSchemaVersion)
+		printVersionGroups(versions)
+		fmt.Fprintln(stdout, "💡 Gere os relatórios de novo com a mesma versão da ferramenta.")
+		return exitFailure
+	}
+
+	fleet := aggregateReports(fs.Args(), inputs)
+	if opts.previous != "" {
+		previous, err := readFleetReport(opts.previous)
+		if err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+		if previous.SchemaVersion != reportSchemaVersion {
+			fmt.Fprintf(stdout, "❌ O relatório anterior %s usa a versão de formato %d e esta ferramenta usa a versão %d; a tendência não pode ser calculada.\n", opts.previous, previous.SchemaVersion, reportSchemaVersion)
+			return exitFailure
+		}
+		fleet.compare(opts.previous, previous)
+	}
+
+	if opts.json != "" {
+		if err := writeJSONReport(opts.json, fleet); err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+	}
+	markdown := fleet.markdown()
+	switch {
+	case opts.markdown != "":
+		if err := os.MkdirAll(filepath.Dir(opts.markdown), 0755); err != nil {
+			fmt.Fprintf(stdout, "❌ Erro ao criar diretório de %s: %v\n", opts.markdown, err)
+			return exitFailure
+		}
+		if err := writeFileAtomic(opts.markdown, []byte(markdown)); err != nil {
+			fmt.Fprintf(stdout, "❌ Erro ao salvar relatório %s: %v\n", opts.markdown, err)
+			return exitFailure
+		}
+	case opts.json == "":
+		fmt.Fprint(stdout, markdown)
+		return exitOK
+	}
+	fmt.Fprintf(stdout, "🔎 Frota: %d API(s) de %d relatório(s): %d aprovada(s), %d reprovada(s), %d com falha; %d erros, %d avisos.\n", len(fleet.APIs), len(fleet.Reports), fleet.Passed, fleet.Failed, fleet.Errored, fleet.Errors, fleet.Warnings)
+	for _, file := range []string{opts.markdown, opts.json} {
+		if file != "" {
+			fmt.Fprintln(stdout, "✅ Relatório da frota salvo em", file)
+		}
+	}
+	return exitOK
+}
+
+func printVersionGroups(versions map[int][]string) {
+	keys := make([]int, 0, len(versions))
+	for version := range versions {
+		keys = append(keys, version)
+	}
+	sort.Ints(keys)
+	for _, version := range keys {
+		fmt.Fprintf(stdout, "   - versão %d: %s\n", version, strings.Join(versions[version], ", "))
+	}
+}
+
+// Função para ler um relatório de entrada; um JSON que não é relatório de validação
+// (ex.: o de resolve) é um erro, para não entrar nos totais como uma API sem violações
+func readAggregateInput(file string) (*aggregateInput, error) {
+	data, err := readFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var input aggregateInput
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, fmt.Errorf("%s não é um relatório JSON válido: %v", file, err)
+	}
+	if input.Validation == nil && input.APIs == nil {
+		return nil, fmt.Errorf("%s não é um relatório de validação (esperado o JSON de validate --format json ou o --report de um manifesto)", file)
+	}
+	return &input, nil
+}
+
+func readFleetReport(file string) (*fleetReport, error) {
+	data, err := readFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var report fleetReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("%s não é um relatório da frota válido: %v", file, err)
+	}
+	if report.APIs == nil {
+		return nil, fmt.Errorf("%s não é um relatório da frota (esperado o JSON gravado por aggregate --json)", file)
+	}
+	return &report, nil
+}
+
+// Função para somar os relatórios: uma entrada por API, na ordem dos nomes, e as
+// regras das mais para as menos violadas
+func aggregateReports(files []string, inputs []*aggregateInput) *fleetReport {
+	fleet := &fleetReport{SchemaVersion: reportSchemaVersion, Reports: []string{}, APIs: []fleetAPI{}, Rules: []fleetRule{}}
+	rules := map[string]*fleetRule{}
+	add := func(api fleetAPI, validation *validationReport) {
+		if validation != nil {
+			api.Spec = validation.File
+			api.Errors, api.Warnings = validation.Errors, validation.Warnings
+			api.Status = "passed"
+			if api.Errors > 0 {
+				api.Status = "failed"
+			}
+			seen := map[string]bool{}
+			for _, v := range validation.Violations {
+				id := v.RuleID
+				if id == "" {
+					id = "(sem regra)"
+				}
+				rule := rules[id]
+				if rule == nil {
+					rule = &fleetRule{Rule: id, Severities: map[string]int{}}
+					rules[id] = rule
+				}
+				rule.Violations++
+				rule.Severities[v.Severity]++
+				if !seen[id] {
+					seen[id] = true
+					rule.APIs++
+				}
+			}
+		}
+		switch api.Status {
+		case "passed":
+			fleet.Passed++
+		case "failed":
+			fleet.Failed++
+		default:
+			api.Status = "error"
+			fleet.Errored++
+		}
+		fleet.Errors += api.Errors
+		fleet.Warnings += api.Warnings
+		fleet.APIs = append(fleet.APIs, api)
+	}
+	for i, input := range inputs {
+		file := reportPath(files[i])
+		fleet.Reports = append(fleet.Reports, file)
+		base := strings.TrimSuffix(filepath.Base(files[i]), filepath.Ext(files[i]))
+		if input.Validation != nil {
+			add(fleetAPI{Name: base, Report: file}, input.Validation)
+			continue
+		}
+		for _, api := range input.APIs {
+			add(fleetAPI{Name: base + "/" + api.Name, Report: file, Spec: api.Spec, Status: api.Status, Error: api.Error}, api.Validation)
+		}
+	}
+	sort.SliceStable(fleet.APIs, func(i, j int) bool { return fleet.APIs[i].Name < fleet.APIs[j].Name })
+	for _, rule := range rules {
+		fleet.Rules = append(fleet.Rules, *rule)
+	}
+	sortFleetRules(fleet.Rules)
+	return fleet
+}
+
+func sortFleetRules(rules []fleetRule) {
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Violations != rules[j].Violations {
+			return rules[i].Violations > rules[j].Violations
+		}
+		return rules[i].Rule < rules[j].Rule
+	})
+}
+
+// Função para calcular a tendência em relação ao relatório anterior: diferenças dos
+// totais, de cada API e de cada regra (as regras que zeraram entram com zero violações)
+func (f *fleetReport) compare(file string, previous *fleetReport) {
+	trend := &fleetTrend{
+		Previous:      reportPath(file),
+		PassedDelta:   f.Passed - previous.Passed,
+		FailedDelta:   f.Failed - previous.Failed,
+		ErroredDelta:  f.Errored - previous.Errored,
+		ErrorsDelta:   f.Errors - previous.Errors,
+		WarningsDelta: f.Warnings - previous.Warnings,
+		NewAPIs:       []string{},
+		RemovedAPIs:   []string{},
+	}
+	before := map[string]fleetAPI{}
+	for _, api := range previous.APIs {
+		before[api.Name] = api
+	}
+	current := map[string]bool{}
+	for i := range f.APIs {
+		api := &f.APIs[i]
+		current[api.Name] = true
+		old, ok := before[api.Name]
+		if !ok {
+			trend.NewAPIs = append(trend.NewAPIs, api.Name)
+			continue
+		}
+		api.PreviousStatus = old.Status
+		api.ErrorsDelta, api.WarningsDelta = api.Errors-old.Errors, api.Warnings-old.Warnings
+	}
+	for _, api := range previous.APIs {
+		if !current[api.Name] {
+			trend.RemovedAPIs = append(trend.RemovedAPIs, api.Name)
+		}
+	}
+	sort.Strings(trend.RemovedAPIs)
+
+	counts := map[string]int{}
+	for _, rule := range previous.Rules {
+		counts[rule.Rule] = rule.Violations
+	}
+	present := map[string]bool{}
+	for i := range f.Rules {
+		present[f.Rules[i].Rule] = true
+		f.Rules[i].Delta = f.Rules[i].Violations - counts[f.Rules[i].Rule]
+	}
+	for _, old := range previous.Rules {
+		if !present[old.Rule] {
+			f.Rules = append(f.Rules, fleetRule{Rule: old.Rule, Severities: map[string]int{}, Delta: -old.Violations})
+		}
+	}
+	sortFleetRules(f.Rules)
+	f.Trend = trend
+}
+
+// Relatório da frota em Markdown
+func (f *fleetReport) markdown() string {
+	withTrend := f.Trend != nil
+	delta := func(n int) string {
+		if !withTrend || n == 0 {
+			return ""
+		}
+		return fmt.Sprintf(" (%+d)", n)
+	}
+	statusLabel := map[string]string{"passed": "✅ aprovada", "failed": "❌ reprovada", "error": "💥 falha"}
+
+	var b strings.Builder
+	b.WriteString("# Relatório de governança da frota\n\n")
+	fmt.Fprintf(&b, "%d API(s) em %d relatório(s).\n\n", len(f.APIs), len(f.Reports))
+	b.WriteString("| Aprovadas | Reprovadas | Com falha | Erros | Avisos |\n|---:|---:|---:|---:|---:|\n")
+	if withTrend {
+		t := f.Trend
+		fmt.Fprintf(&b, "| %d%s | %d%s | %d%s | %d%s | %d%s |\n", f.Passed, delta(t.PassedDelta), f.Failed, delta(t.FailedDelta), f.Errored, delta(t.ErroredDelta), f.Errors, delta(t.ErrorsDelta), f.Warnings, delta(t.WarningsDelta))
+		fmt.Fprintf(&b, "\nComparado com %s.\n", t.Previous)
+		if len(t.NewAPIs) > 0 {
+			fmt.Fprintf(&b, "\nAPIs novas: %s.\n", "`"+strings.Join(t.NewAPIs, "`, `")+"`")
+		}
+		if len(t.RemovedAPIs) > 0 {
+			fmt.Fprintf(&b, "\nAPIs que saíram da frota: %s.\n", "`"+strings.Join(t.RemovedAPIs, "`, `")+"`")
+		}
+	} else {
+		fmt.Fprintf(&b, "| %d | %d | %d | %d | %d |\n", f.Passed, f.Failed, f.Errored, f.Errors, f.Warnings)
+	}
+
+	b.WriteString("\n## APIs\n\n| API | Especificação | Resultado | Erros | Avisos |\n|---|---|---|---:|---:|\n")
+	for _, api := range f.APIs {
+		status := statusLabel[api.Status]
+		if api.Error != "" {
+			status += ": " + markdownCell(api.Error)
+		}
+		if withTrend && api.PreviousStatus != "" && api.PreviousStatus != api.Status {
+			status += " (antes: " + statusLabel[api.PreviousStatus] + ")"
+		}
+		spec := "-"
+		if api.Spec != "" {
+			spec = "`" + api.Spec + "`"
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %d%s | %d%s |\n", api.Name, spec, status, api.Errors, delta(api.ErrorsDelta), api.Warnings, delta(api.WarningsDelta))
+	}
+
+	if len(f.Rules) > 0 {
+		b.WriteString("\n## Violações por regra\n\n| Regra | Violações | APIs | Severidades |\n|---|---:|---:|---|\n")
+		for _, rule := range f.Rules {
+			severities := make([]string, 0, len(rule.Severities))
+			for _, severity := range []string{"error", "warning", "info", "hint"} {
+				if n := rule.Severities[severity]; n > 0 {
+					severities = append(severities, fmt.Sprintf("%s: %d", severity, n))
+				}
+			}
+			fmt.Fprintf(&b, "| `%s` | %d%s | %d | %s |\n", rule.Rule, rule.Violations, delta(rule.Delta), rule.APIs, strings.Join(severities, ", "))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,114 @@
+package validator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Função para gravar os relatórios no diretório e executar o aggregate com --json,
+// retornando o código, a saída e o relatório da frota gravado
+func runAggregateCommand(t *testing.T, dir string, reports map[string]string, args ...string) (int, string, *fleetReport) {
+	t.Helper()
+	writeFiles(t, dir, reports)
+	output := filepath.Join(dir, "frota.json")
+	os.Remove(output)
+	args = append([]string{"aggregate", "--json", output}, args...)
+	names := make([]string, 0, len(reports))
+	for name := range reports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, filepath.Join(dir, name))
+	}
+	code, out := runCommand(t, args...)
+	data, err := os.ReadFile(output)
+	if err != nil {
+		return code, out, nil
+	}
+	var fleet fleetReport
+	if err := json.Unmarshal(data, &fleet); err != nil {
+		t.Fatal(err)
+	}
+	return code, out, &fleet
+}
+
+const (
+	aggregateContas     = `{"schemaVersion": 1, "validation": {"file": "contas.yaml", "errors": 2, "warnings": 1, "violations": [{"ruleId": "a", "severity": "error"}, {"ruleId": "a", "severity": "error"}, {"ruleId": "b", "severity": "warning"}]}}`
+	aggregatePlataforma = `{"schemaVersion": 1, "apis": [{"name": "pix", "spec": "pix.yaml", "status": "passed", "validation": {"file": "pix.yaml", "warnings": 1, "violations": [{"ruleId": "b", "severity": "warning"}]}}, {"name": "cartoes", "spec": "cartoes.yaml", "status": "error", "error": "arquivo não encontrado"}]}`
+)
+
+// Relatórios de especificação e de manifesto são somados por API e por regra
+func TestAggregate(t *testing.T) {
+	dir := t.TempDir()
+	code, out, fleet := runAggregateCommand(t, dir, map[string]string{"contas.json": aggregateContas, "plataforma.json": aggregatePlataforma})
+	if code != exitOK || fleet == nil {
+		t.Fatalf("código %d\n%s", code, out)
+	}
+	if fleet.Passed != 1 || fleet.Failed != 1 || fleet.Errored != 1 || fleet.Errors != 2 || fleet.Warnings != 2 {
+		t.Errorf("totais: %+v", fleet)
+	}
+	var names []string
+	for _, api := range fleet.APIs {
+		names = append(names, api.Name+":"+api.Status)
+	}
+	if strings.Join(names, " ") != "contas:failed plataforma/cartoes:error plataforma/pix:passed" {
+		t.Errorf("APIs: %v", names)
+	}
+	if len(fleet.Rules) != 2 || fleet.Rules[0].Rule != "a" || fleet.Rules[0].Violations != 2 || fleet.Rules[0].APIs != 1 ||
+		fleet.Rules[1].Rule != "b" || fleet.Rules[1].Violations != 2 || fleet.Rules[1].APIs != 2 {
+		t.Errorf("regras: %+v", fleet.Rules)
+	}
+}
+
+// Com --previous, cada número ganha a diferença e as APIs novas e removidas são listadas
+func TestAggregateTrend(t *testing.T) {
+	dir := t.TempDir()
+	_, _, previous := runAggregateCommand(t, dir, map[string]string{"contas.json": aggregateContas, "plataforma.json": aggregatePlataforma})
+	if previous == nil {
+		t.Fatal("o relatório anterior não foi gravado")
+	}
+	data, _ := json.Marshal(previous)
+	writeFiles(t, dir, map[string]string{"anterior.json": string(data)})
+	os.Remove(filepath.Join(dir, "plataforma.json"))
+
+	fixed := `{"schemaVersion": 1, "validation": {"file": "contas.yaml", "errors": 0, "warnings": 1, "violations": [{"ruleId": "b", "severity": "warning"}]}}`
+	code, out, fleet := runAggregateCommand(t, dir, map[string]string{"contas.json": fixed, "novo.json": fixed}, "--previous", filepath.Join(dir, "anterior.json"))
+	if code != exitOK || fleet == nil || fleet.Trend == nil {
+		t.Fatalf("código %d\n%s", code, out)
+	}
+	trend := fleet.Trend
+	if trend.ErrorsDelta != -2 || trend.PassedDelta != 1 || strings.Join(trend.NewAPIs, ",") != "novo" || strings.Join(trend.RemovedAPIs, ",") != "plataforma/cartoes,plataforma/pix" {
+		t.Errorf("tendência: %+v", trend)
+	}
+	if fleet.APIs[0].Name != "contas" || fleet.APIs[0].PreviousStatus != "failed" || fleet.APIs[0].ErrorsDelta != -2 {
+		t.Errorf("API contas: %+v", fleet.APIs[0])
+	}
+	var zeroed *fleetRule
+	for i := range fleet.Rules {
+		if fleet.Rules[i].Rule == "a" {
+			zeroed = &fleet.Rules[i]
+		}
+	}
+	if zeroed == nil || zeroed.Violations != 0 || zeroed.Delta != -2 {
+		t.Errorf("a regra que zerou deveria entrar com delta -2: %+v", fleet.Rules)
+	}
+}
+
+// Relatórios com versões de formato diferentes não são somados; um JSON que não é
+// relatório de validação é recusado
+func TestAggregateRejectsReports(t *testing.T) {
+	dir := t.TempDir()
+	code, out, fleet := runAggregateCommand(t, dir, map[string]string{"contas.json": aggregateContas, "antigo.json": strings.Replace(aggregateContas, `"schemaVersion": 1`, `"schemaVersion": 2`, 1)})
+	if code != exitFailure || fleet != nil || !strings.Contains(out, "versões de formato diferentes") || !strings.Contains(out, "versão 2: "+filepath.Join(dir, "antigo.json")) {
+		t.Errorf("código %d\n%s", code, out)
+	}
+	code, out, _ = runAggregateCommand(t, t.TempDir(), map[string]string{"resolve.json": `{"file": "api.yaml"}`})
+	if code != exitFailure || !strings.Contains(out, "não é um relatório de validação") {
+		t.Errorf("código %d\n%s", code, out)
+	}
+}
@@ -0,0 +1,246 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Parte de um allOf achatado: o schema e onde ele aparece ("allOf[1]", "allOf[0].allOf[2]")
+type allOfPart struct {
+	label  string
+	schema *yaml.Node
+}
+
+// Pares de limites conferidos na combinação das partes
+var allOfBounds = [][2]string{
+	{"minLength", "maxLength"},
+	{"minimum", "maximum"},
+	{"minItems", "maxItems"},
+	{"minProperties", "maxProperties"},
+}
+
+// Função para encontrar schemas allOf que nenhum valor satisfaz: as partes são
+// combinadas e são apontados tipos sem interseção, mínimos acima dos máximos, enums
+// sem valor em comum e propriedades obrigatórias que nenhuma parte define
+func allOfViolations(root *yaml.Node) []Violation {
+	validator := newSchemaValidator(root)
+	var violations []Violation
+	reported := map[string]bool{}
+	visitSchemas(root, nil, func(schema *yaml.Node, path string) {
+		allOf := mappingValue(schema, "allOf")
+		if allOf == nil || allOf.Kind != yaml.SequenceNode {
+			return
+		}
+		// Depois da resolução o mesmo allOf aparece em cada uso do componente; só o primeiro é relatado
+		key := fmt.Sprintf("%d:%d", allOf.Line, allOf.Column)
+		if reported[key] {
+			return
+		}
+		reported[key] = true
+		parts := flattenAllOf(validator, schema, "", 0)
+		for _, conflict := range allOfConflicts(parts, validator.v31) {
+			violations = append(violations, Violation{RuleID: "allof-satisfiable", Severity: conflict.severity, Message: fmt.Sprintf("Schema allOf impossível de satisfazer: %s.", conflict.message), JSONPath: path, Line: schema.Line})
+		}
+	})
+	return violations
+}
+
+// Partes de um allOf, com os allOf internos achatados; as palavras-chave do próprio
+// schema, fora do allOf, também valem
+func flattenAllOf(v *schemaValidator, schema *yaml.Node, prefix string, depth int) []allOfPart {
+	own := "o próprio schema"
+	if prefix != "" {
+		own = prefix
+	}
+	parts := []allOfPart{{own, schema}}
+	allOf := mappingValue(schema, "allOf")
+	if allOf == nil || depth >= maxSchemaRefDepth {
+		return parts
+	}
+	for i, sub := range allOf.Content {
+		label := fmt.Sprintf("allOf[%d]", i)
+		if prefix != "" {
+			label = prefix + "." + label
+		}
+		if ref := mappingValue(sub, "$ref"); ref != nil {
+			if file, pointer := splitRef(ref.Value); file == "" {
+				if target, err := resolvePointer(v.root, pointer); err == nil {
+					sub = target
+				}
+			}
+		}
+		parts = append(parts, flattenAllOf(v, sub, label, depth+1)...)
+	}
+	return parts
+}
+
+type allOfConflict struct {
+	severity string
+	message  string
+}
+
+func allOfConflicts(parts []allOfPart, v31 bool) []allOfConflict {
+	var conflicts []allOfConflict
+	add := func(severity, format string, args ...interface{}) {
+		conflicts = append(conflicts, allOfConflict{severity, fmt.Sprintf(format, args...)})
+	}
+
+	// Tipos: a interseção dos tipos declarados (integer está contido em number)
+	var allowed []string
+	var typed []string
+	for _, p := range parts {
+		types, _ := schemaTypes(p.schema, v31)
+		if len(types) == 0 {
+			continue
+		}
+		typed = append(typed, fmt.Sprintf("%s em %s", strings.Join(types, "/"), p.label))
+		if allowed == nil {
+			allowed = types
+			continue
+		}
+		allowed = intersectTypes(allowed, types)
+		if len(allowed) == 0 {
+			add("error", "tipos incompatíveis (%s)", strings.Join(typed, ", "))
+			break
+		}
+	}
+
+	// Limites: o maior mínimo não pode passar do menor máximo
+	for _, bound := range allOfBounds {
+		var low, high *allOfPart
+		var lowValue, highValue float64
+		for i := range parts {
+			if n, ok := schemaNumber(parts[i].schema, bound[0]); ok && (low == nil || n > lowValue) {
+				low, lowValue = &parts[i], n
+			}
+			if n, ok := schemaNumber(parts[i].schema, bound[1]); ok && (high == nil || n < highValue) {
+				high, highValue = &parts[i], n
+			}
+		}
+		if low != nil && high != nil && lowValue > highValue {
+			add("error", "%s %v (%s) maior que %s %v (%s)", bound[0], lowValue, low.label, bound[1], highValue, high.label)
+		}
+	}
+
+	// Enums: precisa haver ao menos um valor comum a todas as partes
+	var common []*yaml.Node
+	var enumParts []string
+	for _, p := range parts {
+		enum := mappingValue(p.schema, "enum")
+		if enum == nil || enum.Kind != yaml.SequenceNode {
+			continue
+		}
+		enumParts = append(enumParts, p.label)
+		if len(enumParts) == 1 {
+			common = enum.Content
+			continue
+		}
+		var next []*yaml.Node
+		for _, a := range common {
+			for _, b := range enum.Content {
+				if sameValue(a, b) {
+					next = append(next, a)
+					break
+				}
+			}
+		}
+		common = next
+	}
+	if len(enumParts) > 1 && len(common) == 0 {
+		add("error", "os enums de %s não têm nenhum valor em comum", strings.Join(enumParts, ", "))
+	}
+
+	// Propriedades obrigatórias que nenhuma parte define; com additionalProperties: false
+	// em alguma parte, nenhum objeto válido pode tê-las
+	defined := map[string]bool{}
+	closed := ""
+	hasProperties := false
+	for _, p := range parts {
+		if properties := mappingValue(p.schema, "properties"); properties != nil && properties.Kind == yaml.MappingNode {
+			hasProperties = true
+			for i := 0; i+1 < len(properties.Content); i += 2 {
+				defined[properties.Content[i].Value] = true
+			}
+		}
+		if additional := mappingValue(p.schema, "additionalProperties"); additional != nil && additional.Value == "false" && closed == "" {
+			closed = p.label
+		}
+	}
+	var missing []string
+	for _, p := range parts {
+		if required := mappingValue(p.schema, "required"); required != nil && required.Kind == yaml.SequenceNode {
+			for _, name := range required.Content {
+				if !defined[name.Value] {
+					missing = append(missing, fmt.Sprintf("%s (%s)", name.Value, p.label))
+					defined[name.Value] = true
+				}
+			}
+		}
+	}
+	if hasProperties && len(missing) > 0 {
+		sort.Strings(missing)
+		if closed != "" {
+			add("error", "propriedades obrigatórias que nenhuma parte define, com additionalProperties: false em %s: %s", closed, strings.Join(missing, ", "))
+		} else {
+			add("warning", "propriedades obrigatórias que nenhuma parte define: %s", strings.Join(missing, ", "))
+		}
+	}
+
+	// Propriedades definidas em mais de uma parte com tipos incompatíveis
+	propertyTypes := map[string][]string{}
+	propertyParts := map[string][]string{}
+	var names []string
+	for _, p := range parts {
+		properties := mappingValue(p.schema, "properties")
+		if properties == nil || properties.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(properties.Content); i += 2 {
+			name := properties.Content[i].Value
+			types, _ := schemaTypes(properties.Content[i+1], v31)
+			if len(types) == 0 {
+				continue
+			}
+			if _, ok := propertyTypes[name]; !ok {
+				names = append(names, name)
+				propertyTypes[name] = types
+			} else {
+				propertyTypes[name] = intersectTypes(propertyTypes[name], types)
+			}
+			propertyParts[name] = append(propertyParts[name], fmt.Sprintf("%s em %s", strings.Join(types, "/"), p.label))
+		}
+	}
+	for _, name := range names {
+		if len(propertyTypes[name]) == 0 {
+			add("error", "a propriedade %s tem tipos incompatíveis (%s)", name, strings.Join(propertyParts[name], ", "))
+		}
+	}
+	return conflicts
+}
+
+// Interseção de duas listas de tipos; integer e number resultam em integer
+func intersectTypes(a, b []string) []string {
+	has := func(list []string, t string) bool {
+		for _, item := range list {
+			if item == t {
+				return true
+			}
+		}
+		return false
+	}
+	var out []string
+	for _, t := range a {
+		switch {
+		case has(b, t):
+			out = append(out, t)
+		case t == "integer" && has(b, "number"), t == "number" && has(b, "integer"):
+			if !has(out, "integer") {
+				out = append(out, "integer")
+			}
+		}
+	}
+	return out
+}
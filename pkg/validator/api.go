@@ -0,0 +1,224 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sync"
+)
+
+// Logger recebe as mensagens emitidas durante uma chamada da API (avisos das regras,
+// dos overlays, da codificação...), uma por linha; *log.Logger satisfaz a interface
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Options configura uma chamada de Validate ou Resolve. O valor zero usa o pacote de
+// regras ofb (registrado pela CLI com RegisterRuleset) e não busca $refs remotos.
+type Options struct {
+	// Source é o nome da especificação nas mensagens e a referência dos $refs
+	// relativos (padrão: openapi.yaml no diretório atual)
+	Source string
+	// BaseDir é o diretório base dos $refs relativos (padrão: o diretório de Source)
+	BaseDir string
+	// FS é o sistema de arquivos de onde vêm os arquivos referenciados, RulesFile,
+	// os extends das regras e, quando spec é nil, a própria especificação (Source);
+	// os caminhos são relativos à raiz dele. nil lê do disco; MemFS serve conteúdos
+	// em memória e os.DirFS, embed.FS ou um backend próprio também servem.
+	FS fs.FS
+	// Rules é o conteúdo de um arquivo de regras; sem ele, RulesFile é o nome de um
+	// pacote registrado ou o caminho do arquivo (padrão: ofb)
+	Rules     []byte
+	RulesFile string
+	// Format é o formato do documento resolvido: yaml (padrão) ou json
+	Format string
+	// Encoding é a codificação da especificação e dos arquivos referenciados, como em
+	// --encoding: auto (padrão, detectada pelo conteúdo), utf-8, utf-16le, utf-16be,
+	// iso-8859-1 ou windows-1252
+	Encoding string
+
+	AllowRemoteRefs  bool
+	ValidateExamples bool  // --validate-examples
+	StrictYAML       bool  // --strict-yaml
+	StrictRules      bool  // --strict
+	Document         int   // documento de um YAML com vários (--select-document)
+	MaxFileSize      int64 // bytes; 0 mantém o padrão de 50MB e negativo desativa o limite
+	Logger           Logger
+
+	// OldFS e OldBaseDir substituem FS e BaseDir para a versão anterior em Diff, para
+	// que os arquivos referenciados por ela venham da mesma versão
+	OldFS      fs.FS
+	OldBaseDir string
+
+	// PathPrefixes e Components restringem Diff aos paths abaixo dos prefixos e aos
+	// componentes informados, com os que eles referenciam (diff --path-prefix e --component)
+	PathPrefixes []string
+	Components   []string
+
+	rules map[string]interface{} // regras já carregadas (serve), no lugar de Rules e RulesFile
+}
+
+// Result é o resultado de Validate
+type Result struct {
+	Violations []Violation
+	Errors     int // violações de severidade error
+	Warnings   int // as demais severidades
+}
+
+// Valid indica se não há violações de severidade error
+func (r *Result) Valid() bool {
+	return r.Errors == 0
+}
+
+// ResolveResult é o resultado de Resolve
+type ResolveResult struct {
+	Document []byte   // documento com as referências resolvidas
+	Errors   []string // erros de referência, com arquivo e linha
+	Cycles   []string // referências circulares, mantidas como $ref no documento
+}
+
+// Validate valida a especificação com as regras de opts e com as conferências
+// embutidas (YAML, discriminators, segurança, $refs, allOf e, se pedido, exemplos).
+// O erro indica uma falha da execução (regras inválidas, documento ilegível,
+// cancelamento); as violações vêm em Result.
+func Validate(ctx context.Context, spec []byte, opts Options) (*Result, error) {
+	source, s, err := opts.settings(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := opts.rules
+	switch {
+	case rules != nil:
+	case len(opts.Rules) > 0:
+		rules, err = loadRulesData(s, opts.Rules, "regras", sourceDir(source))
+	default:
+		rulesFile := opts.RulesFile
+		if rulesFile == "" {
+			if _, ok := builtinRulesets["ofb"]; !ok {
+				return nil, fmt.Errorf("nenhuma regra informada: use Options.Rules, Options.RulesFile ou registre o pacote ofb com RegisterRuleset")
+			}
+			rulesFile = "ofb"
+		}
+		rules, err = loadRuleset(s, rulesFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	violations, err := validateOpenAPI(ctx, s, source, rules)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Violations: violations}
+	result.Errors, result.Warnings = countViolations(violations)
+	return result, nil
+}
+
+// Resolve indexa a especificação e substitui cada $ref pelo conteúdo, como o
+// comando resolve. Erros de referência e ciclos vêm em ResolveResult.
+func Resolve(ctx context.Context, spec []byte, opts Options) (*ResolveResult, error) {
+	format, err := outputFormatFor("", opts.Format)
+	if err != nil {
+		return nil, err
+	}
+	resolved, data, err := resolveSpec(ctx, spec, opts, format)
+	if err != nil {
+		return nil, err
+	}
+	result := &ResolveResult{Document: data, Errors: []string{}, Cycles: []string{}}
+	for _, e := range resolved.report.Errors {
+		result.Errors = append(result.Errors, e.String())
+	}
+	for _, c := range resolved.report.Cycles {
+		result.Cycles = append(result.Cycles, c.String())
+	}
+	return result, nil
+}
+
+// Resolve a especificação e gera o documento, com o relatório completo da resolução
+func resolveSpec(ctx context.Context, spec []byte, opts Options, format string) (*resolvedSpec, []byte, error) {
+	source, s, err := opts.settings(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	resolved, err := indexAndResolve(ctx, s, source)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := resolved.marshal(format)
+	if err != nil {
+		return nil, nil, err
+	}
+	resolved.report.Expansion = newExpansionReport(resolved, len(data))
+	return resolved, data, nil
+}
+
+// Configuração da chamada, montada a partir das opções, com a especificação em
+// memória. Nada do pacote é alterado: chamadas simultâneas não interferem entre si,
+// nem com uma etapa abandonada de uma chamada cancelada.
+func (o Options) settings(spec []byte) (source string, s *runSettings, err error) {
+	encoding, err := normalizeEncoding(o.Encoding)
+	if err != nil {
+		return "", nil, fmt.Errorf("valor inválido para Options.Encoding: %v", err)
+	}
+	source = o.Source
+	if source == "" {
+		source = "openapi.yaml"
+	}
+	abs, err := filepath.Abs(source)
+	if err != nil {
+		abs = source
+	}
+
+	var log io.Writer = io.Discard
+	if o.Logger != nil {
+		log = &loggerWriter{logger: o.Logger}
+	}
+	s = defaultSettings.with(func(s *runSettings) {
+		s.fsys = o.FS
+		s.encoding = encoding
+		s.encodingWarned = &sync.Map{}
+		s.root = workingRoot()
+		s.baseDir = o.BaseDir
+		s.allowRemoteRefs = o.AllowRemoteRefs
+		s.noCache = true
+		s.validateExamples = o.ValidateExamples
+		s.strictYAML = o.StrictYAML
+		s.strictRules = o.StrictRules
+		s.document = o.Document
+		s.log = log
+		s.progress = nil
+		s.tracker = nil
+	})
+	switch {
+	case o.MaxFileSize > 0:
+		s.maxFileSize = byteSize(o.MaxFileSize)
+	case o.MaxFileSize < 0:
+		s.maxFileSize = 0
+	}
+	if spec != nil || o.FS == nil {
+		s.inline = map[string][]byte{abs: spec}
+	}
+	return source, s, nil
+}
+
+// Repassa ao Logger cada linha escrita
+type loggerWriter struct {
+	logger Logger
+	buf    []byte
+}
+
+func (w *loggerWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		w.logger.Printf("%s", w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+}
@@ -0,0 +1,133 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severidade da divergência entre o major de info.version, o do caminho do arquivo e
+// o das URLs dos servidores; off desativa a verificação
+var versionConsistency = "warning"
+
+// Expressão regular do major no caminho do arquivo (o primeiro grupo); vazio não
+// confere o caminho, para APIs cujo arquivo não fica em um diretório de versão
+var versionPathPattern = `(?:^|/)v(\d+)(?:/|$)`
+
+// Versões de pré-lançamento (2.0.0-rc.1) costumam ser publicadas antes da troca do
+// segmento de versão das URLs: skip não confere, check confere como as demais
+var versionPrerelease = "skip"
+
+// Segmento de versão nas URLs dos servidores e no basePath do Swagger 2.0
+var serverVersionPattern = regexp.MustCompile(`/v(\d+)(?:/|$)`)
+
+func registerVersionFlags(fs *flag.FlagSet) {
+	fs.StringVar(&versionConsistency, "version-consistency", versionConsistency, "severidade da divergência de major entre info.version, o caminho do arquivo e as URLs dos servidores: error, warning, info, hint ou off")
+	fs.StringVar(&versionPathPattern, "version-path-pattern", versionPathPattern, "expressão regular do major no caminho do arquivo, no primeiro grupo (vazio não confere o caminho)")
+	fs.StringVar(&versionPrerelease, "version-prerelease", versionPrerelease, "versões de pré-lançamento em info.version: skip (não confere) ou check")
+}
+
+func checkVersionFlags() error {
+	if versionConsistency != "off" && !ruleSeverities[versionConsistency] {
+		return fmt.Errorf("valor inválido para --version-consistency: %q (use error, warning, info, hint ou off)", versionConsistency)
+	}
+	if versionPrerelease != "skip" && versionPrerelease != "check" {
+		return fmt.Errorf("valor inválido para --version-prerelease: %q (use skip ou check)", versionPrerelease)
+	}
+	if versionPathPattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile(versionPathPattern)
+	if err != nil {
+		return fmt.Errorf("--version-path-pattern %q não é uma expressão regular válida: %v", versionPathPattern, err)
+	}
+	if re.NumSubexp() < 1 {
+		return fmt.Errorf("--version-path-pattern %q precisa de um grupo com o major (ex.: %s)", versionPathPattern, `(?:^|/)v(\d+)(?:/|$)`)
+	}
+	return nil
+}
+
+// Major encontrado em uma das fontes da versão
+type versionSource struct {
+	label string // ex.: "info.version 2.3.1", "servidor https://.../v1"
+	major int
+}
+
+// Função para comparar o major de info.version com o do caminho do arquivo e o de
+// cada URL de servidor (todos os níveis, com as variáveis trocadas pelos defaults; no
+// Swagger 2.0, o basePath). Fontes sem número de versão ficam de fora; a violação
+// lista todas as fontes quando alguma diverge.
+func versionConsistencyViolations(s *runSettings, inputFile string, root *yaml.Node) []Violation {
+	if s.versionConsistency == "off" {
+		return nil
+	}
+	doc := documentContent(root)
+	version := mappingValue(mappingValue(doc, "info"), "version")
+	if version == nil {
+		return nil
+	}
+	m := apiVersionPattern.FindStringSubmatch(strings.TrimSpace(version.Value))
+	if m == nil || (m[2] != "" && s.versionPrerelease == "skip") {
+		return nil
+	}
+	major, _ := strconv.Atoi(m[1])
+	sources := []versionSource{{label: "info.version " + version.Value, major: major}}
+
+	// O padrão já foi conferido em checkVersionFlags
+	if re, err := regexp.Compile(s.versionPathPattern); err == nil && s.versionPathPattern != "" && re.NumSubexp() > 0 && inputFile != "" && inputFile != "-" {
+		file := filepath.ToSlash(inputFile)
+		// A última ocorrência é a mais próxima do arquivo
+		if all := re.FindAllStringSubmatch(file, -1); len(all) > 0 {
+			if n, err := strconv.Atoi(all[len(all)-1][1]); err == nil {
+				sources = append(sources, versionSource{label: "caminho " + file, major: n})
+			}
+		}
+	}
+	seen := map[string]bool{}
+	addServer := func(label, url string) {
+		if seen[url] {
+			return
+		}
+		seen[url] = true
+		if all := serverVersionPattern.FindAllStringSubmatch(url, -1); len(all) > 0 {
+			n, _ := strconv.Atoi(all[len(all)-1][1])
+			sources = append(sources, versionSource{label: label + " " + url, major: n})
+		}
+	}
+	lists, _ := queryJSONPath(root, "$..servers")
+	for _, list := range lists {
+		for _, s := range collectServers(list.Node, list.Path, "") {
+			addServer("servidor", s.url)
+		}
+	}
+	if basePath := mappingValue(doc, "basePath"); basePath != nil && isSwagger2(root) {
+		addServer("basePath", basePath.Value)
+	}
+
+	divergent := false
+	for _, s := range sources[1:] {
+		if s.major != major {
+			divergent = true
+		}
+	}
+	if !divergent {
+		return nil
+	}
+	labels := make([]string, 0, len(sources))
+	for _, s := range sources {
+		labels = append(labels, fmt.Sprintf("%s (v%d)", s.label, s.major))
+	}
+	return []Violation{{
+		RuleID:     "version-consistency",
+		Severity:   s.versionConsistency,
+		Message:    fmt.Sprintf("O major da versão não é o mesmo em todos os lugares: %s.", strings.Join(labels, ", ")),
+		JSONPath:   "$.info.version",
+		Line:       version.Line,
+		Suggestion: fmt.Sprintf("Use a mesma versão major (v%d, de info.version) no diretório do arquivo e no segmento de versão das URLs dos servidores; se o arquivo não fica em um diretório de versão, ajuste --version-path-pattern.", major),
+	}}
+}
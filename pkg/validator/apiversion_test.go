@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+func versionSpec(version string) string {
+	return `openapi: 3.0.3
+info: {title: Contas, version: ` + version + `}
+servers:
+  - url: https://api.banco.com.br/open-banking/accounts/v2
+  - url: https://{ambiente}.banco.com.br/open-banking/accounts/{versao}
+    variables:
+      ambiente: {default: sandbox}
+      versao: {default: v2}
+paths:
+  /contas:
+    get:
+      servers:
+        - url: https://legado.banco.com.br/open-banking/accounts/v1
+      responses: {'200': {description: ok}}
+`
+}
+
+func versionSettings() *runSettings {
+	return &runSettings{versionConsistency: "warning", versionPathPattern: versionPathPattern, versionPrerelease: "skip"}
+}
+
+// O major de info.version é comparado com o do caminho do arquivo e o de cada servidor
+// (com as variáveis trocadas pelos defaults); a violação lista todas as fontes
+func TestVersionConsistency(t *testing.T) {
+	found := versionConsistencyViolations(versionSettings(), "apis/accounts/v2/swagger.yaml", mustParseYAML(t, versionSpec("2.1.0")))
+	if len(found) != 1 {
+		t.Fatalf("esperada uma violação, encontradas %d: %v", len(found), found)
+	}
+	want := "info.version 2.1.0 (v2), caminho apis/accounts/v2/swagger.yaml (v2), servidor https://api.banco.com.br/open-banking/accounts/v2 (v2), servidor https://sandbox.banco.com.br/open-banking/accounts/v2 (v2), servidor https://legado.banco.com.br/open-banking/accounts/v1 (v1)"
+	if v := found[0]; !strings.Contains(v.Message, want) || v.Line != 2 || v.Severity != "warning" {
+		t.Errorf("violação: %+v\nesperado: %s", v, want)
+	}
+
+	spec := strings.Replace(versionSpec("2.1.0"), "accounts/v1", "accounts/v2", 1)
+	if found := versionConsistencyViolations(versionSettings(), "apis/accounts/v2/swagger.yaml", mustParseYAML(t, spec)); len(found) != 0 {
+		t.Errorf("com o mesmo major em todos os lugares, nenhuma violação: %v", found)
+	}
+	if found := versionConsistencyViolations(versionSettings(), "apis/accounts/v3/swagger.yaml", mustParseYAML(t, spec)); len(found) != 1 || !strings.Contains(found[0].Message, "caminho apis/accounts/v3/swagger.yaml (v3)") {
+		t.Errorf("o caminho em v3 deveria divergir: %v", found)
+	}
+}
+
+// Pré-lançamentos só são conferidos com check; off desativa a verificação
+func TestVersionConsistencyOptions(t *testing.T) {
+	root := mustParseYAML(t, versionSpec("3.0.0-rc.1"))
+	s := versionSettings()
+	if found := versionConsistencyViolations(s, "swagger.yaml", root); len(found) != 0 {
+		t.Errorf("com skip, o pré-lançamento não deveria ser conferido: %v", found)
+	}
+	s.versionPrerelease = "check"
+	if found := versionConsistencyViolations(s, "swagger.yaml", root); len(found) != 1 {
+		t.Errorf("com check, o pré-lançamento deveria divergir dos servidores: %v", found)
+	}
+	s.versionConsistency = "off"
+	if found := versionConsistencyViolations(s, "swagger.yaml", root); len(found) != 0 {
+		t.Errorf("com off, nenhuma violação: %v", found)
+	}
+}
@@ -0,0 +1,164 @@
+package validator
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Identificação do artefato gerado, para registro de procedência no pipeline
+type artifactInfo struct {
+	File   string `json:"file"`
+	Bytes  int    `json:"bytes"`
+	SHA256 string `json:"sha256"`
+}
+
+func newArtifactInfo(file string, data []byte) *artifactInfo {
+	sum := sha256.Sum256(data)
+	return &artifactInfo{File: reportPath(file), Bytes: len(data), SHA256: hex.EncodeToString(sum[:])}
+}
+
+// Calcula tamanho e hash do artefato à medida que ele é escrito
+type artifactWriter struct {
+	hash hash.Hash
+	n    int
+}
+
+func newArtifactWriter() *artifactWriter {
+	return &artifactWriter{hash: sha256.New()}
+}
+
+func (w *artifactWriter) Write(p []byte) (int, error) {
+	w.n += len(p)
+	return w.hash.Write(p)
+}
+
+func (w *artifactWriter) info(file string) *artifactInfo {
+	return &artifactInfo{File: reportPath(file), Bytes: w.n, SHA256: hex.EncodeToString(w.hash.Sum(nil))}
+}
+
+func (a *artifactInfo) String() string {
+	return fmt.Sprintf("%s: %d bytes, sha256 %s", a.File, a.Bytes, a.SHA256)
+}
+
+// Função para conferir a estrutura mínima de um documento OpenAPI: campos
+// obrigatórios da raiz e de info, chaves de paths e chaves duplicadas
+func structuralProblems(rootNode *yaml.Node) []string {
+	doc := rootNode
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	if doc.Kind != yaml.MappingNode {
+		return []string{"o documento não é um objeto"}
+	}
+
+	var problems []string
+	version := mappingValue(doc, "openapi")
+	if version == nil {
+		version = mappingValue(doc, "swagger")
+	}
+	if version == nil || version.Kind != yaml.ScalarNode || version.Value == "" {
+		problems = append(problems, "campo openapi ausente")
+	}
+
+	info := mappingValue(doc, "info")
+	if info == nil || info.Kind != yaml.MappingNode {
+		problems = append(problems, "objeto info ausente")
+	} else {
+		for _, field := range []string{"title", "version"} {
+			if mappingValue(info, field) == nil {
+				problems = append(problems, "campo info."+field+" ausente")
+			}
+		}
+	}
+
+	paths := mappingValue(doc, "paths")
+	switch {
+	case paths == nil:
+		if mappingValue(doc, "webhooks") == nil && mappingValue(doc, "components") == nil {
+			problems = append(problems, "objeto paths ausente")
+		}
+	case paths.Kind != yaml.MappingNode:
+		problems = append(problems, "paths não é um objeto")
+	default:
+		for i := 0; i+1 < len(paths.Content); i += 2 {
+			key := paths.Content[i].Value
+			if !strings.HasPrefix(key, "/") && !strings.HasPrefix(key, "x-") {
+				problems = append(problems, fmt.Sprintf("o path %q não começa com /", key))
+			}
+		}
+	}
+
+	return append(problems, duplicateKeys(doc, "$")...)
+}
+
+// Chaves repetidas em um mesmo objeto; o yaml.v3 não as rejeita ao montar a árvore
+func duplicateKeys(node *yaml.Node, path string) []string {
+	var problems []string
+	switch node.Kind {
+	case yaml.MappingNode:
+		seen := map[string]bool{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if seen[key] && key != "<<" {
+				problems = append(problems, fmt.Sprintf("chave duplicada %s em %s", key, path))
+			}
+			seen[key] = true
+			problems = append(problems, duplicateKeys(node.Content[i+1], joinPath(path, key))...)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			problems = append(problems, duplicateKeys(item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	return problems
+}
+
+// Função para conferir o artefato gerado: ele é lido de novo e não pode ter
+// problemas estruturais que a especificação de entrada não tinha
+func verifyArtifact(data []byte, inputProblems []string) error {
+	rootNode, err := parseSpec(data, "artefato gerado")
+	if err != nil {
+		return fmt.Errorf("o artefato gerado não pôde ser lido de novo: %v", err)
+	}
+	return introducedProblems(rootNode, inputProblems)
+}
+
+// Igual a verifyArtifact, lendo o artefato do arquivo gravado sem carregá-lo inteiro
+// em memória antes de montar a árvore
+func verifyArtifactFile(path string, inputProblems []string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("o artefato gerado não pôde ser lido de novo: %v", err)
+	}
+	defer f.Close()
+	var rootNode yaml.Node
+	if err := yaml.NewDecoder(bufio.NewReader(f)).Decode(&rootNode); err != nil {
+		return fmt.Errorf("o artefato gerado não pôde ser lido de novo: %v", err)
+	}
+	return introducedProblems(&rootNode, inputProblems)
+}
+
+// Problemas estruturais do artefato que a especificação de entrada não tinha
+func introducedProblems(rootNode *yaml.Node, inputProblems []string) error {
+	known := map[string]bool{}
+	for _, p := range inputProblems {
+		known[p] = true
+	}
+	var introduced []string
+	for _, p := range structuralProblems(rootNode) {
+		if !known[p] {
+			introduced = append(introduced, p)
+		}
+	}
+	if len(introduced) > 0 {
+		return fmt.Errorf("o artefato gerado tem problemas que a especificação de entrada não tinha: %s", strings.Join(introduced, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package validator
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schemas embarcados no binário: ofb-error, o corpo padrão das respostas de erro do
+// OFB usado pela função schema das regras, e report, o schema do relatório JSON
+//
+//go:embed assets/*.schema.json
+var assetFiles embed.FS
+
+const schemaAssetSuffix = ".schema.json"
+
+// Nomes dos schemas embarcados, em ordem alfabética
+func builtinSchemaNames() []string {
+	entries, _ := assetFiles.ReadDir("assets")
+	var names []string
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), schemaAssetSuffix) {
+			names = append(names, strings.TrimSuffix(entry.Name(), schemaAssetSuffix))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func builtinSchemaData(name string) ([]byte, bool) {
+	data, err := assetFiles.ReadFile("assets/" + name + schemaAssetSuffix)
+	return data, err == nil
+}
+
+// Schema já interpretado para a função schema das regras
+var (
+	ruleSchemasMu sync.Mutex
+	ruleSchemas   = map[string]*yaml.Node{}
+)
+
+// Função para obter o schema de functionOptions.schema: o nome de um schema
+// embarcado, o caminho de um arquivo JSON/YAML ou o próprio schema em linha. Um
+// arquivo existente com o mesmo nome de um schema embarcado tem precedência.
+func ruleSchema(option interface{}) (*yaml.Node, error) {
+	var key, file string
+	var data []byte
+	switch value := option.(type) {
+	case string:
+		// O arquivo que substitui um schema embarcado tem chave própria: carregar um
+		// não esconde o outro pelo resto do processo
+		key = "name:" + value
+		if info, err := os.Stat(value); err == nil && !info.IsDir() {
+			if file, err = filepath.Abs(value); err != nil {
+				file = value
+			}
+			key = "file:" + file
+		}
+	case map[string]interface{}:
+		inline, err := yaml.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		key, data = "inline:"+string(inline), inline
+	default:
+		return nil, fmt.Errorf("functionOptions.schema deve ser o nome de um schema embarcado, um arquivo ou um objeto, encontrado %s", ruleValueType(option))
+	}
+
+	ruleSchemasMu.Lock()
+	defer ruleSchemasMu.Unlock()
+	if schema, ok := ruleSchemas[key]; ok {
+		return schema, nil
+	}
+	if name, ok := option.(string); ok {
+		if file != "" {
+			var err error
+			if data, err = readFile(file); err != nil {
+				return nil, err
+			}
+		} else if data, ok = builtinSchemaData(name); !ok {
+			return nil, fmt.Errorf("schema %q não encontrado (embarcados: %s)", name, strings.Join(builtinSchemaNames(), ", "))
+		}
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("schema inválido: %v", err)
+	}
+	schema := documentContent(&doc)
+	if schema == nil || schema.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("schema inválido: esperado um objeto")
+	}
+	ruleSchemas[key] = schema
+	return schema, nil
+}
+
+// Confere o nó da especificação contra o schema da regra; um schema isolado segue a
+// semântica do JSON Schema (a da OpenAPI 3.1)
+func matchesRuleSchema(node *yaml.Node, option interface{}) bool {
+	schema, err := ruleSchema(option)
+	if err != nil {
+		return false
+	}
+	validator := &schemaValidator{root: schema, v31: true}
+	return len(validator.validate(schema, node, "$")) == 0
+}
@@ -0,0 +1,161 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Cada pacote de regras embarcado carrega sem problemas, mesmo com --strict, e cada
+// schema embarcado é JSON válido e utilizável pela função schema das regras
+func TestBuiltinAssets(t *testing.T) {
+	if len(builtinRulesets) == 0 {
+		t.Fatal("nenhum pacote de regras registrado")
+	}
+	for name, data := range builtinRulesets {
+		s := flagSettings()
+		s.tracker = nil
+		s.strictRules = true
+		rules, err := loadRulesData(s, data, name, ".")
+		if err != nil || len(rules) == 0 {
+			t.Errorf("pacote %s: %d regras, %v", name, len(rules), err)
+		}
+	}
+
+	names := builtinSchemaNames()
+	if strings.Join(names, ",") != "ofb-error,report" {
+		t.Errorf("schemas embarcados %v, esperado [ofb-error report]", names)
+	}
+	for _, name := range names {
+		data, _ := builtinSchemaData(name)
+		if err := checkSchemaAsset(data); err != nil {
+			t.Errorf("schema %s: %v", name, err)
+		}
+		if _, err := ruleSchema(name); err != nil {
+			t.Errorf("schema %s: %v", name, err)
+		}
+	}
+	if _, err := ruleSchema("inexistente"); err == nil || err.Error() != `schema "inexistente" não encontrado (embarcados: ofb-error, report)` {
+		t.Errorf("erro %v", err)
+	}
+}
+
+// O relatório JSON do validate segue o schema embarcado report; um relatório fora do
+// schema é recusado
+func TestReportSchema(t *testing.T) {
+	var out, errOut bytes.Buffer
+	Run([]string{"validate", "--no-cache", "--format", "json", filepath.Join("testdata", "e2e", "violations", "api.yaml")}, &out, &errOut)
+	if errs := reportSchemaErrors(t, out.Bytes()); len(errs) > 0 {
+		t.Errorf("relatório fora do schema report: %+v\n%s", errs, out.String())
+	}
+	if errs := reportSchemaErrors(t, []byte(`{"validation": {"file": "api.yaml", "errors": -1, "warnings": 0, "violations": []}}`)); len(errs) == 0 {
+		t.Error("um relatório com errors negativo deveria ser recusado")
+	}
+}
+
+func reportSchemaErrors(t *testing.T, report []byte) []schemaError {
+	t.Helper()
+	schema, err := ruleSchema("report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(report, &doc); err != nil {
+		t.Fatalf("%v\n%s", err, report)
+	}
+	validator := &schemaValidator{root: schema, v31: true}
+	return validator.validate(schema, documentContent(&doc), "$")
+}
+
+// rules list --builtin confere cada item embarcado e rules show imprime o conteúdo
+func TestRulesListBuiltin(t *testing.T) {
+	var out, errOut bytes.Buffer
+	if code := Run([]string{"rules", "list", "--builtin"}, &out, &errOut); code != exitOK {
+		t.Fatalf("exit %d\n%s%s", code, out.String(), errOut.String())
+	}
+	for _, want := range []string{"📦 Pacotes de regras embarcados", "📦 Schemas embarcados"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("saída sem %q:\n%s", want, out.String())
+		}
+	}
+	for _, name := range []string{"ofb", "ofb-ordering", "ofb-error", "report"} {
+		found := false
+		for _, line := range strings.Split(out.String(), "\n") {
+			if fields := strings.Fields(line); len(fields) > 0 && fields[0] == name {
+				found = strings.HasSuffix(line, "✅")
+			}
+		}
+		if !found {
+			t.Errorf("%s ausente ou com problema:\n%s", name, out.String())
+		}
+	}
+
+	out.Reset()
+	if code := Run([]string{"rules", "show", "ofb-error"}, &out, &errOut); code != exitOK {
+		t.Fatalf("exit %d\n%s", code, errOut.String())
+	}
+	if data, _ := builtinSchemaData("ofb-error"); !bytes.Equal(out.Bytes(), data) {
+		t.Errorf("rules show ofb-error difere do schema embarcado:\n%s", out.String())
+	}
+	out.Reset()
+	if code := Run([]string{"rules", "show", "nada"}, &out, &errOut); code != exitFailure || !strings.Contains(out.String(), `"nada" não é um pacote nem um schema embarcado`) {
+		t.Errorf("exit %d\n%s", code, out.String())
+	}
+}
+
+// Um arquivo com o nome de um pacote ou schema embarcado, informado explicitamente,
+// substitui a cópia embarcada sem escondê-la das execuções seguintes
+func TestBuiltinShadowing(t *testing.T) {
+	spec := mustReadFile(t, filepath.Join("testdata", "e2e", "valid", "api.yaml"))
+	var logged []string
+	result, err := Validate(context.Background(), nil, Options{
+		Source:    "api.yaml",
+		RulesFile: "ofb",
+		FS: MemFS{
+			"api.yaml": spec,
+			"ofb":      []byte("rules:\n  sem-contato:\n    severity: error\n    given: $.info\n    then: {field: contact, function: falsy}\n"),
+		},
+		Logger: loggerFunc(func(format string, v ...interface{}) { logged = append(logged, fmt.Sprintf(format, v...)) }),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Violations) != 1 || result.Violations[0].RuleID != "sem-contato" {
+		t.Errorf("violações %+v, esperada só a da regra do arquivo ofb", result.Violations)
+	}
+	if !strings.Contains(strings.Join(logged, "\n"), "Usando o arquivo ofb no lugar do pacote embarcado") {
+		t.Errorf("sem o aviso da substituição: %q", logged)
+	}
+
+	embedded, err := ruleSchema("ofb-error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("ofb-error", []byte(`{"type": "object", "required": ["erro"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	shadow, err := ruleSchema("ofb-error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shadow == embedded || mappingValue(shadow, "required") == nil || mappingValue(shadow, "required").Content[0].Value != "erro" {
+		t.Errorf("o arquivo ofb-error não substituiu o schema embarcado")
+	}
+	if err := os.Remove("ofb-error"); err != nil {
+		t.Fatal(err)
+	}
+	if again, err := ruleSchema("ofb-error"); err != nil || again != embedded {
+		t.Errorf("sem o arquivo, o schema embarcado deveria voltar: %v", err)
+	}
+}
+
+type loggerFunc func(format string, v ...interface{})
+
+func (f loggerFunc) Printf(format string, v ...interface{}) { f(format, v...) }
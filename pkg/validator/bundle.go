@@ -0,0 +1,372 @@
+package validator
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Resumo do bundle: componentes trazidos de outros arquivos e colisões de nome
+type bundleReport struct {
+	Components []bundledComponent `json:"components"`
+	Collisions []bundleCollision  `json:"collisions"`
+}
+
+// Componente externo copiado para a seção components do documento raiz
+type bundledComponent struct {
+	Source string `json:"source"`
+	Ref    string `json:"ref"`
+}
+
+// Dois componentes diferentes com o mesmo nome; o segundo recebe um sufixo
+type bundleCollision struct {
+	Name     string `json:"name"`
+	Source   string `json:"source"`
+	Existing string `json:"existing"`
+	Renamed  string `json:"renamed"`
+}
+
+// Estado do bundle de um documento
+type bundler struct {
+	root       *yaml.Node
+	components *yaml.Node            // criado apenas quando algum componente externo é copiado
+	files      map[string]*yaml.Node // arquivos externos já carregados
+	done       map[string]string     // arquivo#ponteiro -> $ref interno gerado
+	origin     map[string]string     // $ref interno gerado -> arquivo#ponteiro
+	firstAdded map[*yaml.Node]int    // seção de components -> posição do primeiro componente incorporado
+	report     bundleReport
+}
+
+// Função para trazer os componentes referenciados em outros arquivos para a seção
+// components do documento raiz, mantendo os $refs internos
+func bundleOpenAPI(inputFile string, rootNode *yaml.Node) (*bundleReport, error) {
+	absFile, err := filepath.Abs(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter o caminho de %s: %v", inputFile, err)
+	}
+	doc := rootNode
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	if doc.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("o documento %s não é um mapping YAML", inputFile)
+	}
+
+	b := &bundler{
+		root:       doc,
+		files:      map[string]*yaml.Node{absFile: doc},
+		done:       map[string]string{},
+		origin:     map[string]string{},
+		firstAdded: map[*yaml.Node]int{},
+		report:     bundleReport{Components: []bundledComponent{}, Collisions: []bundleCollision{}},
+	}
+
+	baseDir := filepath.Dir(absFile)
+	if refsBaseDir != "" {
+		if baseDir, err = filepath.Abs(refsBaseDir); err != nil {
+			return nil, err
+		}
+	}
+	if err := b.rewrite(doc, absFile, baseDir, nil, true); err != nil {
+		return nil, err
+	}
+	b.sortAdded()
+	return &b.report, nil
+}
+
+// Ordena pelo nome os componentes incorporados em cada seção (os que já existiam no
+// documento raiz ficam na posição original), para que a saída não dependa da ordem
+// em que as referências foram encontradas
+func (b *bundler) sortAdded() {
+	for section, start := range b.firstAdded {
+		added := section.Content[start:]
+		pairs := make([][2]*yaml.Node, 0, len(added)/2)
+		for i := 0; i+1 < len(added); i += 2 {
+			pairs = append(pairs, [2]*yaml.Node{added[i], added[i+1]})
+		}
+		sort.SliceStable(pairs, func(i, j int) bool { return pairs[i][0].Value < pairs[j][0].Value })
+		for i, pair := range pairs {
+			added[2*i], added[2*i+1] = pair[0], pair[1]
+		}
+	}
+	sort.SliceStable(b.report.Components, func(i, j int) bool {
+		return b.report.Components[i].Ref < b.report.Components[j].Ref
+	})
+}
+
+// Percorre o nó reescrevendo os $refs externos; path guarda as chaves até o nó
+// (usado para descobrir o tipo de componente) e inRoot indica se o nó é do documento raiz
+func (b *bundler) rewrite(node *yaml.Node, file, dir string, path []string, inRoot bool) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "$ref" && value.Kind == yaml.ScalarNode {
+				ref, err := b.bundleRef(value.Value, file, dir, path, inRoot)
+				if err != nil {
+					return fmt.Errorf("%s:%d: %v", file, value.Line, err)
+				}
+				value.Value = ref
+				continue
+			}
+			if err := b.rewrite(value, file, dir, append(path, key.Value), inRoot); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if err := b.rewrite(item, file, dir, path, inRoot); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Retorna o $ref interno equivalente a uma referência, copiando o alvo para components quando é externo
+func (b *bundler) bundleRef(ref, file, dir string, path []string, inRoot bool) (string, error) {
+	target, pointer := splitRef(ref)
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		return ref, nil
+	}
+	if target == "" {
+		if inRoot {
+			return ref, nil
+		}
+		target = file
+	} else if !filepath.IsAbs(target) {
+		target = filepath.Join(dir, target)
+	}
+
+	key := target + "#" + pointer
+	if internal, ok := b.done[key]; ok {
+		return internal, nil
+	}
+
+	doc, err := b.load(target)
+	if err != nil {
+		return "", err
+	}
+	node, err := resolvePointer(doc, pointer)
+	if err != nil {
+		return "", fmt.Errorf("referência %q não encontrada: %v", ref, err)
+	}
+
+	// Um $ref para components do próprio raiz (ex.: ../swagger.yaml#/components/...) continua interno
+	if doc == b.root {
+		b.done[key] = "#" + pointer
+		return "#" + pointer, nil
+	}
+
+	kind, name := componentName(pointer, target, path)
+	copied := deepCopyNode(node)
+	internal := b.place(kind, name, copied, key)
+	b.done[key] = internal
+
+	// Referências dentro do componente copiado são relativas ao arquivo de origem
+	if err := b.rewrite(copied, target, filepath.Dir(target), []string{"components", kind}, false); err != nil {
+		return "", err
+	}
+	b.report.Components = append(b.report.Components, bundledComponent{Source: key, Ref: internal})
+	return internal, nil
+}
+
+// Insere o componente em components.<kind>, renomeando com sufixo numérico quando
+// já existe um componente diferente com o mesmo nome
+func (b *bundler) place(kind, name string, node *yaml.Node, source string) string {
+	if b.components == nil {
+		b.components = ensureMapping(b.root, "components")
+	}
+	section := ensureMapping(b.components, kind)
+	prefix := "#/components/" + kind + "/"
+
+	candidate := name
+	for n := 2; ; n++ {
+		existing := mappingValue(section, candidate)
+		if existing == nil {
+			break
+		}
+		if nodesEqual(existing, node) {
+			return prefix + escapePointer(candidate)
+		}
+		candidate = name + strconv.Itoa(n)
+	}
+
+	if candidate != name {
+		other := b.origin[prefix+escapePointer(name)]
+		if other == "" {
+			other = "documento raiz"
+		}
+		b.report.Collisions = append(b.report.Collisions, bundleCollision{Name: name, Source: source, Existing: other, Renamed: candidate})
+	}
+
+	if _, ok := b.firstAdded[section]; !ok {
+		b.firstAdded[section] = len(section.Content)
+	}
+	section.Content = append(section.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: candidate},
+		node)
+	ref := prefix + escapePointer(candidate)
+	b.origin[ref] = source
+	return ref
+}
+
+// Carrega (uma única vez) um arquivo referenciado
+func (b *bundler) load(file string) (*yaml.Node, error) {
+	if doc, ok := b.files[file]; ok {
+		return doc, nil
+	}
+	node, err := loadSpecNode(file)
+	if err != nil {
+		return nil, err
+	}
+	doc := node
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	b.files[file] = doc
+	return doc, nil
+}
+
+// Separa "arquivo.yaml#/ponteiro" em arquivo e ponteiro
+func splitRef(ref string) (string, string) {
+	if i := strings.Index(ref, "#"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, ""
+}
+
+// Resolve um JSON Pointer (RFC 6901) sobre a árvore YAML
+func resolvePointer(doc *yaml.Node, pointer string) (*yaml.Node, error) {
+	node := doc
+	if pointer == "" || pointer == "/" {
+		return node, nil
+	}
+	for _, raw := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		segment := unescapePointer(raw)
+		switch node.Kind {
+		case yaml.MappingNode:
+			next := mappingValue(node, segment)
+			if next == nil {
+				return nil, fmt.Errorf("chave %q inexistente", segment)
+			}
+			node = next
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(node.Content) {
+				return nil, fmt.Errorf("índice %q inválido", segment)
+			}
+			node = node.Content[i]
+		default:
+			return nil, fmt.Errorf("não é possível navegar em %q", segment)
+		}
+	}
+	return node, nil
+}
+
+func unescapePointer(segment string) string {
+	if decoded, err := url.PathUnescape(segment); err == nil {
+		segment = decoded
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+}
+
+func escapePointer(segment string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(segment, "~", "~0"), "/", "~1")
+}
+
+// Tipos de componente pela chave onde a referência aparece
+var componentKindByKey = map[string]string{
+	"parameters":      "parameters",
+	"responses":       "responses",
+	"requestBody":     "requestBodies",
+	"requestBodies":   "requestBodies",
+	"headers":         "headers",
+	"examples":        "examples",
+	"securitySchemes": "securitySchemes",
+	"links":           "links",
+	"callbacks":       "callbacks",
+}
+
+// Descobre o tipo e o nome do componente: pelo ponteiro quando ele aponta para
+// components.<tipo>.<nome>, senão pelo local da referência e pelo nome do arquivo
+func componentName(pointer, file string, path []string) (string, string) {
+	segments := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	if len(segments) == 3 && segments[0] == "components" {
+		return segments[1], unescapePointer(segments[2])
+	}
+
+	kind := "schemas"
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i] == "schema" || path[i] == "properties" || path[i] == "items" {
+			break
+		}
+		if k, ok := componentKindByKey[path[i]]; ok {
+			kind = k
+			break
+		}
+	}
+
+	name := ""
+	if pointer != "" && len(segments) > 0 {
+		name = unescapePointer(segments[len(segments)-1])
+	}
+	if name == "" {
+		base := filepath.Base(file)
+		name = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	return kind, name
+}
+
+// Garante que a chave exista no mapping com um mapping como valor
+func ensureMapping(node *yaml.Node, key string) *yaml.Node {
+	if value := mappingValue(node, key); value != nil && value.Kind == yaml.MappingNode {
+		return value
+	}
+	value := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	return value
+}
+
+// Copia a árvore YAML para que o componente possa ser alterado sem afetar o arquivo de
+// origem; os aliases (*nome) passam a apontar para as âncoras da cópia
+func deepCopyNode(node *yaml.Node) *yaml.Node {
+	copies := map[*yaml.Node]*yaml.Node{}
+	copied := copyNode(node, copies)
+	for _, c := range copies {
+		if c.Alias != nil {
+			if anchor, ok := copies[c.Alias]; ok {
+				c.Alias = anchor
+			}
+		}
+	}
+	return copied
+}
+
+func copyNode(node *yaml.Node, copies map[*yaml.Node]*yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	copied := *node
+	copies[node] = &copied
+	copied.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		copied.Content[i] = copyNode(child, copies)
+	}
+	return &copied
+}
+
+// Compara duas árvores YAML pelo conteúdo, ignorando posições e comentários
+func nodesEqual(a, b *yaml.Node) bool {
+	var va, vb interface{}
+	if a.Decode(&va) != nil || b.Decode(&vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
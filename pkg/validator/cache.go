@@ -0,0 +1,121 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Diretório do cache mantido no projeto, só com --cache-dir .openapi-ci-cache; a busca
+// de especificações sempre o ignora
+const projectCacheDir = ".openapi-ci-cache"
+
+// Diretório padrão do cache em disco: openapi-ci no diretório de cache do usuário
+// (ex.: ~/.cache/openapi-ci), para que a execução não grave no diretório do projeto;
+// vazio (sem cache) quando o sistema não informa um
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "openapi-ci")
+}
+
+var (
+	cacheDir = defaultCacheDir()
+	noCache  bool
+)
+
+func registerCacheFlags(fs *flag.FlagSet) {
+	fs.StringVar(&cacheDir, "cache-dir", defaultCacheDir(), "diretório do cache de regras, índices e resultados (ex.: .openapi-ci-cache, para mantê-lo no projeto)")
+	fs.BoolVar(&noCache, "no-cache", false, "desativa o cache em disco")
+}
+
+// Cache em disco indexado pelo hash do conteúdo dos arquivos; um *diskCache nulo não guarda nada
+type diskCache struct {
+	dir string
+}
+
+func (s *runSettings) activeCache() *diskCache {
+	if s.noCache || cacheDir == "" {
+		return nil
+	}
+	return &diskCache{dir: cacheDir}
+}
+
+// Hash SHA-256 do conteúdo informado, usado como chave do cache
+func contentHash(parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *diskCache) path(kind, key, ext string) string {
+	return filepath.Join(c.dir, kind, key+ext)
+}
+
+// Lê uma entrada do cache; qualquer falha é tratada como ausência da entrada
+func (c *diskCache) getYAML(kind, key string, v interface{}) bool {
+	if c == nil {
+		return false
+	}
+	data, err := os.ReadFile(c.path(kind, key, ".yaml"))
+	if err != nil {
+		return false
+	}
+	return yaml.Unmarshal(data, v) == nil
+}
+
+// Grava uma entrada do cache; falhas de escrita não interrompem a execução
+func (c *diskCache) putYAML(kind, key string, v interface{}) {
+	if c == nil {
+		return
+	}
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return
+	}
+	c.write(c.path(kind, key, ".yaml"), data)
+}
+
+// Como getYAML, para as entradas guardadas em JSON (relatórios)
+func (c *diskCache) getJSON(kind, key string, v interface{}) bool {
+	if c == nil {
+		return false
+	}
+	data, err := os.ReadFile(c.path(kind, key, ".json"))
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+func (c *diskCache) putJSON(kind, key string, v interface{}) {
+	if c == nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	c.write(c.path(kind, key, ".json"), data)
+}
+
+func (c *diskCache) write(path string, data []byte) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return
+	}
+	os.Rename(tmp, path)
+}
@@ -0,0 +1,64 @@
+package validator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Sem --cache-dir, o cache fica no diretório de cache do usuário e nada é gravado no
+// diretório atual; .openapi-ci-cache só é usado quando informado
+func TestCacheDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	t.Setenv("HOME", home)
+	t.Setenv("LocalAppData", home)
+	userCache, err := os.UserCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	work := t.TempDir()
+	writeFiles(t, work, map[string]string{"api.yaml": string(mustReadFile(t, filepath.Join("testdata", "e2e", "valid", "api.yaml")))})
+	t.Chdir(work)
+
+	if code, out := runCommand(t, "validate", "api.yaml"); code != exitOK {
+		t.Fatalf("código %d\n%s", code, out)
+	}
+	if _, err := os.Stat(filepath.Join(userCache, "openapi-ci", "results")); err != nil {
+		t.Errorf("cache de resultados fora do diretório do usuário: %v", err)
+	}
+	if _, err := os.Stat(projectCacheDir); !os.IsNotExist(err) {
+		t.Errorf("o cache padrão foi gravado no diretório atual")
+	}
+
+	if code, out := runCommand(t, "validate", "--cache-dir", projectCacheDir, "api.yaml"); code != exitOK {
+		t.Fatalf("código %d\n%s", code, out)
+	}
+	if _, err := os.Stat(filepath.Join(projectCacheDir, "results")); err != nil {
+		t.Errorf("--cache-dir %s: %v", projectCacheDir, err)
+	}
+}
+
+// A API e o serve não gravam cache, mesmo com um diretório de cache configurado
+func TestAPIAndServeDoNotCache(t *testing.T) {
+	savedDir := cacheDir
+	defer func() { cacheDir = savedDir }()
+	cacheDir = t.TempDir()
+
+	rules := filepath.Join(t.TempDir(), "regras.yaml")
+	if err := os.WriteFile(rules, []byte("extends: ofb\nrules: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newSpecServer(&serveOptions{rulesets: stringList{"ofb", "interno=" + rules}, maxBody: defaultMaxBody, timeout: time.Minute, maxConcurrent: 1}); err != nil {
+		t.Fatal(err)
+	}
+	spec := mustReadFile(t, filepath.Join("testdata", "e2e", "valid", "api.yaml"))
+	if _, err := Validate(context.Background(), spec, Options{Source: "api.yaml", RulesFile: rules}); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(cacheDir); len(entries) > 0 {
+		t.Errorf("%d entradas gravadas no cache", len(entries))
+	}
+}
@@ -0,0 +1,245 @@
+package validator
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Flags do subcomando canonicalize
+type canonicalizeOptions struct {
+	output       string
+	outputFormat string
+	indent       int
+	noVerify     bool
+}
+
+func (o *canonicalizeOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.output, "o", "", "arquivo de saída (padrão: saída padrão)")
+	fs.StringVar(&o.outputFormat, "output-format", "", "formato do documento gerado: yaml ou json (padrão: pela extensão do arquivo de saída)")
+	fs.IntVar(&o.indent, "indent", defaultIndent, "indentação do YAML gerado")
+	fs.BoolVar(&o.noVerify, "no-verify", false, "não confere se a especificação resolvida continua igual à original")
+	registerRefFlags(fs)
+	registerHTTPFlags(fs)
+	registerEncodingFlag(fs)
+	registerSelectDocumentFlag(fs)
+	registerInputLimitFlag(fs)
+}
+
+var canonicalizeCommand = &command{
+	Name:    "canonicalize",
+	Args:    "<spec.yaml>",
+	Summary: "reescreve uma especificação na forma canônica",
+	Description: `Reescreve a especificação em uma forma canônica, sem mudar o significado:
+os tipos de components e os componentes de cada tipo ficam em ordem alfabética
+(paths e as demais chaves mantêm a ordem da fonte), as aspas são usadas apenas
+quando necessárias, a indentação é a de --indent, coleções vazias são escritas
+como {} e [] e as demais em estilo de bloco. Comentários são mantidos.
+
+Antes de gravar, a especificação original e a canônica são resolvidas e
+comparadas; qualquer diferença de conteúdo faz a execução falhar. Com as
+especificações versionadas na forma canônica, os diffs de revisão mostram
+apenas mudanças reais.`,
+	Examples: []string{
+		programName + " canonicalize swagger.yaml -o swagger.yaml",
+		programName + " canonicalize --indent 4 -o openapi.yaml swagger.yaml",
+		programName + " canonicalize swagger.yaml | diff swagger.yaml -",
+	},
+	Flags: func(fs *flag.FlagSet) { new(canonicalizeOptions).register(fs) },
+	Run:   runCanonicalize,
+}
+
+// Subcomando canonicalize: grava a especificação na forma canônica
+func runCanonicalize(c *command, args []string) int {
+	opts := &canonicalizeOptions{}
+	fs := c.newFlagSet()
+	opts.register(fs)
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	if err := checkHTTPFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkEncodingFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkSelectDocumentFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if fs.NArg() != 1 {
+		return c.usageError("esperado um argumento (especificação), recebidos %d", fs.NArg())
+	}
+	if opts.indent < 1 {
+		return c.usageError("valor inválido para --indent: %d", opts.indent)
+	}
+	inputFile := fs.Arg(0)
+	outputFile := opts.output
+	if outputFile == "" {
+		outputFile = inputFile // só para escolher o formato pela extensão
+	}
+	format, err := outputFormatFor(outputFile, opts.outputFormat)
+	if err != nil {
+		return c.usageError("%v", err)
+	}
+
+	data, err := readSpecFile(inputFile)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌", err)
+		return exitFailure
+	}
+	rootNode, err := parseSpecDocument(data, inputFile, selectDocument)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao processar", inputFile+":", err)
+		return exitFailure
+	}
+	canonicalizeNode(rootNode)
+	canonical, err := marshalSpec(rootNode, format, opts.indent, nil)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌", err)
+		return exitFailure
+	}
+
+	if !opts.noVerify {
+		if err := verifyCanonical(inputFile, canonical, format); err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+	}
+
+	if opts.output == "" {
+		stdout.Write(canonical)
+		return exitOK
+	}
+	if err := writeFileAtomic(opts.output, canonical); err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao salvar", opts.output+":", err)
+		return exitFailure
+	}
+	fmt.Fprintln(stdout, "✅ Especificação canônica salva em:", opts.output)
+	return exitOK
+}
+
+// Função para colocar a árvore na forma canônica: ordena components, remove os
+// estilos de aspas e de coleção escolhidos à mão e padroniza as coleções vazias
+func canonicalizeNode(rootNode *yaml.Node) {
+	doc := rootNode
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	if components := mappingValue(doc, "components"); components != nil && components.Kind == yaml.MappingNode {
+		sortMappingKeys(components)
+		for i := 1; i < len(components.Content); i += 2 {
+			if entries := components.Content[i]; entries.Kind == yaml.MappingNode && !strings.HasPrefix(components.Content[i-1].Value, "x-") {
+				sortMappingKeys(entries)
+			}
+		}
+	}
+	// Swagger 2.0: as seções equivalentes ficam na raiz
+	for _, section := range []string{"definitions", "parameters", "responses", "securityDefinitions"} {
+		if entries := mappingValue(doc, section); entries != nil && entries.Kind == yaml.MappingNode && isSwagger2(rootNode) {
+			sortMappingKeys(entries)
+		}
+	}
+	normalizeStyles(rootNode)
+}
+
+// Ordena as chaves de um objeto, mantendo cada valor com a sua chave
+func sortMappingKeys(node *yaml.Node) {
+	type pair struct{ key, value *yaml.Node }
+	pairs := make([]pair, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, pair{node.Content[i], node.Content[i+1]})
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].key.Value < pairs[j].key.Value })
+	content := make([]*yaml.Node, 0, len(node.Content))
+	for _, p := range pairs {
+		content = append(content, p.key, p.value)
+	}
+	node.Content = content
+}
+
+// Textos que ferramentas YAML 1.1 leriam como booleanos; continuam entre aspas
+var yaml11Bools = map[string]bool{"y": true, "n": true, "yes": true, "no": true, "on": true, "off": true}
+
+// Estilos padronizados: escalares simples (o encoder só coloca aspas quando o valor
+// seria lido com outro tipo) e blocos literais para textos com quebra de linha;
+// coleções vazias em {} ou [], as demais em bloco
+func normalizeStyles(node *yaml.Node) {
+	seen := map[*yaml.Node]bool{}
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n == nil || seen[n] {
+			return
+		}
+		seen[n] = true
+		switch n.Kind {
+		case yaml.ScalarNode:
+			n.Style = 0
+			switch {
+			case n.Tag == "!!str" && strings.Contains(n.Value, "\n"):
+				n.Style = yaml.LiteralStyle
+			case n.Tag == "!!str" && yaml11Bools[strings.ToLower(n.Value)]:
+				n.Style = yaml.DoubleQuotedStyle
+			}
+		case yaml.MappingNode, yaml.SequenceNode:
+			n.Style = 0
+			if len(n.Content) == 0 {
+				n.Style = yaml.FlowStyle
+			}
+		}
+		for _, child := range n.Content {
+			walk(child)
+		}
+	}
+	walk(node)
+}
+
+// Função para conferir que a forma canônica não muda o significado: a especificação
+// original e a canônica (gravada temporariamente ao lado da original, para que os
+// $refs relativos continuem válidos) são resolvidas e os conteúdos comparados
+func verifyCanonical(inputFile string, canonical []byte, format string) error {
+	ext := ".yaml"
+	if format == formatJSON {
+		ext = ".json"
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(inputFile), ".canonical-*"+ext)
+	if err != nil {
+		return fmt.Errorf("erro ao criar arquivo temporário: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(canonical); err != nil {
+		tmp.Close()
+		return fmt.Errorf("erro ao gravar arquivo temporário: %v", err)
+	}
+	tmp.Close()
+
+	ctx := context.Background()
+	settings := flagSettings()
+	original, err := indexAndResolve(ctx, settings, inputFile)
+	if err != nil {
+		return err
+	}
+	// A forma canônica é sempre gravada em UTF-8 e com um único documento, quaisquer
+	// que sejam --encoding e --select-document
+	rewritten, err := indexAndResolve(ctx, settings.with(func(s *runSettings) { s.encoding, s.document = "utf-8", 0 }), tmp.Name())
+	if err != nil {
+		return fmt.Errorf("a forma canônica não pôde ser resolvida: %v", err)
+	}
+	var a, b interface{}
+	if err := original.rootNode.Decode(&a); err != nil {
+		return fmt.Errorf("erro ao ler a especificação resolvida: %v", err)
+	}
+	if err := rewritten.rootNode.Decode(&b); err != nil {
+		return fmt.Errorf("erro ao ler a forma canônica resolvida: %v", err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		return fmt.Errorf("a forma canônica de %s difere da original depois de resolvida; nada foi gravado", inputFile)
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Regiões da especificação que mudaram em relação à versão anterior (--changed-only):
+// operações e path items de paths e webhooks, componentes alterados ou novos e os
+// componentes que eles referenciam. Com ela ativa, as regras só avaliam os nós dessas
+// regiões e as violações fora delas não são relatadas; o nível raiz (info, servers,
+// tags, security) continua valendo sempre.
+type changedScope struct {
+	prefixes   []string // JSONPaths das regiões
+	labels     []string // regiões alteradas, para o resumo
+	referenced int      // componentes incluídos só por serem referenciados
+}
+
+// Seções do nível raiz cujas entradas são regiões; o restante do documento é global
+var changedSections = map[string]bool{"paths": true, "webhooks": true, "components": true, "definitions": true, "parameters": true, "responses": true}
+
+// Função para montar o escopo a partir da comparação das duas versões resolvidas
+// (diffSpecs), de modo que a mudança num arquivo referenciado conte para as operações
+// e componentes que o usam. Uma operação de um path item novo ou com mudança fora das
+// operações leva o path item inteiro; as regiões e os componentes que elas referenciam
+// são localizados na árvore original da versão nova (newRoot), a das regras.
+func computeChangedScope(oldResolved, newResolved, newRoot *yaml.Node) *changedScope {
+	oldDoc, newDoc := documentContent(oldResolved), documentContent(newResolved)
+	doc := documentContent(newRoot)
+	scope := &changedScope{}
+	included := map[string]bool{}
+	var queue []*yaml.Node
+	add := func(prefix, label string) {
+		if included[prefix] {
+			return
+		}
+		included[prefix] = true
+		scope.prefixes = append(scope.prefixes, prefix)
+		scope.labels = append(scope.labels, label)
+		if matches, err := queryJSONPath(newRoot, prefix); err == nil && len(matches) == 1 {
+			queue = append(queue, matches[0].Node)
+		}
+	}
+
+	for _, c := range diffSpecs(oldResolved, newResolved).Changes {
+		if c.Type == "removed" {
+			continue // não há o que avaliar na versão nova
+		}
+		segments, _, err := parseJSONPath(c.JSONPath)
+		if err != nil {
+			continue
+		}
+		section := segments[0].name
+		if (section == "paths" || section == "webhooks") && len(segments) == 3 {
+			route := segments[1].name
+			oldItem := mappingValue(mappingValue(oldDoc, section), route)
+			item := mappingValue(mappingValue(newDoc, section), route)
+			if oldItem == nil || !nodesEqual(pathItemShared(oldItem), pathItemShared(item)) {
+				display := route
+				if section == "webhooks" {
+					display = "webhook:" + route
+				}
+				add(joinPath(joinPath("$", section), route), display)
+				continue
+			}
+		}
+		add(c.JSONPath, c.Location)
+	}
+
+	// Componentes referenciados pelas regiões, de forma transitiva
+	followComponentRefs(doc, queue, func(path string, target *yaml.Node) bool {
+		if included[path] {
+			return false
+		}
+		included[path] = true
+		scope.prefixes = append(scope.prefixes, path)
+		scope.referenced++
+		return true
+	})
+	return scope
+}
+
+// Função para seguir os $refs locais dos nós até os componentes do documento, de forma
+// transitiva: include recebe o JSONPath e o nó de cada componente alcançado e diz se
+// ele é novo (os $refs dele são seguidos) ou se já estava incluído
+func followComponentRefs(doc *yaml.Node, queue []*yaml.Node, include func(path string, target *yaml.Node) bool) {
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		forEachRef(node, func(ref *yaml.Node, _ string) {
+			file, pointer := splitRef(ref.Value)
+			if file != "" {
+				return
+			}
+			segments := pointerSegments(pointer)
+			var path string
+			depth := 2
+			switch {
+			case len(segments) >= 3 && segments[0] == "components":
+				path, depth = joinPath(joinPath("$.components", segments[1]), segments[2]), 3
+			case len(segments) >= 2 && changedSections[segments[0]] && segments[0] != "components":
+				path = joinPath(joinPath("$", segments[0]), segments[1])
+			default:
+				return
+			}
+			target, err := resolvePointer(doc, "/"+strings.Join(escapeSegments(segments[:depth]), "/"))
+			if err == nil && include(path, target) {
+				queue = append(queue, target)
+			}
+		})
+	}
+}
+
+// Chaves do path item fora das operações (parameters, servers, summary...)
+func pathItemShared(item *yaml.Node) *yaml.Node {
+	shared := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	forEachEntry(item, func(key string, value *yaml.Node) {
+		if !httpMethods[key] {
+			shared.Content = append(shared.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+		}
+	})
+	return shared
+}
+
+func escapeSegments(segments []string) []string {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = escapePointer(segment)
+	}
+	return escaped
+}
+
+// Indica se o JSONPath está no escopo: dentro de uma região ou fora das seções de
+// regiões (nível raiz, info, servers...)
+func (s *changedScope) contains(jsonPath string) bool {
+	jsonPath = strings.TrimSuffix(jsonPath, "~")
+	segments, _, err := parseJSONPath(jsonPath)
+	if err != nil || len(segments) < 2 || !changedSections[segments[0].name] {
+		return true
+	}
+	// components.securitySchemes e as demais seções de components, sem um componente, são globais
+	if segments[0].name == "components" && len(segments) < 3 {
+		return true
+	}
+	for _, prefix := range s.prefixes {
+		if jsonPath == prefix || strings.HasPrefix(jsonPath, prefix+".") || strings.HasPrefix(jsonPath, prefix+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// Nós selecionados que ficam no escopo; nunca nulo quando matches não é nulo, para
+// que a regra não passe a contar como não aplicável
+func (s *changedScope) matches(matches []pathMatch) []pathMatch {
+	kept := make([]pathMatch, 0, len(matches))
+	for _, m := range matches {
+		if s.contains(m.Path) {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+// Violações no escopo
+func (s *changedScope) filter(violations []Violation) []Violation {
+	kept := violations[:0:0]
+	for _, v := range violations {
+		if v.JSONPath == "" || s.contains(v.JSONPath) {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// Resumo do escopo da execução
+func (s *changedScope) summary(baseRef string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🧭 Validação restrita ao que mudou em relação a %s (--changed-only): ", baseRef)
+	if len(s.labels) == 0 {
+		b.WriteString("nenhum path ou componente mudou")
+	} else {
+		b.WriteString(strings.Join(s.labels, ", "))
+	}
+	if s.referenced > 0 {
+		fmt.Fprintf(&b, " e %d componente(s) referenciado(s)", s.referenced)
+	}
+	b.WriteString("; o nível raiz (info, servers, tags, security) foi conferido por inteiro.")
+	return b.String()
+}
+
+// Filtros da comparação de versões (diff --path-prefix e --component)
+type diffFilter struct {
+	pathPrefixes []string // paths e webhooks iguais ao prefixo ou abaixo dele
+	components   []string // nome do componente ("Consent") ou tipo e nome ("schemas.Consent")
+}
+
+func (f diffFilter) active() bool {
+	return len(f.pathPrefixes) > 0 || len(f.components) > 0
+}
+
+// Indica se o path está no prefixo: o próprio path ou um abaixo dele (/consents
+// abrange /consents/{consentId}, mas não /consentsV2)
+func routeHasPrefix(route, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return prefix == "" || route == prefix || strings.HasPrefix(route, prefix+"/")
+}
+
+// Indica se o componente (JSONPath em components ou nas seções do Swagger 2.0)
+// corresponde ao nome informado em --component
+func componentMatches(path, name string) bool {
+	segments, _, err := parseJSONPath(path)
+	if err != nil || len(segments) < 2 {
+		return false
+	}
+	kind, component := segments[len(segments)-2].name, segments[len(segments)-1].name
+	name = strings.TrimPrefix(name, "components.")
+	return name == component || name == kind+"."+component
+}
+
+// Função para montar o escopo da comparação a partir dos filtros, nas árvores originais
+// das duas versões (para que o que foi removido também entre): os paths e webhooks dos
+// prefixos, os componentes informados e, de forma transitiva, os componentes que eles
+// referenciam. Um componente que não existe em nenhuma das versões é erro, para que um
+// nome digitado errado não passe por comparação sem mudanças.
+func computeDiffScope(oldRoot, newRoot *yaml.Node, filter diffFilter) (*changedScope, error) {
+	scope := &changedScope{}
+	included := map[string]bool{}
+	for _, root := range []*yaml.Node{newRoot, oldRoot} {
+		doc := documentContent(root)
+		var queue []*yaml.Node
+		visited := map[string]bool{} // nesta versão, para seguir os $refs de cada uma
+		add := func(prefix, label string, node *yaml.Node, referenced bool) bool {
+			if visited[prefix] {
+				return false
+			}
+			visited[prefix] = true
+			if !included[prefix] {
+				included[prefix] = true
+				scope.prefixes = append(scope.prefixes, prefix)
+				if referenced {
+					scope.referenced++
+				} else {
+					scope.labels = append(scope.labels, label)
+				}
+			}
+			return true
+		}
+		for _, section := range []string{"paths", "webhooks"} {
+			forEachEntry(mappingValue(doc, section), func(route string, item *yaml.Node) {
+				for _, prefix := range filter.pathPrefixes {
+					if routeHasPrefix(route, prefix) {
+						label := route
+						if section == "webhooks" {
+							label = "webhook:" + route
+						}
+						if add(joinPath(joinPath("$", section), route), label, item, false) {
+							queue = append(queue, item)
+						}
+						return
+					}
+				}
+			})
+		}
+		for _, c := range diffComponents(doc) {
+			for _, name := range filter.components {
+				if componentMatches(c.path, name) {
+					if add(c.path, c.label, c.node, false) {
+						queue = append(queue, c.node)
+					}
+					break
+				}
+			}
+		}
+		followComponentRefs(doc, queue, func(path string, target *yaml.Node) bool {
+			return add(path, "", target, true)
+		})
+	}
+
+	for _, name := range filter.components {
+		found := false
+		for _, prefix := range scope.prefixes {
+			found = found || componentMatches(prefix, name)
+		}
+		if !found {
+			return nil, fmt.Errorf("componente %q não encontrado em nenhuma das versões", name)
+		}
+	}
+	return scope, nil
+}
+
+// Resumo do escopo da comparação, para que um resultado sem mudanças não seja lido
+// como uma comparação do documento inteiro
+func (s *changedScope) diffSummary(filter diffFilter) string {
+	var filters []string
+	for _, prefix := range filter.pathPrefixes {
+		filters = append(filters, "--path-prefix "+prefix)
+	}
+	for _, name := range filter.components {
+		filters = append(filters, "--component "+name)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Comparação restrita a %s: ", strings.Join(filters, ", "))
+	if len(s.labels) == 0 {
+		b.WriteString("nenhum path ou componente corresponde aos filtros")
+	} else {
+		b.WriteString(strings.Join(s.labels, ", "))
+	}
+	if s.referenced > 0 {
+		fmt.Fprintf(&b, " e %d componente(s) referenciado(s)", s.referenced)
+	}
+	b.WriteString("; o restante das especificações não foi comparado.")
+	return b.String()
+}
@@ -0,0 +1,121 @@
+package validator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Especificação multi-arquivo de testdata/e2e/multi em memória, com as trocas informadas
+// aplicadas a schemas/account.yaml
+func multiFixture(t *testing.T, accountReplacements ...string) MemFS {
+	t.Helper()
+	files := MemFS{}
+	for _, name := range []string{"api.yaml", "schemas/account.yaml", "schemas/common.yaml"} {
+		data, err := os.ReadFile(filepath.Join("testdata", "e2e", "multi", filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[name] = data
+	}
+	files["schemas/account.yaml"] = []byte(strings.NewReplacer(accountReplacements...).Replace(string(files["schemas/account.yaml"])))
+	return files
+}
+
+// Resolve a especificação e retorna o documento, já com a árvore original e a resolvida
+func openTestDocument(t *testing.T, files MemFS) (*specDocument, *resolvedSpec) {
+	t.Helper()
+	source, s, err := Options{Source: "api.yaml", FS: files}.settings(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := openSpecDocument(s, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec, err := doc.resolve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc, spec
+}
+
+// Uma mudança num arquivo referenciado conta para as operações que o usam
+func TestChangedScopeFollowsReferencedFiles(t *testing.T) {
+	_, oldSpec := openTestDocument(t, multiFixture(t))
+	newDoc, newSpec := openTestDocument(t, multiFixture(t, "accountId:\n      type: string", "accountId:\n      type: integer"))
+
+	scope := computeChangedScope(&oldSpec.rootNode, &newSpec.rootNode, newDoc.root)
+	if !containsString(scope.labels, "GET /accounts") {
+		t.Errorf("GET /accounts deveria estar no escopo: %q", scope.labels)
+	}
+	if !scope.contains("$.paths['/accounts'].get.responses['200']") {
+		t.Error("as violações da operação alterada deveriam ficar no escopo")
+	}
+}
+
+func TestChangedScopeUnchanged(t *testing.T) {
+	_, oldSpec := openTestDocument(t, multiFixture(t))
+	newDoc, newSpec := openTestDocument(t, multiFixture(t))
+
+	scope := computeChangedScope(&oldSpec.rootNode, &newSpec.rootNode, newDoc.root)
+	if len(scope.prefixes) != 0 {
+		t.Errorf("versões iguais não deveriam ter regiões: %q", scope.prefixes)
+	}
+}
+
+const changedSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas:
+    get:
+      responses:
+        '200': {description: ok}
+  /cartoes:
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Cartao'}
+components:
+  schemas:
+    Cartao:
+      type: object
+      properties:
+        limite: {$ref: '#/components/schemas/Valor'}
+    Valor: {type: string}
+    Outro: {type: string}
+`
+
+// Um path novo entra inteiro, com os componentes que ele referencia de forma
+// transitiva; os demais paths e componentes ficam fora e o nível raiz fica sempre
+func TestChangedScopeNewPath(t *testing.T) {
+	old := strings.Replace(changedSpec, "  /cartoes:", "  /cartoes-antigo:", 1)
+	_, oldSpec := openTestDocument(t, MemFS{"api.yaml": []byte(old)})
+	newDoc, newSpec := openTestDocument(t, MemFS{"api.yaml": []byte(changedSpec)})
+
+	scope := computeChangedScope(&oldSpec.rootNode, &newSpec.rootNode, newDoc.root)
+	if !containsString(scope.labels, "/cartoes") || scope.referenced != 2 {
+		t.Fatalf("escopo %q com %d referenciados, esperado /cartoes com 2 (Cartao e Valor)", scope.labels, scope.referenced)
+	}
+	for path, want := range map[string]bool{
+		"$.paths['/cartoes'].get.responses['200']": true,
+		"$.components.schemas.Valor":               true,
+		"$.info.contact":                           true,
+		"$.components.securitySchemes":             true,
+		"$.paths['/contas'].get":                   false,
+		"$.components.schemas.Outro.type":          false,
+	} {
+		if got := scope.contains(path); got != want {
+			t.Errorf("contains(%s) = %v, esperado %v", path, got, want)
+		}
+	}
+	kept := scope.filter([]Violation{{JSONPath: "$.paths['/contas'].get"}, {JSONPath: "$.components.schemas.Cartao"}, {}})
+	if len(kept) != 2 || kept[0].JSONPath != "$.components.schemas.Cartao" {
+		t.Errorf("violações mantidas: %v", kept)
+	}
+}
@@ -0,0 +1,251 @@
+package validator
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Códigos de saída
+const (
+	exitOK       = 0 // validação sem erros
+	exitFailure  = 1 // violações de severidade error ou falha de processamento
+	exitUsage    = 3 // uso incorreto da linha de comando ou regras inválidas
+	exitInternal = 4 // erro interno (pânico) do validador ou de uma biblioteca
+)
+
+// Nome do programa nas mensagens de uso
+const programName = "validator"
+
+// Um subcomando da CLI. O comando raiz tem o nome vazio.
+type command struct {
+	Name        string
+	Args        string // argumentos posicionais na linha de uso
+	Summary     string
+	Description string
+	Examples    []string
+	Hidden      bool
+	Flags       func(fs *flag.FlagSet) // registra as flags, usado também pela ajuda e pelo docs
+	Run         func(c *command, args []string) int
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		rootCommand,
+		validateCommand,
+		resolveCommand,
+		mergeCommand,
+		canonicalizeCommand,
+		exportCommand,
+		statsCommand,
+		graphCommand,
+		diffCommand,
+		snapshotCommand,
+		publishCommand,
+		crosscheckCommand,
+		aggregateCommand,
+		serveCommand,
+		hookCommand,
+		explainCommand,
+		rulesCommand,
+		initCommand,
+		docsCommand,
+	}
+}
+
+// Procura um subcomando pelo nome
+func findCommand(name string) *command {
+	for _, c := range commands {
+		if c.Name != "" && c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (c *command) fullName() string {
+	if c.Name == "" {
+		return programName
+	}
+	return programName + " " + c.Name
+}
+
+func (c *command) usageLine() string {
+	return strings.TrimSpace(c.fullName() + " [flags] " + c.Args)
+}
+
+// Cria o FlagSet do comando; erros de parse são tratados por parse
+func (c *command) newFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet(c.fullName(), flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&debugBundleDir, "debug-bundle", "", "em caso de erro interno, grava no diretório a entrada, as regras e a pilha para o relato do problema")
+	return fs
+}
+
+// Interpreta as flags; retorna false com o código de saída quando a execução deve parar
+// (ajuda solicitada ou flag inválida)
+func (c *command) parse(fs *flag.FlagSet, args []string) (int, bool) {
+	if recordFlags {
+		snapshotFlags(fs)
+	}
+	err := fs.Parse(args)
+	if err == nil {
+		return exitOK, true
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		c.printHelp(stdout)
+		return exitOK, false
+	}
+	return c.usageError("%v", err), false
+}
+
+// Dentro de Run, os valores das variáveis de flag antes da interpretação, restaurados
+// no fim para que a próxima chamada não herde as flags da anterior
+var (
+	recordFlags  bool
+	flagRestores []func()
+)
+
+func snapshotFlags(fs *flag.FlagSet) {
+	fs.VisitAll(func(f *flag.Flag) {
+		// As listas acumulam a cada Set e o tamanho zero não tem texto: ficam com uma cópia
+		switch value := f.Value.(type) {
+		case *stringList:
+			saved := append(stringList(nil), *value...)
+			flagRestores = append(flagRestores, func() { *value = saved })
+		case *byteSize:
+			saved := *value
+			flagRestores = append(flagRestores, func() { *value = saved })
+		default:
+			def := f.DefValue
+			flagRestores = append(flagRestores, func() { value.Set(def) })
+		}
+	})
+}
+
+// Imprime um erro de uso em stderr e retorna o código de saída correspondente
+func (c *command) usageError(format string, args ...interface{}) int {
+	fmt.Fprintf(stderr, "%s: %s\n", c.fullName(), fmt.Sprintf(format, args...))
+	fmt.Fprintf(stderr, "uso: %s\n", c.usageLine())
+	fmt.Fprintf(stderr, "Execute '%s --help' para mais detalhes.\n", c.fullName())
+	return exitUsage
+}
+
+// Imprime a ajuda completa do comando
+func (c *command) printHelp(w io.Writer) {
+	fmt.Fprintf(w, "uso: %s\n\n", c.usageLine())
+	fmt.Fprintln(w, c.Description)
+
+	if c.Name == "" {
+		fmt.Fprintln(w, "\nComandos:")
+		width := 0
+		for _, sub := range commands {
+			if !sub.Hidden && len(sub.Name) > width {
+				width = len(sub.Name)
+			}
+		}
+		for _, sub := range commands {
+			if sub.Name != "" && !sub.Hidden {
+				fmt.Fprintf(w, "  %-*s %s\n", width, sub.Name, sub.Summary)
+			}
+		}
+	}
+
+	if c.Flags != nil {
+		fs := c.newFlagSet()
+		c.Flags(fs)
+		fmt.Fprintln(w, "\nFlags:")
+		fs.SetOutput(w)
+		fs.PrintDefaults()
+	}
+
+	if len(c.Examples) > 0 {
+		fmt.Fprintln(w, "\nExemplos:")
+		for _, example := range c.Examples {
+			fmt.Fprintf(w, "  %s\n", example)
+		}
+	}
+
+	if c.Name == "" {
+		fmt.Fprintf(w, "\nExecute '%s <comando> --help' para a ajuda de um comando.\n", programName)
+	}
+}
+
+// Subcomando help: "validator help validate" equivale a "validator validate --help"
+func runHelp(args []string) int {
+	if len(args) == 0 {
+		rootCommand.printHelp(stdout)
+		return exitOK
+	}
+	c := findCommand(args[0])
+	if c == nil {
+		return rootCommand.usageError("comando desconhecido %q", args[0])
+	}
+	c.printHelp(stdout)
+	return exitOK
+}
+
+var docsCommand = &command{
+	Name:        "docs",
+	Summary:     "gera a referência da CLI em Markdown",
+	Description: "Emite a referência completa da linha de comando em Markdown, para a wiki interna.",
+	Hidden:      true,
+	Run:         runDocs,
+}
+
+// Subcomando oculto docs: referência completa da CLI em Markdown
+func runDocs(c *command, args []string) int {
+	fs := c.newFlagSet()
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	writeDocs(stdout)
+	return exitOK
+}
+
+func writeDocs(w io.Writer) {
+	fmt.Fprintf(w, "# Referência da CLI `%s`\n\n", programName)
+	fmt.Fprintln(w, "| Código de saída | Significado |")
+	fmt.Fprintln(w, "|---|---|")
+	fmt.Fprintf(w, "| %d | validação sem erros |\n", exitOK)
+	fmt.Fprintf(w, "| %d | violações de severidade error ou falha de processamento |\n", exitFailure)
+	fmt.Fprintf(w, "| %d | uso incorreto da linha de comando ou arquivo de regras inválido |\n", exitUsage)
+	fmt.Fprintf(w, "| %d | erro interno do validador (com --debug-bundle, diagnóstico gravado) |\n", exitInternal)
+
+	for _, c := range commands {
+		if c.Hidden {
+			continue
+		}
+		fmt.Fprintf(w, "\n## `%s`\n\n", c.fullName())
+		if c.Summary != "" {
+			fmt.Fprintf(w, "%s.\n\n", strings.ToUpper(c.Summary[:1])+c.Summary[1:])
+		}
+		fmt.Fprintf(w, "```\n%s\n```\n\n%s\n", c.usageLine(), c.Description)
+
+		if c.Flags != nil {
+			fs := c.newFlagSet()
+			c.Flags(fs)
+			fmt.Fprintln(w, "\n| Flag | Padrão | Descrição |")
+			fmt.Fprintln(w, "|---|---|---|")
+			fs.VisitAll(func(f *flag.Flag) {
+				def := "—"
+				if f.DefValue != "" {
+					def = "`" + f.DefValue + "`"
+				}
+				fmt.Fprintf(w, "| `--%s` | %s | %s |\n", f.Name, def, strings.ReplaceAll(f.Usage, "|", "\\|"))
+			})
+		}
+
+		if len(c.Examples) > 0 {
+			fmt.Fprintln(w, "\nExemplos:\n\n```")
+			for _, example := range c.Examples {
+				fmt.Fprintln(w, example)
+			}
+			fmt.Fprintln(w, "```")
+		}
+	}
+}
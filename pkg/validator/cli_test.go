@@ -0,0 +1,55 @@
+package validator
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Os resumos da lista de comandos começam todos na mesma coluna, mesmo com nomes
+// mais longos que os demais (canonicalize, crosscheck)
+func TestHelpAlignsCommands(t *testing.T) {
+	var out, errOut bytes.Buffer
+	Run([]string{"--help"}, &out, &errOut)
+	help := out.String() + errOut.String()
+	start := strings.Index(help, "\nComandos:\n")
+	if start < 0 {
+		t.Fatalf("ajuda sem a lista de comandos:\n%s", help)
+	}
+	column := -1
+	for _, line := range strings.Split(help[start+len("\nComandos:\n"):], "\n") {
+		if !strings.HasPrefix(line, "  ") {
+			break
+		}
+		name := strings.Fields(line)[0]
+		summary := len(line) - len(strings.TrimLeft(line[2+len(name):], " "))
+		if column < 0 {
+			column = summary
+		}
+		if summary != column {
+			t.Errorf("resumo de %s na coluna %d, esperado %d:\n%s", name, summary, column, line)
+		}
+	}
+}
+
+// O comando principal aceita o nome de um pacote embarcado no lugar do arquivo de
+// regras, como validate --rules
+func TestRootAcceptsBuiltinRuleset(t *testing.T) {
+	spec := filepath.Join("testdata", "e2e", "valid", "api.yaml")
+	var out, errOut bytes.Buffer
+	code := Run([]string{"--no-cache", "--output-dir", t.TempDir(), spec, "ofb"}, &out, &errOut)
+	if code != exitOK {
+		t.Errorf("exit %d\n%s%s", code, out.String(), errOut.String())
+	}
+}
+
+// resolve aceita --no-cache e --cache-dir (o cache HTTP dos $refs remotos)
+func TestResolveAcceptsCacheFlags(t *testing.T) {
+	spec := filepath.Join("testdata", "e2e", "valid", "api.yaml")
+	var out, errOut bytes.Buffer
+	code := Run([]string{"resolve", "--no-cache", "--check", spec}, &out, &errOut)
+	if code != exitOK {
+		t.Errorf("exit %d\n%s%s", code, out.String(), errOut.String())
+	}
+}
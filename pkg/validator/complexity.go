@@ -0,0 +1,258 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Limites de complexidade dos schemas; acima deles a validação gera violações
+// (0 desativa a conferência)
+var (
+	maxSchemaDepth int
+	maxProperties  int
+)
+
+func registerComplexityFlags(fs *flag.FlagSet) {
+	fs.IntVar(&maxSchemaDepth, "max-schema-depth", 0, "aviso quando os schemas de uma operação passam desta profundidade de aninhamento (0 = sem limite)")
+	fs.IntVar(&maxProperties, "max-properties", 0, "aviso quando um schema declara mais propriedades que o limite (0 = sem limite)")
+}
+
+// Métricas de tamanho e complexidade dos schemas de uma operação, no documento resolvido
+type operationMetrics struct {
+	Operation    string `json:"operation"`    // METHOD path
+	SchemaNodes  int    `json:"schemaNodes"`  // schemas no documento resolvido, contando cada uso
+	MaxDepth     int    `json:"maxDepth"`     // aninhamento máximo de properties/items
+	Components   int    `json:"components"`   // componentes distintos referenciados, direta ou indiretamente
+	ExampleBytes int    `json:"exampleBytes"` // tamanho estimado, em JSON, do maior exemplo de resposta
+
+	path string // JSONPath da operação, para as violações
+	line int
+}
+
+// Tamanho de um schema já medido: nós e profundidade
+type schemaSize struct {
+	nodes, depth int
+}
+
+// Medidor dos schemas do documento resolvido. Cada schema é medido uma vez (o mesmo
+// nó aparece em cada uso do componente). Os $refs locais que sobram, como os que o
+// resolvedor mantém para fechar um ciclo, são seguidos, e o conjunto active corta o
+// ciclo quando o caminho volta a um schema que já está sendo medido.
+type schemaMeter struct {
+	doc      *yaml.Node
+	measured map[*yaml.Node]schemaSize
+	active   map[*yaml.Node]bool
+}
+
+func newSchemaMeter(root *yaml.Node) *schemaMeter {
+	return &schemaMeter{doc: documentContent(root), measured: map[*yaml.Node]schemaSize{}, active: map[*yaml.Node]bool{}}
+}
+
+func (m *schemaMeter) measure(schema *yaml.Node) schemaSize {
+	if schema == nil || schema.Kind != yaml.MappingNode || m.active[schema] {
+		return schemaSize{}
+	}
+	if size, ok := m.measured[schema]; ok {
+		return size
+	}
+	m.active[schema] = true
+	defer delete(m.active, schema)
+
+	size := schemaSize{nodes: 1, depth: 1}
+	if ref := mappingValue(schema, "$ref"); ref != nil {
+		if file, pointer := splitRef(ref.Value); file == "" {
+			if target, err := resolvePointer(m.doc, pointer); err == nil && !m.active[target] {
+				size = m.measure(target)
+			}
+		}
+		m.measured[schema] = size
+		return size
+	}
+	nested := func(sub *yaml.Node, level int) {
+		s := m.measure(sub)
+		size.nodes += s.nodes
+		if s.depth+level > size.depth {
+			size.depth = s.depth + level
+		}
+	}
+	for i := 0; i+1 < len(schema.Content); i += 2 {
+		key, value := schema.Content[i].Value, schema.Content[i+1]
+		switch key {
+		case "properties", "patternProperties":
+			for j := 1; j < len(value.Content); j += 2 {
+				nested(value.Content[j], 1)
+			}
+		case "items", "additionalProperties":
+			nested(value, 1)
+		case "prefixItems":
+			for _, sub := range value.Content {
+				nested(sub, 1)
+			}
+		case "allOf", "anyOf", "oneOf":
+			// As composições ficam no mesmo nível do schema que as declara
+			for _, sub := range value.Content {
+				nested(sub, 0)
+			}
+		case "not":
+			nested(value, 0)
+		}
+	}
+	m.measured[schema] = size
+	return size
+}
+
+// Função para calcular as métricas de cada operação. O tamanho e a profundidade vêm
+// do documento resolvido; os componentes, dos $refs do documento original.
+func complexityMetrics(original, resolved *yaml.Node) []operationMetrics {
+	meter := newSchemaMeter(resolved)
+	validator := newSchemaValidator(resolved)
+	originalDoc, resolvedDoc := documentContent(original), documentContent(resolved)
+
+	metrics := []operationMetrics{}
+	for _, op := range listOperations(resolved) {
+		section := "paths"
+		if op.Webhook {
+			section = "webhooks"
+		}
+		item := mappingValue(mappingValue(resolvedDoc, section), op.Path)
+		operation := mappingValue(item, op.Method)
+		entry := operationMetrics{Operation: strings.ToUpper(op.Method) + " " + op.displayPath(), path: joinPath(joinPath("$."+section, op.Path), op.Method)}
+		if operation != nil {
+			entry.line = operation.Line
+		}
+
+		for _, schema := range operationSchemas(item, operation) {
+			size := meter.measure(schema)
+			entry.SchemaNodes += size.nodes
+			if size.depth > entry.MaxDepth {
+				entry.MaxDepth = size.depth
+			}
+		}
+
+		// Maior exemplo entre as respostas de sucesso, gerado a partir dos schemas
+		if responses := mappingValue(operation, "responses"); responses != nil && responses.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(responses.Content); i += 2 {
+				if !strings.HasPrefix(responses.Content[i].Value, "2") {
+					continue
+				}
+				for _, schema := range contentSchemas(mappingValue(responses.Content[i+1], "content")) {
+					value, ok := newSampleGenerator(validator, false).generate(schema, "$", 0)
+					if !ok {
+						continue
+					}
+					if data, err := encodeJSON(value); err == nil && len(data) > entry.ExampleBytes {
+						entry.ExampleBytes = len(data)
+					}
+				}
+			}
+		}
+
+		originalItem := mappingValue(mappingValue(originalDoc, section), op.Path)
+		refs := map[string]bool{}
+		collectComponentRefs(originalDoc, mappingValue(originalItem, "parameters"), refs)
+		collectComponentRefs(originalDoc, mappingValue(originalItem, op.Method), refs)
+		entry.Components = len(refs)
+		metrics = append(metrics, entry)
+	}
+	return metrics
+}
+
+// Schemas de uma operação: parâmetros (do path item e da operação), corpo da
+// requisição e respostas, com os cabeçalhos
+func operationSchemas(item, operation *yaml.Node) []*yaml.Node {
+	var schemas []*yaml.Node
+	for _, owner := range []*yaml.Node{item, operation} {
+		if params := mappingValue(owner, "parameters"); params != nil {
+			for _, param := range params.Content {
+				if schema := parameterSchemaOrContent(param); schema != nil {
+					schemas = append(schemas, schema)
+				}
+			}
+		}
+	}
+	schemas = append(schemas, contentSchemas(mappingValue(mappingValue(operation, "requestBody"), "content"))...)
+	if responses := mappingValue(operation, "responses"); responses != nil && responses.Kind == yaml.MappingNode {
+		for i := 1; i < len(responses.Content); i += 2 {
+			response := responses.Content[i]
+			schemas = append(schemas, contentSchemas(mappingValue(response, "content"))...)
+			if headers := mappingValue(response, "headers"); headers != nil && headers.Kind == yaml.MappingNode {
+				for j := 1; j < len(headers.Content); j += 2 {
+					if schema := parameterSchemaOrContent(headers.Content[j]); schema != nil {
+						schemas = append(schemas, schema)
+					}
+				}
+			}
+		}
+	}
+	return schemas
+}
+
+// Schemas de um objeto content, na ordem dos media types
+func contentSchemas(content *yaml.Node) []*yaml.Node {
+	var schemas []*yaml.Node
+	if content != nil && content.Kind == yaml.MappingNode {
+		for i := 1; i < len(content.Content); i += 2 {
+			if schema := mappingValue(content.Content[i], "schema"); schema != nil {
+				schemas = append(schemas, schema)
+			}
+		}
+	}
+	return schemas
+}
+
+// Função para reunir os $refs alcançáveis a partir do nó; os locais são seguidos
+// (uma vez cada, o que também encerra os ciclos) e os externos contam pelo endereço
+func collectComponentRefs(doc, node *yaml.Node, refs map[string]bool) {
+	if node == nil {
+		return
+	}
+	if node.Kind == yaml.MappingNode {
+		if ref := mappingValue(node, "$ref"); ref != nil && ref.Kind == yaml.ScalarNode {
+			if refs[ref.Value] {
+				return
+			}
+			refs[ref.Value] = true
+			if file, pointer := splitRef(ref.Value); file == "" {
+				if target, err := resolvePointer(doc, pointer); err == nil {
+					collectComponentRefs(doc, target, refs)
+				}
+			}
+			return
+		}
+	}
+	for _, child := range node.Content {
+		collectComponentRefs(doc, child, refs)
+	}
+}
+
+// Violações dos limites de complexidade: operações com schemas profundos demais e
+// schemas com propriedades demais (relatados uma vez, mesmo quando reaproveitados)
+func complexityViolations(s *runSettings, root *yaml.Node, metrics []operationMetrics) []Violation {
+	var violations []Violation
+	if s.maxSchemaDepth > 0 {
+		for _, m := range metrics {
+			if m.MaxDepth > s.maxSchemaDepth {
+				violations = append(violations, Violation{RuleID: "max-schema-depth", Severity: "warning", Message: fmt.Sprintf("Os schemas de %s têm %d níveis de aninhamento (limite: %d).", m.Operation, m.MaxDepth, s.maxSchemaDepth), JSONPath: m.path, Line: m.line})
+			}
+		}
+	}
+	if s.maxProperties > 0 {
+		reported := map[string]bool{}
+		visitSchemas(root, nil, func(schema *yaml.Node, path string) {
+			properties := mappingValue(schema, "properties")
+			if properties == nil || len(properties.Content)/2 <= s.maxProperties {
+				return
+			}
+			key := fmt.Sprintf("%d:%d", properties.Line, properties.Column)
+			if reported[key] {
+				return
+			}
+			reported[key] = true
+			violations = append(violations, Violation{RuleID: "max-properties", Severity: "warning", Message: fmt.Sprintf("O schema declara %d propriedades (limite: %d).", len(properties.Content)/2, s.maxProperties), JSONPath: path, Line: schema.Line})
+		})
+	}
+	return violations
+}
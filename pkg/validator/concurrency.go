@@ -0,0 +1,166 @@
+package validator
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Com --concurrency, as regras são avaliadas em paralelo e os resultados do given
+// de uma mesma regra são divididos em partes de ruleMatchChunk nós. O documento é
+// só lido durante a avaliação, e as violações saem na mesma ordem da avaliação
+// sequencial (regras por nome, nós na ordem do given). --concurrency 1 avalia uma
+// regra de cada vez, para depuração.
+var ruleConcurrency = runtime.NumCPU()
+
+const ruleMatchChunk = 256
+
+func registerConcurrencyFlag(fs *flag.FlagSet) {
+	fs.IntVar(&ruleConcurrency, "concurrency", ruleConcurrency, "regras avaliadas ao mesmo tempo (1 avalia uma de cada vez, para depuração)")
+}
+
+func checkConcurrencyFlag() error {
+	if ruleConcurrency < 1 {
+		return fmt.Errorf("valor inválido para --concurrency: %d", ruleConcurrency)
+	}
+	return nil
+}
+
+// Resultado de uma regra; done é falso quando a execução foi cancelada antes
+type ruleOutcome struct {
+	violations    []Violation
+	matched       []string
+	notApplicable bool // o when ou o scope da regra não vale para nenhum nó
+	done          bool
+}
+
+// Parte dos nós selecionados por uma regra
+type ruleChunk struct {
+	rule, lo, hi int
+}
+
+type chunkResult struct {
+	chunk      int
+	violations []Violation
+}
+
+// Função para avaliar as regras sobre o documento, com até --concurrency goroutines.
+// Com cancelamento, retorna o erro do contexto e os resultados das regras que
+// chegaram a terminar.
+func evaluateRules(ctx context.Context, s *runSettings, root *yaml.Node, names []string, rules map[string]interface{}) ([]ruleOutcome, error) {
+	outcomes := make([]ruleOutcome, len(names))
+	scope := newGuardScope(root)
+	scope.changed = s.changedScope
+	if s.ruleConcurrency <= 1 {
+		for i, name := range names {
+			if err := ctx.Err(); err != nil {
+				return outcomes, err
+			}
+			ruleData, _ := rules[name].(map[string]interface{})
+			outcomes[i] = evaluateRule(scope, name, ruleData)
+		}
+		return outcomes, nil
+	}
+
+	// Primeira etapa: o given de cada regra; as regras sem nós já terminam aqui
+	compiled := make([]*compiledRule, len(names))
+	matches := make([][]pathMatch, len(names))
+	queried := make([]bool, len(names))
+	inParallel(ctx, s.ruleConcurrency, len(names), func(i int) {
+		ruleData, _ := rules[names[i]].(map[string]interface{})
+		rule := compileRule(names[i], ruleData)
+		if rule == nil {
+			outcomes[i].done = true
+			return
+		}
+		found, ok := rule.query(scope)
+		switch {
+		case !ok:
+			outcomes[i].done = true
+		case found == nil && rule.conditional():
+			outcomes[i].notApplicable, outcomes[i].done = true, true
+		case len(found) == 0:
+			outcomes[i].violations, outcomes[i].done = rule.missing(), true
+		default:
+			compiled[i], matches[i] = rule, found
+			queried[i] = true
+		}
+	})
+
+	// Segunda etapa: a função da regra sobre cada parte dos nós
+	var chunks []ruleChunk
+	pending := make([]int, len(names))
+	for i := range names {
+		if !queried[i] {
+			continue
+		}
+		for lo := 0; lo < len(matches[i]); lo += ruleMatchChunk {
+			hi := lo + ruleMatchChunk
+			if hi > len(matches[i]) {
+				hi = len(matches[i])
+			}
+			chunks = append(chunks, ruleChunk{rule: i, lo: lo, hi: hi})
+			pending[i]++
+		}
+	}
+	results := make(chan chunkResult, s.ruleConcurrency)
+	go func() {
+		inParallel(ctx, s.ruleConcurrency, len(chunks), func(k int) {
+			c := chunks[k]
+			results <- chunkResult{chunk: k, violations: compiled[c.rule].check(matches[c.rule][c.lo:c.hi])}
+		})
+		close(results)
+	}()
+	found := make([][]Violation, len(chunks))
+	for result := range results {
+		found[result.chunk] = result.violations
+		pending[chunks[result.chunk].rule]--
+	}
+
+	// As partes são reunidas na ordem do given
+	for k, c := range chunks {
+		if pending[c.rule] == 0 {
+			outcomes[c.rule].violations = append(outcomes[c.rule].violations, found[k]...)
+		}
+	}
+	for i := range names {
+		if queried[i] && pending[i] == 0 {
+			outcomes[i].matched = make([]string, 0, len(matches[i]))
+			for _, m := range matches[i] {
+				outcomes[i].matched = append(outcomes[i].matched, m.Path)
+			}
+			outcomes[i].done = true
+		}
+	}
+	return outcomes, ctx.Err()
+}
+
+// Executa fn para cada índice de 0 a n-1 em até workers goroutines; com o contexto
+// cancelado, os índices restantes não são distribuídos
+func inParallel(ctx context.Context, workers, n int, fn func(i int)) {
+	tasks := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range tasks {
+				fn(i)
+			}
+		}()
+	}
+dispatch:
+	for i := 0; i < n; i++ {
+		select {
+		case tasks <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(tasks)
+	wg.Wait()
+}
@@ -0,0 +1,119 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Regras do pacote ofb e uma que seleciona todas as propriedades, para que o given
+// passe de ruleMatchChunk nós e seja dividido em partes
+const concurrencyRules = `extends: [ofb]
+rules:
+  propriedade-com-exemplo:
+    description: As propriedades devem ter exemplo
+    severity: warning
+    given: $..properties[*]
+    then: {field: example, function: truthy}
+`
+
+// Especificação grande já lida, com as regras ordenadas como na validação
+func concurrencyFixture(tb testing.TB) (*runSettings, *yaml.Node, []string, map[string]interface{}) {
+	tb.Helper()
+	s := flagSettings()
+	s.tracker = nil
+	rules, err := loadRulesData(s, []byte(concurrencyRules), "regras", ".")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var doc yaml.Node
+	if err := yaml.Unmarshal(generateLargeSpec(200), &doc); err != nil {
+		tb.Fatal(err)
+	}
+	return s, &doc, names, rules
+}
+
+// Com qualquer número de goroutines, o resultado é o da avaliação sequencial: mesmas
+// violações, na mesma ordem, e os mesmos nós selecionados
+func TestEvaluateRulesDeterministic(t *testing.T) {
+	s, root, names, rules := concurrencyFixture(t)
+	s.ruleConcurrency = 1
+	want, err := evaluateRules(context.Background(), s, root, names, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := sort.SearchStrings(names, "propriedade-com-exemplo")
+	if n := len(want[i].violations); n <= ruleMatchChunk {
+		t.Fatalf("%d violações da regra de exemplo, esperado mais de %d para dividir o given", n, ruleMatchChunk)
+	}
+	for _, workers := range []int{2, 4, 16} {
+		for run := 0; run < 5; run++ {
+			s.ruleConcurrency = workers
+			got, err := evaluateRules(context.Background(), s, root, names, rules)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("--concurrency %d difere da avaliação sequencial", workers)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.ruleConcurrency = 4
+	if _, err := evaluateRules(ctx, s, root, names, rules); err != context.Canceled {
+		t.Errorf("com o contexto cancelado: %v", err)
+	}
+}
+
+// A saída do validate não depende de --concurrency; valores menores que 1 são erro de uso
+func TestConcurrencyFlag(t *testing.T) {
+	spec := filepath.Join("testdata", "e2e", "violations", "api.yaml")
+	var outputs []string
+	for _, workers := range []string{"1", "8"} {
+		var out, errOut bytes.Buffer
+		Run([]string{"validate", "--no-cache", "--format", "json", "--concurrency", workers, spec}, &out, &errOut)
+		if !strings.Contains(out.String(), `"violations"`) {
+			t.Fatalf("--concurrency %s sem o relatório:\n%s%s", workers, out.String(), errOut.String())
+		}
+		outputs = append(outputs, out.String())
+	}
+	if outputs[0] != outputs[1] {
+		t.Errorf("a saída muda com --concurrency\n--- 1\n%s\n--- 8\n%s", outputs[0], outputs[1])
+	}
+
+	var out, errOut bytes.Buffer
+	if code := Run([]string{"validate", "--concurrency", "0", spec}, &out, &errOut); code != exitUsage || !strings.Contains(out.String()+errOut.String(), "valor inválido para --concurrency: 0") {
+		t.Errorf("exit %d, esperado erro de uso\n%s%s", code, out.String(), errOut.String())
+	}
+}
+
+// Avaliação das regras na especificação grande com 1, 2, 4 e 8 goroutines; o ganho só
+// aparece em máquinas com vários núcleos (go test -bench EvaluateRules -benchmem)
+func BenchmarkEvaluateRules(b *testing.B) {
+	s, root, names, rules := concurrencyFixture(b)
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("concurrency-%d", workers), func(b *testing.B) {
+			s.ruleConcurrency = workers
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := evaluateRules(context.Background(), s, root, names, rules); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Nome do arquivo de configuração do projeto
+const projectConfigFile = ".openapi-ci.yaml"
+
+// Configuração do projeto lida de .openapi-ci.yaml
+type projectConfig struct {
+	Spec    string   `yaml:"spec"`
+	Rules   string   `yaml:"rules"`
+	BaseRef string   `yaml:"baseRef"`
+	Strip   []string `yaml:"strip"` // nós removidos pelo resolve (ver --strip)
+	// Registro de extensões usado na validação (ver --extensions)
+	Extensions string `yaml:"extensions"`
+	// Padrões (estilo .gitignore) dos arquivos de especificação considerados pelo hook
+	SpecPaths []string `yaml:"specPaths"`
+}
+
+// Função para carregar a configuração do projeto; retorna nil quando o arquivo não existe
+func loadProjectConfig(path string) (*projectConfig, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = expandEnv(data, path); err != nil {
+		return nil, err
+	}
+
+	var config projectConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("erro ao fazer unmarshal da configuração %s: %v", path, err)
+	}
+	config.Spec, config.Rules, config.Extensions = configPath(config.Spec), configPath(config.Rules), configPath(config.Extensions)
+	return &config, nil
+}
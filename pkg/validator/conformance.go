@@ -0,0 +1,219 @@
+package validator
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Item da checklist de conformidade do OFB
+type requirement struct {
+	ID    string `yaml:"id"`
+	Title string `yaml:"title"`
+}
+
+// Função para ler a checklist de conformidade: a lista requirements (ou a própria
+// raiz) com id e title de cada requisito, na ordem em que devem aparecer na matriz
+func loadChecklist(path string) ([]requirement, error) {
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Requirements []requirement `yaml:"requirements"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil || doc.Requirements == nil {
+		var list []requirement
+		if listErr := yaml.Unmarshal(data, &list); listErr != nil {
+			if err == nil {
+				err = listErr
+			}
+			return nil, fmt.Errorf("erro ao ler a checklist %s: %v", path, err)
+		}
+		doc.Requirements = list
+	}
+	seen := map[string]bool{}
+	for i, r := range doc.Requirements {
+		if r.ID == "" {
+			return nil, fmt.Errorf("checklist %s: o item %d não tem id", path, i+1)
+		}
+		if seen[r.ID] {
+			return nil, fmt.Errorf("checklist %s: o requisito %s aparece mais de uma vez", path, r.ID)
+		}
+		seen[r.ID] = true
+	}
+	return doc.Requirements, nil
+}
+
+// Requisitos da checklist que a regra cobre (x-requirement-id: um id ou uma lista)
+func ruleRequirements(ruleData map[string]interface{}) []string {
+	switch value := ruleData["x-requirement-id"].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		var ids []string
+		for _, item := range value {
+			if id, ok := item.(string); ok {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	}
+	return nil
+}
+
+// Linha da matriz de conformidade: um requisito, as regras que o cobrem e o
+// resultado em cada especificação
+type conformanceRow struct {
+	requirement
+	Rules    []string
+	Listed   bool     // presente na checklist
+	Statuses []string // pass, fail, not-verified ou error, na ordem das especificações
+	Failures []int    // violações das regras do requisito, por especificação
+}
+
+// Função para montar a matriz de conformidade: valida cada especificação com as
+// regras e cruza as violações de cada regra com os requisitos que ela cobre
+func conformanceMatrix(ctx context.Context, specs []string, rules map[string]interface{}, checklist []requirement) ([]conformanceRow, error) {
+	byRequirement := map[string][]string{}
+	for name, rule := range rules {
+		ruleData, ok := rule.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, id := range ruleRequirements(ruleData) {
+			byRequirement[id] = append(byRequirement[id], name)
+		}
+	}
+
+	var rows []conformanceRow
+	listed := map[string]bool{}
+	for _, r := range checklist {
+		listed[r.ID] = true
+		rows = append(rows, conformanceRow{requirement: r, Listed: true})
+	}
+	var extra []string
+	for id := range byRequirement {
+		if !listed[id] {
+			extra = append(extra, id)
+		}
+	}
+	sort.Strings(extra)
+	for _, id := range extra {
+		rows = append(rows, conformanceRow{requirement: requirement{ID: id}})
+	}
+	for i := range rows {
+		rows[i].Rules = byRequirement[rows[i].ID]
+		sort.Strings(rows[i].Rules)
+	}
+
+	for _, spec := range specs {
+		violations, err := validateOpenAPI(ctx, flagSettings(), spec, rules)
+		if err != nil && isCancellation(err) {
+			return nil, err
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "⚠️  %s não foi validada: %s\n", spec, firstLine(err.Error()))
+		}
+		counts := map[string]int{}
+		for _, v := range violations {
+			counts[v.RuleID]++
+		}
+		for i := range rows {
+			row := &rows[i]
+			status, failures := "pass", 0
+			for _, name := range row.Rules {
+				failures += counts[name]
+			}
+			switch {
+			case len(row.Rules) == 0:
+				status = "not-verified"
+			case err != nil:
+				status = "error"
+			case failures > 0:
+				status = "fail"
+			}
+			row.Statuses = append(row.Statuses, status)
+			row.Failures = append(row.Failures, failures)
+		}
+	}
+	return rows, nil
+}
+
+// Função para gravar a matriz em CSV ou, com extensão .md (ou sem arquivo), em Markdown
+func writeConformance(output string, specs []string, rows []conformanceRow) error {
+	return writeExportOutput(output, func(w io.Writer) error {
+		if strings.EqualFold(filepath.Ext(output), ".csv") {
+			return conformanceCSV(w, specs, rows)
+		}
+		conformanceMarkdown(w, specs, rows)
+		return nil
+	})
+}
+
+func conformanceCSV(w io.Writer, specs []string, rows []conformanceRow) error {
+	out := csv.NewWriter(w)
+	header := []string{"requirement", "title", "rules"}
+	for _, spec := range specs {
+		header = append(header, reportPath(spec))
+	}
+	out.Write(header)
+	for _, row := range rows {
+		record := []string{row.ID, row.Title, strings.Join(row.Rules, " ")}
+		record = append(record, row.Statuses...)
+		out.Write(record)
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// Status da célula na tabela Markdown
+var conformanceLabels = map[string]string{
+	"pass":         "✅ aprovado",
+	"fail":         "❌ reprovado",
+	"not-verified": "⚠️ não verificado",
+	"error":        "💥 erro",
+}
+
+func conformanceMarkdown(w io.Writer, specs []string, rows []conformanceRow) {
+	fmt.Fprintln(w, "## Matriz de conformidade")
+	fmt.Fprintln(w)
+	header, align := "| Requisito | Descrição | Regras |", "|---|---|---|"
+	for _, spec := range specs {
+		header += " `" + markdownCell(reportPath(spec)) + "` |"
+		align += "---|"
+	}
+	fmt.Fprintln(w, header)
+	fmt.Fprintln(w, align)
+	unlisted := 0
+	for _, row := range rows {
+		id := markdownCell(row.ID)
+		if !row.Listed {
+			id += " ¹"
+			unlisted++
+		}
+		rules := "-"
+		if len(row.Rules) > 0 {
+			rules = "`" + strings.Join(row.Rules, "`, `") + "`"
+		}
+		line := fmt.Sprintf("| %s | %s | %s |", id, markdownCell(row.Title), rules)
+		for i, status := range row.Statuses {
+			label := conformanceLabels[status]
+			if status == "fail" {
+				label += fmt.Sprintf(" (%d)", row.Failures[i])
+			}
+			line += " " + label + " |"
+		}
+		fmt.Fprintln(w, line)
+	}
+	if unlisted > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "¹ Requisito citado em x-requirement-id que não está na checklist.")
+	}
+}
@@ -0,0 +1,174 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Com --coverage, a validação também mede quanto da especificação as regras examinaram
+var reportCoverage bool
+
+func registerCoverageFlag(fs *flag.FlagSet) {
+	fs.BoolVar(&reportCoverage, "coverage", false, "relata a cobertura das regras: operações, parâmetros, schemas e respostas examinados por ao menos uma regra")
+}
+
+// Tipos de local conferidos na cobertura, na ordem do resumo
+var coverageKinds = []string{"operations", "parameters", "schemas", "responses"}
+
+var coverageLabels = map[string]string{"operations": "operações", "parameters": "parâmetros", "schemas": "schemas", "responses": "respostas"}
+
+// Cobertura das regras sobre uma especificação
+type ruleCoverage struct {
+	Percent             float64                  `json:"percent"`
+	Checked             int                      `json:"checked"`
+	Total               int                      `json:"total"`
+	ByKind              map[string]coverageCount `json:"byKind"`
+	UncheckedOperations []string                 `json:"uncheckedOperations"`
+	UnmatchedRules      []string                 `json:"unmatchedRules"` // regras cujo given não encontrou nada
+}
+
+type coverageCount struct {
+	Checked int `json:"checked"`
+	Total   int `json:"total"`
+}
+
+// Registro dos caminhos examinados por cada regra durante a avaliação
+type coverageRecorder struct {
+	matched map[string]bool
+	rules   map[string]int // caminhos examinados por regra
+}
+
+func newCoverageRecorder() *coverageRecorder {
+	return &coverageRecorder{matched: map[string]bool{}, rules: map[string]int{}}
+}
+
+// Registra os caminhos examinados pela regra; sem --coverage o registro é nulo
+func (r *coverageRecorder) record(rule string, paths []string) {
+	if r == nil {
+		return
+	}
+	r.rules[rule] += len(paths)
+	for _, path := range paths {
+		r.matched[path] = true
+	}
+}
+
+// Função para calcular a cobertura: um local conta como examinado quando alguma regra
+// encontrou o próprio nó ou um nó dentro dele
+func (r *coverageRecorder) result(root *yaml.Node) *ruleCoverage {
+	if r == nil {
+		return nil
+	}
+	matched := make([]string, 0, len(r.matched))
+	for path := range r.matched {
+		matched = append(matched, path)
+	}
+	sort.Strings(matched)
+	covered := func(location string) bool {
+		for i := sort.SearchStrings(matched, location); i < len(matched) && strings.HasPrefix(matched[i], location); i++ {
+			rest := matched[i][len(location):]
+			if rest == "" || rest[0] == '.' || rest[0] == '[' {
+				return true
+			}
+		}
+		return false
+	}
+
+	coverage := &ruleCoverage{ByKind: map[string]coverageCount{}, UncheckedOperations: []string{}, UnmatchedRules: []string{}}
+	count := func(kind, location string) bool {
+		c := coverage.ByKind[kind]
+		c.Total++
+		ok := covered(location)
+		if ok {
+			c.Checked++
+		}
+		coverage.ByKind[kind] = c
+		return ok
+	}
+
+	doc := documentContent(root)
+	for _, op := range listOperations(root) {
+		section := "paths"
+		if op.Webhook {
+			section = "webhooks"
+		}
+		itemPath := joinPath("$."+section, op.Path)
+		opPath := joinPath(itemPath, op.Method)
+		item := mappingValue(mappingValue(doc, section), op.Path)
+		operation := mappingValue(item, op.Method)
+
+		checked := count("operations", opPath)
+		for _, owner := range []struct {
+			node *yaml.Node
+			path string
+		}{{item, itemPath}, {operation, opPath}} {
+			if params := mappingValue(owner.node, "parameters"); params != nil && params.Kind == yaml.SequenceNode {
+				for i := range params.Content {
+					count("parameters", fmt.Sprintf("%s[%d]", joinPath(owner.path, "parameters"), i))
+				}
+			}
+		}
+		if responses := mappingValue(operation, "responses"); responses != nil && responses.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(responses.Content); i += 2 {
+				count("responses", joinPath(joinPath(opPath, "responses"), responses.Content[i].Value))
+			}
+		}
+		if !checked {
+			coverage.UncheckedOperations = append(coverage.UncheckedOperations, strings.ToUpper(op.Method)+" "+op.displayPath())
+		}
+	}
+	schemasPath, schemas := "$.components.schemas", mappingValue(mappingValue(doc, "components"), "schemas")
+	if isSwagger2(root) {
+		schemasPath, schemas = "$.definitions", mappingValue(doc, "definitions")
+	}
+	if schemas != nil && schemas.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(schemas.Content); i += 2 {
+			count("schemas", joinPath(schemasPath, schemas.Content[i].Value))
+		}
+	}
+
+	for _, c := range coverage.ByKind {
+		coverage.Checked += c.Checked
+		coverage.Total += c.Total
+	}
+	if coverage.Total > 0 {
+		coverage.Percent = math.Round(float64(coverage.Checked)*1000/float64(coverage.Total)) / 10
+	}
+	for rule, n := range r.rules {
+		if n == 0 {
+			coverage.UnmatchedRules = append(coverage.UnmatchedRules, rule)
+		}
+	}
+	sort.Strings(coverage.UnmatchedRules)
+	return coverage
+}
+
+// Resumo da cobertura no texto da validação
+func (c *ruleCoverage) String() string {
+	var parts []string
+	for _, kind := range coverageKinds {
+		if n, ok := c.ByKind[kind]; ok {
+			parts = append(parts, fmt.Sprintf("%s %d/%d", coverageLabels[kind], n.Checked, n.Total))
+		}
+	}
+	return fmt.Sprintf("%.1f%% (%d de %d locais: %s)", c.Percent, c.Checked, c.Total, strings.Join(parts, ", "))
+}
+
+// Imprime a cobertura após o resumo da validação em texto
+func printCoverage(c *ruleCoverage) {
+	if c == nil {
+		return
+	}
+	fmt.Fprintln(stdout, "📊 Cobertura das regras:", c)
+	if len(c.UncheckedOperations) > 0 {
+		fmt.Fprintln(stdout, "   Operações não examinadas por nenhuma regra:", strings.Join(c.UncheckedOperations, ", "))
+	}
+	if len(c.UnmatchedRules) > 0 {
+		fmt.Fprintln(stdout, "   Regras que não encontraram nenhum nó:", strings.Join(c.UnmatchedRules, ", "))
+	}
+}
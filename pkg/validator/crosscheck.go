@@ -0,0 +1,527 @@
+package validator
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Flags do subcomando crosscheck
+type crosscheckOptions struct {
+	manifest          string
+	consent           string
+	permissionsSchema string
+	sharedSchemas     stringList
+	format            string
+}
+
+func (o *crosscheckOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.manifest, "manifest", "", "manifesto com as APIs conferidas (além das informadas como argumentos)")
+	fs.StringVar(&o.consent, "consent", "", "especificação de consentimentos (padrão: a que declara o schema de --permissions-schema)")
+	fs.StringVar(&o.permissionsSchema, "permissions-schema", "EnumConsentPermissions", "schema da especificação de consentimentos com o enum das permissões")
+	fs.Var(&o.sharedSchemas, "shared-schema", "schema que deve ser idêntico em todas as especificações que o declaram (padrão: ResponseError); pode ser repetida")
+	fs.StringVar(&o.format, "format", "text", "formato da saída: text ou json")
+	registerEncodingFlag(fs)
+	registerSelectDocumentFlag(fs)
+	registerInputLimitFlag(fs)
+	registerOverlayFlags(fs)
+	registerConvertFlag(fs)
+}
+
+var crosscheckCommand = &command{
+	Name:    "crosscheck",
+	Args:    "[spec.yaml...]",
+	Summary: "confere invariantes entre especificações (permissões, erros e cabeçalhos)",
+	Description: `Confere, entre as especificações informadas (e as do manifesto), o que
+cada uma sozinha não consegue garantir:
+
+  permissões   os valores de x-required-permissions existem no enum de
+               permissões da especificação de consentimentos
+  schemas      os schemas de --shared-schema (ResponseError por padrão) são
+               estruturalmente idênticos em todas as especificações
+  cabeçalhos   os cabeçalhos comuns (components.headers e parâmetros com
+               in: header) têm a mesma definição em todas as especificações
+
+As comparações seguem os $refs locais e ignoram descrições, títulos, exemplos e
+extensões x-. Cada divergência indica as duas especificações envolvidas e os
+valores encontrados em cada uma; havendo divergências, a execução falha.`,
+	Examples: []string{
+		programName + " crosscheck consents.yaml accounts.yaml credit-cards.yaml",
+		programName + " crosscheck --manifest apis.yaml",
+		programName + " crosscheck --shared-schema ResponseError --shared-schema Meta --format json --manifest apis.yaml",
+	},
+	Flags: func(fs *flag.FlagSet) { new(crosscheckOptions).register(fs) },
+	Run:   runCrosscheck,
+}
+
+// Divergência entre duas especificações
+type crossFinding struct {
+	Check   string `json:"check"` // permissions, shared-schema ou header
+	Spec    string `json:"spec"`
+	Other   string `json:"other"`
+	Path    string `json:"path,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// Relatório do crosscheck (--format json)
+type crosscheckReport struct {
+	Specs    []string       `json:"specs"`
+	Consent  string         `json:"consent,omitempty"`
+	Findings []crossFinding `json:"findings"`
+}
+
+// Especificação carregada para as conferências
+type crossSpec struct {
+	label string
+	doc   *yaml.Node
+	v2    bool
+}
+
+// Subcomando crosscheck: invariantes entre especificações
+func runCrosscheck(c *command, args []string) int {
+	opts := &crosscheckOptions{}
+	fs := c.newFlagSet()
+	opts.register(fs)
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	if err := checkOverlayFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkEncodingFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkSelectDocumentFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if opts.format != "text" && opts.format != "json" {
+		return c.usageError("formato %q inválido para crosscheck (use text ou json)", opts.format)
+	}
+
+	type input struct{ label, file string }
+	var inputs []input
+	if opts.manifest != "" {
+		m, err := loadManifest(opts.manifest)
+		if err != nil {
+			fmt.Fprintln(stdout, "❌ Erro ao carregar o manifesto:", err)
+			return exitFailure
+		}
+		for _, entry := range m.APIs {
+			inputs = append(inputs, input{label: entry.Name, file: entry.Spec})
+		}
+	}
+	for _, file := range fs.Args() {
+		inputs = append(inputs, input{label: reportPath(file), file: file})
+	}
+	if opts.consent != "" {
+		found := false
+		for _, in := range inputs {
+			found = found || in.file == opts.consent
+		}
+		if !found {
+			inputs = append(inputs, input{label: reportPath(opts.consent), file: opts.consent})
+		}
+	}
+	if len(inputs) < 2 {
+		return c.usageError("informe ao menos duas especificações (argumentos ou --manifest), recebidas %d", len(inputs))
+	}
+	shared := []string(opts.sharedSchemas)
+	if len(shared) == 0 {
+		shared = []string{"ResponseError"}
+	}
+
+	var specs []*crossSpec
+	var consent *crossSpec
+	for _, in := range inputs {
+		root, err := loadRootSpec(in.file)
+		if err != nil {
+			fmt.Fprintln(stdout, "❌ Erro ao processar", in.file+":", err)
+			return exitFailure
+		}
+		spec := &crossSpec{label: in.label, doc: documentContent(root), v2: isSwagger2(root)}
+		specs = append(specs, spec)
+		if in.file == opts.consent {
+			consent = spec
+		}
+	}
+	if consent == nil && opts.consent == "" {
+		for _, spec := range specs {
+			if spec.schema(opts.permissionsSchema) != nil {
+				consent = spec
+				break
+			}
+		}
+	}
+
+	if consent != nil && consent.schema(opts.permissionsSchema) == nil {
+		fmt.Fprintf(stdout, "❌ %s não declara o schema %s (--permissions-schema).\n", consent.label, opts.permissionsSchema)
+		return exitFailure
+	}
+
+	report := crosscheckReport{Findings: []crossFinding{}}
+	for _, spec := range specs {
+		report.Specs = append(report.Specs, spec.label)
+	}
+	if consent != nil {
+		report.Consent = consent.label
+	}
+	report.Findings = append(report.Findings, permissionFindings(specs, consent, opts.permissionsSchema)...)
+	for _, name := range shared {
+		var defs []crossDefinition
+		for _, spec := range specs {
+			if schema := spec.schema(name); schema != nil {
+				defs = append(defs, crossDefinition{spec: spec, node: schema, path: spec.schemaPath(name)})
+			}
+		}
+		report.Findings = append(report.Findings, definitionFindings("shared-schema", "O schema "+name, defs)...)
+	}
+	report.Findings = append(report.Findings, headerFindings(specs)...)
+
+	if opts.format == "json" {
+		if err := printJSONReport(report); err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+	} else {
+		if consent == nil {
+			fmt.Fprintf(stdout, "⚠️  Nenhuma especificação declara o schema %s; as permissões não foram conferidas.\n", opts.permissionsSchema)
+		}
+		for _, f := range report.Findings {
+			location := f.Path
+			if f.Line > 0 {
+				location = fmt.Sprintf("%s, linha %d", f.Path, f.Line)
+			}
+			fmt.Fprintf(stdout, "❌ [%s] %s x %s: %s", f.Check, f.Spec, f.Other, f.Message)
+			if location != "" {
+				fmt.Fprintf(stdout, " (%s)", location)
+			}
+			fmt.Fprintln(stdout)
+		}
+		fmt.Fprintf(stdout, "🔎 crosscheck: %d especificações, %d divergências.\n", len(specs), len(report.Findings))
+	}
+	if len(report.Findings) > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// Schema declarado em components.schemas (ou definitions, no Swagger 2.0)
+func (s *crossSpec) schema(name string) *yaml.Node {
+	if s.v2 {
+		return mappingValue(mappingValue(s.doc, "definitions"), name)
+	}
+	return mappingValue(mappingValue(mappingValue(s.doc, "components"), "schemas"), name)
+}
+
+func (s *crossSpec) schemaPath(name string) string {
+	if s.v2 {
+		return joinPath("$.definitions", name)
+	}
+	return joinPath("$.components.schemas", name)
+}
+
+// Segue os $refs locais até o objeto referenciado
+func (s *crossSpec) deref(node *yaml.Node) *yaml.Node {
+	for depth := 0; node != nil && depth < 16; depth++ {
+		ref := mappingValue(node, "$ref")
+		if ref == nil {
+			return node
+		}
+		file, pointer := splitRef(ref.Value)
+		if file != "" {
+			return node
+		}
+		target, err := resolvePointer(s.doc, pointer)
+		if err != nil {
+			return node
+		}
+		node = target
+	}
+	return node
+}
+
+// Função para conferir as permissões: cada valor de x-required-permissions, em
+// qualquer especificação, precisa existir no enum da especificação de consentimentos
+func permissionFindings(specs []*crossSpec, consent *crossSpec, schemaName string) []crossFinding {
+	if consent == nil {
+		return nil
+	}
+	schema := consent.deref(consent.schema(schemaName))
+	if items := mappingValue(schema, "items"); mappingValue(schema, "enum") == nil && items != nil {
+		schema = consent.deref(items)
+	}
+	allowed := map[string]bool{}
+	var values []string
+	if enum := mappingValue(schema, "enum"); enum != nil {
+		for _, value := range enum.Content {
+			allowed[value.Value] = true
+			values = append(values, value.Value)
+		}
+	}
+
+	var findings []crossFinding
+	for _, spec := range specs {
+		walkExtension(spec.doc, "x-required-permissions", func(value *yaml.Node, path string) {
+			items := []*yaml.Node{value}
+			if value.Kind == yaml.SequenceNode {
+				items = value.Content
+			}
+			for _, item := range items {
+				if item.Kind != yaml.ScalarNode || allowed[item.Value] {
+					continue
+				}
+				findings = append(findings, crossFinding{
+					Check:   "permissions",
+					Spec:    spec.label,
+					Other:   consent.label,
+					Path:    path,
+					Line:    item.Line,
+					Message: fmt.Sprintf("a permissão %q não existe no enum %s de %s%s", item.Value, schemaName, consent.label, suggestionSuffix(item.Value, values)),
+				})
+			}
+		})
+	}
+	return findings
+}
+
+// Chama visit para cada valor da extensão no documento, fora dos exemplos
+func walkExtension(doc *yaml.Node, key string, visit func(value *yaml.Node, path string)) {
+	var walk func(node *yaml.Node, path string)
+	walk = func(node *yaml.Node, path string) {
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				k, v := node.Content[i].Value, node.Content[i+1]
+				switch k {
+				case key:
+					visit(v, joinPath(path, k))
+				case "example", "examples":
+				default:
+					walk(v, joinPath(path, k))
+				}
+			}
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				walk(item, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	}
+	if doc != nil {
+		walk(doc, "$")
+	}
+}
+
+// Definição de um mesmo item (schema ou cabeçalho) em uma especificação
+type crossDefinition struct {
+	spec *crossSpec
+	node *yaml.Node
+	path string
+}
+
+// Função para comparar as definições de um item: a primeira especificação que o
+// declara é a referência e cada outra que diverge dela gera uma divergência, com os
+// primeiros pontos em que os valores diferem
+func definitionFindings(check, subject string, defs []crossDefinition) []crossFinding {
+	if len(defs) < 2 {
+		return nil
+	}
+	reference := structureOf(defs[0].spec, defs[0].node, false, map[*yaml.Node]bool{})
+	var findings []crossFinding
+	for _, def := range defs[1:] {
+		var diffs []string
+		diffStructures("$", reference, structureOf(def.spec, def.node, false, map[*yaml.Node]bool{}), defs[0].spec.label, def.spec.label, &diffs)
+		if len(diffs) == 0 {
+			continue
+		}
+		message := fmt.Sprintf("%s difere: %s", subject, strings.Join(diffs, "; "))
+		if len(diffs) == maxStructureDiffs {
+			message += "; ..."
+		}
+		findings = append(findings, crossFinding{Check: check, Spec: def.spec.label, Other: defs[0].spec.label, Path: def.path, Line: def.node.Line, Message: message})
+	}
+	return findings
+}
+
+// Função para conferir os cabeçalhos comuns: os de components.headers e os parâmetros
+// in: header de components.parameters (parameters no Swagger 2.0), pelo nome sem
+// diferenciar maiúsculas
+func headerFindings(specs []*crossSpec) []crossFinding {
+	defs := map[string][]crossDefinition{}
+	names := map[string]string{}
+	for _, spec := range specs {
+		seen := map[string]bool{}
+		add := func(name string, node *yaml.Node, path string) {
+			key := strings.ToLower(name)
+			if seen[key] {
+				return // o mesmo cabeçalho como header e como parâmetro na mesma especificação
+			}
+			seen[key] = true
+			if _, ok := names[key]; !ok {
+				names[key] = name
+			}
+			defs[key] = append(defs[key], crossDefinition{spec: spec, node: node, path: path})
+		}
+		parameters, parametersPath := mappingValue(mappingValue(spec.doc, "components"), "parameters"), "$.components.parameters"
+		if spec.v2 {
+			parameters, parametersPath = mappingValue(spec.doc, "parameters"), "$.parameters"
+		}
+		if headers := mappingValue(mappingValue(spec.doc, "components"), "headers"); headers != nil && headers.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(headers.Content); i += 2 {
+				header := spec.deref(headers.Content[i+1])
+				add(headers.Content[i].Value, headerDefinition(header), joinPath("$.components.headers", headers.Content[i].Value))
+			}
+		}
+		if parameters != nil && parameters.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(parameters.Content); i += 2 {
+				param := spec.deref(parameters.Content[i+1])
+				in, name := mappingValue(param, "in"), mappingValue(param, "name")
+				if in == nil || in.Value != "header" || name == nil {
+					continue
+				}
+				add(name.Value, headerDefinition(param), joinPath(parametersPath, parameters.Content[i].Value))
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(defs))
+	for key := range defs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var findings []crossFinding
+	for _, key := range keys {
+		findings = append(findings, definitionFindings("header", "O cabeçalho "+names[key], defs[key])...)
+	}
+	return findings
+}
+
+// Parte comparável de um cabeçalho: sem name e in, que um objeto header não tem e
+// um parâmetro in: header tem
+func headerDefinition(node *yaml.Node) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return node
+	}
+	copied := *node
+	copied.Content = nil
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if key := node.Content[i].Value; key != "name" && key != "in" {
+			copied.Content = append(copied.Content, node.Content[i], node.Content[i+1])
+		}
+	}
+	return &copied
+}
+
+// Chaves que não mudam a estrutura e ficam fora das comparações
+var nonStructuralKeys = map[string]bool{"description": true, "summary": true, "title": true, "example": true, "examples": true}
+
+// Chaves cujos valores são mapas de nomes (propriedades, cabeçalhos), não de palavras-chave
+var namedMapKeys = map[string]bool{"properties": true, "headers": true, "patternProperties": true, "$defs": true, "definitions": true, "dependentSchemas": true}
+
+// Função para converter o nó em valores Go comparáveis, seguindo os $refs locais (um
+// $ref que volta a um schema em andamento fica como texto) e sem as chaves
+// não estruturais; names indica que as chaves do mapa são nomes de propriedades
+func structureOf(spec *crossSpec, node *yaml.Node, names bool, active map[*yaml.Node]bool) interface{} {
+	if node == nil {
+		return nil
+	}
+	switch node.Kind {
+	case yaml.AliasNode:
+		return structureOf(spec, node.Alias, names, active)
+	case yaml.MappingNode:
+		if ref := mappingValue(node, "$ref"); ref != nil && !names {
+			target := spec.deref(node)
+			if target == node || active[target] {
+				return map[string]interface{}{"$ref": ref.Value}
+			}
+			active[target] = true
+			defer delete(active, target)
+			return structureOf(spec, target, false, active)
+		}
+		result := map[string]interface{}{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if !names && (nonStructuralKeys[key] || strings.HasPrefix(key, "x-")) {
+				continue
+			}
+			result[key] = structureOf(spec, node.Content[i+1], !names && namedMapKeys[key], active)
+		}
+		return result
+	case yaml.SequenceNode:
+		result := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			result = append(result, structureOf(spec, item, false, active))
+		}
+		return result
+	}
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return node.Value
+	}
+	return value
+}
+
+// Quantidade máxima de diferenças descritas por divergência
+const maxStructureDiffs = 5
+
+// Função para descrever os pontos em que a e b diferem, com o valor de cada lado
+func diffStructures(path string, a, b interface{}, labelA, labelB string, diffs *[]string) {
+	if len(*diffs) >= maxStructureDiffs {
+		return
+	}
+	ma, okA := a.(map[string]interface{})
+	mb, okB := b.(map[string]interface{})
+	if okA && okB {
+		keys := map[string]bool{}
+		for k := range ma {
+			keys[k] = true
+		}
+		for k := range mb {
+			keys[k] = true
+		}
+		for _, k := range sortedKeys(keys) {
+			va, inA := ma[k]
+			vb, inB := mb[k]
+			switch {
+			case !inA:
+				*diffs = append(*diffs, fmt.Sprintf("%s existe apenas em %s (%s)", joinPath(path, k), labelB, compactJSON(vb)))
+			case !inB:
+				*diffs = append(*diffs, fmt.Sprintf("%s existe apenas em %s (%s)", joinPath(path, k), labelA, compactJSON(va)))
+			default:
+				diffStructures(joinPath(path, k), va, vb, labelA, labelB, diffs)
+			}
+			if len(*diffs) >= maxStructureDiffs {
+				return
+			}
+		}
+		return
+	}
+	sa, okA := a.([]interface{})
+	sb, okB := b.([]interface{})
+	if okA && okB && len(sa) == len(sb) {
+		for i := range sa {
+			diffStructures(fmt.Sprintf("%s[%d]", path, i), sa[i], sb[i], labelA, labelB, diffs)
+		}
+		return
+	}
+	if !reflect.DeepEqual(a, b) {
+		*diffs = append(*diffs, fmt.Sprintf("%s é %s em %s e %s em %s", path, compactJSON(a), labelA, compactJSON(b), labelB))
+	}
+}
+
+// Valor em JSON de uma linha, para as mensagens
+func compactJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	if len(data) > 120 {
+		return string(data[:117]) + "..."
+	}
+	return string(data)
+}
@@ -0,0 +1,84 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// crosscheck aponta a permissão que não existe no enum do consentimento (com a
+// sugestão), o schema compartilhado e o cabeçalho que divergem entre as
+// especificações, ignorando diferenças só de descrição
+func TestCrosscheck(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"consents.yaml": `openapi: 3.0.3
+info: {title: Consentimentos, version: 1.0.0}
+paths: {}
+components:
+  headers:
+    XFapiInteractionId: {schema: {type: string, format: uuid}}
+  schemas:
+    EnumConsentPermissions:
+      type: string
+      enum: [ACCOUNTS_READ, ACCOUNTS_BALANCES_READ]
+    ResponseError:
+      type: object
+      required: [errors]
+      properties:
+        errors: {type: array, items: {type: object}}
+`,
+		"accounts.yaml": `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /accounts:
+    get:
+      x-required-permissions: [ACCOUNTS_READ, ACCOUNTS_BALANCE_READ]
+      responses:
+        '200': {description: ok}
+components:
+  headers:
+    XFapiInteractionId: {schema: {type: string}, description: ignorada}
+  schemas:
+    ResponseError:
+      type: object
+      required: [errors, meta]
+      properties:
+        errors: {type: array, items: {type: object}}
+`,
+	})
+	consents := filepath.Join(dir, "consents.yaml")
+	accounts := filepath.Join(dir, "accounts.yaml")
+
+	code, out := runCommand(t, "crosscheck", "--format", "json", consents, accounts)
+	if code != exitFailure {
+		t.Fatalf("código %d\n%s", code, out)
+	}
+	var report crosscheckReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	c, a := reportPath(consents), reportPath(accounts)
+	if report.Consent != c {
+		t.Errorf("consentimento %q, esperado %q", report.Consent, c)
+	}
+	var got []string
+	for _, f := range report.Findings {
+		got = append(got, fmt.Sprintf("%s %s:%d %s", f.Check, f.Spec, f.Line, f.Message))
+	}
+	want := []string{
+		"permissions " + a + `:6 a permissão "ACCOUNTS_BALANCE_READ" não existe no enum EnumConsentPermissions de ` + c + " (você quis dizer ACCOUNTS_BALANCES_READ?)",
+		"shared-schema " + a + `:14 O schema ResponseError difere: $.required é ["errors"] em ` + c + ` e ["errors","meta"] em ` + a,
+		"header " + a + ":11 O cabeçalho XFapiInteractionId difere: $.schema.format existe apenas em " + c + ` ("uuid")`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("divergências:\n%s\nesperado:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if code, out := runCommand(t, "crosscheck", consents); code != exitUsage || !strings.Contains(out, "informe ao menos duas especificações") {
+		t.Errorf("uma especificação: código %d\n%s", code, out)
+	}
+}
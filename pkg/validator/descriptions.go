@@ -0,0 +1,278 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Locais das descrições conferidos pela função descriptions
+var descriptionKinds = []string{"operations", "parameters", "schemas"}
+
+// Textos que não descrevem nada, usados quando as opções não dizem
+var defaultDescriptionPlaceholders = []string{
+	`(?i)^(todo|tbd|fixme|xxx|wip)\b`,
+	`(?i)^(descri[cç][aã]o|description|texto|text|teste|test)\.?$`,
+	`(?i)lorem ipsum`,
+	`^[.\-_?!\s]*$`,
+}
+
+// Opções da função descriptions
+type descriptionRequirement struct {
+	minLength     int              // caracteres, sem contar os espaços das pontas
+	placeholders  []*regexp.Regexp // textos de preenchimento
+	maxDuplicates int              // locais distintos com o mesmo texto antes de apontar (0 desativa)
+	kinds         map[string]bool
+}
+
+// Função para ler as opções da função descriptions: o tamanho mínimo (padrão: 10), as
+// expressões dos textos de preenchimento (padrão: TODO, "descrição", lorem ipsum...), em
+// quantos locais distintos o mesmo texto pode aparecer (padrão: 3; 0 desativa) e quais
+// descrições conferir (padrão: operations, parameters e schemas)
+//
+//	then:
+//	  function: descriptions
+//	  functionOptions:
+//	    minLength: 10
+//	    placeholders: ["(?i)^todo"]
+//	    maxDuplicates: 3
+//	    in: [operations, parameters, schemas]
+func parseDescriptionOptions(options map[string]interface{}) (*descriptionRequirement, []string) {
+	req := &descriptionRequirement{minLength: 10, maxDuplicates: 3, kinds: map[string]bool{}}
+	for _, kind := range descriptionKinds {
+		req.kinds[kind] = true
+	}
+	var problems []string
+	for _, option := range []struct {
+		key    string
+		target *int
+	}{{"minLength", &req.minLength}, {"maxDuplicates", &req.maxDuplicates}} {
+		raw, ok := options[option.key]
+		if !ok {
+			continue
+		}
+		n, isInt := raw.(int)
+		if f, isFloat := raw.(float64); isFloat && f == float64(int(f)) {
+			n, isInt = int(f), true
+		}
+		if !isInt || n < 0 {
+			problems = append(problems, fmt.Sprintf("functionOptions.%s deve ser um número inteiro não negativo, encontrado %v", option.key, raw))
+			continue
+		}
+		*option.target = n
+	}
+	expressions := defaultDescriptionPlaceholders
+	if _, ok := options["placeholders"]; ok {
+		var listProblems []string
+		expressions, listProblems = stringListOption(options, "placeholders")
+		problems = append(problems, listProblems...)
+	}
+	for _, expr := range expressions {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("functionOptions.placeholders %q não é uma expressão regular válida: %v", expr, err))
+			continue
+		}
+		req.placeholders = append(req.placeholders, re)
+	}
+	if _, ok := options["in"]; ok {
+		kinds, listProblems := stringListOption(options, "in")
+		problems = append(problems, listProblems...)
+		req.kinds = map[string]bool{}
+		for _, kind := range kinds {
+			known := false
+			for _, k := range descriptionKinds {
+				known = known || kind == k
+			}
+			if !known {
+				problems = append(problems, fmt.Sprintf("functionOptions.in: %q desconhecido (use %s)%s", kind, strings.Join(descriptionKinds, ", "), suggestionSuffix(kind, descriptionKinds)))
+				continue
+			}
+			req.kinds[kind] = true
+		}
+	}
+	known := []string{"minLength", "placeholders", "maxDuplicates", "in"}
+	var unknown []string
+	for key := range options {
+		if key != "minLength" && key != "placeholders" && key != "maxDuplicates" && key != "in" {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		problems = append(problems, fmt.Sprintf("functionOptions.%s desconhecida para a função descriptions (use %s)%s", key, strings.Join(known, ", "), suggestionSuffix(key, known)))
+	}
+	return req, problems
+}
+
+// Descrição encontrada no documento, com o local para as mensagens
+type documentedText struct {
+	text  string
+	label string // ex.: "GET /accounts", "parâmetro page-size de GET /accounts", "schema Account.data"
+	node  *yaml.Node
+	path  string
+}
+
+// Função para reunir as descrições do documento na ordem em que aparecem: as das
+// operações (paths e webhooks), as dos parâmetros escritos nas operações, nos path items
+// e nos componentes, e as dos schemas dos componentes com as das suas propriedades,
+// itens e composições. Os $refs não são seguidos: cada componente é visitado uma vez, no
+// local em que foi escrito.
+func (req *descriptionRequirement) collect(doc *yaml.Node, docPath string) []documentedText {
+	var found []documentedText
+	add := func(owner *yaml.Node, ownerPath, label string) {
+		if d := mappingValue(owner, "description"); d != nil && d.Kind == yaml.ScalarNode {
+			found = append(found, documentedText{text: d.Value, label: label, node: d, path: joinPath(ownerPath, "description")})
+		}
+	}
+	addParameters := func(owner *yaml.Node, ownerPath, of string) {
+		params := mappingValue(owner, "parameters")
+		if !req.kinds["parameters"] || params == nil || params.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, param := range params.Content {
+			name := mappingValue(param, "name")
+			if name == nil {
+				continue
+			}
+			add(param, fmt.Sprintf("%s[%d]", joinPath(ownerPath, "parameters"), i), "parâmetro "+name.Value+" de "+of)
+		}
+	}
+
+	for _, section := range []string{"paths", "webhooks"} {
+		forEachEntry(mappingValue(doc, section), func(route string, item *yaml.Node) {
+			itemPath := joinPath(joinPath(docPath, section), route)
+			op := operationRef{Path: route, Webhook: section == "webhooks"}
+			addParameters(item, itemPath, op.displayPath())
+			forEachEntry(item, func(method string, operation *yaml.Node) {
+				if !httpMethods[method] {
+					return
+				}
+				label := strings.ToUpper(method) + " " + op.displayPath()
+				if req.kinds["operations"] {
+					add(operation, joinPath(itemPath, method), label)
+				}
+				addParameters(operation, joinPath(itemPath, method), label)
+			})
+		})
+	}
+
+	components, componentsPath := mappingValue(doc, "components"), joinPath(docPath, "components")
+	if req.kinds["parameters"] {
+		for _, section := range []struct {
+			node *yaml.Node
+			path string
+		}{{mappingValue(components, "parameters"), joinPath(componentsPath, "parameters")}, {mappingValue(doc, "parameters"), joinPath(docPath, "parameters")}} {
+			forEachEntry(section.node, func(name string, param *yaml.Node) {
+				add(param, joinPath(section.path, name), "parâmetro "+name)
+			})
+		}
+	}
+	if req.kinds["schemas"] {
+		var visit func(schema *yaml.Node, path, label string, depth int)
+		visit = func(schema *yaml.Node, path, label string, depth int) {
+			if schema == nil || schema.Kind != yaml.MappingNode || mappingValue(schema, "$ref") != nil || depth >= maxSchemaRefDepth {
+				return
+			}
+			add(schema, path, "schema "+label)
+			forEachEntry(mappingValue(schema, "properties"), func(name string, property *yaml.Node) {
+				visit(property, joinPath(joinPath(path, "properties"), name), label+"."+name, depth+1)
+			})
+			visit(mappingValue(schema, "items"), joinPath(path, "items"), label+"[]", depth+1)
+			visit(mappingValue(schema, "additionalProperties"), joinPath(path, "additionalProperties"), label+".*", depth+1)
+			for _, keyword := range []string{"allOf", "oneOf", "anyOf"} {
+				if list := mappingValue(schema, keyword); list != nil && list.Kind == yaml.SequenceNode {
+					for i, branch := range list.Content {
+						visit(branch, fmt.Sprintf("%s[%d]", joinPath(path, keyword), i), label, depth+1)
+					}
+				}
+			}
+		}
+		for _, section := range []struct {
+			node *yaml.Node
+			path string
+		}{{mappingValue(components, "schemas"), joinPath(componentsPath, "schemas")}, {mappingValue(doc, "definitions"), joinPath(docPath, "definitions")}} {
+			forEachEntry(section.node, func(name string, schema *yaml.Node) {
+				visit(schema, joinPath(section.path, name), name, 0)
+			})
+		}
+	}
+	return found
+}
+
+// Texto da descrição para comparar as repetições: sem os espaços das pontas e com os
+// espaços internos (e quebras de linha) reduzidos a um
+func normalizedDescription(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Função para conferir a qualidade das descrições (given $): cada descrição precisa ter
+// o tamanho mínimo e não pode ser um texto de preenchimento; depois de reunidas todas,
+// o mesmo texto em mais de maxDuplicates locais é apontado em cada um deles, com os
+// demais locais na mensagem para que os autores possam diferenciá-los. Uma descrição
+// curta ou de preenchimento não entra na comparação das repetições.
+func (r *compiledRule) descriptionViolations(m pathMatch) []Violation {
+	doc := m.Node
+	if doc == nil || doc.Kind != yaml.MappingNode {
+		return nil
+	}
+	description := strings.TrimSuffix(r.description, ".")
+	found := r.descriptions.collect(doc, m.Path)
+	var violations []Violation
+	var compared []documentedText
+	for _, d := range found {
+		text := normalizedDescription(d.text)
+		problem := ""
+		for _, re := range r.descriptions.placeholders {
+			if re.MatchString(text) {
+				problem = "é um texto de preenchimento"
+				break
+			}
+		}
+		if n := utf8.RuneCountInString(text); problem == "" && n < r.descriptions.minLength {
+			problem = fmt.Sprintf("tem %d caracteres, menos que o mínimo de %d", n, r.descriptions.minLength)
+		}
+		if problem == "" {
+			compared = append(compared, d)
+			continue
+		}
+		v := r.violation(d.path, d.node.Line, d.node)
+		v.Message = fmt.Sprintf("%s: a descrição de %s (%q) %s.", description, d.label, d.text, problem)
+		violations = append(violations, v)
+	}
+	if r.descriptions.maxDuplicates == 0 {
+		return violations
+	}
+
+	// Segunda passagem: os textos repetidos, depois de reunidas todas as descrições
+	byText := map[string][]documentedText{}
+	for _, d := range compared {
+		text := normalizedDescription(d.text)
+		byText[text] = append(byText[text], d)
+	}
+	for _, d := range compared {
+		same := byText[normalizedDescription(d.text)]
+		if len(same) <= r.descriptions.maxDuplicates {
+			continue
+		}
+		var others []string
+		for _, o := range same {
+			if o.path == d.path {
+				continue
+			}
+			if len(others) == 5 {
+				others = append(others, fmt.Sprintf("e mais %d", len(same)-1-5))
+				break
+			}
+			others = append(others, fmt.Sprintf("%s (linha %d)", o.label, o.node.Line))
+		}
+		v := r.violation(d.path, d.node.Line, d.node)
+		v.Message = fmt.Sprintf("%s: a descrição de %s se repete em %d locais (máximo %d): %s.", description, d.label, len(same), r.descriptions.maxDuplicates, strings.Join(others, ", "))
+		violations = append(violations, v)
+	}
+	return violations
+}
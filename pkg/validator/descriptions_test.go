@@ -0,0 +1,87 @@
+package validator
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const descriptionsSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas:
+    parameters:
+      - {name: pagina, in: query, description: Página}
+    get:
+      description: TODO
+      responses: {'200': {description: ok}}
+    post:
+      description: Retorna os dados do recurso consultado.
+      responses: {'201': {description: ok}}
+  /cartoes:
+    get:
+      description: "Retorna os dados   do recurso consultado."
+      responses: {'200': {description: ok}}
+    put:
+      description: Retorna os dados do recurso consultado.
+      responses: {'200': {description: ok}}
+components:
+  schemas:
+    Conta:
+      description: Descrição
+      properties:
+        saldo:
+          description: Saldo disponível da conta, em reais.
+          items: {description: Lorem ipsum dolor sit amet}
+`
+
+// Descrições curtas ou de preenchimento são apontadas (operações, parâmetros e schemas
+// com as propriedades); o mesmo texto em mais de maxDuplicates locais é apontado em
+// cada um, com os outros locais, ignorando a diferença de espaços
+func TestDescriptions(t *testing.T) {
+	rules := "rules:\n  descricoes:\n    description: Descrições úteis.\n    given: $\n    then: {function: descriptions, functionOptions: {maxDuplicates: 2}}\n"
+	found := ruleViolations(t, descriptionsSpec, rules, "descricoes")
+	want := []string{
+		`Descrições úteis: a descrição de parâmetro pagina de /contas ("Página") tem 6 caracteres, menos que o mínimo de 10.`,
+		`Descrições úteis: a descrição de GET /contas ("TODO") é um texto de preenchimento.`,
+		`Descrições úteis: a descrição de schema Conta ("Descrição") é um texto de preenchimento.`,
+		`Descrições úteis: a descrição de schema Conta.saldo[] ("Lorem ipsum dolor sit amet") é um texto de preenchimento.`,
+		"Descrições úteis: a descrição de POST /contas se repete em 3 locais (máximo 2): GET /cartoes (linha 15), PUT /cartoes (linha 18).",
+		"Descrições úteis: a descrição de GET /cartoes se repete em 3 locais (máximo 2): POST /contas (linha 11), PUT /cartoes (linha 18).",
+		"Descrições úteis: a descrição de PUT /cartoes se repete em 3 locais (máximo 2): POST /contas (linha 11), GET /cartoes (linha 15).",
+	}
+	if len(found) != len(want) {
+		t.Fatalf("esperadas %d violações, encontradas %d: %v", len(want), len(found), found)
+	}
+	for i, v := range found {
+		if v.Message != want[i] {
+			t.Errorf("violação %d: %q, esperado %q", i, v.Message, want[i])
+		}
+	}
+
+	rules = "rules:\n  descricoes:\n    given: $\n    then: {function: descriptions, functionOptions: {minLength: 0, placeholders: [], maxDuplicates: 0, in: [operations]}}\n"
+	if found := ruleViolations(t, descriptionsSpec, rules, "descricoes"); len(found) != 0 {
+		t.Errorf("sem mínimo, preenchimentos nem repetições, nada deveria ser apontado: %v", found)
+	}
+	rules = "rules:\n  descricoes:\n    given: $\n    then: {function: descriptions, functionOptions: {in: [schemas], placeholders: [\"(?i)^descri\"]}}\n"
+	if found := ruleViolations(t, descriptionsSpec, rules, "descricoes"); len(found) != 1 || !strings.Contains(found[0].Message, "schema Conta (") {
+		t.Errorf("só os schemas, com o preenchimento configurado: %v", found)
+	}
+}
+
+// Opções inválidas da função descriptions são erro de uso
+func TestDescriptionsOptions(t *testing.T) {
+	for options, message := range map[string]string{
+		"{minLength: -1}":       "functionOptions.minLength deve ser um número inteiro não negativo, encontrado -1",
+		"{maxDuplicates: 2.5}":  "functionOptions.maxDuplicates deve ser um número inteiro não negativo, encontrado 2.5",
+		`{placeholders: ["("]}`: `functionOptions.placeholders "(" não é uma expressão regular válida`,
+		"{in: [operation]}":     `functionOptions.in: "operation" desconhecido (use operations, parameters, schemas)`,
+		"{minLenght: 5}":        "functionOptions.minLenght desconhecida para a função descriptions",
+	} {
+		rules := "rules:\n  a:\n    given: $\n    then: {function: descriptions, functionOptions: " + options + "}\n"
+		_, err := Validate(context.Background(), []byte(descriptionsSpec), Options{Source: "api.yaml", Rules: []byte(rules)})
+		if err == nil || rulesExitCode(err) != exitUsage || !strings.Contains(err.Error(), message) {
+			t.Errorf("%s: erro %v, esperado erro de uso com %q", options, err, message)
+		}
+	}
+}
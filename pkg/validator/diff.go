@@ -0,0 +1,731 @@
+package validator
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Change é uma mudança entre duas versões da especificação
+type Change struct {
+	Type     string `json:"type"`     // added, removed ou changed
+	Location string `json:"location"` // operação ("GET /contas") ou componente ("components.schemas.Conta")
+	JSONPath string `json:"jsonPath"` // local na versão nova (na anterior, para o que foi removido)
+	Breaking bool   `json:"breaking"` // quebra os clientes da versão anterior
+	Message  string `json:"message"`
+}
+
+// DiffResult é o resultado de Diff
+type DiffResult struct {
+	Changes  []Change `json:"changes"`
+	Breaking int      `json:"breaking"`        // mudanças que quebram os clientes
+	Scope    string   `json:"scope,omitempty"` // escopo aplicado com PathPrefixes ou Components
+}
+
+// Diff compara duas versões da especificação. As duas são resolvidas por inteiro, de
+// modo que a mudança de um schema compartilhado aparece em cada operação que o usa;
+// as mudanças que quebram os clientes da versão anterior (operação ou resposta 2xx
+// removida, parâmetro ou propriedade obrigatória nova, tipo alterado...) são marcadas
+// em Breaking. Options vale para as duas versões, com Source como referência dos
+// $refs relativos de ambas; os arquivos referenciados pela versão anterior vêm de
+// OldFS e OldBaseDir, quando informados. Com PathPrefixes ou Components, só as
+// mudanças no escopo são relatadas e Scope o descreve.
+func Diff(ctx context.Context, oldSpec, newSpec []byte, opts Options) (*DiffResult, error) {
+	oldOpts := opts
+	if opts.OldFS != nil {
+		oldOpts.FS = opts.OldFS
+	}
+	if opts.OldBaseDir != "" {
+		oldOpts.BaseDir = opts.OldBaseDir
+	}
+	source, s, err := oldOpts.settings(oldSpec)
+	if err != nil {
+		return nil, err
+	}
+	oldDoc, err := openSpecDocument(s, source)
+	if err != nil {
+		return nil, fmt.Errorf("versão anterior: %v", err)
+	}
+	if source, s, err = opts.settings(newSpec); err != nil {
+		return nil, err
+	}
+	newDoc, err := openSpecDocument(s, source)
+	if err != nil {
+		return nil, err
+	}
+	return diffDocuments(ctx, oldDoc, newDoc, diffFilter{pathPrefixes: opts.PathPrefixes, components: opts.Components})
+}
+
+// Função para comparar as duas versões abertas: o escopo dos filtros vem das árvores
+// originais, que ainda têm os $refs, e a comparação, dos documentos resolvidos
+func diffDocuments(ctx context.Context, oldDoc, newDoc *specDocument, filter diffFilter) (*DiffResult, error) {
+	var scope *changedScope
+	if filter.active() {
+		var err error
+		if scope, err = computeDiffScope(oldDoc.root, newDoc.root, filter); err != nil {
+			return nil, err
+		}
+	}
+	oldDoc.exclusive, newDoc.exclusive = true, true
+	oldResolved, err := oldDoc.resolve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("versão anterior: %v", err)
+	}
+	newResolved, err := newDoc.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := diffSpecs(&oldResolved.rootNode, &newResolved.rootNode)
+	if scope != nil {
+		result.restrict(scope, filter)
+	}
+	return result, nil
+}
+
+// Mantém só as mudanças no escopo dos filtros e registra o escopo aplicado
+func (r *DiffResult) restrict(scope *changedScope, filter diffFilter) {
+	kept := []Change{}
+	r.Breaking = 0
+	for _, c := range r.Changes {
+		if !scope.contains(c.JSONPath) {
+			continue
+		}
+		if c.Breaking {
+			r.Breaking++
+		}
+		kept = append(kept, c)
+	}
+	r.Changes = kept
+	r.Scope = scope.diffSummary(filter)
+}
+
+// Flags do subcomando diff
+type diffOptions struct {
+	format       string
+	baseRef      string
+	pathPrefixes stringList
+	components   stringList
+	timeout      time.Duration
+}
+
+func (o *diffOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.format, "format", "text", "formato da saída: text ou json (o mesmo de POST /diff)")
+	fs.StringVar(&o.baseRef, "base-ref", "", "ref do git de onde ler a versão anterior, no lugar de oldSwagger.yaml")
+	fs.Var(&o.pathPrefixes, "path-prefix", "compara só os paths iguais ao prefixo ou abaixo dele (ex.: /consents); pode ser repetida")
+	fs.Var(&o.components, "component", "compara só o componente (Consent ou schemas.Consent) e os que ele referencia; pode ser repetida")
+	fs.DurationVar(&o.timeout, "timeout", defaultTimeout, "tempo máximo da execução (0 desativa)")
+	registerRefFlags(fs)
+	registerHTTPFlags(fs)
+	registerEncodingFlag(fs)
+	registerInputLimitFlag(fs)
+}
+
+var diffCommand = &command{
+	Name:    "diff",
+	Args:    "[<oldSwagger.yaml>] <swagger.yaml>",
+	Summary: "compara duas versões e aponta as mudanças que quebram os clientes",
+	Description: `Resolve as duas versões da especificação por inteiro e lista as mudanças
+nas operações de paths e webhooks e nos componentes. As que quebram os clientes
+da versão anterior (operação ou resposta 2xx removida, parâmetro ou propriedade
+obrigatória nova, tipo alterado, valor de enum...) são marcadas, e o código de
+saída é 1 quando há alguma.
+
+Com --base-ref, a versão anterior é a da especificação na ref do git: a árvore
+da ref é extraída num diretório temporário, para que os $refs da versão
+anterior alcancem os arquivos da mesma ref, e não os da árvore de trabalho.
+
+Com --path-prefix (/consents abrange /consents/{consentId}, mas não
+/consentsV2) e --component, a comparação fica nos paths e componentes
+selecionados e nos componentes que eles referenciam, de forma transitiva, nas
+duas versões; os documentos continuam resolvidos por inteiro, de modo que a
+mudança de um schema compartilhado ainda aparece nas operações selecionadas. O
+relatório informa o escopo aplicado, para que um resultado sem mudanças não seja
+lido como uma comparação completa.`,
+	Examples: []string{
+		programName + " diff oldSwagger.yaml swagger.yaml",
+		programName + " diff --base-ref origin/main swagger.yaml",
+		programName + " diff --path-prefix /consents oldSwagger.yaml swagger.yaml",
+		programName + " diff --component schemas.Consent --format json oldSwagger.yaml swagger.yaml",
+	},
+	Flags: func(fs *flag.FlagSet) { new(diffOptions).register(fs) },
+	Run:   runDiff,
+}
+
+// Subcomando diff: mudanças entre a versão anterior e a nova
+func runDiff(c *command, args []string) int {
+	opts := &diffOptions{}
+	fs := c.newFlagSet()
+	opts.register(fs)
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	if err := checkHTTPFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkEncodingFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if opts.format != "text" && opts.format != "json" {
+		return c.usageError("formato %q inválido para diff (use text ou json)", opts.format)
+	}
+	var oldFile, newFile string
+	switch {
+	case opts.baseRef != "" && fs.NArg() == 1:
+		newFile = fs.Arg(0)
+	case opts.baseRef == "" && fs.NArg() == 2:
+		oldFile, newFile = fs.Arg(0), fs.Arg(1)
+	case opts.baseRef != "":
+		return c.usageError("com --base-ref, esperado um argumento (especificação), recebidos %d", fs.NArg())
+	default:
+		return c.usageError("esperados dois argumentos (versão anterior e nova), recebidos %d", fs.NArg())
+	}
+
+	ctx, cancel := newRunContext(opts.timeout)
+	defer cancel()
+
+	oldSettings := flagSettings()
+	if opts.baseRef != "" {
+		baseFile, cleanup, err := fetchBaseSpec(opts.baseRef, newFile)
+		if err != nil {
+			fmt.Fprintln(stdout, "❌ Erro ao obter a versão anterior:", err)
+			return exitFailure
+		}
+		if baseFile == "" {
+			fmt.Fprintf(stdout, "❌ %s não existe na ref %s; não há versão anterior para comparar.\n", newFile, opts.baseRef)
+			return exitFailure
+		}
+		defer cleanup()
+		oldFile, oldSettings = baseFile, baseSpecSettings(newFile, baseFile)
+	}
+	oldDoc, err := openSpecDocument(oldSettings, oldFile)
+	if err != nil {
+		return statsError(oldFile, err, opts.timeout)
+	}
+	newDoc, err := openSpecDocument(flagSettings(), newFile)
+	if err != nil {
+		return statsError(newFile, err, opts.timeout)
+	}
+	result, err := diffDocuments(ctx, oldDoc, newDoc, diffFilter{pathPrefixes: opts.pathPrefixes, components: opts.components})
+	if err != nil {
+		if isCancellation(err) {
+			reportCancellation(err, opts.timeout)
+			return exitFailure
+		}
+		fmt.Fprintln(stdout, "❌", err)
+		return exitFailure
+	}
+
+	if opts.format == "json" {
+		if err := printJSONReport(result); err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+	} else {
+		printDiff(result)
+	}
+	if result.Breaking > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// Lista as mudanças, com as que quebram os clientes marcadas, e o escopo aplicado
+func printDiff(result *DiffResult) {
+	for _, c := range result.Changes {
+		mark := "  "
+		if c.Breaking {
+			mark = "❌"
+		}
+		fmt.Fprintf(stdout, "%s %s: %s\n", mark, c.Location, c.Message)
+	}
+	switch {
+	case len(result.Changes) == 0:
+		fmt.Fprintln(stdout, "✅ Nenhuma mudança entre as versões")
+	case result.Breaking == 0:
+		fmt.Fprintf(stdout, "✅ %d mudança(s), nenhuma quebra os clientes\n", len(result.Changes))
+	default:
+		fmt.Fprintf(stdout, "❌ %d mudança(s), %d quebra(m) os clientes da versão anterior\n", len(result.Changes), result.Breaking)
+	}
+	if result.Scope != "" {
+		fmt.Fprintln(stdout, "🧭", result.Scope)
+	}
+}
+
+// Função para comparar as duas versões já resolvidas: as operações de paths e webhooks,
+// campo a campo, e os componentes, pelo conteúdo. As mudanças seguem a ordem dos
+// documentos, com as remoções depois das entradas da versão nova.
+func diffSpecs(oldRoot, newRoot *yaml.Node) *DiffResult {
+	oldDoc, newDoc := documentContent(oldRoot), documentContent(newRoot)
+	result := &DiffResult{Changes: []Change{}}
+	add := func(changes ...Change) {
+		for _, c := range changes {
+			if c.Breaking {
+				result.Breaking++
+			}
+			result.Changes = append(result.Changes, c)
+		}
+	}
+
+	versions := diffVersions{old: isOpenAPI31(oldRoot), new: isOpenAPI31(newRoot)}
+	for _, section := range []string{"paths", "webhooks"} {
+		oldItems := mappingValue(oldDoc, section)
+		newItems := mappingValue(newDoc, section)
+		forEachEntry(newItems, func(route string, item *yaml.Node) {
+			oldItem := mappingValue(oldItems, route)
+			forEachEntry(item, func(method string, operation *yaml.Node) {
+				if !httpMethods[method] {
+					return
+				}
+				op := operationRef{Method: method, Path: route, Webhook: section == "webhooks"}
+				path := joinPath(joinPath(joinPath("$", section), route), method)
+				old := mappingValue(oldItem, method)
+				if old == nil {
+					add(Change{Type: "added", Location: op.label(), JSONPath: path, Message: "operação nova"})
+					return
+				}
+				add(diffOperation(op, path, versions, oldItem, old, item, operation)...)
+			})
+		})
+		forEachEntry(oldItems, func(route string, oldItem *yaml.Node) {
+			item := mappingValue(newItems, route)
+			forEachEntry(oldItem, func(method string, _ *yaml.Node) {
+				if !httpMethods[method] || mappingValue(item, method) != nil {
+					return
+				}
+				op := operationRef{Method: method, Path: route, Webhook: section == "webhooks"}
+				add(Change{Type: "removed", Location: op.label(), JSONPath: joinPath(joinPath(joinPath("$", section), route), method), Breaking: true, Message: "a operação foi removida"})
+			})
+		})
+	}
+
+	// Componentes: o impacto nos clientes já aparece nas operações que os usam
+	oldComponents := diffComponents(oldDoc)
+	newComponents := diffComponents(newDoc)
+	for _, c := range newComponents {
+		old, ok := findComponent(oldComponents, c.path)
+		switch {
+		case !ok:
+			add(Change{Type: "added", Location: c.label, JSONPath: c.path, Message: "componente novo"})
+		case !sameNode(old.node, c.node):
+			add(Change{Type: "changed", Location: c.label, JSONPath: c.path, Message: "o componente mudou"})
+		}
+	}
+	for _, c := range oldComponents {
+		if _, ok := findComponent(newComponents, c.path); !ok {
+			add(Change{Type: "removed", Location: c.label, JSONPath: c.path, Message: "o componente foi removido"})
+		}
+	}
+	return result
+}
+
+// Operação nas mensagens da comparação: "GET /contas"
+func (op operationRef) label() string {
+	return strings.ToUpper(op.Method) + " " + op.displayPath()
+}
+
+// Componente da comparação, pelo JSONPath
+type diffComponent struct {
+	path  string
+	label string
+	node  *yaml.Node
+}
+
+// Componentes do documento na ordem em que aparecem: components.<tipo>.<nome> ou, no
+// Swagger 2.0, definitions, parameters e responses
+func diffComponents(doc *yaml.Node) []diffComponent {
+	var components []diffComponent
+	visit := func(path, label string, entries *yaml.Node) {
+		forEachEntry(entries, func(name string, node *yaml.Node) {
+			components = append(components, diffComponent{path: joinPath(path, name), label: label + "." + name, node: node})
+		})
+	}
+	forEachEntry(mappingValue(doc, "components"), func(kind string, entries *yaml.Node) {
+		visit(joinPath("$.components", kind), "components."+kind, entries)
+	})
+	for _, section := range []string{"definitions", "parameters", "responses"} {
+		visit(joinPath("$", section), section, mappingValue(doc, section))
+	}
+	return components
+}
+
+func findComponent(components []diffComponent, path string) (diffComponent, bool) {
+	for _, c := range components {
+		if c.path == path {
+			return c, true
+		}
+	}
+	return diffComponent{}, false
+}
+
+// Compara duas árvores pelo conteúdo; nós ausentes só são iguais entre si
+func sameNode(a, b *yaml.Node) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return nodesEqual(a, b)
+}
+
+// Função para comparar uma operação nas duas versões: os parâmetros (os do path item
+// valem para a operação), o corpo da requisição e as respostas. Uma operação com
+// outras mudanças (descrições, exemplos, tags...) vira uma única mudança compatível.
+func diffOperation(op operationRef, path string, versions diffVersions, oldItem, oldOp, newItem, newOp *yaml.Node) []Change {
+	label := op.label()
+	var changes []Change
+	change := func(breaking bool, format string, args ...interface{}) {
+		changes = append(changes, Change{Type: "changed", Location: label, JSONPath: path, Breaking: breaking, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Parâmetros
+	oldParams, newParams := effectiveParameters(oldItem, oldOp), effectiveParameters(newItem, newOp)
+	for _, p := range newParams {
+		old := findParameter(oldParams, p.key)
+		switch {
+		case old == nil && p.required:
+			change(true, "novo parâmetro obrigatório %s", p.key)
+		case old == nil:
+			change(false, "novo parâmetro opcional %s", p.key)
+		default:
+			if p.required && !old.required {
+				change(true, "o parâmetro %s passou a ser obrigatório", p.key)
+			}
+			for _, d := range diffSchemas(mappingValue(old.node, "schema"), mappingValue(p.node, "schema"), "parâmetro "+p.key, requestSide, versions) {
+				change(d.breaking, "%s", d.message)
+			}
+		}
+	}
+	for _, p := range oldParams {
+		if findParameter(newParams, p.key) == nil {
+			change(false, "o parâmetro %s foi removido", p.key)
+		}
+	}
+
+	// Corpo da requisição
+	oldBody, newBody := mappingValue(oldOp, "requestBody"), mappingValue(newOp, "requestBody")
+	switch {
+	case oldBody == nil && newBody != nil && isTrue(mappingValue(newBody, "required")):
+		change(true, "novo corpo de requisição obrigatório")
+	case oldBody == nil && newBody != nil:
+		change(false, "novo corpo de requisição opcional")
+	case oldBody != nil && newBody == nil:
+		change(false, "o corpo da requisição foi removido")
+	case oldBody != nil:
+		if isTrue(mappingValue(newBody, "required")) && !isTrue(mappingValue(oldBody, "required")) {
+			change(true, "o corpo da requisição passou a ser obrigatório")
+		}
+		for _, d := range diffContent(mappingValue(oldBody, "content"), mappingValue(newBody, "content"), "corpo da requisição", requestSide, versions) {
+			change(d.breaking, "%s", d.message)
+		}
+	}
+
+	// Respostas
+	oldResponses, newResponses := mappingValue(oldOp, "responses"), mappingValue(newOp, "responses")
+	forEachEntry(newResponses, func(status string, response *yaml.Node) {
+		old := mappingValue(oldResponses, status)
+		if old == nil {
+			change(false, "nova resposta %s", status)
+			return
+		}
+		where := "resposta " + status
+		for _, d := range diffContent(mappingValue(old, "content"), mappingValue(response, "content"), where, responseSide, versions) {
+			change(d.breaking, "%s", d.message)
+		}
+		// Swagger 2.0 sem conversão: o schema fica na própria resposta
+		for _, d := range diffSchemas(mappingValue(old, "schema"), mappingValue(response, "schema"), where, responseSide, versions) {
+			change(d.breaking, "%s", d.message)
+		}
+	})
+	forEachEntry(oldResponses, func(status string, _ *yaml.Node) {
+		if mappingValue(newResponses, status) == nil {
+			change(strings.HasPrefix(status, "2"), "a resposta %s foi removida", status)
+		}
+	})
+
+	if len(changes) == 0 && (!sameNode(oldOp, newOp) || !sameNode(pathItemShared(oldItem), pathItemShared(newItem))) {
+		change(false, "a operação mudou (descrições, exemplos ou outros detalhes)")
+	}
+	return changes
+}
+
+// Parâmetro efetivo de uma operação, identificado por "nome (in)"
+type diffParameter struct {
+	key      string
+	required bool
+	node     *yaml.Node
+}
+
+// Parâmetros da operação somados aos do path item; os da operação substituem os do
+// path item com o mesmo nome e local
+func effectiveParameters(item, operation *yaml.Node) []diffParameter {
+	var params []diffParameter
+	for _, owner := range []*yaml.Node{operation, item} {
+		list := mappingValue(owner, "parameters")
+		if list == nil || list.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, param := range list.Content {
+			name, in := mappingValue(param, "name"), mappingValue(param, "in")
+			if name == nil || in == nil {
+				continue
+			}
+			key := fmt.Sprintf("%s (%s)", name.Value, in.Value)
+			if findParameter(params, key) != nil {
+				continue
+			}
+			params = append(params, diffParameter{key: key, required: in.Value == "path" || isTrue(mappingValue(param, "required")), node: param})
+		}
+	}
+	return params
+}
+
+func findParameter(params []diffParameter, key string) *diffParameter {
+	for i := range params {
+		if params[i].key == key {
+			return &params[i]
+		}
+	}
+	return nil
+}
+
+func isTrue(node *yaml.Node) bool {
+	return node != nil && node.Kind == yaml.ScalarNode && node.Value == "true"
+}
+
+// Lado da troca em que o schema é usado: o que quebra um cliente muda com ele
+type schemaSide int
+
+const (
+	requestSide  schemaSide = iota // o cliente envia: exigir mais quebra
+	responseSide                   // o cliente recebe: entregar menos quebra
+)
+
+// Diferença encontrada em um schema
+type schemaDiff struct {
+	message  string
+	breaking bool
+}
+
+// Compara o content (media types) da requisição ou da resposta
+func diffContent(oldContent, newContent *yaml.Node, where string, side schemaSide, versions diffVersions) []schemaDiff {
+	var diffs []schemaDiff
+	forEachEntry(oldContent, func(mediaType string, old *yaml.Node) {
+		current := mappingValue(newContent, mediaType)
+		if current == nil {
+			diffs = append(diffs, schemaDiff{message: fmt.Sprintf("%s: o media type %s foi removido", where, mediaType), breaking: true})
+			return
+		}
+		diffs = append(diffs, diffSchemas(mappingValue(old, "schema"), mappingValue(current, "schema"), where, side, versions)...)
+	})
+	forEachEntry(newContent, func(mediaType string, _ *yaml.Node) {
+		if oldContent != nil && mappingValue(oldContent, mediaType) == nil {
+			diffs = append(diffs, schemaDiff{message: fmt.Sprintf("%s: novo media type %s", where, mediaType)})
+		}
+	})
+	return diffs
+}
+
+// Função para comparar dois schemas já resolvidos, propriedade a propriedade (com as
+// do allOf) e nos itens das listas: tipo que o cliente deixa de poder enviar ou passa
+// a receber (listas de tipos do 3.1 e null, pelo tipo "null" ou por nullable),
+// propriedade removida da resposta, propriedade obrigatória nova na requisição, valor
+// de enum removido da requisição ou novo na resposta. Ciclos mantidos como $ref não
+// são seguidos.
+func diffSchemas(oldSchema, newSchema *yaml.Node, where string, side schemaSide, versions diffVersions) []schemaDiff {
+	var diffs []schemaDiff
+	report := func(breaking bool, format string, args ...interface{}) {
+		diffs = append(diffs, schemaDiff{message: where + ": " + fmt.Sprintf(format, args...), breaking: breaking})
+	}
+	var walk func(old, current *yaml.Node, field string, depth int)
+	walk = func(old, current *yaml.Node, field string, depth int) {
+		if old == nil || current == nil || depth >= maxSchemaRefDepth || old == current {
+			return
+		}
+		if mappingValue(old, "$ref") != nil || mappingValue(current, "$ref") != nil {
+			return
+		}
+		of := "do schema"
+		if field != "" {
+			of = "da propriedade " + field
+		}
+		oldTypes, oldNullable := schemaTypes(old, versions.old)
+		newTypes, newNullable := schemaTypes(current, versions.new)
+		if len(oldTypes) > 0 && len(newTypes) > 0 && !sameStrings(oldTypes, newTypes) {
+			// Na requisição quebra o tipo que deixou de ser aceito; na resposta, o tipo novo
+			breaking := false
+			for _, t := range oldTypes {
+				breaking = breaking || side == requestSide && !containsString(newTypes, t)
+			}
+			for _, t := range newTypes {
+				breaking = breaking || side == responseSide && !containsString(oldTypes, t)
+			}
+			report(breaking, "o tipo %s mudou de %s para %s", of, strings.Join(oldTypes, " | "), strings.Join(newTypes, " | "))
+			if !overlaps(oldTypes, newTypes) {
+				return
+			}
+		}
+		switch {
+		case oldNullable && !newNullable:
+			report(side == requestSide, "o valor %s deixou de aceitar null", of)
+		case !oldNullable && newNullable:
+			report(side == responseSide, "o valor %s passou a aceitar null", of)
+		}
+
+		oldEnum, newEnum := enumValues(old), enumValues(current)
+		if len(oldEnum) > 0 && len(newEnum) > 0 {
+			for _, v := range oldEnum {
+				if side == requestSide && !containsString(newEnum, v) {
+					report(true, "o valor %q saiu do enum %s", v, of)
+				}
+			}
+			for _, v := range newEnum {
+				if side == responseSide && !containsString(oldEnum, v) {
+					report(true, "novo valor %q no enum %s", v, of)
+				}
+			}
+		}
+
+		oldProps, newProps := flattenedProperties(old), flattenedProperties(current)
+		oldRequired, newRequired := requiredNames(old), requiredNames(current)
+		for _, name := range sortedNodeKeys(newProps) {
+			child := joinField(field, name)
+			if _, existed := oldProps[name]; !existed {
+				if side == requestSide && newRequired[name] {
+					report(true, "nova propriedade obrigatória %s", child)
+				}
+				continue
+			}
+			if side == requestSide && newRequired[name] && !oldRequired[name] {
+				report(true, "a propriedade %s passou a ser obrigatória", child)
+			}
+			if side == responseSide && oldRequired[name] && !newRequired[name] {
+				report(true, "a propriedade %s deixou de ser obrigatória", child)
+			}
+			walk(oldProps[name], newProps[name], child, depth+1)
+		}
+		for _, name := range sortedNodeKeys(oldProps) {
+			if _, kept := newProps[name]; !kept && side == responseSide {
+				report(true, "a propriedade %s foi removida", joinField(field, name))
+			}
+		}
+		walk(mappingValue(old, "items"), mappingValue(current, "items"), joinField(field, "[]"), depth+1)
+	}
+	walk(oldSchema, newSchema, "", 0)
+	return diffs
+}
+
+func joinField(field, name string) string {
+	switch {
+	case field == "":
+		return name
+	case name == "[]":
+		return field + name
+	}
+	return field + "." + name
+}
+
+// Versões OpenAPI das duas especificações: em 3.1, type pode ser uma lista e null é um tipo
+type diffVersions struct {
+	old, new bool
+}
+
+// Indica se as duas listas têm os mesmos valores, em qualquer ordem
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, v := range a {
+		if !containsString(b, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func overlaps(a, b []string) bool {
+	for _, v := range a {
+		if containsString(b, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func enumValues(schema *yaml.Node) []string {
+	enum := mappingValue(schema, "enum")
+	if enum == nil || enum.Kind != yaml.SequenceNode {
+		return nil
+	}
+	var values []string
+	for _, v := range enum.Content {
+		values = append(values, v.Value)
+	}
+	return values
+}
+
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Propriedades do schema somadas às dos ramos do allOf
+func flattenedProperties(schema *yaml.Node) map[string]*yaml.Node {
+	props := map[string]*yaml.Node{}
+	var collect func(node *yaml.Node, depth int)
+	collect = func(node *yaml.Node, depth int) {
+		if node == nil || depth >= maxSchemaRefDepth {
+			return
+		}
+		forEachEntry(mappingValue(node, "properties"), func(name string, property *yaml.Node) {
+			if _, ok := props[name]; !ok {
+				props[name] = property
+			}
+		})
+		if allOf := mappingValue(node, "allOf"); allOf != nil && allOf.Kind == yaml.SequenceNode {
+			for _, branch := range allOf.Content {
+				collect(branch, depth+1)
+			}
+		}
+	}
+	collect(schema, 0)
+	return props
+}
+
+// Propriedades obrigatórias do schema e dos ramos do allOf
+func requiredNames(schema *yaml.Node) map[string]bool {
+	names := map[string]bool{}
+	var collect func(node *yaml.Node, depth int)
+	collect = func(node *yaml.Node, depth int) {
+		if node == nil || depth >= maxSchemaRefDepth {
+			return
+		}
+		if required := mappingValue(node, "required"); required != nil && required.Kind == yaml.SequenceNode {
+			for _, name := range required.Content {
+				names[name.Value] = true
+			}
+		}
+		if allOf := mappingValue(node, "allOf"); allOf != nil && allOf.Kind == yaml.SequenceNode {
+			for _, branch := range allOf.Content {
+				collect(branch, depth+1)
+			}
+		}
+	}
+	collect(schema, 0)
+	return names
+}
+
+func sortedNodeKeys(m map[string]*yaml.Node) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
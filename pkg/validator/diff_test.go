@@ -0,0 +1,298 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+const diffOldSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas:
+    get:
+      parameters:
+        - {name: page, in: query, schema: {type: integer}}
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Conta'}
+        '404': {description: não encontrada}
+  /contas/{contaId}:
+    delete:
+      parameters:
+        - {name: contaId, in: path, required: true, schema: {type: string}}
+      responses:
+        '204': {description: removida}
+components:
+  schemas:
+    Conta:
+      type: object
+      required: [id]
+      properties:
+        id: {type: string}
+        tipo: {type: string, enum: [CORRENTE, POUPANCA]}
+        saldo: {type: number}
+`
+
+func TestDiffClassifiesBreakingChanges(t *testing.T) {
+	newSpec := strings.NewReplacer(
+		"- {name: page, in: query, schema: {type: integer}}", "- {name: page, in: query, schema: {type: integer}}\n        - {name: cpf, in: header, required: true, schema: {type: string}}",
+		"saldo: {type: number}", "saldo: {type: string}",
+		"enum: [CORRENTE, POUPANCA]", "enum: [CORRENTE, POUPANCA, SALARIO]",
+	).Replace(diffOldSpec)
+	newSpec = strings.Replace(newSpec, "  /contas/{contaId}:\n    delete:\n      parameters:\n        - {name: contaId, in: path, required: true, schema: {type: string}}\n      responses:\n        '204': {description: removida}\n", "", 1)
+
+	result, err := Diff(context.Background(), []byte(diffOldSpec), []byte(newSpec), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{
+		"GET /contas: novo parâmetro obrigatório cpf (header)":                               true,
+		"GET /contas: resposta 200: o tipo da propriedade saldo mudou de number para string": true,
+		"GET /contas: resposta 200: novo valor \"SALARIO\" no enum da propriedade tipo":      true,
+		"DELETE /contas/{contaId}: a operação foi removida":                                  true,
+		"components.schemas.Conta: o componente mudou":                                       false,
+	}
+	got := map[string]bool{}
+	for _, c := range result.Changes {
+		got[c.Location+": "+c.Message] = c.Breaking
+	}
+	for message, breaking := range want {
+		b, ok := got[message]
+		if !ok {
+			t.Errorf("mudança ausente: %s\nencontradas: %v", message, got)
+			continue
+		}
+		if b != breaking {
+			t.Errorf("%s: breaking = %v, esperado %v", message, b, breaking)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("esperadas %d mudanças, encontradas %d: %v", len(want), len(got), got)
+	}
+	if result.Breaking != 4 {
+		t.Errorf("Breaking = %d, esperado 4", result.Breaking)
+	}
+}
+
+func TestDiffRequestSide(t *testing.T) {
+	old := `openapi: 3.0.3
+info: {title: Pagamentos, version: 1.0.0}
+paths:
+  /pagamentos:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                valor: {type: string}
+                tipo: {type: string, enum: [PIX, TED]}
+      responses:
+        '201': {description: criado}
+`
+	current := strings.NewReplacer(
+		"      requestBody:\n", "      requestBody:\n        required: true\n",
+		"              properties:\n", "              required: [valor, descricao]\n              properties:\n",
+		"                valor: {type: string}\n", "                valor: {type: string}\n                descricao: {type: string}\n",
+		"enum: [PIX, TED]", "enum: [PIX]",
+	).Replace(old)
+
+	result, err := Diff(context.Background(), []byte(old), []byte(current), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	for _, c := range result.Changes {
+		if !c.Breaking {
+			t.Errorf("mudança compatível inesperada: %s", c.Message)
+		}
+		messages = append(messages, c.Message)
+	}
+	for _, want := range []string{
+		"o corpo da requisição passou a ser obrigatório",
+		"corpo da requisição: nova propriedade obrigatória descricao",
+		"corpo da requisição: a propriedade valor passou a ser obrigatória",
+		`corpo da requisição: o valor "TED" saiu do enum da propriedade tipo`,
+	} {
+		if !containsString(messages, want) {
+			t.Errorf("mudança ausente: %s\nencontradas: %q", want, messages)
+		}
+	}
+}
+
+func TestDiffIdenticalSpecs(t *testing.T) {
+	result, err := Diff(context.Background(), []byte(diffOldSpec), []byte(diffOldSpec), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Changes) != 0 || result.Breaking != 0 {
+		t.Errorf("versões iguais não deveriam ter mudanças: %+v", result)
+	}
+}
+
+func TestDiffScoped(t *testing.T) {
+	newSpec := strings.NewReplacer(
+		"saldo: {type: number}", "saldo: {type: string}",
+		"  /contas/{contaId}:\n    delete:\n      parameters:\n        - {name: contaId, in: path, required: true, schema: {type: string}}\n      responses:\n        '204': {description: removida}\n", "",
+	).Replace(diffOldSpec)
+
+	locations := func(opts Options) ([]string, *DiffResult) {
+		t.Helper()
+		result, err := Diff(context.Background(), []byte(diffOldSpec), []byte(newSpec), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, c := range result.Changes {
+			got = append(got, c.Location)
+		}
+		return got, result
+	}
+
+	// O path removido só existe na versão anterior e ainda entra no escopo
+	got, result := locations(Options{PathPrefixes: []string{"/contas/{contaId}"}})
+	if strings.Join(got, "|") != "DELETE /contas/{contaId}" || result.Breaking != 1 {
+		t.Errorf("--path-prefix /contas/{contaId}: mudanças %q, breaking %d", got, result.Breaking)
+	}
+	if !strings.Contains(result.Scope, "--path-prefix /contas/{contaId}") {
+		t.Errorf("escopo não informado: %q", result.Scope)
+	}
+
+	got, result = locations(Options{Components: []string{"schemas.Conta"}})
+	if strings.Join(got, "|") != "components.schemas.Conta" || result.Breaking != 0 {
+		t.Errorf("--component schemas.Conta: mudanças %q, breaking %d", got, result.Breaking)
+	}
+
+	if _, err := Diff(context.Background(), []byte(diffOldSpec), []byte(newSpec), Options{Components: []string{"Contas"}}); err == nil {
+		t.Error("componente inexistente deveria ser erro")
+	}
+}
+
+// Listas de tipos do 3.1 e null: o que quebra depende do lado da troca
+func TestDiffTypeArrays31(t *testing.T) {
+	old := `openapi: 3.1.0
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                apelido: {type: [string, "null"]}
+                limite: {type: string}
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  codigo: {type: [string, integer]}
+                  saldo: {type: number}
+`
+	current := strings.NewReplacer(
+		`apelido: {type: [string, "null"]}`, `apelido: {type: string}`,
+		`limite: {type: string}`, `limite: {type: [string, number]}`,
+		`codigo: {type: [string, integer]}`, `codigo: {type: [boolean]}`,
+		`saldo: {type: number}`, `saldo: {type: [number, "null"]}`,
+	).Replace(old)
+
+	result, err := Diff(context.Background(), []byte(old), []byte(current), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDiffMessages(t, result, map[string]bool{
+		"corpo da requisição: o valor da propriedade apelido deixou de aceitar null":             true,
+		"corpo da requisição: o tipo da propriedade limite mudou de string para string | number": false,
+		"resposta 200: o tipo da propriedade codigo mudou de string | integer para boolean":      true,
+		"resposta 200: o valor da propriedade saldo passou a aceitar null":                       true,
+	})
+}
+
+// nullable do 3.0
+func TestDiffNullable30(t *testing.T) {
+	current := strings.Replace(diffOldSpec, "saldo: {type: number}", "saldo: {type: number, nullable: true}", 1)
+	result, err := Diff(context.Background(), []byte(diffOldSpec), []byte(current), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDiffMessages(t, result, map[string]bool{
+		"resposta 200: o valor da propriedade saldo passou a aceitar null": true,
+	})
+}
+
+// Confere as mensagens das operações (sem o local) e se cada uma quebra os clientes
+func checkDiffMessages(t *testing.T, result *DiffResult, want map[string]bool) {
+	t.Helper()
+	got := map[string]bool{}
+	for _, c := range result.Changes {
+		if strings.HasPrefix(c.Location, "components.") {
+			continue
+		}
+		got[c.Message] = c.Breaking
+	}
+	for message, breaking := range want {
+		b, ok := got[message]
+		if !ok {
+			t.Errorf("mudança ausente: %s\nencontradas: %v", message, got)
+			continue
+		}
+		if b != breaking {
+			t.Errorf("%s: breaking = %v, esperado %v", message, b, breaking)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("esperadas %d mudanças, encontradas %d: %v", len(want), len(got), got)
+	}
+}
+
+// Cada versão resolve os $refs nos próprios arquivos: a mudança num arquivo
+// referenciado aparece na operação que o usa
+func TestDiffOldFS(t *testing.T) {
+	oldFiles := multiFixture(t)
+	newFiles := multiFixture(t, "accountId:\n      type: string", "accountId:\n      type: integer")
+
+	result, err := Diff(context.Background(), oldFiles["api.yaml"], newFiles["api.yaml"], Options{Source: "api.yaml", FS: newFiles, OldFS: oldFiles})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "resposta 200: o tipo da propriedade data[].accountId mudou de string para integer"
+	for _, c := range result.Changes {
+		if c.Message == want && c.Location == "GET /accounts" && c.Breaking {
+			return
+		}
+	}
+	t.Errorf("mudança ausente: %s\nencontradas: %+v", want, result.Changes)
+}
+
+// diff --base-ref: a versão anterior e os arquivos que ela referencia vêm da ref
+func TestDiffCommandBaseRef(t *testing.T) {
+	files := multiFixture(t)
+	changed := multiFixture(t, "accountId:\n      type: string", "accountId:\n      type: integer")
+	commit := newGitRepo(t)
+	committed := map[string]string{}
+	for name, data := range files {
+		committed[name] = string(data)
+	}
+	commit(committed)
+	if err := os.WriteFile("schemas/account.yaml", changed["schemas/account.yaml"], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out, errOut bytes.Buffer
+	code := Run([]string{"diff", "--base-ref", "HEAD", "api.yaml"}, &out, &errOut)
+	if code != exitFailure || !strings.Contains(out.String(), "data[].accountId mudou de string para integer") {
+		t.Errorf("código %d, saída:\n%s%s", code, out.String(), errOut.String())
+	}
+}
@@ -0,0 +1,179 @@
+package validator
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Arquivo com padrões no estilo .gitignore aplicados na busca de especificações
+const ignoreFileName = ".openapiignore"
+
+// Padrões ignorados sempre: artefatos gerados pelo próprio validador e dependências
+var defaultIgnorePatterns = []string{"*Resolve.yaml", "*Resolve.yml", "*Resolve.json", "node_modules/", ".git/", projectCacheDir + "/"}
+
+// Flag que pode ser repetida (--exclude a --exclude b)
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// Um padrão no estilo .gitignore
+type ignorePattern struct {
+	re       *regexp.Regexp
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// Conjunto ordenado de padrões; o último padrão que casar decide
+type ignoreMatcher struct {
+	patterns []ignorePattern
+}
+
+// Função para montar o matcher a partir dos padrões padrão, do .openapiignore e de --exclude
+func newIgnoreMatcher(root string, extra []string) (*ignoreMatcher, error) {
+	m := &ignoreMatcher{}
+	m.add(defaultIgnorePatterns...)
+
+	file, err := os.Open(filepath.Join(root, ignoreFileName))
+	if err == nil {
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			m.add(scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	m.add(extra...)
+	return m, nil
+}
+
+func (m *ignoreMatcher) add(lines ...string) {
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p := ignorePattern{}
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.HasPrefix(line, "/") || strings.Contains(line, "/") {
+			p.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		p.re = regexp.MustCompile("^" + globToRegexp(line) + "$")
+		m.patterns = append(m.patterns, p)
+	}
+}
+
+// Converte um glob do .gitignore (*, ?, **) em expressão regular
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case c == '*' && i+1 < len(glob) && glob[i+1] == '*':
+			i++
+			if i+1 < len(glob) && glob[i+1] == '/' {
+				i++
+				b.WriteString("(?:.*/)?")
+			} else {
+				b.WriteString(".*")
+			}
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// Indica se o caminho (relativo à raiz, com /) deve ser ignorado
+func (m *ignoreMatcher) ignored(rel string, isDir bool) bool {
+	ignored := false
+	for _, p := range m.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		target := rel
+		if !p.anchored {
+			target = path.Base(rel)
+		}
+		if p.re.MatchString(target) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// Função para encontrar as especificações OpenAPI em um diretório, respeitando os padrões ignorados
+func discoverSpecs(root string, exclude []string) ([]string, error) {
+	matcher, err := newIgnoreMatcher(root, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	var specs []string
+	err = filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil || rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if matcher.ignored(rel, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".yaml", ".yml", ".json":
+			if looksLikeOpenAPI(p) {
+				specs = append(specs, p)
+			}
+		}
+		return nil
+	})
+	sort.Strings(specs)
+	return specs, err
+}
+
+// Verificação rápida: o arquivo possui a chave openapi ou swagger na raiz
+var rootVersionPattern = regexp.MustCompile(`(?m)^(?:openapi|swagger)\s*:|"(?:openapi|swagger)"\s*:`)
+
+func looksLikeOpenAPI(p string) bool {
+	data, err := readSpecFile(p)
+	if err != nil {
+		return false
+	}
+	return rootVersionPattern.Match(data)
+}
@@ -0,0 +1,168 @@
+package validator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Função para conferir os discriminators da especificação (antes da resolução, para
+// que os $refs dos membros ainda identifiquem os schemas): cada valor de mapping
+// precisa levar a um schema existente, o propertyName precisa existir e ser
+// obrigatório em cada variante e cada membro de oneOf/anyOf precisa estar no
+// mapping ou ser um $ref para components/schemas (mapeamento implícito pelo nome)
+func discriminatorViolations(inputFile string, root *yaml.Node) []Violation {
+	validator := newSchemaValidator(root)
+	var violations []Violation
+	visitSchemas(root, nil, func(schema *yaml.Node, path string) {
+		discriminator := mappingValue(schema, "discriminator")
+		if discriminator == nil {
+			return
+		}
+		report := func(format string, args ...interface{}) {
+			violations = append(violations, Violation{RuleID: "discriminator", Severity: "error", Message: fmt.Sprintf("Discriminator inválido: %s.", fmt.Sprintf(format, args...)), JSONPath: path, Line: discriminator.Line})
+		}
+
+		// Swagger 2.0: discriminator é o nome da propriedade, definida no próprio schema
+		if discriminator.Kind == yaml.ScalarNode {
+			if problem := discriminatorPropertyProblem(validator, schema, discriminator.Value); problem != "" {
+				report("a propriedade %s %s", discriminator.Value, problem)
+			}
+			return
+		}
+		property := mappingValue(discriminator, "propertyName")
+		if property == nil || property.Value == "" {
+			report("propertyName ausente")
+			return
+		}
+
+		// Variantes: os membros de oneOf/anyOf ou, sem eles (herança com allOf), os destinos do mapping
+		type variant struct {
+			label  string
+			ref    string
+			schema *yaml.Node
+		}
+		var variants []variant
+		hasMembers := mappingValue(schema, "oneOf") != nil || mappingValue(schema, "anyOf") != nil
+		for _, key := range []string{"oneOf", "anyOf"} {
+			members := mappingValue(schema, key)
+			if members == nil {
+				continue
+			}
+			for i, member := range members.Content {
+				label := fmt.Sprintf("%s[%d]", key, i)
+				ref := ""
+				target := member
+				if r := mappingValue(member, "$ref"); r != nil {
+					ref = r.Value
+					label = r.Value
+					target, _ = resolveSchemaRef(inputFile, validator.root, ref)
+				}
+				variants = append(variants, variant{label, ref, target})
+			}
+		}
+
+		mapped := map[string]bool{}
+		if mapping := mappingValue(discriminator, "mapping"); mapping != nil && mapping.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(mapping.Content); i += 2 {
+				value, target := mapping.Content[i].Value, mapping.Content[i+1]
+				ref := target.Value
+				if !strings.Contains(ref, "#") && !strings.Contains(ref, "/") {
+					ref = "#/components/schemas/" + ref // nome do schema
+				}
+				mapped[ref] = true
+				resolved, found := resolveSchemaRef(inputFile, validator.root, ref)
+				if !found {
+					report("o valor %q do mapping aponta para %s, que não existe", value, target.Value)
+					continue
+				}
+				if !hasMembers {
+					variants = append(variants, variant{ref, ref, resolved})
+				}
+			}
+		}
+
+		for _, v := range variants {
+			// Sem o schema ($ref quebrado, já relatado pelo índice, ou remoto) não há o que conferir
+			if v.schema != nil {
+				if problem := discriminatorPropertyProblem(validator, v.schema, property.Value); problem != "" {
+					report("a propriedade %s %s em %s", property.Value, problem, v.label)
+				}
+			}
+			if !hasMembers {
+				continue
+			}
+			switch {
+			case mapped[v.ref]:
+			case v.ref == "":
+				report("o membro %s é um schema em linha, que só pode ser identificado por uma entrada em mapping com $ref", v.label)
+			case !strings.HasPrefix(v.ref, "#/components/schemas/"):
+				report("o membro %s não está no mapping e o mapeamento implícito pelo nome só vale para #/components/schemas", v.label)
+			}
+		}
+	})
+	return violations
+}
+
+// Problema da propriedade do discriminator em uma variante (com as partes de allOf
+// combinadas): "não existe" ou "não é obrigatória"; vazio quando está correta
+func discriminatorPropertyProblem(v *schemaValidator, schema *yaml.Node, property string) string {
+	defined, required := false, false
+	for _, part := range flattenAllOf(v, schema, "", 0) {
+		if mappingValue(mappingValue(part.schema, "properties"), property) != nil {
+			defined = true
+		}
+		if list := mappingValue(part.schema, "required"); list != nil {
+			for _, name := range list.Content {
+				if name.Value == property {
+					required = true
+				}
+			}
+		}
+	}
+	switch {
+	case !defined:
+		return "não existe"
+	case !required:
+		return "não é obrigatória"
+	}
+	return ""
+}
+
+// Schema apontado por um $ref local ou para outro arquivo (relativo à especificação).
+// found é falso quando o destino não existe; $refs remotos não são buscados e
+// retornam nil com found verdadeiro.
+func resolveSchemaRef(inputFile string, root *yaml.Node, ref string) (target *yaml.Node, found bool) {
+	for depth := 0; depth < maxSchemaRefDepth; depth++ {
+		file, pointer := splitRef(ref)
+		doc := root
+		if file != "" {
+			if isRemoteURL(file) {
+				return nil, true
+			}
+			data, err := readSpecFile(filepath.Join(filepath.Dir(inputFile), filepath.FromSlash(file)))
+			if err != nil {
+				return nil, false
+			}
+			if doc, err = parseSpec(data, file); err != nil {
+				return nil, false
+			}
+			if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+				doc = doc.Content[0]
+			}
+		}
+		node, err := resolvePointer(doc, pointer)
+		if err != nil {
+			return nil, false
+		}
+		// Um schema que é só um $ref local é seguido até o destino
+		inner := mappingValue(node, "$ref")
+		if inner == nil || file != "" {
+			return node, true
+		}
+		ref = inner.Value
+	}
+	return nil, true
+}
@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const discriminatorSpec = `openapi: 3.0.3
+info: {title: Pagamentos, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    Pagamento:
+      oneOf:
+        - $ref: '#/components/schemas/Pix'
+        - $ref: '#/components/schemas/Boleto'
+        - $ref: '#/components/schemas/Ted'
+        - {type: object}
+      discriminator:
+        propertyName: tipo
+        mapping:
+          pix: '#/components/schemas/Pix'
+          cartao: '#/components/schemas/Cartao'
+    Pix:
+      type: object
+      required: [tipo]
+      properties:
+        tipo: {type: string}
+    Boleto:
+      type: object
+      properties:
+        tipo: {type: string}
+    Ted:
+      allOf:
+        - {type: object, required: [tipo]}
+        - properties:
+            tipo: {type: string}
+`
+
+// O mapping para um schema inexistente, a propriedade que não é obrigatória e o membro
+// em linha são relatados no schema pai; Boleto (implícito pelo nome) e Ted (propriedade
+// e required em partes diferentes do allOf) passam
+func TestDiscriminatorViolations(t *testing.T) {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(discriminatorSpec), &root); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range discriminatorViolations("api.yaml", &root) {
+		if v.JSONPath != "$.components.schemas.Pagamento" || v.Line != 13 {
+			t.Errorf("violação em %s, linha %d: %s", v.JSONPath, v.Line, v.Message)
+		}
+		got = append(got, v.Message)
+	}
+	want := []string{
+		`Discriminator inválido: o valor "cartao" do mapping aponta para #/components/schemas/Cartao, que não existe.`,
+		"Discriminator inválido: a propriedade tipo não é obrigatória em #/components/schemas/Boleto.",
+		"Discriminator inválido: a propriedade tipo não existe em oneOf[3].",
+		"Discriminator inválido: o membro oneOf[3] é um schema em linha, que só pode ser identificado por uma entrada em mapping com $ref.",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("violações:\n%q\nesperado:\n%q", got, want)
+	}
+}
@@ -0,0 +1,10 @@
+// Package validator valida e resolve especificações OpenAPI com as regras do Open
+// Finance Brasil. A CLI (rules/) é um invólucro de Main; outros serviços Go usam
+// Validate, Resolve e Diff, que recebem as especificações em memória e retornam os
+// resultados em vez de imprimi-los:
+//
+//	import "github.com/OpenBanking-Brasil/OFB-CI-CD/pkg/validator"
+//
+// Cada chamada usa apenas a configuração das próprias Options, e chamadas
+// simultâneas não interferem entre si.
+package validator
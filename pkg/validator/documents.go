@@ -0,0 +1,75 @@
+package validator
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Documento escolhido com --select-document em uma especificação com vários
+// documentos YAML (a partir de 1; 0 exige um único documento)
+var selectDocument int
+
+func registerSelectDocumentFlag(fs *flag.FlagSet) {
+	fs.IntVar(&selectDocument, "select-document", 0, "em uma especificação com vários documentos YAML (separados por ---), usa o documento N (a partir de 1)")
+}
+
+// Confere o valor de --select-document
+func checkSelectDocumentFlag() error {
+	if selectDocument < 0 {
+		return fmt.Errorf("valor inválido para --select-document: %d", selectDocument)
+	}
+	return nil
+}
+
+// Função para ler todos os documentos YAML do conteúdo. yaml.Unmarshal usaria só o
+// primeiro e o restante sumiria da validação sem aviso, então mais de um documento
+// é erro, a menos que selected (a partir de 1) escolha um deles.
+func parseSpecDocument(data []byte, source string, selected int) (*yaml.Node, error) {
+	if isJSONContent(data) {
+		if err := checkJSONSyntax(data, source); err != nil {
+			return nil, err
+		}
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	var docs []*yaml.Node
+	for {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, yamlSyntaxError(data, source, err)
+		}
+		docs = append(docs, &node)
+	}
+
+	switch {
+	case len(docs) == 0:
+		// Arquivo vazio ou só com comentários; checkOpenAPIDocument explica o problema
+		return &yaml.Node{}, nil
+	case selected > len(docs):
+		return nil, fmt.Errorf("--select-document %d, mas %s tem %d documento(s) YAML", selected, source, len(docs))
+	case selected > 0:
+		return docs[selected-1], nil
+	case len(docs) == 1:
+		return docs[0], nil
+	}
+
+	var starts, empty []string
+	for i, doc := range docs {
+		starts = append(starts, fmt.Sprintf("%d (linha %d)", i+1, doc.Line))
+		if len(doc.Content) == 0 || doc.Content[0].Tag == "!!null" {
+			empty = append(empty, strconv.Itoa(i+1))
+		}
+	}
+	message := fmt.Sprintf("%s tem %d documentos YAML separados por ---: %s", source, len(docs), strings.Join(starts, ", "))
+	if len(empty) > 0 {
+		message += "; vazios: " + strings.Join(empty, ", ")
+	}
+	return nil, fmt.Errorf("%s. Apenas um documento é validado: remova os separadores extras ou escolha um com --select-document N", message)
+}
@@ -0,0 +1,50 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+const multiDocumentSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths: {}
+---
+openapi: 3.0.3
+info: {title: Cartões, version: 1.0.0}
+paths: {}
+---
+`
+
+// Mais de um documento YAML é erro, com a contagem, as linhas e os vazios, a menos
+// que --select-document escolha um deles
+func TestParseSpecDocument(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		selected int
+		title    string
+		err      string
+	}{
+		{"um documento", "openapi: 3.0.3\ninfo: {title: Contas, version: 1.0.0}\n", 0, "Contas", ""},
+		{"vários documentos", multiDocumentSpec, 0, "", "api.yaml tem 3 documentos YAML separados por ---: 1 (linha 1), 2 (linha 4), 3 (linha 8); vazios: 3. Apenas um documento é validado"},
+		{"documento escolhido", multiDocumentSpec, 2, "Cartões", ""},
+		{"documento além do fim", multiDocumentSpec, 4, "", "--select-document 4, mas api.yaml tem 3 documento(s) YAML"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseSpecDocument([]byte(tt.data), "api.yaml", tt.selected)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("erro %v, esperado %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if title := mappingValue(mappingValue(doc.Content[0], "info"), "title"); title == nil || title.Value != tt.title {
+				t.Errorf("título %v, esperado %s", title, tt.title)
+			}
+		})
+	}
+}
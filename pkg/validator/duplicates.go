@@ -0,0 +1,227 @@
+package validator
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schemas de components estruturalmente idênticos depois da resolução (--duplicates).
+// Cada schema é reduzido a uma forma canônica (chaves em ordem, sem a description do
+// próprio componente); com exact, o grupo é de schemas com a mesma forma e, com near,
+// também dos que diferem só em descrições, títulos e exemplos em qualquer nível.
+// Schemas que são apenas um $ref para outro ficam de fora: são apelidos, não cópias.
+var duplicateSchemas = "off"
+
+func registerDuplicatesFlag(fs *flag.FlagSet) {
+	fs.StringVar(&duplicateSchemas, "duplicates", duplicateSchemas, "agrupa os schemas de components idênticos depois da resolução: off, exact ou near (também os que diferem só em descrições e exemplos)")
+}
+
+func checkDuplicatesFlag() error {
+	switch duplicateSchemas {
+	case "off", "exact", "near":
+		return nil
+	}
+	return fmt.Errorf("valor inválido para --duplicates: %q (use off, exact ou near)", duplicateSchemas)
+}
+
+// Schemas idênticos entre si, na ordem em que aparecem no documento
+type duplicateGroup struct {
+	Schemas []duplicateSchema `json:"schemas"`
+	Near    bool              `json:"near,omitempty"` // diferem em descrições ou exemplos (--duplicates near)
+}
+
+// Um schema do grupo, com a quantidade de $refs locais que apontam para ele
+type duplicateSchema struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Line int    `json:"line,omitempty"`
+	Refs int    `json:"refs"`
+}
+
+// Função para agrupar os schemas de components (definitions no Swagger 2.0 sem
+// conversão) com a mesma forma canônica no documento resolvido; source é o documento
+// original, de onde vêm as linhas, os apelidos e a contagem de $refs
+func findDuplicateSchemas(source, resolved *yaml.Node, near bool) []duplicateGroup {
+	section, path, prefix := "components.schemas", "$.components.schemas", "#/components/schemas/"
+	if isSwagger2(source) {
+		section, path, prefix = "definitions", "$.definitions", "#/definitions/"
+	}
+	lookup := func(root *yaml.Node) *yaml.Node {
+		node := documentContent(root)
+		for _, key := range strings.Split(section, ".") {
+			node = mappingValue(node, key)
+		}
+		return node
+	}
+	schemas, resolvedSchemas := lookup(source), lookup(resolved)
+	if schemas == nil || resolvedSchemas == nil || schemas.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	refs := map[string]int{}
+	forEachRef(documentContent(source), func(ref *yaml.Node, _ string) {
+		if strings.HasPrefix(ref.Value, prefix) {
+			refs[ref.Value]++
+		}
+	})
+
+	exact := newSchemaCanonicalizer(false)
+	loose := newSchemaCanonicalizer(true)
+	var order []string
+	groups := map[string][]duplicateSchema{}
+	forms := map[string]map[string]bool{}
+	for i := 0; i+1 < len(schemas.Content); i += 2 {
+		key, original := schemas.Content[i], schemas.Content[i+1]
+		if mappingValue(original, "$ref") != nil {
+			continue
+		}
+		node := mappingValue(resolvedSchemas, key.Value)
+		if node == nil {
+			continue
+		}
+		form := exact.top(node)
+		group := form
+		if near {
+			group = loose.top(node)
+		}
+		if _, ok := groups[group]; !ok {
+			order = append(order, group)
+			forms[group] = map[string]bool{}
+		}
+		forms[group][form] = true
+		groups[group] = append(groups[group], duplicateSchema{Name: key.Value, Path: joinPath(path, key.Value), Line: key.Line, Refs: refs[prefix+escapePointer(key.Value)]})
+	}
+
+	var result []duplicateGroup
+	for _, group := range order {
+		if len(groups[group]) > 1 {
+			result = append(result, duplicateGroup{Schemas: groups[group], Near: len(forms[group]) > 1})
+		}
+	}
+	return result
+}
+
+// Forma canônica dos schemas em texto JSON, guardada por nó: o documento resolvido
+// repete o mesmo nó em cada uso de um componente
+type schemaCanonicalizer struct {
+	near   bool
+	forms  map[canonicalKey]string
+	active map[*yaml.Node]bool
+}
+
+type canonicalKey struct {
+	node  *yaml.Node
+	names bool
+}
+
+func newSchemaCanonicalizer(near bool) *schemaCanonicalizer {
+	return &schemaCanonicalizer{near: near, forms: map[canonicalKey]string{}, active: map[*yaml.Node]bool{}}
+}
+
+// Forma canônica do schema de um componente, sem a description do próprio componente
+func (c *schemaCanonicalizer) top(node *yaml.Node) string {
+	for node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node == nil || node.Kind != yaml.MappingNode {
+		return c.form(node, false)
+	}
+	var b strings.Builder
+	c.writeMapping(&b, node, false, true)
+	return b.String()
+}
+
+// Forma canônica de um nó; names indica que as chaves do mapa são nomes de propriedades
+func (c *schemaCanonicalizer) form(node *yaml.Node, names bool) string {
+	for node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node == nil {
+		return "null"
+	}
+	key := canonicalKey{node, names}
+	if form, ok := c.forms[key]; ok {
+		return form
+	}
+	// Um nó que contém a si mesmo (ciclo mantido pela resolução) não é expandido de novo
+	if c.active[node] {
+		return `"<ciclo>"`
+	}
+	c.active[node] = true
+	defer delete(c.active, node)
+
+	var b strings.Builder
+	switch node.Kind {
+	case yaml.MappingNode:
+		c.writeMapping(&b, node, names, false)
+	case yaml.SequenceNode:
+		b.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(c.form(item, false))
+		}
+		b.WriteByte(']')
+	default:
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			value = node.Value
+		}
+		text, err := json.Marshal(value)
+		if err != nil {
+			text, _ = json.Marshal(node.Value)
+		}
+		b.Write(text)
+	}
+	form := b.String()
+	c.forms[key] = form
+	return form
+}
+
+func (c *schemaCanonicalizer) writeMapping(b *strings.Builder, node *yaml.Node, names, top bool) {
+	type entry struct{ key, form string }
+	entries := make([]entry, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		if !names && (top && key == "description" || c.near && nonStructuralKeys[key]) {
+			continue
+		}
+		entries = append(entries, entry{key, c.form(node.Content[i+1], !names && namedMapKeys[key])})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+	b.WriteByte('{')
+	for i, e := range entries {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(e.key)
+		b.Write(key)
+		b.WriteByte(':')
+		b.WriteString(e.form)
+	}
+	b.WriteByte('}')
+}
+
+// Uma violação informativa por grupo, na linha do primeiro schema
+func duplicateViolations(groups []duplicateGroup) []Violation {
+	violations := make([]Violation, 0, len(groups))
+	for _, g := range groups {
+		names := make([]string, 0, len(g.Schemas))
+		for _, s := range g.Schemas {
+			names = append(names, fmt.Sprintf("%s (refs: %d)", s.Name, s.Refs))
+		}
+		message := "Schemas estruturalmente idênticos: " + strings.Join(names, ", ") + "."
+		if g.Near {
+			message = "Schemas que diferem só em descrições e exemplos: " + strings.Join(names, ", ") + "."
+		}
+		first := g.Schemas[0]
+		violations = append(violations, Violation{RuleID: "duplicate-schemas", Severity: "info", Message: message, JSONPath: first.Path, Line: first.Line, Suggestion: "Mantenha um dos schemas e aponte os $refs dos demais para ele."})
+	}
+	return violations
+}
@@ -0,0 +1,81 @@
+package validator
+
+import (
+	"testing"
+)
+
+const duplicatesSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    Valor:
+      description: Valor da transação
+      type: object
+      properties:
+        amount: {type: string, description: Valor}
+        currency: {type: string}
+    Montante:
+      properties:
+        currency: {type: string}
+        amount: {type: string, description: Valor}
+      type: object
+    Quantia:
+      type: object
+      properties:
+        amount: {type: string, description: Quantia em reais, example: '10.00'}
+        currency: {type: string}
+    Apelido:
+      $ref: '#/components/schemas/Valor'
+    Texto:
+      type: object
+      properties:
+        description: {type: string}
+    Nota:
+      type: object
+      properties:
+        observacao: {type: string}
+    Uso:
+      properties:
+        valor: {$ref: '#/components/schemas/Valor'}
+`
+
+func duplicateNames(groups []duplicateGroup) [][]string {
+	var names [][]string
+	for _, g := range groups {
+		var group []string
+		for _, s := range g.Schemas {
+			group = append(group, s.Name)
+		}
+		names = append(names, group)
+	}
+	return names
+}
+
+// Com exact, só os schemas com a mesma forma (a ordem das chaves e a description do
+// componente não contam); apelidos ($ref) ficam de fora e uma propriedade chamada
+// description continua sendo estrutura
+func TestDuplicateSchemasExact(t *testing.T) {
+	root := mustParseYAML(t, duplicatesSpec)
+	groups := findDuplicateSchemas(root, root, false)
+	if len(groups) != 1 || groups[0].Near {
+		t.Fatalf("esperado um grupo exato, encontrados %v", duplicateNames(groups))
+	}
+	g := groups[0]
+	if len(g.Schemas) != 2 || g.Schemas[0].Name != "Valor" || g.Schemas[1].Name != "Montante" {
+		t.Fatalf("grupo %v, esperado [Valor Montante]", duplicateNames(groups))
+	}
+	if g.Schemas[0].Refs != 2 || g.Schemas[0].Line != 6 || g.Schemas[0].Path != "$.components.schemas.Valor" {
+		t.Errorf("Valor: %+v, esperado 2 $refs na linha 6", g.Schemas[0])
+	}
+}
+
+// Com near, entram também os que diferem só em descrições e exemplos, e o grupo é
+// marcado como aproximado
+func TestDuplicateSchemasNear(t *testing.T) {
+	root := mustParseYAML(t, duplicatesSpec)
+	groups := findDuplicateSchemas(root, root, true)
+	if len(groups) != 1 || !groups[0].Near || len(groups[0].Schemas) != 3 || groups[0].Schemas[2].Name != "Quantia" {
+		t.Fatalf("esperado o grupo aproximado [Valor Montante Quantia], encontrados %v", duplicateNames(groups))
+	}
+}
@@ -0,0 +1,121 @@
+package validator
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Regrava os arquivos esperados em testdata/golden: go test -run TestEndToEnd -update
+var update = flag.Bool("update", false, "regrava os arquivos esperados em testdata/golden")
+
+// Registra os pacotes embarcados do binário (rules/main.go) antes dos testes
+func TestMain(m *testing.M) {
+	for _, pack := range []struct {
+		name, file string
+		extensions bool
+	}{
+		{"ofb", "pb33f_rules.yaml", false},
+		{"ofb-ordering", "ofb_ordering.yaml", false},
+		{"ofb", "ofb_extensions.yaml", true},
+	} {
+		data, err := os.ReadFile(filepath.Join("..", "..", "rules", pack.file))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if pack.extensions {
+			RegisterExtensions(pack.name, data)
+		} else {
+			RegisterRuleset(pack.name, data)
+		}
+	}
+	os.Exit(m.Run())
+}
+
+// Especificações de testdata/e2e conferidas de ponta a ponta
+var e2eFixtures = []string{
+	"valid",      // sem violações
+	"violations", // uma violação de cada regra do pacote ofb
+	"multi",      // $refs para outros arquivos
+	"circular",   // schema que se refere a si mesmo
+	"swagger2",   // Swagger 2.0
+	"utf16",      // UTF-16LE com BOM
+	"broken",     // YAML com erro de sintaxe
+}
+
+// Formatos de saída do validate, com a extensão do arquivo esperado
+var e2eFormats = []struct{ format, ext string }{
+	{"text", "txt"},
+	{"json", "json"},
+	{"sarif", "sarif"},
+}
+
+// Executa o validate em cada especificação e formato e compara a saída (stdout, stderr e
+// código de saída) com testdata/golden/<especificação>.<formato>
+func TestEndToEnd(t *testing.T) {
+	for _, fixture := range e2eFixtures {
+		for _, f := range e2eFormats {
+			t.Run(fixture+"/"+f.format, func(t *testing.T) {
+				spec := filepath.Join("testdata", "e2e", fixture, "api.yaml")
+				var out, errOut bytes.Buffer
+				code := Run([]string{"validate", "--no-cache", "--format", f.format, spec}, &out, &errOut)
+				got := normalizeOutput(t, fmt.Sprintf("exit: %d\n--- stdout\n%s--- stderr\n%s", code, out.String(), errOut.String()))
+				compareGolden(t, filepath.Join("testdata", "golden", fixture+"."+f.ext), got)
+			})
+		}
+	}
+}
+
+// Troca o diretório do pacote pelo marcador $DIR, para que os arquivos esperados não
+// dependam de onde o repositório foi clonado
+func normalizeOutput(t *testing.T, output string) string {
+	t.Helper()
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	output = strings.ReplaceAll(output, filepath.ToSlash(dir), "$DIR")
+	return strings.ReplaceAll(output, dir, "$DIR")
+}
+
+// Compara a saída com o arquivo esperado, ou o regrava com -update
+func compareGolden(t *testing.T, golden, got string) {
+	t.Helper()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v (gere com go test -run %s -update)", err, t.Name())
+	}
+	if got != string(want) {
+		t.Errorf("a saída difere de %s (regrave com -update se a mudança for intencional)\n--- esperado\n%s\n--- obtido\n%s", golden, want, got)
+	}
+}
+
+// Uma especificação sem $refs sai do resolve exatamente como entrou: linhas em branco,
+// espaços no fim da linha, textos dobrados e URLs em mappings em fluxo
+func TestResolveRoundTrip(t *testing.T) {
+	spec := filepath.Join("testdata", "e2e", "roundtrip", "api.yaml")
+	output := filepath.Join(t.TempDir(), "resolvido.yaml")
+	var out, errOut bytes.Buffer
+	if code := Run([]string{"resolve", "-o", output, spec}, &out, &errOut); code != 0 {
+		t.Fatalf("exit %d\n%s%s", code, out.String(), errOut.String())
+	}
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareGolden(t, spec, string(got))
+}
@@ -0,0 +1,199 @@
+package validator
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Codificação das especificações de entrada (--encoding); "auto" detecta pelo conteúdo
+var inputEncoding = "auto"
+
+// Codificações aceitas em --encoding
+var textEncodings = map[string]encoding.Encoding{
+	"utf-8":        unicode.UTF8BOM,
+	"utf-16le":     unicode.UTF16(unicode.LittleEndian, unicode.UseBOM),
+	"utf-16be":     unicode.UTF16(unicode.BigEndian, unicode.UseBOM),
+	"iso-8859-1":   charmap.ISO8859_1,
+	"windows-1252": charmap.Windows1252,
+}
+
+// Nomes alternativos aceitos em --encoding
+var encodingAliases = map[string]string{"utf8": "utf-8", "utf16le": "utf-16le", "utf16be": "utf-16be", "latin1": "iso-8859-1", "latin-1": "iso-8859-1", "cp1252": "windows-1252"}
+
+func registerEncodingFlag(fs *flag.FlagSet) {
+	fs.StringVar(&inputEncoding, "encoding", inputEncoding, "codificação das especificações: auto, utf-8, utf-16le, utf-16be, iso-8859-1 ou windows-1252")
+}
+
+// Confere e normaliza o valor de --encoding
+func checkEncodingFlag() error {
+	name, err := normalizeEncoding(inputEncoding)
+	if err != nil {
+		return fmt.Errorf("valor inválido para --encoding: %v", err)
+	}
+	inputEncoding = name
+	return nil
+}
+
+// Nome de uma codificação aceita, em minúsculas e sem apelidos; vazio é "auto"
+func normalizeEncoding(value string) (string, error) {
+	name := strings.ToLower(strings.TrimSpace(value))
+	if alias, ok := encodingAliases[name]; ok {
+		name = alias
+	}
+	if name == "" {
+		name = "auto"
+	}
+	if _, ok := textEncodings[name]; !ok && name != "auto" {
+		return "", fmt.Errorf("%q (use auto, utf-8, utf-16le, utf-16be, iso-8859-1 ou windows-1252)", value)
+	}
+	return name, nil
+}
+
+// Função para detectar a codificação pelo conteúdo: BOM, bytes nulos alternados
+// (UTF-16 sem BOM, comum em exportações de ferramentas do Windows) e, quando há
+// sequências inválidas em UTF-8, ISO-8859-1
+func detectEncoding(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return "utf-8"
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return "utf-16le"
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return "utf-16be"
+	}
+
+	// Texto ASCII em UTF-16 tem um byte nulo em cada par, na posição par ou ímpar
+	sample := data
+	if len(sample) > 4096 {
+		sample = sample[:4096]
+	}
+	if len(sample) >= 4 {
+		var evenZeros, oddZeros int
+		for i, b := range sample {
+			if b == 0 {
+				if i%2 == 0 {
+					evenZeros++
+				} else {
+					oddZeros++
+				}
+			}
+		}
+		pairs := len(sample) / 2
+		switch {
+		case oddZeros*10 >= pairs*4 && evenZeros*10 < pairs:
+			return "utf-16le"
+		case evenZeros*10 >= pairs*4 && oddZeros*10 < pairs:
+			return "utf-16be"
+		}
+	}
+
+	if !utf8.Valid(data) {
+		return "iso-8859-1"
+	}
+	return "utf-8"
+}
+
+// Avisos de codificação já emitidos pela linha de comando, um por arquivo em cada
+// execução; cada chamada da API tem o seu conjunto (runSettings.encodingWarned)
+var cliEncodingWarned = &sync.Map{}
+
+// Função para converter o conteúdo para UTF-8 a partir da codificação informada ou,
+// com "auto", da detectada. Conversões de outras codificações são avisadas em s.log,
+// uma vez por arquivo.
+func (s *runSettings) decodeText(data []byte, source, encodingName string) ([]byte, error) {
+	name := encodingName
+	if name == "" || name == "auto" {
+		name = detectEncoding(data)
+	}
+	// UTF-8, o caso comum, não é copiado: basta remover o BOM
+	if name == "utf-8" {
+		return bytes.TrimPrefix(data, []byte{0xEF, 0xBB, 0xBF}), nil
+	}
+	decoded, err := textEncodings[name].NewDecoder().Bytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao converter %s de %s para UTF-8: %v", source, name, err)
+	}
+	if name != "utf-8" {
+		if !s.encodingAlreadyWarned(source) {
+			how := "detectada"
+			if encodingName != "" && encodingName != "auto" {
+				how = "informada em --encoding"
+			}
+			fmt.Fprintf(s.log, "ℹ️  %s: codificação %s (%s) convertida para UTF-8\n", source, name, how)
+		}
+	}
+	return decoded, nil
+}
+
+// Registra o aviso de conversão do arquivo e indica se ele já tinha sido emitido;
+// sem conjunto de avisos, todos são emitidos
+func (s *runSettings) encodingAlreadyWarned(source string) bool {
+	if s.encodingWarned == nil {
+		return false
+	}
+	_, warned := s.encodingWarned.LoadOrStore(source, true)
+	return warned
+}
+
+// Sistema de arquivos que entrega os arquivos convertidos para UTF-8, usado pelo
+// rolodex para que os arquivos referenciados passem pela mesma conversão; os
+// diretórios passam sem conversão para que o rolodex possa percorrê-los
+type decodingFS struct {
+	fsys     fs.FS
+	settings *runSettings
+}
+
+func (d decodingFS) Open(name string) (fs.File, error) {
+	f, err := d.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		return f, nil
+	}
+	defer f.Close()
+	s := d.settings
+	s.progress.loaded(name)
+	if s.maxFileSize > 0 && info.Size() > int64(s.maxFileSize) {
+		return nil, s.fileSizeError(name, info.Size())
+	}
+	data, err := s.readSized(f, name, info.Size())
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := s.decodeText(data, name, s.encoding)
+	if err != nil {
+		return nil, err
+	}
+	return &decodedFile{Reader: bytes.NewReader(decoded), info: decodedInfo{FileInfo: info, size: int64(len(decoded))}}, nil
+}
+
+type decodedFile struct {
+	*bytes.Reader
+	info decodedInfo
+}
+
+func (f *decodedFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *decodedFile) Close() error               { return nil }
+
+// Informações do arquivo original com o tamanho do conteúdo convertido
+type decodedInfo struct {
+	fs.FileInfo
+	size int64
+}
+
+func (i decodedInfo) Size() int64 { return i.size }
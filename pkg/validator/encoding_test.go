@@ -0,0 +1,137 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// A detecção reconhece o BOM, o UTF-16 sem BOM pelos bytes nulos e o ISO-8859-1 pelas
+// sequências inválidas em UTF-8
+func TestDetectEncoding(t *testing.T) {
+	text := "info: {title: Cartões de crédito}\n"
+	encode := func(t *testing.T, name string) []byte {
+		t.Helper()
+		data, err := textEncodings[name].NewEncoder().Bytes([]byte(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	utf16NoBOM := func(t *testing.T, order unicode.Endianness) []byte {
+		t.Helper()
+		data, err := unicode.UTF16(order, unicode.IgnoreBOM).NewEncoder().Bytes([]byte(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	for _, tt := range []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"UTF-8", []byte(text), "utf-8"},
+		{"UTF-8 com BOM", append([]byte{0xEF, 0xBB, 0xBF}, text...), "utf-8"},
+		{"UTF-16LE com BOM", encode(t, "utf-16le"), "utf-16le"},
+		{"UTF-16BE com BOM", encode(t, "utf-16be"), "utf-16be"},
+		{"UTF-16LE sem BOM", utf16NoBOM(t, unicode.LittleEndian), "utf-16le"},
+		{"UTF-16BE sem BOM", utf16NoBOM(t, unicode.BigEndian), "utf-16be"},
+		{"ISO-8859-1", encode(t, "iso-8859-1"), "iso-8859-1"},
+		{"curto demais para UTF-16", []byte("a\x00"), "utf-8"},
+	} {
+		if got := detectEncoding(tt.data); got != tt.want {
+			t.Errorf("%s: detectado %s, esperado %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+// A mesma especificação, gravada em cada codificação, gera o mesmo documento resolvido
+// que a original em UTF-8, com o aviso da conversão; o Windows-1252 (aspas curvas)
+// só com --encoding
+func TestEncodingFixtures(t *testing.T) {
+	source := mustReadFile(t, filepath.Join("testdata", "e2e", "valid", "api.yaml"))
+	source = bytes.Replace(source, []byte("title: "), []byte("title: “Aspas” "), 1)
+	dir := t.TempDir()
+	original := filepath.Join(dir, "original.yaml")
+	if err := os.WriteFile(original, source, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	want := resolveArtifact(t, original)
+
+	latin1 := bytes.Replace(source, []byte("“Aspas” "), nil, 1)
+	wantLatin1 := resolveArtifact(t, writeTemp(t, dir, "latin1-original.yaml", latin1))
+	for _, tt := range []struct {
+		name     string
+		encoder  func([]byte) ([]byte, error)
+		source   []byte
+		want     []byte
+		args     []string
+		detected string
+	}{
+		{"utf-16le", unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewEncoder().Bytes, source, want, nil, "utf-16le (detectada)"},
+		{"utf-16be", unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewEncoder().Bytes, source, want, nil, "utf-16be (detectada)"},
+		{"iso-8859-1", charmap.ISO8859_1.NewEncoder().Bytes, latin1, wantLatin1, nil, "iso-8859-1 (detectada)"},
+		{"windows-1252", charmap.Windows1252.NewEncoder().Bytes, source, want, []string{"--encoding", "cp1252"}, "windows-1252 (informada em --encoding)"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.encoder(tt.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			spec := writeTemp(t, t.TempDir(), "api.yaml", data)
+			output := filepath.Join(t.TempDir(), "resolvido.yaml")
+			var out, errOut bytes.Buffer
+			if code := Run(append(append([]string{"resolve", "--no-cache", "-o", output}, tt.args...), spec), &out, &errOut); code != exitOK {
+				t.Fatalf("exit %d\n%s%s", code, out.String(), errOut.String())
+			}
+			if got := mustReadFile(t, output); !bytes.Equal(got, tt.want) {
+				t.Errorf("documento resolvido difere do original em UTF-8:\n%s", got)
+			}
+			if message := "codificação " + tt.detected + " convertida para UTF-8"; !strings.Contains(out.String()+errOut.String(), message) {
+				t.Errorf("sem o aviso %q:\n%s%s", message, out.String(), errOut.String())
+			}
+		})
+	}
+
+	var out, errOut bytes.Buffer
+	if code := Run([]string{"resolve", "--encoding", "ebcdic", original}, &out, &errOut); code != exitUsage || !strings.Contains(out.String()+errOut.String(), `valor inválido para --encoding: "ebcdic"`) {
+		t.Errorf("exit %d, esperado erro de uso\n%s%s", code, out.String(), errOut.String())
+	}
+}
+
+// Grava o conteúdo em um arquivo do diretório e devolve o caminho
+func writeTemp(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// Cada chamada da API tem os próprios avisos: uma segunda chamada com a mesma
+// especificação também recebe o aviso da conversão no seu Logger
+func TestEncodingWarningPerCall(t *testing.T) {
+	spec, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().Bytes(mustReadFile(t, filepath.Join("testdata", "e2e", "valid", "api.yaml")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for call := 1; call <= 2; call++ {
+		var logged []string
+		logger := loggerFunc(func(format string, v ...interface{}) { logged = append(logged, fmt.Sprintf(format, v...)) })
+		if _, err := Resolve(context.Background(), spec, Options{Source: "api.yaml", Logger: logger}); err != nil {
+			t.Fatal(err)
+		}
+		if warning := "api.yaml: codificação utf-16le (detectada) convertida para UTF-8"; !strings.Contains(strings.Join(logged, "\n"), warning) {
+			t.Errorf("chamada %d sem o aviso %q: %q", call, warning, logged)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Desativa a expansão de ${VAR} nos arquivos de regras e configuração (--no-env-expansion)
+var noEnvExpansion bool
+
+// ${VAR} ou ${VAR:-padrão}; $${...} é mantido literalmente
+var envVarPattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+func registerEnvFlag(fs *flag.FlagSet) {
+	fs.BoolVar(&noEnvExpansion, "no-env-expansion", false, "não expande ${VAR} nos arquivos de regras e configuração (os baixados de URLs nunca são expandidos)")
+}
+
+// Função para expandir variáveis de ambiente no conteúdo de um arquivo.
+// Variáveis sem valor e sem padrão geram um único erro listando todas elas.
+// Conteúdo baixado de uma URL nunca é expandido: um arquivo de regras remoto poderia
+// citar ${GITHUB_TOKEN} em uma mensagem e expor o segredo no relatório.
+func expandEnv(data []byte, source string) ([]byte, error) {
+	if noEnvExpansion || isRemoteURL(source) {
+		return data, nil
+	}
+
+	var missing []string
+	seen := map[string]bool{}
+	expanded := envVarPattern.ReplaceAllStringFunc(string(data), func(match string) string {
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+		parts := envVarPattern.FindStringSubmatch(match)
+		if value, ok := os.LookupEnv(parts[1]); ok && value != "" {
+			return value
+		}
+		if parts[2] != "" {
+			return parts[3]
+		}
+		if !seen[parts[1]] {
+			seen[parts[1]] = true
+			missing = append(missing, parts[1])
+		}
+		return match
+	})
+
+	if len(missing) > 0 {
+		return nil, &undefinedEnvError{source: source, names: missing}
+	}
+	return []byte(expanded), nil
+}
+
+// Variáveis de ambiente sem valor e sem padrão em um arquivo
+type undefinedEnvError struct {
+	source string
+	names  []string
+}
+
+func (e *undefinedEnvError) Error() string {
+	return fmt.Sprintf("variáveis de ambiente não definidas em %s: %s (use ${VAR:-padrão} ou --no-env-expansion)", e.source, strings.Join(e.names, ", "))
+}
+
+// O problema sem o arquivo, para a lista de problemas das regras
+func (e *undefinedEnvError) problem() string {
+	return fmt.Sprintf("variáveis de ambiente não definidas: %s (use ${VAR:-padrão} ou --no-env-expansion)", strings.Join(e.names, ", "))
+}
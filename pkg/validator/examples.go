@@ -0,0 +1,89 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Com --validate-examples, os exemplos e valores padrão são conferidos contra os schemas
+var validateExamples bool
+
+func registerExamplesFlag(fs *flag.FlagSet) {
+	fs.BoolVar(&validateExamples, "validate-examples", false, "confere example, examples e default contra os schemas, no documento resolvido")
+}
+
+// Função para conferir os exemplos da especificação resolvida: example e default de
+// cada schema, e example/examples[*].value de parâmetros, cabeçalhos e media types
+// contra o schema correspondente. Exemplos com externalValue não são lidos.
+func exampleViolations(root *yaml.Node) []Violation {
+	checker := &exampleChecker{validator: newSchemaValidator(root), swagger2: isSwagger2(root)}
+	visitSchemas(root, checker.checkHolder, checker.checkSchema)
+	return checker.violations
+}
+
+type exampleChecker struct {
+	validator  *schemaValidator
+	swagger2   bool
+	violations []Violation
+}
+
+// Exemplos de um objeto com schema: example e examples (objetos Example com value ou
+// externalValue; em Swagger 2.0, examples das respostas associa o media type ao valor)
+func (c *exampleChecker) checkHolder(holder, schema *yaml.Node, path string) {
+	if example := mappingValue(holder, "example"); example != nil {
+		c.check(schema, example, joinPath(path, "example"), "exemplo")
+	}
+	examples := mappingValue(holder, "examples")
+	if examples == nil || examples.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(examples.Content); i += 2 {
+		name, example := examples.Content[i].Value, examples.Content[i+1]
+		examplePath := joinPath(joinPath(path, "examples"), name)
+		if c.swagger2 {
+			c.check(schema, example, examplePath, "exemplo")
+			continue
+		}
+		if value := mappingValue(example, "value"); value != nil {
+			c.check(schema, value, joinPath(examplePath, "value"), "exemplo")
+		} else if external := mappingValue(example, "externalValue"); external != nil {
+			c.violations = append(c.violations, Violation{RuleID: "example-external", Severity: "info", Message: fmt.Sprintf("O exemplo usa externalValue (%s) e não foi conferido.", external.Value), JSONPath: examplePath, Line: external.Line})
+		}
+	}
+}
+
+// Confere example, examples (3.1) e default de um schema
+func (c *exampleChecker) checkSchema(schema *yaml.Node, path string) {
+	if example := mappingValue(schema, "example"); example != nil {
+		c.check(schema, example, joinPath(path, "example"), "exemplo")
+	}
+	if examples := mappingValue(schema, "examples"); examples != nil && examples.Kind == yaml.SequenceNode {
+		for i, example := range examples.Content {
+			c.check(schema, example, fmt.Sprintf("%s[%d]", joinPath(path, "examples"), i), "exemplo")
+		}
+	}
+	if def := mappingValue(schema, "default"); def != nil {
+		c.check(schema, def, joinPath(path, "default"), "valor padrão")
+	}
+}
+
+// Registra uma violação para cada valor que não corresponde ao schema, com a
+// primeira falha encontrada
+func (c *exampleChecker) check(schema, value *yaml.Node, path, label string) {
+	errs := c.validator.validate(schema, value, path)
+	if len(errs) == 0 {
+		return
+	}
+	first := errs[0]
+	message := fmt.Sprintf("O %s não corresponde ao schema: %s (palavra-chave %s", label, first.message, first.keyword)
+	if first.at != path {
+		message += " em " + first.at
+	}
+	message += ")"
+	if len(errs) > 1 {
+		message += fmt.Sprintf(" e mais %d problema(s)", len(errs)-1)
+	}
+	c.violations = append(c.violations, Violation{RuleID: "example-schema", Severity: "error", Message: message + ".", JSONPath: path, Line: value.Line})
+}
@@ -0,0 +1,270 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Com --examples-matrix, o relatório traz, por operação, as combinações de código de
+// resposta e media type que têm ao menos um exemplo
+var examplesMatrix bool
+
+// Cobertura mínima de exemplos por operação, em porcentagem das combinações de
+// resposta; 0 desativa a verificação
+var examplesMinCoverage float64
+
+// Severidade das operações abaixo de --examples-min-coverage
+var examplesCoverageSeverity = "warning"
+
+func registerExamplesMatrixFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&examplesMatrix, "examples-matrix", false, "relata, por operação, as combinações de código de resposta e media type com ao menos um exemplo")
+	fs.Float64Var(&examplesMinCoverage, "examples-min-coverage", 0, "porcentagem mínima (0 a 100) das combinações de resposta de cada operação que precisam de exemplo (0 desativa)")
+	fs.StringVar(&examplesCoverageSeverity, "examples-coverage-severity", examplesCoverageSeverity, "severidade das operações abaixo de --examples-min-coverage: error, warning, info ou hint")
+}
+
+func checkExamplesMatrixFlags() error {
+	if examplesMinCoverage < 0 || examplesMinCoverage > 100 {
+		return fmt.Errorf("valor inválido para --examples-min-coverage: %g (use um número de 0 a 100)", examplesMinCoverage)
+	}
+	if !ruleSeverities[examplesCoverageSeverity] {
+		return fmt.Errorf("valor inválido para --examples-coverage-severity: %q (use error, warning, info ou hint)", examplesCoverageSeverity)
+	}
+	return nil
+}
+
+// Exemplos das respostas de uma operação, no relatório JSON
+type operationExamples struct {
+	Operation string        `json:"operation"` // METHOD path
+	Percent   float64       `json:"percent"`   // combinações com exemplo
+	Cells     []exampleCell `json:"cells"`
+
+	path string // JSONPath das respostas, para as violações
+	line int
+}
+
+// Combinação de código de resposta e media type, com as origens dos exemplos
+// encontrados (examples, example ou schema); sem origens, não há exemplo
+type exampleCell struct {
+	Status    string   `json:"status"`
+	MediaType string   `json:"mediaType"`
+	Sources   []string `json:"sources,omitempty"`
+}
+
+// Função para montar a matriz de exemplos das operações no documento resolvido: cada
+// media type de cada resposta conta como exemplificado com examples, example ou um
+// example (ou examples) no próprio schema, seguindo os $refs que a resolução manteve.
+// No Swagger 2.0, os media types vêm do produces da operação ou do global. Respostas
+// sem corpo ficam de fora, e operações sem nenhuma combinação não entram na matriz.
+func examplesMatrixFor(resolved *yaml.Node) []operationExamples {
+	doc := documentContent(resolved)
+	swagger2 := isSwagger2(resolved)
+	matrix := []operationExamples{}
+	for _, op := range listOperations(resolved) {
+		section := "paths"
+		if op.Webhook {
+			section = "webhooks"
+		}
+		operation := mappingValue(mappingValue(mappingValue(doc, section), op.Path), op.Method)
+		responses := mappingValue(operation, "responses")
+		entry := operationExamples{Operation: strings.ToUpper(op.Method) + " " + op.displayPath(), path: joinPath(joinPath(joinPath("$."+section, op.Path), op.Method), "responses")}
+		if responses != nil {
+			entry.line = responses.Line
+		}
+		forEachEntry(responses, func(status string, response *yaml.Node) {
+			if strings.HasPrefix(status, "x-") {
+				return
+			}
+			response = localRefTarget(doc, response)
+			if swagger2 {
+				entry.Cells = append(entry.Cells, swagger2ExampleCells(doc, operation, status, response)...)
+				return
+			}
+			forEachEntry(mappingValue(response, "content"), func(mediaType string, media *yaml.Node) {
+				cell := exampleCell{Status: status, MediaType: mediaType}
+				if examples := mappingValue(media, "examples"); examples != nil && len(examples.Content) > 0 {
+					cell.Sources = append(cell.Sources, "examples")
+				}
+				if mappingValue(media, "example") != nil {
+					cell.Sources = append(cell.Sources, "example")
+				}
+				if schemaHasExample(doc, mappingValue(media, "schema")) {
+					cell.Sources = append(cell.Sources, "schema")
+				}
+				entry.Cells = append(entry.Cells, cell)
+			})
+		})
+		if len(entry.Cells) == 0 {
+			continue
+		}
+		entry.Percent = math.Round(float64(entry.covered())*1000/float64(len(entry.Cells))) / 10
+		matrix = append(matrix, entry)
+	}
+	return matrix
+}
+
+// Combinações de uma resposta do Swagger 2.0: um media type do produces por
+// combinação (ou os de examples, sem produces), quando a resposta tem schema ou examples
+func swagger2ExampleCells(doc, operation *yaml.Node, status string, response *yaml.Node) []exampleCell {
+	schema := mappingValue(response, "schema")
+	examples := mappingValue(response, "examples")
+	if schema == nil && examples == nil {
+		return nil
+	}
+	produces := scalarList(mappingValue(operation, "produces"))
+	if produces == nil {
+		produces = scalarList(mappingValue(doc, "produces"))
+	}
+	if produces == nil {
+		forEachEntry(examples, func(mediaType string, _ *yaml.Node) { produces = append(produces, mediaType) })
+	}
+	if produces == nil {
+		produces = []string{"application/json"}
+	}
+	withSchema := schemaHasExample(doc, schema)
+	cells := make([]exampleCell, 0, len(produces))
+	for _, mediaType := range produces {
+		cell := exampleCell{Status: status, MediaType: mediaType}
+		if mappingValue(examples, mediaType) != nil {
+			cell.Sources = append(cell.Sources, "examples")
+		}
+		if withSchema {
+			cell.Sources = append(cell.Sources, "schema")
+		}
+		cells = append(cells, cell)
+	}
+	return cells
+}
+
+// Indica se o schema (ou o destino do $ref) declara example ou examples no próprio
+// nível; os exemplos das propriedades não contam como exemplo da resposta
+func schemaHasExample(doc, schema *yaml.Node) bool {
+	for depth := 0; schema != nil && depth < maxSchemaRefDepth; depth++ {
+		if mappingValue(schema, "example") != nil {
+			return true
+		}
+		if examples := mappingValue(schema, "examples"); examples != nil && len(examples.Content) > 0 {
+			return true
+		}
+		if mappingValue(schema, "$ref") == nil {
+			return false
+		}
+		schema = localRefTarget(doc, schema)
+	}
+	return false
+}
+
+// Combinações da operação com ao menos um exemplo
+func (e operationExamples) covered() int {
+	n := 0
+	for _, cell := range e.Cells {
+		if len(cell.Sources) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// Combinações da operação sem exemplo, como "400 application/json"
+func (e operationExamples) missing() []string {
+	var missing []string
+	for _, cell := range e.Cells {
+		if len(cell.Sources) == 0 {
+			missing = append(missing, cell.Status+" "+cell.MediaType)
+		}
+	}
+	return missing
+}
+
+// Função para relatar as operações com cobertura de exemplos abaixo de
+// --examples-min-coverage, listando as combinações sem exemplo
+func examplesCoverageViolations(s *runSettings, matrix []operationExamples) []Violation {
+	if s.examplesMinCover <= 0 {
+		return nil
+	}
+	var violations []Violation
+	for _, e := range matrix {
+		if e.Percent >= s.examplesMinCover {
+			continue
+		}
+		violations = append(violations, Violation{
+			RuleID:     "examples-coverage",
+			Severity:   s.examplesSeverity,
+			Message:    fmt.Sprintf("%s: %d de %d combinações de resposta têm exemplo (%.1f%%, mínimo %g%%); sem exemplo: %s.", e.Operation, e.covered(), len(e.Cells), e.Percent, s.examplesMinCover, strings.Join(e.missing(), ", ")),
+			JSONPath:   e.path,
+			Line:       e.line,
+			Suggestion: "Declare example ou examples no media type de cada resposta (ou example no schema), inclusive nas respostas de erro, que os parceiros usam nos testes.",
+		})
+	}
+	return violations
+}
+
+// Imprime a matriz de exemplos após o resumo da validação em texto: a cobertura de
+// cada operação e as combinações sem exemplo
+func printExamplesMatrix(matrix []operationExamples) {
+	if len(matrix) == 0 {
+		return
+	}
+	cells, covered := 0, 0
+	for _, e := range matrix {
+		cells += len(e.Cells)
+		covered += e.covered()
+	}
+	fmt.Fprintf(stdout, "🧪 Exemplos das respostas: %d de %d combinações de código e media type\n", covered, cells)
+	for _, e := range matrix {
+		line := fmt.Sprintf("   %s: %.1f%%", e.Operation, e.Percent)
+		if missing := e.missing(); len(missing) > 0 {
+			line += " (sem exemplo: " + strings.Join(missing, ", ") + ")"
+		}
+		fmt.Fprintln(stdout, line)
+	}
+}
+
+// Matriz de exemplos em Markdown: uma linha por operação e uma coluna por código de
+// resposta, com ✅ ou ❌ para cada media type
+func examplesMatrixMarkdown(matrix []operationExamples) string {
+	seen := map[string]bool{}
+	var statuses []string
+	for _, e := range matrix {
+		for _, cell := range e.Cells {
+			if !seen[cell.Status] {
+				seen[cell.Status] = true
+				statuses = append(statuses, cell.Status)
+			}
+		}
+	}
+	sort.SliceStable(statuses, func(i, j int) bool { return statusRank(statuses[i]) < statusRank(statuses[j]) })
+
+	var b strings.Builder
+	b.WriteString("| Operação | Cobertura |")
+	for _, status := range statuses {
+		b.WriteString(" " + markdownCell(status) + " |")
+	}
+	b.WriteString("\n|---|---:|" + strings.Repeat("---|", len(statuses)) + "\n")
+	for _, e := range matrix {
+		fmt.Fprintf(&b, "| `%s` | %.1f%% |", markdownCell(e.Operation), e.Percent)
+		for _, status := range statuses {
+			var marks []string
+			for _, cell := range e.Cells {
+				if cell.Status != status {
+					continue
+				}
+				mark := "❌"
+				if len(cell.Sources) > 0 {
+					mark = "✅"
+				}
+				marks = append(marks, mark+" "+markdownCell(cell.MediaType))
+			}
+			if len(marks) == 0 {
+				marks = []string{"-"}
+			}
+			b.WriteString(" " + strings.Join(marks, "<br>") + " |")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,119 @@
+package validator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const examplesSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas:
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              example: {data: []}
+            application/xml:
+              schema: {$ref: '#/components/schemas/Contas'}
+        '404':
+          $ref: '#/components/responses/NaoEncontrado'
+        '204': {description: sem corpo}
+        x-nota: ignorada
+  /saude:
+    get:
+      responses: {'204': {description: ok}}
+components:
+  schemas:
+    Contas:
+      type: object
+      example: {data: []}
+  responses:
+    NaoEncontrado:
+      description: não encontrado
+      content:
+        application/json:
+          schema:
+            type: object
+            properties:
+              codigo: {type: string, example: NAO_ENCONTRADO}
+`
+
+// Cada media type de cada resposta com corpo é uma combinação; example do schema (pelo
+// $ref) conta, o das propriedades não, e operações sem combinação ficam de fora
+func TestExamplesMatrix(t *testing.T) {
+	matrix := examplesMatrixFor(mustParseYAML(t, examplesSpec))
+	if len(matrix) != 1 {
+		t.Fatalf("esperada só GET /contas na matriz: %+v", matrix)
+	}
+	e := matrix[0]
+	want := []exampleCell{
+		{Status: "200", MediaType: "application/json", Sources: []string{"example"}},
+		{Status: "200", MediaType: "application/xml", Sources: []string{"schema"}},
+		{Status: "404", MediaType: "application/json"},
+	}
+	if e.Operation != "GET /contas" || !reflect.DeepEqual(e.Cells, want) {
+		t.Errorf("matriz %s %+v, esperado GET /contas %+v", e.Operation, e.Cells, want)
+	}
+	if e.Percent != 66.7 || e.line != 7 || e.path != "$.paths['/contas'].get.responses" {
+		t.Errorf("cobertura %.1f na linha %d (%s), esperado 66.7 na linha 7", e.Percent, e.line, e.path)
+	}
+
+	markdown := examplesMatrixMarkdown(matrix)
+	for _, want := range []string{"| Operação | Cobertura | 200 | 404 |", "| `GET /contas` | 66.7% | ✅ application/json<br>✅ application/xml | ❌ application/json |"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Markdown sem %q:\n%s", want, markdown)
+		}
+	}
+}
+
+// No Swagger 2.0, os media types vêm do produces da operação ou do global
+func TestExamplesMatrixSwagger2(t *testing.T) {
+	spec := `swagger: '2.0'
+info: {title: Contas, version: 1.0.0}
+produces: [application/json]
+paths:
+  /contas:
+    get:
+      produces: [application/json, text/csv]
+      responses:
+        '200':
+          description: ok
+          schema: {type: object}
+          examples: {text/csv: 'id,nome'}
+  /cartoes:
+    get:
+      responses:
+        '200': {description: ok, schema: {type: object, example: {}}}
+`
+	matrix := examplesMatrixFor(mustParseYAML(t, spec))
+	got := map[string][]exampleCell{}
+	for _, e := range matrix {
+		got[e.Operation] = e.Cells
+	}
+	want := map[string][]exampleCell{
+		"GET /contas":  {{Status: "200", MediaType: "application/json"}, {Status: "200", MediaType: "text/csv", Sources: []string{"examples"}}},
+		"GET /cartoes": {{Status: "200", MediaType: "application/json", Sources: []string{"schema"}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("matriz %+v, esperado %+v", got, want)
+	}
+}
+
+// Abaixo de --examples-min-coverage, a operação é relatada com as combinações sem exemplo
+func TestExamplesCoverage(t *testing.T) {
+	matrix := examplesMatrixFor(mustParseYAML(t, examplesSpec))
+	if found := examplesCoverageViolations(&runSettings{examplesMinCover: 60, examplesSeverity: "error"}, matrix); len(found) != 0 {
+		t.Errorf("66.7%% atende ao mínimo de 60%%: %v", found)
+	}
+	found := examplesCoverageViolations(&runSettings{examplesMinCover: 80, examplesSeverity: "error"}, matrix)
+	if len(found) != 1 || found[0].Severity != "error" || found[0].Line != 7 {
+		t.Fatalf("esperada uma violação error na linha 7: %v", found)
+	}
+	if want := "GET /contas: 2 de 3 combinações de resposta têm exemplo (66.7%, mínimo 80%); sem exemplo: 404 application/json."; found[0].Message != want {
+		t.Errorf("mensagem %q, esperado %q", found[0].Message, want)
+	}
+}
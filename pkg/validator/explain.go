@@ -0,0 +1,152 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var explainCommand = &command{
+	Name:    "explain",
+	Args:    "<nome-da-regra>",
+	Summary: "mostra a definição efetiva de uma regra",
+	Description: `Mostra descrição, severidade, given, função, opções, documentação e
+exemplos de uma regra, já com extends e ajustes de severidade aplicados. As
+regras vêm de --rules, da configuração do projeto ou do pacote ofb embarcado.`,
+	Examples: []string{
+		programName + " explain only-https",
+		programName + " explain --rules rules.yaml require-contact-info",
+	},
+	Flags: func(fs *flag.FlagSet) { registerExplainFlags(fs) },
+	Run:   runExplain,
+}
+
+func registerExplainFlags(fs *flag.FlagSet) *string {
+	rulesFile := fs.String("rules", "", "arquivo de regras (padrão: o da configuração ou o pacote OFB embarcado)")
+	registerEnvFlag(fs)
+	registerCacheFlags(fs)
+	return rulesFile
+}
+
+// Subcomando explain: mostra a definição efetiva de uma regra
+func runExplain(c *command, args []string) int {
+	fs := c.newFlagSet()
+	rulesFile := registerExplainFlags(fs)
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() != 1 {
+		return c.usageError("esperado um argumento (nome da regra), recebidos %d", fs.NArg())
+	}
+	name := fs.Arg(0)
+
+	rules, source, err := loadExplainRules(*rulesFile)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao carregar as regras:", err)
+		return rulesExitCode(err)
+	}
+
+	ruleData, ok := rules[name].(map[string]interface{})
+	if !ok {
+		fmt.Fprintf(stdout, "❌ Regra %q não encontrada em %s.\n", name, source)
+		if names := similarRuleNames(rules, name); len(names) > 0 {
+			fmt.Fprintln(stdout, "   Regras parecidas:", strings.Join(names, ", "))
+		}
+		return exitFailure
+	}
+
+	fmt.Fprintf(stdout, "📘 %s (%s)\n\n", name, source)
+	printField("Descrição", ruleData["description"])
+	printField("Description", ruleData["descriptionEn"])
+	printField("Severidade", ruleData["severity"])
+	if severities, ok := ruleData["severities"].(map[string]interface{}); ok {
+		printField("Severidade por perfil", profileSeverityList(severities))
+	}
+	printField("Given", ruleData["given"])
+	if when, ok := ruleData["when"]; ok {
+		printField("Condição (when)", yamlText(when))
+	}
+	if scope, err := parseRuleScope(ruleData["scope"]); err == nil && scope != nil {
+		printField("Escopo (scope)", scope.String())
+	}
+	if given, _ := ruleData["given"].(string); given != "" {
+		if targets, err := parseRuleTargets(ruleData["targets"], given); err == nil && targets != nil {
+			printField("Seções (targets)", strings.Join(targets, ", "))
+		}
+	}
+	if then, ok := ruleData["then"].(map[string]interface{}); ok {
+		printField("Campo", then["field"])
+		printField("Função", then["function"])
+		if options, ok := then["functionOptions"]; ok {
+			printField("Opções", yamlText(options))
+		}
+	}
+	printField("Sugestão", ruleData["suggestion"])
+	printField("Documentação", ruleData["documentationUrl"])
+	if ids := ruleRequirements(ruleData); len(ids) > 0 {
+		printField("Requisitos", strings.Join(ids, ", "))
+	}
+	if fixable, _ := ruleData["fixable"].(bool); fixable {
+		printField("Correção automática", "sim (validate --fix)")
+	}
+
+	if examples, ok := ruleData["examples"].(map[string]interface{}); ok {
+		printField("Exemplo que passa", examples["passing"])
+		printField("Exemplo que falha", examples["failing"])
+	}
+	return exitOK
+}
+
+// Carrega as regras do arquivo informado, da configuração do projeto ou do pacote embarcado
+func loadExplainRules(rulesFile string) (map[string]interface{}, string, error) {
+	if rulesFile == "" {
+		if config, err := loadProjectConfig(projectConfigFile); err == nil && config != nil {
+			rulesFile = config.Rules
+		}
+	}
+	if rulesFile == "" {
+		rules, err := loadRuleset(flagSettings(), "ofb")
+		return rules, "pacote ofb embarcado", err
+	}
+	rules, err := loadRuleset(flagSettings(), rulesFile)
+	return rules, rulesFile, err
+}
+
+// Imprime um campo da regra; valores com várias linhas são indentados
+func printField(label string, value interface{}) {
+	if value == nil {
+		return
+	}
+	text := strings.TrimRight(fmt.Sprint(value), "\n")
+	if text == "" {
+		return
+	}
+	if strings.Contains(text, "\n") {
+		fmt.Fprintf(stdout, "%s:\n    %s\n\n", label, strings.ReplaceAll(text, "\n", "\n    "))
+		return
+	}
+	fmt.Fprintf(stdout, "%s: %s\n", label, text)
+}
+
+// Converte um valor para texto YAML
+func yamlText(value interface{}) string {
+	out, err := marshalYAML(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(out)
+}
+
+// Nomes de regras que contêm o termo procurado (ou são contidos por ele)
+func similarRuleNames(rules map[string]interface{}, name string) []string {
+	var names []string
+	for candidate := range rules {
+		if strings.Contains(candidate, name) || strings.Contains(name, candidate) {
+			names = append(names, candidate)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
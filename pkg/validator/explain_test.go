@@ -0,0 +1,55 @@
+package validator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// explain mostra a definição efetiva: a regra herdada por extends com a severidade
+// ajustada pelo arquivo, a regra própria do arquivo e, para um nome desconhecido, as
+// regras parecidas
+func TestExplain(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"regras.yaml": `extends: ofb
+rules:
+  only-https: warning
+  info-titulo:
+    description: O título é obrigatório
+    given: $.info
+    severity: error
+    then: {field: title, function: truthy}
+`})
+	rules := filepath.Join(dir, "regras.yaml")
+
+	tests := []struct {
+		name string
+		code int
+		want []string
+	}{
+		{"only-https", exitOK, []string{
+			"📘 only-https (" + rules + ")",
+			"Description: Server URLs must use HTTPS",
+			"Severidade: warning\n",
+			"Given: $..servers[*].url\n",
+			"Função: pattern\n",
+			"Opções: match: ^https://\n",
+			"Exemplo que falha:\n    servers:\n      - url: http://",
+		}},
+		{"info-titulo", exitOK, []string{"Descrição: O título é obrigatório\n", "Campo: title\n", "Função: truthy\n"}},
+		{"https", exitFailure, []string{`❌ Regra "https" não encontrada`, "Regras parecidas: only-https"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, out := runCommand(t, "explain", "--no-cache", "--rules", rules, tt.name)
+			if code != tt.code {
+				t.Errorf("código %d, esperado %d\n%s", code, tt.code, out)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("saída sem %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,636 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Flags do subcomando export
+type exportOptions struct {
+	format    string
+	outDir    string
+	output    string
+	rules     string
+	checklist string
+	since     string
+	timeout   time.Duration
+}
+
+func (o *exportOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.format, "format", "examples", "artefato exportado: examples (um par requisição/resposta de exemplo por operação), conformance (matriz requisito × regras × resultado) ou inventory (um registro por operação)")
+	fs.StringVar(&o.outDir, "out-dir", "", "diretório de saída de --format examples")
+	fs.StringVar(&o.output, "o", "", "arquivo de saída de conformance (.csv ou .md) e inventory (.csv ou .json) (padrão: a saída padrão, em Markdown e JSON)")
+	fs.StringVar(&o.rules, "rules", "", "arquivo de regras de --format conformance (padrão: o da configuração ou o pacote OFB embarcado)")
+	fs.StringVar(&o.checklist, "checklist", "", "checklist de conformidade do OFB (YAML com requirements: [{id, title}]) para --format conformance")
+	fs.StringVar(&o.since, "since", "", "especificação anterior: --format inventory marca cada operação como added, changed, unchanged ou removed")
+	fs.DurationVar(&o.timeout, "timeout", defaultTimeout, "tempo máximo da execução (0 desativa)")
+	registerRefFlags(fs)
+	registerHTTPFlags(fs)
+	registerEncodingFlag(fs)
+	registerSelectDocumentFlag(fs)
+	registerInputLimitFlag(fs)
+	registerOverlayFlags(fs)
+}
+
+var exportCommand = &command{
+	Name:    "export",
+	Args:    "<spec.yaml> [spec.yaml...]",
+	Summary: "exporta artefatos derivados da especificação resolvida",
+	Description: `Resolve a especificação e exporta artefatos para outras ferramentas.
+
+Com --format examples, grava em --out-dir um arquivo JSON por operação com um
+par requisição/resposta de exemplo (parâmetros de path, query, cabeçalhos e
+cookies, corpo da requisição e a primeira resposta 2xx, ou default), para
+alimentar servidores de mock e sandboxes de parceiros, e um index.json com a
+lista das operações. Os valores vêm dos exemplos declarados (example, examples,
+default, const, enum) e, na falta deles, são gerados a partir dos schemas:
+propriedades obrigatórias sempre, opcionais só nos primeiros níveis, formatos
+conhecidos, pattern por um gerador limitado e os limites numéricos e de
+tamanho. Em schemas recursivos, o ciclo é cortado omitindo a propriedade ou
+deixando a lista vazia. Cada exemplo gerado é conferido contra o schema e as
+divergências são relatadas como avisos.
+
+Com --format conformance, valida as especificações com as regras e grava a
+matriz de conformidade para a certificação: cada requisito da checklist
+(--checklist), as regras que o citam em x-requirement-id (um id ou uma
+lista) e o resultado em cada especificação (aprovado, reprovado com o número
+de violações ou erro). Requisitos que nenhuma regra carregada cobre aparecem
+como "não verificado"; ids citados pelas regras e ausentes da checklist
+também entram na matriz, marcados. A saída é CSV ou Markdown conforme a
+extensão de -o.
+
+Com --format inventory, grava um registro por operação para o catálogo de
+APIs: path, método, operationId, tags, summary, esquemas e escopos exigidos
+(da segurança da operação ou da global), deprecated, media types de
+requisição e de resposta e o info.version, ordenados por path e método, em
+JSON ou, com -o terminado em .csv, em CSV. Com --since, a especificação
+anterior também é resolvida e cada operação recebe change: added, changed
+(a operação resolvida ou os parâmetros do path mudaram), unchanged ou, para
+as que deixaram de existir, removed.`,
+	Examples: []string{
+		programName + " export --format examples --out-dir mocks swagger.yaml",
+		programName + " export --format conformance --checklist checklist-ofb.yaml -o conformidade.md apis/*.yaml",
+		programName + " export --format inventory -o inventario.csv swagger.yaml",
+		programName + " export --format inventory --since swagger-v1.yaml swagger.yaml",
+	},
+	Flags: func(fs *flag.FlagSet) { new(exportOptions).register(fs) },
+	Run:   runExport,
+}
+
+// Subcomando export: grava os artefatos derivados da especificação resolvida
+func runExport(c *command, args []string) int {
+	opts := &exportOptions{}
+	fs := c.newFlagSet()
+	opts.register(fs)
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	if err := checkOverlayFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkHTTPFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkEncodingFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkSelectDocumentFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	switch opts.format {
+	case "examples", "inventory":
+		if opts.format == "examples" && opts.outDir == "" {
+			return c.usageError("--format examples exige --out-dir")
+		}
+		if fs.NArg() != 1 {
+			return c.usageError("esperado um argumento (especificação), recebidos %d", fs.NArg())
+		}
+	case "conformance":
+		if fs.NArg() == 0 {
+			return c.usageError("informe ao menos uma especificação")
+		}
+	default:
+		return c.usageError("valor inválido para --format: %q (use examples, conformance ou inventory)", opts.format)
+	}
+	if opts.since != "" && opts.format != "inventory" {
+		return c.usageError("--since só vale com --format inventory")
+	}
+
+	ctx, cancel := newRunContext(opts.timeout)
+	defer cancel()
+	if opts.format == "conformance" {
+		return exportConformance(ctx, opts, fs.Args())
+	}
+
+	inputFile := fs.Arg(0)
+	spec, code := resolveForExport(ctx, inputFile, opts.timeout)
+	if spec == nil {
+		return code
+	}
+	if opts.format == "inventory" {
+		return exportInventory(ctx, opts, spec)
+	}
+	return exportExamples(inputFile, &spec.rootNode, opts.outDir)
+}
+
+// Resolve a especificação exportada; com referências quebradas nada é exportado
+func resolveForExport(ctx context.Context, inputFile string, timeout time.Duration) (*resolvedSpec, int) {
+	spec, err := indexAndResolve(ctx, flagSettings(), inputFile)
+	if err != nil {
+		if isCancellation(err) {
+			reportCancellation(err, timeout)
+			return nil, exitFailure
+		}
+		fmt.Fprintln(stdout, "❌ Erro ao processar", inputFile+":", err)
+		return nil, exitFailure
+	}
+	if len(spec.report.Errors) > 0 {
+		for _, e := range spec.report.Errors {
+			fmt.Fprintln(stdout, "❌", e)
+		}
+		fmt.Fprintln(stdout, "❌ A especificação tem referências não resolvidas; nada foi exportado.")
+		return nil, exitFailure
+	}
+	return spec, exitOK
+}
+
+// Modo --format inventory: um registro por operação, comparado com --since
+func exportInventory(ctx context.Context, opts *exportOptions, spec *resolvedSpec) int {
+	records := inventoryRecords(&spec.rootNode)
+	if opts.since != "" {
+		previous, code := resolveForExport(ctx, opts.since, opts.timeout)
+		if previous == nil {
+			return code
+		}
+		records = markInventoryChanges(records, inventoryRecords(&previous.rootNode))
+	}
+	if err := writeInventory(opts.output, records); err != nil {
+		fmt.Fprintln(stdout, "❌", err)
+		return exitFailure
+	}
+	if opts.output != "" {
+		fmt.Fprintf(stdout, "📄 Inventário com %d operações salvo em: %s\n", len(records), opts.output)
+	}
+	return exitOK
+}
+
+// Modo --format conformance: matriz de requisitos × especificações
+func exportConformance(ctx context.Context, opts *exportOptions, specs []string) int {
+	var checklist []requirement
+	if opts.checklist != "" {
+		var err error
+		if checklist, err = loadChecklist(opts.checklist); err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+	}
+	rules, _, err := loadExplainRules(opts.rules)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao carregar as regras:", err)
+		return rulesExitCode(err)
+	}
+	rows, err := conformanceMatrix(ctx, specs, rules, checklist)
+	if err != nil {
+		if isCancellation(err) {
+			reportCancellation(err, opts.timeout)
+		} else {
+			fmt.Fprintln(stdout, "❌", err)
+		}
+		return exitFailure
+	}
+	if len(rows) == 0 {
+		fmt.Fprintln(stderr, "⚠️  Nenhum requisito na checklist nem em x-requirement-id das regras.")
+	}
+	if err := writeConformance(opts.output, specs, rows); err != nil {
+		fmt.Fprintln(stdout, "❌", err)
+		return exitFailure
+	}
+	if opts.output != "" {
+		fmt.Fprintln(stdout, "📄 Matriz de conformidade salva em:", opts.output)
+	}
+	return exitOK
+}
+
+// Grava a saída de um export em -o ou, sem arquivo, na saída padrão
+func writeExportOutput(output string, write func(w io.Writer) error) error {
+	if output == "" {
+		return write(stdout)
+	}
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório de %s: %v", output, err)
+	}
+	if err := writeFileAtomic(output, buf.Bytes()); err != nil {
+		return fmt.Errorf("erro ao salvar %s: %v", output, err)
+	}
+	return nil
+}
+
+// Arquivo de uma operação no pacote de exemplos
+type exampleOperation struct {
+	Operation exampleOperationInfo `json:"operation"`
+	Request   exampleRequest       `json:"request"`
+	Response  *exampleResponse     `json:"response,omitempty"`
+}
+
+type exampleOperationInfo struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	OperationID string `json:"operationId,omitempty"`
+	Webhook     bool   `json:"webhook,omitempty"`
+}
+
+type exampleRequest struct {
+	Path           string                     `json:"path"` // path com os valores dos parâmetros
+	PathParameters map[string]json.RawMessage `json:"pathParameters,omitempty"`
+	Query          map[string]json.RawMessage `json:"query,omitempty"`
+	Headers        map[string]json.RawMessage `json:"headers,omitempty"`
+	Cookies        map[string]json.RawMessage `json:"cookies,omitempty"`
+	ContentType    string                     `json:"contentType,omitempty"`
+	Body           json.RawMessage            `json:"body,omitempty"`
+}
+
+type exampleResponse struct {
+	Status      string                     `json:"status"`
+	ContentType string                     `json:"contentType,omitempty"`
+	Headers     map[string]json.RawMessage `json:"headers,omitempty"`
+	Body        json.RawMessage            `json:"body,omitempty"`
+}
+
+// index.json do pacote de exemplos
+type exampleIndex struct {
+	Spec       string              `json:"spec"`
+	Version    string              `json:"version,omitempty"`
+	Operations []exampleIndexEntry `json:"operations"`
+}
+
+// Operação no índice; Request e Response indicam a origem do corpo: example
+// (declarado), schema (gerado) ou none
+type exampleIndexEntry struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	OperationID string   `json:"operationId,omitempty"`
+	File        string   `json:"file"`
+	Request     string   `json:"request"`
+	Response    string   `json:"response"`
+	Warnings    []string `json:"warnings,omitempty"`
+}
+
+// Modo --format examples: um arquivo por operação e o índice
+func exportExamples(inputFile string, root *yaml.Node, outDir string) int {
+	builder := &exampleBuilder{doc: documentContent(root), validator: newSchemaValidator(root)}
+	index := exampleIndex{Spec: reportPath(inputFile), Operations: []exampleIndexEntry{}}
+	if version := mappingValue(mappingValue(builder.doc, "info"), "version"); version != nil {
+		index.Version = version.Value
+	}
+
+	used := map[string]bool{"index": true}
+	warnings := 0
+	for _, op := range listOperations(root) {
+		example, entry, err := builder.build(op)
+		if err != nil {
+			fmt.Fprintf(stdout, "❌ %s %s: %v\n", strings.ToUpper(op.Method), op.displayPath(), err)
+			return exitFailure
+		}
+		name := exampleFileName(op, example.Operation.OperationID)
+		for n := 2; used[strings.ToLower(name)]; n++ {
+			name = fmt.Sprintf("%s-%d", exampleFileName(op, example.Operation.OperationID), n)
+		}
+		used[strings.ToLower(name)] = true
+		entry.File = name + ".json"
+		if err := writeJSONReport(filepath.Join(outDir, entry.File), example); err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+		for _, w := range entry.Warnings {
+			fmt.Fprintf(stdout, "⚠️  %s %s: %s\n", strings.ToUpper(op.Method), op.displayPath(), w)
+		}
+		warnings += len(entry.Warnings)
+		index.Operations = append(index.Operations, entry)
+	}
+	if err := writeJSONReport(filepath.Join(outDir, "index.json"), index); err != nil {
+		fmt.Fprintln(stdout, "❌", err)
+		return exitFailure
+	}
+	fmt.Fprintf(stdout, "📂 %d operações exportadas em %s (%d avisos).\n", len(index.Operations), outDir, warnings)
+	return exitOK
+}
+
+// Nome do arquivo da operação: o operationId ou método e path, só com caracteres seguros
+func exampleFileName(op operationRef, operationID string) string {
+	name := operationID
+	if name == "" {
+		name = op.Method + "_" + op.Path
+		if op.Webhook {
+			name = "webhook_" + op.Path + "_" + op.Method
+		}
+	}
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		case r == '{' || r == '}':
+			return -1
+		}
+		return '_'
+	}, name)
+	for strings.Contains(name, "__") {
+		name = strings.ReplaceAll(name, "__", "_")
+	}
+	name = strings.Trim(name, "_.")
+	if name == "" {
+		name = op.Method
+	}
+	return name
+}
+
+// Monta os exemplos das operações do documento resolvido. Documentos Swagger 2.0 já
+// chegam convertidos para OpenAPI 3.0 (parâmetros body e formData viram requestBody).
+type exampleBuilder struct {
+	doc       *yaml.Node
+	validator *schemaValidator
+}
+
+// Segue $refs locais que sobraram na árvore (ex.: ciclos)
+func (b *exampleBuilder) deref(node *yaml.Node) *yaml.Node {
+	for i := 0; node != nil && i < maxSchemaRefDepth; i++ {
+		ref := mappingValue(node, "$ref")
+		if ref == nil {
+			return node
+		}
+		file, pointer := splitRef(ref.Value)
+		if file != "" {
+			return node
+		}
+		target, err := resolvePointer(b.doc, pointer)
+		if err != nil {
+			return node
+		}
+		node = target
+	}
+	return node
+}
+
+func (b *exampleBuilder) build(op operationRef) (*exampleOperation, exampleIndexEntry, error) {
+	section := "paths"
+	if op.Webhook {
+		section = "webhooks"
+	}
+	item := b.deref(mappingValue(mappingValue(b.doc, section), op.Path))
+	operation := mappingValue(item, op.Method)
+	example := &exampleOperation{Operation: exampleOperationInfo{Method: op.Method, Path: op.Path, Webhook: op.Webhook}}
+	entry := exampleIndexEntry{Method: op.Method, Path: op.displayPath(), Request: "none", Response: "none"}
+	if id := mappingValue(operation, "operationId"); id != nil {
+		example.Operation.OperationID = id.Value
+		entry.OperationID = id.Value
+	}
+	var warnings []string
+	collect := func(g *sampleGenerator) {
+		warnings = append(warnings, g.warnings...)
+	}
+
+	// Parâmetros do path item e da operação; os da operação substituem os de mesmo nome e local
+	params := map[[2]string]*yaml.Node{}
+	var order [][2]string
+	for _, owner := range []*yaml.Node{item, operation} {
+		list := mappingValue(owner, "parameters")
+		if list == nil || list.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, param := range list.Content {
+			param = b.deref(param)
+			name, in := mappingValue(param, "name"), mappingValue(param, "in")
+			if name == nil || in == nil {
+				continue
+			}
+			key := [2]string{in.Value, name.Value}
+			if _, ok := params[key]; !ok {
+				order = append(order, key)
+			}
+			params[key] = param
+		}
+	}
+
+	request := &example.Request
+	request.Path = op.Path
+	for _, key := range order {
+		param := params[key]
+		in, name := key[0], key[1]
+		required := mappingValue(param, "required")
+		value := b.declaredExample(param)
+		// Parâmetros opcionais entram apenas quando têm exemplo declarado
+		if value == nil && in != "path" && (required == nil || required.Value != "true") {
+			continue
+		}
+		if value == nil {
+			g := newSampleGenerator(b.validator, true)
+			value = g.sample(parameterSchemaOrContent(param), fmt.Sprintf("parâmetro %s (%s)", name, in))
+			collect(g)
+		}
+		if value == nil {
+			continue
+		}
+		raw, err := rawJSON(value)
+		if err != nil {
+			return nil, entry, err
+		}
+		switch in {
+		case "path":
+			if request.PathParameters == nil {
+				request.PathParameters = map[string]json.RawMessage{}
+			}
+			request.PathParameters[name] = raw
+			request.Path = strings.ReplaceAll(request.Path, "{"+name+"}", plainText(value))
+		case "query":
+			request.Query = addRaw(request.Query, name, raw)
+		case "header":
+			request.Headers = addRaw(request.Headers, name, raw)
+		case "cookie":
+			request.Cookies = addRaw(request.Cookies, name, raw)
+		}
+	}
+
+	if body := b.deref(mappingValue(operation, "requestBody")); body != nil {
+		g := newSampleGenerator(b.validator, true)
+		request.ContentType, request.Body, entry.Request = b.content(mappingValue(body, "content"), g, "corpo da requisição")
+		collect(g)
+	}
+
+	response, source, err := b.response(operation, collect)
+	if err != nil {
+		return nil, entry, err
+	}
+	example.Response, entry.Response = response, source
+	entry.Warnings = warnings
+	return example, entry, nil
+}
+
+// Primeira resposta 2xx da operação (ou default), com cabeçalhos e corpo
+func (b *exampleBuilder) response(operation *yaml.Node, collect func(*sampleGenerator)) (*exampleResponse, string, error) {
+	responses := mappingValue(operation, "responses")
+	if responses == nil || responses.Kind != yaml.MappingNode || len(responses.Content) == 0 {
+		return nil, "none", nil
+	}
+	var status string
+	var chosen *yaml.Node
+	for i := 0; i+1 < len(responses.Content); i += 2 {
+		if code := responses.Content[i].Value; strings.HasPrefix(code, "2") {
+			status, chosen = code, responses.Content[i+1]
+			break
+		}
+	}
+	if chosen == nil {
+		if chosen = mappingValue(responses, "default"); chosen == nil {
+			status, chosen = responses.Content[0].Value, responses.Content[1]
+		} else {
+			status = "default"
+		}
+	}
+	chosen = b.deref(chosen)
+	response := &exampleResponse{Status: status}
+	source := "none"
+
+	if headers := mappingValue(chosen, "headers"); headers != nil && headers.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(headers.Content); i += 2 {
+			name, header := headers.Content[i].Value, b.deref(headers.Content[i+1])
+			value := b.declaredExample(header)
+			if value == nil {
+				g := newSampleGenerator(b.validator, false)
+				value = g.sample(parameterSchemaOrContent(header), "cabeçalho "+name+" da resposta")
+				collect(g)
+			}
+			if value == nil {
+				continue
+			}
+			raw, err := rawJSON(value)
+			if err != nil {
+				return nil, source, err
+			}
+			response.Headers = addRaw(response.Headers, name, raw)
+		}
+	}
+
+	g := newSampleGenerator(b.validator, false)
+	response.ContentType, response.Body, source = b.content(mappingValue(chosen, "content"), g, "corpo da resposta")
+	collect(g)
+	return response, source, nil
+}
+
+// Corpo de um objeto content: o media type JSON, o exemplo declarado ou o gerado pelo schema
+func (b *exampleBuilder) content(content *yaml.Node, g *sampleGenerator, label string) (string, json.RawMessage, string) {
+	if content == nil || content.Kind != yaml.MappingNode || len(content.Content) == 0 {
+		return "", nil, "none"
+	}
+	var names []string
+	for i := 0; i+1 < len(content.Content); i += 2 {
+		names = append(names, content.Content[i].Value)
+	}
+	mediaType := preferredMediaType(names)
+	media := mappingValue(content, mediaType)
+	if value := b.declaredExample(media); value != nil {
+		body, source := b.encode(value, nil)
+		return mediaType, body, source
+	}
+	schema := mappingValue(media, "schema")
+	if schema == nil {
+		return mediaType, nil, "none"
+	}
+	body, source := b.encode(g.sample(schema, label), g)
+	return mediaType, body, source
+}
+
+// Exemplo declarado em um parâmetro, cabeçalho ou media type: example ou o primeiro
+// examples com value (externalValue não é lido)
+func (b *exampleBuilder) declaredExample(holder *yaml.Node) *yaml.Node {
+	if example := mappingValue(holder, "example"); example != nil {
+		return example
+	}
+	examples := mappingValue(holder, "examples")
+	if examples == nil || examples.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 1; i < len(examples.Content); i += 2 {
+		if value := mappingValue(b.deref(examples.Content[i]), "value"); value != nil {
+			return value
+		}
+	}
+	return nil
+}
+
+// Schema de um parâmetro ou cabeçalho: schema ou o do primeiro media type de content
+func parameterSchemaOrContent(param *yaml.Node) *yaml.Node {
+	if schema := mappingValue(param, "schema"); schema != nil {
+		return schema
+	}
+	if content := mappingValue(param, "content"); content != nil && content.Kind == yaml.MappingNode && len(content.Content) > 1 {
+		return mappingValue(content.Content[1], "schema")
+	}
+	return nil
+}
+
+// O media type JSON da lista (application/json ou +json), senão o primeiro
+func preferredMediaType(mediaTypes []string) string {
+	for _, media := range mediaTypes {
+		base, _, _ := strings.Cut(media, ";")
+		if base = strings.TrimSpace(base); base == "application/json" || strings.HasSuffix(base, "+json") {
+			return media
+		}
+	}
+	return mediaTypes[0]
+}
+
+// Serializa o corpo com a origem: example quando nada foi gerado a partir do schema
+func (b *exampleBuilder) encode(value *yaml.Node, g *sampleGenerator) (json.RawMessage, string) {
+	if value == nil {
+		return nil, "none"
+	}
+	raw, err := rawJSON(value)
+	if err != nil {
+		if g != nil {
+			g.warnings = append(g.warnings, err.Error())
+		}
+		return nil, "none"
+	}
+	if g != nil && g.generated {
+		return raw, "schema"
+	}
+	return raw, "example"
+}
+
+func rawJSON(value *yaml.Node) (json.RawMessage, error) {
+	data, err := encodeJSON(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(data), nil
+}
+
+func addRaw(values map[string]json.RawMessage, name string, raw json.RawMessage) map[string]json.RawMessage {
+	if values == nil {
+		values = map[string]json.RawMessage{}
+	}
+	values[name] = raw
+	return values
+}
+
+// Texto de um valor escalar para compor o path; listas e objetos viram JSON compacto
+func plainText(value *yaml.Node) string {
+	if value.Kind == yaml.ScalarNode {
+		return value.Value
+	}
+	data, _ := marshalJSON(plainValue(value), "")
+	return string(data)
+}
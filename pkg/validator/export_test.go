@@ -0,0 +1,120 @@
+package validator
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+const exportSpec = `openapi: 3.0.3
+info: {title: Contas, version: 2.1.0}
+paths:
+  /contas/{contaId}:
+    get:
+      operationId: obterConta
+      parameters:
+        - {name: contaId, in: path, required: true, schema: {type: string, pattern: '^[A-Z]{3}-[0-9]{4}$'}}
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Conta'}
+  /contas:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            example: {tipo: CORRENTE}
+            schema: {$ref: '#/components/schemas/Conta'}
+      responses:
+        '201': {description: criada}
+components:
+  schemas:
+    Conta:
+      type: object
+      required: [tipo, abertura, subcontas]
+      properties:
+        tipo: {type: string, enum: [CORRENTE, POUPANCA]}
+        abertura: {type: string, format: date}
+        saldo: {type: number, minimum: 10}
+        subcontas:
+          type: array
+          items: {$ref: '#/components/schemas/Conta'}
+`
+
+// --format examples grava um arquivo por operação e o índice: o exemplo declarado é
+// usado como está e o gerado respeita enum, format, pattern e mínimo, cortando a
+// recursão de Conta com a lista vazia
+func TestExportExamples(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"api.yaml": exportSpec})
+	outDir := filepath.Join(dir, "exemplos")
+	code, out := runCommand(t, "export", "--out-dir", outDir, filepath.Join(dir, "api.yaml"))
+	if code != exitOK {
+		t.Fatalf("código %d\n%s", code, out)
+	}
+
+	var index exampleIndex
+	if err := json.Unmarshal(mustReadFile(t, filepath.Join(outDir, "index.json")), &index); err != nil {
+		t.Fatal(err)
+	}
+	var entries []string
+	for _, e := range index.Operations {
+		entries = append(entries, e.Method+" "+e.Path+" "+e.File+" "+e.Request+"/"+e.Response)
+	}
+	want := []string{"get /contas/{contaId} obterConta.json none/schema", "post /contas post_contas.json example/none"}
+	if index.Version != "2.1.0" || !reflect.DeepEqual(entries, want) {
+		t.Errorf("índice %s %v, esperado %v", index.Version, entries, want)
+	}
+
+	var get, post struct {
+		Request struct {
+			Path string                 `json:"path"`
+			Body map[string]interface{} `json:"body"`
+		} `json:"request"`
+		Response struct {
+			Status string                 `json:"status"`
+			Body   map[string]interface{} `json:"body"`
+		} `json:"response"`
+	}
+	if err := json.Unmarshal(mustReadFile(t, filepath.Join(outDir, "obterConta.json")), &get); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(mustReadFile(t, filepath.Join(outDir, "post_contas.json")), &post); err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`^/contas/[A-Z]{3}-[0-9]{4}$`).MatchString(get.Request.Path) {
+		t.Errorf("path %q não segue o pattern de contaId", get.Request.Path)
+	}
+	wantBody := map[string]interface{}{"tipo": "CORRENTE", "abertura": "2024-01-01", "saldo": 10.0, "subcontas": []interface{}{}}
+	if get.Response.Status != "200" || !reflect.DeepEqual(get.Response.Body, wantBody) {
+		t.Errorf("resposta %s %v, esperado 200 %v", get.Response.Status, get.Response.Body, wantBody)
+	}
+	if !reflect.DeepEqual(post.Request.Body, map[string]interface{}{"tipo": "CORRENTE"}) || post.Response.Status != "201" {
+		t.Errorf("POST: requisição %v, resposta %s", post.Request.Body, post.Response.Status)
+	}
+}
+
+// O gerador de pattern produz um texto que casa com a expressão e respeita os limites
+// de tamanho (-1: sem limite)
+func TestPatternSample(t *testing.T) {
+	for _, tt := range []struct {
+		pattern        string
+		minLen, maxLen int
+	}{
+		{`^[A-Z]{3}-[0-9]{4}$`, -1, -1},
+		{`^\d{8}$`, -1, -1},
+		{`^[a-z]+(_[a-z]+)*$`, 5, 10},
+		{`^(CPF|CNPJ)$`, -1, -1},
+		{`^[\w\W\s]{0,500}$`, 3, 20},
+	} {
+		got, ok := patternSample(tt.pattern, tt.minLen, tt.maxLen)
+		if !ok || !regexp.MustCompile(tt.pattern).MatchString(got) || len(got) < tt.minLen || (tt.maxLen >= 0 && len(got) > tt.maxLen) {
+			t.Errorf("pattern %s: %q (ok=%v)", tt.pattern, got, ok)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Registros de extensões que podem ser usados em --extensions pelo nome; a CLI
+// registra o das extensões do Open Finance Brasil (ofb), embarcado no binário
+var builtinExtensionRegistries = map[string][]byte{}
+
+// RegisterExtensions torna um registro de extensões disponível pelo nome em
+// --extensions. Deve ser chamada antes de qualquer validação.
+func RegisterExtensions(name string, data []byte) {
+	builtinExtensionRegistries[name] = data
+}
+
+// Registro de extensões usado na validação (--extensions; off desativa) e severidade
+// das extensões que não estão nele (--unregistered-extensions)
+var (
+	extensionsFile         string
+	unregisteredExtensions = "info"
+)
+
+func registerExtensionFlags(fs *flag.FlagSet) {
+	fs.StringVar(&extensionsFile, "extensions", "", "registro de extensões (arquivo ou nome embarcado; off desativa; padrão: o da configuração ou ofb)")
+	fs.StringVar(&unregisteredExtensions, "unregistered-extensions", unregisteredExtensions, "severidade das extensões x- fora do registro: error, warning, info, hint ou off")
+}
+
+// Confere as flags e carrega o registro, para que um registro inválido seja erro de uso
+func checkExtensionFlags() error {
+	if unregisteredExtensions != "off" && !ruleSeverities[unregisteredExtensions] {
+		return fmt.Errorf("valor inválido para --unregistered-extensions: %q (use error, warning, info, hint ou off)", unregisteredExtensions)
+	}
+	_, err := flagSettings().extensionRegistry()
+	return err
+}
+
+// Locais (tipos de objeto da especificação) em que uma extensão pode aparecer
+var extensionLocations = []string{"root", "info", "contact", "license", "server", "serverVariable", "tag", "externalDocs", "paths", "pathItem", "operation", "parameter", "requestBody", "responses", "response", "header", "mediaType", "encoding", "example", "link", "callback", "schema", "discriminator", "xml", "components", "securityScheme", "oauthFlow"}
+
+// Campos de cada tipo de objeto que levam a outro objeto: "{}tipo" é um mapa de
+// objetos do tipo, "[]tipo" uma lista e "*" vale para qualquer chave que não seja
+// extensão (paths, responses e callbacks). Campos fora da tabela não são percorridos.
+var extensionFields = map[string]map[string]string{
+	"root":           {"info": "info", "servers": "[]server", "tags": "[]tag", "externalDocs": "externalDocs", "paths": "paths", "webhooks": "{}pathItem", "components": "components", "definitions": "{}schema", "parameters": "{}parameter", "responses": "{}response", "securityDefinitions": "{}securityScheme"},
+	"info":           {"contact": "contact", "license": "license"},
+	"server":         {"variables": "{}serverVariable"},
+	"tag":            {"externalDocs": "externalDocs"},
+	"paths":          {"*": "pathItem"},
+	"pathItem":       {"get": "operation", "put": "operation", "post": "operation", "delete": "operation", "options": "operation", "head": "operation", "patch": "operation", "trace": "operation", "parameters": "[]parameter", "servers": "[]server"},
+	"operation":      {"parameters": "[]parameter", "requestBody": "requestBody", "responses": "responses", "callbacks": "{}callback", "servers": "[]server", "externalDocs": "externalDocs"},
+	"callback":       {"*": "pathItem"},
+	"parameter":      {"schema": "schema", "items": "schema", "content": "{}mediaType", "examples": "{}example"},
+	"header":         {"schema": "schema", "items": "schema", "content": "{}mediaType", "examples": "{}example"},
+	"requestBody":    {"content": "{}mediaType"},
+	"responses":      {"*": "response"},
+	"response":       {"headers": "{}header", "content": "{}mediaType", "links": "{}link", "schema": "schema"},
+	"mediaType":      {"schema": "schema", "examples": "{}example", "encoding": "{}encoding"},
+	"encoding":       {"headers": "{}header"},
+	"link":           {"server": "server"},
+	"schema":         {"properties": "{}schema", "patternProperties": "{}schema", "$defs": "{}schema", "definitions": "{}schema", "dependentSchemas": "{}schema", "items": "schema", "additionalItems": "schema", "additionalProperties": "schema", "unevaluatedItems": "schema", "unevaluatedProperties": "schema", "propertyNames": "schema", "contains": "schema", "not": "schema", "if": "schema", "then": "schema", "else": "schema", "allOf": "[]schema", "anyOf": "[]schema", "oneOf": "[]schema", "prefixItems": "[]schema", "discriminator": "discriminator", "xml": "xml", "externalDocs": "externalDocs"},
+	"components":     {"schemas": "{}schema", "parameters": "{}parameter", "responses": "{}response", "headers": "{}header", "requestBodies": "{}requestBody", "securitySchemes": "{}securityScheme", "examples": "{}example", "links": "{}link", "callbacks": "{}callback", "pathItems": "{}pathItem"},
+	"securityScheme": {"flows": "{}oauthFlow"},
+}
+
+// Registro de extensões: o schema do valor e os locais permitidos de cada uma
+type extensionRegistry struct {
+	source     string
+	extensions map[string]*registeredExtension
+	names      []string
+}
+
+type registeredExtension struct {
+	name      string
+	locations []string // vazio: qualquer local
+	schema    *yaml.Node
+}
+
+// Registros já carregados, pelo arquivo ou nome
+var (
+	extensionRegistriesMu sync.Mutex
+	extensionRegistries   = map[string]*extensionRegistry{}
+)
+
+// Função para obter o registro em uso: o de --extensions, o de extensions em
+// .openapi-ci.yaml ou o embarcado ofb; nulo com off ou sem registro disponível
+func (s *runSettings) extensionRegistry() (*extensionRegistry, error) {
+	target := s.extensions
+	if target == "" {
+		if config, err := loadProjectConfig(projectConfigFile); err == nil && config != nil && config.Extensions != "" {
+			target = config.Extensions
+		}
+	}
+	if target == "" {
+		if _, ok := builtinExtensionRegistries["ofb"]; !ok {
+			return nil, nil
+		}
+		target = "ofb"
+	}
+	if target == "off" {
+		return nil, nil
+	}
+	return loadExtensionRegistry(target)
+}
+
+// Função para ler um registro de extensões: um arquivo existente tem precedência
+// sobre o registro embarcado com o mesmo nome
+//
+//	extensions:
+//	  x-maturity:
+//	    locations: [operation]
+//	    schema: {type: string, enum: [proposed, current, deprecated]}
+func loadExtensionRegistry(target string) (*extensionRegistry, error) {
+	extensionRegistriesMu.Lock()
+	defer extensionRegistriesMu.Unlock()
+	if registry, ok := extensionRegistries[target]; ok {
+		return registry, nil
+	}
+	var data []byte
+	if info, err := os.Stat(target); err == nil && !info.IsDir() || isRemoteURL(target) {
+		if data, err = flagSettings().readSource(target); err != nil {
+			return nil, err
+		}
+	} else if builtin, ok := builtinExtensionRegistries[target]; ok {
+		data = builtin
+	} else {
+		names := make([]string, 0, len(builtinExtensionRegistries))
+		for name := range builtinExtensionRegistries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("registro de extensões %q não encontrado (embarcados: %s)", target, strings.Join(names, ", "))
+	}
+	registry, err := parseExtensionRegistry(data, target)
+	if err != nil {
+		return nil, err
+	}
+	extensionRegistries[target] = registry
+	return registry, nil
+}
+
+func parseExtensionRegistry(data []byte, source string) (*extensionRegistry, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, yamlSyntaxError(data, source, err)
+	}
+	entries := mappingValue(documentContent(&doc), "extensions")
+	if entries == nil || entries.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("registro de extensões %s inválido: esperado extensions com um objeto por extensão", source)
+	}
+	known := map[string]bool{}
+	for _, location := range extensionLocations {
+		known[location] = true
+	}
+	registry := &extensionRegistry{source: source, extensions: map[string]*registeredExtension{}}
+	var problems []string
+	for i := 0; i+1 < len(entries.Content); i += 2 {
+		key, entry := entries.Content[i], entries.Content[i+1]
+		at := fmt.Sprintf("%s:%d: %s", source, key.Line, key.Value)
+		if !strings.HasPrefix(key.Value, "x-") {
+			problems = append(problems, fmt.Sprintf("%s: o nome de uma extensão começa com x-", at))
+			continue
+		}
+		if entry.Kind != yaml.MappingNode {
+			problems = append(problems, fmt.Sprintf("%s: esperado um objeto com schema e locations", at))
+			continue
+		}
+		ext := &registeredExtension{name: key.Value}
+		if schema := mappingValue(entry, "schema"); schema != nil {
+			if schema.Kind != yaml.MappingNode {
+				problems = append(problems, fmt.Sprintf("%s: schema deve ser um objeto (JSON Schema)", at))
+			}
+			ext.schema = schema
+		}
+		if locations := mappingValue(entry, "locations"); locations != nil {
+			if locations.Kind != yaml.SequenceNode {
+				problems = append(problems, fmt.Sprintf("%s: locations deve ser uma lista (ex.: [operation])", at))
+			}
+			for _, location := range locations.Content {
+				if !known[location.Value] {
+					problems = append(problems, fmt.Sprintf("%s: local %q desconhecido (use %s)%s", at, location.Value, strings.Join(extensionLocations, ", "), suggestionSuffix(location.Value, extensionLocations)))
+					continue
+				}
+				ext.locations = append(ext.locations, location.Value)
+			}
+		}
+		registry.extensions[ext.name] = ext
+		registry.names = append(registry.names, ext.name)
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("registro de extensões %s inválido:\n   - %s", source, strings.Join(problems, "\n   - "))
+	}
+	return registry, nil
+}
+
+// Função para conferir as extensões do documento: cada extensão do registro precisa
+// aparecer em um dos locais permitidos e ter um valor válido para o schema; as demais
+// são relatadas com a severidade de --unregistered-extensions. Os objetos são
+// percorridos pela estrutura da especificação, para que nomes de propriedades e de
+// cabeçalhos que começam com x- (x-fapi-interaction-id) não contem como extensões.
+func extensionViolations(root *yaml.Node, registry *extensionRegistry, unregistered string) []Violation {
+	var violations []Violation
+	var visit func(node *yaml.Node, kind, path string)
+	descend := func(node *yaml.Node, field, path string) {
+		for node != nil && node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+		switch {
+		case node == nil:
+		case strings.HasPrefix(field, "{}"):
+			forEachEntry(node, func(name string, value *yaml.Node) {
+				visit(value, field[2:], joinPath(path, name))
+			})
+		case strings.HasPrefix(field, "[]"), node.Kind == yaml.SequenceNode:
+			if node.Kind == yaml.SequenceNode {
+				for i, item := range node.Content {
+					visit(item, strings.TrimPrefix(field, "[]"), fmt.Sprintf("%s[%d]", path, i))
+				}
+			}
+		default:
+			visit(node, field, path)
+		}
+	}
+	visit = func(node *yaml.Node, kind, path string) {
+		for node != nil && node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+		if node == nil || node.Kind != yaml.MappingNode {
+			return
+		}
+		fields := extensionFields[kind]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if strings.HasPrefix(key.Value, "x-") {
+				violations = append(violations, registry.check(key, value, kind, joinPath(path, key.Value), unregistered)...)
+				continue
+			}
+			field, ok := fields[key.Value]
+			if !ok && key.Value != "$ref" {
+				field = fields["*"]
+			}
+			if field != "" {
+				descend(value, field, joinPath(path, key.Value))
+			}
+		}
+	}
+	visit(documentContent(root), "root", "$")
+	return violations
+}
+
+// Confere uma ocorrência da extensão no local (tipo do objeto que a contém)
+func (r *extensionRegistry) check(key, value *yaml.Node, location, path, unregistered string) []Violation {
+	ext := r.extensions[key.Value]
+	if ext == nil {
+		if unregistered == "off" {
+			return nil
+		}
+		return []Violation{{RuleID: "extension-unregistered", Severity: unregistered, Message: fmt.Sprintf("A extensão %s não está no registro de extensões (%s)%s.", key.Value, r.source, suggestionSuffix(key.Value, r.names)), JSONPath: path, Line: key.Line, Suggestion: "Corrija o nome ou inclua a extensão no registro, com o schema do valor e os locais em que ela pode aparecer."}}
+	}
+	allowed := len(ext.locations) == 0
+	for _, l := range ext.locations {
+		allowed = allowed || l == location
+	}
+	var violations []Violation
+	if !allowed {
+		violations = append(violations, Violation{RuleID: "extension-location", Severity: "error", Message: fmt.Sprintf("A extensão %s não pode aparecer em %s (locais permitidos: %s).", key.Value, location, strings.Join(ext.locations, ", ")), JSONPath: path, Line: key.Line})
+	}
+	if ext.schema != nil {
+		validator := &schemaValidator{root: ext.schema, v31: true}
+		if errs := validator.validate(ext.schema, value, path); len(errs) > 0 {
+			first := errs[0]
+			message := fmt.Sprintf("O valor da extensão %s não corresponde ao schema do registro: %s (palavra-chave %s", key.Value, first.message, first.keyword)
+			if first.at != path {
+				message += " em " + first.at
+			}
+			message += ")"
+			if len(errs) > 1 {
+				message += fmt.Sprintf(" e mais %d problema(s)", len(errs)-1)
+			}
+			violations = append(violations, Violation{RuleID: "extension-value", Severity: "error", Message: message + ".", JSONPath: path, Line: value.Line})
+		}
+	}
+	return violations
+}
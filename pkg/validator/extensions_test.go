@@ -0,0 +1,87 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+const extensionsRegistry = `extensions:
+  x-maturity:
+    locations: [operation]
+    schema: {type: string, enum: [proposed, current, deprecated]}
+  x-required-permissions:
+    locations: [operation]
+    schema: {type: array, items: {type: string}, minItems: 1}
+`
+
+const extensionsSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0, x-maturity: current}
+paths:
+  /contas:
+    get:
+      x-maturity: estavel
+      x-required-permissions: [ACCOUNTS_READ]
+      x-owner: contas
+      responses:
+        '200':
+          description: ok
+          headers:
+            x-fapi-interaction-id: {schema: {type: string}}
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  x-campo: {type: string}
+`
+
+// Extensões do registro são conferidas pelo local e pelo schema do valor; as demais
+// seguem --unregistered-extensions. Propriedades e cabeçalhos com x- não são extensões.
+func TestExtensionViolations(t *testing.T) {
+	registry, err := parseExtensionRegistry([]byte(extensionsRegistry), "extensoes.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := extensionViolations(mustParseYAML(t, extensionsSpec), registry, "warning")
+	want := []struct {
+		rule, severity, message string
+		line                    int
+	}{
+		{"extension-location", "error", "A extensão x-maturity não pode aparecer em info (locais permitidos: operation)", 2},
+		{"extension-value", "error", "O valor da extensão x-maturity não corresponde ao schema do registro", 6},
+		{"extension-unregistered", "warning", "A extensão x-owner não está no registro de extensões (extensoes.yaml)", 8},
+	}
+	if len(found) != len(want) {
+		t.Fatalf("esperadas %d violações, encontradas %d: %v", len(want), len(found), found)
+	}
+	for i, w := range want {
+		v := found[i]
+		if v.RuleID != w.rule || v.Severity != w.severity || v.Line != w.line || !strings.Contains(v.Message, w.message) {
+			t.Errorf("violação %d: %s %s linha %d %q, esperado %s %s linha %d %q", i, v.RuleID, v.Severity, v.Line, v.Message, w.rule, w.severity, w.line, w.message)
+		}
+	}
+	if found := extensionViolations(mustParseYAML(t, extensionsSpec), registry, "off"); len(found) != 2 {
+		t.Errorf("com off, as extensões fora do registro não deveriam ser relatadas: %v", found)
+	}
+}
+
+// Registros malformados são rejeitados com todos os problemas
+func TestExtensionRegistryErrors(t *testing.T) {
+	_, err := parseExtensionRegistry([]byte("extensions:\n  maturity: {}\n  x-a: 5\n  x-b: {locations: [operacao]}\n  x-c: {schema: string}\n"), "extensoes.yaml")
+	if err == nil {
+		t.Fatal("o registro deveria ser rejeitado")
+	}
+	for _, want := range []string{
+		"extensoes.yaml:2: maturity: o nome de uma extensão começa com x-",
+		"extensoes.yaml:3: x-a: esperado um objeto com schema e locations",
+		`extensoes.yaml:4: x-b: local "operacao" desconhecido`,
+		"extensoes.yaml:5: x-c: schema deve ser um objeto",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("erro sem %q:\n%v", want, err)
+		}
+	}
+	if _, err := parseExtensionRegistry([]byte("x-maturity: {}"), "extensoes.yaml"); err == nil || !strings.Contains(err.Error(), "esperado extensions") {
+		t.Errorf("registro sem extensions: %v", err)
+	}
+}
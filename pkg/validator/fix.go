@@ -0,0 +1,405 @@
+package validator
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Com --fix, o validate aplica as correções automáticas das regras marcadas com
+// fixable: true e regrava a especificação; --fix-dry-run apenas mostra o diff
+var (
+	fixSpecs  bool
+	fixDryRun bool
+)
+
+func registerFixFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&fixSpecs, "fix", false, "aplica as correções automáticas das regras com fixable: true e regrava a especificação")
+	fs.BoolVar(&fixDryRun, "fix-dry-run", false, "mostra em diff unificado o que --fix mudaria, sem gravar")
+}
+
+// As correções regravam o próprio arquivo: não se combinam com o que altera o
+// documento lido (overlays, conversão) nem com a escolha de um dos documentos
+func checkFixFlags() error {
+	if !fixSpecs && !fixDryRun {
+		return nil
+	}
+	switch {
+	case fixSpecs && fixDryRun:
+		return fmt.Errorf("use --fix ou --fix-dry-run, não os dois")
+	case len(overlayFiles) > 0:
+		return fmt.Errorf("--fix não pode ser combinado com --overlay: as correções seriam gravadas com o conteúdo do overlay")
+	case selectDocument > 0:
+		return fmt.Errorf("--fix não pode ser combinado com --select-document")
+	}
+	return nil
+}
+
+// Correção automática de uma regra: recebe o nó encontrado pelo given, o then.field e o
+// functionOptions da regra, altera a árvore e retorna false quando não há o que corrigir
+type ruleFix func(node *yaml.Node, field string, options map[string]interface{}) bool
+
+// Correções disponíveis, pelo nome da regra; a regra também precisa declarar fixable: true
+var ruleFixes = map[string]ruleFix{
+	"only-https":                   fixHTTPSURL,
+	"schema-additional-properties": fixAdditionalProperties,
+	"operation-tags":               fixOperationTags,
+	"operation-id-casing":          fixOperationIDCasing,
+	"response-codes-order":         fixOrdering,
+	"tags-order":                   fixOrdering,
+	"required-order":               fixOrdering,
+}
+
+func fixableRuleNames() []string {
+	names := make([]string, 0, len(ruleFixes))
+	for name := range ruleFixes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// O alvo da correção: o campo da regra no nó encontrado ou o próprio nó
+func fixTarget(node *yaml.Node, field string) *yaml.Node {
+	if field == "" {
+		return node
+	}
+	return mappingValue(node, field)
+}
+
+// URL de servidor com http:// ou sem esquema passa a usar https://; URLs relativas e
+// com variáveis no esquema ficam como estão
+func fixHTTPSURL(node *yaml.Node, field string, _ map[string]interface{}) bool {
+	target := fixTarget(node, field)
+	if target == nil || target.Kind != yaml.ScalarNode {
+		return false
+	}
+	url := target.Value
+	switch {
+	case strings.HasPrefix(strings.ToLower(url), "http://"):
+		target.Value = "https://" + url[len("http://"):]
+	case url == "" || strings.Contains(url, "://") || strings.HasPrefix(url, "/") || strings.HasPrefix(url, "{"):
+		return false
+	default:
+		target.Value = "https://" + url
+	}
+	return true
+}
+
+// Schema de objeto sem additionalProperties ganha additionalProperties: false
+func fixAdditionalProperties(node *yaml.Node, field string, _ map[string]interface{}) bool {
+	if field == "" {
+		field = "additionalProperties"
+	}
+	if node == nil || node.Kind != yaml.MappingNode || mappingValue(node, "$ref") != nil {
+		return false
+	}
+	if current := mappingValue(node, field); current != nil && current.Value == "false" {
+		return false
+	}
+	setValue(node, keyLike(node, field), boolLike(node, false))
+	return true
+}
+
+// Operação sem tags ganha a lista vazia tags: [], a ser preenchida depois
+func fixOperationTags(node *yaml.Node, field string, _ map[string]interface{}) bool {
+	if field == "" {
+		field = "tags"
+	}
+	if node == nil || node.Kind != yaml.MappingNode || mappingValue(node, field) != nil {
+		return false
+	}
+	setValue(node, keyLike(node, field), &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle, Line: node.Line, Column: node.Column})
+	return true
+}
+
+// operationId passa para lowerCamelCase: getAccounts, não get_accounts ou GetAccounts
+func fixOperationIDCasing(node *yaml.Node, field string, _ map[string]interface{}) bool {
+	target := fixTarget(node, field)
+	if target == nil || target.Kind != yaml.ScalarNode {
+		return false
+	}
+	id := lowerCamelCase(target.Value)
+	if id == "" || id == target.Value {
+		return false
+	}
+	target.Value = id
+	return true
+}
+
+// Texto em lowerCamelCase, com letras e dígitos (x-fapi-interaction-id → xFapiInteractionId)
+func lowerCamelCase(value string) string {
+	words := strings.FieldsFunc(value, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	var id strings.Builder
+	for i, word := range words {
+		runes := []rune(word)
+		if i == 0 {
+			runes[0] = unicode.ToLower(runes[0])
+		} else {
+			runes[0] = unicode.ToUpper(runes[0])
+		}
+		id.WriteString(string(runes))
+	}
+	return id.String()
+}
+
+// Função para aplicar as correções automáticas à especificação: com --fix o arquivo é
+// regravado; com --fix-dry-run o diff vai para a saída padrão. O documento é lido sem
+// conversão, para que as correções caiam no próprio arquivo, e a árvore de nós
+// preserva os comentários e a ordem das chaves.
+func fixSpec(inputFile string, rules map[string]interface{}) error {
+	data, err := readSpecFile(inputFile)
+	if err != nil {
+		return err
+	}
+	if raw, err := readFile(inputFile); err == nil && !bytes.Equal(raw, data) {
+		return fmt.Errorf("%s não está em UTF-8: --fix só regrava arquivos UTF-8", inputFile)
+	}
+	rootNode, err := parseSpecDocument(data, inputFile, 0)
+	if err != nil {
+		return err
+	}
+	if err := checkOpenAPIDocument(data, rootNode); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	reduced := isSwagger2(rootNode)
+	applied, total := map[string]int{}, 0
+	for _, name := range names {
+		ruleData, _ := rules[name].(map[string]interface{})
+		fix := ruleFixes[name]
+		if fixable, _ := ruleData["fixable"].(bool); !fixable || fix == nil {
+			continue
+		}
+		rule := compileRule(name, ruleData)
+		if rule == nil || (reduced && isOpenAPI3OnlyRule(rule.given)) {
+			continue
+		}
+		// O when é avaliado a cada regra, já com as correções das anteriores
+		matches, ok := rule.query(newGuardScope(rootNode))
+		if !ok {
+			continue
+		}
+		for _, m := range matches {
+			if !applyFunction(rule.function, fixTarget(m.Node, rule.field), rule.options) && fix(m.Node, rule.field, rule.options) {
+				applied[name]++
+				total++
+			}
+		}
+	}
+
+	if total == 0 {
+		fmt.Fprintln(stdout, "ℹ️  Nenhuma correção automática a aplicar em", inputFile)
+		return nil
+	}
+	format := formatYAML
+	if isJSONContent(data) {
+		format = formatJSON
+	}
+	fixed, err := marshalSpec(rootNode, format, detectIndent(data), foldedScalarsOf(data, rootNode))
+	if err != nil {
+		return err
+	}
+
+	fixedNames := make([]string, 0, len(applied))
+	for name := range applied {
+		fixedNames = append(fixedNames, name)
+	}
+	sort.Strings(fixedNames)
+	if fixDryRun {
+		fmt.Fprint(stdout, unifiedDiff(inputFile, normalizeLineEndings(data), fixed))
+	}
+	for _, name := range fixedNames {
+		fmt.Fprintf(stdout, "🔧 %s: %d correções\n", name, applied[name])
+	}
+	if fixDryRun {
+		fmt.Fprintf(stdout, "🔧 %d correções seriam aplicadas em %s (nada foi gravado)\n", total, inputFile)
+		return nil
+	}
+	if err := writeFileAtomic(inputFile, fixed); err != nil {
+		return fmt.Errorf("erro ao salvar %s: %v", inputFile, err)
+	}
+	fmt.Fprintf(stdout, "🔧 %d correções aplicadas em %s; validando novamente\n", total, inputFile)
+	return nil
+}
+
+// Linhas de contexto em volta de cada trecho alterado do diff
+const diffContext = 3
+
+type diffLine struct {
+	op   byte // ' ', '-' ou '+'
+	text string
+}
+
+// Função para gerar o diff unificado entre o conteúdo original e o corrigido
+func unifiedDiff(name string, before, after []byte) string {
+	lines := diffLines(splitLines(before), splitLines(after))
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- a/%s\n+++ b/%s\n", name, name)
+	for start := 0; start < len(lines); {
+		// Próximo trecho: da primeira alteração até um intervalo sem alterações maior
+		// que o dobro do contexto
+		first := start
+		for first < len(lines) && lines[first].op == ' ' {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+		last := first
+		for i := first; i < len(lines); i++ {
+			if lines[i].op != ' ' {
+				last = i
+			} else if i-last > 2*diffContext {
+				break
+			}
+		}
+		from, to := first-diffContext, last+diffContext+1
+		if from < start {
+			from = start
+		}
+		if to > len(lines) {
+			to = len(lines)
+		}
+
+		// Posição do trecho nos dois arquivos
+		oldStart, newStart := 1, 1
+		for _, l := range lines[:from] {
+			if l.op != '+' {
+				oldStart++
+			}
+			if l.op != '-' {
+				newStart++
+			}
+		}
+		oldCount, newCount := 0, 0
+		for _, l := range lines[from:to] {
+			if l.op != '+' {
+				oldCount++
+			}
+			if l.op != '-' {
+				newCount++
+			}
+		}
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		for _, l := range lines[from:to] {
+			out.WriteByte(l.op)
+			out.WriteString(l.text)
+			out.WriteByte('\n')
+		}
+		start = to
+	}
+	return out.String()
+}
+
+func splitLines(data []byte) []string {
+	text := strings.TrimSuffix(string(data), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// Diferença mínima entre duas listas de linhas (algoritmo de Myers); o início e o fim
+// em comum ficam de fora da busca, que assim cobre só o trecho alterado
+func diffLines(a, b []string) []diffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	var lines []diffLine
+	for _, text := range a[:prefix] {
+		lines = append(lines, diffLine{' ', text})
+	}
+	lines = append(lines, myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, text := range a[len(a)-suffix:] {
+		lines = append(lines, diffLine{' ', text})
+	}
+	return lines
+}
+
+func myersDiff(a, b []string) []diffLine {
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+	done := false
+	for d := 0; d <= max && !done; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				done = true
+				break
+			}
+		}
+	}
+
+	// Refaz o caminho do fim para o início
+	var reversed []diffLine
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && prev[offset+k-1] < prev[offset+k+1]) {
+			prevK = k + 1
+		}
+		prevX := prev[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			reversed = append(reversed, diffLine{' ', a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			reversed = append(reversed, diffLine{'+', b[y-1]})
+			y--
+		} else {
+			reversed = append(reversed, diffLine{'-', a[x-1]})
+			x--
+		}
+	}
+	for x > 0 && y > 0 {
+		reversed = append(reversed, diffLine{' ', a[x-1]})
+		x--
+		y--
+	}
+	lines := make([]diffLine, 0, len(reversed))
+	for i := len(reversed) - 1; i >= 0; i-- {
+		lines = append(lines, reversed[i])
+	}
+	return lines
+}
@@ -0,0 +1,56 @@
+package validator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// O diff de --fix-dry-run mostra só as linhas corrigidas: textos dobrados e URLs em
+// mappings em fluxo não mudam de forma
+func TestFixDryRunTouchesOnlyFixedLines(t *testing.T) {
+	spec := filepath.Join(t.TempDir(), "api.yaml")
+	source := `openapi: 3.0.3
+info:
+  title: Contas
+  version: 1.0.0
+  description: >
+    Texto dobrado
+    em duas linhas.
+servers:
+  - url: http://api.example.com/v1
+paths:
+  /contas:
+    get:
+      responses:
+        '200':
+          description: ok
+components:
+  securitySchemes:
+    oauth:
+      type: oauth2
+      flows:
+        clientCredentials: {tokenUrl: https://auth.example.com/token, scopes: {}}
+`
+	if err := os.WriteFile(spec, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out, errOut bytes.Buffer
+	Run([]string{"validate", "--no-cache", "--rules", "ofb", "--fix-dry-run", spec}, &out, &errOut)
+
+	var changed []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if (strings.HasPrefix(line, "-") || strings.HasPrefix(line, "+")) && !strings.HasPrefix(line, "---") && !strings.HasPrefix(line, "+++") {
+			changed = append(changed, line)
+		}
+	}
+	want := []string{"-  - url: http://api.example.com/v1", "+  - url: https://api.example.com/v1"}
+	if strings.Join(changed, "\n") != strings.Join(want, "\n") {
+		t.Errorf("linhas alteradas:\n%s\nesperado:\n%s\n--- saída\n%s%s", strings.Join(changed, "\n"), strings.Join(want, "\n"), out.String(), errOut.String())
+	}
+	if after, _ := os.ReadFile(spec); string(after) != source {
+		t.Error("--fix-dry-run não deveria gravar a especificação")
+	}
+}
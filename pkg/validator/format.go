@@ -0,0 +1,473 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formatos aceitos para o documento resolvido
+const (
+	formatYAML = "yaml"
+	formatJSON = "json"
+)
+
+// Função para escolher o formato de saída: o informado em --output-format ou,
+// na falta dele, o indicado pela extensão do arquivo de saída
+func outputFormatFor(outputFile, format string) (string, error) {
+	switch strings.ToLower(format) {
+	case formatYAML, "yml":
+		return formatYAML, nil
+	case formatJSON:
+		return formatJSON, nil
+	case "":
+		if strings.EqualFold(filepath.Ext(outputFile), ".json") {
+			return formatJSON, nil
+		}
+		return formatYAML, nil
+	}
+	return "", fmt.Errorf("formato de saída %q inválido (use yaml ou json)", format)
+}
+
+// Função para serializar o documento no formato escolhido. Em YAML, a árvore de nós
+// mantém a ordem das chaves, os comentários e as âncoras do original, e a indentação
+// é a do arquivo de origem, para que o diff contra a fonte mostre apenas os $refs resolvidos.
+// Os escalares dobrados com texto em sources voltam a ser escritos como na fonte (veja
+// protectScalars).
+func marshalSpec(rootNode *yaml.Node, format string, indent int, sources scalarSources) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeSpec(&buf, rootNode, format, indent, sources); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Serializa o documento direto em w; em YAML, a saída é escrita à medida que é
+// gerada, sem montar o documento inteiro em memória
+func encodeSpec(w io.Writer, rootNode *yaml.Node, format string, indent int, sources scalarSources) error {
+	out := &lfWriter{w: w}
+	if format == formatJSON {
+		data, err := encodeJSON(rootNode)
+		if err != nil {
+			return fmt.Errorf("erro ao converter para JSON: %v", err)
+		}
+		if _, err := out.Write(data); err != nil {
+			return err
+		}
+		return out.flush()
+	}
+
+	clearMergeTags(rootNode)
+	if err := encodeYAMLSections(out, rootNode, indent, sources); err != nil {
+		return fmt.Errorf("erro ao converter para YAML: %v", err)
+	}
+	return out.flush()
+}
+
+// Seções serializadas por partes: paths, webhooks e as seções de components (e as
+// equivalentes do Swagger 2.0)
+var chunkedSections = map[string]map[string]bool{
+	"paths":       nil,
+	"webhooks":    nil,
+	"definitions": nil,
+	"parameters":  nil,
+	"responses":   nil,
+	"components": {
+		"schemas": true, "responses": true, "parameters": true, "examples": true, "requestBodies": true,
+		"headers": true, "securitySchemes": true, "links": true, "callbacks": true, "pathItems": true,
+	},
+}
+
+// O Encoder do yaml.v3 guarda todos os eventos de um documento até o fim dele, o que
+// em documentos grandes ocupa mais memória que a própria árvore. Por isso cada par
+// chave/valor da raiz é serializado separadamente e, nas seções de chunkedSections,
+// cada entrada também, com a indentação aplicada aqui; o texto é o mesmo da
+// serialização do documento de uma vez.
+func encodeYAMLSections(w io.Writer, rootNode *yaml.Node, indent int, sources scalarSources) error {
+	if rootNode.Kind != yaml.DocumentNode || len(rootNode.Content) != 1 || !plainMapping(rootNode.Content[0]) {
+		return encodeYAMLNode(w, rootNode, indent, "", sources)
+	}
+	doc := rootNode.Content[0]
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		key, value := doc.Content[i], doc.Content[i+1]
+		chunk := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Content: []*yaml.Node{key, value}}}}
+		first, last := i == 0, i+2 == len(doc.Content)
+		if first {
+			chunk.HeadComment = rootNode.HeadComment
+		}
+		if last {
+			chunk.FootComment = rootNode.FootComment
+		}
+		sub, isSection := chunkedSections[key.Value]
+		if !isSection || first && rootNode.HeadComment != "" || last && rootNode.FootComment != "" || !plainKey(key) || !plainMapping(value) {
+			if err := encodeYAMLNode(w, chunk, indent, "", sources); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := encodeYAMLEntries(w, key, value, sub, indent, "", sources); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Escreve "chave:" e cada entrada do mapping como um documento separado, recuado
+// pela indentação; as entradas listadas em sub são divididas do mesmo modo
+func encodeYAMLEntries(w io.Writer, key, value *yaml.Node, sub map[string]bool, indent int, prefix string, sources scalarSources) error {
+	if _, err := io.WriteString(w, prefix+key.Value+":\n"); err != nil {
+		return err
+	}
+	inner := prefix + strings.Repeat(" ", indent)
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		k, v := value.Content[i], value.Content[i+1]
+		if sub[k.Value] && plainKey(k) && plainMapping(v) {
+			if err := encodeYAMLEntries(w, k, v, nil, indent, inner, sources); err != nil {
+				return err
+			}
+			continue
+		}
+		entry := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{k, v}}
+		if err := encodeYAMLNode(w, entry, indent, inner, sources); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Serializa um nó com um Encoder próprio, recuando as linhas não vazias por prefix;
+// os emoji saem como estão (veja protectSupplementary) e os escalares dobrados e os
+// com ":" em coleções em fluxo, como na fonte (veja protectScalars)
+func encodeYAMLNode(w io.Writer, node *yaml.Node, indent int, prefix string, sources scalarSources) error {
+	var buf bytes.Buffer
+	target := w
+	restoreScalars, rewrite := protectScalars(node, sources, indent)
+	restore, replacer := protectSupplementary(node)
+	if prefix != "" || replacer != nil || rewrite != nil {
+		target = &buf
+	}
+	encoder := yaml.NewEncoder(target)
+	encoder.SetIndent(indent)
+	err := encoder.Encode(node)
+	if err == nil {
+		err = encoder.Close()
+	}
+	if restore != nil {
+		restore()
+	}
+	if restoreScalars != nil {
+		restoreScalars()
+	}
+	if err != nil {
+		return err
+	}
+	if target == w {
+		return nil
+	}
+	data := buf.Bytes()
+	if replacer != nil {
+		data = []byte(replacer.Replace(string(data)))
+	}
+	if rewrite != nil {
+		data = rewrite(data)
+	}
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if len(line) > 0 && line[0] != '\n' {
+			if _, err := io.WriteString(w, prefix); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Chave escrita como texto simples, sem comentários, âncora ou tag
+func plainKey(key *yaml.Node) bool {
+	return key.Kind == yaml.ScalarNode && key.Style == 0 && (key.Tag == "" || key.Tag == "!!str") && key.Anchor == "" &&
+		key.HeadComment == "" && key.LineComment == "" && key.FootComment == ""
+}
+
+// Mapping em bloco não vazio, sem comentários, âncora ou tag próprios
+func plainMapping(node *yaml.Node) bool {
+	return node.Kind == yaml.MappingNode && len(node.Content) > 0 && node.Style&yaml.FlowStyle == 0 && (node.Tag == "" || node.Tag == "!!map") &&
+		node.Anchor == "" && node.HeadComment == "" && node.LineComment == "" && node.FootComment == ""
+}
+
+// Escreve com as quebras de linha normalizadas para LF; um \r no fim de um bloco
+// aguarda o próximo para saber se faz parte de um \r\n
+type lfWriter struct {
+	w         io.Writer
+	pendingCR bool
+	buf       []byte
+}
+
+func (l *lfWriter) Write(p []byte) (int, error) {
+	l.buf = l.buf[:0]
+	for _, b := range p {
+		if l.pendingCR {
+			l.pendingCR = false
+			l.buf = append(l.buf, '\n')
+			if b == '\n' {
+				continue
+			}
+		}
+		if b == '\r' {
+			l.pendingCR = true
+			continue
+		}
+		l.buf = append(l.buf, b)
+	}
+	if _, err := l.w.Write(l.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (l *lfWriter) flush() error {
+	if !l.pendingCR {
+		return nil
+	}
+	l.pendingCR = false
+	_, err := l.w.Write([]byte{'\n'})
+	return err
+}
+
+// Quebras de linha sempre LF, independente da plataforma e das quebras do arquivo de origem
+func normalizeLineEndings(data []byte) []byte {
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	return bytes.ReplaceAll(data, []byte("\r"), []byte("\n"))
+}
+
+// O yaml.v3 escreve a chave de merge como "!!merge <<"; sem a tag explícita ela volta a ser "<<"
+func clearMergeTags(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if key := node.Content[i]; key.Value == "<<" && key.Tag == "!!merge" {
+				key.Tag = ""
+			}
+		}
+	}
+	for _, child := range node.Content {
+		clearMergeTags(child)
+	}
+}
+
+// Indentação padrão quando o arquivo não tem linhas indentadas (ou é JSON)
+const defaultIndent = 2
+
+// Função para descobrir a indentação usada no arquivo: o menor recuo entre as
+// linhas indentadas que não são comentários
+func detectIndent(data []byte) int {
+	indent := 0
+	for _, line := range sourceLines(data) {
+		trimmed := strings.TrimLeft(line, " ")
+		n := len(line) - len(trimmed)
+		if n == 0 || strings.TrimSpace(trimmed) == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if indent == 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent < 2 || indent > 8 || isJSONContent(data) {
+		return defaultIndent
+	}
+	return indent
+}
+
+// Indica se o conteúdo é JSON: o primeiro caractere significativo abre um objeto ou array
+func isJSONContent(data []byte) bool {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), " \t\r\n")
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+// Confere a sintaxe JSON para relatar o erro na posição do arquivo; o parser YAML
+// aceita JSON, mas aponta erros em posições que não correspondem à sintaxe JSON
+func checkJSONSyntax(data []byte, source string) error {
+	if json.Valid(data) {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	var v interface{}
+	for {
+		err := decoder.Decode(&v)
+		if err == nil {
+			continue
+		}
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, column := offsetPosition(data, syntaxErr.Offset)
+			return fmt.Errorf("erro de sintaxe JSON em %s, linha %d, coluna %d: %v%s", source, line, column, err, sourceExcerpt(data, line))
+		}
+		return fmt.Errorf("erro de sintaxe JSON em %s: %v", source, err)
+	}
+}
+
+// Converte um deslocamento em bytes para linha e coluna (a partir de 1), como os
+// editores mostram: CRLF e CR contam como uma quebra e a coluna conta caracteres
+func offsetPosition(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := normalizeLineEndings(data[:offset])
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := utf8.RuneCount(before[bytes.LastIndexByte(before, '\n')+1:]) + 1
+	return line, column
+}
+
+// Linhas do conteúdo, aceitando quebras LF, CRLF e CR
+func sourceLines(data []byte) []string {
+	return strings.Split(string(normalizeLineEndings(data)), "\n")
+}
+
+// Função para converter a árvore YAML em JSON com indentação de 2 espaços,
+// mantendo a ordem das chaves e os números como escritos no arquivo
+func encodeJSON(node *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeJSONNode(&buf, node, ""); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func writeJSONNode(buf *bytes.Buffer, node *yaml.Node, indent string) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeJSONNode(buf, node.Content[0], indent)
+	case yaml.AliasNode:
+		if node.Alias == nil {
+			return fmt.Errorf("alias *%s sem âncora (linha %d)", node.Value, node.Line)
+		}
+		return writeJSONNode(buf, node.Alias, indent)
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteString("{\n")
+		inner := indent + "  "
+		for i, entry := range mergedEntries(node) {
+			if i > 0 {
+				buf.WriteString(",\n")
+			}
+			buf.WriteString(inner)
+			writeJSONString(buf, entry[0].Value)
+			buf.WriteString(": ")
+			if err := writeJSONNode(buf, entry[1], inner); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + indent + "}")
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteString("[\n")
+		inner := indent + "  "
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteString(",\n")
+			}
+			buf.WriteString(inner)
+			if err := writeJSONNode(buf, item, inner); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + indent + "]")
+	case yaml.ScalarNode:
+		return writeJSONScalar(buf, node)
+	}
+	return nil
+}
+
+// Pares chave/valor do mapping com as chaves de merge (<<) expandidas; as chaves
+// escritas no próprio mapping prevalecem sobre as herdadas
+func mergedEntries(node *yaml.Node) [][2]*yaml.Node {
+	var own, merged [][2]*yaml.Node
+	seen := map[string]bool{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Value == "<<" && key.ShortTag() == "!!merge" {
+			sources := []*yaml.Node{value}
+			if value.Kind == yaml.SequenceNode {
+				sources = value.Content
+			}
+			for _, source := range sources {
+				if source.Kind == yaml.AliasNode && source.Alias != nil {
+					source = source.Alias
+				}
+				if source.Kind == yaml.MappingNode {
+					merged = append(merged, mergedEntries(source)...)
+				}
+			}
+			continue
+		}
+		own = append(own, [2]*yaml.Node{key, value})
+		seen[key.Value] = true
+	}
+	for _, entry := range merged {
+		if !seen[entry[0].Value] {
+			own = append(own, entry)
+			seen[entry[0].Value] = true
+		}
+	}
+	return own
+}
+
+// Escreve um escalar pelo seu tipo YAML; números válidos em JSON são copiados sem conversão
+func writeJSONScalar(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.ShortTag() {
+	case "!!null":
+		buf.WriteString("null")
+	case "!!bool":
+		var b bool
+		if err := node.Decode(&b); err != nil {
+			return err
+		}
+		buf.WriteString(strconv.FormatBool(b))
+	case "!!int", "!!float":
+		if json.Valid([]byte(node.Value)) {
+			buf.WriteString(node.Value)
+			return nil
+		}
+		// Formas exclusivas do YAML (0x1F, 1_000, +1, .5) são convertidas
+		var f float64
+		if err := node.Decode(&f); err != nil {
+			return err
+		}
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return fmt.Errorf("valor %s na linha %d não pode ser representado em JSON", node.Value, node.Line)
+		}
+		buf.WriteString(strconv.FormatFloat(f, 'f', -1, 64))
+	default:
+		writeJSONString(buf, node.Value)
+	}
+	return nil
+}
+
+// Escreve uma string JSON sem escapar <, > e & (comuns em descrições)
+func writeJSONString(buf *bytes.Buffer, s string) {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	buf.Write(bytes.TrimSuffix(out.Bytes(), []byte("\n")))
+}
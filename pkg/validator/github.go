@@ -0,0 +1,262 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Marcador oculto que identifica o comentário do validador no PR, para atualizá-lo
+// em vez de acumular um comentário por execução
+const githubCommentMarker = "<!-- ofb-openapi-validator -->"
+
+// Violações listadas no comentário; o restante fica só nos contadores
+const githubCommentMaxViolations = 50
+
+// Opções de --github-comment; repositório e PR têm como padrão as variáveis do Actions
+var (
+	githubComment bool
+	githubRepo    string
+	githubPR      int
+)
+
+func registerGitHubFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&githubComment, "github-comment", false, "publica o resumo como comentário no PR (atualizando o anterior do validador); o token vem de GITHUB_TOKEN")
+	fs.StringVar(&githubRepo, "github-repo", "", "repositório do PR no formato dono/nome (padrão: GITHUB_REPOSITORY)")
+	fs.IntVar(&githubPR, "github-pr", 0, "número do PR (padrão: o do evento do GitHub Actions)")
+}
+
+// Confere --github-pr
+func checkGitHubFlags() error {
+	if githubPR < 0 {
+		return fmt.Errorf("valor inválido para --github-pr: %d", githubPR)
+	}
+	if githubRepo != "" && strings.Count(githubRepo, "/") != 1 {
+		return fmt.Errorf("valor inválido para --github-repo: %q (use dono/nome)", githubRepo)
+	}
+	return nil
+}
+
+// Destino do comentário: repositório, PR e token, das flags ou do ambiente do Actions
+type githubTarget struct {
+	api   string
+	repo  string
+	pr    int
+	token string
+}
+
+func resolveGitHubTarget() (*githubTarget, error) {
+	target := &githubTarget{api: strings.TrimSuffix(os.Getenv("GITHUB_API_URL"), "/"), repo: githubRepo, pr: githubPR, token: os.Getenv("GITHUB_TOKEN")}
+	if target.api == "" {
+		target.api = "https://api.github.com"
+	}
+	if target.repo == "" {
+		target.repo = os.Getenv("GITHUB_REPOSITORY")
+	}
+	if target.pr == 0 {
+		target.pr = pullRequestFromEnv()
+	}
+	var missing []string
+	if target.token == "" {
+		missing = append(missing, "GITHUB_TOKEN")
+	}
+	if target.repo == "" {
+		missing = append(missing, "repositório (--github-repo ou GITHUB_REPOSITORY)")
+	}
+	if target.pr == 0 {
+		missing = append(missing, "número do PR (--github-pr ou evento pull_request)")
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("faltam %s", strings.Join(missing, ", "))
+	}
+	return target, nil
+}
+
+// Número do PR no evento do Actions (GITHUB_EVENT_PATH) ou em GITHUB_REF (refs/pull/N/merge)
+func pullRequestFromEnv() int {
+	if path := os.Getenv("GITHUB_EVENT_PATH"); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			var event struct {
+				Number      int `json:"number"`
+				PullRequest struct {
+					Number int `json:"number"`
+				} `json:"pull_request"`
+			}
+			if json.Unmarshal(data, &event) == nil {
+				if event.PullRequest.Number > 0 {
+					return event.PullRequest.Number
+				}
+				if event.Number > 0 {
+					return event.Number
+				}
+			}
+		}
+	}
+	if ref := os.Getenv("GITHUB_REF"); strings.HasPrefix(ref, "refs/pull/") {
+		number, _, _ := strings.Cut(strings.TrimPrefix(ref, "refs/pull/"), "/")
+		if n, err := strconv.Atoi(number); err == nil {
+			return n
+		}
+	}
+	return 0
+}
+
+// Publica o resumo no PR (--github-comment). Falhas só geram um
+// aviso: o comentário não muda o resultado da validação nem o código de saída.
+func commentOnPullRequest(summary string) {
+	target, err := resolveGitHubTarget()
+	if err == nil {
+		err = target.upsertComment(githubCommentMarker + "\n" + summary)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "⚠️  Comentário no PR não publicado:", err)
+		return
+	}
+	fmt.Fprintf(stderr, "ℹ️  Resumo publicado no PR #%d de %s.\n", target.pr, target.repo)
+}
+
+// Atualiza o comentário anterior do validador ou cria um novo
+func (t *githubTarget) upsertComment(body string) error {
+	id, err := t.findComment()
+	if err != nil {
+		return err
+	}
+	payload := map[string]string{"body": body}
+	if id != 0 {
+		return t.call(http.MethodPatch, fmt.Sprintf("/repos/%s/issues/comments/%d", t.repo, id), payload, nil)
+	}
+	return t.call(http.MethodPost, fmt.Sprintf("/repos/%s/issues/%d/comments", t.repo, t.pr), payload, nil)
+}
+
+// Procura, página a página, o comentário com o marcador do validador
+func (t *githubTarget) findComment() (int64, error) {
+	for page := 1; ; page++ {
+		var comments []struct {
+			ID   int64  `json:"id"`
+			Body string `json:"body"`
+		}
+		if err := t.call(http.MethodGet, fmt.Sprintf("/repos/%s/issues/%d/comments?per_page=100&page=%d", t.repo, t.pr, page), nil, &comments); err != nil {
+			return 0, err
+		}
+		for _, c := range comments {
+			if strings.Contains(c.Body, githubCommentMarker) {
+				return c.ID, nil
+			}
+		}
+		if len(comments) < 100 {
+			return 0, nil
+		}
+	}
+}
+
+// Chamada à API REST do GitHub
+func (t *githubTarget) call(method, path string, payload, result interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := marshalJSON(payload, "")
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, t.api+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&apiErr)
+		if apiErr.Message != "" {
+			return fmt.Errorf("%s %s: resposta HTTP %s: %s", method, path, resp.Status, apiErr.Message)
+		}
+		return fmt.Errorf("%s %s: resposta HTTP %s", method, path, resp.Status)
+	}
+	if result != nil {
+		return json.NewDecoder(resp.Body).Decode(result)
+	}
+	return nil
+}
+
+// Título do resumo publicado no PR
+const githubCommentTitle = "Validação OpenAPI"
+
+// Resumo em Markdown das especificações validadas: contadores por arquivo, a matriz de
+// exemplos (--examples-matrix) e as violações
+func validationMarkdown(title string, reports []*validationReport, failures map[string]string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", title)
+	b.WriteString("| Especificação | Erros | Avisos | Resultado |\n|---|---:|---:|---|\n")
+	total := 0
+	for _, r := range reports {
+		status := "✅ aprovada"
+		if r.Errors > 0 {
+			status = "❌ reprovada"
+		}
+		fmt.Fprintf(&b, "| `%s` | %d | %d | %s |\n", r.File, r.Errors, r.Warnings, status)
+		total += len(r.Violations)
+	}
+	files := make([]string, 0, len(failures))
+	for file := range failures {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		fmt.Fprintf(&b, "| `%s` | - | - | 💥 %s |\n", file, markdownCell(failures[file]))
+	}
+	for _, r := range reports {
+		if len(r.ExamplesMatrix) > 0 {
+			fmt.Fprintf(&b, "\n<details><summary>Exemplos das respostas: <code>%s</code></summary>\n\n%s\n</details>\n", r.File, examplesMatrixMarkdown(r.ExamplesMatrix))
+		}
+	}
+	if total == 0 {
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "\n<details><summary>%d violações</summary>\n\n", total)
+	listed := 0
+	for _, r := range reports {
+		for _, v := range r.Violations {
+			if listed == githubCommentMaxViolations {
+				fmt.Fprintf(&b, "\n… e mais %d; veja o log da execução.\n", total-listed)
+				b.WriteString("\n</details>\n")
+				return b.String()
+			}
+			where := ""
+			if v.File == "" {
+				where = "`" + r.File + "` "
+			}
+			fmt.Fprintf(&b, "- **%s** %s%s\n", v.Severity, where, markdownCell(strings.TrimPrefix(v.Error(), "["+v.Severity+"] ")))
+			listed++
+		}
+	}
+	b.WriteString("\n</details>\n")
+	return b.String()
+}
+
+// Texto numa linha de tabela ou lista Markdown
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	return strings.ReplaceAll(s, "|", "\\|")
+}
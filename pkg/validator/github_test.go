@@ -0,0 +1,89 @@
+package validator
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// API do GitHub de mentira: responde a lista de comentários do PR e registra as
+// chamadas que publicam ou atualizam
+type fakeGitHub struct {
+	mu       sync.Mutex
+	comments string // resposta de GET .../comments
+	status   int    // status das chamadas de escrita
+	calls    []string
+	bodies   []string
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer segredo" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if r.Method == http.MethodGet {
+		io.WriteString(w, f.comments)
+		return
+	}
+	var payload struct {
+		Body string `json:"body"`
+	}
+	json.NewDecoder(r.Body).Decode(&payload)
+	f.calls = append(f.calls, r.Method+" "+r.URL.Path)
+	f.bodies = append(f.bodies, payload.Body)
+	w.WriteHeader(f.status)
+	io.WriteString(w, `{"message": "falhou"}`)
+}
+
+// --github-comment atualiza o comentário anterior do validador (pelo marcador) ou cria
+// um novo; uma falha da API só gera aviso e não muda o código de saída
+func TestGitHubComment(t *testing.T) {
+	spec := filepath.Join("testdata", "e2e", "valid", "api.yaml")
+	tests := []struct {
+		name, comments string
+		status         int
+		call, message  string
+	}{
+		{"atualiza o anterior", `[{"id": 3, "body": "outro"}, {"id": 7, "body": "` + githubCommentMarker + `\nantigo"}]`, http.StatusOK,
+			"PATCH /repos/dono/api/issues/comments/7", "ℹ️  Resumo publicado no PR #5 de dono/api."},
+		{"cria um novo", `[{"id": 3, "body": "outro"}]`, http.StatusCreated,
+			"POST /repos/dono/api/issues/5/comments", "ℹ️  Resumo publicado no PR #5 de dono/api."},
+		{"falha na API", `[]`, http.StatusInternalServerError,
+			"POST /repos/dono/api/issues/5/comments", "⚠️  Comentário no PR não publicado: POST /repos/dono/api/issues/5/comments: resposta HTTP 500 Internal Server Error: falhou"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			github := &fakeGitHub{comments: tt.comments, status: tt.status}
+			server := httptest.NewServer(github)
+			defer server.Close()
+			t.Setenv("GITHUB_API_URL", server.URL)
+			t.Setenv("GITHUB_TOKEN", "segredo")
+
+			code, out := runCommand(t, "validate", "--no-cache", "--github-comment", "--github-repo", "dono/api", "--github-pr", "5", spec)
+			if code != exitOK || !strings.Contains(out, tt.message) {
+				t.Errorf("código %d, saída sem %q:\n%s", code, tt.message, out)
+			}
+			if len(github.calls) != 1 || github.calls[0] != tt.call {
+				t.Fatalf("chamadas %v, esperado [%s]", github.calls, tt.call)
+			}
+			if body := github.bodies[0]; !strings.HasPrefix(body, githubCommentMarker+"\n") || !strings.Contains(body, githubCommentTitle) {
+				t.Errorf("comentário sem o marcador ou o título:\n%s", body)
+			}
+		})
+	}
+
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GITHUB_EVENT_PATH", "")
+	t.Setenv("GITHUB_REF", "")
+	code, out := runCommand(t, "validate", "--no-cache", "--github-comment", "--github-repo", "dono/api", spec)
+	if code != exitOK || !strings.Contains(out, "⚠️  Comentário no PR não publicado: faltam GITHUB_TOKEN, número do PR") {
+		t.Errorf("sem token e PR: código %d\n%s", code, out)
+	}
+}
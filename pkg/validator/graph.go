@@ -0,0 +1,396 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Flags do subcomando graph
+type graphOptions struct {
+	format    string
+	output    string
+	component string
+	minRefs   int
+	timeout   time.Duration
+}
+
+func (o *graphOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.format, "format", "dot", "formato do grafo: dot (Graphviz) ou mermaid")
+	fs.StringVar(&o.output, "o", "", "arquivo de saída (padrão: saída padrão)")
+	fs.StringVar(&o.component, "component", "", "mostra só o componente e os que ele referencia, direta ou indiretamente (ex.: schemas/Conta)")
+	fs.IntVar(&o.minRefs, "min-refs", 0, "agrupa em um único nó os componentes com menos referências diretas que este valor (0 desativa)")
+	fs.DurationVar(&o.timeout, "timeout", defaultTimeout, "tempo máximo da execução (0 desativa)")
+	registerRefFlags(fs)
+	registerHTTPFlags(fs)
+	registerEncodingFlag(fs)
+	registerInputLimitFlag(fs)
+}
+
+var graphCommand = &command{
+	Name:    "graph",
+	Args:    "<spec.yaml>",
+	Summary: "exporta o grafo de dependências dos componentes (DOT ou Mermaid)",
+	Description: `Indexa as referências da especificação e exporta um grafo dirigido dos
+componentes: cada nó é um componente (tipo e nome, ex.: schemas Conta; os de
+outros arquivos trazem o arquivo) e cada aresta, um $ref de um componente para
+outro. --format dot gera um digraph do Graphviz e --format mermaid, um
+flowchart para colar em Markdown.
+
+As arestas que fazem parte de um ciclo (incluindo um schema que referencia a
+si mesmo) têm um estilo próprio (vermelho e tracejado), já que são elas que a
+política de referências circulares (--fail-on-circular) alcança.
+
+Com --component, o grafo mostra só o componente e os que ele referencia, de
+forma transitiva; o nome pode ser o $ref (#/components/schemas/Conta), o tipo
+e o nome (schemas/Conta) ou só o nome, quando não há dois com o mesmo nome.
+Com --min-refs N, os componentes com menos de N referências diretas são
+agrupados em um único nó, para destacar os compartilhados.`,
+	Examples: []string{
+		programName + " graph -o componentes.dot swagger.yaml",
+		programName + " graph --format mermaid -o componentes.md swagger.yaml",
+		programName + " graph --component schemas/Conta --format mermaid swagger.yaml",
+		programName + " graph --min-refs 3 swagger.yaml | dot -Tsvg -o componentes.svg",
+	},
+	Flags: func(fs *flag.FlagSet) { new(graphOptions).register(fs) },
+	Run:   runGraph,
+}
+
+// Nó do grafo: um componente ou o grupo dos componentes pouco referenciados
+type graphNode struct {
+	id    string
+	kind  string
+	name  string
+	group int // componentes agrupados (--min-refs); zero para os nós comuns
+}
+
+// Aresta do grafo, marcada quando faz parte de um ciclo
+type graphEdge struct {
+	from, to string
+	cycle    bool
+}
+
+// Grafo pronto para exportar, com os nós na ordem de saída
+type componentGraph struct {
+	nodes []*graphNode
+	edges []graphEdge
+}
+
+// Subcomando graph: exporta o grafo dos componentes
+func runGraph(c *command, args []string) int {
+	opts := &graphOptions{}
+	fs := c.newFlagSet()
+	opts.register(fs)
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	if err := checkHTTPFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkEncodingFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if opts.format != "dot" && opts.format != "mermaid" {
+		return c.usageError("formato %q inválido para graph (use dot ou mermaid)", opts.format)
+	}
+	if opts.minRefs < 0 {
+		return c.usageError("valor inválido para --min-refs: %d", opts.minRefs)
+	}
+	if fs.NArg() != 1 {
+		return c.usageError("esperado um argumento (especificação), recebidos %d", fs.NArg())
+	}
+	inputFile := fs.Arg(0)
+
+	ctx, cancel := newRunContext(opts.timeout)
+	defer cancel()
+
+	doc, err := openSpecDocument(flagSettings(), inputFile)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌", err)
+		return exitFailure
+	}
+	if _, err := doc.index(ctx); err != nil {
+		return statsError(inputFile, err, opts.timeout)
+	}
+	usage := collectRefUsage(inputFile, doc.indexedDocuments())
+
+	focus := ""
+	if opts.component != "" {
+		var problem string
+		if focus, problem = findGraphComponent(usage, opts.component); problem != "" {
+			fmt.Fprintln(stdout, "❌", problem)
+			return exitFailure
+		}
+	}
+	graph := buildComponentGraph(usage, focus, opts.minRefs)
+
+	var out string
+	if opts.format == "mermaid" {
+		out = graph.mermaid()
+	} else {
+		out = graph.dot()
+	}
+	if opts.output == "" {
+		fmt.Fprint(stdout, out)
+		return exitOK
+	}
+	if err := os.MkdirAll(filepath.Dir(opts.output), 0755); err != nil {
+		fmt.Fprintf(stdout, "❌ Erro ao criar diretório de %s: %v\n", opts.output, err)
+		return exitFailure
+	}
+	if err := writeFileAtomic(opts.output, []byte(out)); err != nil {
+		fmt.Fprintf(stdout, "❌ Erro ao salvar %s: %v\n", opts.output, err)
+		return exitFailure
+	}
+	cycles := 0
+	for _, e := range graph.edges {
+		if e.cycle {
+			cycles++
+		}
+	}
+	fmt.Fprintf(stdout, "✅ Grafo com %d nó(s) e %d aresta(s) salvo em %s\n", len(graph.nodes), len(graph.edges), opts.output)
+	if cycles > 0 {
+		fmt.Fprintf(stdout, "⚠️  %d aresta(s) fazem parte de ciclos de referência (destacadas no grafo)\n", cycles)
+	}
+	return exitOK
+}
+
+// Função para achar o componente de --component pelo $ref, por tipo/nome ou só pelo
+// nome; devolve o problema (inexistente ou ambíguo) para a mensagem
+func findGraphComponent(usage *refUsageReport, name string) (string, string) {
+	var matches, names []string
+	for _, c := range usage.Components {
+		kind, short := componentKindName(c.Ref)
+		if strings.Contains(name, "/") {
+			names = append(names, kind+"/"+short)
+		} else {
+			names = append(names, short)
+		}
+		if c.Ref == name || kind+"/"+short == name || short == name {
+			matches = append(matches, c.Ref)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Sprintf("componente %q não encontrado na especificação%s", name, suggestionSuffix(name, names))
+	case 1:
+		return matches[0], ""
+	}
+	sort.Strings(matches)
+	return "", fmt.Sprintf("componente %q é ambíguo: %s (use o tipo e o nome, ex.: schemas/%s)", name, strings.Join(matches, ", "), name)
+}
+
+// Tipo e nome do componente a partir do $ref: components.<tipo>.<nome>, as seções do
+// Swagger 2.0 ou, nos demais casos, o arquivo e o último segmento do ponteiro
+func componentKindName(ref string) (string, string) {
+	file, pointer := splitRef(ref)
+	segments := pointerSegments(pointer)
+	var kind, name string
+	switch {
+	case len(segments) == 3 && segments[0] == "components":
+		kind, name = segments[1], segments[2]
+	case len(segments) == 2:
+		kind, name = segments[0], segments[1]
+	case len(segments) > 0:
+		kind, name = strings.Join(segments[:len(segments)-1], "/"), segments[len(segments)-1]
+	default:
+		kind, name = "arquivo", file
+	}
+	if file != "" && len(segments) > 0 {
+		if kind == "" {
+			kind = file
+		} else {
+			kind = file + ": " + kind
+		}
+	}
+	return kind, name
+}
+
+// Função para montar o grafo a partir do uso das referências: restringe ao fecho do
+// componente focus (quando informado), marca as arestas que estão em ciclos e agrupa
+// os componentes com menos de minRefs referências diretas
+func buildComponentGraph(usage *refUsageReport, focus string, minRefs int) *componentGraph {
+	components := map[string]componentUsage{}
+	for _, c := range usage.Components {
+		components[c.Ref] = c
+	}
+	included := map[string]bool{}
+	if focus == "" {
+		for ref := range components {
+			included[ref] = true
+		}
+	} else {
+		queue := []string{focus}
+		included[focus] = true
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for _, next := range components[current].Outbound {
+				if !included[next] {
+					included[next] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+	}
+	refs := make([]string, 0, len(included))
+	for ref := range included {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+
+	// Componentes fortemente conexos (Tarjan): uma aresta dentro do mesmo componente
+	// conexo fecha um ciclo
+	sccOf := map[string]int{}
+	indexOf, lowOf := map[string]int{}, map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	counter, sccs := 0, 0
+	var connect func(ref string)
+	connect = func(ref string) {
+		indexOf[ref], lowOf[ref] = counter, counter
+		counter++
+		stack = append(stack, ref)
+		onStack[ref] = true
+		for _, next := range components[ref].Outbound {
+			if !included[next] {
+				continue
+			}
+			if _, seen := indexOf[next]; !seen {
+				connect(next)
+				if lowOf[next] < lowOf[ref] {
+					lowOf[ref] = lowOf[next]
+				}
+			} else if onStack[next] && indexOf[next] < lowOf[ref] {
+				lowOf[ref] = indexOf[next]
+			}
+		}
+		if lowOf[ref] == indexOf[ref] {
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				sccOf[top] = sccs
+				if top == ref {
+					break
+				}
+			}
+			sccs++
+		}
+	}
+	for _, ref := range refs {
+		if _, seen := indexOf[ref]; !seen {
+			connect(ref)
+		}
+	}
+
+	// Agrupar os pouco referenciados; o componente de --component nunca é agrupado
+	graph := &componentGraph{}
+	nodeOf := map[string]string{}
+	var group *graphNode
+	for _, ref := range refs {
+		if minRefs > 0 && ref != focus && len(components[ref].Inbound) < minRefs {
+			if group == nil {
+				group = &graphNode{id: "(agrupados)"}
+			}
+			group.group++
+			nodeOf[ref] = group.id
+			continue
+		}
+		kind, name := componentKindName(ref)
+		graph.nodes = append(graph.nodes, &graphNode{id: ref, kind: kind, name: name})
+		nodeOf[ref] = ref
+	}
+	if group != nil {
+		graph.nodes = append(graph.nodes, group)
+		group.name = fmt.Sprintf("%d componente(s) com menos de %d referência(s)", group.group, minRefs)
+	}
+
+	edges := map[[2]string]int{}
+	add := func(from, to string, cycle bool) {
+		key := [2]string{nodeOf[from], nodeOf[to]}
+		if key[0] == key[1] && group != nil && key[0] == group.id {
+			return
+		}
+		if i, ok := edges[key]; ok {
+			graph.edges[i].cycle = graph.edges[i].cycle || cycle
+			return
+		}
+		edges[key] = len(graph.edges)
+		graph.edges = append(graph.edges, graphEdge{from: key[0], to: key[1], cycle: cycle})
+	}
+	for _, ref := range refs {
+		if components[ref].Recursive {
+			add(ref, ref, true)
+		}
+		for _, next := range components[ref].Outbound {
+			if included[next] {
+				add(ref, next, sccOf[ref] == sccOf[next])
+			}
+		}
+	}
+	return graph
+}
+
+// Grafo no formato DOT do Graphviz
+func (g *componentGraph) dot() string {
+	quote := func(text string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(text) + `"`
+	}
+	var b strings.Builder
+	b.WriteString("digraph componentes {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, fontname=\"Helvetica\"];\n")
+	for _, n := range g.nodes {
+		if n.group > 0 {
+			fmt.Fprintf(&b, "  %s [label=%s, style=dashed];\n", quote(n.id), quote(n.name))
+			continue
+		}
+		fmt.Fprintf(&b, "  %s [label=%s];\n", quote(n.id), quote(n.kind+"\n"+n.name))
+	}
+	for _, e := range g.edges {
+		if e.cycle {
+			fmt.Fprintf(&b, "  %s -> %s [color=red, style=dashed, penwidth=2];\n", quote(e.from), quote(e.to))
+			continue
+		}
+		fmt.Fprintf(&b, "  %s -> %s;\n", quote(e.from), quote(e.to))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Grafo no formato flowchart do Mermaid; os nós ganham identificadores curtos, já que
+// o Mermaid não aceita $refs como identificador
+func (g *componentGraph) mermaid() string {
+	label := func(text string) string {
+		return `"` + strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(text) + `"`
+	}
+	ids := map[string]string{}
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i, n := range g.nodes {
+		ids[n.id] = fmt.Sprintf("n%d", i)
+		if n.group > 0 {
+			fmt.Fprintf(&b, "  %s[[%s]]\n", ids[n.id], label(n.name))
+			continue
+		}
+		fmt.Fprintf(&b, "  %s[%s]\n", ids[n.id], strings.Replace(label(n.kind+"\n"+n.name), "\n", "<br/>", 1))
+	}
+	var cycles []string
+	for i, e := range g.edges {
+		if e.cycle {
+			fmt.Fprintf(&b, "  %s -.->|ciclo| %s\n", ids[e.from], ids[e.to])
+			cycles = append(cycles, fmt.Sprint(i))
+			continue
+		}
+		fmt.Fprintf(&b, "  %s --> %s\n", ids[e.from], ids[e.to])
+	}
+	if len(cycles) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:#d00,stroke-width:2px\n", strings.Join(cycles, ","))
+	}
+	return b.String()
+}
@@ -0,0 +1,103 @@
+package validator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const graphSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas:
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Conta'}
+components:
+  schemas:
+    Conta:
+      type: object
+      properties:
+        titular: {$ref: '#/components/schemas/Pessoa'}
+        saldo: {$ref: '#/components/schemas/Valor'}
+    Pessoa:
+      type: object
+      properties:
+        endereco: {$ref: '#/components/schemas/Endereco'}
+        saldo: {$ref: '#/components/schemas/Valor'}
+    Endereco:
+      type: object
+      properties:
+        morador: {$ref: '#/components/schemas/Pessoa'}
+    Valor: {type: string}
+    Avulso: {type: string}
+`
+
+// Função para executar o subcomando graph sobre graphSpec, retornando o código e a saída
+func runGraphCommand(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"api.yaml": graphSpec})
+	return runCommand(t, append(append([]string{"graph"}, args...), filepath.Join(dir, "api.yaml"))...)
+}
+
+// Cada $ref entre componentes vira uma aresta; as dos ciclos são destacadas
+func TestGraphDot(t *testing.T) {
+	code, out := runGraphCommand(t)
+	if code != exitOK {
+		t.Fatalf("código %d\n%s", code, out)
+	}
+	for _, want := range []string{
+		`"#/components/schemas/Avulso" [label="schemas\nAvulso"];`,
+		`"#/components/schemas/Conta" -> "#/components/schemas/Valor";`,
+		`"#/components/schemas/Pessoa" -> "#/components/schemas/Endereco" [color=red, style=dashed, penwidth=2];`,
+		`"#/components/schemas/Endereco" -> "#/components/schemas/Pessoa" [color=red, style=dashed, penwidth=2];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("grafo sem %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, " -> ") != 5 {
+		t.Errorf("esperadas 5 arestas:\n%s", out)
+	}
+}
+
+// Com --component, só o componente e os que ele referencia, de forma transitiva
+func TestGraphMermaidComponent(t *testing.T) {
+	code, out := runGraphCommand(t, "--format", "mermaid", "--component", "schemas/Pessoa")
+	want := `flowchart LR
+  n0["schemas<br/>Endereco"]
+  n1["schemas<br/>Pessoa"]
+  n2["schemas<br/>Valor"]
+  n0 -.->|ciclo| n1
+  n1 -.->|ciclo| n0
+  n1 --> n2
+  linkStyle 0,1 stroke:#d00,stroke-width:2px
+`
+	if code != exitOK || out != want {
+		t.Errorf("código %d, saída:\n%s\nesperado:\n%s", code, out, want)
+	}
+}
+
+// Com --min-refs, os componentes pouco referenciados viram um único nó
+func TestGraphMinRefs(t *testing.T) {
+	code, out := runGraphCommand(t, "--min-refs", "2")
+	if code != exitOK || !strings.Contains(out, `"(agrupados)" [label="3 componente(s) com menos de 2 referência(s)", style=dashed];`) {
+		t.Errorf("código %d, saída sem o grupo:\n%s", code, out)
+	}
+	if strings.Contains(out, "Conta") {
+		t.Errorf("Conta tem uma referência e deveria estar no grupo:\n%s", out)
+	}
+}
+
+// Componente inexistente é falha, com a sugestão do mais próximo
+func TestGraphUnknownComponent(t *testing.T) {
+	code, out := runGraphCommand(t, "--component", "Contas")
+	if code != exitFailure || !strings.Contains(out, `componente "Contas" não encontrado`) || !strings.Contains(out, "Conta") {
+		t.Errorf("código %d, saída:\n%s", code, out)
+	}
+}
@@ -0,0 +1,186 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Condição de aplicação de uma regra (when): um JSONPath e, opcionalmente, o valor
+// (value) ou a expressão regular (pattern) esperados. Sem value nem pattern, basta o
+// caminho existir e não ser vazio. O caminho começa em $ (o documento) ou em @ (cada
+// nó selecionado pelo given); cada ^ depois do @ sobe um nível, para os irmãos e
+// ancestrais do nó (ex.: "@^^.tags" a partir de um item de parameters da operação).
+//
+//	when:
+//	  path: "@.tags"
+//	  value: payments
+type ruleGuard struct {
+	relative bool
+	up       int
+	query    string // JSONPath relativo ao nó de partida
+	value    *string
+	pattern  *regexp.Regexp
+}
+
+// Função para ler o when de uma regra; nulo quando a regra não tem condição
+func parseRuleGuard(raw interface{}) (*ruleGuard, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	when, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("when deve ser um objeto com path e, opcionalmente, value ou pattern, encontrado %s", ruleValueType(raw))
+	}
+	var unknown []string
+	for key := range when {
+		if key != "path" && key != "value" && key != "pattern" {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("when.%s desconhecido (use path, value e pattern)", strings.Join(unknown, ", when."))
+	}
+	path, ok := when["path"].(string)
+	if !ok || strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("when.path deve ser uma expressão JSONPath em texto, começando com $ ou @")
+	}
+	guard := &ruleGuard{}
+	path = strings.TrimSpace(path)
+	switch {
+	case strings.HasPrefix(path, "$"):
+		guard.query = path
+	case strings.HasPrefix(path, "@"):
+		rest := path[1:]
+		for strings.HasPrefix(rest, "^") {
+			guard.up++
+			rest = rest[1:]
+		}
+		guard.relative, guard.query = true, "$"+rest
+	default:
+		return nil, fmt.Errorf("when.path %q deve começar com $ (documento) ou @ (nó selecionado)", path)
+	}
+	if _, _, err := parseJSONPath(guard.query); err != nil {
+		return nil, fmt.Errorf("when.path %q inválido: %v", path, err)
+	}
+
+	value, hasValue := when["value"]
+	pattern, hasPattern := when["pattern"]
+	if hasValue && hasPattern {
+		return nil, fmt.Errorf("when aceita value ou pattern, não os dois")
+	}
+	if hasValue {
+		switch value.(type) {
+		case string, bool, int, float64:
+			text := fmt.Sprint(value)
+			guard.value = &text
+		default:
+			return nil, fmt.Errorf("when.value deve ser um valor escalar, encontrado %s", ruleValueType(value))
+		}
+	}
+	if hasPattern {
+		expr, ok := pattern.(string)
+		if !ok {
+			return nil, fmt.Errorf("when.pattern deve ser uma expressão regular em texto, encontrado %s", ruleValueType(pattern))
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("when.pattern %q não é uma expressão regular válida: %v", expr, err)
+		}
+		guard.pattern = re
+	}
+	return guard, nil
+}
+
+// Documento em avaliação, com os pais de cada nó montados na primeira condição
+// relativa que sobe de nível
+type guardScope struct {
+	root    *yaml.Node
+	once    sync.Once
+	parents map[*yaml.Node]*yaml.Node
+	changed *changedScope // regiões alteradas (--changed-only); nulo avalia tudo
+}
+
+func newGuardScope(root *yaml.Node) *guardScope {
+	return &guardScope{root: root}
+}
+
+func (s *guardScope) parent(node *yaml.Node) *yaml.Node {
+	s.once.Do(func() {
+		s.parents = map[*yaml.Node]*yaml.Node{}
+		var walk func(*yaml.Node)
+		walk = func(n *yaml.Node) {
+			for _, child := range n.Content {
+				if _, seen := s.parents[child]; seen {
+					continue
+				}
+				s.parents[child] = n
+				walk(child)
+			}
+		}
+		walk(documentContent(s.root))
+	})
+	return s.parents[node]
+}
+
+// Indica se a condição vale para o nó selecionado (nas condições relativas) ou para o
+// documento (nas que começam em $)
+func (g *ruleGuard) holds(scope *guardScope, node *yaml.Node) bool {
+	start := scope.root
+	if g.relative {
+		start = node
+		for i := 0; i < g.up && start != nil; i++ {
+			start = scope.parent(start)
+		}
+		if start == nil {
+			return false
+		}
+	}
+	matches, err := queryJSONPath(start, g.query)
+	if err != nil {
+		return false
+	}
+	for _, m := range matches {
+		if g.accepts(m.Node) {
+			return true
+		}
+	}
+	return false
+}
+
+// O valor encontrado atende à condição; em listas, basta um item (ex.: tags)
+func (g *ruleGuard) accepts(node *yaml.Node) bool {
+	if g.value == nil && g.pattern == nil {
+		return isTruthy(node)
+	}
+	if node == nil {
+		return false
+	}
+	if node.Kind == yaml.SequenceNode {
+		for _, item := range node.Content {
+			if g.accepts(item) {
+				return true
+			}
+		}
+		return false
+	}
+	if node.Kind != yaml.ScalarNode {
+		return false
+	}
+	if g.value != nil {
+		return node.Value == *g.value
+	}
+	return g.pattern.MatchString(node.Value)
+}
+
+// Imprime as regras que não se aplicam ao documento pelo when ou pelo scope
+func printNotApplicable(names []string) {
+	if len(names) > 0 {
+		fmt.Fprintf(stdout, "ℹ️  %d regras não aplicáveis (when ou scope): %s\n", len(names), strings.Join(names, ", "))
+	}
+}
@@ -0,0 +1,91 @@
+package validator
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// Violações da regra ruleID ao validar spec com as regras informadas (formato próprio)
+func ruleViolations(t *testing.T, spec, rules, ruleID string) []Violation {
+	t.Helper()
+	result, err := Validate(context.Background(), []byte(spec), Options{Source: "api.yaml", Rules: []byte(rules)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found []Violation
+	for _, v := range result.Violations {
+		if v.RuleID == ruleID {
+			found = append(found, v)
+		}
+	}
+	return found
+}
+
+const guardSpec = `openapi: 3.0.3
+info: {title: Pagamentos, version: 1.0.0, x-categoria: pagamentos}
+paths:
+  /pix:
+    post:
+      tags: [pagamentos]
+      parameters:
+        - {name: x-idempotency-key, in: header, schema: {type: string}}
+      responses:
+        '201': {description: criado}
+  /contas:
+    get:
+      tags: [contas]
+      parameters:
+        - {name: page, in: query, schema: {type: integer}}
+      responses:
+        '200': {description: ok}
+`
+
+// when com $ vale para o documento inteiro: a regra só é avaliada quando o valor confere
+func TestRuleGuardOnDocument(t *testing.T) {
+	rules := func(value string) string {
+		return `rules:
+  operacao-com-descricao:
+    given: $.paths[*][*]
+    when: {path: $.info.x-categoria, value: ` + value + `}
+    then: {field: description, function: truthy}
+`
+	}
+	if found := ruleViolations(t, guardSpec, rules("pagamentos"), "operacao-com-descricao"); len(found) != 2 {
+		t.Errorf("com a condição atendida, esperadas 2 violações, encontradas %d: %v", len(found), found)
+	}
+	if found := ruleViolations(t, guardSpec, rules("contas"), "operacao-com-descricao"); len(found) != 0 {
+		t.Errorf("com a condição não atendida, a regra não deveria ser avaliada: %v", found)
+	}
+}
+
+// when com @ parte de cada nó selecionado; cada ^ sobe um nível (do parâmetro até a
+// operação) e, em listas, basta um item conferir
+func TestRuleGuardRelativeToNode(t *testing.T) {
+	rules := `rules:
+  parametro-obrigatorio:
+    given: $.paths[*][*].parameters[*]
+    when: {path: "@^^.tags", pattern: "^pag"}
+    then: {field: required, function: truthy}
+`
+	found := ruleViolations(t, guardSpec, rules, "parametro-obrigatorio")
+	if len(found) != 1 || !strings.HasPrefix(found[0].JSONPath, "$.paths['/pix'].post.parameters[0]") {
+		t.Errorf("esperada uma violação no parâmetro de POST /pix, encontradas: %v", found)
+	}
+}
+
+// Condições malformadas são rejeitadas com a mensagem do campo
+func TestRuleGuardErrors(t *testing.T) {
+	for raw, message := range map[string]string{
+		"{path: $.info, value: a, pattern: b}": "when aceita value ou pattern, não os dois",
+		"{path: info}":                         `when.path "info" deve começar com $ (documento) ou @ (nó selecionado)`,
+		"{path: $.info, valor: a}":             "when.valor desconhecido",
+		"{path: $.info, pattern: '('}":         "não é uma expressão regular válida",
+	} {
+		rules := "rules:\n  r:\n    given: $.info\n    when: " + raw + "\n    then: {field: title, function: truthy}\n"
+		_, err := Validate(context.Background(), []byte(guardSpec), Options{Source: "api.yaml", Rules: []byte(rules)})
+		if err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("when %s: erro %v, esperado %q", raw, err, message)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Cabeçalho exigido nas respostas (functionOptions.headers da função headers)
+type headerRequirement struct {
+	name     string
+	statuses []string   // códigos (200), classes (2xx) ou default; vazio vale para todos
+	paths    *pathScope // paths em que o cabeçalho é exigido; nulo vale para todos
+	schema   map[string]interface{}
+}
+
+// Função para ler o catálogo de cabeçalhos da função headers: cada item tem o name do
+// cabeçalho e, opcionalmente, os status em que ele é exigido, o escopo de paths e as
+// chaves que o schema do cabeçalho deve ter
+//
+//	then:
+//	  function: headers
+//	  functionOptions:
+//	    headers:
+//	      - name: x-fapi-interaction-id
+//	        status: [2xx, 4xx, 5xx]
+//	        schema: {type: string}
+//	      - name: Cache-Control
+//	        status: 2xx
+//	        scope: "/opendata-*/**"
+func parseHeaderCatalogue(options map[string]interface{}) ([]headerRequirement, []string) {
+	items, ok := options["headers"].([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, []string{"a função headers exige functionOptions.headers com a lista de cabeçalhos (ex.: - name: x-fapi-interaction-id)"}
+	}
+	var requirements []headerRequirement
+	var problems []string
+	for i, item := range items {
+		at := fmt.Sprintf("functionOptions.headers[%d]", i)
+		entry, isMap := item.(map[string]interface{})
+		if !isMap {
+			problems = append(problems, fmt.Sprintf("%s deve ser um objeto com name, encontrado %s", at, ruleValueType(item)))
+			continue
+		}
+		var req headerRequirement
+		req.name, _ = entry["name"].(string)
+		if strings.TrimSpace(req.name) == "" {
+			problems = append(problems, fmt.Sprintf("%s.name ausente (nome do cabeçalho, ex.: x-fapi-interaction-id)", at))
+			continue
+		}
+		switch status := entry["status"].(type) {
+		case nil:
+		case string:
+			req.statuses = []string{status}
+		case []interface{}:
+			for _, s := range status {
+				text, isString := s.(string)
+				if !isString {
+					text = fmt.Sprint(s)
+				}
+				req.statuses = append(req.statuses, text)
+			}
+		default:
+			req.statuses = []string{fmt.Sprint(status)}
+		}
+		for _, status := range req.statuses {
+			if !validStatusSelector(status) {
+				problems = append(problems, fmt.Sprintf("%s.status %q inválido (use um código como 200, uma classe como 2xx ou default)", at, status))
+			}
+		}
+		scope, err := parseRuleScope(entry["scope"])
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", at, err))
+		}
+		req.paths = scope
+		if raw, ok := entry["schema"]; ok {
+			if req.schema, ok = raw.(map[string]interface{}); !ok {
+				problems = append(problems, fmt.Sprintf("%s.schema deve ser um objeto com as chaves esperadas no schema do cabeçalho, encontrado %s", at, ruleValueType(raw)))
+			}
+		}
+		requirements = append(requirements, req)
+	}
+	return requirements, problems
+}
+
+// Um código de status de três dígitos, uma classe (2xx) ou default
+func validStatusSelector(status string) bool {
+	if status == "default" {
+		return true
+	}
+	if len(status) != 3 || status[0] < '1' || status[0] > '5' {
+		return false
+	}
+	rest := strings.ToLower(status[1:])
+	if rest == "xx" {
+		return true
+	}
+	return rest[0] >= '0' && rest[0] <= '9' && rest[1] >= '0' && rest[1] <= '9'
+}
+
+// Indica se o cabeçalho é exigido na resposta com o status (chave de responses)
+func (h headerRequirement) appliesTo(status string) bool {
+	if len(h.statuses) == 0 {
+		return true
+	}
+	status = strings.ToLower(status)
+	for _, s := range h.statuses {
+		s = strings.ToLower(s)
+		if s == status || strings.HasSuffix(s, "xx") && len(status) == 3 && status[0] == s[0] {
+			return true
+		}
+	}
+	return false
+}
+
+// Função para conferir os cabeçalhos das respostas da operação selecionada (given
+// $.paths[*][*]): cada resposta documentada precisa ter os cabeçalhos do catálogo que
+// valem para o status e o path, com nome em qualquer caixa, e o schema deles precisa
+// ter as chaves esperadas. $refs locais para responses, headers e schemas são seguidos;
+// respostas em outros arquivos não são conferidas.
+func (r *compiledRule) headerViolations(m pathMatch) []Violation {
+	if m.Key == nil || !httpMethods[m.Key.Value] {
+		return nil
+	}
+	label, ok := operationLabel(m.Path)
+	if !ok {
+		return nil
+	}
+	doc := documentContent(r.root)
+	deref := func(node *yaml.Node) *yaml.Node { return localRefTarget(doc, node) }
+	description := strings.TrimSuffix(r.description, ".")
+	responses := mappingValue(m.Node, "responses")
+	responsesPath := joinPath(m.Path, "responses")
+
+	var violations []Violation
+	forEachEntry(responses, func(status string, node *yaml.Node) {
+		response := deref(node)
+		if response == nil {
+			return
+		}
+		headers := mappingValue(response, "headers")
+		statusPath := joinPath(responsesPath, status)
+		for _, req := range r.headers {
+			if !req.appliesTo(status) {
+				continue
+			}
+			if req.paths != nil {
+				if _, in := req.paths.match(m.Path); !in {
+					continue
+				}
+			}
+			name, header := headerEntry(headers, req.name)
+			if header == nil {
+				v := r.violation(statusPath, responseKeyLine(responses, status), nil)
+				v.Message = fmt.Sprintf("%s: a resposta %s de %s não documenta o cabeçalho %s.", description, status, label, req.name)
+				violations = append(violations, v)
+				continue
+			}
+			header = deref(header)
+			schema := mappingValue(header, "schema")
+			if schema == nil {
+				// Swagger 2.0: o tipo fica no próprio cabeçalho
+				schema = header
+			}
+			schema = deref(schema)
+			if problem := headerSchemaProblem(schema, req.schema); problem != "" {
+				headerPath := joinPath(joinPath(statusPath, "headers"), name.Value)
+				v := r.violation(headerPath, name.Line, name)
+				v.Message = fmt.Sprintf("%s: o cabeçalho %s da resposta %s de %s %s.", description, name.Value, status, label, problem)
+				violations = append(violations, v)
+			}
+		}
+	})
+	return violations
+}
+
+// Chave e valor do cabeçalho com o nome, sem diferenciar maiúsculas (cabeçalhos HTTP
+// não diferenciam)
+func headerEntry(headers *yaml.Node, name string) (*yaml.Node, *yaml.Node) {
+	if headers == nil || headers.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(headers.Content); i += 2 {
+		if strings.EqualFold(headers.Content[i].Value, name) {
+			return headers.Content[i], headers.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// Linha da chave do status em responses
+func responseKeyLine(responses *yaml.Node, status string) int {
+	for i := 0; i+1 < len(responses.Content); i += 2 {
+		if responses.Content[i].Value == status {
+			return responses.Content[i].Line
+		}
+	}
+	return responses.Line
+}
+
+// O que falta no schema do cabeçalho em relação às chaves esperadas; vazio quando o
+// schema tem todas com o mesmo valor (ou não dá para conferir, $ref para outro arquivo)
+func headerSchemaProblem(schema *yaml.Node, expected map[string]interface{}) string {
+	if len(expected) == 0 || schema == nil {
+		return ""
+	}
+	canonical := newSchemaCanonicalizer(false)
+	keys := make([]string, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var problems []string
+	for _, key := range keys {
+		var want yaml.Node
+		if err := want.Encode(expected[key]); err != nil {
+			continue
+		}
+		got := mappingValue(schema, key)
+		switch {
+		case got == nil:
+			problems = append(problems, fmt.Sprintf("%s ausente (esperado %s)", key, canonical.form(&want, false)))
+		case canonical.form(got, false) != canonical.form(&want, false):
+			problems = append(problems, fmt.Sprintf("%s %s (esperado %s)", key, canonical.form(got, false), canonical.form(&want, false)))
+		}
+	}
+	if len(problems) == 0 {
+		return ""
+	}
+	return "tem schema diferente do catálogo: " + strings.Join(problems, ", ")
+}
@@ -0,0 +1,93 @@
+package validator
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const headersSpec = `openapi: 3.0.3
+info: {title: Open Finance, version: 1.0.0}
+paths:
+  /accounts/v2/accounts:
+    get:
+      responses:
+        '200':
+          description: ok
+          headers:
+            X-FAPI-Interaction-Id: {schema: {type: integer}}
+        '404': {$ref: '#/components/responses/NaoEncontrado'}
+  /opendata-loans/v1/loans:
+    get:
+      responses:
+        '200':
+          description: ok
+          headers:
+            x-fapi-interaction-id: {$ref: '#/components/headers/Interacao'}
+components:
+  headers:
+    Interacao: {schema: {type: string, maxLength: 100}}
+  responses:
+    NaoEncontrado:
+      description: não encontrado
+`
+
+const headersRules = `rules:
+  cabecalhos:
+    given: $.paths[*][*]
+    then:
+      function: headers
+      functionOptions:
+        headers:
+          - name: x-fapi-interaction-id
+            status: [2xx, 4xx]
+            schema: {type: string}
+          - name: Cache-Control
+            status: 200
+            scope: "/opendata-*/**"
+`
+
+// Cada resposta precisa dos cabeçalhos do catálogo para o status e o path, com o
+// nome em qualquer caixa e o schema esperado; $refs locais são seguidos
+func TestHeaderCatalogue(t *testing.T) {
+	found := ruleViolations(t, headersSpec, headersRules, "cabecalhos")
+	want := []string{
+		"o cabeçalho X-FAPI-Interaction-Id da resposta 200 de GET /accounts/v2/accounts tem schema diferente do catálogo: type \"integer\" (esperado \"string\")",
+		"a resposta 404 de GET /accounts/v2/accounts não documenta o cabeçalho x-fapi-interaction-id",
+		"a resposta 200 de GET /opendata-loans/v1/loans não documenta o cabeçalho Cache-Control",
+	}
+	if len(found) != len(want) {
+		t.Fatalf("esperadas %d violações, encontradas %d: %v", len(want), len(found), found)
+	}
+	for i, v := range found {
+		if !strings.Contains(v.Message, want[i]) {
+			t.Errorf("violação %d: %q, esperado %q", i, v.Message, want[i])
+		}
+	}
+	if found[0].Line != 10 || found[1].Line != 11 {
+		t.Errorf("linhas %d e %d, esperadas 10 (cabeçalho) e 11 (status)", found[0].Line, found[1].Line)
+	}
+}
+
+// Catálogos malformados são erro de uso
+func TestHeaderCatalogueErrors(t *testing.T) {
+	tests := []struct {
+		options, message string
+	}{
+		{"{}", "a função headers exige functionOptions.headers"},
+		{"{headers: [x-fapi]}", "functionOptions.headers[0] deve ser um objeto com name, encontrado texto"},
+		{"{headers: [{status: 200}]}", "functionOptions.headers[0].name ausente"},
+		{"{headers: [{name: a, status: 2x}]}", `functionOptions.headers[0].status "2x" inválido`},
+		{"{headers: [{name: a, schema: string}]}", "functionOptions.headers[0].schema deve ser um objeto"},
+		{"{headers: [{name: a, scope: opendata}]}", `functionOptions.headers[0]: scope "opendata" deve começar com /`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.options, func(t *testing.T) {
+			rules := "rules:\n  a:\n    given: $.paths[*][*]\n    then: {function: headers, functionOptions: " + tt.options + "}\n"
+			_, err := Validate(context.Background(), []byte(headersSpec), Options{Source: "api.yaml", Rules: []byte(rules)})
+			if err == nil || rulesExitCode(err) != exitUsage || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("erro %v, esperado erro de uso com %q", err, tt.message)
+			}
+		})
+	}
+}
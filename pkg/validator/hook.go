@@ -0,0 +1,275 @@
+package validator
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Tempo padrão do hook: o pre-commit deve responder em poucos segundos
+const defaultHookTimeout = 30 * time.Second
+
+// Marcador do script instalado por 'hook install', para reconhecer (e atualizar)
+// o próprio hook sem tocar em hooks de outras ferramentas
+const hookScriptMarker = "# ofb-openapi-validator"
+
+var hookCommand = &command{
+	Name:    "hook",
+	Args:    "[install] [arquivos...]",
+	Summary: "valida as especificações alteradas no commit (pre-commit)",
+	Description: `Valida apenas as especificações em stage (git diff --cached), para o hook de
+pre-commit: sem resolução de referências nem comparação, com uma linha por
+violação. Arquivos informados como argumentos (ex.: pelo framework pre-commit)
+substituem a detecção pelo git.
+
+Os arquivos considerados são os YAML/JSON com a chave openapi ou swagger na raiz,
+fora dos padrões de .openapiignore e, quando "specPaths" existe em
+.openapi-ci.yaml, dentro de um dos padrões listados (estilo .gitignore, ex.:
+"apis/**/*.yaml" ou "specs/"). As regras vêm de --rules, da configuração
+ou do pacote ofb. É validado o conteúdo do diretório de trabalho.
+
+'hook install' grava o script de pre-commit no diretório de hooks do
+repositório; um hook existente de outra ferramenta não é sobrescrito. Com
+--pre-commit-config, imprime o trecho para o .pre-commit-config.yaml.`,
+	Examples: []string{
+		programName + " hook",
+		programName + " hook install",
+		programName + " hook install --pre-commit-config",
+	},
+	Flags: func(fs *flag.FlagSet) { registerHookFlags(fs, new(string), new(time.Duration)) },
+	Run:   runHook,
+}
+
+func registerHookFlags(fs *flag.FlagSet, rules *string, timeout *time.Duration) {
+	fs.StringVar(rules, "rules", "", "arquivo de regras ou nome de pacote embarcado (padrão: o da configuração ou ofb)")
+	fs.DurationVar(timeout, "timeout", defaultHookTimeout, "tempo máximo da execução (0 desativa)")
+	registerCacheFlags(fs)
+	registerStrictYAMLFlag(fs)
+}
+
+func runHook(c *command, args []string) int {
+	if len(args) > 0 && args[0] == "install" {
+		return runHookInstall(c, args[1:])
+	}
+	var rulesFile string
+	var timeout time.Duration
+	fs := c.newFlagSet()
+	registerHookFlags(fs, &rulesFile, &timeout)
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+
+	config, err := loadProjectConfig(projectConfigFile)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao ler a configuração:", err)
+		return exitFailure
+	}
+	if rulesFile == "" {
+		rulesFile = "ofb"
+		if config != nil && config.Rules != "" {
+			rulesFile = config.Rules
+		}
+	}
+
+	candidates := fs.Args()
+	if len(candidates) == 0 {
+		if candidates, err = stagedFiles(); err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+	}
+	var specPaths []string
+	if config != nil {
+		specPaths = config.SpecPaths
+	}
+	specs, err := hookSpecs(candidates, specPaths)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌", err)
+		return exitFailure
+	}
+	if len(specs) == 0 {
+		return exitOK
+	}
+
+	ctx, cancel := newRunContext(timeout)
+	defer cancel()
+	rules, err := loadRuleset(flagSettings(), rulesFile)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao carregar as regras:", err)
+		return rulesExitCode(err)
+	}
+
+	errors, warnings := 0, 0
+	for _, spec := range specs {
+		violations, err := validateOpenAPI(ctx, flagSettings(), spec, rules)
+		if err != nil {
+			if isCancellation(err) {
+				reportCancellation(err, timeout)
+				return exitFailure
+			}
+			fmt.Fprintf(stdout, "%s: %s\n", spec, firstLine(err.Error()))
+			errors++
+			continue
+		}
+		for _, v := range violations {
+			fmt.Fprintln(stdout, hookLine(spec, v))
+		}
+		e, w := countViolations(violations)
+		errors += e
+		warnings += w
+	}
+	if errors > 0 {
+		fmt.Fprintf(stdout, "❌ %d erros, %d avisos em %d especificações; corrija antes do commit (ou use git commit --no-verify).\n", errors, warnings, len(specs))
+		return exitFailure
+	}
+	if warnings > 0 {
+		fmt.Fprintf(stdout, "⚠️  %d avisos em %d especificações.\n", warnings, len(specs))
+	}
+	return exitOK
+}
+
+// Linha de uma violação no hook, sempre com o arquivo: arquivo:linha:coluna quando
+// há posição, ou o arquivo seguido da mensagem e do JSONPath
+func hookLine(spec string, v Violation) string {
+	line := firstLine(v.Error())
+	if v.File != "" {
+		return line
+	}
+	prefix := "[" + v.Severity + "] "
+	return prefix + reportPath(spec) + ": " + strings.TrimPrefix(line, prefix)
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// Arquivos em stage, adicionados ou modificados, relativos ao diretório atual
+func stagedFiles() ([]string, error) {
+	out, err := exec.Command("git", "diff", "--cached", "--name-only", "--diff-filter=ACMR", "--relative", "-z").Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("erro ao listar os arquivos em stage: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("erro ao listar os arquivos em stage: %v", err)
+	}
+	var files []string
+	for _, name := range bytes.Split(out, []byte{0}) {
+		if len(name) > 0 {
+			files = append(files, filepath.FromSlash(string(name)))
+		}
+	}
+	return files, nil
+}
+
+// Filtra os candidatos: extensão YAML/JSON, fora de .openapiignore, dentro de
+// specPaths (quando configurado) e com a chave openapi/swagger na raiz
+func hookSpecs(candidates, specPaths []string) ([]string, error) {
+	ignore, err := newIgnoreMatcher(".", nil)
+	if err != nil {
+		return nil, err
+	}
+	include := &ignoreMatcher{}
+	include.add(specPaths...)
+
+	var specs []string
+	for _, file := range candidates {
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		rel := filepath.ToSlash(filepath.Clean(file))
+		if matchesPath(ignore, rel) {
+			continue
+		}
+		if len(specPaths) > 0 && !matchesPath(include, rel) {
+			continue
+		}
+		if looksLikeOpenAPI(file) {
+			specs = append(specs, file)
+		}
+	}
+	return specs, nil
+}
+
+// Indica se o arquivo ou um dos diretórios acima dele casa com os padrões
+func matchesPath(m *ignoreMatcher, rel string) bool {
+	for dir := path.Dir(rel); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if m.ignored(dir, true) {
+			return true
+		}
+	}
+	return m.ignored(rel, false)
+}
+
+const preCommitConfigSnippet = `# Trecho para o .pre-commit-config.yaml
+repos:
+  - repo: local
+    hooks:
+      - id: ofb-openapi-validator
+        name: validação OpenAPI
+        entry: %s hook
+        language: system
+        files: \.(ya?ml|json)$
+`
+
+// 'hook install': grava .git/hooks/pre-commit ou imprime o trecho do pre-commit
+func runHookInstall(c *command, args []string) int {
+	fs := c.newFlagSet()
+	snippet := fs.Bool("pre-commit-config", false, "imprime o trecho do .pre-commit-config.yaml em vez de gravar o hook")
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 0 {
+		return c.usageError("argumentos inesperados: %s", strings.Join(fs.Args(), " "))
+	}
+
+	executable := programName
+	if exe, err := os.Executable(); err == nil {
+		executable = exe
+	}
+	if *snippet {
+		fmt.Fprintf(stdout, preCommitConfigSnippet, shellQuote(executable))
+		return exitOK
+	}
+
+	out, err := exec.Command("git", "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		fmt.Fprintln(stdout, "❌ Não foi possível localizar o repositório git:", err)
+		return exitFailure
+	}
+	hookFile := filepath.Join(strings.TrimSpace(string(out)), "pre-commit")
+	if existing, err := os.ReadFile(hookFile); err == nil && !bytes.Contains(existing, []byte(hookScriptMarker)) {
+		fmt.Fprintf(stdout, "❌ %s já existe e não foi criado pelo validador; para não sobrescrevê-lo, adicione esta linha ao hook:\n   %s hook || exit 1\n", hookFile, shellQuote(executable))
+		return exitFailure
+	}
+
+	script := fmt.Sprintf("#!/bin/sh\n%s: gerado por '%s hook install'\nexec %s hook\n", hookScriptMarker, programName, shellQuote(executable))
+	if err := os.MkdirAll(filepath.Dir(hookFile), 0755); err != nil {
+		fmt.Fprintln(stdout, "❌", err)
+		return exitFailure
+	}
+	if err := os.WriteFile(hookFile, []byte(script), 0755); err != nil {
+		fmt.Fprintf(stdout, "❌ Erro ao salvar %s: %v\n", hookFile, err)
+		return exitFailure
+	}
+	fmt.Fprintln(stdout, "✅ Hook de pre-commit instalado:", hookFile)
+	return exitOK
+}
+
+// Caminho entre aspas simples para o shell
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r == '/' || r == '.' || r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,196 @@
+package validator
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Variável de ambiente com o token enviado nas buscas HTTPS (ex.: raw.githubusercontent.com
+// de repositórios privados), quando --http-header não define Authorization
+const httpTokenEnv = "OFBCI_HTTP_TOKEN"
+
+// Opções das buscas HTTP de $refs remotos e de conjuntos de regras
+var (
+	httpHeaders stringList
+	httpRetries = 2
+	offline     bool
+)
+
+func registerHTTPFlags(fs *flag.FlagSet) {
+	fs.Var(&httpHeaders, "http-header", "cabeçalho enviado nas buscas HTTP, no formato 'Nome: valor'; pode ser repetida")
+	fs.IntVar(&httpRetries, "http-retries", httpRetries, "novas tentativas de uma busca HTTP após falha de rede, 429 ou 5xx (com espera crescente)")
+	fs.BoolVar(&offline, "offline", false, "não acessa a rede: usa apenas o cache e falha se alguma busca HTTP for necessária")
+}
+
+// Confere o formato de --http-header
+func checkHTTPFlags() error {
+	for _, h := range httpHeaders {
+		if i := strings.Index(h, ":"); i < 0 || strings.TrimSpace(h[:i]) == "" {
+			return fmt.Errorf("valor inválido para --http-header: %q (use 'Nome: valor')", h)
+		}
+	}
+	if httpRetries < 0 {
+		return fmt.Errorf("valor inválido para --http-retries: %d", httpRetries)
+	}
+	return nil
+}
+
+func isRemoteURL(path string) bool {
+	return strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://")
+}
+
+// Entrada do cache HTTP: o conteúdo e os validadores para a requisição condicional
+type httpCacheEntry struct {
+	URL          string `yaml:"url"`
+	ETag         string `yaml:"etag,omitempty"`
+	LastModified string `yaml:"lastModified,omitempty"`
+	Body         string `yaml:"body"`
+}
+
+// Função para buscar uma URL com cache em disco: a cópia guardada é revalidada com
+// If-None-Match/If-Modified-Since e reaproveitada na resposta 304. Com --offline, só
+// a cópia guardada é usada.
+func (s *runSettings) fetchRemote(rawURL string) ([]byte, error) {
+	cache := s.activeCache()
+	key := contentHash([]byte(rawURL))
+	var cached httpCacheEntry
+	hasCached := cache.getYAML("http", key, &cached) && cached.URL == rawURL
+
+	if s.offline {
+		if hasCached {
+			return []byte(cached.Body), nil
+		}
+		return nil, fmt.Errorf("%s não está no cache e --offline impede a busca na rede", rawURL)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("URL inválida %s: %v", rawURL, err)
+	}
+	for _, h := range s.httpHeaders {
+		i := strings.Index(h, ":")
+		req.Header.Set(strings.TrimSpace(h[:i]), strings.TrimSpace(expandHeaderEnv(h[i+1:])))
+	}
+	if token := os.Getenv(httpTokenEnv); token != "" && req.Header.Get("Authorization") == "" && req.URL.Scheme == "https" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if hasCached {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	client := &http.Client{Timeout: s.remoteTimeout}
+	backoff := 500 * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Do(req)
+		var body []byte
+		if err == nil {
+			body, err = s.readLimited(resp.Body, rawURL)
+			resp.Body.Close()
+		}
+		retryable := err != nil
+		if err == nil {
+			switch {
+			case resp.StatusCode == http.StatusNotModified && hasCached:
+				return []byte(cached.Body), nil
+			case resp.StatusCode == http.StatusOK:
+				cache.putYAML("http", key, httpCacheEntry{URL: rawURL, ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified"), Body: string(body)})
+				return body, nil
+			case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+				retryable = true
+				if wait, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && time.Duration(wait)*time.Second > backoff {
+					backoff = time.Duration(wait) * time.Second
+				}
+			}
+			err = fmt.Errorf("resposta HTTP %s", resp.Status)
+		}
+		if !retryable || attempt >= s.httpRetries {
+			return nil, fmt.Errorf("erro ao buscar %s: %v", rawURL, err)
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// Valores de --http-header podem citar variáveis de ambiente (ex.: 'Authorization: token ${GH_TOKEN}'),
+// para que o segredo não apareça na linha de comando
+func expandHeaderEnv(value string) string {
+	return envVarPattern.ReplaceAllStringFunc(value, func(match string) string {
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+		parts := envVarPattern.FindStringSubmatch(match)
+		if v, ok := os.LookupEnv(parts[1]); ok && v != "" {
+			return v
+		}
+		return parts[3]
+	})
+}
+
+// Handler de URLs remotas para o rolodex, com cache, autenticação e novas tentativas
+func (s *runSettings) remoteURLHandler(rawURL string) (*http.Response, error) {
+	s.progress.loaded(rawURL)
+	body, err := s.fetchRemote(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}, nil
+}
+
+// Função para ler um arquivo local ou uma URL (conjuntos de regras e extends remotos)
+func (s *runSettings) readSource(path string) ([]byte, error) {
+	if !isRemoteURL(path) {
+		return s.readFile(path)
+	}
+	data, err := s.fetchRemote(path)
+	if err != nil {
+		return nil, err
+	}
+	return s.convertToUTF8(data, path)
+}
+
+// Diretório de um arquivo local ou de uma URL, base para os caminhos relativos de extends
+func sourceDir(path string) string {
+	if isRemoteURL(path) {
+		return path[:strings.LastIndex(path, "/")+1]
+	}
+	return filepath.Dir(path)
+}
+
+// Caminho de target relativo a baseDir (diretório local ou URL)
+func joinSource(baseDir, target string) string {
+	if isRemoteURL(target) || (!isRemoteURL(baseDir) && filepath.IsAbs(target)) {
+		return target
+	}
+	if isRemoteURL(baseDir) {
+		base, err := url.Parse(baseDir)
+		if err != nil {
+			return target
+		}
+		ref, err := url.Parse(filepath.ToSlash(target))
+		if err != nil {
+			return target
+		}
+		return base.ResolveReference(ref).String()
+	}
+	return filepath.Join(baseDir, target)
+}
@@ -0,0 +1,72 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Com --strict-yaml, problemas de higiene do YAML são erros em vez de avisos
+var strictYAML bool
+
+func registerStrictYAMLFlag(fs *flag.FlagSet) {
+	fs.BoolVar(&strictYAML, "strict-yaml", false, "trata chaves duplicadas, tabulações na indentação e chaves de mesclagem (<<) como erro")
+}
+
+// Função para conferir a higiene do arquivo de origem: chaves duplicadas (o yaml.v3
+// mantém só o último valor), tabulações na indentação e chaves de mesclagem (<<), que
+// não existem em JSON nem no OpenAPI. A árvore é lida de novo, sem overlays nem
+// conversão, para que as linhas sejam as do arquivo.
+func yamlHygieneViolations(data []byte, strict bool) []Violation {
+	severity := "warning"
+	if strict {
+		severity = "error"
+	}
+	var violations []Violation
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil
+	}
+	var walk func(node *yaml.Node, path string)
+	walk = func(node *yaml.Node, path string) {
+		switch node.Kind {
+		case yaml.DocumentNode:
+			for _, child := range node.Content {
+				walk(child, path)
+			}
+		case yaml.MappingNode:
+			firstLine := map[string]int{}
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := node.Content[i]
+				if key.Value == "<<" && key.Tag == "!!merge" {
+					violations = append(violations, Violation{RuleID: "yaml-merge-key", Severity: severity, Message: "chave de mesclagem (<<) não é suportada em JSON nem pelo OpenAPI; copie os campos", JSONPath: path, Line: key.Line})
+				} else if line, ok := firstLine[key.Value]; ok {
+					violations = append(violations, Violation{RuleID: "yaml-duplicate-key", Severity: severity, Message: fmt.Sprintf("chave duplicada %q nas linhas %d e %d; apenas um dos valores é considerado", key.Value, line, key.Line), JSONPath: path, Line: key.Line})
+				} else {
+					firstLine[key.Value] = key.Line
+				}
+				walk(node.Content[i+1], joinPath(path, key.Value))
+			}
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				walk(item, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	}
+	walk(&root, "$")
+
+	// Tabulações na indentação: fora dos blocos de texto o parser já as rejeita, mas
+	// dentro deles alteram o conteúdo sem aviso. Em JSON, tabulações são válidas.
+	if !isJSONContent(data) {
+		for i, line := range sourceLines(data) {
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			if strings.Contains(indent, "\t") {
+				violations = append(violations, Violation{RuleID: "yaml-tab-indent", Severity: severity, Message: "tabulação usada na indentação; use espaços", JSONPath: "$", Line: i + 1})
+			}
+		}
+	}
+	return violations
+}
@@ -0,0 +1,257 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Opções da função identifiers
+type identifierRequirement struct {
+	exceptions []scopePattern // paths legados que não são conferidos
+	envelope   []string       // propriedades que embrulham o recurso na resposta (ex.: data)
+}
+
+// Função para ler as opções da função identifiers: os paths que ficam de fora (padrões
+// do scope, para endpoints legados) e as propriedades da resposta que embrulham o
+// recurso (padrão: data, o envelope do Open Finance)
+//
+//	then:
+//	  function: identifiers
+//	  functionOptions:
+//	    envelope: [data]
+//	    exceptions:
+//	      - "/legacy/**"
+func parseIdentifierOptions(options map[string]interface{}) (*identifierRequirement, []string) {
+	req := &identifierRequirement{envelope: []string{"data"}}
+	var problems []string
+	if _, ok := options["exceptions"]; ok {
+		patterns, listProblems := stringListOption(options, "exceptions")
+		problems = append(problems, listProblems...)
+		for _, text := range patterns {
+			p, err := newScopePattern(text)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("functionOptions.exceptions: o padrão %v", err))
+				continue
+			}
+			req.exceptions = append(req.exceptions, p)
+		}
+	}
+	if _, ok := options["envelope"]; ok {
+		var listProblems []string
+		req.envelope, listProblems = stringListOption(options, "envelope")
+		problems = append(problems, listProblems...)
+	}
+	known := []string{"exceptions", "envelope"}
+	var unknown []string
+	for key := range options {
+		if key != "exceptions" && key != "envelope" {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		problems = append(problems, fmt.Sprintf("functionOptions.%s desconhecida para a função identifiers (use %s)%s", key, strings.Join(known, ", "), suggestionSuffix(key, known)))
+	}
+	return req, problems
+}
+
+// Indica se o path está na lista de exceções
+func (req *identifierRequirement) excepted(route string) bool {
+	for _, p := range req.exceptions {
+		if p.re.MatchString(route) {
+			return true
+		}
+	}
+	return false
+}
+
+// Nível do schema da resposta em que as propriedades são procuradas: o objeto da
+// resposta ou o recurso dentro do envelope
+type identifierLevel struct {
+	component  string // $ref do schema do nível; vazio quando é inline
+	properties []string
+}
+
+// Função para conferir os identificadores das consultas por id (given $): em cada GET
+// cujo path termina em um parâmetro (/accounts/{accountId}), o schema da resposta 200
+// precisa ter uma propriedade com o mesmo nome do parâmetro, no próprio objeto ou no
+// recurso do envelope. A heurística só aponta a divergência quando o recurso tem outra
+// propriedade com cara de identificador (terminada em id), que é mostrada na mensagem
+// com o componente do schema; os paths das exceções ficam de fora.
+func (r *compiledRule) identifierViolations(m pathMatch) []Violation {
+	doc := m.Node
+	if doc == nil || doc.Kind != yaml.MappingNode {
+		return nil
+	}
+	description := strings.TrimSuffix(r.description, ".")
+	var violations []Violation
+	forEachEntry(mappingValue(doc, "paths"), func(route string, item *yaml.Node) {
+		segments := strings.Split(strings.TrimSuffix(route, "/"), "/")
+		last := segments[len(segments)-1]
+		if !strings.HasPrefix(last, "{") || !strings.HasSuffix(last, "}") || r.identifiers.excepted(route) {
+			return
+		}
+		param := strings.TrimSuffix(strings.TrimPrefix(last, "{"), "}")
+		operation := mappingValue(item, "get")
+		if operation == nil {
+			return
+		}
+		levels := r.identifiers.responseLevels(doc, operation)
+		if len(levels) == 0 {
+			return
+		}
+		for _, level := range levels {
+			for _, name := range level.properties {
+				if name == param {
+					return
+				}
+			}
+		}
+
+		// Os demais parâmetros do path (ex.: {consentId} de um recurso pai) não contam
+		others := map[string]bool{}
+		for _, segment := range segments[:len(segments)-1] {
+			if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+				others[strings.TrimSuffix(strings.TrimPrefix(segment, "{"), "}")] = true
+			}
+		}
+		resource := levels[len(levels)-1]
+		var candidates []string
+		for _, name := range resource.properties {
+			if !others[name] && strings.HasSuffix(strings.ToLower(name), "id") {
+				candidates = append(candidates, name)
+			}
+		}
+		if len(candidates) == 0 {
+			return
+		}
+
+		schema := "o schema inline da resposta 200"
+		if resource.component != "" {
+			schema = "o schema " + resource.component + " da resposta 200"
+		}
+		operationPath := joinPath(joinPath(joinPath(m.Path, "paths"), route), "get")
+		node, path := pathParameterNode(doc, item, operation, param, joinPath(joinPath(m.Path, "paths"), route), operationPath)
+		v := r.violation(path, node.Line, node)
+		v.Message = fmt.Sprintf("%s: GET %s tem o parâmetro de path {%s}, mas %s não tem a propriedade %s (identificadores no schema: %s).", description, route, param, schema, param, strings.Join(candidates, ", "))
+		violations = append(violations, v)
+	})
+	return violations
+}
+
+// Níveis do schema da resposta 200 da operação (OpenAPI 3: o media type JSON do content,
+// ou o primeiro; Swagger 2.0: o schema da resposta), seguindo os $refs locais: o objeto
+// da resposta e, se ele tiver uma propriedade do envelope, o recurso embrulhado (os
+// itens, quando é uma lista). Vazio quando a resposta ou o schema não existem ou estão
+// em outro arquivo.
+func (req *identifierRequirement) responseLevels(doc, operation *yaml.Node) []identifierLevel {
+	response := localRefTarget(doc, mappingValue(mappingValue(operation, "responses"), "200"))
+	schema := mappingValue(response, "schema")
+	if content := mappingValue(response, "content"); content != nil {
+		var mediaTypes []string
+		forEachEntry(content, func(mediaType string, _ *yaml.Node) { mediaTypes = append(mediaTypes, mediaType) })
+		if len(mediaTypes) == 0 {
+			return nil
+		}
+		schema = mappingValue(mappingValue(content, preferredMediaType(mediaTypes)), "schema")
+	}
+	root, ok := identifierLevelOf(doc, schema)
+	if !ok {
+		return nil
+	}
+	levels := []identifierLevel{root}
+	for _, name := range req.envelope {
+		wrapped := schemaProperty(doc, schema, name)
+		if items := mappingValue(localRefTarget(doc, wrapped), "items"); items != nil {
+			wrapped = items
+		}
+		if level, ok := identifierLevelOf(doc, wrapped); ok {
+			levels = append(levels, level)
+			break
+		}
+	}
+	return levels
+}
+
+// Propriedades do schema (e dos ramos do allOf), com o $ref pelo qual ele foi alcançado
+func identifierLevelOf(doc, schema *yaml.Node) (identifierLevel, bool) {
+	var level identifierLevel
+	if ref := mappingValue(schema, "$ref"); ref != nil {
+		level.component = ref.Value
+	}
+	seen := map[string]bool{}
+	var collect func(node *yaml.Node, depth int)
+	collect = func(node *yaml.Node, depth int) {
+		node = localRefTarget(doc, node)
+		if node == nil || depth >= maxSchemaRefDepth {
+			return
+		}
+		forEachEntry(mappingValue(node, "properties"), func(name string, _ *yaml.Node) {
+			if !seen[name] {
+				seen[name] = true
+				level.properties = append(level.properties, name)
+			}
+		})
+		if allOf := mappingValue(node, "allOf"); allOf != nil && allOf.Kind == yaml.SequenceNode {
+			for _, branch := range allOf.Content {
+				collect(branch, depth+1)
+			}
+		}
+	}
+	collect(schema, 0)
+	return level, len(level.properties) > 0
+}
+
+// Schema de uma propriedade do objeto (ou de um ramo do allOf); nulo quando não existe
+func schemaProperty(doc, schema *yaml.Node, name string) *yaml.Node {
+	var find func(node *yaml.Node, depth int) *yaml.Node
+	find = func(node *yaml.Node, depth int) *yaml.Node {
+		node = localRefTarget(doc, node)
+		if node == nil || depth >= maxSchemaRefDepth {
+			return nil
+		}
+		if property := mappingValue(mappingValue(node, "properties"), name); property != nil {
+			return property
+		}
+		if allOf := mappingValue(node, "allOf"); allOf != nil && allOf.Kind == yaml.SequenceNode {
+			for _, branch := range allOf.Content {
+				if property := find(branch, depth+1); property != nil {
+					return property
+				}
+			}
+		}
+		return nil
+	}
+	return find(schema, 0)
+}
+
+// Declaração do parâmetro de path na operação ou no path item, no local em que foi
+// escrita (o componente, quando é um $ref local); sem declaração, a própria operação
+func pathParameterNode(doc, item, operation *yaml.Node, name, itemPath, operationPath string) (*yaml.Node, string) {
+	for _, owner := range []struct {
+		node *yaml.Node
+		path string
+	}{{operation, operationPath}, {item, itemPath}} {
+		params := mappingValue(owner.node, "parameters")
+		if params == nil || params.Kind != yaml.SequenceNode {
+			continue
+		}
+		for i, param := range params.Content {
+			path := fmt.Sprintf("%s[%d]", joinPath(owner.path, "parameters"), i)
+			if ref := mappingValue(param, "$ref"); ref != nil {
+				if file, pointer := splitRef(ref.Value); file == "" {
+					path = pointerToPath(pointer)
+				}
+			}
+			target := localRefTarget(doc, param)
+			in, paramName := mappingValue(target, "in"), mappingValue(target, "name")
+			if in != nil && in.Value == "path" && paramName != nil && paramName.Value == name {
+				return target, path
+			}
+		}
+	}
+	return operation, operationPath
+}
@@ -0,0 +1,118 @@
+package validator
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const identifiersSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas/{accountId}:
+    parameters:
+      - {name: accountId, in: path, required: true, schema: {type: string}}
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/ContaResposta'}
+  /cartoes/{cartaoId}:
+    get:
+      parameters:
+        - {$ref: '#/components/parameters/CartaoId'}
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  data:
+                    type: array
+                    items:
+                      allOf:
+                        - {$ref: '#/components/schemas/Base'}
+                        - {properties: {cartaoId: {type: string}}}
+  /consentimentos/{consentId}/pagamentos/{pagamentoId}:
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema:
+                properties:
+                  data: {properties: {consentId: {type: string}, valor: {type: number}}}
+  /legado/{id}:
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema:
+                properties:
+                  legadoId: {type: string}
+components:
+  parameters:
+    CartaoId: {name: cartaoId, in: path, required: true, schema: {type: string}}
+  schemas:
+    Base:
+      properties:
+        criadoEm: {type: string}
+    ContaResposta:
+      properties:
+        data: {$ref: '#/components/schemas/Conta'}
+    Conta:
+      properties:
+        contaId: {type: string}
+        nome: {type: string}
+`
+
+// O parâmetro do GET por id precisa existir no recurso da resposta 200 (no envelope
+// data, pelos $refs, itens e allOf); só há violação quando o recurso tem outro
+// identificador, e os parâmetros do recurso pai não contam
+func TestIdentifiers(t *testing.T) {
+	rules := "rules:\n  ids:\n    description: Identificadores consistentes.\n    given: $\n    then: {function: identifiers}\n"
+	found := ruleViolations(t, identifiersSpec, rules, "ids")
+	want := []struct {
+		line    int
+		message string
+	}{
+		{6, "Identificadores consistentes: GET /contas/{accountId} tem o parâmetro de path {accountId}, mas o schema #/components/schemas/Conta da resposta 200 não tem a propriedade accountId (identificadores no schema: contaId)."},
+		{44, "Identificadores consistentes: GET /legado/{id} tem o parâmetro de path {id}, mas o schema inline da resposta 200 não tem a propriedade id (identificadores no schema: legadoId)."},
+	}
+	if len(found) != len(want) {
+		t.Fatalf("esperadas %d violações, encontradas %d: %v", len(want), len(found), found)
+	}
+	for i, v := range found {
+		if v.Line != want[i].line || v.Message != want[i].message {
+			t.Errorf("violação %d: linha %d %q, esperado linha %d %q", i, v.Line, v.Message, want[i].line, want[i].message)
+		}
+	}
+
+	rules = "rules:\n  ids:\n    given: $\n    then: {function: identifiers, functionOptions: {exceptions: [\"/legado/**\"], envelope: []}}\n"
+	found = ruleViolations(t, identifiersSpec, rules, "ids")
+	if len(found) != 0 {
+		t.Errorf("sem envelope, o objeto da resposta não tem identificador, e /legado é exceção: %v", found)
+	}
+}
+
+// Opções inválidas da função identifiers são erro de uso
+func TestIdentifiersOptions(t *testing.T) {
+	for options, message := range map[string]string{
+		"{exceptions: [5]}":    "functionOptions.exceptions[0] deve ser um texto não vazio, encontrado número",
+		"{envelope: {a: b}}":   "functionOptions.envelope deve ser uma lista de textos, encontrado objeto",
+		"{exception: [/a/**]}": "functionOptions.exception desconhecida para a função identifiers (use exceptions, envelope)",
+	} {
+		rules := "rules:\n  a:\n    given: $\n    then: {function: identifiers, functionOptions: " + options + "}\n"
+		_, err := Validate(context.Background(), []byte(identifiersSpec), Options{Source: "api.yaml", Rules: []byte(rules)})
+		if err == nil || rulesExitCode(err) != exitUsage || !strings.Contains(err.Error(), message) {
+			t.Errorf("%s: erro %v, esperado erro de uso com %q", options, err, message)
+		}
+	}
+}
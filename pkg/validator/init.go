@@ -0,0 +1,125 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Locais convencionais onde procurar uma especificação existente
+var conventionalSpecPaths = []string{"swagger.yaml", "openapi.yaml", "spec/*.yaml"}
+
+const starterRules = `# Regras do projeto para o validador OpenAPI do Open Finance Brasil.
+#
+# "extends: ofb" carrega o pacote de regras do programa embarcado no
+# validador. As regras abaixo são somadas às do pacote; uma regra com o
+# mesmo nome substitui a herdada.
+extends: ofb
+
+rules:
+  # Ajustar a severidade de uma regra do pacote (error, warning, info ou off):
+  # require-contact-info: error
+
+  # Adicionar uma regra do projeto:
+  # operation-summary:
+  #   description: "Cada operação deve ter um resumo."
+  #   severity: warning
+  #   given: "$.paths[*][*]"
+  #   then:
+  #     field: summary
+  #     function: truthy
+`
+
+const starterConfig = `# Configuração do validador OpenAPI do Open Finance Brasil.
+# Com este arquivo no diretório, o validador pode ser executado sem argumentos;
+# argumentos de linha de comando têm precedência sobre estes valores.
+
+# Especificação a ser validada e resolvida
+%s
+
+# Arquivo de regras (estende o pacote OFB embarcado)
+rules: rules.yaml
+
+# Ref do git de onde ler a versão anterior da especificação para comparação
+# baseRef: origin/main
+`
+
+// Procura uma especificação nos locais convencionais
+func detectSpec(dir string) string {
+	for _, pattern := range conventionalSpecPaths {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		sort.Strings(matches)
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && !info.IsDir() {
+				rel, err := filepath.Rel(dir, match)
+				if err != nil {
+					return match
+				}
+				return filepath.ToSlash(rel)
+			}
+		}
+	}
+	return ""
+}
+
+var initCommand = &command{
+	Name:    "init",
+	Summary: "cria a configuração e as regras iniciais do projeto",
+	Description: `Cria ` + projectConfigFile + ` e rules.yaml (estendendo o pacote ofb embarcado)
+no diretório atual. Se houver uma especificação em ` + strings.Join(conventionalSpecPaths, ", ") + `,
+o caminho dela é preenchido na configuração. Arquivos existentes só são
+sobrescritos com --force.`,
+	Examples: []string{
+		programName + " init",
+		programName + " init --force",
+	},
+	Flags: func(fs *flag.FlagSet) { fs.Bool("force", false, "sobrescreve arquivos existentes") },
+	Run:   runInit,
+}
+
+// Subcomando init: gera .openapi-ci.yaml e rules.yaml iniciais
+func runInit(c *command, args []string) int {
+	fs := c.newFlagSet()
+	force := fs.Bool("force", false, "sobrescreve arquivos existentes")
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 0 {
+		return c.usageError("argumentos inesperados: %s", strings.Join(fs.Args(), " "))
+	}
+
+	specLine := "# spec: swagger.yaml   (nenhuma especificação encontrada em " + strings.Join(conventionalSpecPaths, ", ") + ")"
+	if spec := detectSpec("."); spec != "" {
+		specLine = "spec: " + spec
+		fmt.Fprintln(stdout, "🔎 Especificação encontrada:", spec)
+	}
+
+	files := []struct {
+		path    string
+		content string
+	}{
+		{projectConfigFile, fmt.Sprintf(starterConfig, specLine)},
+		{"rules.yaml", starterRules},
+	}
+
+	if !*force {
+		for _, f := range files {
+			if _, err := os.Stat(f.path); err == nil {
+				fmt.Fprintf(stdout, "❌ %s já existe; use --force para sobrescrever.\n", f.path)
+				return exitFailure
+			}
+		}
+	}
+
+	for _, f := range files {
+		if err := os.WriteFile(f.path, []byte(f.content), 0644); err != nil {
+			fmt.Fprintf(stdout, "❌ Erro ao salvar %s: %v\n", f.path, err)
+			return exitFailure
+		}
+		fmt.Fprintln(stdout, "✅ Arquivo criado:", f.path)
+	}
+	return exitOK
+}
@@ -0,0 +1,184 @@
+package validator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Função para converter um caminho local no nome correspondente em s.fsys
+func (s *runSettings) inputName(p string) (string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(s.root, abs)
+	if err != nil {
+		return "", err
+	}
+	name := filepath.ToSlash(rel)
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: "open", Path: p, Err: fs.ErrNotExist}
+	}
+	return name, nil
+}
+
+// Função para abrir um arquivo de entrada, no disco ou em s.fsys
+func (s *runSettings) openInput(p string) (fs.File, error) {
+	if s.fsys == nil {
+		return os.Open(p)
+	}
+	name, err := s.inputName(p)
+	if err != nil {
+		return nil, err
+	}
+	return s.fsys.Open(name)
+}
+
+// Função para consultar um arquivo de entrada sem lê-lo
+func (s *runSettings) statInput(p string) (fs.FileInfo, error) {
+	if s.fsys == nil {
+		return os.Stat(p)
+	}
+	name, err := s.inputName(p)
+	if err != nil {
+		return nil, err
+	}
+	return fs.Stat(s.fsys, name)
+}
+
+// Função para listar um diretório de entrada
+func (s *runSettings) readInputDir(p string) ([]fs.DirEntry, error) {
+	if s.fsys == nil {
+		return os.ReadDir(p)
+	}
+	name, err := s.inputName(p)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadDir(s.fsys, name)
+}
+
+// Sistema de arquivos com raiz em dir, entregue ao rolodex para os $refs locais
+func (s *runSettings) inputDirFS(dir string) (fs.FS, error) {
+	if s.fsys == nil {
+		return os.DirFS(dir), nil
+	}
+	name, err := s.inputName(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%s fora do sistema de arquivos informado", dir)
+	}
+	return fs.Sub(s.fsys, name)
+}
+
+// MemFS é um sistema de arquivos em memória para Options.FS: cada chave é um caminho
+// com barras, relativo à raiz (ex.: "schemas/common.yaml"), e os diretórios são
+// deduzidos dos caminhos.
+type MemFS map[string][]byte
+
+// Open implementa fs.FS
+func (m MemFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if data, ok := m[name]; ok {
+		return &memFile{Reader: bytes.NewReader(data), info: memInfo{name: path.Base(name), size: int64(len(data))}}, nil
+	}
+	entries, err := m.ReadDir(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &memDir{info: memInfo{name: path.Base(name), dir: true}, entries: entries}, nil
+}
+
+// ReadDir implementa fs.ReadDirFS
+func (m MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	prefix := ""
+	if name != "." {
+		prefix = name + "/"
+	}
+	seen := map[string]bool{}
+	var entries []fs.DirEntry
+	for file, data := range m {
+		if !strings.HasPrefix(file, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(file, prefix)
+		child, _, isDir := strings.Cut(rest, "/")
+		if child == "" || seen[child] {
+			continue
+		}
+		seen[child] = true
+		info := memInfo{name: child, dir: isDir}
+		if !isDir {
+			info.size = int64(len(data))
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	if len(entries) == 0 && name != "." {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+type memFile struct {
+	*bytes.Reader
+	info memInfo
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memFile) Close() error               { return nil }
+
+type memDir struct {
+	info    memInfo
+	entries []fs.DirEntry
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *memDir) Close() error               { return nil }
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
+
+type memInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) ModTime() time.Time { return time.Time{} }
+func (i memInfo) IsDir() bool        { return i.dir }
+func (i memInfo) Sys() interface{}   { return nil }
+func (i memInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
@@ -0,0 +1,82 @@
+package validator
+
+import (
+	"context"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// MemFS se comporta como um fs.FS completo: arquivos, diretórios deduzidos dos
+// caminhos, ReadDir ordenado e erros para caminhos inválidos ou inexistentes
+func TestMemFS(t *testing.T) {
+	files := MemFS{
+		"catalogo/api.yaml":           []byte("openapi: 3.0.3\n"),
+		"catalogo/schemas/conta.yaml": []byte("Conta: {type: object}\n"),
+		"regras.yaml":                 []byte("rules: {}\n"),
+	}
+	if err := fstest.TestFS(files, "catalogo/api.yaml", "catalogo/schemas/conta.yaml", "regras.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"inexistente.yaml", "catalogo/outro", "/regras.yaml", "../regras.yaml"} {
+		if _, err := files.Open(name); err == nil {
+			t.Errorf("Open(%q) deveria falhar", name)
+		}
+	}
+}
+
+// Especificação, arquivos referenciados, arquivo de regras e seus extends vêm todos
+// do Options.FS, sem tocar no disco; MemFS e um fs.FS qualquer dão o mesmo resultado
+func TestOptionsFS(t *testing.T) {
+	files := map[string]string{
+		"catalogo/api.yaml":           refsSpec,
+		"catalogo/schemas/conta.yaml": "Conta: {type: object, properties: {id: {type: string}}}\n",
+		"regras/base.yaml": `rules:
+  propriedade-com-descricao:
+    description: As propriedades devem ter descrição
+    severity: error
+    given: $..properties[*]
+    then: {field: description, function: truthy}
+`,
+		"regras/api.yaml": "extends: [base.yaml]\nrules: {}\n",
+	}
+	mem, mapFS := MemFS{}, fstest.MapFS{}
+	for name, data := range files {
+		mem[name] = []byte(data)
+		mapFS[name] = &fstest.MapFile{Data: []byte(data)}
+	}
+
+	var results []*Result
+	for _, fsys := range []fs.FS{mem, mapFS} {
+		opts := Options{Source: "catalogo/api.yaml", RulesFile: "regras/api.yaml", FS: fsys}
+		result, err := Validate(context.Background(), nil, opts)
+		if err != nil {
+			t.Fatalf("%T: %v", fsys, err)
+		}
+		if len(result.Violations) != 1 || result.Violations[0].RuleID != "propriedade-com-descricao" || !strings.HasSuffix(result.Violations[0].File, "catalogo/schemas/conta.yaml") {
+			t.Fatalf("%T: violações %+v, esperada a da propriedade id em schemas/conta.yaml", fsys, result.Violations)
+		}
+		results = append(results, result)
+
+		resolved, err := Resolve(context.Background(), nil, opts)
+		if err != nil {
+			t.Fatalf("%T: %v", fsys, err)
+		}
+		if len(resolved.Errors) > 0 || !strings.Contains(string(resolved.Document), "id:") {
+			t.Errorf("%T: referência externa não resolvida: %q\n%s", fsys, resolved.Errors, resolved.Document)
+		}
+	}
+	if !reflect.DeepEqual(results[0], results[1]) {
+		t.Errorf("MemFS e fstest.MapFS divergem:\n%+v\n%+v", results[0], results[1])
+	}
+
+	delete(mem, "regras/base.yaml")
+	if _, err := Validate(context.Background(), nil, Options{Source: "catalogo/api.yaml", RulesFile: "regras/api.yaml", FS: mem}); err == nil || !strings.Contains(err.Error(), `extends "base.yaml"`) {
+		t.Errorf("extends ausente do FS: %v", err)
+	}
+	if _, err := Validate(context.Background(), nil, Options{Source: "catalogo/inexistente.yaml", RulesFile: "regras/api.yaml", FS: MemFS{"regras/api.yaml": []byte("rules: {}\n")}}); err == nil {
+		t.Error("especificação ausente do FS deveria falhar")
+	}
+}
@@ -0,0 +1,224 @@
+package validator
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Ordem dos métodos no inventário, para uma saída estável
+var methodOrder = map[string]int{"get": 0, "put": 1, "post": 2, "delete": 3, "options": 4, "head": 5, "patch": 6, "trace": 7}
+
+// Registro de uma operação no inventário do catálogo de APIs
+type inventoryRecord struct {
+	Path                 string   `json:"path"`
+	Method               string   `json:"method"`
+	OperationID          string   `json:"operationId,omitempty"`
+	Tags                 []string `json:"tags"`
+	Summary              string   `json:"summary,omitempty"`
+	SecuritySchemes      []string `json:"securitySchemes"`
+	Scopes               []string `json:"scopes"`
+	Deprecated           bool     `json:"deprecated"`
+	RequestContentTypes  []string `json:"requestContentTypes"`
+	ResponseContentTypes []string `json:"responseContentTypes"`
+	Version              string   `json:"version,omitempty"`
+	Change               string   `json:"change,omitempty"` // com --since: added, changed, unchanged ou removed
+
+	webhook     bool
+	fingerprint string // hash da operação resolvida, para --since
+}
+
+// Função para montar o inventário do documento resolvido: um registro por operação,
+// ordenado por path e método (webhooks depois dos paths)
+func inventoryRecords(root *yaml.Node) []inventoryRecord {
+	doc := documentContent(root)
+	version := ""
+	if v := mappingValue(mappingValue(doc, "info"), "version"); v != nil {
+		version = v.Value
+	}
+	globalSecurity := mappingValue(doc, "security")
+
+	records := []inventoryRecord{}
+	for _, op := range listOperations(root) {
+		section := "paths"
+		if op.Webhook {
+			section = "webhooks"
+		}
+		item := mappingValue(mappingValue(doc, section), op.Path)
+		operation := mappingValue(item, op.Method)
+		record := inventoryRecord{
+			Path:                 op.displayPath(),
+			Method:               op.Method,
+			Tags:                 scalarList(mappingValue(operation, "tags")),
+			SecuritySchemes:      []string{},
+			Scopes:               []string{},
+			RequestContentTypes:  []string{},
+			ResponseContentTypes: []string{},
+			Version:              version,
+			webhook:              op.Webhook,
+		}
+		if record.Tags == nil {
+			record.Tags = []string{}
+		}
+		if id := mappingValue(operation, "operationId"); id != nil {
+			record.OperationID = id.Value
+		}
+		if summary := mappingValue(operation, "summary"); summary != nil {
+			record.Summary = summary.Value
+		}
+		if deprecated := mappingValue(operation, "deprecated"); deprecated != nil && deprecated.Value == "true" {
+			record.Deprecated = true
+		}
+
+		// A segurança da operação substitui a global; escopos de todas as alternativas
+		security := mappingValue(operation, "security")
+		if security == nil {
+			security = globalSecurity
+		}
+		schemes, scopes := map[string]bool{}, map[string]bool{}
+		if security != nil && security.Kind == yaml.SequenceNode {
+			for _, requirement := range security.Content {
+				for i := 0; i+1 < len(requirement.Content); i += 2 {
+					schemes[requirement.Content[i].Value] = true
+					for _, scope := range requirement.Content[i+1].Content {
+						scopes[scope.Value] = true
+					}
+				}
+			}
+		}
+		record.SecuritySchemes = sortedKeys(schemes)
+		record.Scopes = sortedKeys(scopes)
+
+		record.RequestContentTypes = sortedKeys(contentTypes(mappingValue(mappingValue(operation, "requestBody"), "content")))
+		responseTypes := map[string]bool{}
+		if responses := mappingValue(operation, "responses"); responses != nil && responses.Kind == yaml.MappingNode {
+			for i := 1; i < len(responses.Content); i += 2 {
+				for media := range contentTypes(mappingValue(responses.Content[i], "content")) {
+					responseTypes[media] = true
+				}
+			}
+		}
+		record.ResponseContentTypes = sortedKeys(responseTypes)
+
+		// Impressão digital: a operação resolvida e os parâmetros do path item
+		data, _ := encodeJSON(operation)
+		params, _ := encodeJSON(orEmpty(mappingValue(item, "parameters")))
+		record.fingerprint = contentHash(data, params)
+		records = append(records, record)
+	}
+	sortInventory(records)
+	return records
+}
+
+func sortInventory(records []inventoryRecord) {
+	sort.SliceStable(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.webhook != b.webhook {
+			return !a.webhook
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return methodOrder[a.Method] < methodOrder[b.Method]
+	})
+}
+
+// Media types de um objeto content
+func contentTypes(content *yaml.Node) map[string]bool {
+	types := map[string]bool{}
+	if content != nil && content.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(content.Content); i += 2 {
+			types[content.Content[i].Value] = true
+		}
+	}
+	return types
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func orEmpty(node *yaml.Node) *yaml.Node {
+	if node == nil {
+		return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	}
+	return node
+}
+
+// Marca cada registro em relação ao inventário anterior (--since): added, changed
+// (a operação resolvida mudou) ou unchanged; as operações que deixaram de existir
+// entram como removed
+func markInventoryChanges(current, previous []inventoryRecord) []inventoryRecord {
+	key := func(r inventoryRecord) string { return r.Method + " " + r.Path }
+	before := map[string]inventoryRecord{}
+	for _, r := range previous {
+		before[key(r)] = r
+	}
+	for i := range current {
+		old, ok := before[key(current[i])]
+		switch {
+		case !ok:
+			current[i].Change = "added"
+		case old.fingerprint != current[i].fingerprint:
+			current[i].Change = "changed"
+		default:
+			current[i].Change = "unchanged"
+		}
+		delete(before, key(current[i]))
+	}
+	for _, r := range previous {
+		if _, ok := before[key(r)]; ok {
+			r.Change = "removed"
+			current = append(current, r)
+		}
+	}
+	sortInventory(current)
+	return current
+}
+
+// Função para gravar o inventário em JSON ou, com extensão .csv, em CSV; sem arquivo,
+// o JSON vai para a saída padrão
+func writeInventory(output string, records []inventoryRecord) error {
+	return writeExportOutput(output, func(w io.Writer) error {
+		if strings.EqualFold(filepath.Ext(output), ".csv") {
+			return inventoryCSV(w, records)
+		}
+		encoder := newJSONEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(records); err != nil {
+			return fmt.Errorf("erro ao gerar o inventário: %v", err)
+		}
+		return nil
+	})
+}
+
+// CSV do inventário; listas ficam em uma célula, separadas por ";"
+func inventoryCSV(w io.Writer, records []inventoryRecord) error {
+	out := csv.NewWriter(w)
+	header := []string{"path", "method", "operationId", "tags", "summary", "securitySchemes", "scopes", "deprecated", "requestContentTypes", "responseContentTypes", "version"}
+	withChange := len(records) > 0 && records[0].Change != ""
+	if withChange {
+		header = append(header, "change")
+	}
+	out.Write(header)
+	for _, r := range records {
+		record := []string{r.Path, r.Method, r.OperationID, strings.Join(r.Tags, ";"), r.Summary, strings.Join(r.SecuritySchemes, ";"), strings.Join(r.Scopes, ";"), strconv.FormatBool(r.Deprecated), strings.Join(r.RequestContentTypes, ";"), strings.Join(r.ResponseContentTypes, ";"), r.Version}
+		if withChange {
+			record = append(record, r.Change)
+		}
+		out.Write(record)
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,195 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Resultado de uma expressão JSONPath aplicada sobre a árvore YAML
+type pathMatch struct {
+	Path string     // caminho concreto do nó (ex.: $.paths['/contas'].get)
+	Node *yaml.Node // nó encontrado
+	Key  *yaml.Node // nó da chave, quando o nó veio de um mapping
+}
+
+// Um segmento da expressão: nome de campo, índice, curinga ou descida recursiva
+type pathSegment struct {
+	name      string
+	index     int
+	wildcard  bool
+	recursive bool
+	isIndex   bool
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$-]*$`)
+
+// Função para compilar uma expressão JSONPath no subconjunto suportado:
+// $, .campo, .*, [*], ['campo'], [n], ..campo e o sufixo ~ (nome da chave)
+func parseJSONPath(expr string) ([]pathSegment, bool, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "$") {
+		return nil, false, fmt.Errorf("expressão JSONPath deve começar com '$': %q", expr)
+	}
+
+	keys := false
+	if strings.HasSuffix(expr, "~") {
+		keys = true
+		expr = strings.TrimSuffix(expr, "~")
+	}
+
+	var segments []pathSegment
+	rest := expr[1:]
+	for len(rest) > 0 {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			rest = rest[2:]
+			name, n := readName(rest)
+			if n == 0 {
+				return nil, false, fmt.Errorf("nome esperado após '..' em %q", expr)
+			}
+			segments = append(segments, pathSegment{name: name, wildcard: name == "*", recursive: true})
+			rest = rest[n:]
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			name, n := readName(rest)
+			if n == 0 {
+				return nil, false, fmt.Errorf("nome esperado após '.' em %q", expr)
+			}
+			segments = append(segments, pathSegment{name: name, wildcard: name == "*"})
+			rest = rest[n:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, false, fmt.Errorf("colchete não fechado em %q", expr)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				segments = append(segments, pathSegment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, pathSegment{name: inner[1 : len(inner)-1]})
+			default:
+				i, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, false, fmt.Errorf("seletor não suportado [%s] em %q", inner, expr)
+				}
+				segments = append(segments, pathSegment{index: i, isIndex: true})
+			}
+		default:
+			return nil, false, fmt.Errorf("caractere inesperado %q em %q", rest[0], expr)
+		}
+	}
+	return segments, keys, nil
+}
+
+// Lê um nome de campo até o próximo separador
+func readName(s string) (string, int) {
+	n := 0
+	for n < len(s) && s[n] != '.' && s[n] != '[' && s[n] != '~' {
+		n++
+	}
+	return s[:n], n
+}
+
+// Função para avaliar uma expressão JSONPath sobre o documento YAML
+func queryJSONPath(root *yaml.Node, expr string) ([]pathMatch, error) {
+	segments, keys, err := parseJSONPath(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := root
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+
+	current := []pathMatch{{Path: "$", Node: doc}}
+	for _, seg := range segments {
+		var next []pathMatch
+		for _, m := range current {
+			if seg.recursive {
+				next = append(next, descend(m, seg)...)
+			} else {
+				next = append(next, children(m, seg)...)
+			}
+		}
+		current = next
+	}
+
+	if keys {
+		var names []pathMatch
+		for _, m := range current {
+			if m.Key != nil {
+				names = append(names, pathMatch{Path: m.Path + "~", Node: m.Key, Key: m.Key})
+			}
+		}
+		return names, nil
+	}
+	return current, nil
+}
+
+// Retorna os filhos diretos de um nó que casam com o segmento
+func children(m pathMatch, seg pathSegment) []pathMatch {
+	node := m.Node
+	var out []pathMatch
+	switch node.Kind {
+	case yaml.MappingNode:
+		if seg.isIndex {
+			return nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, v := node.Content[i], node.Content[i+1]
+			if seg.wildcard || k.Value == seg.name {
+				out = append(out, pathMatch{Path: joinPath(m.Path, k.Value), Node: v, Key: k})
+			}
+		}
+	case yaml.SequenceNode:
+		for i, v := range node.Content {
+			if seg.wildcard || (seg.isIndex && seg.index == i) {
+				out = append(out, pathMatch{Path: fmt.Sprintf("%s[%d]", m.Path, i), Node: v})
+			}
+		}
+	case yaml.AliasNode:
+		if node.Alias != nil {
+			return children(pathMatch{Path: m.Path, Node: node.Alias, Key: m.Key}, seg)
+		}
+	}
+	return out
+}
+
+// Descida recursiva (..campo): procura o segmento em todos os níveis abaixo do nó
+func descend(m pathMatch, seg pathSegment) []pathMatch {
+	flat := pathSegment{name: seg.name, wildcard: seg.wildcard}
+	out := children(m, flat)
+	for _, c := range children(m, pathSegment{wildcard: true}) {
+		out = append(out, descend(c, seg)...)
+	}
+	return out
+}
+
+// Monta o caminho concreto usando a notação com colchetes quando necessário
+func joinPath(base, key string) string {
+	if identifierPattern.MatchString(key) {
+		return base + "." + key
+	}
+	return base + "['" + strings.ReplaceAll(key, "'", "\\'") + "']"
+}
+
+// Indica se a expressão aponta para um único local (sem curingas nem descida recursiva)
+func isDefinitePath(expr string) bool {
+	segments, _, err := parseJSONPath(expr)
+	if err != nil {
+		return false
+	}
+	for _, seg := range segments {
+		if seg.wildcard || seg.recursive {
+			return false
+		}
+	}
+	return true
+}
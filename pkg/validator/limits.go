@@ -0,0 +1,318 @@
+package validator
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Limites do documento resolvido: profundidade de expansão dos $refs, tamanho da saída
+// e quantas vezes ele pode ser maior que os arquivos de origem
+var (
+	maxRefDepth       int
+	maxOutputSize     byteSize
+	maxExpansionRatio float64
+)
+
+// Tamanho máximo padrão de cada arquivo lido (especificações, referências, regras,
+// respostas HTTP)
+const defaultMaxFileSize = 50 << 20
+
+var maxFileSize = byteSize(defaultMaxFileSize)
+
+func registerInputLimitFlag(fs *flag.FlagSet) {
+	fs.Var(&maxFileSize, "max-file-size", "tamanho máximo de cada arquivo lido (ex.: 100MB; 0 = sem limite)")
+}
+
+func registerOutputLimitFlags(fs *flag.FlagSet) {
+	fs.IntVar(&maxRefDepth, "max-depth", 0, "profundidade máxima de $refs expandidos; além dela a referência fica como $ref (0 = sem limite)")
+	fs.Var(&maxOutputSize, "max-output-size", "aborta quando o documento resolvido passa do limite (ex.: 50MB; padrão: sem limite)")
+	fs.Float64Var(&maxExpansionRatio, "max-expansion-ratio", 0, "aborta quando o documento resolvido fica mais de N vezes maior que os arquivos de origem (ex.: 10; 0 = sem limite)")
+}
+
+func checkOutputLimitFlags() error {
+	if maxExpansionRatio < 0 {
+		return fmt.Errorf("valor inválido para --max-expansion-ratio: %g (use 0 ou um número positivo)", maxExpansionRatio)
+	}
+	return nil
+}
+
+// Tamanho em bytes aceito em flags: 1048576, 512KB, 50MB, 1GB
+type byteSize int64
+
+func (b *byteSize) String() string {
+	if *b == 0 {
+		return ""
+	}
+	return formatBytes(int64(*b))
+}
+
+func (b *byteSize) Set(value string) error {
+	v := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(v, unit.suffix) {
+			v, multiplier = strings.TrimSpace(strings.TrimSuffix(v, unit.suffix)), unit.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n < 0 {
+		return fmt.Errorf("tamanho inválido %q (ex.: 512KB, 50MB)", value)
+	}
+	*b = byteSize(n * float64(multiplier))
+	return nil
+}
+
+func formatBytes(n int64) string {
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}} {
+		if n >= unit.size {
+			if n%unit.size == 0 {
+				return fmt.Sprintf("%d%s", n/unit.size, unit.suffix)
+			}
+			return fmt.Sprintf("%.1f%s", float64(n)/float64(unit.size), unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%dB", n)
+}
+
+// Função para copiar o documento resolvido expandindo no máximo maxDepth $refs
+// aninhados; a partir daí o $ref original é mantido. refNodes são os nós que
+// continham $ref antes da resolução, com uma cópia do conteúdo original.
+func limitRefDepth(rootNode *yaml.Node, refNodes map[*yaml.Node]*yaml.Node, maxDepth int) (*yaml.Node, int) {
+	limited := 0
+	var copyNode func(node *yaml.Node, depth int) *yaml.Node
+	copyNode = func(node *yaml.Node, depth int) *yaml.Node {
+		if original, ok := refNodes[node]; ok {
+			if depth >= maxDepth {
+				limited++
+				return deepCopyNode(original)
+			}
+			depth++
+		}
+		copied := *node
+		copied.Content = make([]*yaml.Node, len(node.Content))
+		for i, child := range node.Content {
+			copied.Content[i] = copyNode(child, depth)
+		}
+		return &copied
+	}
+	return copyNode(rootNode, 0), limited
+}
+
+// Contribuição de um componente para o tamanho da saída
+type sizeContribution struct {
+	Ref   string  `json:"ref"`
+	Times float64 `json:"times"` // quantas vezes o conteúdo foi expandido
+	Bytes float64 `json:"bytes"` // tamanho estimado somando todas as expansões
+}
+
+// Função para estimar o tamanho do documento sem serializá-lo. Após a resolução, o
+// mesmo conteúdo é compartilhado por todos os $refs que apontam para ele, então a
+// árvore é um grafo: o tamanho de cada nó é calculado uma vez e multiplicado pelo
+// número de caminhos que chegam até ele. A estimativa (texto dos escalares) é um
+// limite inferior do tamanho real.
+func estimateOutputSize(rootNode *yaml.Node, refNodes map[*yaml.Node]*yaml.Node) (float64, []sizeContribution) {
+	size := map[*yaml.Node]float64{}
+	var order []*yaml.Node // pós-ordem
+	state := map[*yaml.Node]int{}
+	var visit func(node *yaml.Node)
+	visit = func(node *yaml.Node) {
+		if state[node] != 0 {
+			return
+		}
+		state[node] = 1
+		total := float64(len(node.Value) + 2)
+		for _, child := range node.Content {
+			visit(child)
+			if state[child] == 2 {
+				total += size[child]
+			}
+		}
+		size[node] = total
+		state[node] = 2
+		order = append(order, node)
+	}
+	visit(rootNode)
+
+	// Número de caminhos da raiz até cada nó, em ordem topológica (pós-ordem invertida)
+	paths := map[*yaml.Node]float64{rootNode: 1}
+	for i := len(order) - 1; i >= 0; i-- {
+		node := order[i]
+		for _, child := range node.Content {
+			paths[child] += paths[node]
+		}
+	}
+
+	byRef := map[string]*sizeContribution{}
+	for node, original := range refNodes {
+		if paths[node] == 0 {
+			continue
+		}
+		ref := mappingValue(original, "$ref").Value
+		c := byRef[ref]
+		if c == nil {
+			c = &sizeContribution{Ref: ref}
+			byRef[ref] = c
+		}
+		c.Times += paths[node]
+		c.Bytes += paths[node] * size[node]
+	}
+	contributions := make([]sizeContribution, 0, len(byRef))
+	for _, c := range byRef {
+		contributions = append(contributions, *c)
+	}
+	sort.Slice(contributions, func(i, j int) bool {
+		if contributions[i].Bytes != contributions[j].Bytes {
+			return contributions[i].Bytes > contributions[j].Bytes
+		}
+		return contributions[i].Ref < contributions[j].Ref
+	})
+	return size[rootNode], contributions
+}
+
+// Erro de --max-output-size com os componentes que mais contribuíram para o tamanho
+func outputSizeError(size float64, contributions []sizeContribution) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "o documento resolvido teria pelo menos %s, acima de --max-output-size %s", formatBytes(int64(size)), maxOutputSize.String())
+	if len(contributions) > 0 {
+		b.WriteString("; componentes que mais contribuíram:")
+		for i, c := range contributions {
+			if i == 5 {
+				break
+			}
+			fmt.Fprintf(&b, "\n   %s: expandido %.0f vezes, ~%s", c.Ref, c.Times, formatBytes(int64(c.Bytes)))
+		}
+		b.WriteString("\n   Use --max-depth ou --keep-refs para limitar a expansão.")
+	}
+	return fmt.Errorf("%s", b.String())
+}
+
+// Expansão do documento resolvido em relação aos arquivos de origem, no relatório JSON
+type expansionReport struct {
+	SourceBytes   int64              `json:"sourceBytes"`   // especificação e arquivos referenciados
+	ResolvedBytes int64              `json:"resolvedBytes"` // artefato serializado
+	Ratio         float64            `json:"ratio"`
+	Components    []sizeContribution `json:"components"` // conteúdos expandidos mais de uma vez, dos que mais pesam aos que menos pesam
+}
+
+// Função para medir a expansão do documento resolvido, ainda em memória, dado o tamanho
+// do artefato serializado: a razão para o tamanho das fontes e os componentes cujo
+// conteúdo aparece repetido na saída
+func newExpansionReport(spec *resolvedSpec, resolvedBytes int) *expansionReport {
+	e := &expansionReport{SourceBytes: spec.sourceBytes, ResolvedBytes: int64(resolvedBytes), Components: []sizeContribution{}}
+	if e.SourceBytes > 0 {
+		e.Ratio = math.Round(float64(e.ResolvedBytes)*100/float64(e.SourceBytes)) / 100
+	}
+	_, contributions := estimateOutputSize(&spec.rootNode, spec.refNodes)
+	for _, c := range contributions {
+		if c.Times > 1 {
+			e.Components = append(e.Components, c)
+		}
+	}
+	return e
+}
+
+func (e *expansionReport) String() string {
+	return fmt.Sprintf("%.2fx o tamanho das fontes (%s → %s)", e.Ratio, formatBytes(e.SourceBytes), formatBytes(e.ResolvedBytes))
+}
+
+// Indica se a expansão passa de --max-expansion-ratio
+func (e *expansionReport) exceeded() bool {
+	return maxExpansionRatio > 0 && e.Ratio > maxExpansionRatio
+}
+
+// Erro de --max-expansion-ratio com os componentes mais duplicados na saída
+func expansionRatioError(e *expansionReport) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "o documento resolvido tem %s, acima de --max-expansion-ratio %g", e, maxExpansionRatio)
+	if len(e.Components) > 0 {
+		b.WriteString("; componentes mais duplicados:")
+		for i, c := range e.Components {
+			if i == 5 {
+				break
+			}
+			fmt.Fprintf(&b, "\n   %s: expandido %.0f vezes, ~%s", c.Ref, c.Times, formatBytes(int64(c.Bytes)))
+		}
+	}
+	b.WriteString("\n   Use --bundle ou --keep-refs para manter os componentes repetidos como $ref.")
+	return fmt.Errorf("%s", b.String())
+}
+
+// Função para ler um arquivo respeitando --max-file-size, sem carregar mais do que o limite
+func (s *runSettings) readFileLimited(path string) ([]byte, error) {
+	if data, ok := s.inlineSource(path); ok {
+		if s.maxFileSize > 0 && int64(len(data)) > int64(s.maxFileSize) {
+			return nil, s.fileSizeError(path, int64(len(data)))
+		}
+		return data, nil
+	}
+	f, err := s.openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return s.readLimited(f, path)
+	}
+	if s.maxFileSize > 0 && info.Size() > int64(s.maxFileSize) {
+		return nil, s.fileSizeError(path, info.Size())
+	}
+	return s.readSized(f, path, info.Size())
+}
+
+// Lê até --max-file-size bytes; conteúdos maiores (ex.: respostas HTTP sem tamanho
+// informado) falham assim que passam do limite
+func (s *runSettings) readLimited(r io.Reader, source string) ([]byte, error) {
+	if s.maxFileSize <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, int64(s.maxFileSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > int64(s.maxFileSize) {
+		return nil, s.fileSizeError(source, -1)
+	}
+	return data, nil
+}
+
+// Como readLimited, com o tamanho informado pelo Stat: o conteúdo é lido em um único
+// buffer, sem as cópias do crescimento do io.ReadAll
+func (s *runSettings) readSized(r io.Reader, source string, size int64) ([]byte, error) {
+	if size <= 0 {
+		return s.readLimited(r, source)
+	}
+	if s.maxFileSize > 0 {
+		r = io.LimitReader(r, int64(s.maxFileSize)+1)
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, size+bytes.MinRead))
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	if s.maxFileSize > 0 && int64(buf.Len()) > int64(s.maxFileSize) {
+		return nil, s.fileSizeError(source, -1)
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *runSettings) fileSizeError(source string, size int64) error {
+	if size < 0 {
+		return fmt.Errorf("%s passa de --max-file-size %s; aumente o limite se o arquivo estiver correto", source, s.maxFileSize.String())
+	}
+	return fmt.Errorf("%s tem %s, acima de --max-file-size %s; aumente o limite se o arquivo estiver correto", source, formatBytes(size), s.maxFileSize.String())
+}
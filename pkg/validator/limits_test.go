@@ -0,0 +1,323 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Especificação em que o schema de erro compartilhado é expandido em cada resposta
+const expansionSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas:
+    get:
+      responses:
+        '400': {description: erro, content: {application/json: {schema: {$ref: '#/components/schemas/Erro'}}}}
+        '404': {description: erro, content: {application/json: {schema: {$ref: '#/components/schemas/Erro'}}}}
+        '500': {description: erro, content: {application/json: {schema: {$ref: '#/components/schemas/Erro'}}}}
+  /cartoes:
+    get:
+      responses:
+        '400': {description: erro, content: {application/json: {schema: {$ref: '#/components/schemas/Erro'}}}}
+        '500': {description: erro, content: {application/json: {schema: {$ref: '#/components/schemas/Erro'}}}}
+components:
+  schemas:
+    Erro:
+      type: object
+      required: [codigo, titulo, detalhe]
+      properties:
+        codigo: {type: string, description: Código do erro, maxLength: 255, pattern: '^[A-Z_]+$'}
+        titulo: {type: string, description: Título legível do erro, maxLength: 255}
+        detalhe: {type: string, description: Descrição detalhada do erro para o desenvolvedor, maxLength: 2048}
+`
+
+// O relatório JSON de --check traz a expansão e a tabela dos componentes duplicados
+// mesmo abaixo do limite
+func TestExpansionReport(t *testing.T) {
+	spec := filepath.Join(t.TempDir(), "api.yaml")
+	if err := os.WriteFile(spec, []byte(expansionSpec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out, errOut bytes.Buffer
+	if code := Run([]string{"resolve", "--no-cache", "--check", "--format", "json", spec}, &out, &errOut); code != exitOK {
+		t.Fatalf("exit %d\n%s%s", code, out.String(), errOut.String())
+	}
+	var report struct {
+		Resolution struct {
+			Expansion *expansionReport `json:"expansion"`
+		} `json:"resolution"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("%v\n%s", err, out.String())
+	}
+	e := report.Resolution.Expansion
+	if e == nil || e.SourceBytes != int64(len(expansionSpec)) || e.ResolvedBytes <= e.SourceBytes || e.Ratio <= 1 {
+		t.Fatalf("expansão %+v, esperado o tamanho da fonte (%d) e um artefato maior", e, len(expansionSpec))
+	}
+	if len(e.Components) != 1 || e.Components[0].Ref != "#/components/schemas/Erro" || e.Components[0].Times != 5 {
+		t.Errorf("componentes %+v, esperado só o Erro, expandido 5 vezes", e.Components)
+	}
+}
+
+// Acima de --max-expansion-ratio, a resolução falha com os componentes mais duplicados
+// e a recomendação, sem gravar o artefato; valores negativos são erro de uso
+func TestMaxExpansionRatio(t *testing.T) {
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "api.yaml")
+	output := filepath.Join(dir, "resolvido.yaml")
+	if err := os.WriteFile(spec, []byte(expansionSpec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out, errOut bytes.Buffer
+	if code := Run([]string{"resolve", "--no-cache", "--max-expansion-ratio", "1.1", "-o", output, spec}, &out, &errOut); code != exitFailure {
+		t.Fatalf("exit %d, esperado %d\n%s%s", code, exitFailure, out.String(), errOut.String())
+	}
+	for _, want := range []string{"acima de --max-expansion-ratio 1.1", "#/components/schemas/Erro: expandido 5 vezes", "Use --bundle ou --keep-refs"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("saída sem %q:\n%s", want, out.String())
+		}
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("%s não deveria ter sido gravado: %v", output, err)
+	}
+
+	out.Reset()
+	if code := Run([]string{"resolve", "--no-cache", "--max-expansion-ratio", "100", "-o", output, spec}, &out, &errOut); code != exitOK {
+		t.Fatalf("exit %d com limite folgado\n%s%s", code, out.String(), errOut.String())
+	}
+	if !strings.Contains(out.String(), "📈 Expansão:") {
+		t.Errorf("saída sem a expansão:\n%s", out.String())
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if code := Run([]string{"resolve", "--max-expansion-ratio", "-1", spec}, &out, &errOut); code != exitUsage || !strings.Contains(out.String()+errOut.String(), "valor inválido para --max-expansion-ratio: -1") {
+		t.Errorf("exit %d, esperado erro de uso\n%s%s", code, out.String(), errOut.String())
+	}
+}
+
+// Tamanhos aceitos em --max-file-size e --max-output-size
+func TestByteSize(t *testing.T) {
+	for value, want := range map[string]int64{"1048576": 1 << 20, "512KB": 512 << 10, "50mb": 50 << 20, " 1.5 GB ": 3 << 29, "0": 0} {
+		var b byteSize
+		if err := b.Set(value); err != nil || int64(b) != want {
+			t.Errorf("%q: %d (%v), esperado %d", value, b, err, want)
+		}
+	}
+	for _, value := range []string{"-1MB", "muito", "10TB"} {
+		var b byteSize
+		if err := b.Set(value); err == nil || !strings.Contains(err.Error(), "tamanho inválido") {
+			t.Errorf("%q deveria ser rejeitado: %v", value, err)
+		}
+	}
+	for n, want := range map[int64]string{512: "512B", 2048: "2KB", 1536: "1.5KB", 50 << 20: "50MB"} {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %s, esperado %s", n, got, want)
+		}
+	}
+}
+
+// A especificação e o arquivo de regras acima de --max-file-size falham com o tamanho
+// e o limite na mensagem, antes de serem lidos; um leitor sem tamanho conhecido falha
+// assim que passa do limite
+func TestMaxFileSize(t *testing.T) {
+	dir := t.TempDir()
+	spec := writeTemp(t, dir, "api.yaml", generateLargeSpec(20))
+	rules := writeTemp(t, dir, "regras.yaml", []byte("rules:\n  titulo:\n    description: '"+strings.Repeat("x", 2048)+"'\n    given: $.info\n    then: {field: title, function: truthy}\n"))
+	size := formatBytes(int64(len(mustReadFile(t, spec))))
+
+	var out, errOut bytes.Buffer
+	Run([]string{"validate", "--no-cache", "--max-file-size", "1KB", spec}, &out, &errOut)
+	if want := spec + " tem " + size + ", acima de --max-file-size 1KB"; !strings.Contains(out.String()+errOut.String(), want) {
+		t.Errorf("saída sem %q:\n%s%s", want, out.String(), errOut.String())
+	}
+
+	out.Reset()
+	errOut.Reset()
+	small := writeTemp(t, dir, "pequena.yaml", mustReadFile(t, filepath.Join("testdata", "e2e", "valid", "api.yaml")))
+	Run([]string{"validate", "--no-cache", "--max-file-size", "2KB", "--rules", rules, small}, &out, &errOut)
+	if want := "regras.yaml tem 2.1KB, acima de --max-file-size 2KB"; !strings.Contains(out.String()+errOut.String(), want) {
+		t.Errorf("saída sem %q:\n%s%s", want, out.String(), errOut.String())
+	}
+
+	s := &runSettings{maxFileSize: 1024}
+	if _, err := s.readLimited(strings.NewReader(strings.Repeat("x", 1025)), "resposta"); err == nil || err.Error() != "resposta passa de --max-file-size 1KB; aumente o limite se o arquivo estiver correto" {
+		t.Errorf("erro %v", err)
+	}
+	if data, err := s.readLimited(strings.NewReader(strings.Repeat("x", 1024)), "resposta"); err != nil || len(data) != 1024 {
+		t.Errorf("no limite, o conteúdo deveria ser lido: %d bytes, %v", len(data), err)
+	}
+}
+
+// Especificação sintética com o número de recursos pedido: cada um com listagem,
+// consulta por id e schemas próprios que usam os componentes compartilhados de erro e
+// paginação, como as APIs do Open Finance
+func generateLargeSpec(resources int) []byte {
+	var b strings.Builder
+	b.WriteString("openapi: 3.0.3\ninfo:\n  title: API sintética\n  version: 1.0.0\n  description: Especificação gerada para os testes de tamanho e desempenho.\npaths:\n")
+	for i := 0; i < resources; i++ {
+		fmt.Fprintf(&b, `  /recursos%[1]d:
+    get:
+      tags: [Recursos]
+      operationId: listarRecursos%[1]d
+      summary: Lista os recursos do grupo %[1]d.
+      description: Obtém a lista paginada dos recursos do grupo %[1]d consentidos pelo cliente.
+      parameters:
+        - $ref: '#/components/parameters/Pagina'
+      responses:
+        '200':
+          description: Lista dos recursos.
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/ListaRecursos%[1]d'}
+        '400': {$ref: '#/components/responses/Erro'}
+        '500': {$ref: '#/components/responses/Erro'}
+  /recursos%[1]d/{recursoId}:
+    get:
+      tags: [Recursos]
+      operationId: obterRecurso%[1]d
+      summary: Consulta um recurso do grupo %[1]d.
+      description: Obtém os dados de um recurso do grupo %[1]d pelo identificador.
+      parameters:
+        - {name: recursoId, in: path, required: true, description: Identificador do recurso., schema: {type: string, maxLength: 100}}
+      responses:
+        '200':
+          description: Dados do recurso.
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Recurso%[1]d'}
+        '404': {$ref: '#/components/responses/Erro'}
+`, i)
+	}
+	b.WriteString(`components:
+  parameters:
+    Pagina: {name: page, in: query, description: Número da página., schema: {type: integer, minimum: 1}}
+  responses:
+    Erro:
+      description: Erro.
+      content:
+        application/json:
+          schema: {$ref: '#/components/schemas/Erro'}
+  schemas:
+    Erro:
+      type: object
+      description: Lista de erros da requisição.
+      required: [errors]
+      properties:
+        errors:
+          type: array
+          items:
+            type: object
+            required: [code, title, detail]
+            properties:
+              code: {type: string, maxLength: 255, description: Código do erro.}
+              title: {type: string, maxLength: 255, description: Título legível do erro.}
+              detail: {type: string, maxLength: 2048, description: Descrição do erro.}
+    Paginacao:
+      type: object
+      description: Links e metadados da paginação.
+      properties:
+        totalRecords: {type: integer, description: Total de registros.}
+        totalPages: {type: integer, description: Total de páginas.}
+`)
+	for i := 0; i < resources; i++ {
+		fmt.Fprintf(&b, `    Recurso%[1]d:
+      type: object
+      description: Recurso do grupo %[1]d.
+      required: [recursoId, nome]
+      properties:
+        recursoId: {type: string, maxLength: 100, description: Identificador do recurso.}
+        nome: {type: string, maxLength: 70, description: Nome do recurso.}
+        valor: {type: number, description: Valor associado ao recurso.}
+    ListaRecursos%[1]d:
+      type: object
+      description: Lista dos recursos do grupo %[1]d.
+      required: [data, meta]
+      properties:
+        data:
+          type: array
+          items: {$ref: '#/components/schemas/Recurso%[1]d'}
+        meta: {$ref: '#/components/schemas/Paginacao'}
+`, i)
+	}
+	return []byte(b.String())
+}
+
+// Leitura de uma especificação grande com --max-file-size: a memória alocada deve
+// ficar perto do tamanho do arquivo (go test -bench ReadFileLimited -benchmem)
+func BenchmarkReadFileLimited(b *testing.B) {
+	dir := b.TempDir()
+	path := filepath.Join(dir, "api.yaml")
+	data := generateLargeSpec(2000)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		b.Fatal(err)
+	}
+	s := flagSettings()
+	s.tracker = nil
+	s.maxFileSize = defaultMaxFileSize
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.readFileLimited(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Com --max-depth, as referências além da profundidade ficam como $ref; com
+// --max-output-size, a resolução aborta sem gravar e aponta o componente que mais cresceu
+func TestOutputLimits(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"expansao.yaml": expansionSpec,
+		"profundidade.yaml": `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas:
+    get:
+      responses:
+        '200': {description: ok, content: {application/json: {schema: {$ref: '#/components/schemas/Conta'}}}}
+components:
+  schemas:
+    Conta:
+      type: object
+      properties:
+        titular: {$ref: '#/components/schemas/Pessoa'}
+    Pessoa:
+      type: object
+      properties:
+        endereco: {$ref: '#/components/schemas/Endereco'}
+    Endereco: {type: string}
+`,
+	})
+	output := filepath.Join(dir, "resolvido.yaml")
+
+	code, out := runCommand(t, "resolve", "--no-cache", "--max-depth", "1", "-o", output, filepath.Join(dir, "profundidade.yaml"))
+	if code != exitOK || !strings.Contains(out, "2 referências além da profundidade 1 mantidas como $ref") {
+		t.Fatalf("código %d\n%s", code, out)
+	}
+	resolved := string(mustReadFile(t, output))
+	for _, want := range []string{"titular: {$ref: '#/components/schemas/Pessoa'}", "endereco: {$ref: '#/components/schemas/Endereco'}"} {
+		if !strings.Contains(resolved, want) {
+			t.Errorf("artefato sem %q:\n%s", want, resolved)
+		}
+	}
+
+	os.Remove(output)
+	code, out = runCommand(t, "resolve", "--no-cache", "--max-output-size", "1KB", "-o", output, filepath.Join(dir, "expansao.yaml"))
+	for _, want := range []string{"acima de --max-output-size 1KB", "#/components/schemas/Erro: expandido 5 vezes", "Use --max-depth ou --keep-refs"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("saída sem %q:\n%s", want, out)
+		}
+	}
+	if _, err := os.Stat(output); code != exitFailure || !os.IsNotExist(err) {
+		t.Errorf("código %d, esperado %d sem gravar %s", code, exitFailure, output)
+	}
+}
@@ -0,0 +1,187 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Com --check-links, a validação confere se os links de documentação da
+// especificação (externalDocs, contatos, links nas descrições) ainda respondem
+var (
+	checkLinks       bool
+	linkTimeout      = 10 * time.Second
+	linkConcurrency  = 8
+	linkAllowedHosts stringList
+)
+
+func registerLinkFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&checkLinks, "check-links", false, "confere os links da especificação e relata como aviso os que respondem 4xx/5xx ou não respondem (ignorado com --offline)")
+	fs.DurationVar(&linkTimeout, "link-timeout", linkTimeout, "tempo máximo de cada conferência de link")
+	fs.IntVar(&linkConcurrency, "link-concurrency", linkConcurrency, "conferências de link simultâneas")
+	fs.Var(&linkAllowedHosts, "link-allow-host", "host que não é conferido (ex.: instável ou interno), incluindo os subdomínios; pode ser repetida")
+}
+
+func checkLinkFlags() error {
+	if linkConcurrency < 1 {
+		return fmt.Errorf("valor inválido para --link-concurrency: %d", linkConcurrency)
+	}
+	if linkTimeout <= 0 {
+		return fmt.Errorf("valor inválido para --link-timeout: %s", linkTimeout)
+	}
+	return nil
+}
+
+// URLs em textos: o link termina em espaço, aspas ou delimitadores de Markdown
+var linkPattern = regexp.MustCompile(`https?://[^\s<>"'()\[\]{}]+`)
+
+// Campos com endereços de API, não de documentação: servidores e fluxos OAuth
+var linkSkippedKeys = map[string]bool{"$ref": true, "authorizationUrl": true, "tokenUrl": true, "refreshUrl": true, "openIdConnectUrl": true, "operationRef": true}
+
+// Um uso de link na especificação
+type linkUsage struct {
+	path string
+	line int
+}
+
+// Função para reunir os links do documento, sem repetição, com cada local em que aparecem
+func collectLinks(root *yaml.Node) map[string][]linkUsage {
+	links := map[string][]linkUsage{}
+	var walk func(node *yaml.Node, path string)
+	walk = func(node *yaml.Node, path string) {
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := node.Content[i].Value
+				if linkSkippedKeys[key] || (key == "servers" && node.Content[i+1].Kind == yaml.SequenceNode) {
+					continue
+				}
+				walk(node.Content[i+1], joinPath(path, key))
+			}
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				walk(item, fmt.Sprintf("%s[%d]", path, i))
+			}
+		case yaml.ScalarNode:
+			for _, link := range linkPattern.FindAllString(node.Value, -1) {
+				link = strings.TrimRight(link, ".,;:!?*_`")
+				if u, err := url.Parse(link); err != nil || u.Host == "" {
+					continue
+				}
+				links[link] = append(links[link], linkUsage{path: path, line: node.Line})
+			}
+		}
+	}
+	if doc := documentContent(root); doc != nil {
+		walk(doc, "$")
+	}
+	return links
+}
+
+// Indica se o host do link está na lista de --link-allow-host
+func linkAllowed(link string, allowedHosts []string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range allowedHosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// Função para conferir os links do documento, no máximo --link-concurrency ao mesmo
+// tempo, e gerar um aviso por uso de cada link quebrado. Com --offline nada é conferido.
+func linkViolations(ctx context.Context, s *runSettings, root *yaml.Node) []Violation {
+	if s.offline {
+		fmt.Fprintln(s.log, "ℹ️  --check-links ignorado: --offline impede o acesso à rede")
+		return nil
+	}
+	links := collectLinks(root)
+	var pending []string
+	for link := range links {
+		if !linkAllowed(link, s.linkAllowedHosts) {
+			pending = append(pending, link)
+		}
+	}
+	sort.Strings(pending)
+
+	client := &http.Client{Timeout: s.linkTimeout}
+	problems := make(map[string]string, len(pending))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, s.linkConcurrency)
+	for _, link := range pending {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(link string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if problem := probeLink(ctx, client, link); problem != "" {
+				mu.Lock()
+				problems[link] = problem
+				mu.Unlock()
+			}
+		}(link)
+	}
+	wg.Wait()
+
+	var violations []Violation
+	for _, link := range pending {
+		problem, ok := problems[link]
+		if !ok || ctx.Err() != nil {
+			continue
+		}
+		for _, usage := range links[link] {
+			violations = append(violations, Violation{RuleID: "link-check", Severity: "warning", Message: fmt.Sprintf("O link %s %s.", link, problem), JSONPath: usage.path, Line: usage.line})
+		}
+	}
+	return violations
+}
+
+// Confere um link: HEAD e, quando o servidor não aceita HEAD, GET. Retorna o problema
+// encontrado ou vazio quando o link responde.
+func probeLink(ctx context.Context, client *http.Client, link string) string {
+	status := 0
+	for _, method := range []string{http.MethodHead, http.MethodGet} {
+		req, err := http.NewRequestWithContext(ctx, method, link, nil)
+		if err != nil {
+			return "é inválido"
+		}
+		req.Header.Set("User-Agent", programName+" (conferência de links)")
+		resp, err := client.Do(req)
+		if err != nil {
+			var timeout interface{ Timeout() bool }
+			if errors.As(err, &timeout) && timeout.Timeout() {
+				return fmt.Sprintf("não respondeu em %s", linkTimeout)
+			}
+			return "não pôde ser acessado: " + firstLine(err.Error())
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+		status = resp.StatusCode
+		if method == http.MethodHead && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented || status == http.StatusForbidden) {
+			continue
+		}
+		break
+	}
+	if status >= 400 {
+		return fmt.Sprintf("respondeu %d %s", status, http.StatusText(status))
+	}
+	return ""
+}
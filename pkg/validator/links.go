@@ -0,0 +1,315 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Expressões de execução dentro de um texto (ex.: "/contas/{$request.path.accountId}")
+var embeddedExpression = regexp.MustCompile(`\{(\$[^}]+)\}`)
+
+// Locais de parâmetro aceitos como prefixo nas chaves de parameters de um link
+var parameterLocations = []string{"path", "query", "header", "cookie"}
+
+// Operação de destino de um link, com os parâmetros declarados (do path item e dela)
+type linkTarget struct {
+	label  string // METHOD path
+	params map[string]bool
+	names  []string
+}
+
+// Função para conferir os links das respostas (OpenAPI 3): operationId ou operationRef
+// precisa levar a uma operação do documento, cada parâmetro do link precisa existir
+// na operação de destino e as expressões de execução precisam apontar para partes
+// que existem na requisição ou na resposta de origem ($request.path.id,
+// $response.body#/data/accountId). $refs para outros arquivos são seguidos; expressões
+// sobre schemas que não dá para conferir (sem properties, $ref remoto) são aceitas.
+func operationLinkViolations(inputFile string, root *yaml.Node) []Violation {
+	if isSwagger2(root) {
+		return nil
+	}
+	doc := documentContent(root)
+	deref := func(node *yaml.Node) *yaml.Node {
+		if ref := mappingValue(node, "$ref"); ref != nil {
+			target, _ := resolveSchemaRef(inputFile, doc, ref.Value)
+			return target
+		}
+		return node
+	}
+
+	// Operações por operationId e pelo ponteiro (para operationRef)
+	targets := map[string]*linkTarget{}
+	byPointer := map[string]*linkTarget{}
+	var operationIDs []string
+	type source struct {
+		op         operationRef
+		item, node *yaml.Node
+		path       string
+	}
+	var sources []source
+	for _, op := range listOperations(root) {
+		section := "paths"
+		if op.Webhook {
+			section = "webhooks"
+		}
+		item := mappingValue(mappingValue(doc, section), op.Path)
+		node := mappingValue(item, op.Method)
+		target := &linkTarget{label: strings.ToUpper(op.Method) + " " + op.displayPath(), params: map[string]bool{}}
+		for _, holder := range []*yaml.Node{item, node} {
+			params := mappingValue(holder, "parameters")
+			if params == nil || params.Kind != yaml.SequenceNode {
+				continue
+			}
+			for _, param := range params.Content {
+				param = deref(param)
+				name, in := mappingValue(param, "name"), mappingValue(param, "in")
+				if name == nil || in == nil {
+					continue
+				}
+				if !target.params[name.Value] {
+					target.names = append(target.names, name.Value)
+				}
+				target.params[name.Value], target.params[in.Value+"."+name.Value] = true, true
+			}
+		}
+		if id := mappingValue(node, "operationId"); id != nil && id.Value != "" {
+			targets[id.Value] = target
+			operationIDs = append(operationIDs, id.Value)
+		}
+		byPointer["/"+section+"/"+escapePointer(op.Path)+"/"+op.Method] = target
+		sources = append(sources, source{op: op, item: item, node: node, path: joinPath(joinPath(joinPath("$", section), op.Path), op.Method)})
+	}
+
+	var violations []Violation
+	for _, src := range sources {
+		responses := mappingValue(src.node, "responses")
+		if responses == nil || responses.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(responses.Content); i += 2 {
+			status := responses.Content[i].Value
+			response := deref(responses.Content[i+1])
+			links := mappingValue(response, "links")
+			if links == nil || links.Kind != yaml.MappingNode {
+				continue
+			}
+			for j := 0; j+1 < len(links.Content); j += 2 {
+				name := links.Content[j]
+				link := deref(links.Content[j+1])
+				if link == nil {
+					continue
+				}
+				linkPath := joinPath(joinPath(joinPath(joinPath(src.path, "responses"), status), "links"), name.Value)
+				report := func(rule, format string, args ...interface{}) {
+					violations = append(violations, Violation{RuleID: rule, Severity: "error", Message: fmt.Sprintf("O link %s da resposta %s de %s %s", name.Value, status, strings.ToUpper(src.op.Method)+" "+src.op.displayPath(), fmt.Sprintf(format, args...)), JSONPath: linkPath, Line: name.Line})
+				}
+
+				// Destino: operationId ou operationRef, não os dois
+				operationID, operationRef := mappingValue(link, "operationId"), mappingValue(link, "operationRef")
+				var target *linkTarget
+				switch {
+				case operationID == nil && operationRef == nil:
+					report("link-operation", "não tem operationId nem operationRef.")
+				case operationID != nil && operationRef != nil:
+					report("link-operation", "tem operationId e operationRef; use apenas um.")
+				case operationID != nil:
+					if target = targets[operationID.Value]; target == nil {
+						report("link-operation", "usa operationId %s, que não existe no documento%s.", operationID.Value, suggestionSuffix(operationID.Value, operationIDs))
+					}
+				default:
+					file, pointer := splitRef(operationRef.Value)
+					if file == "" {
+						if target = byPointer[pointer]; target == nil {
+							report("link-operation", "usa operationRef %s, que não aponta para uma operação do documento.", operationRef.Value)
+						}
+					}
+				}
+
+				// Parâmetros: o nome ou <local>.<nome> na operação de destino
+				params := mappingValue(link, "parameters")
+				if params != nil && params.Kind == yaml.MappingNode {
+					for k := 0; k+1 < len(params.Content); k += 2 {
+						key := params.Content[k].Value
+						if target != nil && !target.params[key] {
+							report("link-parameter", "passa o parâmetro %s, que %s não declara%s.", key, target.label, suggestionSuffix(linkParameterName(key), target.names))
+						}
+						for _, problem := range linkExpressionProblems(deref, src.item, src.node, response, params.Content[k+1]) {
+							report("link-expression", "%s", problem)
+						}
+					}
+				}
+				for _, problem := range linkExpressionProblems(deref, src.item, src.node, response, mappingValue(link, "requestBody")) {
+					report("link-expression", "%s", problem)
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// Nome do parâmetro sem o prefixo do local (path.accountId → accountId)
+func linkParameterName(key string) string {
+	for _, in := range parameterLocations {
+		if strings.HasPrefix(key, in+".") {
+			return key[len(in)+1:]
+		}
+	}
+	return key
+}
+
+// Função para conferir as expressões de execução de um valor do link: o valor inteiro
+// ($response.body#/id) ou as expressões entre chaves dentro de um texto
+func linkExpressionProblems(deref func(*yaml.Node) *yaml.Node, item, operation, response, value *yaml.Node) []string {
+	if value == nil || value.Kind != yaml.ScalarNode || value.Tag != "!!str" {
+		return nil
+	}
+	var expressions []string
+	if strings.HasPrefix(value.Value, "$") {
+		expressions = append(expressions, value.Value)
+	} else {
+		for _, m := range embeddedExpression.FindAllStringSubmatch(value.Value, -1) {
+			expressions = append(expressions, m[1])
+		}
+	}
+	var problems []string
+	for _, expr := range expressions {
+		if problem := linkExpressionProblem(deref, item, operation, response, expr); problem != "" {
+			problems = append(problems, fmt.Sprintf("usa a expressão %s, mas %s.", expr, problem))
+		}
+	}
+	return problems
+}
+
+// O que não existe para a expressão; vazio quando ela aponta para algo que existe
+func linkExpressionProblem(deref func(*yaml.Node) *yaml.Node, item, operation, response *yaml.Node, expr string) string {
+	switch expr {
+	case "$url", "$method", "$statusCode":
+		return ""
+	}
+	source, rest, _ := strings.Cut(strings.TrimPrefix(expr, "$"), ".")
+	if source != "request" && source != "response" {
+		return "ela não começa com $url, $method, $statusCode, $request ou $response"
+	}
+	kind, name, _ := strings.Cut(rest, ".")
+	if strings.HasPrefix(rest, "body") {
+		kind, name = "body", strings.TrimPrefix(rest, "body")
+	}
+	switch {
+	case kind == "body":
+		pointer := strings.TrimPrefix(name, "#")
+		if name != "" && !strings.HasPrefix(name, "#") {
+			return "o corpo deve ser indicado como body ou body#/ponteiro"
+		}
+		holder := response
+		if source == "request" {
+			holder = deref(mappingValue(operation, "requestBody"))
+		}
+		content := mappingValue(holder, "content")
+		if content == nil || content.Kind != yaml.MappingNode || len(content.Content) == 0 {
+			return fmt.Sprintf("a %s não tem corpo", sourceLabel(source))
+		}
+		if pointer == "" {
+			return ""
+		}
+		for i := 1; i < len(content.Content); i += 2 {
+			schema := mappingValue(content.Content[i], "schema")
+			if schema == nil || schemaHasPointer(deref, schema, pointerSegments(pointer), 0) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("o schema do corpo da %s não tem %s", sourceLabel(source), pointer)
+	case kind == "header" && source == "response":
+		headers := mappingValue(response, "headers")
+		for i := 0; headers != nil && i+1 < len(headers.Content); i += 2 {
+			if strings.EqualFold(headers.Content[i].Value, name) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("a resposta não declara o cabeçalho %s", name)
+	case kind == "path" || kind == "query" || kind == "header" || kind == "cookie":
+		if source == "response" {
+			return "respostas só têm body e header"
+		}
+		for _, holder := range []*yaml.Node{item, operation} {
+			params := mappingValue(holder, "parameters")
+			if params == nil {
+				continue
+			}
+			for _, param := range params.Content {
+				param = deref(param)
+				n, in := mappingValue(param, "name"), mappingValue(param, "in")
+				if n != nil && in != nil && in.Value == kind && (n.Value == name || kind == "header" && strings.EqualFold(n.Value, name)) {
+					return ""
+				}
+			}
+		}
+		return fmt.Sprintf("a requisição não declara o parâmetro %s em %s", name, kind)
+	}
+	return fmt.Sprintf("%s não é uma parte da %s (use body, header, path, query ou cookie)", kind, sourceLabel(source))
+}
+
+func sourceLabel(source string) string {
+	if source == "request" {
+		return "requisição"
+	}
+	return "resposta"
+}
+
+// Segmentos de um JSON Pointer, já decodificados
+func pointerSegments(pointer string) []string {
+	pointer = strings.TrimPrefix(pointer, "/")
+	if pointer == "" {
+		return nil
+	}
+	segments := strings.Split(pointer, "/")
+	for i, segment := range segments {
+		segments[i] = unescapePointer(segment)
+	}
+	return segments
+}
+
+// Indica se o caminho pode existir em um valor do schema: as propriedades, os itens
+// das listas e as partes de allOf, oneOf e anyOf são seguidos; um schema sem
+// properties nem items (ou com additionalProperties) não restringe o caminho
+func schemaHasPointer(deref func(*yaml.Node) *yaml.Node, schema *yaml.Node, segments []string, depth int) bool {
+	schema = deref(schema)
+	if schema == nil || len(segments) == 0 || depth >= maxSchemaRefDepth || schema.Kind != yaml.MappingNode {
+		return true
+	}
+	constrained := false
+	for _, key := range []string{"allOf", "oneOf", "anyOf"} {
+		members := mappingValue(schema, key)
+		if members == nil || members.Kind != yaml.SequenceNode {
+			continue
+		}
+		constrained = true
+		for _, member := range members.Content {
+			if schemaHasPointer(deref, member, segments, depth+1) {
+				return true
+			}
+		}
+	}
+	if properties := mappingValue(schema, "properties"); properties != nil {
+		constrained = true
+		if property := mappingValue(properties, segments[0]); property != nil {
+			return schemaHasPointer(deref, property, segments[1:], depth+1)
+		}
+	}
+	if items := mappingValue(schema, "items"); items != nil {
+		constrained = true
+		if _, err := strconv.Atoi(segments[0]); err == nil {
+			return schemaHasPointer(deref, items, segments[1:], depth+1)
+		}
+	}
+	if additional := mappingValue(schema, "additionalProperties"); additional != nil && additional.Value != "false" {
+		if additional.Kind == yaml.MappingNode {
+			return schemaHasPointer(deref, additional, segments[1:], depth+1)
+		}
+		return true
+	}
+	return !constrained
+}
@@ -0,0 +1,100 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+const linksSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas:
+    post:
+      operationId: criarConta
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                tipo: {type: string}
+      responses:
+        '201':
+          description: criada
+          headers:
+            Location: {schema: {type: string}}
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Conta'}
+          links:
+            consultar:
+              operationId: consultarConta
+              parameters:
+                contaId: $response.body#/data/contaId
+                query.detalhe: '{$request.body#/tipo}'
+            porReferencia:
+              operationRef: '#/paths/~1contas~1{contaId}/get'
+              parameters:
+                path.contaId: $response.header.location
+  /contas/{contaId}:
+    parameters:
+      - {name: contaId, in: path, required: true, schema: {type: string}}
+    get:
+      operationId: consultarConta
+      parameters:
+        - {name: detalhe, in: query, schema: {type: string}}
+      responses:
+        '200': {description: ok}
+components:
+  schemas:
+    Conta:
+      type: object
+      properties:
+        data:
+          type: object
+          properties:
+            contaId: {type: string}
+`
+
+// Links corretos, com $ref no schema do corpo e cabeçalho sem diferenciar maiúsculas,
+// não geram violações
+func TestLinksValid(t *testing.T) {
+	if found := operationLinkViolations("api.yaml", mustParseYAML(t, linksSpec)); len(found) != 0 {
+		t.Errorf("violações inesperadas: %v", found)
+	}
+}
+
+// Cada problema do link é apontado pela regra correspondente, na linha do nome do link
+func TestLinksProblems(t *testing.T) {
+	tests := []struct {
+		name, from, to, rule, message string
+	}{
+		{"operationId inexistente", "operationId: consultarConta\n              parameters", "operationId: consultarContas\n              parameters", "link-operation", "usa operationId consultarContas, que não existe no documento (você quis dizer consultarConta?)"},
+		{"operationRef inexistente", "~1contas~1{contaId}/get", "~1contas~1{contaId}/put", "link-operation", "não aponta para uma operação do documento"},
+		{"sem destino", "              operationId: consultarConta\n", "", "link-operation", "não tem operationId nem operationRef"},
+		{"parâmetro não declarado", "contaId: $response.body", "contaID: $response.body", "link-parameter", "passa o parâmetro contaID, que GET /contas/{contaId} não declara"},
+		{"ponteiro fora do schema", "#/data/contaId", "#/data/id", "link-expression", "o schema do corpo da resposta não tem /data/id"},
+		{"cabeçalho não declarado", "$response.header.location", "$response.header.etag", "link-expression", "a resposta não declara o cabeçalho etag"},
+		{"expressão embutida", "{$request.body#/tipo}", "{$request.query.tipo}", "link-expression", "a requisição não declara o parâmetro tipo em query"},
+		{"origem desconhecida", "$response.header.location", "$resposta.header.location", "link-expression", "ela não começa com $url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := strings.Replace(linksSpec, tt.from, tt.to, 1)
+			if spec == linksSpec {
+				t.Fatalf("trecho %q não encontrado", tt.from)
+			}
+			found := operationLinkViolations("api.yaml", mustParseYAML(t, spec))
+			if len(found) != 1 {
+				t.Fatalf("esperada uma violação, encontradas %d: %v", len(found), found)
+			}
+			v := found[0]
+			if v.RuleID != tt.rule || !strings.Contains(v.Message, tt.message) {
+				t.Errorf("violação %s %q, esperado %s com %q", v.RuleID, v.Message, tt.rule, tt.message)
+			}
+			if !strings.HasPrefix(v.JSONPath, "$.paths['/contas'].post.responses['201'].links.") || v.Line == 0 {
+				t.Errorf("localização inesperada: %s linha %d", v.JSONPath, v.Line)
+			}
+		})
+	}
+}
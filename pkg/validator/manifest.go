@@ -0,0 +1,231 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Entrada do manifesto: uma especificação, o conjunto de regras e onde salvar o relatório
+type manifestEntry struct {
+	Name    string `yaml:"name"`
+	Spec    string `yaml:"spec"`
+	Ruleset string `yaml:"ruleset"`
+	Output  string `yaml:"output"`
+}
+
+// Manifesto com as APIs de um mono-repo (apis.yaml)
+type manifest struct {
+	APIs []manifestEntry `yaml:"apis"`
+}
+
+// Resultado de uma API do manifesto
+type apiReport struct {
+	Name       string            `json:"name"`
+	Spec       string            `json:"spec"`
+	Ruleset    string            `json:"ruleset"`
+	Output     string            `json:"output,omitempty"`
+	Status     string            `json:"status"`
+	Error      string            `json:"error,omitempty"`
+	Validation *validationReport `json:"validation,omitempty"`
+}
+
+// Relatório agregado de todas as APIs do manifesto
+type manifestReport struct {
+	SchemaVersion int         `json:"schemaVersion"`
+	Manifest      string      `json:"manifest"`
+	Passed        int         `json:"passed"`
+	Failed        int         `json:"failed"`
+	Errored       int         `json:"errored"`
+	APIs          []apiReport `json:"apis"`
+}
+
+// Função para carregar o manifesto; caminhos relativos são resolvidos a partir do diretório dele
+func loadManifest(path string) (*manifest, error) {
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("erro ao fazer unmarshal do manifesto %s: %v", path, err)
+	}
+	if len(m.APIs) == 0 {
+		return nil, fmt.Errorf("o manifesto %s não possui entradas em 'apis'", path)
+	}
+
+	dir := filepath.Dir(path)
+	for i := range m.APIs {
+		entry := &m.APIs[i]
+		if entry.Name == "" {
+			entry.Name = entry.Spec
+		}
+		if entry.Ruleset == "" {
+			entry.Ruleset = "ofb"
+		}
+		entry.Spec = manifestPath(dir, entry.Spec)
+		entry.Output = manifestPath(dir, entry.Output)
+		if _, builtin := builtinRulesets[entry.Ruleset]; !builtin {
+			entry.Ruleset = manifestPath(dir, entry.Ruleset)
+		}
+	}
+	return &m, nil
+}
+
+func manifestPath(dir, path string) string {
+	path = configPath(path)
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// Valida uma entrada do manifesto; erros de arquivo ficam registrados na própria entrada
+func validateManifestEntry(ctx context.Context, entry manifestEntry, rulesets map[string]map[string]interface{}) apiReport {
+	result := apiReport{Name: entry.Name, Spec: reportPath(entry.Spec), Ruleset: reportPath(entry.Ruleset), Output: reportPath(entry.Output)}
+
+	fail := func(err error) apiReport {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+
+	if _, err := os.Stat(entry.Spec); err != nil {
+		return fail(fmt.Errorf("especificação não encontrada: %s", entry.Spec))
+	}
+
+	rules, ok := rulesets[entry.Ruleset]
+	if !ok {
+		loaded, err := loadRuleset(flagSettings(), entry.Ruleset)
+		if err != nil {
+			return fail(err)
+		}
+		rulesets[entry.Ruleset] = loaded
+		rules = loaded
+	}
+
+	report, err := validateSpecReport(ctx, entry.Spec, rules)
+	if err != nil {
+		return fail(err)
+	}
+	result.Validation = report
+	result.Status = "passed"
+	if result.Validation.Errors > 0 {
+		result.Status = "failed"
+	}
+
+	if entry.Output != "" {
+		if err := writeJSONReport(entry.Output, jsonReport{SchemaVersion: reportSchemaVersion, Validation: result.Validation}); err != nil {
+			return fail(err)
+		}
+	}
+	return result
+}
+
+// Modo manifesto: valida cada API com o seu conjunto de regras e gera o relatório agregado
+func runManifest(ctx context.Context, path, reportFile, format string) int {
+	m, err := loadManifest(path)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao carregar o manifesto:", err)
+		return exitFailure
+	}
+	return runEntries(ctx, path, m.APIs, reportFile, format)
+}
+
+// Valida uma lista de entradas (do manifesto ou de um diretório) e gera o relatório agregado
+func runEntries(ctx context.Context, source string, entries []manifestEntry, reportFile, format string) int {
+	report := manifestReport{SchemaVersion: reportSchemaVersion, Manifest: reportPath(source), APIs: []apiReport{}}
+	rulesets := map[string]map[string]interface{}{}
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		result := validateManifestEntry(ctx, entry, rulesets)
+		switch result.Status {
+		case "passed":
+			report.Passed++
+		case "failed":
+			report.Failed++
+		default:
+			report.Errored++
+		}
+		report.APIs = append(report.APIs, result)
+
+		if format != "json" && format != "sarif" {
+			switch result.Status {
+			case "error":
+				fmt.Fprintf(stdout, "❌ %s: %s\n", result.Name, result.Error)
+			default:
+				icon := "✅"
+				if result.Status == "failed" {
+					icon = "❌"
+				}
+				fmt.Fprintf(stdout, "%s %s: %d erros, %d avisos%s%s\n", icon, result.Name, result.Validation.Errors, result.Validation.Warnings, profileSuffix(result.Validation.Profile), cachedSuffix(result.Validation))
+				printCoverage(result.Validation.Coverage)
+				printMaturity(result.Validation.Maturity)
+				printExamplesMatrix(result.Validation.ExamplesMatrix)
+				printNotApplicable(result.Validation.NotApplicable)
+			}
+		}
+	}
+
+	if notifyURL != "" {
+		var specs []notificationSpec
+		for _, api := range report.APIs {
+			if api.Validation != nil {
+				specs = append(specs, notificationFor(api.Spec, api.Validation.Violations))
+			} else {
+				specs = append(specs, notificationError(api.Spec, api.Error))
+			}
+		}
+		notifyRun(specs)
+	}
+	if githubComment {
+		var validations []*validationReport
+		failures := map[string]string{}
+		for _, api := range report.APIs {
+			if api.Validation != nil {
+				validations = append(validations, api.Validation)
+			} else {
+				failures[api.Spec] = api.Error
+			}
+		}
+		commentOnPullRequest(validationMarkdown(githubCommentTitle, validations, failures))
+	}
+
+	if reportFile != "" {
+		if err := writeJSONReport(reportFile, report); err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+	}
+	switch format {
+	case "json":
+		if err := printJSONReport(report); err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+	case "sarif":
+		var validations []*validationReport
+		for _, api := range report.APIs {
+			if api.Validation != nil {
+				validations = append(validations, api.Validation)
+			}
+		}
+		if err := printSARIF(validations); err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+	default:
+		fmt.Fprintf(stdout, "🔎 %d APIs: %d aprovadas, %d reprovadas, %d com erro.\n", len(report.APIs), report.Passed, report.Failed, report.Errored)
+	}
+
+	if report.Failed > 0 || report.Errored > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
@@ -0,0 +1,61 @@
+package validator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Cada API do manifesto é validada com o seu conjunto de regras: a especificação
+// inexistente vira uma entrada com erro sem interromper as demais, e o relatório de
+// cada API é gravado em output
+func TestValidateManifest(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"valida.yaml":  string(mustReadFile(t, filepath.Join("testdata", "e2e", "valid", "api.yaml"))),
+		"violada.yaml": string(mustReadFile(t, filepath.Join("testdata", "e2e", "violations", "api.yaml"))),
+		"apis.yaml": `apis:
+  - name: contas
+    spec: valida.yaml
+    output: relatorios/contas.json
+  - name: pix
+    spec: violada.yaml
+  - name: cartoes
+    spec: inexistente.yaml
+`,
+	})
+
+	code, out := runCommand(t, "validate", "--no-cache", "--format", "json", "--manifest", filepath.Join(dir, "apis.yaml"))
+	if code != exitFailure {
+		t.Errorf("código %d, esperado %d", code, exitFailure)
+	}
+	var report manifestReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	if report.Passed != 1 || report.Failed != 1 || report.Errored != 1 || len(report.APIs) != 3 {
+		t.Fatalf("relatório: %+v", report)
+	}
+	for i, want := range []string{"contas:passed", "pix:failed", "cartoes:error"} {
+		if got := report.APIs[i].Name + ":" + report.APIs[i].Status; got != want {
+			t.Errorf("API %d: %s, esperado %s", i, got, want)
+		}
+	}
+	if report.APIs[2].Error == "" {
+		t.Error("a API sem especificação não informa o erro")
+	}
+
+	var perAPI struct {
+		Validation *validationReport `json:"validation"`
+	}
+	if err := json.Unmarshal(mustReadFile(t, filepath.Join(dir, "relatorios", "contas.json")), &perAPI); err != nil {
+		t.Fatal(err)
+	}
+	if perAPI.Validation == nil || perAPI.Validation.Errors != 0 || perAPI.Validation.File != report.APIs[0].Validation.File {
+		t.Errorf("relatório de contas: %+v", perAPI.Validation)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "relatorios", "pix.json")); !os.IsNotExist(err) {
+		t.Error("relatório gravado para a API sem output")
+	}
+}
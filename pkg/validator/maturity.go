@@ -0,0 +1,205 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Estágios de x-maturity, na ordem do ciclo de vida de uma operação
+var maturityStages = []string{"proposed", "current", "deprecated"}
+
+// Posição de cada estágio no ciclo de vida; uma operação só avança
+var maturityOrder = map[string]int{"proposed": 0, "current": 1, "deprecated": 2}
+
+// Versão de info.version: major e sufixo de pré-lançamento (ex.: 2.0.0-rc.1)
+var apiVersionPattern = regexp.MustCompile(`^v?(\d+)(?:\.\d+)*(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// Operações por estágio de x-maturity, no resumo e no relatório JSON
+type maturityReport struct {
+	Proposed    []string `json:"proposed"`
+	Current     []string `json:"current"`
+	Deprecated  []string `json:"deprecated"`
+	Unannotated int      `json:"unannotated"` // operações sem x-maturity
+}
+
+func (r *maturityReport) String() string {
+	return fmt.Sprintf("%d proposed, %d current, %d deprecated, %d sem x-maturity", len(r.Proposed), len(r.Current), len(r.Deprecated), r.Unannotated)
+}
+
+// Operação com o valor de x-maturity e os campos relacionados
+type maturityOperation struct {
+	label     string // METHOD path
+	path      string // JSONPath da operação
+	node      *yaml.Node
+	stage     string
+	stageNode *yaml.Node
+}
+
+// Função para listar as operações do documento com o x-maturity de cada uma
+func maturityOperations(root *yaml.Node) []maturityOperation {
+	doc := documentContent(root)
+	var ops []maturityOperation
+	for _, op := range listOperations(root) {
+		section := "paths"
+		if op.Webhook {
+			section = "webhooks"
+		}
+		node := mappingValue(mappingValue(mappingValue(doc, section), op.Path), op.Method)
+		entry := maturityOperation{label: strings.ToUpper(op.Method) + " " + op.displayPath(), path: joinPath(joinPath("$."+section, op.Path), op.Method), node: node}
+		if stage := mappingValue(node, "x-maturity"); stage != nil {
+			entry.stage, entry.stageNode = stage.Value, stage
+		}
+		ops = append(ops, entry)
+	}
+	return ops
+}
+
+// Indica se info.version é de uma versão GA: major 1 ou maior e sem pré-lançamento
+func isGAVersion(doc *yaml.Node) (string, bool) {
+	version := mappingValue(mappingValue(doc, "info"), "version")
+	if version == nil {
+		return "", false
+	}
+	m := apiVersionPattern.FindStringSubmatch(strings.TrimSpace(version.Value))
+	if m == nil {
+		return version.Value, false
+	}
+	major, _ := strconv.Atoi(m[1])
+	return version.Value, major >= 1 && m[2] == ""
+}
+
+// Função para conferir o x-maturity de cada operação: valor conhecido, coerência com
+// deprecated: true, data de descontinuação (x-sunset-date) nas depreciadas e nenhuma
+// operação proposed em uma versão GA
+func maturityViolations(root *yaml.Node) []Violation {
+	version, ga := isGAVersion(documentContent(root))
+	var violations []Violation
+	for _, op := range maturityOperations(root) {
+		if op.stageNode == nil {
+			continue
+		}
+		stagePath := joinPath(op.path, "x-maturity")
+		if _, ok := maturityOrder[op.stage]; !ok {
+			violations = append(violations, Violation{RuleID: "maturity-value", Severity: "error", Message: fmt.Sprintf("%s tem x-maturity %q; use %s.", op.label, op.stage, strings.Join(maturityStages, ", ")), JSONPath: stagePath, Line: op.stageNode.Line, Suggestion: maturitySuggestion(op.stage)})
+			continue
+		}
+		deprecated := mappingValue(op.node, "deprecated")
+		isDeprecated := deprecated != nil && deprecated.Value == "true"
+		switch {
+		case op.stage == "deprecated" && !isDeprecated:
+			violations = append(violations, Violation{RuleID: "maturity-deprecated", Severity: "error", Message: fmt.Sprintf("%s tem x-maturity deprecated, mas não tem deprecated: true.", op.label), JSONPath: op.path, Line: op.stageNode.Line, Suggestion: "Inclua deprecated: true na operação."})
+		case op.stage != "deprecated" && isDeprecated:
+			violations = append(violations, Violation{RuleID: "maturity-deprecated", Severity: "error", Message: fmt.Sprintf("%s tem deprecated: true, mas x-maturity %s.", op.label, op.stage), JSONPath: stagePath, Line: op.stageNode.Line, Suggestion: "Use x-maturity: deprecated ou remova deprecated: true."})
+		}
+		if op.stage == "deprecated" {
+			sunset := mappingValue(op.node, "x-sunset-date")
+			switch {
+			case sunset == nil:
+				violations = append(violations, Violation{RuleID: "maturity-sunset", Severity: "error", Message: fmt.Sprintf("%s está deprecated e não informa a data de descontinuação (x-sunset-date).", op.label), JSONPath: op.path, Line: op.stageNode.Line, Suggestion: "Inclua x-sunset-date: AAAA-MM-DD na operação."})
+			case !validSunsetDate(sunset.Value):
+				violations = append(violations, Violation{RuleID: "maturity-sunset", Severity: "error", Message: fmt.Sprintf("%s tem x-sunset-date %q, que não é uma data (AAAA-MM-DD).", op.label, sunset.Value), JSONPath: joinPath(op.path, "x-sunset-date"), Line: sunset.Line})
+			}
+		}
+		if op.stage == "proposed" && ga {
+			violations = append(violations, Violation{RuleID: "maturity-proposed-ga", Severity: "error", Message: fmt.Sprintf("%s está proposed, o que não é permitido na versão GA %s.", op.label, version), JSONPath: stagePath, Line: op.stageNode.Line, Suggestion: "Publique a operação como current ou use uma versão de pré-lançamento (ex.: 2.0.0-rc.1)."})
+		}
+	}
+	return violations
+}
+
+func maturitySuggestion(stage string) string {
+	if closest := closestName(stage, maturityStages); closest != "" {
+		return "Use x-maturity: " + closest + "."
+	}
+	return ""
+}
+
+// Datas aceitas em x-sunset-date: data (RFC 3339 full-date) ou data e hora
+func validSunsetDate(value string) bool {
+	if _, err := time.Parse("2006-01-02", value); err == nil {
+		return true
+	}
+	_, err := time.Parse(time.RFC3339, value)
+	return err == nil
+}
+
+// Função para conferir as transições de x-maturity entre a versão anterior e a nova:
+// uma operação só avança (proposed → current → deprecated). Sem x-maturity, uma
+// operação que já existia conta como current; operações novas não são conferidas.
+func maturityTransitionViolations(previous, root *yaml.Node) []Violation {
+	before := map[string]string{}
+	for _, op := range maturityOperations(previous) {
+		stage := op.stage
+		if op.stageNode == nil {
+			stage = "current"
+		}
+		before[op.label] = stage
+	}
+	var violations []Violation
+	for _, op := range maturityOperations(root) {
+		old, existed := before[op.label]
+		stage := op.stage
+		line := 0
+		if op.stageNode == nil {
+			stage = "current"
+		} else {
+			line = op.stageNode.Line
+		}
+		from, okFrom := maturityOrder[old]
+		to, okTo := maturityOrder[stage]
+		if !existed || !okFrom || !okTo || to >= from {
+			continue
+		}
+		if line == 0 && op.node != nil {
+			line = op.node.Line
+		}
+		violations = append(violations, Violation{RuleID: "maturity-transition", Severity: "error", Message: fmt.Sprintf("%s voltou de %s para %s; o ciclo de vida só avança (%s).", op.label, old, stage, strings.Join(maturityStages, " → ")), JSONPath: joinPath(op.path, "x-maturity"), Line: line})
+	}
+	return violations
+}
+
+// Operações por estágio; nulo quando nenhuma operação usa x-maturity
+func maturitySummary(root *yaml.Node) *maturityReport {
+	report := &maturityReport{Proposed: []string{}, Current: []string{}, Deprecated: []string{}}
+	annotated := false
+	for _, op := range maturityOperations(root) {
+		switch op.stage {
+		case "proposed":
+			report.Proposed = append(report.Proposed, op.label)
+		case "current":
+			report.Current = append(report.Current, op.label)
+		case "deprecated":
+			report.Deprecated = append(report.Deprecated, op.label)
+		default:
+			if op.stageNode == nil {
+				report.Unannotated++
+			}
+		}
+		annotated = annotated || op.stageNode != nil
+	}
+	if !annotated {
+		return nil
+	}
+	return report
+}
+
+// Imprime as operações por estágio de x-maturity
+func printMaturity(r *maturityReport) {
+	if r == nil {
+		return
+	}
+	fmt.Fprintln(stdout, "🧭 Maturidade das operações:", r)
+	for _, stage := range []struct {
+		name string
+		ops  []string
+	}{{"proposed", r.Proposed}, {"current", r.Current}, {"deprecated", r.Deprecated}} {
+		if len(stage.ops) > 0 {
+			fmt.Fprintf(stdout, "   %s: %s\n", stage.name, strings.Join(stage.ops, ", "))
+		}
+	}
+}
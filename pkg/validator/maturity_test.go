@@ -0,0 +1,124 @@
+package validator
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const maturitySpec = `openapi: 3.0.3
+info: {title: Contas, version: %s}
+paths:
+  /contas:
+    get:
+      x-maturity: proposed
+      responses: {'200': {description: ok}}
+    post:
+      x-maturity: curent
+      responses: {'201': {description: ok}}
+    put:
+      x-maturity: deprecated
+      responses: {'200': {description: ok}}
+    delete:
+      x-maturity: current
+      deprecated: true
+      responses: {'204': {description: ok}}
+    patch:
+      x-maturity: deprecated
+      deprecated: true
+      x-sunset-date: amanhã
+      responses: {'200': {description: ok}}
+  /saldos:
+    get:
+      responses: {'200': {description: ok}}
+`
+
+func parseMaturitySpec(t *testing.T, spec string) *yaml.Node {
+	t.Helper()
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(spec), &root); err != nil {
+		t.Fatal(err)
+	}
+	return &root
+}
+
+// Valor desconhecido (com sugestão), incoerência com deprecated: true, depreciada sem
+// x-sunset-date válida e proposed em versão GA; em pré-lançamento, proposed é aceita
+func TestMaturityViolations(t *testing.T) {
+	messages := func(version string) []string {
+		var got []string
+		for _, v := range maturityViolations(parseMaturitySpec(t, fmt.Sprintf(maturitySpec, version))) {
+			got = append(got, fmt.Sprintf("%s:%d %s", v.RuleID, v.Line, v.Message))
+		}
+		return got
+	}
+	want := []string{
+		"maturity-proposed-ga:6 GET /contas está proposed, o que não é permitido na versão GA 1.0.0.",
+		`maturity-value:9 POST /contas tem x-maturity "curent"; use proposed, current, deprecated.`,
+		"maturity-deprecated:12 PUT /contas tem x-maturity deprecated, mas não tem deprecated: true.",
+		"maturity-sunset:12 PUT /contas está deprecated e não informa a data de descontinuação (x-sunset-date).",
+		"maturity-deprecated:15 DELETE /contas tem deprecated: true, mas x-maturity current.",
+		`maturity-sunset:21 PATCH /contas tem x-sunset-date "amanhã", que não é uma data (AAAA-MM-DD).`,
+	}
+	if got := messages("1.0.0"); !reflect.DeepEqual(got, want) {
+		t.Errorf("violações:\n%s\nesperado:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if got := messages("2.0.0-rc.1"); len(got) != len(want)-1 || strings.HasPrefix(got[0], "maturity-proposed-ga") {
+		t.Errorf("em pré-lançamento:\n%s", strings.Join(got, "\n"))
+	}
+
+	report := maturitySummary(parseMaturitySpec(t, fmt.Sprintf(maturitySpec, "1.0.0")))
+	if report == nil || report.String() != "1 proposed, 1 current, 2 deprecated, 1 sem x-maturity" ||
+		!reflect.DeepEqual(report.Deprecated, []string{"PUT /contas", "PATCH /contas"}) {
+		t.Errorf("resumo %+v", report)
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"api.yaml": fmt.Sprintf(maturitySpec, "1.0.0")})
+	code, out := runCommand(t, "validate", "--no-cache", filepath.Join(dir, "api.yaml"))
+	summary := "🧭 Maturidade das operações: 1 proposed, 1 current, 2 deprecated, 1 sem x-maturity\n" +
+		"   proposed: GET /contas\n   current: DELETE /contas\n   deprecated: PUT /contas, PATCH /contas\n"
+	if code != exitFailure || !strings.Contains(out, summary) {
+		t.Errorf("código %d, saída sem o resumo:\n%s", code, out)
+	}
+}
+
+// Uma operação só avança no ciclo de vida; sem x-maturity, a que já existia conta como
+// current, e operações novas não são conferidas
+func TestMaturityTransitionViolations(t *testing.T) {
+	previous := parseMaturitySpec(t, `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas:
+    get: {x-maturity: current, responses: {'200': {description: ok}}}
+    post: {x-maturity: deprecated, responses: {'201': {description: ok}}}
+    put: {x-maturity: proposed, responses: {'200': {description: ok}}}
+    delete: {responses: {'204': {description: ok}}}
+`)
+	current := parseMaturitySpec(t, `openapi: 3.0.3
+info: {title: Contas, version: 2.0.0}
+paths:
+  /contas:
+    get: {x-maturity: proposed, responses: {'200': {description: ok}}}
+    post: {responses: {'201': {description: ok}}}
+    put: {x-maturity: current, responses: {'200': {description: ok}}}
+    delete: {x-maturity: proposed, responses: {'204': {description: ok}}}
+    patch: {x-maturity: proposed, responses: {'200': {description: ok}}}
+`)
+	var got []string
+	for _, v := range maturityTransitionViolations(previous, current) {
+		got = append(got, fmt.Sprintf("%s:%d %s", v.JSONPath, v.Line, v.Message))
+	}
+	want := []string{
+		"$.paths['/contas'].get.x-maturity:5 GET /contas voltou de current para proposed; o ciclo de vida só avança (proposed → current → deprecated).",
+		"$.paths['/contas'].post.x-maturity:6 POST /contas voltou de deprecated para current; o ciclo de vida só avança (proposed → current → deprecated).",
+		"$.paths['/contas'].delete.x-maturity:8 DELETE /contas voltou de current para proposed; o ciclo de vida só avança (proposed → current → deprecated).",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("violações:\n%s\nesperado:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,356 @@
+package validator
+
+import (
+	"fmt"
+	"mime"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Extensão que libera uma operação (ou um path) de seguir os media types da tag quando
+// as opções não dizem
+const defaultMediaTypeJustification = "x-media-type-justification"
+
+// Opções da função mediaTypes
+type mediaTypeRequirement struct {
+	forbidden     map[string]bool // parâmetros proibidos nos media types (ex.: charset)
+	justification string          // extensão que libera a operação da comparação com a tag
+	consistent    bool            // compara os media types das operações de cada tag
+}
+
+// Função para ler as opções da função mediaTypes: os parâmetros proibidos nos media
+// types (padrão: charset), se as operações de cada tag precisam usar os mesmos media
+// types (padrão: sim) e a extensão que libera uma operação ou um path dessa comparação
+// (padrão: x-media-type-justification)
+//
+//	then:
+//	  function: mediaTypes
+//	  functionOptions:
+//	    forbiddenParameters: [charset]
+//	    consistentByTag: true
+//	    justification: x-media-type-justification
+func parseMediaTypeOptions(options map[string]interface{}) (*mediaTypeRequirement, []string) {
+	req := &mediaTypeRequirement{forbidden: map[string]bool{"charset": true}, justification: defaultMediaTypeJustification, consistent: true}
+	var problems []string
+	if _, ok := options["forbiddenParameters"]; ok {
+		names, listProblems := stringListOption(options, "forbiddenParameters")
+		problems = append(problems, listProblems...)
+		req.forbidden = map[string]bool{}
+		for _, name := range names {
+			req.forbidden[strings.ToLower(name)] = true
+		}
+	}
+	if raw, ok := options["consistentByTag"]; ok {
+		if req.consistent, ok = raw.(bool); !ok {
+			problems = append(problems, fmt.Sprintf("functionOptions.consistentByTag deve ser true ou false, encontrado %s", ruleValueType(raw)))
+		}
+	}
+	if raw, ok := options["justification"]; ok {
+		name, isString := raw.(string)
+		if !isString || !strings.HasPrefix(name, "x-") {
+			problems = append(problems, fmt.Sprintf("functionOptions.justification deve ser o nome de uma extensão (x-...), encontrado %v", raw))
+		} else {
+			req.justification = name
+		}
+	}
+	known := []string{"forbiddenParameters", "consistentByTag", "justification"}
+	var unknown []string
+	for key := range options {
+		if key != "forbiddenParameters" && key != "consistentByTag" && key != "justification" {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		problems = append(problems, fmt.Sprintf("functionOptions.%s desconhecida para a função mediaTypes (use %s)%s", key, strings.Join(known, ", "), suggestionSuffix(key, known)))
+	}
+	return req, problems
+}
+
+// Media type declarado em uma operação, como foi escrito e normalizado (tipo/subtipo
+// em minúsculas, sem parâmetros)
+type declaredMediaType struct {
+	written    string
+	normalized string
+	params     map[string]string
+	err        error
+	duplicate  string // media type do mesmo content que tem a mesma forma normalizada
+	node       *yaml.Node
+	path       string
+	where      string // ex.: "resposta 200", "requisição"
+}
+
+// Media types de requisição e de resposta de uma operação do OpenAPI 3 (content do
+// requestBody e das respostas, seguindo os $refs locais) ou do Swagger 2.0 (consumes e
+// produces da operação ou do documento)
+func operationMediaTypes(doc, operation *yaml.Node, path string) (request, response []declaredMediaType) {
+	collect := func(content *yaml.Node, contentPath, where string) []declaredMediaType {
+		var found []declaredMediaType
+		written := map[string]string{}
+		for i := 0; content != nil && content.Kind == yaml.MappingNode && i+1 < len(content.Content); i += 2 {
+			key := content.Content[i]
+			mt := newDeclaredMediaType(key.Value, key, joinPath(contentPath, key.Value), where)
+			mt.duplicate = written[mt.normalized]
+			if mt.duplicate == "" {
+				written[mt.normalized] = key.Value
+			}
+			found = append(found, mt)
+		}
+		return found
+	}
+	// Um $ref local aponta para o componente, onde o media type foi escrito
+	follow := func(node *yaml.Node, nodePath string) (*yaml.Node, string) {
+		ref := mappingValue(node, "$ref")
+		if ref == nil {
+			return node, nodePath
+		}
+		if file, pointer := splitRef(ref.Value); file == "" {
+			return localRefTarget(doc, node), pointerToPath(pointer)
+		}
+		return nil, nodePath
+	}
+
+	if body, bodyPath := follow(mappingValue(operation, "requestBody"), joinPath(path, "requestBody")); body != nil {
+		request = collect(mappingValue(body, "content"), joinPath(bodyPath, "content"), "requisição")
+	}
+	forEachEntry(mappingValue(operation, "responses"), func(status string, node *yaml.Node) {
+		if strings.HasPrefix(status, "x-") {
+			return
+		}
+		if resp, respPath := follow(node, joinPath(joinPath(path, "responses"), status)); resp != nil {
+			response = append(response, collect(mappingValue(resp, "content"), joinPath(respPath, "content"), "resposta "+status)...)
+		}
+	})
+
+	for _, section := range []struct {
+		key  string
+		list *[]declaredMediaType
+		what string
+	}{{"consumes", &request, "requisição"}, {"produces", &response, "respostas"}} {
+		owner, ownerPath := operation, path
+		if mappingValue(operation, section.key) == nil {
+			owner, ownerPath = doc, "$"
+		}
+		list := mappingValue(owner, section.key)
+		if list == nil || list.Kind != yaml.SequenceNode {
+			continue
+		}
+		for i, item := range list.Content {
+			*section.list = append(*section.list, newDeclaredMediaType(item.Value, item, fmt.Sprintf("%s[%d]", joinPath(ownerPath, section.key), i), section.what))
+		}
+	}
+	return request, response
+}
+
+func newDeclaredMediaType(written string, node *yaml.Node, path, where string) declaredMediaType {
+	m := declaredMediaType{written: written, node: node, path: path, where: where}
+	m.normalized, m.params, m.err = mime.ParseMediaType(written)
+	if m.err == nil && !strings.Contains(m.normalized, "/") {
+		m.err = fmt.Errorf("falta o subtipo (tipo/subtipo)")
+	}
+	if m.err != nil {
+		m.normalized = strings.ToLower(strings.TrimSpace(strings.SplitN(written, ";", 2)[0]))
+	}
+	return m
+}
+
+// Operação com os media types, para a comparação dentro da tag
+type mediaTypeOperation struct {
+	label     string
+	tag       string
+	justified bool
+	request   []declaredMediaType
+	response  []declaredMediaType
+}
+
+// Função para conferir os media types do documento (given $): cada media type precisa
+// ser válido, não ter os parâmetros proibidos e não repetir outro do mesmo content
+// depois de normalizado; com consistentByTag, as operações de uma tag (a primeira de
+// cada operação) usam o mesmo conjunto de media types de requisição e de resposta que
+// a maioria delas, salvo as marcadas com a extensão de justificativa. As mensagens
+// trazem o media type como foi escrito.
+func (r *compiledRule) mediaTypeViolations(m pathMatch) []Violation {
+	doc := m.Node
+	if doc == nil || doc.Kind != yaml.MappingNode {
+		return nil
+	}
+	description := strings.TrimSuffix(r.description, ".")
+	var violations []Violation
+	// Um componente compartilhado é conferido uma vez, no local em que foi escrito
+	checked := map[string]bool{}
+	checkDeclared := func(label string, declared []declaredMediaType) {
+		for _, mt := range declared {
+			if checked[mt.path] {
+				continue
+			}
+			checked[mt.path] = true
+			var problem string
+			switch {
+			case mt.err != nil:
+				problem = fmt.Sprintf("não é um media type válido (%v)", mt.err)
+			case len(mt.params) > 0:
+				var forbidden []string
+				for name := range mt.params {
+					if r.mediaTypes.forbidden[name] {
+						forbidden = append(forbidden, name)
+					}
+				}
+				sort.Strings(forbidden)
+				if len(forbidden) > 0 {
+					problem = fmt.Sprintf("tem o parâmetro %s, proibido pelo perfil; use %s", strings.Join(forbidden, ", "), mt.normalized)
+				}
+			}
+			if problem == "" && mt.duplicate != "" {
+				problem = fmt.Sprintf("repete %q, que é o mesmo media type depois de normalizado", mt.duplicate)
+			}
+			if problem == "" {
+				continue
+			}
+			v := r.violation(mt.path, mt.node.Line, mt.node)
+			v.Message = fmt.Sprintf("%s: o media type %q (%s de %s) %s.", description, mt.written, mt.where, label, problem)
+			violations = append(violations, v)
+		}
+	}
+
+	var operations []mediaTypeOperation
+	for _, section := range []string{"paths", "webhooks"} {
+		forEachEntry(mappingValue(doc, section), func(route string, item *yaml.Node) {
+			itemPath := joinPath(joinPath(m.Path, section), route)
+			justified := isTruthy(mappingValue(item, r.mediaTypes.justification))
+			forEachEntry(item, func(method string, operation *yaml.Node) {
+				if !httpMethods[method] {
+					return
+				}
+				op := operationRef{Method: method, Path: route, Webhook: section == "webhooks"}
+				entry := mediaTypeOperation{label: strings.ToUpper(method) + " " + op.displayPath(), justified: justified || isTruthy(mappingValue(operation, r.mediaTypes.justification))}
+				entry.request, entry.response = operationMediaTypes(doc, operation, joinPath(itemPath, method))
+				if tags := mappingValue(operation, "tags"); tags != nil && tags.Kind == yaml.SequenceNode && len(tags.Content) > 0 {
+					entry.tag = tags.Content[0].Value
+				}
+				checkDeclared(entry.label, entry.request)
+				checkDeclared(entry.label, entry.response)
+				operations = append(operations, entry)
+			})
+		})
+	}
+	if !r.mediaTypes.consistent {
+		return violations
+	}
+
+	// Operações de cada tag, na ordem do documento
+	var tags []string
+	byTag := map[string][]mediaTypeOperation{}
+	for _, op := range operations {
+		if op.tag == "" {
+			continue
+		}
+		if _, ok := byTag[op.tag]; !ok {
+			tags = append(tags, op.tag)
+		}
+		byTag[op.tag] = append(byTag[op.tag], op)
+	}
+	for _, tag := range tags {
+		for _, kind := range []struct {
+			name string
+			list func(mediaTypeOperation) []declaredMediaType
+		}{
+			{"requisição", func(op mediaTypeOperation) []declaredMediaType { return op.request }},
+			{"resposta", func(op mediaTypeOperation) []declaredMediaType { return op.response }},
+		} {
+			violations = append(violations, r.tagMediaTypeViolations(tag, kind.name, byTag[tag], kind.list)...)
+		}
+	}
+	return violations
+}
+
+// Conjunto normalizado de media types, em ordem, como chave de comparação
+func mediaTypeSet(declared []declaredMediaType) string {
+	seen := map[string]bool{}
+	var set []string
+	for _, mt := range declared {
+		if !seen[mt.normalized] {
+			seen[mt.normalized] = true
+			set = append(set, mt.normalized)
+		}
+	}
+	sort.Strings(set)
+	return strings.Join(set, ", ")
+}
+
+// Compara o conjunto de media types (de requisição ou de resposta) de cada operação da
+// tag com o da maioria (no empate, o da primeira operação); operações sem media types
+// desse tipo ficam de fora
+func (r *compiledRule) tagMediaTypeViolations(tag, kind string, operations []mediaTypeOperation, list func(mediaTypeOperation) []declaredMediaType) []Violation {
+	counts := map[string]int{}
+	var sets []string
+	for _, op := range operations {
+		if set := mediaTypeSet(list(op)); set != "" {
+			if counts[set] == 0 {
+				sets = append(sets, set)
+			}
+			counts[set]++
+		}
+	}
+	if len(sets) < 2 {
+		return nil
+	}
+	reference := sets[0]
+	for _, set := range sets[1:] {
+		if counts[set] > counts[reference] {
+			reference = set
+		}
+	}
+	expected := map[string]bool{}
+	for _, mt := range strings.Split(reference, ", ") {
+		expected[mt] = true
+	}
+
+	description := strings.TrimSuffix(r.description, ".")
+	var violations []Violation
+	for _, op := range operations {
+		declared := list(op)
+		set := mediaTypeSet(declared)
+		if set == "" || set == reference || op.justified {
+			continue
+		}
+		var divergent []string
+		var first *declaredMediaType
+		for i, mt := range declared {
+			if !expected[mt.normalized] {
+				divergent = append(divergent, fmt.Sprintf("%q (%s)", mt.written, mt.where))
+				if first == nil {
+					first = &declared[i]
+				}
+			}
+		}
+		present := map[string]bool{}
+		for _, mt := range declared {
+			present[mt.normalized] = true
+		}
+		var missing []string
+		for _, mt := range strings.Split(reference, ", ") {
+			if !present[mt] {
+				missing = append(missing, mt)
+			}
+		}
+		if first == nil {
+			first = &declared[0]
+		}
+		problem := ""
+		if len(divergent) > 0 {
+			problem = "usa " + strings.Join(divergent, ", ")
+		}
+		if len(missing) > 0 {
+			if problem != "" {
+				problem += " e "
+			}
+			problem += "não usa " + strings.Join(missing, ", ")
+		}
+		v := r.violation(first.path, first.node.Line, first.node)
+		v.Message = fmt.Sprintf("%s: %s %s na %s, diferente das demais operações da tag %s (%s); se o desvio for intencional, marque a operação com %s.", description, op.label, problem, kind, tag, reference, r.mediaTypes.justification)
+		violations = append(violations, v)
+	}
+	return violations
+}
@@ -0,0 +1,92 @@
+package validator
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const mediaTypesSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas:
+    get:
+      tags: [contas]
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json; charset=utf-8: {}
+            Application/JSON: {}
+        '404': {$ref: '#/components/responses/Erro'}
+  /contas/{contaId}:
+    get:
+      tags: [contas]
+      responses:
+        '200': {description: ok, content: {application/json: {}}}
+        '404': {$ref: '#/components/responses/Erro'}
+  /contas/{contaId}/extrato:
+    get:
+      tags: [contas]
+      responses:
+        '200': {description: ok, content: {text/csv: {}}}
+  /contas/{contaId}/comprovante:
+    get:
+      tags: [contas]
+      x-media-type-justification: o comprovante é um PDF
+      responses:
+        '200': {description: ok, content: {application/pdf: {}}}
+components:
+  responses:
+    Erro:
+      description: erro
+      content:
+        json: {}
+`
+
+// Parâmetros proibidos, media types inválidos e repetidos depois de normalizados são
+// relatados (o do componente uma vez só); a operação da tag com outros media types
+// também, salvo a justificada
+func TestMediaTypes(t *testing.T) {
+	rules := "rules:\n  media:\n    description: Media types do perfil.\n    given: $\n    then: {function: mediaTypes}\n"
+	found := ruleViolations(t, mediaTypesSpec, rules, "media")
+	want := []struct {
+		line    int
+		message string
+	}{
+		{11, `Media types do perfil: o media type "application/json; charset=utf-8" (resposta 200 de GET /contas) tem o parâmetro charset, proibido pelo perfil; use application/json.`},
+		{12, `Media types do perfil: o media type "Application/JSON" (resposta 200 de GET /contas) repete "application/json; charset=utf-8", que é o mesmo media type depois de normalizado.`},
+		{36, `Media types do perfil: o media type "json" (resposta 404 de GET /contas) não é um media type válido`},
+		{24, `Media types do perfil: GET /contas/{contaId}/extrato usa "text/csv" (resposta 200) e não usa application/json, json na resposta, diferente das demais operações da tag contas (application/json, json); se o desvio for intencional, marque a operação com x-media-type-justification.`},
+	}
+	if len(found) != len(want) {
+		t.Fatalf("esperadas %d violações, encontradas %d: %v", len(want), len(found), found)
+	}
+	for i, v := range found {
+		if v.Line != want[i].line || !strings.HasPrefix(v.Message, want[i].message) {
+			t.Errorf("violação %d: linha %d %q, esperado linha %d %q", i, v.Line, v.Message, want[i].line, want[i].message)
+		}
+	}
+
+	rules = "rules:\n  media:\n    given: $\n    then: {function: mediaTypes, functionOptions: {forbiddenParameters: [], consistentByTag: false}}\n"
+	found = ruleViolations(t, mediaTypesSpec, rules, "media")
+	if len(found) != 2 || !strings.Contains(found[0].Message, "Application/JSON") || !strings.Contains(found[1].Message, `"json"`) {
+		t.Errorf("sem parâmetros proibidos nem comparação por tag, esperadas só a repetição e o inválido: %v", found)
+	}
+}
+
+// Opções inválidas da função mediaTypes são erro de uso
+func TestMediaTypesOptions(t *testing.T) {
+	for options, message := range map[string]string{
+		"{consistentByTag: sim}":     "functionOptions.consistentByTag deve ser true ou false, encontrado texto",
+		"{justification: motivo}":    "functionOptions.justification deve ser o nome de uma extensão (x-...), encontrado motivo",
+		"{forbiddenParameter: [x]}":  "functionOptions.forbiddenParameter desconhecida para a função mediaTypes",
+		"{forbiddenParameters: [5]}": "forbiddenParameters",
+	} {
+		rules := "rules:\n  a:\n    given: $\n    then: {function: mediaTypes, functionOptions: " + options + "}\n"
+		_, err := Validate(context.Background(), []byte(mediaTypesSpec), Options{Source: "api.yaml", Rules: []byte(rules)})
+		if err == nil || rulesExitCode(err) != exitUsage || !strings.Contains(err.Error(), message) {
+			t.Errorf("%s: erro %v, esperado erro de uso com %q", options, err, message)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+// Com --profile-mem, o pico de memória e o volume alocado em cada fase são impressos
+// no fim da execução (em stderr, para não misturar com a saída JSON)
+var profileMem bool
+
+func registerProfileMemFlag(fs *flag.FlagSet) {
+	fs.BoolVar(&profileMem, "profile-mem", false, "imprime ao final o pico de memória e o volume alocado em cada fase")
+}
+
+// Intervalo entre as amostras de memória; o pico é o maior valor amostrado na fase
+const memSampleInterval = 10 * time.Millisecond
+
+// Métricas lidas em cada amostra: memória ocupada por objetos no heap e total alocado
+var memSamples = []metrics.Sample{
+	{Name: "/memory/classes/heap/objects:bytes"},
+	{Name: "/gc/heap/allocs:bytes"},
+}
+
+// Memória de uma fase (index, resolve, serialize...)
+type phaseMemory struct {
+	name     string
+	file     string
+	peak     uint64
+	start    uint64 // total alocado no início da fase
+	alloc    uint64
+	began    time.Time
+	duration time.Duration
+}
+
+type memProfiler struct {
+	mu      sync.Mutex
+	phases  []*phaseMemory
+	samples []metrics.Sample
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+var memProfile = &memProfiler{}
+
+// Inicia uma fase; a amostragem começa na primeira fase
+func (p *memProfiler) enter(phase, file string) {
+	if !profileMem {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.samples == nil {
+		p.samples = append([]metrics.Sample(nil), memSamples...)
+		p.stop, p.done = make(chan struct{}), make(chan struct{})
+		go p.sample()
+	}
+	heap, total := p.read()
+	if current := p.current(); current != nil {
+		current.finish(heap, total)
+	}
+	p.phases = append(p.phases, &phaseMemory{name: phase, file: file, peak: heap, start: total, began: time.Now()})
+}
+
+func (p *memProfiler) sample() {
+	defer close(p.done)
+	ticker := time.NewTicker(memSampleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			heap, _ := p.read()
+			if current := p.current(); current != nil && heap > current.peak {
+				current.peak = heap
+			}
+			p.mu.Unlock()
+		}
+	}
+}
+
+// Lê as métricas; chamado com p.mu travado
+func (p *memProfiler) read() (heap, total uint64) {
+	metrics.Read(p.samples)
+	return p.samples[0].Value.Uint64(), p.samples[1].Value.Uint64()
+}
+
+func (p *memProfiler) current() *phaseMemory {
+	if len(p.phases) == 0 {
+		return nil
+	}
+	return p.phases[len(p.phases)-1]
+}
+
+func (m *phaseMemory) finish(heap, total uint64) {
+	if heap > m.peak {
+		m.peak = heap
+	}
+	m.alloc = total - m.start
+	m.duration = time.Since(m.began)
+}
+
+// Encerra a amostragem e imprime a memória de cada fase
+func (p *memProfiler) report() {
+	p.mu.Lock()
+	if p.samples == nil {
+		p.mu.Unlock()
+		return
+	}
+	heap, total := p.read()
+	if current := p.current(); current != nil {
+		current.finish(heap, total)
+	}
+	p.mu.Unlock()
+	close(p.stop)
+	<-p.done
+
+	// A próxima execução no mesmo processo (Run, testes) começa do zero
+	p.mu.Lock()
+	phases := p.phases
+	p.phases, p.samples = nil, nil
+	p.mu.Unlock()
+
+	var peak uint64
+	fmt.Fprintln(stderr, "📊 Memória por fase (--profile-mem):")
+	for _, m := range phases {
+		fmt.Fprintf(stderr, "   %-10s pico %9s, alocados %9s, %6s  %s\n", m.name, formatBytes(int64(m.peak)), formatBytes(int64(m.alloc)), m.duration.Round(time.Millisecond), reportPath(m.file))
+		if m.peak > peak {
+			peak = m.peak
+		}
+	}
+	fmt.Fprintf(stderr, "   pico geral: %s\n", formatBytes(int64(peak)))
+}
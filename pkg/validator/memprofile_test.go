@@ -0,0 +1,117 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/metrics"
+	"strings"
+	"testing"
+	"time"
+)
+
+// --profile-mem lista as fases da execução com o pico e o volume alocado; uma segunda
+// execução no mesmo processo começa do zero
+func TestProfileMem(t *testing.T) {
+	spec := filepath.Join("testdata", "e2e", "multi", "api.yaml")
+	output := filepath.Join(t.TempDir(), "resolvido.yaml")
+	for i := 0; i < 2; i++ {
+		var out, errOut bytes.Buffer
+		if code := Run([]string{"resolve", "--no-cache", "--profile-mem", "-o", output, spec}, &out, &errOut); code != exitOK {
+			t.Fatalf("execução %d: exit %d\n%s%s", i+1, code, out.String(), errOut.String())
+		}
+		report := errOut.String()
+		if !strings.Contains(report, "📊 Memória por fase (--profile-mem):") || !strings.Contains(report, "pico geral:") {
+			t.Fatalf("execução %d sem o relatório de memória:\n%s", i+1, report)
+		}
+		for _, phase := range []string{"index", "resolve", "serialize"} {
+			if n := strings.Count(report, "   "+phase+" "); n != 1 {
+				t.Errorf("execução %d: fase %s aparece %d vezes:\n%s", i+1, phase, n, report)
+			}
+		}
+		if strings.Contains(out.String(), "📊") {
+			t.Errorf("o relatório de memória deveria ir para stderr:\n%s", out.String())
+		}
+	}
+}
+
+// Serialização de uma especificação grande já resolvida: direto no arquivo temporário,
+// como o resolve faz, e em memória, como antes. Além do B/op (quase todo lixo
+// transitório do emissor do yaml.v3), cada variante informa o pico do heap amostrado,
+// em que aparece o buffer do documento inteiro que a gravação direta evita
+// (go test -bench SerializeLarge -benchmem)
+func BenchmarkSerializeLarge(b *testing.B) {
+	dir := b.TempDir()
+	spec := filepath.Join(dir, "api.yaml")
+	if err := os.WriteFile(spec, generateLargeSpec(500), 0o644); err != nil {
+		b.Fatal(err)
+	}
+	s := flagSettings()
+	s.tracker = nil
+	resolved, err := indexAndResolve(context.Background(), s, spec)
+	if err != nil {
+		b.Fatal(err)
+	}
+	output := filepath.Join(dir, "resolvido.yaml")
+	b.Run("arquivo", func(b *testing.B) {
+		reportPeakHeap(b, func() {
+			tmp, _, err := encodeToTemp(output, resolved, formatYAML)
+			if err != nil {
+				b.Fatal(err)
+			}
+			os.Remove(tmp)
+		})
+	})
+	b.Run("memória", func(b *testing.B) {
+		var kept []byte
+		reportPeakHeap(b, func() {
+			data, err := resolved.marshal(formatYAML)
+			if err != nil {
+				b.Fatal(err)
+			}
+			kept = data
+		})
+		_ = kept
+	})
+}
+
+// Executa fn b.N vezes amostrando o heap, como --profile-mem, e informa o maior valor
+// acima do heap do início em pico-heap-B/op
+func reportPeakHeap(b *testing.B, fn func()) {
+	b.ReportAllocs()
+	runtime.GC()
+	samples := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(samples)
+	base := samples[0].Value.Uint64()
+
+	var peak uint64
+	stop, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		local := []metrics.Sample{{Name: samples[0].Name}}
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			metrics.Read(local)
+			if heap := local[0].Value.Uint64(); heap > peak {
+				peak = heap
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		fn()
+	}
+	b.StopTimer()
+	close(stop)
+	<-done
+	if peak > base {
+		b.ReportMetric(float64(peak-base), "pico-heap-B/op")
+	}
+}
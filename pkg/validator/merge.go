@@ -0,0 +1,344 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Flags do subcomando merge
+type mergeOptions struct {
+	output       string
+	outputFormat string
+	indent       int
+	thenValidate bool
+	rules        string
+}
+
+func (o *mergeOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.output, "o", "", "arquivo de saída com o documento combinado (obrigatório)")
+	fs.StringVar(&o.outputFormat, "output-format", "", "formato do documento gerado: yaml ou json (padrão: pela extensão do arquivo de saída)")
+	fs.IntVar(&o.indent, "indent", defaultIndent, "indentação do YAML gerado")
+	fs.BoolVar(&o.thenValidate, "then-validate", false, "valida o documento combinado em seguida, como o subcomando validate")
+	fs.StringVar(&o.rules, "rules", "", "arquivo de regras ou pacote embarcado usado com --then-validate")
+	registerEncodingFlag(fs)
+	registerInputLimitFlag(fs)
+}
+
+var mergeCommand = &command{
+	Name:    "merge",
+	Args:    "-o <saída.yaml> <fragmento.yaml>...",
+	Summary: "combina especificações parciais em um único documento",
+	Description: `Combina os fragmentos (especificações OpenAPI parciais, um por recurso, por
+exemplo) em um único documento gravado em -o: paths, webhooks, cada seção de
+components (schemas, parameters, responses, securitySchemes...), tags e os
+requisitos de security. openapi, info, servers e as demais chaves do nível
+raiz vêm do primeiro fragmento que as tem; os fragmentos que declaram a versão
+precisam declarar a mesma (openapi 3.x ou swagger: "2.0", em que definitions,
+parameters, responses e securityDefinitions do nível raiz também são
+combinados).
+
+Um path, componente ou tag presente em mais de um fragmento com o mesmo
+conteúdo aparece uma vez só; com conteúdo diferente é um conflito, relatado
+com os dois locais (arquivo e linha), e nada é gravado. $refs entre os
+fragmentos passam a ser locais ao documento combinado e os $refs para outros
+arquivos são reescritos a partir do diretório da saída.
+
+Com --then-validate, o documento combinado é validado em seguida, como em
+'validate' (com --rules, se informado), e o código de saída é o da validação.`,
+	Examples: []string{
+		programName + " merge -o swagger.yaml contas.yaml cartoes.yaml comum.yaml",
+		programName + " merge --then-validate -o swagger.yaml fragmentos/*.yaml",
+		programName + " merge --rules pb33f_rules.yaml --then-validate -o openapi.json contas.yaml cartoes.yaml",
+	},
+	Flags: func(fs *flag.FlagSet) { new(mergeOptions).register(fs) },
+	Run:   runMerge,
+}
+
+// Fragmento lido, com o caminho absoluto para reconhecer os $refs entre fragmentos
+type mergeFragment struct {
+	file string
+	abs  string
+	doc  *yaml.Node
+}
+
+// Definição já presente no documento combinado e o fragmento de onde ela veio
+type mergeEntry struct {
+	node   *yaml.Node
+	source string
+	line   int
+}
+
+// Subcomando merge: combina os fragmentos e, com --then-validate, valida o resultado
+func runMerge(c *command, args []string) int {
+	opts := &mergeOptions{}
+	fs := c.newFlagSet()
+	opts.register(fs)
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	if err := checkEncodingFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if fs.NArg() < 2 {
+		return c.usageError("esperados pelo menos dois fragmentos, recebidos %d", fs.NArg())
+	}
+	if opts.output == "" {
+		return c.usageError("merge exige -o com o arquivo de saída")
+	}
+	if opts.indent < 1 {
+		return c.usageError("valor inválido para --indent: %d", opts.indent)
+	}
+	if opts.rules != "" && !opts.thenValidate {
+		return c.usageError("--rules só vale com --then-validate")
+	}
+	format, err := outputFormatFor(opts.output, opts.outputFormat)
+	if err != nil {
+		return c.usageError("%v", err)
+	}
+
+	var fragments []*mergeFragment
+	for _, file := range fs.Args() {
+		data, err := readSpecFile(file)
+		if err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+		root, err := parseSpecDocument(data, file, 0)
+		if err != nil {
+			fmt.Fprintln(stdout, "❌ Erro ao processar", file+":", err)
+			return exitFailure
+		}
+		doc := documentContent(root)
+		if doc == nil || doc.Kind != yaml.MappingNode {
+			fmt.Fprintf(stdout, "❌ %s não é um documento OpenAPI (esperado um objeto no nível raiz)\n", file)
+			return exitFailure
+		}
+		abs, _ := filepath.Abs(file)
+		fragments = append(fragments, &mergeFragment{file: file, abs: abs, doc: doc})
+	}
+	outputAbs, _ := filepath.Abs(opts.output)
+	for _, f := range fragments {
+		rebaseFragmentRefs(f, fragments, filepath.Dir(outputAbs))
+	}
+
+	merged, conflicts := mergeFragments(fragments)
+	if len(conflicts) > 0 {
+		fmt.Fprintf(stdout, "❌ %d conflito(s) entre os fragmentos; nada foi gravado:\n", len(conflicts))
+		for _, conflict := range conflicts {
+			fmt.Fprintln(stdout, "   -", conflict)
+		}
+		return exitFailure
+	}
+	out, err := marshalSpec(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{merged}}, format, opts.indent, nil)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌", err)
+		return exitFailure
+	}
+	if err := os.MkdirAll(filepath.Dir(opts.output), 0755); err != nil {
+		fmt.Fprintf(stdout, "❌ Erro ao criar diretório de %s: %v\n", opts.output, err)
+		return exitFailure
+	}
+	if err := writeFileAtomic(opts.output, out); err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao salvar", opts.output+":", err)
+		return exitFailure
+	}
+	fmt.Fprintf(stdout, "✅ %d fragmentos combinados em: %s\n", len(fragments), opts.output)
+
+	if !opts.thenValidate {
+		return exitOK
+	}
+	validateArgs := []string{opts.output}
+	if opts.rules != "" {
+		validateArgs = append([]string{"--rules", opts.rules}, validateArgs...)
+	}
+	return validateCommand.Run(validateCommand, validateArgs)
+}
+
+// Função para reescrever os $refs do fragmento: os que apontam para outro fragmento
+// viram locais (#/components/...) e os relativos a outros arquivos passam a partir do
+// diretório da saída
+func rebaseFragmentRefs(f *mergeFragment, fragments []*mergeFragment, outputDir string) {
+	forEachRef(f.doc, func(ref *yaml.Node, _ string) {
+		file, pointer := splitRef(ref.Value)
+		if file == "" || isRemoteURL(file) {
+			return
+		}
+		target := filepath.Join(filepath.Dir(f.abs), filepath.FromSlash(file))
+		for _, other := range fragments {
+			if other.abs == target {
+				ref.Value = "#" + pointer
+				return
+			}
+		}
+		if rel, err := filepath.Rel(outputDir, target); err == nil {
+			ref.Value = filepath.ToSlash(rel)
+			if pointer != "" {
+				ref.Value += "#" + pointer
+			}
+		}
+	})
+}
+
+// Função para combinar os fragmentos sobre uma cópia do primeiro; retorna os
+// conflitos (mesma chave com conteúdo diferente) em vez de escolher um dos lados
+func mergeFragments(fragments []*mergeFragment) (*yaml.Node, []string) {
+	first := fragments[0]
+	merged := deepCopyNode(first.doc)
+	version := func(doc *yaml.Node) string {
+		if v := mappingValue(doc, "openapi"); v != nil {
+			return "openapi " + v.Value
+		}
+		if v := mappingValue(doc, "swagger"); v != nil {
+			return "swagger " + v.Value
+		}
+		return "sem versão"
+	}
+	var conflicts []string
+	versioned := first
+	for _, f := range fragments[1:] {
+		switch {
+		case version(f.doc) == "sem versão":
+		case version(versioned.doc) == "sem versão":
+			versioned = f
+		case version(f.doc) != version(versioned.doc):
+			conflicts = append(conflicts, fmt.Sprintf("%s usa %s, mas %s usa %s", f.file, version(f.doc), versioned.file, version(versioned.doc)))
+		}
+	}
+	swagger2 := mappingValue(versioned.doc, "swagger") != nil
+
+	// Seções com entradas por nome: paths e, conforme a versão, as de components
+	sections := [][]string{{"paths"}, {"webhooks"}}
+	if swagger2 {
+		sections = append(sections, []string{"definitions"}, []string{"parameters"}, []string{"responses"}, []string{"securityDefinitions"})
+	} else {
+		var kinds []string
+		seen := map[string]bool{}
+		for _, f := range fragments {
+			forEachEntry(mappingValue(f.doc, "components"), func(kind string, node *yaml.Node) {
+				if !seen[kind] && !strings.HasPrefix(kind, "x-") && node.Kind == yaml.MappingNode {
+					seen[kind] = true
+					kinds = append(kinds, kind)
+				}
+			})
+		}
+		for _, kind := range kinds {
+			sections = append(sections, []string{"components", kind})
+		}
+	}
+	for _, section := range sections {
+		label := strings.Join(section, ".")
+		entries := map[string]mergeEntry{}
+		lookup := func(doc *yaml.Node) *yaml.Node {
+			for _, key := range section {
+				doc = mappingValue(doc, key)
+			}
+			return doc
+		}
+		target := lookup(merged)
+		if target != nil && target.Kind != yaml.MappingNode {
+			// Seção vazia (paths: sem valor) no primeiro fragmento
+			target.Kind, target.Tag, target.Value = yaml.MappingNode, "!!map", ""
+		}
+		if target != nil {
+			for i := 0; i+1 < len(target.Content); i += 2 {
+				key := target.Content[i]
+				entries[key.Value] = mergeEntry{node: target.Content[i+1], source: first.file, line: key.Line}
+			}
+		}
+		for _, f := range fragments[1:] {
+			source := lookup(f.doc)
+			if source == nil || source.Kind != yaml.MappingNode {
+				continue
+			}
+			for i := 0; i+1 < len(source.Content); i += 2 {
+				key, node := source.Content[i], source.Content[i+1]
+				if existing, ok := entries[key.Value]; ok {
+					if !nodesEqual(existing.node, node) {
+						conflicts = append(conflicts, fmt.Sprintf("%s %s: definido em %s:%d e, com outro conteúdo, em %s:%d", label, key.Value, existing.source, existing.line, f.file, key.Line))
+					}
+					continue
+				}
+				if target == nil {
+					target = merged
+					for _, k := range section {
+						target = ensureMapping(target, k)
+					}
+				}
+				entries[key.Value] = mergeEntry{node: node, source: f.file, line: key.Line}
+				target.Content = append(target.Content, deepCopyNode(key), deepCopyNode(node))
+			}
+		}
+	}
+
+	// Tags pelo nome e requisitos de security sem repetição
+	tags := map[string]mergeEntry{}
+	forEachItem := func(doc *yaml.Node, key string, visit func(item *yaml.Node)) {
+		if list := mappingValue(doc, key); list != nil && list.Kind == yaml.SequenceNode {
+			for _, item := range list.Content {
+				visit(item)
+			}
+		}
+	}
+	forEachItem(merged, "tags", func(tag *yaml.Node) {
+		if name := mappingValue(tag, "name"); name != nil {
+			tags[name.Value] = mergeEntry{node: tag, source: first.file, line: tag.Line}
+		}
+	})
+	for _, f := range fragments[1:] {
+		forEachItem(f.doc, "tags", func(tag *yaml.Node) {
+			name := mappingValue(tag, "name")
+			if name == nil {
+				return
+			}
+			if existing, ok := tags[name.Value]; ok {
+				if !nodesEqual(existing.node, tag) {
+					conflicts = append(conflicts, fmt.Sprintf("tag %s: definida em %s:%d e, com outro conteúdo, em %s:%d", name.Value, existing.source, existing.line, f.file, tag.Line))
+				}
+				return
+			}
+			tags[name.Value] = mergeEntry{node: tag, source: f.file, line: tag.Line}
+			appendItem(merged, "tags", deepCopyNode(tag))
+		})
+		forEachItem(f.doc, "security", func(requirement *yaml.Node) {
+			present := false
+			forEachItem(merged, "security", func(existing *yaml.Node) {
+				present = present || nodesEqual(existing, requirement)
+			})
+			if !present {
+				appendItem(merged, "security", deepCopyNode(requirement))
+			}
+		})
+	}
+
+	// As demais chaves do nível raiz (openapi, info, servers...) vêm do primeiro
+	// fragmento que as tem
+	combined := map[string]bool{"paths": true, "webhooks": true, "components": true, "tags": true, "security": true}
+	if swagger2 {
+		for _, key := range []string{"definitions", "parameters", "responses", "securityDefinitions"} {
+			combined[key] = true
+		}
+	}
+	for _, f := range fragments[1:] {
+		forEachEntry(f.doc, func(key string, node *yaml.Node) {
+			if !combined[key] && mappingValue(merged, key) == nil {
+				merged.Content = append(merged.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, deepCopyNode(node))
+			}
+		})
+	}
+	return merged, conflicts
+}
+
+// Acrescenta o item à lista da chave, criando a lista quando ela não existe
+func appendItem(node *yaml.Node, key string, item *yaml.Node) {
+	list := mappingValue(node, key)
+	if list == nil || list.Kind != yaml.SequenceNode {
+		list = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, list)
+	}
+	list.Content = append(list.Content, item)
+}
@@ -0,0 +1,103 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Função para gravar os fragmentos no diretório e executar o merge com a saída em
+// saida/api.yaml, retornando o código e a saída padrão
+func runMergeCommand(t *testing.T, dir string, fragments map[string]string, names ...string) (int, string) {
+	t.Helper()
+	writeFiles(t, dir, fragments)
+	args := []string{"merge", "-o", filepath.Join(dir, "saida", "api.yaml")}
+	for _, name := range names {
+		args = append(args, filepath.Join(dir, name))
+	}
+	return runCommand(t, args...)
+}
+
+const mergeContas = `openapi: 3.0.3
+info: {title: Open Finance, version: 1.0.0}
+tags:
+  - {name: contas}
+paths:
+  /contas:
+    get:
+      tags: [contas]
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: 'comum.yaml#/components/schemas/Erro'}
+        '500':
+          description: erro
+          content:
+            application/json:
+              schema: {$ref: 'externo/pix.yaml#/Pix'}
+`
+
+const mergeComum = `openapi: 3.0.3
+info: {title: Comum, version: 9.9.9}
+servers:
+  - url: https://api.exemplo.com.br
+tags:
+  - {name: contas}
+paths: {}
+components:
+  schemas:
+    Erro: {type: object}
+`
+
+// Os fragmentos são combinados: os $refs entre eles ficam locais, os $refs para outros
+// arquivos passam a partir do diretório da saída, entradas iguais aparecem uma vez e
+// as chaves do nível raiz vêm do primeiro fragmento que as tem
+func TestMerge(t *testing.T) {
+	dir := t.TempDir()
+	code, out := runMergeCommand(t, dir, map[string]string{"contas.yaml": mergeContas, "comum.yaml": mergeComum}, "contas.yaml", "comum.yaml")
+	if code != exitOK {
+		t.Fatalf("código %d\n%s", code, out)
+	}
+	data := mustReadFile(t, filepath.Join(dir, "saida", "api.yaml"))
+	var merged struct {
+		Info    map[string]string
+		Servers []map[string]string
+		Tags    []map[string]string
+	}
+	if err := yaml.Unmarshal(data, &merged); err != nil {
+		t.Fatal(err)
+	}
+	if merged.Info["title"] != "Open Finance" || len(merged.Servers) != 1 || len(merged.Tags) != 1 {
+		t.Errorf("nível raiz inesperado: %+v", merged)
+	}
+	for _, want := range []string{"$ref: '#/components/schemas/Erro'", "$ref: '../externo/pix.yaml#/Pix'", "Erro:"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("documento combinado sem %q:\n%s", want, data)
+		}
+	}
+}
+
+// Entradas com o mesmo nome e conteúdo diferente são conflitos, com os dois locais, e
+// nada é gravado
+func TestMergeConflicts(t *testing.T) {
+	dir := t.TempDir()
+	cartoes := strings.Replace(mergeComum, "Erro: {type: object}", "Erro: {type: string}", 1)
+	cartoes = strings.Replace(cartoes, "openapi: 3.0.3", "openapi: 3.1.0", 1)
+	code, out := runMergeCommand(t, dir, map[string]string{"comum.yaml": mergeComum, "cartoes.yaml": cartoes}, "comum.yaml", "cartoes.yaml")
+	if code != exitFailure {
+		t.Fatalf("código %d, esperado %d\n%s", code, exitFailure, out)
+	}
+	for _, want := range []string{"2 conflito(s)", "usa openapi 3.1.0, mas", "components.schemas Erro: definido em " + filepath.Join(dir, "comum.yaml") + ":10 e, com outro conteúdo, em " + filepath.Join(dir, "cartoes.yaml") + ":10"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("saída sem %q:\n%s", want, out)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "saida", "api.yaml")); !os.IsNotExist(err) {
+		t.Errorf("com conflitos, nada deveria ser gravado: %v", err)
+	}
+}
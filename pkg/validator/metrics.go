@@ -0,0 +1,174 @@
+package validator
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limites dos histogramas de duração, em segundos (os mesmos do cliente Prometheus)
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Métricas do servidor (serve), no formato de texto do Prometheus. Cada servidor tem o
+// seu registro: nada é registrado em estado global, e quem embute o pacote não herda
+// métricas que não pediu.
+type serverMetrics struct {
+	mu         sync.Mutex
+	requests   map[[2]string]float64 // endpoint, status
+	latency    map[string]*histogram // por endpoint
+	validation *histogram
+	violations map[string]float64    // por severidade
+	rulesets   map[[2]string]float64 // pacote, hit ou miss
+}
+
+func newServerMetrics() *serverMetrics {
+	return &serverMetrics{
+		requests:   map[[2]string]float64{},
+		latency:    map[string]*histogram{},
+		validation: newHistogram(),
+		violations: map[string]float64{},
+		rulesets:   map[[2]string]float64{},
+	}
+}
+
+// Registra uma requisição atendida
+func (m *serverMetrics) observeRequest(endpoint string, status int, elapsed time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[[2]string{endpoint, strconv.Itoa(status)}]++
+	h, ok := m.latency[endpoint]
+	if !ok {
+		h = newHistogram()
+		m.latency[endpoint] = h
+	}
+	h.observe(elapsed.Seconds())
+}
+
+// Registra uma validação concluída e as violações encontradas
+func (m *serverMetrics) observeValidation(elapsed time.Duration, violations []Violation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.validation.observe(elapsed.Seconds())
+	for _, v := range violations {
+		m.violations[v.Severity]++
+	}
+}
+
+// Registra a busca de um pacote de regras entre os carregados na inicialização
+func (m *serverMetrics) observeRuleset(name string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rulesets[[2]string{name, result}]++
+}
+
+// Escreve as métricas no formato de exposição de texto do Prometheus
+func (m *serverMetrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	writeHeader(w, "ofb_validator_http_requests_total", "counter", "Requisições atendidas, por endpoint e status HTTP.")
+	for _, key := range sortedPairs(m.requests) {
+		fmt.Fprintf(w, "ofb_validator_http_requests_total{endpoint=%s,status=%s} %s\n", labelValue(key[0]), labelValue(key[1]), formatMetric(m.requests[key]))
+	}
+
+	writeHeader(w, "ofb_validator_http_request_duration_seconds", "histogram", "Duração das requisições, por endpoint.")
+	endpoints := make([]string, 0, len(m.latency))
+	for endpoint := range m.latency {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+	for _, endpoint := range endpoints {
+		m.latency[endpoint].write(w, "ofb_validator_http_request_duration_seconds", "endpoint="+labelValue(endpoint))
+	}
+
+	writeHeader(w, "ofb_validator_validation_duration_seconds", "histogram", "Duração da validação, sem a leitura da requisição e a espera por vaga.")
+	m.validation.write(w, "ofb_validator_validation_duration_seconds", "")
+
+	writeHeader(w, "ofb_validator_violations_total", "counter", "Violações encontradas nas validações, por severidade.")
+	severities := make([]string, 0, len(m.violations))
+	for severity := range m.violations {
+		severities = append(severities, severity)
+	}
+	sort.Strings(severities)
+	for _, severity := range severities {
+		fmt.Fprintf(w, "ofb_validator_violations_total{severity=%s} %s\n", labelValue(severity), formatMetric(m.violations[severity]))
+	}
+
+	writeHeader(w, "ofb_validator_ruleset_lookups_total", "counter", "Buscas de pacotes de regras: hit quando o pacote foi carregado na inicialização.")
+	for _, key := range sortedPairs(m.rulesets) {
+		fmt.Fprintf(w, "ofb_validator_ruleset_lookups_total{ruleset=%s,result=%s} %s\n", labelValue(key[0]), labelValue(key[1]), formatMetric(m.rulesets[key]))
+	}
+}
+
+func writeHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func sortedPairs(values map[[2]string]float64) [][2]string {
+	keys := make([][2]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	return keys
+}
+
+// Valor de um rótulo entre aspas, com os escapes do formato de texto
+func labelValue(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func formatMetric(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Histograma cumulativo com os limites de durationBuckets
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func newHistogram() *histogram {
+	return &histogram{counts: make([]uint64, len(durationBuckets))}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, bound := range durationBuckets {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+func (h *histogram) write(w io.Writer, name, labels string) {
+	prefix := ""
+	if labels != "" {
+		prefix = labels + ","
+	}
+	for i, bound := range durationBuckets {
+		fmt.Fprintf(w, "%s_bucket{%sle=%s} %d\n", name, prefix, labelValue(formatMetric(bound)), h.counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{%sle=\"+Inf\"} %d\n", name, prefix, h.count)
+	suffix := ""
+	if labels != "" {
+		suffix = "{" + labels + "}"
+	}
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, suffix, formatMetric(h.sum))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, suffix, h.count)
+}
@@ -0,0 +1,98 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Seções de components aceitas nas opções da função naming
+var namingKinds = []string{"schemas", "parameters", "responses", "headers", "requestBodies", "securitySchemes", "examples", "links", "callbacks", "pathItems"}
+
+// Padrão de nome de um tipo de componente (functionOptions da função naming)
+type namingPattern struct {
+	kind    string
+	pattern *regexp.Regexp
+}
+
+// Função para ler as opções da função naming: cada chave é uma seção de components e
+// cada valor, a expressão regular que os nomes da seção devem seguir
+//
+//	then:
+//	  function: naming
+//	  functionOptions:
+//	    schemas: "^[A-Z][A-Za-z0-9]*$"
+//	    parameters: "^[a-z][A-Za-z0-9]*$"
+func parseNamingOptions(options map[string]interface{}) ([]namingPattern, []string) {
+	known := map[string]bool{}
+	for _, kind := range namingKinds {
+		known[kind] = true
+	}
+	kinds := make([]string, 0, len(options))
+	for kind := range options {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	var patterns []namingPattern
+	var problems []string
+	for _, kind := range kinds {
+		if !known[kind] {
+			problems = append(problems, fmt.Sprintf("functionOptions.%s não é uma seção de components (use %s)%s", kind, strings.Join(namingKinds, ", "), suggestionSuffix(kind, namingKinds)))
+			continue
+		}
+		expr, ok := options[kind].(string)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("functionOptions.%s deve ser uma expressão regular em texto, encontrado %s", kind, ruleValueType(options[kind])))
+			continue
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("functionOptions.%s %q não é uma expressão regular válida: %v", kind, expr, err))
+			continue
+		}
+		patterns = append(patterns, namingPattern{kind: kind, pattern: re})
+	}
+	return patterns, problems
+}
+
+// Função para conferir os nomes dos componentes do nó selecionado (normalmente
+// $.components): uma violação por nome fora do padrão da seção, na linha do nome
+func (r *compiledRule) namingViolations(components *yaml.Node, path string) []Violation {
+	var violations []Violation
+	for _, p := range r.naming {
+		section := mappingValue(components, p.kind)
+		if section == nil || section.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(section.Content); i += 2 {
+			key := section.Content[i]
+			if p.pattern.MatchString(key.Value) || bundleRenamed(section, key.Value) {
+				continue
+			}
+			v := r.violation(joinPath(joinPath(path, p.kind), key.Value), key.Line, key)
+			v.Message = fmt.Sprintf("%s: %s %q não segue o padrão %s.", strings.TrimSuffix(r.description, "."), p.kind, key.Value, p.pattern)
+			violations = append(violations, v)
+		}
+	}
+	return violations
+}
+
+// Indica se o nome foi criado pelo resolve --bundle em uma colisão: o nome original,
+// presente na mesma seção, seguido de um número a partir de 2 (ver bundler.place).
+// O nome original continua sendo conferido.
+func bundleRenamed(section *yaml.Node, name string) bool {
+	for i := len(name) - 1; i > 0 && name[i] >= '0' && name[i] <= '9'; i-- {
+		suffix := name[i:]
+		if n, err := strconv.Atoi(suffix); err != nil || n < 2 || suffix[0] == '0' {
+			continue
+		}
+		if mappingValue(section, name[:i]) != nil {
+			return true
+		}
+	}
+	return false
+}
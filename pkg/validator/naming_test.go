@@ -0,0 +1,71 @@
+package validator
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const namingRules = `rules:
+  nomes-componentes:
+    given: $.components
+    then:
+      function: naming
+      functionOptions:
+        schemas: "^[A-Z][A-Za-z0-9]*$"
+        parameters: "^[a-z][A-Za-z0-9]*$"
+`
+
+// Cada nome fora do padrão da seção gera uma violação na linha do nome; os nomes
+// criados pelo resolve --bundle em colisões (Conta2) não
+func TestNamingConvention(t *testing.T) {
+	spec := `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    Conta: {type: object}
+    Conta2: {type: object}
+    dados_conta: {type: object}
+  parameters:
+    pagina: {name: page, in: query, schema: {type: integer}}
+    Pagina-Tamanho: {name: page-size, in: query, schema: {type: integer}}
+`
+	found := ruleViolations(t, spec, namingRules, "nomes-componentes")
+	if len(found) != 2 {
+		t.Fatalf("esperadas 2 violações, encontradas %d: %v", len(found), found)
+	}
+	want := map[int]string{8: `schemas "dados_conta"`, 11: `parameters "Pagina-Tamanho"`}
+	for _, v := range found {
+		if !strings.Contains(v.Message, want[v.Line]) {
+			t.Errorf("linha %d: mensagem %q, esperado %q", v.Line, v.Message, want[v.Line])
+		}
+	}
+}
+
+// Opções inválidas da função naming são erro de uso
+func TestNamingOptions(t *testing.T) {
+	tests := []struct {
+		name, options, message string
+	}{
+		{"seção desconhecida", `{schema: "^A"}`, "functionOptions.schema não é uma seção de components"},
+		{"padrão numérico", `{schemas: 5}`, "functionOptions.schemas deve ser uma expressão regular em texto"},
+		{"padrão inválido", `{schemas: "^[A-Z"}`, `functionOptions.schemas "^[A-Z" não é uma expressão regular válida`},
+	}
+	spec := mustReadFile(t, "testdata/e2e/valid/api.yaml")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := "rules:\n  nomes:\n    given: $.components\n    then: {function: naming, functionOptions: " + tt.options + "}\n"
+			_, err := Validate(context.Background(), spec, Options{Source: "api.yaml", Rules: []byte(rules)})
+			if err == nil {
+				t.Fatal("as opções deveriam ser rejeitadas")
+			}
+			if code := rulesExitCode(err); code != exitUsage {
+				t.Errorf("código %d, esperado %d: %v", code, exitUsage, err)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("mensagem sem %q:\n%v", tt.message, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package validator
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Variável de ambiente com o segredo que assina as notificações (cabeçalho X-OFBCI-Signature)
+const notifySecretEnv = "OFBCI_NOTIFY_SECRET"
+
+// Opções de --notify-url
+var (
+	notifyURL       string
+	notifyOn        = "failure"
+	notifyReportURL string
+	notifyRetries   = 3
+)
+
+func registerNotifyFlags(fs *flag.FlagSet) {
+	fs.StringVar(&notifyURL, "notify-url", "", "ao final da execução, envia um resumo em JSON por POST para a URL (ex.: webhook do Teams ou do Slack); assinado com HMAC-SHA256 quando "+notifySecretEnv+" está definida")
+	fs.StringVar(&notifyOn, "notify-on", notifyOn, "quando notificar: failure (violações de error ou falha), breaking ou always")
+	fs.StringVar(&notifyReportURL, "notify-report-url", "", "link do relatório (ex.: artefato do CI) incluído na notificação")
+	fs.IntVar(&notifyRetries, "notify-retries", notifyRetries, "novas tentativas da notificação após falha de rede, 429 ou 5xx (com espera crescente)")
+}
+
+// Confere --notify-on e --notify-url
+func checkNotifyFlags() error {
+	switch notifyOn {
+	case "failure", "always":
+	case "breaking":
+		if notifyURL != "" {
+			fmt.Fprintln(stderr, "⚠️  --notify-on breaking: o validador ainda não compara versões, então nenhuma mudança incompatível é contada e a notificação não é enviada.")
+		}
+	default:
+		return fmt.Errorf("valor inválido para --notify-on: %q (use failure, breaking ou always)", notifyOn)
+	}
+	if notifyURL != "" && !isRemoteURL(notifyURL) {
+		return fmt.Errorf("valor inválido para --notify-url: %q (use uma URL http:// ou https://)", notifyURL)
+	}
+	if notifyRetries < 0 {
+		return fmt.Errorf("valor inválido para --notify-retries: %d", notifyRetries)
+	}
+	return nil
+}
+
+// Conteúdo da notificação
+type notification struct {
+	Repo            string             `json:"repo,omitempty"`
+	Ref             string             `json:"ref,omitempty"`
+	Status          string             `json:"status"` // passed, failed ou error
+	Specs           []notificationSpec `json:"specs"`
+	BreakingChanges *int               `json:"breakingChanges,omitempty"` // sem comparação de versões, ausente
+	Report          string             `json:"report,omitempty"`
+}
+
+// Resultado de uma especificação na notificação
+type notificationSpec struct {
+	Spec       string         `json:"spec"`
+	Version    string         `json:"version,omitempty"`
+	Status     string         `json:"status"`
+	Severities map[string]int `json:"severities"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// Resultado de uma especificação validada, para a notificação
+func notificationFor(spec string, violations []Violation) notificationSpec {
+	entry := notificationSpec{Spec: reportPath(spec), Version: specInfoVersion(spec), Status: "passed", Severities: map[string]int{}}
+	for _, v := range violations {
+		entry.Severities[v.Severity]++
+		if v.IsError() {
+			entry.Status = "failed"
+		}
+	}
+	return entry
+}
+
+// Especificação que não pôde ser validada, para a notificação
+func notificationError(spec, message string) notificationSpec {
+	return notificationSpec{Spec: reportPath(spec), Status: "error", Severities: map[string]int{}, Error: firstLine(message)}
+}
+
+// info.version da especificação, quando legível
+func specInfoVersion(spec string) string {
+	data, err := readSpecFile(spec)
+	if err != nil {
+		return ""
+	}
+	root, err := parseRootSpec(flagSettings(), data, spec)
+	if err != nil {
+		return ""
+	}
+	if version := mappingValue(mappingValue(documentContent(root), "info"), "version"); version != nil {
+		return version.Value
+	}
+	return ""
+}
+
+// Envia a notificação se --notify-url e a política de --notify-on pedirem. Falhas só
+// geram um aviso: a notificação não muda o resultado nem o código de saída.
+func notifyRun(specs []notificationSpec) {
+	if notifyURL == "" {
+		return
+	}
+	n := notification{Repo: os.Getenv("GITHUB_REPOSITORY"), Ref: os.Getenv("GITHUB_REF_NAME"), Status: "passed", Specs: specs, Report: notifyReportURL}
+	for _, s := range specs {
+		switch {
+		case s.Status == "error":
+			n.Status = "error"
+		case s.Status == "failed" && n.Status == "passed":
+			n.Status = "failed"
+		}
+	}
+	switch notifyOn {
+	case "failure":
+		if n.Status == "passed" {
+			return
+		}
+	case "breaking":
+		if n.BreakingChanges == nil || *n.BreakingChanges == 0 {
+			return
+		}
+	}
+	if err := postNotification(n); err != nil {
+		fmt.Fprintln(stderr, "⚠️  Notificação não enviada:", err)
+	}
+}
+
+// POST da notificação, com assinatura e novas tentativas
+func postNotification(n notification) error {
+	body, err := marshalJSON(n, "")
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(http.MethodPost, notifyURL, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("URL de --notify-url inválida")
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", programName)
+		if secret := os.Getenv(notifySecretEnv); secret != "" {
+			mac := hmac.New(sha256.New, []byte(secret))
+			mac.Write(body)
+			req.Header.Set("X-OFBCI-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		}
+
+		resp, err := client.Do(req)
+		retryable := err != nil
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err // a URL do webhook costuma conter o segredo e fica fora do log
+		}
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode < 300 {
+				return nil
+			}
+			retryable = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+			if wait, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && time.Duration(wait)*time.Second > backoff {
+				backoff = time.Duration(wait) * time.Second
+			}
+			err = fmt.Errorf("resposta HTTP %s", resp.Status)
+		}
+		if !retryable || attempt >= notifyRetries {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
@@ -0,0 +1,98 @@
+package validator
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Webhook de mentira: responde com os status da lista, na ordem (o último se repete),
+// e guarda o corpo e a assinatura de cada chamada
+type fakeWebhook struct {
+	mu         sync.Mutex
+	statuses   []int
+	bodies     [][]byte
+	signatures []string
+}
+
+func (f *fakeWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	f.bodies = append(f.bodies, body)
+	f.signatures = append(f.signatures, r.Header.Get("X-OFBCI-Signature"))
+	status := f.statuses[len(f.statuses)-1]
+	if len(f.bodies) <= len(f.statuses) {
+		status = f.statuses[len(f.bodies)-1]
+	}
+	w.WriteHeader(status)
+}
+
+// --notify-url envia o resumo assinado conforme a política de --notify-on, tenta de
+// novo após 5xx e, quando não consegue, só avisa, sem mudar o código de saída
+func TestNotify(t *testing.T) {
+	valid := filepath.Join("testdata", "e2e", "valid", "api.yaml")
+	violations := filepath.Join("testdata", "e2e", "violations", "api.yaml")
+	t.Setenv(notifySecretEnv, "segredo")
+	t.Setenv("GITHUB_REPOSITORY", "dono/api")
+
+	tests := []struct {
+		name     string
+		spec     string
+		args     []string
+		statuses []int
+		code     int
+		calls    int
+		warning  string
+	}{
+		{"falha notificada", violations, nil, []int{http.StatusOK}, exitFailure, 1, ""},
+		{"sucesso não notificado", valid, nil, []int{http.StatusOK}, exitOK, 0, ""},
+		{"sucesso com always", valid, []string{"--notify-on", "always"}, []int{http.StatusOK}, exitOK, 1, ""},
+		{"nova tentativa após 503", violations, []string{"--notify-retries", "1"}, []int{http.StatusServiceUnavailable, http.StatusOK}, exitFailure, 2, ""},
+		{"400 sem nova tentativa", violations, nil, []int{http.StatusBadRequest}, exitFailure, 1, "⚠️  Notificação não enviada: resposta HTTP 400 Bad Request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhook := &fakeWebhook{statuses: tt.statuses}
+			server := httptest.NewServer(webhook)
+			defer server.Close()
+
+			args := append(append([]string{"validate", "--no-cache", "--notify-url", server.URL}, tt.args...), tt.spec)
+			code, out := runCommand(t, args...)
+			if code != tt.code || len(webhook.bodies) != tt.calls {
+				t.Fatalf("código %d e %d chamadas, esperado %d e %d\n%s", code, len(webhook.bodies), tt.code, tt.calls, out)
+			}
+			if tt.warning != "" && !strings.Contains(out, tt.warning) {
+				t.Errorf("saída sem %q:\n%s", tt.warning, out)
+			}
+			if tt.calls == 0 {
+				return
+			}
+
+			body := webhook.bodies[0]
+			mac := hmac.New(sha256.New, []byte("segredo"))
+			mac.Write(body)
+			if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); webhook.signatures[0] != want {
+				t.Errorf("assinatura %q, esperado %q", webhook.signatures[0], want)
+			}
+			var n notification
+			if err := json.Unmarshal(body, &n); err != nil {
+				t.Fatal(err)
+			}
+			if n.Repo != "dono/api" || len(n.Specs) != 1 || n.Specs[0].Spec != reportPath(tt.spec) || n.Specs[0].Version == "" {
+				t.Errorf("notificação %s", body)
+			}
+			if tt.code == exitFailure && (n.Status != "failed" || n.Specs[0].Severities["error"] == 0) {
+				t.Errorf("notificação da falha %s", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package validator
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Métodos HTTP que identificam operações dentro de um path item
+var httpMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+// Uma operação do documento (método + path). Em OpenAPI 3.1, as operações de
+// webhooks usam o nome do webhook como path.
+type operationRef struct {
+	Method  string
+	Path    string
+	Webhook bool
+}
+
+// Path da operação para exibição: webhooks aparecem como "webhook:<nome>"
+func (op operationRef) displayPath() string {
+	if op.Webhook {
+		return "webhook:" + op.Path
+	}
+	return op.Path
+}
+
+// Lista as operações do documento na ordem em que aparecem
+func listOperations(root *yaml.Node) []operationRef {
+	var ops []operationRef
+	for _, section := range []string{"paths", "webhooks"} {
+		pathItems, _ := queryJSONPath(root, "$."+section+"[*]")
+		for _, item := range pathItems {
+			if item.Key == nil || item.Node.Kind != yaml.MappingNode {
+				continue
+			}
+			for i := 0; i+1 < len(item.Node.Content); i += 2 {
+				method := item.Node.Content[i].Value
+				if httpMethods[method] {
+					ops = append(ops, operationRef{Method: method, Path: item.Key.Value, Webhook: section == "webhooks"})
+				}
+			}
+		}
+	}
+	return ops
+}
+
+// Identifica a operação dona de um JSONPath concreto. Caminhos no nível do
+// path item (sem método) retornam o método vazio e valem para todas as suas operações.
+func owningOperation(path string) (operationRef, bool) {
+	segments, _, err := parseJSONPath(strings.TrimSuffix(path, "~"))
+	if err != nil || len(segments) < 2 || (segments[0].name != "paths" && segments[0].name != "webhooks") || segments[1].isIndex {
+		return operationRef{}, false
+	}
+	op := operationRef{Path: segments[1].name, Webhook: segments[0].name == "webhooks"}
+	if len(segments) > 2 && httpMethods[segments[2].name] {
+		op.Method = segments[2].name
+	}
+	return op, true
+}
+
+// Agrupa as violações pela operação em que ocorreram; violações fora de
+// operações ficam de fora do resultado
+func groupViolationsByOperation(root *yaml.Node, violations []Violation) map[operationRef][]Violation {
+	ops := listOperations(root)
+	grouped := map[operationRef][]Violation{}
+	for _, v := range violations {
+		owner, ok := owningOperation(v.JSONPath)
+		if !ok {
+			continue
+		}
+		for _, op := range ops {
+			if op.Path == owner.Path && op.Webhook == owner.Webhook && (owner.Method == "" || owner.Method == op.Method) {
+				grouped[op] = append(grouped[op], v)
+			}
+		}
+	}
+	return grouped
+}
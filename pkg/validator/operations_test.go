@@ -0,0 +1,82 @@
+package validator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const operationsSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas:
+    get:
+      summary: Lista as contas
+      responses:
+        '200': {description: ok}
+    post:
+      responses:
+        '201': {description: criada}
+  /contas/{contaId}:
+    get:
+      summary: Obtém uma conta
+      responses:
+        '200': {description: ok}
+`
+
+// As violações vão para a operação do JSONPath; as do path item valem para todas as
+// operações dele e as de fora de paths ficam de fora
+func TestGroupViolationsByOperation(t *testing.T) {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(operationsSpec), &root); err != nil {
+		t.Fatal(err)
+	}
+	grouped := groupViolationsByOperation(&root, []Violation{
+		{RuleID: "a", JSONPath: "$.paths['/contas'].post.responses['201']"},
+		{RuleID: "b", JSONPath: "$.paths['/contas'].parameters[0]"},
+		{RuleID: "c", JSONPath: "$.info.title"},
+	})
+	count := map[string]int{}
+	for op, violations := range grouped {
+		count[op.Method+" "+op.displayPath()] = len(violations)
+	}
+	want := map[string]int{"get /contas": 1, "post /contas": 2}
+	if len(count) != len(want) || count["get /contas"] != 1 || count["post /contas"] != 2 {
+		t.Errorf("agrupamento %v, esperado %v", count, want)
+	}
+}
+
+// --print-paths imprime uma linha por operação, na ordem do documento, em texto ou
+// JSON lines, e falha quando alguma operação tem violações
+func TestPrintPaths(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"api.yaml": operationsSpec,
+		"regras.yaml": `rules:
+  operacao-com-resumo:
+    description: Toda operação deve ter summary
+    given: $.paths[*].*
+    severity: error
+    then: {field: summary, function: truthy}
+`,
+	})
+	tests := []struct {
+		format, want string
+	}{
+		{"text", "GET /contas PASS\nPOST /contas FAIL\nGET /contas/{contaId} PASS\n"},
+		{"jsonl", `{"method":"GET","path":"/contas","status":"PASS","violations":0}
+{"method":"POST","path":"/contas","status":"FAIL","violations":1}
+{"method":"GET","path":"/contas/{contaId}","status":"PASS","violations":0}
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			code, out := runCommand(t, "validate", "--no-cache", "--print-paths", "--format", tt.format,
+				"--rules", filepath.Join(dir, "regras.yaml"), filepath.Join(dir, "api.yaml"))
+			if code != exitFailure || out != tt.want {
+				t.Errorf("código %d, saída:\n%s\nesperado:\n%s", code, out, tt.want)
+			}
+		})
+	}
+}
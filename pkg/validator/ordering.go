@@ -0,0 +1,208 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Ordens aceitas pela função ordering
+var orderingKinds = []string{"alphabetical", "numeric", "status"}
+
+// Opções da função ordering
+type orderingRequirement struct {
+	order string // alphabetical, numeric ou status (códigos de resposta)
+	by    string // campo que ordena uma lista de objetos (ex.: name nas tags)
+	keys  bool   // confere as chaves do objeto em vez dos itens da lista
+}
+
+// Função para ler as opções da função ordering: a ordem (alphabetical, sem diferenciar
+// maiúsculas; numeric; ou status, a dos códigos de resposta: numérica, com 2XX depois
+// dos 2xx e default no fim), o campo dos itens de uma lista de objetos e se a ordem é a
+// das chaves do objeto selecionado
+//
+//	then:
+//	  function: ordering
+//	  functionOptions:
+//	    order: status
+//	    keys: true
+func parseOrderingOptions(options map[string]interface{}) (*orderingRequirement, []string) {
+	req := &orderingRequirement{order: "alphabetical"}
+	var problems []string
+	if raw, ok := options["order"]; ok {
+		order, isString := raw.(string)
+		known := false
+		for _, kind := range orderingKinds {
+			known = known || order == kind
+		}
+		if !isString || !known {
+			problems = append(problems, fmt.Sprintf("functionOptions.order deve ser %s, encontrado %v%s", strings.Join(orderingKinds, ", "), raw, suggestionSuffix(fmt.Sprint(raw), orderingKinds)))
+		} else {
+			req.order = order
+		}
+	}
+	if raw, ok := options["by"]; ok {
+		if req.by, ok = raw.(string); !ok || req.by == "" {
+			problems = append(problems, fmt.Sprintf("functionOptions.by deve ser o nome de um campo dos itens, encontrado %s", ruleValueType(raw)))
+		}
+	}
+	if raw, ok := options["keys"]; ok {
+		if req.keys, ok = raw.(bool); !ok {
+			problems = append(problems, fmt.Sprintf("functionOptions.keys deve ser true ou false, encontrado %s", ruleValueType(raw)))
+		}
+	}
+	if req.keys && req.by != "" {
+		problems = append(problems, "functionOptions.by não se aplica com keys: true (as chaves são ordenadas pelo próprio nome)")
+	}
+	known := []string{"order", "by", "keys"}
+	var unknown []string
+	for key := range options {
+		if key != "order" && key != "by" && key != "keys" {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		problems = append(problems, fmt.Sprintf("functionOptions.%s desconhecida para a função ordering (use %s)%s", key, strings.Join(known, ", "), suggestionSuffix(key, known)))
+	}
+	return req, problems
+}
+
+// Elemento que participa da ordem: uma chave do objeto ou um item da lista
+type orderedElement struct {
+	label string
+	slot  int // posição no objeto (par chave/valor) ou na lista
+	node  *yaml.Node
+}
+
+// Elementos ordenáveis do nó, na ordem do documento. Ficam de fora as extensões (x-)
+// de um objeto e os itens sem o campo de ordenação, que não mudam de lugar.
+func (req *orderingRequirement) elements(node *yaml.Node) []orderedElement {
+	var elements []orderedElement
+	switch {
+	case node == nil:
+	case req.keys && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if !strings.HasPrefix(key.Value, "x-") {
+				elements = append(elements, orderedElement{label: key.Value, slot: i / 2, node: key})
+			}
+		}
+	case !req.keys && node.Kind == yaml.SequenceNode:
+		for i, item := range node.Content {
+			value := item
+			if req.by != "" {
+				value = mappingValue(item, req.by)
+			}
+			if value != nil && value.Kind == yaml.ScalarNode {
+				elements = append(elements, orderedElement{label: value.Value, slot: i, node: item})
+			}
+		}
+	}
+	return elements
+}
+
+// Indica se a vem antes de b na ordem configurada; elementos equivalentes não mudam de lugar
+func (req *orderingRequirement) less(a, b string) bool {
+	switch req.order {
+	case "numeric":
+		x, errA := strconv.ParseFloat(a, 64)
+		y, errB := strconv.ParseFloat(b, 64)
+		switch {
+		case errA == nil && errB == nil:
+			return x < y
+		case errA == nil || errB == nil:
+			// Os números vêm antes dos textos
+			return errA == nil
+		}
+	case "status":
+		return statusRank(a) < statusRank(b)
+	}
+	return strings.ToLower(a) < strings.ToLower(b)
+}
+
+// Posição de um código de resposta na ordem: 200 < 201 < 2XX < 300 ... < default
+func statusRank(code string) int {
+	upper := strings.ToUpper(code)
+	if len(upper) == 3 && upper[0] >= '1' && upper[0] <= '5' && upper[1:] == "XX" {
+		return int(upper[0]-'0')*1000 + 999
+	}
+	if n, err := strconv.Atoi(code); err == nil {
+		return n * 10
+	}
+	return 1 << 30
+}
+
+// Primeiro elemento fora da posição que ocuparia na ordem, com a posição (a partir de
+// 1) em que deveria estar e os elementos ordenados; falso quando a ordem está correta
+func (req *orderingRequirement) firstOutOfOrder(elements []orderedElement) (int, int, []orderedElement, bool) {
+	sorted := req.sorted(elements)
+	for i := range elements {
+		if sorted[i].slot == elements[i].slot {
+			continue
+		}
+		for pos, e := range sorted {
+			if e.slot == elements[i].slot {
+				return i, pos + 1, sorted, true
+			}
+		}
+	}
+	return 0, 0, nil, false
+}
+
+func (req *orderingRequirement) sorted(elements []orderedElement) []orderedElement {
+	sorted := append([]orderedElement(nil), elements...)
+	sort.SliceStable(sorted, func(i, j int) bool { return req.less(sorted[i].label, sorted[j].label) })
+	return sorted
+}
+
+// Função para conferir a ordem do nó selecionado: uma violação no primeiro elemento
+// fora de ordem, com a posição atual e a esperada entre os elementos ordenáveis e o
+// vizinho que ele teria nessa posição
+func (r *compiledRule) orderingViolations(node *yaml.Node, path string) []Violation {
+	elements := r.ordering.elements(node)
+	i, expected, sorted, found := r.ordering.firstOutOfOrder(elements)
+	if !found {
+		return nil
+	}
+	neighbour := "depois de " + sorted[expected-2].label
+	if expected < len(sorted) {
+		neighbour = "antes de " + sorted[expected].label
+	}
+	e := elements[i]
+	elementPath := fmt.Sprintf("%s[%d]", path, e.slot)
+	if r.ordering.keys {
+		elementPath = joinPath(path, e.label)
+	}
+	v := r.violation(elementPath, e.node.Line, e.node)
+	v.Message = fmt.Sprintf("%s: %s está na posição %d e deveria estar na posição %d, %s.", strings.TrimSuffix(r.description, "."), e.label, i+1, expected, neighbour)
+	return []Violation{v}
+}
+
+// Correção das regras com a função ordering: os elementos ordenáveis são reordenados
+// nas posições que já ocupavam, e as extensões e os itens sem o campo de ordenação
+// ficam onde estão. Os comentários acompanham os nós.
+func fixOrdering(node *yaml.Node, field string, options map[string]interface{}) bool {
+	target := fixTarget(node, field)
+	req, problems := parseOrderingOptions(options)
+	if target == nil || len(problems) > 0 {
+		return false
+	}
+	elements := req.elements(target)
+	if _, _, _, found := req.firstOutOfOrder(elements); !found {
+		return false
+	}
+	content := append([]*yaml.Node(nil), target.Content...)
+	for k, e := range req.sorted(elements) {
+		slot := elements[k].slot
+		if req.keys {
+			target.Content[2*slot], target.Content[2*slot+1] = content[2*e.slot], content[2*e.slot+1]
+			continue
+		}
+		target.Content[slot] = content[e.slot]
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const orderingSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+tags:
+  - {name: contas}
+  - {name: Cartoes}
+  - {name: pix}
+paths:
+  /contas:
+    get:
+      responses:
+        '200': {description: ok}
+        default: {description: erro}
+        '4XX': {description: erro}
+        '404': {description: não encontrado}
+`
+
+// A primeira chave ou item fora de ordem é relatado com a posição atual e a esperada
+func TestOrdering(t *testing.T) {
+	rules := "rules:\n  respostas:\n    given: $.paths[*][*].responses\n    then: {function: ordering, functionOptions: {order: status, keys: true}}\n" +
+		"  tags:\n    given: $.tags\n    then: {function: ordering, functionOptions: {by: name}}\n"
+	found := ruleViolations(t, orderingSpec, rules, "respostas")
+	if len(found) != 1 || !strings.Contains(found[0].Message, "default está na posição 2 e deveria estar na posição 4, depois de 4XX") || found[0].Line != 12 {
+		t.Errorf("respostas: %v", found)
+	}
+	found = ruleViolations(t, orderingSpec, rules, "tags")
+	if len(found) != 1 || !strings.Contains(found[0].Message, "contas está na posição 1 e deveria estar na posição 2, antes de pix") || found[0].JSONPath != "$.tags[0]" {
+		t.Errorf("tags: %v", found)
+	}
+}
+
+func TestOrderingLess(t *testing.T) {
+	for _, tt := range []struct {
+		order, a, b string
+		want        bool
+	}{
+		{"alphabetical", "Cartoes", "contas", true},
+		{"numeric", "2", "10", true},
+		{"numeric", "10", "abc", true},
+		{"numeric", "abc", "10", false},
+		{"status", "299", "2XX", true},
+		{"status", "2xx", "300", true},
+		{"status", "500", "default", true},
+	} {
+		req := &orderingRequirement{order: tt.order}
+		if got := req.less(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s: less(%q, %q) = %v, esperado %v", tt.order, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// A correção reordena os elementos nas posições que já ocupavam; as extensões ficam
+// onde estão
+func TestFixOrdering(t *testing.T) {
+	root := mustParseYAML(t, "responses:\n  '404': {description: a}\n  x-nota: fica\n  '200': {description: b}\n  default: {description: c}\n")
+	if !fixOrdering(documentContent(root), "responses", map[string]interface{}{"order": "status", "keys": true}) {
+		t.Fatal("a correção deveria ser aplicada")
+	}
+	out, err := yaml.Marshal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "responses:\n    '200': {description: b}\n    x-nota: fica\n    '404': {description: a}\n    default: {description: c}\n"
+	if string(out) != want {
+		t.Errorf("resultado:\n%s\nesperado:\n%s", out, want)
+	}
+	if fixOrdering(documentContent(root), "responses", map[string]interface{}{"order": "status", "keys": true}) {
+		t.Error("em ordem, nada deveria ser corrigido")
+	}
+}
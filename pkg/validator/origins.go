@@ -0,0 +1,206 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pb33f/libopenapi/index"
+	"gopkg.in/yaml.v3"
+)
+
+// Função para registrar o arquivo de origem de cada nó dos arquivos referenciados,
+// antes da resolução. O resolver troca o conteúdo de cada $ref pelos nós do destino,
+// sem copiá-los, e por isso um nó do documento resolvido que veio de outro arquivo
+// continua sendo o mesmo nó da árvore daquele arquivo. Os nós da raiz não entram.
+// Além da árvore de cada índice, entram os destinos das referências: um arquivo
+// alcançado por outro referenciado (a → b → c) pode ser lido de novo pelo rolodex
+// enquanto o índice dele ainda não existe, e o resolver usa então essa outra árvore.
+func recordNodeOrigins(inputFile string, rolodex *index.Rolodex) map[*yaml.Node]string {
+	origins := map[*yaml.Node]string{}
+	rootAbs, _ := filepath.Abs(inputFile)
+	var visit func(node *yaml.Node, file string)
+	visit = func(node *yaml.Node, file string) {
+		if node == nil {
+			return
+		}
+		if _, seen := origins[node]; seen {
+			return
+		}
+		origins[node] = file
+		for _, child := range node.Content {
+			visit(child, file)
+		}
+	}
+	indexes := rolodex.GetIndexes()
+	for _, idx := range indexes {
+		if file := idx.GetSpecAbsolutePath(); file != "" && file != rootAbs {
+			visit(idx.GetRootNode(), file)
+		}
+	}
+	if root := rolodex.GetRootIndex(); root != nil {
+		indexes = append(indexes, root)
+	}
+	for _, idx := range indexes {
+		for _, ref := range idx.GetMappedReferences() {
+			file := ref.RemoteLocation
+			if file == "" {
+				file, _, _ = strings.Cut(ref.FullDefinition, "#")
+			}
+			if file != "" && file != rootAbs {
+				visit(ref.Node, file)
+			}
+		}
+	}
+	return origins
+}
+
+// Destino de um $ref de um arquivo referenciado: o nó e o arquivo da definição
+type refDefinition struct {
+	file string
+	node *yaml.Node
+}
+
+// Função para registrar, antes da resolução, o destino de cada $ref (o nó mapeado pelo
+// índice para a FullDefinition da referência). O resolver troca só o conteúdo do nó do
+// $ref: ele continua com a linha e a origem do arquivo que referencia, e é pelo destino
+// que a violação chega ao arquivo em que o schema foi escrito.
+func recordRefTargets(rolodex *index.Rolodex) map[*yaml.Node]refDefinition {
+	targets := map[*yaml.Node]refDefinition{}
+	indexes := rolodex.GetIndexes()
+	if root := rolodex.GetRootIndex(); root != nil {
+		indexes = append(indexes, root)
+	}
+	for _, idx := range indexes {
+		mapped := idx.GetMappedReferences()
+		for _, ref := range idx.GetRawReferencesSequenced() {
+			target := mapped[ref.FullDefinition]
+			if ref.Node == nil || target == nil || target.Node == nil || target.Node == ref.Node {
+				continue
+			}
+			file := target.RemoteLocation
+			if file == "" {
+				file, _, _ = strings.Cut(target.FullDefinition, "#")
+			}
+			targets[ref.Node] = refDefinition{file: file, node: target.Node}
+		}
+	}
+	return targets
+}
+
+// Destino final de um nó que o resolver substituiu (seguindo $refs para $refs); ok é
+// falso para os demais, inclusive os $refs mantidos (ciclos, --keep-refs)
+func (s *resolvedSpec) replacedTarget(node *yaml.Node) (target refDefinition, ok bool) {
+	if mappingValue(node, "$ref") != nil {
+		return target, false
+	}
+	for i := 0; i < 16; i++ {
+		next, found := s.refTargets[node]
+		if !found {
+			break
+		}
+		target, ok, node = next, true, next.node
+	}
+	return target, ok
+}
+
+// Função para apontar as violações das análises do documento resolvido para o arquivo
+// em que o nó foi escrito: o nó é localizado pelo JSONPath e, quando veio de um arquivo
+// referenciado (direta ou indiretamente), a violação recebe o arquivo e a coluna. As
+// demais continuam na especificação de entrada. Um nó que o resolver substituiu é
+// apontado para a definição do destino; as violações que assim passam a ser a mesma
+// (um schema referenciado de vários lugares) ficam uma vez só.
+func (s *resolvedSpec) attributeOrigins(violations []Violation) []Violation {
+	if len(s.origins) == 0 {
+		return violations
+	}
+	rootAbs, _ := filepath.Abs(filepath.FromSlash(s.report.File))
+	seen := map[string]bool{}
+	kept := violations[:0]
+	for _, v := range violations {
+		if v.File == "" && v.JSONPath != "" {
+			v = s.attributeOrigin(v, rootAbs)
+		}
+		if v.File != "" {
+			key := fmt.Sprintf("%s\x00%s\x00%d\x00%d\x00%s", v.RuleID, v.File, v.Line, v.Column, v.Message)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		kept = append(kept, v)
+	}
+	return kept
+}
+
+// Arquivo, linha e coluna de uma violação, pelo nó do JSONPath
+func (s *resolvedSpec) attributeOrigin(v Violation, rootAbs string) Violation {
+	path := strings.TrimSuffix(v.JSONPath, "~")
+	matches, err := queryJSONPath(&s.rootNode, path)
+	if err == nil && len(matches) == 0 && path == v.JSONPath {
+		// Campo ausente (then.field): a violação fica no nó que deveria tê-lo
+		if parent := parentJSONPath(path); parent != "" {
+			matches, err = queryJSONPath(&s.rootNode, parent)
+		}
+	}
+	if err != nil || len(matches) == 0 {
+		return v
+	}
+	node := matches[0].Node
+	if path != v.JSONPath && matches[0].Key != nil {
+		node = matches[0].Key
+	}
+	line := node.Line
+	file, ok := s.origins[node]
+	if target, replaced := s.replacedTarget(node); replaced && target.file != rootAbs {
+		node, file, ok = target.node, target.file, true
+	}
+	if !ok {
+		return v
+	}
+	v.File = reportPath(file)
+	if v.Line == 0 || v.Line == line {
+		v.Line, v.Column = node.Line, node.Column
+	}
+	return v
+}
+
+// Caminho do nó pai de um caminho concreto ($.a.b, $.a['/b'] ou $.a[0]); vazio na raiz
+func parentJSONPath(path string) string {
+	cut := strings.LastIndex(path, ".")
+	switch {
+	case strings.HasSuffix(path, "']"):
+		cut = strings.LastIndex(path, "['")
+	case strings.HasSuffix(path, "]"):
+		cut = strings.LastIndex(path, "[")
+	}
+	if cut <= 0 {
+		return ""
+	}
+	return path[:cut]
+}
+
+// As regras avaliam a árvore de entrada, sem seguir os $refs, e por isso não veem os
+// nós escritos nos arquivos referenciados. Esses nós são avaliados no documento
+// resolvido: só entram as violações de nós vindos de outro arquivo, com o arquivo de
+// origem, e cada uma uma vez só, mesmo quando o arquivo é referenciado de vários lugares.
+func referencedRuleViolations(ctx context.Context, s *runSettings, spec *resolvedSpec, names []string, rules map[string]interface{}) []Violation {
+	if len(spec.origins) == 0 {
+		return nil
+	}
+	outcomes, _ := evaluateRules(ctx, s, &spec.rootNode, names, rules)
+	var found []Violation
+	seen := map[string]bool{}
+	for _, outcome := range outcomes {
+		for _, v := range spec.attributeOrigins(outcome.violations) {
+			key := fmt.Sprintf("%s\x00%s\x00%d\x00%d\x00%s", v.RuleID, v.File, v.Line, v.Column, v.Message)
+			if v.File == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			found = append(found, v)
+		}
+	}
+	return found
+}
@@ -0,0 +1,123 @@
+package validator
+
+import (
+	"context"
+	"testing"
+)
+
+// Uma violação em um nó escrito dois arquivos depois da especificação de entrada
+// (api.yaml → schemas/conta.yaml → schemas/comum/endereco.yaml) é relatada no arquivo
+// e na linha em que o nó foi escrito, uma vez só
+func TestViolationTwoFilesAway(t *testing.T) {
+	files := MemFS{
+		"api.yaml": []byte(`openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas:
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: './schemas/conta.yaml#/Conta'}
+  /contas/{contaId}:
+    get:
+      parameters:
+        - {name: contaId, in: path, required: true, schema: {type: string}}
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: './schemas/conta.yaml#/Conta'}
+`),
+		"schemas/conta.yaml": []byte(`Conta:
+  type: object
+  description: Conta
+  properties:
+    endereco:
+      $ref: './comum/endereco.yaml#/Endereco'
+`),
+		"schemas/comum/endereco.yaml": []byte(`Endereco:
+  type: object
+  description: Endereço
+  properties:
+    rua:
+      type: string
+`),
+	}
+	rules := []byte(`rules:
+  propriedade-com-descricao:
+    description: As propriedades devem ter descrição
+    severity: error
+    given: $..properties[*]
+    then: {field: description, function: truthy}
+`)
+	result, err := Validate(context.Background(), nil, Options{Source: "api.yaml", FS: files, Rules: rules})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found []Violation
+	for _, v := range result.Violations {
+		if v.RuleID == "propriedade-com-descricao" {
+			found = append(found, v)
+		}
+	}
+	if len(found) != 1 {
+		t.Fatalf("esperada uma violação, encontradas %d: %+v", len(found), found)
+	}
+	if v := found[0]; v.File != "schemas/comum/endereco.yaml" || v.Line != 6 || v.Column != 7 {
+		t.Errorf("violação em %s:%d:%d, esperado schemas/comum/endereco.yaml:6:7", v.File, v.Line, v.Column)
+	}
+}
+
+// Uma violação das análises do documento resolvido (allOf impossível) em um schema
+// escrito dois arquivos depois da entrada aponta para a definição, e não para o $ref
+// que a alcança, uma vez só mesmo com o schema referenciado de dois lugares
+func TestAnalysisViolationTwoFilesAway(t *testing.T) {
+	files := MemFS{
+		"api.yaml": []byte(`openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas:
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: './schemas/conta.yaml#/Conta'}
+`),
+		"schemas/conta.yaml": []byte(`Conta:
+  type: object
+  properties:
+    endereco:
+      $ref: './comum/endereco.yaml#/Endereco'
+    cobranca:
+      $ref: './comum/endereco.yaml#/Endereco'
+`),
+		"schemas/comum/endereco.yaml": []byte(`Endereco:
+  type: object
+  allOf:
+    - type: object
+    - type: string
+`),
+	}
+	result, err := Validate(context.Background(), nil, Options{Source: "api.yaml", FS: files, Rules: []byte("rules: {}\n")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found []Violation
+	for _, v := range result.Violations {
+		if v.RuleID == "allof-satisfiable" {
+			found = append(found, v)
+		}
+	}
+	if len(found) != 1 {
+		t.Fatalf("esperada uma violação, encontradas %d: %+v", len(found), found)
+	}
+	if v := found[0]; v.File != "schemas/comum/endereco.yaml" || v.Line != 2 || v.Column != 3 {
+		t.Errorf("violação em %s:%d:%d, esperado schemas/comum/endereco.yaml:2:3", v.File, v.Line, v.Column)
+	}
+}
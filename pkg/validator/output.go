@@ -0,0 +1,13 @@
+package validator
+
+import (
+	"io"
+	"os"
+)
+
+// Destino das mensagens: a CLI escreve em os.Stdout e os.Stderr; nas chamadas da
+// API (Validate, Resolve) as mensagens vão para o Logger das opções
+var (
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
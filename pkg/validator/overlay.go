@@ -0,0 +1,167 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Overlays (OpenAPI Overlay 1.0) aplicados, na ordem, ao documento raiz antes
+// das regras e da resolução
+var (
+	overlayFiles     stringList
+	overlayUnmatched = "warn"
+)
+
+func registerOverlayFlags(fs *flag.FlagSet) {
+	fs.Var(&overlayFiles, "overlay", "documento Overlay aplicado à especificação antes da validação e da resolução; pode ser repetida (aplicados na ordem)")
+	fs.StringVar(&overlayUnmatched, "overlay-unmatched", overlayUnmatched, "ação quando o target de um overlay não encontra nada: warn ou error")
+}
+
+// Confere o valor de --overlay-unmatched
+func checkOverlayFlags() error {
+	if overlayUnmatched != "warn" && overlayUnmatched != "error" {
+		return fmt.Errorf("valor inválido para --overlay-unmatched: %q (use warn ou error)", overlayUnmatched)
+	}
+	return nil
+}
+
+// Hash dos overlays em uso, para compor as chaves de cache que dependem do documento
+func overlaysHash(s *runSettings) []byte {
+	var parts [][]byte
+	for _, path := range s.overlays {
+		data, _ := s.readFile(path)
+		parts = append(parts, []byte(path), data)
+	}
+	return []byte(contentHash(parts...))
+}
+
+// Documento Overlay
+type overlayDocument struct {
+	Overlay string          `yaml:"overlay"`
+	Actions []overlayAction `yaml:"actions"`
+}
+
+// Ação do overlay: target JSONPath com update (mesclado) ou remove
+type overlayAction struct {
+	Target      string    `yaml:"target"`
+	Description string    `yaml:"description"`
+	Update      yaml.Node `yaml:"update"`
+	Remove      bool      `yaml:"remove"`
+}
+
+// Função para converter a especificação raiz na árvore YAML, convertendo Swagger 2.0
+// para OpenAPI 3.0 e aplicando os overlays.
+// Arquivos referenciados por $ref usam parseSpec diretamente.
+func parseRootSpec(s *runSettings, data []byte, source string) (*yaml.Node, error) {
+	rootNode, err := parseSpecDocument(data, source, s.document)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOpenAPIDocument(data, rootNode); err != nil {
+		return nil, err
+	}
+	if _, err := specVersion(rootNode); err != nil {
+		return nil, err
+	}
+	if isSwagger2(rootNode) && !s.noConvert {
+		convertSwagger2(rootNode)
+	}
+	for _, path := range s.overlays {
+		if err := applyOverlayFile(s, rootNode, path); err != nil {
+			return nil, err
+		}
+	}
+	return rootNode, nil
+}
+
+// Lê e interpreta um documento Overlay
+func loadOverlay(s *runSettings, path string) (*overlayDocument, error) {
+	data, err := s.readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = expandEnv(data, path); err != nil {
+		return nil, err
+	}
+	var doc overlayDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, yamlSyntaxError(data, path, err)
+	}
+	if doc.Overlay == "" {
+		return nil, fmt.Errorf("%s não é um documento Overlay (campo overlay ausente)", path)
+	}
+	return &doc, nil
+}
+
+// Função para aplicar as ações de um overlay ao documento
+func applyOverlayFile(s *runSettings, rootNode *yaml.Node, path string) error {
+	doc, err := loadOverlay(s, path)
+	if err != nil {
+		return err
+	}
+	for i, action := range doc.Actions {
+		if action.Target == "" {
+			return fmt.Errorf("%s: ação %d sem target", path, i+1)
+		}
+		matches, err := queryJSONPath(rootNode, action.Target)
+		if err != nil {
+			return fmt.Errorf("%s: ação %d: %v", path, i+1, err)
+		}
+		if len(matches) == 0 {
+			message := fmt.Sprintf("%s: o target %s não encontrou nenhum nó", path, action.Target)
+			if s.overlayUnmatched == "error" {
+				return fmt.Errorf("%s", message)
+			}
+			// Em stderr para não misturar com a saída JSON
+			fmt.Fprintln(s.log, "⚠️ ", message)
+			continue
+		}
+
+		if action.Remove {
+			doc := rootNode
+			if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+				doc = doc.Content[0]
+			}
+			parents := parentIndex(doc)
+			for _, m := range matches {
+				removeNode(parents, m)
+			}
+			continue
+		}
+		if action.Update.Kind == 0 {
+			continue
+		}
+		for _, m := range matches {
+			mergeOverlayNode(m.Node, &action.Update)
+		}
+	}
+	return nil
+}
+
+// Mescla o update no nó alvo: objetos são mesclados recursivamente, listas recebem
+// os itens do update e valores simples são substituídos
+func mergeOverlayNode(target, update *yaml.Node) {
+	switch {
+	case target.Kind == yaml.MappingNode && update.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(update.Content); i += 2 {
+			key, value := update.Content[i], update.Content[i+1]
+			if existing := mappingValue(target, key.Value); existing != nil {
+				mergeOverlayNode(existing, value)
+				continue
+			}
+			target.Content = append(target.Content, deepCopyNode(key), deepCopyNode(value))
+		}
+	case target.Kind == yaml.SequenceNode:
+		if update.Kind == yaml.SequenceNode {
+			for _, item := range update.Content {
+				target.Content = append(target.Content, deepCopyNode(item))
+			}
+		} else {
+			target.Content = append(target.Content, deepCopyNode(update))
+		}
+	default:
+		*target = *deepCopyNode(update)
+	}
+}
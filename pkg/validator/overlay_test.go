@@ -0,0 +1,88 @@
+package validator
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const overlayBase = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+servers:
+  - url: https://api.banco.com.br/open-banking/contas/v1
+paths:
+  /contas:
+    get:
+      responses:
+        '200': {description: ok}
+  /admin:
+    get:
+      responses:
+        '200': {description: ok}
+`
+
+// Os overlays são aplicados na ordem, antes da resolução e das regras; o target sem
+// nós é um aviso ou, com --overlay-unmatched error, um erro
+func TestOverlay(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"api.yaml": overlayBase,
+		"sandbox.yaml": `overlay: 1.0.0
+info: {title: Sandbox, version: 1.0.0}
+actions:
+  - target: $.servers[0]
+    update: {url: http://sandbox.banco.com.br/open-banking/contas/v1}
+  - target: $.paths['/admin']
+    remove: true
+`,
+		"titulo.yaml": `overlay: 1.0.0
+info: {title: Título, version: 1.0.0}
+actions:
+  - target: $.info
+    update: {title: Contas Sandbox}
+  - target: $.paths['/inexistente']
+    update: {description: nada}
+`,
+	})
+	spec := filepath.Join(dir, "api.yaml")
+	overlays := []string{"--overlay", filepath.Join(dir, "sandbox.yaml"), "--overlay", filepath.Join(dir, "titulo.yaml")}
+	output := filepath.Join(dir, "resolvido.yaml")
+
+	code, out := runCommand(t, append(append([]string{"resolve", "--no-cache", "-o", output}, overlays...), spec)...)
+	if code != exitOK || !strings.Contains(out, "o target $.paths['/inexistente'] não encontrou nenhum nó") {
+		t.Fatalf("código %d, esperado o aviso do target sem nós\n%s", code, out)
+	}
+	resolved := string(mustReadFile(t, output))
+	for _, want := range []string{"title: Contas Sandbox", "url: http://sandbox.banco.com.br"} {
+		if !strings.Contains(resolved, want) {
+			t.Errorf("artefato sem %q:\n%s", want, resolved)
+		}
+	}
+	if strings.Contains(resolved, "/admin") {
+		t.Errorf("/admin não foi removido:\n%s", resolved)
+	}
+
+	code, out = runCommand(t, append(append([]string{"resolve", "--no-cache", "--overlay-unmatched", "error", "-o", output}, overlays...), spec)...)
+	if code != exitFailure || !strings.Contains(out, "❌ Erro ao processar") {
+		t.Errorf("código %d com --overlay-unmatched error\n%s", code, out)
+	}
+
+	// As regras veem a URL http:// trazida pelo overlay
+	code, out = runCommand(t, "validate", "--no-cache", "--format", "json", "--overlay", filepath.Join(dir, "sandbox.yaml"), spec)
+	var report struct {
+		Validation validationReport `json:"validation"`
+	}
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	https := 0
+	for _, v := range report.Validation.Violations {
+		if v.RuleID == "only-https" {
+			https++
+		}
+	}
+	if code != exitFailure || https != 1 {
+		t.Errorf("código %d, violações %+v", code, report.Validation.Violations)
+	}
+}
@@ -0,0 +1,101 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// Diretório onde gravar o pacote de diagnóstico de um erro interno (--debug-bundle)
+var debugBundleDir string
+
+// Pânico ocorrido em outra goroutine (runPhase), relançado com a pilha original
+type phasePanic struct {
+	value interface{}
+	stack []byte
+}
+
+// Função para converter um pânico em erro interno: informa a fase e o arquivo em
+// processamento e, com --debug-bundle, grava a entrada, as regras e a pilha para
+// anexar ao relato do problema
+func reportPanic(recovered interface{}, args []string) int {
+	value, stack := recovered, debug.Stack()
+	if p, ok := recovered.(phasePanic); ok {
+		value, stack = p.value, p.stack
+	}
+
+	tracker.mu.Lock()
+	phase, file, rulesFile := tracker.phase, tracker.file, tracker.rules
+	tracker.mu.Unlock()
+
+	fmt.Fprintf(stderr, "💥 Erro interno durante a fase %q do arquivo %s: %v\n", phase, file, value)
+	fmt.Fprintln(stderr, "   Isto é um defeito do validador ou de uma biblioteca, não da especificação.")
+	if debugBundleDir == "" {
+		fmt.Fprintln(stderr, "   Execute de novo com --debug-bundle <dir> para gerar os arquivos de diagnóstico.")
+		return exitInternal
+	}
+	if err := writeDebugBundle(debugBundleDir, args, phase, file, rulesFile, value, stack); err != nil {
+		fmt.Fprintln(stderr, "❌ Erro ao gravar o pacote de diagnóstico:", err)
+		return exitInternal
+	}
+	fmt.Fprintln(stderr, "📦 Pacote de diagnóstico salvo em", debugBundleDir, "- anexe-o ao relato do problema.")
+	return exitInternal
+}
+
+// Grava no diretório a descrição do erro, a pilha e cópias da especificação e das regras
+func writeDebugBundle(dir string, args []string, phase, file, rulesFile string, value interface{}, stack []byte) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	var info strings.Builder
+	fmt.Fprintf(&info, "data: %s\n", time.Now().UTC().Format(time.RFC3339))
+	fmt.Fprintf(&info, "comando: %s %s\n", programName, strings.Join(redactArgs(args), " "))
+	fmt.Fprintf(&info, "fase: %s\narquivo: %s\nregras: %s\n", phase, file, rulesFile)
+	fmt.Fprintf(&info, "erro: %v\n", value)
+	fmt.Fprintf(&info, "go: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range build.Deps {
+			if strings.Contains(dep.Path, "libopenapi") {
+				fmt.Fprintf(&info, "%s: %s\n", dep.Path, dep.Version)
+			}
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "erro.txt"), []byte(info.String()), 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "stack.txt"), stack, 0644); err != nil {
+		return err
+	}
+	for _, path := range []string{file, rulesFile} {
+		if path == "" || isRemoteURL(path) {
+			continue
+		}
+		if data, err := os.ReadFile(path); err == nil {
+			if err := os.WriteFile(filepath.Join(dir, filepath.Base(path)), data, 0644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Remove os valores de --http-header da linha de comando registrada, que podem conter tokens
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		name := strings.TrimLeft(arg, "-")
+		switch {
+		case i > 0 && strings.TrimLeft(args[i-1], "-") == "http-header" && !strings.Contains(args[i-1], "="):
+			redacted[i] = "<omitido>"
+		case strings.HasPrefix(name, "http-header="):
+			redacted[i] = arg[:strings.Index(arg, "=")+1] + "<omitido>"
+		default:
+			redacted[i] = arg
+		}
+	}
+	return redacted
+}
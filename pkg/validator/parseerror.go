@@ -0,0 +1,124 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Formatos das mensagens do yaml.v3: "yaml: line N: ..." e "yaml: unknown anchor 'x' referenced"
+var (
+	yamlErrorLine   = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+	yamlErrorAnchor = regexp.MustCompile(`^yaml: unknown anchor '([^']*)' referenced$`)
+)
+
+// Função para transformar um erro de sintaxe do yaml.v3 em uma mensagem com o
+// arquivo, o trecho com a linha do erro e, quando possível, uma dica para os
+// erros mais comuns (tabulação na indentação, valores sem aspas que começam com
+// caracteres especiais ou que contêm ": ")
+func yamlSyntaxError(data []byte, source string, err error) error {
+	message := err.Error()
+	line := 0
+	if m := yamlErrorLine.FindStringSubmatch(message); m != nil {
+		line, _ = strconv.Atoi(m[1])
+		message = m[2]
+	} else if m := yamlErrorAnchor.FindStringSubmatch(message); m != nil {
+		// O yaml.v3 não informa a linha do alias; procurar o primeiro uso
+		message = fmt.Sprintf("alias *%s sem âncora &%s correspondente", m[1], m[1])
+		for i, text := range sourceLines(data) {
+			if strings.Contains(text, "*"+m[1]) {
+				line = i + 1
+				break
+			}
+		}
+	} else {
+		message = strings.TrimPrefix(message, "yaml: ")
+	}
+
+	if line == 0 {
+		return fmt.Errorf("erro de sintaxe YAML em %s: %s", source, message)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "erro de sintaxe YAML em %s, linha %d: %s", source, line, message)
+	b.WriteString(sourceExcerpt(data, line))
+	if hint := yamlErrorHint(data, line, message); hint != "" {
+		b.WriteString("\n   dica: " + hint)
+	}
+	return fmt.Errorf("%s", b.String())
+}
+
+// Trecho do arquivo com a linha indicada e as duas anteriores e posteriores
+func sourceExcerpt(data []byte, line int) string {
+	lines := sourceLines(data)
+	if line > len(lines) {
+		line = len(lines)
+	}
+	first, last := line-2, line+2
+	if first < 1 {
+		first = 1
+	}
+	if last > len(lines) {
+		last = len(lines)
+	}
+	width := len(strconv.Itoa(last))
+	var b strings.Builder
+	for n := first; n <= last; n++ {
+		marker := "  "
+		if n == line {
+			marker = "> "
+		}
+		text := lines[n-1]
+		fmt.Fprintf(&b, "\n %s%*d | %s", marker, width, n, strings.ReplaceAll(text, "\t", "→"))
+	}
+	return b.String()
+}
+
+// Dica para os erros mais comuns. O yaml.v3 às vezes indica a linha anterior ao
+// problema, então a linha do erro e a seguinte são conferidas.
+func yamlErrorHint(data []byte, line int, message string) string {
+	lines := sourceLines(data)
+	for n := line; n <= line+1 && n <= len(lines); n++ {
+		text := lines[n-1]
+		indent := text[:len(text)-len(strings.TrimLeft(text, " \t"))]
+		if strings.Contains(indent, "\t") {
+			return fmt.Sprintf("a linha %d usa tabulação (→) na indentação; o YAML só aceita espaços", n)
+		}
+		key, value := splitYAMLLine(text)
+		if value == "" || strings.HasPrefix(value, "\"") || strings.HasPrefix(value, "'") {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(value, "*") || strings.HasPrefix(value, "&"):
+			return fmt.Sprintf("valores que começam com %c são lidos como alias ou âncora; coloque o valor entre aspas (ex.: %s\"%s\")", value[0], key, value)
+		case strings.ContainsAny(value[:1], "@`%"):
+			return fmt.Sprintf("valores que começam com %c precisam estar entre aspas (ex.: %s\"%s\")", value[0], key, value)
+		case (strings.HasPrefix(value, "{") || strings.HasPrefix(value, "[")) && strings.Contains(message, "did not find expected"):
+			return fmt.Sprintf("valores que começam com %c são lidos como objeto ou lista em linha; se for texto, coloque o valor entre aspas (ex.: %s'%s')", value[0], key, value)
+		case strings.Contains(value, ": ") && strings.Contains(message, "mapping values are not allowed"):
+			return fmt.Sprintf("o valor contém \": \"; coloque o valor entre aspas (ex.: %s\"%s\")", key, value)
+		}
+	}
+	return ""
+}
+
+// Separa uma linha "chave: valor" (ou "- chave: valor", "- valor") no prefixo
+// até o valor e no valor, sem espaços nas pontas
+func splitYAMLLine(text string) (string, string) {
+	trimmed := strings.TrimLeft(text, " \t")
+	prefix := ""
+	for strings.HasPrefix(trimmed, "- ") {
+		prefix += "- "
+		trimmed = strings.TrimLeft(trimmed[2:], " ")
+	}
+	if strings.HasPrefix(trimmed, "#") {
+		return "", ""
+	}
+	if i := strings.Index(trimmed, ": "); i > 0 && !strings.ContainsAny(trimmed[:1], "\"'{[") {
+		return prefix + trimmed[:i+2], strings.TrimSpace(trimmed[i+2:])
+	}
+	if prefix != "" {
+		return prefix, strings.TrimSpace(trimmed)
+	}
+	return "", ""
+}
@@ -0,0 +1,169 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severidade dos paths equivalentes (mesmo template, só com outros nomes de parâmetro
+// ou barra final) e dos segmentos fixos que colidem com um parâmetro na mesma posição;
+// off desativa cada verificação
+var (
+	pathConflicts = "error"
+	pathOverlaps  = "warning"
+)
+
+func registerPathConflictFlags(fs *flag.FlagSet) {
+	fs.StringVar(&pathConflicts, "path-conflicts", pathConflicts, "severidade dos paths equivalentes (ex.: /contas/{id} e /contas/{contaId}): error, warning, info, hint ou off")
+	fs.StringVar(&pathOverlaps, "path-overlaps", pathOverlaps, "severidade dos segmentos fixos que colidem com um parâmetro na mesma posição (ex.: /contas/saldos e /contas/{id}): error, warning, info, hint ou off")
+}
+
+func checkPathConflictFlags() error {
+	for _, f := range []struct{ name, value string }{{"path-conflicts", pathConflicts}, {"path-overlaps", pathOverlaps}} {
+		if f.value != "off" && !ruleSeverities[f.value] {
+			return fmt.Errorf("valor inválido para --%s: %q (use error, warning, info, hint ou off)", f.name, f.value)
+		}
+	}
+	return nil
+}
+
+// Path declarado, com os segmentos normalizados: os trechos {parâmetro} viram {}
+type pathTemplate struct {
+	route    string
+	line     int
+	segments []string
+	params   []bool // segmento com parâmetro
+}
+
+func parsePathTemplate(route string, line int) pathTemplate {
+	t := pathTemplate{route: route, line: line}
+	trimmed := strings.Trim(route, "/")
+	if trimmed == "" {
+		return t
+	}
+	for _, segment := range strings.Split(trimmed, "/") {
+		var b strings.Builder
+		param := false
+		for segment != "" {
+			open := strings.IndexByte(segment, '{')
+			end := strings.IndexByte(segment[open+1:], '}')
+			if open < 0 || end < 0 {
+				b.WriteString(segment)
+				break
+			}
+			b.WriteString(segment[:open] + "{}")
+			segment = segment[open+1+end+1:]
+			param = true
+		}
+		t.segments = append(t.segments, b.String())
+		t.params = append(t.params, param)
+	}
+	return t
+}
+
+// Função para comparar os paths dois a dois: templates equivalentes são um conflito
+// (o roteador não tem como distinguir) e um segmento fixo na posição de um parâmetro
+// de outro path, com os demais segmentos compatíveis, é uma sobreposição que alguns
+// roteadores resolvem pelo fixo e outros pela ordem de declaração
+func pathConflictViolations(s *runSettings, root *yaml.Node) []Violation {
+	if s.pathConflicts == "off" && s.pathOverlaps == "off" {
+		return nil
+	}
+	paths := mappingValue(documentContent(root), "paths")
+	if paths == nil || paths.Kind != yaml.MappingNode {
+		return nil
+	}
+	var templates []pathTemplate
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		key := paths.Content[i]
+		if strings.HasPrefix(key.Value, "x-") {
+			continue
+		}
+		templates = append(templates, parsePathTemplate(key.Value, key.Line))
+	}
+
+	var violations []Violation
+	for j := range templates {
+		b := templates[j]
+		for i := 0; i < j; i++ {
+			a := templates[i]
+			if len(a.segments) != len(b.segments) {
+				continue
+			}
+			equivalent, overlap := true, 0
+			for k := range a.segments {
+				switch {
+				case a.segments[k] == b.segments[k]:
+				case a.params[k] && b.params[k]:
+					// Dois parâmetros em formatos diferentes ({id}.json e {id}) podem casar
+					equivalent = false
+				case a.params[k] && segmentAccepts(a.segments[k], b.segments[k]), b.params[k] && segmentAccepts(b.segments[k], a.segments[k]):
+					equivalent = false
+					overlap = k + 1
+				default:
+					equivalent, overlap = false, -1
+				}
+				if overlap < 0 {
+					break
+				}
+			}
+			severity, kind := s.pathConflicts, "path-conflict"
+			var message string
+			switch {
+			case equivalent:
+				reason := "diferem só no nome dos parâmetros"
+				if strings.Trim(a.route, "/") == strings.Trim(b.route, "/") {
+					reason = "diferem só na barra inicial ou final"
+				}
+				message = fmt.Sprintf("Os paths %s (linha %d) e %s (linha %d) são equivalentes: %s e o roteador não tem como distinguir as requisições.", a.route, a.line, b.route, b.line, reason)
+			case overlap > 0:
+				severity, kind = s.pathOverlaps, "path-overlap"
+				message = fmt.Sprintf("Os paths %s (linha %d) e %s (linha %d) se sobrepõem: o segmento %q de um coincide com um parâmetro do outro na mesma posição, e a requisição vai para um ou outro conforme o roteador.", a.route, a.line, b.route, b.line, overlapSegment(a, b, overlap-1))
+			default:
+				continue
+			}
+			if severity == "off" {
+				continue
+			}
+			violations = append(violations, Violation{
+				RuleID:   kind,
+				Severity: severity,
+				Message:  message,
+				JSONPath: joinPath("$.paths", b.route),
+				Line:     b.line,
+			})
+		}
+	}
+	return violations
+}
+
+// Indica se o segmento fixo casa com o segmento com parâmetros ({}.json aceita
+// extrato.json, mas não saldos)
+func segmentAccepts(template, fixed string) bool {
+	parts := strings.Split(template, "{}")
+	if !strings.HasPrefix(fixed, parts[0]) {
+		return false
+	}
+	fixed = fixed[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		// Cada parâmetro casa com pelo menos um caractere
+		i := strings.Index(fixed[min(1, len(fixed)):], part)
+		if i < 0 {
+			return false
+		}
+		fixed = fixed[min(1, len(fixed))+i+len(part):]
+	}
+	last := parts[len(parts)-1]
+	return len(fixed) > len(last) && strings.HasSuffix(fixed, last)
+}
+
+// Segmento fixo da posição em que os paths se sobrepõem
+func overlapSegment(a, b pathTemplate, k int) string {
+	if a.params[k] {
+		return b.segments[k]
+	}
+	return a.segments[k]
+}
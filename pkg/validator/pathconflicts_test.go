@@ -0,0 +1,83 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+const pathConflictsSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas/{contaId}:
+    get: {responses: {'200': {description: ok}}}
+  /contas/{id}:
+    get: {responses: {'200': {description: ok}}}
+  /contas/saldos:
+    get: {responses: {'200': {description: ok}}}
+  /cartoes/:
+    get: {responses: {'200': {description: ok}}}
+  /cartoes:
+    get: {responses: {'200': {description: ok}}}
+  /contas/{contaId}.json:
+    get: {responses: {'200': {description: ok}}}
+  /pix/{id}/devolucoes:
+    get: {responses: {'200': {description: ok}}}
+  x-rascunho:
+    /contas/{x}: {}
+`
+
+// Templates equivalentes são conflitos e segmentos fixos na posição de um parâmetro,
+// sobreposições; um segmento fixo só colide com o parâmetro que o aceita ({id}.json
+// não aceita saldos) e parâmetros em formatos diferentes não colidem
+func TestPathConflicts(t *testing.T) {
+	s := &runSettings{pathConflicts: "error", pathOverlaps: "warning"}
+	found := pathConflictViolations(s, mustParseYAML(t, pathConflictsSpec))
+	want := []struct {
+		rule, severity, message string
+		line                    int
+	}{
+		{"path-conflict", "error", "Os paths /contas/{contaId} (linha 4) e /contas/{id} (linha 6) são equivalentes: diferem só no nome dos parâmetros", 6},
+		{"path-overlap", "warning", `Os paths /contas/{contaId} (linha 4) e /contas/saldos (linha 8) se sobrepõem: o segmento "saldos"`, 8},
+		{"path-overlap", "warning", `Os paths /contas/{id} (linha 6) e /contas/saldos (linha 8) se sobrepõem`, 8},
+		{"path-conflict", "error", "Os paths /cartoes/ (linha 10) e /cartoes (linha 12) são equivalentes: diferem só na barra inicial ou final", 12},
+	}
+	if len(found) != len(want) {
+		t.Fatalf("esperadas %d violações, encontradas %d: %v", len(want), len(found), found)
+	}
+	for i, w := range want {
+		v := found[i]
+		if v.RuleID != w.rule || v.Severity != w.severity || v.Line != w.line || !strings.Contains(v.Message, w.message) {
+			t.Errorf("violação %d: %s %s linha %d %q, esperado %s %s linha %d %q", i, v.RuleID, v.Severity, v.Line, v.Message, w.rule, w.severity, w.line, w.message)
+		}
+	}
+}
+
+// Cada verificação pode ser desligada com off
+func TestPathConflictsOff(t *testing.T) {
+	s := &runSettings{pathConflicts: "off", pathOverlaps: "info"}
+	found := pathConflictViolations(s, mustParseYAML(t, pathConflictsSpec))
+	if len(found) != 2 || found[0].RuleID != "path-overlap" || found[0].Severity != "info" {
+		t.Errorf("com --path-conflicts off, esperadas só as 2 sobreposições: %v", found)
+	}
+}
+
+func TestSegmentAccepts(t *testing.T) {
+	for _, tt := range []struct {
+		template, fixed string
+		want            bool
+	}{
+		{"{}", "saldos", true},
+		{"{}.json", "extrato.json", true},
+		{"{}.json", "saldos", false},
+		{"{}.json", ".json", false},
+		{"v{}", "v2", true},
+		{"v{}", "v", false},
+		{"{}-{}", "a-b", true},
+		{"{}-{}", "-b", false},
+		{"{}-{}", "ab", false},
+	} {
+		if got := segmentAccepts(tt.template, tt.fixed); got != tt.want {
+			t.Errorf("segmentAccepts(%q, %q) = %v, esperado %v", tt.template, tt.fixed, got, tt.want)
+		}
+	}
+}
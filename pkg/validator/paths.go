@@ -0,0 +1,34 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Função para escrever um caminho nos relatórios (JSON, --ref-report, manifesto):
+// relativo ao diretório atual quando está dentro dele e sempre com "/", para que
+// o relatório seja o mesmo no Windows e no Linux
+func reportPath(path string) string {
+	if path == "" || isRemoteURL(path) {
+		return path
+	}
+	if filepath.IsAbs(path) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				path = rel
+			}
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// Função para interpretar um caminho escrito em configuração ou manifesto: "\" e "/"
+// valem como separador em qualquer sistema, para que arquivos escritos no Windows
+// funcionem no CI
+func configPath(path string) string {
+	if path == "" || isRemoteURL(path) {
+		return path
+	}
+	return filepath.FromSlash(strings.ReplaceAll(path, `\`, "/"))
+}
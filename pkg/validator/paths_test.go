@@ -0,0 +1,98 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Caminhos de configuração e manifesto aceitam "\" e "/" como separador; os dos
+// relatórios saem sempre com "/", relativos ao diretório atual quando estão dentro dele
+func TestConfigAndReportPaths(t *testing.T) {
+	for path, want := range map[string]string{
+		`apis\contas\api.yaml`:     filepath.Join("apis", "contas", "api.yaml"),
+		"apis/contas/api.yaml":     filepath.Join("apis", "contas", "api.yaml"),
+		`..\comum\regras.yaml`:     filepath.Join("..", "comum", "regras.yaml"),
+		"https://exemplo.com/a\\b": "https://exemplo.com/a\\b",
+		"":                         "",
+	} {
+		if got := configPath(path); got != want {
+			t.Errorf("configPath(%q) = %q, esperado %q", path, got, want)
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(filepath.Dir(filepath.Dir(wd)), "fora", "api.yaml")
+	for path, want := range map[string]string{
+		filepath.Join(wd, "testdata", "e2e", "valid", "api.yaml"): "testdata/e2e/valid/api.yaml",
+		filepath.Join("testdata", "e2e", "valid", "api.yaml"):     "testdata/e2e/valid/api.yaml",
+		outside:                        filepath.ToSlash(outside),
+		"https://exemplo.com/api.yaml": "https://exemplo.com/api.yaml",
+	} {
+		if got := reportPath(path); got != want {
+			t.Errorf("reportPath(%q) = %q, esperado %q", path, got, want)
+		}
+	}
+}
+
+// Um manifesto escrito no Windows, com "\" nos caminhos da especificação e do
+// relatório, funciona em qualquer sistema, e o relatório agregado usa "/"
+func TestManifestWindowsPaths(t *testing.T) {
+	dir := t.TempDir()
+	writeTemp(t, mkdirAll(t, filepath.Join(dir, "apis", "contas")), "api.yaml", mustReadFile(t, filepath.Join("testdata", "e2e", "valid", "api.yaml")))
+	mkdirAll(t, filepath.Join(dir, "relatorios"))
+	manifestFile := writeTemp(t, dir, "apis.yaml", []byte("apis:\n  - name: contas\n    spec: apis\\contas\\api.yaml\n    output: relatorios\\contas.json\n"))
+
+	var out, errOut bytes.Buffer
+	if code := Run([]string{"validate", "--no-cache", "--format", "json", "--manifest", manifestFile}, &out, &errOut); code != exitOK {
+		t.Fatalf("exit %d\n%s%s", code, out.String(), errOut.String())
+	}
+	var report manifestReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("%v\n%s", err, out.String())
+	}
+	if len(report.APIs) != 1 || !strings.HasSuffix(report.APIs[0].Spec, "/apis/contas/api.yaml") || !strings.HasSuffix(report.APIs[0].Output, "/relatorios/contas.json") {
+		t.Errorf("caminhos do relatório: %+v", report.APIs)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "relatorios", "contas.json")); err != nil {
+		t.Errorf("relatório da API não gravado: %v", err)
+	}
+}
+
+// Com CRLF, as violações apontam para as mesmas linhas e colunas que o editor mostra,
+// iguais às da mesma especificação com LF, no JSON e no SARIF
+func TestCRLFPositions(t *testing.T) {
+	source := mustReadFile(t, filepath.Join("testdata", "e2e", "violations", "api.yaml"))
+	lf := writeTemp(t, t.TempDir(), "api.yaml", source)
+	crlf := writeTemp(t, t.TempDir(), "api.yaml", bytes.ReplaceAll(source, []byte("\n"), []byte("\r\n")))
+
+	for _, format := range []string{"json", "sarif"} {
+		outputs := map[string]string{}
+		for name, spec := range map[string]string{"LF": lf, "CRLF": crlf} {
+			var out, errOut bytes.Buffer
+			Run([]string{"validate", "--no-cache", "--format", format, spec}, &out, &errOut)
+			outputs[name] = strings.ReplaceAll(out.String(), filepath.ToSlash(filepath.Dir(spec)), "$DIR")
+		}
+		if !strings.Contains(outputs["LF"], `"startLine"`) && !strings.Contains(outputs["LF"], `"line"`) {
+			t.Fatalf("%s sem posições:\n%s", format, outputs["LF"])
+		}
+		if outputs["LF"] != outputs["CRLF"] {
+			t.Errorf("%s: as posições com CRLF diferem das com LF\n--- LF\n%s\n--- CRLF\n%s", format, outputs["LF"], outputs["CRLF"])
+		}
+	}
+}
+
+// Cria o diretório (e os pais) e devolve o caminho
+func mkdirAll(t *testing.T, dir string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
@@ -0,0 +1,128 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Perfil de severidades das regras (--profile). Uma regra pode definir a severidade
+// de cada perfil em severities; no perfil escolhido ela substitui severity, e as
+// violações, os relatórios e o código de saída usam a severidade efetiva.
+//
+//	severities:
+//	  consultation: warning
+//	  ga: error
+//
+// Com auto, o perfil vem do info.version de cada especificação: versões de
+// pré-lançamento (ex.: 2.0.0-rc.1) ou 0.x usam consultation, as demais usam ga.
+var severityProfile = "auto"
+
+// Perfis do ciclo de vida de uma especificação, usados pelo auto
+const (
+	profileConsultation = "consultation"
+	profileGA           = "ga"
+)
+
+func registerProfileFlag(fs *flag.FlagSet) {
+	fs.StringVar(&severityProfile, "profile", severityProfile, "perfil de severidades das regras (severities): consultation, ga ou outro definido nas regras; auto escolhe pelo info.version")
+}
+
+// Severidade da regra no perfil; vazio quando a regra não define o perfil
+func profileSeverity(ruleData map[string]interface{}, profile string) string {
+	severities, _ := ruleData["severities"].(map[string]interface{})
+	severity, _ := severities[profile].(string)
+	return severity
+}
+
+// Perfis definidos nas regras, em ordem alfabética
+func ruleProfiles(rules map[string]interface{}) []string {
+	set := map[string]bool{}
+	for _, raw := range rules {
+		ruleData, _ := raw.(map[string]interface{})
+		severities, _ := ruleData["severities"].(map[string]interface{})
+		for profile := range severities {
+			set[profile] = true
+		}
+	}
+	return sortedKeys(set)
+}
+
+// Função para escolher o perfil da especificação: o de --profile ou, com auto, o do
+// info.version. Vazio quando as regras não usam perfis ou a versão não é reconhecida.
+func specProfile(selected string, root *yaml.Node, rules map[string]interface{}) (string, error) {
+	profiles := ruleProfiles(rules)
+	if selected != "auto" {
+		known := map[string]bool{profileConsultation: true, profileGA: true}
+		for _, name := range profiles {
+			known[name] = true
+		}
+		if known[selected] {
+			return selected, nil
+		}
+		names := sortedKeys(known)
+		return "", fmt.Errorf("perfil %q desconhecido em --profile (use auto ou um dos perfis: %s)%s", selected, strings.Join(names, ", "), suggestionSuffix(selected, names))
+	}
+	if len(profiles) == 0 {
+		return "", nil
+	}
+	version, ga := isGAVersion(documentContent(root))
+	switch {
+	case ga:
+		return profileGA, nil
+	case version != "" && apiVersionPattern.MatchString(strings.TrimSpace(version)):
+		return profileConsultation, nil
+	}
+	return "", nil
+}
+
+// Regras com a severidade do perfil no lugar de severity; sem perfil, as mesmas regras
+func applyProfile(rules map[string]interface{}, profile string) map[string]interface{} {
+	if profile == "" {
+		return rules
+	}
+	var adjusted map[string]interface{}
+	for name, raw := range rules {
+		ruleData, _ := raw.(map[string]interface{})
+		severity := profileSeverity(ruleData, profile)
+		if severity == "" {
+			continue
+		}
+		if adjusted == nil {
+			adjusted = make(map[string]interface{}, len(rules))
+			for k, v := range rules {
+				adjusted[k] = v
+			}
+		}
+		adjusted[name] = withRuleFields(ruleData, map[string]interface{}{"severity": severity})
+	}
+	if adjusted == nil {
+		return rules
+	}
+	return adjusted
+}
+
+// Perfil aplicado, para o resumo da validação
+func profileSuffix(profile string) string {
+	if profile == "" {
+		return ""
+	}
+	return " (perfil " + profile + ")"
+}
+
+// Severidades por perfil, em ordem alfabética (ex.: "consultation: warning, ga: error")
+func profileSeverityList(severities map[string]interface{}) string {
+	profiles := make([]string, 0, len(severities))
+	for profile := range severities {
+		profiles = append(profiles, profile)
+	}
+	sort.Strings(profiles)
+	parts := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		parts = append(parts, fmt.Sprintf("%s: %v", profile, severities[profile]))
+	}
+	return strings.Join(parts, ", ")
+}
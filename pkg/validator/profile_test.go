@@ -0,0 +1,57 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+const profileRules = `rules:
+  operacao-com-descricao:
+    given: $.paths[*][*]
+    severity: info
+    severities: {consultation: warning, ga: error}
+    then: {field: description, function: truthy}
+`
+
+func profileSpec(version string) string {
+	return "openapi: 3.0.3\ninfo: {title: Contas, version: " + version + "}\npaths:\n  /contas:\n    get:\n      responses:\n        '200': {description: ok}\n"
+}
+
+// Com auto, o perfil vem do info.version: versões GA usam ga e as de pré-lançamento
+// ou 0.x, consultation
+func TestProfileFromVersion(t *testing.T) {
+	for version, severity := range map[string]string{"2.0.0": "error", "2.0.0-rc.1": "warning", "0.3.0": "warning"} {
+		found := ruleViolations(t, profileSpec(version), profileRules, "operacao-com-descricao")
+		if len(found) != 1 || found[0].Severity != severity {
+			t.Errorf("versão %s: violações %v, esperada uma com severidade %s", version, found, severity)
+		}
+	}
+}
+
+// Um perfil escolhido explicitamente vale para qualquer versão; sem severities para o
+// perfil, a regra mantém severity
+func TestProfileSelected(t *testing.T) {
+	rules := map[string]interface{}{
+		"com-perfil": map[string]interface{}{"severity": "info", "severities": map[string]interface{}{"ga": "error"}},
+		"sem-perfil": map[string]interface{}{"severity": "hint"},
+	}
+	root := mustParseYAML(t, profileSpec("1.0.0-beta"))
+	profile, err := specProfile("ga", root, rules)
+	if err != nil || profile != "ga" {
+		t.Fatalf("perfil %q, erro %v", profile, err)
+	}
+	adjusted := applyProfile(rules, profile)
+	for name, severity := range map[string]string{"com-perfil": "error", "sem-perfil": "hint"} {
+		if got := adjusted[name].(map[string]interface{})["severity"]; got != severity {
+			t.Errorf("%s: severidade %v, esperado %s", name, got, severity)
+		}
+	}
+	if rules["com-perfil"].(map[string]interface{})["severity"] != "info" {
+		t.Error("applyProfile não deveria alterar as regras originais")
+	}
+
+	_, err = specProfile("gaa", root, rules)
+	if err == nil || !strings.Contains(err.Error(), `perfil "gaa" desconhecido`) || !strings.Contains(err.Error(), "ga") {
+		t.Errorf("erro do perfil desconhecido: %v", err)
+	}
+}
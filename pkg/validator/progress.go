@@ -0,0 +1,149 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Progresso da indexação e da resolução em stderr (--progress): nas especificações
+// com muitos arquivos externos essas etapas levam dezenas de segundos sem nenhuma
+// saída. Com auto, o terminal recebe uma linha atualizada e, com a variável CI
+// definida, é impressa uma linha a cada progressLogInterval; log força as linhas
+// periódicas e off desativa. Nada é impresso nas etapas que terminam antes do
+// primeiro intervalo.
+var progressMode = "auto"
+
+const (
+	progressTTYDelay    = time.Second
+	progressTTYInterval = 200 * time.Millisecond
+	progressLogInterval = 10 * time.Second
+)
+
+func registerProgressFlag(fs *flag.FlagSet) {
+	fs.StringVar(&progressMode, "progress", progressMode, "progresso da indexação e da resolução em stderr: auto (linha atualizada no terminal; linhas periódicas com CI definida), log ou off")
+}
+
+func checkProgressFlag() error {
+	switch progressMode {
+	case "auto", "log", "off":
+		return nil
+	}
+	return fmt.Errorf("valor inválido para --progress: %q (use auto, log ou off)", progressMode)
+}
+
+// Etapa em andamento: os arquivos carregados pelo rolodex e o último deles. Cada
+// execução tem o seu, que escreve em out conforme mode (--progress); os métodos de
+// um reporter nulo não fazem nada.
+type progressReporter struct {
+	out  io.Writer
+	mode string
+
+	mu      sync.Mutex
+	phase   string
+	file    string
+	current string
+	files   int
+	refs    int
+	began   time.Time
+}
+
+func newProgressReporter(out io.Writer, mode string) *progressReporter {
+	return &progressReporter{out: out, mode: mode}
+}
+
+// Registra um arquivo carregado pelo rolodex (local ou remoto)
+func (p *progressReporter) loaded(name string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.files++
+	p.current = name
+}
+
+// Inicia o acompanhamento de uma etapa; a função retornada encerra a etapa e limpa a
+// linha do terminal. O número de arquivos continua da indexação para a resolução.
+func (p *progressReporter) begin(phase, file string, refs int) func() {
+	if p == nil {
+		return func() {}
+	}
+	p.mu.Lock()
+	if phase == "index" {
+		p.files, p.current = 0, ""
+	}
+	p.phase, p.file, p.refs, p.began = phase, file, refs, time.Now()
+	p.mu.Unlock()
+
+	tty := isTerminal(p.out)
+	delay, interval := progressLogInterval, progressLogInterval
+	switch {
+	case p.mode == "off":
+		return func() {}
+	case p.mode == "auto" && tty:
+		delay, interval = progressTTYDelay, progressTTYInterval
+	case p.mode == "auto" && os.Getenv("CI") == "":
+		return func() {}
+	default:
+		tty = false
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		printed := false
+		for {
+			select {
+			case <-stop:
+				if printed && tty {
+					fmt.Fprint(p.out, "\r\033[K")
+				}
+				return
+			case <-timer.C:
+			}
+			if tty {
+				fmt.Fprintf(p.out, "\r\033[K⏳ %s", p.line())
+			} else {
+				fmt.Fprintf(p.out, "⏳ %s\n", p.line())
+			}
+			printed = true
+			timer.Reset(interval)
+		}
+	}()
+	return func() {
+		close(stop)
+		<-done
+	}
+}
+
+// Texto do progresso da etapa em andamento
+func (p *progressReporter) line() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	elapsed := time.Since(p.began).Round(time.Second)
+	if p.phase == "resolve" {
+		return fmt.Sprintf("Resolvendo %s: %d referências em %d arquivos (%s)", p.file, p.refs, p.files+1, elapsed)
+	}
+	text := fmt.Sprintf("Indexando %s: %d arquivos carregados", p.file, p.files)
+	if p.current != "" {
+		text += ", atual: " + p.current
+	}
+	return fmt.Sprintf("%s (%s)", text, elapsed)
+}
+
+// A linha atualizada só faz sentido quando a saída é um terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
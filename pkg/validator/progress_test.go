@@ -0,0 +1,47 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// O reporter conta os arquivos carregados pelo rolodex durante a indexação e mostra
+// o total na resolução; com --progress off nada é impresso
+func TestProgressCountsLoadedFiles(t *testing.T) {
+	var out bytes.Buffer
+	s := flagSettings()
+	s.noCache, s.tracker = true, nil
+	s.progress = newProgressReporter(&out, "off")
+	if _, err := indexAndResolve(context.Background(), s, filepath.Join("testdata", "e2e", "multi", "api.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if s.progress.files != 2 {
+		t.Errorf("%d arquivos carregados, esperados 2 (schemas/account.yaml e schemas/common.yaml)", s.progress.files)
+	}
+	if line := s.progress.line(); !strings.HasPrefix(line, "Resolvendo ") || !strings.Contains(line, "em 3 arquivos") {
+		t.Errorf("linha da resolução: %q", line)
+	}
+	if out.Len() > 0 {
+		t.Errorf("--progress off não deveria imprimir nada: %q", out.String())
+	}
+}
+
+// A indexação mostra o arquivo atual e recomeça a contagem a cada especificação
+func TestProgressIndexLine(t *testing.T) {
+	p := newProgressReporter(&bytes.Buffer{}, "off")
+	p.loaded("antigo.yaml")
+	end := p.begin("index", "api.yaml", 0)
+	p.loaded("schemas/conta.yaml")
+	end()
+	if line := p.line(); !strings.HasPrefix(line, "Indexando api.yaml: 1 arquivos carregados, atual: schemas/conta.yaml (") {
+		t.Errorf("linha da indexação: %q", line)
+	}
+
+	// Os métodos de um reporter nulo (API, serve) não fazem nada
+	var none *progressReporter
+	none.loaded("x.yaml")
+	none.begin("index", "api.yaml", 0)()
+}
@@ -0,0 +1,520 @@
+package validator
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Flags do subcomando publish
+type publishOptions struct {
+	output       string
+	outputFormat string
+	marker       string
+	format       string
+	dryRun       bool
+}
+
+func (o *publishOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.output, "o", "", "arquivo da especificação publicada (obrigatório, exceto com --dry-run)")
+	fs.StringVar(&o.outputFormat, "output-format", "", "formato do documento gerado: yaml ou json (padrão: pela extensão do arquivo de saída)")
+	fs.StringVar(&o.marker, "marker", "x-internal", "extensão que marca o que não é publicado (com valor true)")
+	fs.StringVar(&o.format, "format", "text", "formato do resumo: text ou json")
+	fs.BoolVar(&o.dryRun, "dry-run", false, "mostra o que seria removido, sem gravar")
+	registerRefFlags(fs)
+	registerHTTPFlags(fs)
+	registerEncodingFlag(fs)
+	registerSelectDocumentFlag(fs)
+	registerInputLimitFlag(fs)
+}
+
+var publishCommand = &command{
+	Name:    "publish",
+	Args:    "<spec.yaml>",
+	Summary: "gera a versão da especificação para parceiros externos, sem o que é interno",
+	Description: `Remove da especificação as operações, os path items, os parâmetros e os
+webhooks marcados com a extensão de --marker (x-internal: true por padrão) e,
+em seguida, os componentes que deixaram de ser referenciados por causa disso.
+Componentes que já não eram referenciados na especificação original são
+mantidos. Um parâmetro é removido também quando o $ref dele aponta para um
+parâmetro marcado.
+
+Antes de gravar, o documento publicado é lido e resolvido de novo: campos
+obrigatórios ausentes ou $refs que apontam para o que foi removido fazem a
+execução falhar, sem gravar nada. O resumo lista cada item removido, para a
+revisão antes da publicação.`,
+	Examples: []string{
+		programName + " publish -o publico/swagger.yaml swagger.yaml",
+		programName + " publish --dry-run swagger.yaml",
+		programName + " publish --marker x-partner-hidden --format json -o publico.yaml swagger.yaml",
+	},
+	Flags: func(fs *flag.FlagSet) { new(publishOptions).register(fs) },
+	Run:   runPublish,
+}
+
+// Item removido da especificação publicada
+type publishRemoval struct {
+	Kind     string `json:"kind"` // path, operation, webhook, parameter ou component
+	Location string `json:"location"`
+	Reason   string `json:"reason"`
+}
+
+// Resumo da publicação, impresso com --format json
+type publishSummary struct {
+	File    string           `json:"file"`
+	Output  string           `json:"output,omitempty"`
+	Marker  string           `json:"marker"`
+	Removed []publishRemoval `json:"removed"`
+}
+
+// Subcomando publish: grava a especificação sem os itens marcados como internos
+func runPublish(c *command, args []string) int {
+	opts := &publishOptions{}
+	fs := c.newFlagSet()
+	opts.register(fs)
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	if err := checkHTTPFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkEncodingFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkSelectDocumentFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if fs.NArg() != 1 {
+		return c.usageError("esperado um argumento (especificação), recebidos %d", fs.NArg())
+	}
+	if opts.output == "" && !opts.dryRun {
+		return c.usageError("informe o arquivo da especificação publicada com -o (ou use --dry-run)")
+	}
+	if !strings.HasPrefix(opts.marker, "x-") {
+		return c.usageError("--marker deve ser uma extensão (x-...), recebido %q", opts.marker)
+	}
+	if opts.format != "text" && opts.format != "json" {
+		return c.usageError("formato %q inválido para publish (use text ou json)", opts.format)
+	}
+	inputFile := fs.Arg(0)
+	outputFile := opts.output
+	if outputFile == "" {
+		outputFile = inputFile // só para escolher o formato pela extensão
+	}
+	format, err := outputFormatFor(outputFile, opts.outputFormat)
+	if err != nil {
+		return c.usageError("%v", err)
+	}
+
+	data, err := readSpecFile(inputFile)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌", err)
+		return exitFailure
+	}
+	rootNode, err := parseSpecDocument(data, inputFile, selectDocument)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao processar", inputFile+":", err)
+		return exitFailure
+	}
+
+	removed := redactSpec(rootNode, opts.marker)
+	published, err := marshalSpec(rootNode, format, detectIndent(data), foldedScalarsOf(data, rootNode))
+	if err != nil {
+		fmt.Fprintln(stdout, "❌", err)
+		return exitFailure
+	}
+	if err := verifyPublished(inputFile, published, format); err != nil {
+		fmt.Fprintln(stdout, "❌", err)
+		return exitFailure
+	}
+
+	if !opts.dryRun {
+		if err := writeFileAtomic(opts.output, published); err != nil {
+			fmt.Fprintln(stdout, "❌ Erro ao salvar", opts.output+":", err)
+			return exitFailure
+		}
+	}
+
+	summary := publishSummary{File: reportPath(inputFile), Marker: opts.marker, Removed: removed}
+	if !opts.dryRun {
+		summary.Output = opts.output
+	}
+	if opts.format == "json" {
+		if err := printJSONReport(summary); err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+		return exitOK
+	}
+	printPublishSummary(summary, opts.dryRun)
+	return exitOK
+}
+
+var publishKindNames = map[string]string{
+	"path":      "path",
+	"operation": "operação",
+	"webhook":   "webhook",
+	"parameter": "parâmetro",
+	"component": "componente",
+}
+
+func printPublishSummary(summary publishSummary, dryRun bool) {
+	switch {
+	case len(summary.Removed) == 0:
+		fmt.Fprintf(stdout, "ℹ️  Nada marcado com %s em %s.\n", summary.Marker, summary.File)
+	case dryRun:
+		fmt.Fprintf(stdout, "🔎 %d itens seriam removidos de %s:\n", len(summary.Removed), summary.File)
+	default:
+		fmt.Fprintf(stdout, "🔒 %d itens removidos de %s:\n", len(summary.Removed), summary.File)
+	}
+	for _, r := range summary.Removed {
+		fmt.Fprintf(stdout, "   - %s %s (%s)\n", publishKindNames[r.Kind], r.Location, r.Reason)
+	}
+	if summary.Output != "" {
+		fmt.Fprintln(stdout, "✅ Especificação publicada salva em:", summary.Output)
+	}
+}
+
+// Função para remover do documento o que está marcado com marker e, depois, os
+// componentes que só eram referenciados pelo que foi removido. Retorna os itens
+// removidos, na ordem do documento.
+func redactSpec(rootNode *yaml.Node, marker string) []publishRemoval {
+	doc := documentContent(rootNode)
+	if doc == nil || doc.Kind != yaml.MappingNode {
+		return nil
+	}
+	sections := componentSections(rootNode)
+	before := reachableComponents(doc, sections)
+
+	var removed []publishRemoval
+	reason := marker + ": true"
+	isMarked := func(node *yaml.Node) bool { return publishMarked(doc, node, marker) }
+
+	// Parâmetros marcados de uma lista; os $refs são seguidos até o parâmetro
+	filterParameters := func(owner *yaml.Node, location string) {
+		params := mappingValue(owner, "parameters")
+		if params == nil || params.Kind != yaml.SequenceNode {
+			return
+		}
+		kept := params.Content[:0:0]
+		for _, param := range params.Content {
+			if !isMarked(param) {
+				kept = append(kept, param)
+				continue
+			}
+			name := parameterLabel(doc, param)
+			removed = append(removed, publishRemoval{Kind: "parameter", Location: name + " em " + location, Reason: reason})
+		}
+		if len(kept) == 0 && len(params.Content) > 0 {
+			removeKey(owner, "parameters")
+		}
+		params.Content = kept
+	}
+
+	for _, section := range []string{"paths", "webhooks"} {
+		items := mappingValue(doc, section)
+		if items == nil || items.Kind != yaml.MappingNode {
+			continue
+		}
+		kept := items.Content[:0:0]
+		for i := 0; i+1 < len(items.Content); i += 2 {
+			key, item := items.Content[i], items.Content[i+1]
+			kind, label := "path", key.Value
+			if section == "webhooks" {
+				kind = "webhook"
+			}
+			if strings.HasPrefix(key.Value, "x-") {
+				kept = append(kept, key, item)
+				continue
+			}
+			if isMarked(item) {
+				removed = append(removed, publishRemoval{Kind: kind, Location: label, Reason: reason})
+				continue
+			}
+			operations, remaining := 0, 0
+			if item.Kind == yaml.MappingNode {
+				content := item.Content[:0:0]
+				for j := 0; j+1 < len(item.Content); j += 2 {
+					method, operation := item.Content[j].Value, item.Content[j+1]
+					if !httpMethods[method] {
+						content = append(content, item.Content[j], operation)
+						continue
+					}
+					operations++
+					location := strings.ToUpper(method) + " " + label
+					if isMarked(operation) {
+						removed = append(removed, publishRemoval{Kind: "operation", Location: location, Reason: reason})
+						continue
+					}
+					remaining++
+					content = append(content, item.Content[j], operation)
+				}
+				item.Content = content
+				filterParameters(item, label)
+				for j := 0; j+1 < len(item.Content); j += 2 {
+					if method := item.Content[j].Value; httpMethods[method] {
+						filterParameters(item.Content[j+1], strings.ToUpper(method)+" "+label)
+					}
+				}
+			}
+			if operations > 0 && remaining == 0 {
+				removed = append(removed, publishRemoval{Kind: kind, Location: label, Reason: "sem operações restantes"})
+				continue
+			}
+			kept = append(kept, key, item)
+		}
+		items.Content = kept
+	}
+
+	// Parâmetros marcados em components (ou na raiz do Swagger 2.0)
+	for _, section := range sections {
+		if !strings.HasSuffix(section.pointer, "/parameters") {
+			continue
+		}
+		kept := section.node.Content[:0:0]
+		for i := 0; i+1 < len(section.node.Content); i += 2 {
+			if isMarked(section.node.Content[i+1]) {
+				delete(before, section.pointer+"/"+escapePointer(section.node.Content[i].Value))
+				removed = append(removed, publishRemoval{Kind: "parameter", Location: "#" + section.pointer + "/" + escapePointer(section.node.Content[i].Value), Reason: reason})
+				continue
+			}
+			kept = append(kept, section.node.Content[i], section.node.Content[i+1])
+		}
+		section.node.Content = kept
+	}
+
+	// Componentes que eram referenciados e deixaram de ser
+	after := reachableComponents(doc, sections)
+	for _, section := range sections {
+		kept := section.node.Content[:0:0]
+		for i := 0; i+1 < len(section.node.Content); i += 2 {
+			pointer := section.pointer + "/" + escapePointer(section.node.Content[i].Value)
+			if before[pointer] && !after[pointer] {
+				removed = append(removed, publishRemoval{Kind: "component", Location: "#" + pointer, Reason: "não é mais referenciado"})
+				continue
+			}
+			kept = append(kept, section.node.Content[i], section.node.Content[i+1])
+		}
+		// Uma seção que ficou vazia sai do documento, e components também
+		if len(kept) == 0 && len(section.node.Content) > 0 {
+			removeKey(section.parent, section.name)
+			if components := mappingValue(doc, "components"); section.parent == components && len(components.Content) == 0 {
+				removeKey(doc, "components")
+			}
+		}
+		section.node.Content = kept
+	}
+	return removed
+}
+
+// Indica se o objeto tem a extensão marker com valor true; um $ref local é seguido
+// até o objeto referenciado
+func publishMarked(doc, node *yaml.Node, marker string) bool {
+	for depth := 0; node != nil && node.Kind == yaml.MappingNode && depth < 16; depth++ {
+		if value := mappingValue(node, marker); value != nil {
+			return value.Kind == yaml.ScalarNode && strings.EqualFold(value.Value, "true")
+		}
+		ref := mappingValue(node, "$ref")
+		if ref == nil {
+			return false
+		}
+		file, pointer := splitRef(ref.Value)
+		if file != "" {
+			return false
+		}
+		next, err := resolvePointer(doc, pointer)
+		if err != nil {
+			return false
+		}
+		node = next
+	}
+	return false
+}
+
+// Nome do parâmetro para o resumo, com o local (in); um $ref mostra o destino
+func parameterLabel(doc, param *yaml.Node) string {
+	target := param
+	if ref := mappingValue(param, "$ref"); ref != nil {
+		if file, pointer := splitRef(ref.Value); file == "" {
+			if next, err := resolvePointer(doc, pointer); err == nil {
+				target = next
+			}
+		}
+		if mappingValue(target, "name") == nil {
+			return ref.Value
+		}
+	}
+	name, in := mappingValue(target, "name"), mappingValue(target, "in")
+	if name == nil {
+		return "(sem nome)"
+	}
+	if in != nil {
+		return fmt.Sprintf("%s (%s)", name.Value, in.Value)
+	}
+	return name.Value
+}
+
+// Uma seção de componentes: o ponteiro (ex.: /components/schemas) e o objeto
+type componentSection struct {
+	pointer      string
+	name         string
+	parent, node *yaml.Node
+}
+
+// Seções de componentes do documento: as de components ou, no Swagger 2.0, as
+// equivalentes na raiz
+func componentSections(rootNode *yaml.Node) []componentSection {
+	doc := documentContent(rootNode)
+	var sections []componentSection
+	if isSwagger2(rootNode) {
+		for _, name := range []string{"definitions", "parameters", "responses", "securityDefinitions"} {
+			if node := mappingValue(doc, name); node != nil && node.Kind == yaml.MappingNode {
+				sections = append(sections, componentSection{pointer: "/" + name, name: name, parent: doc, node: node})
+			}
+		}
+		return sections
+	}
+	components := mappingValue(doc, "components")
+	if components == nil || components.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(components.Content); i += 2 {
+		name, node := components.Content[i].Value, components.Content[i+1]
+		if !strings.HasPrefix(name, "x-") && node.Kind == yaml.MappingNode {
+			sections = append(sections, componentSection{pointer: "/components/" + escapePointer(name), name: name, parent: components, node: node})
+		}
+	}
+	return sections
+}
+
+// Função para montar o índice dos componentes alcançáveis a partir do restante do
+// documento (paths, webhooks, security...), seguindo os $refs locais de componente
+// em componente. Os esquemas de segurança são alcançados pelo nome, nos requisitos
+// de security da raiz e das operações.
+func reachableComponents(doc *yaml.Node, sections []componentSection) map[string]bool {
+	owners := map[string]*yaml.Node{}
+	for _, section := range sections {
+		for i := 0; i+1 < len(section.node.Content); i += 2 {
+			owners[section.pointer+"/"+escapePointer(section.node.Content[i].Value)] = section.node.Content[i+1]
+		}
+	}
+	roots := []string{"components"}
+	depth := 3 // /components/<tipo>/<nome>
+	if mappingValue(doc, "swagger") != nil {
+		roots, depth = []string{"definitions", "parameters", "responses", "securityDefinitions"}, 2
+	}
+	inComponents := func(path string) bool {
+		for _, root := range roots {
+			if rest := strings.TrimPrefix(path, "$."+root); rest != path && (rest == "" || rest[0] == '.' || rest[0] == '[') {
+				return true
+			}
+		}
+		return false
+	}
+
+	reached := map[string]bool{}
+	var pending []string
+	reach := func(pointer string) {
+		// O componente é o prefixo do ponteiro com a seção e o nome
+		segments := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+		if len(segments) < depth {
+			return
+		}
+		owner := ""
+		for _, segment := range segments[:depth] {
+			owner += "/" + escapePointer(unescapePointer(segment))
+		}
+		if _, ok := owners[owner]; ok && !reached[owner] {
+			reached[owner] = true
+			pending = append(pending, owner)
+		}
+	}
+	visitRef := func(ref *yaml.Node, _ string) {
+		if file, pointer := splitRef(ref.Value); file == "" {
+			reach(pointer)
+		}
+	}
+
+	forEachRef(doc, func(ref *yaml.Node, path string) {
+		if !inComponents(path) {
+			visitRef(ref, path)
+		}
+	})
+	schemes := "/components/securitySchemes/"
+	if depth == 2 {
+		schemes = "/securityDefinitions/"
+	}
+	requirements := []*yaml.Node{mappingValue(doc, "security")}
+	for _, section := range []string{"paths", "webhooks"} {
+		if items := mappingValue(doc, section); items != nil && items.Kind == yaml.MappingNode {
+			for i := 1; i < len(items.Content); i += 2 {
+				for j := 0; j+1 < len(items.Content[i].Content); j += 2 {
+					if httpMethods[items.Content[i].Content[j].Value] {
+						requirements = append(requirements, mappingValue(items.Content[i].Content[j+1], "security"))
+					}
+				}
+			}
+		}
+	}
+	for _, security := range requirements {
+		if security == nil || security.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, requirement := range security.Content {
+			for i := 0; i+1 < len(requirement.Content); i += 2 {
+				reach(schemes + escapePointer(requirement.Content[i].Value))
+			}
+		}
+	}
+
+	for len(pending) > 0 {
+		owner := pending[0]
+		pending = pending[1:]
+		forEachRef(owners[owner], visitRef)
+	}
+	return reached
+}
+
+// Função para conferir o documento publicado: ele é gravado temporariamente ao lado da
+// especificação (para que os $refs relativos continuem válidos), lido e resolvido de
+// novo; problemas de estrutura e $refs quebrados impedem a publicação
+func verifyPublished(inputFile string, published []byte, format string) error {
+	ext := ".yaml"
+	if format == formatJSON {
+		ext = ".json"
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(inputFile), ".publish-*"+ext)
+	if err != nil {
+		return fmt.Errorf("erro ao criar arquivo temporário: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(published); err != nil {
+		tmp.Close()
+		return fmt.Errorf("erro ao gravar arquivo temporário: %v", err)
+	}
+	tmp.Close()
+
+	// O documento publicado é sempre gravado em UTF-8 e com um único documento
+	settings := flagSettings().with(func(s *runSettings) { s.encoding, s.document = "utf-8", 0 })
+	spec, err := indexAndResolve(context.Background(), settings, tmp.Name())
+	if err != nil {
+		return fmt.Errorf("o documento publicado não pôde ser resolvido: %v", err)
+	}
+	problems := spec.inputProblems
+	for _, e := range spec.report.Errors {
+		problem := e.Message
+		if e.Path != "" {
+			problem = e.Path + ": " + problem
+		}
+		problems = append(problems, problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("o documento publicado não é uma especificação completa; nada foi gravado:\n   - %s", strings.Join(problems, "\n   - "))
+	}
+	return nil
+}
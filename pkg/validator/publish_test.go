@@ -0,0 +1,95 @@
+package validator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const publishSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas:
+    get:
+      parameters:
+        - $ref: '#/components/parameters/pagina'
+        - {name: x-debug, in: header, x-internal: true, schema: {type: boolean}}
+      responses:
+        '200': {description: ok, content: {application/json: {schema: {$ref: '#/components/schemas/Conta'}}}}
+    delete:
+      x-internal: true
+      responses:
+        '200': {description: ok, content: {application/json: {schema: {$ref: '#/components/schemas/Auditoria'}}}}
+  /admin:
+    x-internal: true
+    get:
+      responses:
+        '200': {description: ok, content: {application/json: {schema: {$ref: '#/components/schemas/Admin'}}}}
+components:
+  parameters:
+    pagina: {name: page, in: query, schema: {type: integer}}
+  schemas:
+    Conta: {type: object}
+    Auditoria:
+      type: object
+      properties:
+        usuario: {$ref: '#/components/schemas/Usuario'}
+    Usuario: {type: string}
+    Admin: {type: object}
+    Legado: {type: string}
+`
+
+// publish remove a operação, o parâmetro e o path marcados e os componentes que só
+// eles referenciavam (inclusive de forma transitiva), mantém o componente que já não
+// era referenciado e lista cada remoção; com --dry-run, nada é gravado
+func TestPublish(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"api.yaml": publishSpec})
+	spec := filepath.Join(dir, "api.yaml")
+	output := filepath.Join(dir, "publico.yaml")
+
+	code, out := runCommand(t, "publish", "--dry-run", "--format", "json", "-o", output, spec)
+	if code != exitOK {
+		t.Fatalf("código %d\n%s", code, out)
+	}
+	var summary publishSummary
+	if err := json.Unmarshal([]byte(out), &summary); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	var removed []string
+	for _, r := range summary.Removed {
+		removed = append(removed, r.Kind+" "+r.Location)
+	}
+	want := []string{
+		"operation DELETE /contas",
+		"parameter x-debug (header) em GET /contas",
+		"path /admin",
+		"component #/components/schemas/Auditoria",
+		"component #/components/schemas/Usuario",
+		"component #/components/schemas/Admin",
+	}
+	if !reflect.DeepEqual(removed, want) {
+		t.Errorf("removidos:\n%s\nesperado:\n%s", strings.Join(removed, "\n"), strings.Join(want, "\n"))
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Error("--dry-run gravou a especificação")
+	}
+
+	if code, out := runCommand(t, "publish", "-o", output, spec); code != exitOK || !strings.Contains(out, "🔒 6 itens removidos de "+spec) {
+		t.Fatalf("código %d\n%s", code, out)
+	}
+	published := string(mustReadFile(t, output))
+	for _, gone := range []string{"x-internal", "delete:", "/admin", "Auditoria", "Usuario", "Admin:"} {
+		if strings.Contains(published, gone) {
+			t.Errorf("%q continua na especificação publicada:\n%s", gone, published)
+		}
+	}
+	for _, kept := range []string{"pagina:", "Conta:", "Legado:"} {
+		if !strings.Contains(published, kept) {
+			t.Errorf("%q foi removido da especificação publicada:\n%s", kept, published)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Função para conferir, antes da resolução, se o destino de cada $ref existe: o
+// arquivo (para referências a outros arquivos locais) e o ponteiro dentro dele.
+// Cada $ref quebrado vira um erro com o local da referência, o destino e os nomes
+// existentes mais parecidos; o rolodex relataria apenas uma falha genérica.
+// Referências remotas não são conferidas aqui. Retorna os arquivos locais alcançados
+// e lidos sem erro (caminhos absolutos), os únicos que o rolodex indexa.
+func checkRefTargets(s *runSettings, inputFile string, root *yaml.Node, report *resolutionReport) []string {
+	rootAbs, _ := filepath.Abs(inputFile)
+	docs := map[string]*yaml.Node{rootAbs: documentContent(root)}
+	queue := []string{rootAbs}
+	var files []string
+	// Os erros usam o mesmo arquivo que o rolodex atribui (a especificação ou o caminho absoluto)
+	display := func(abs string) string {
+		if abs == rootAbs {
+			return inputFile
+		}
+		return abs
+	}
+	baseDir := func(file string) string {
+		if file == rootAbs {
+			return s.refsDir(rootAbs)
+		}
+		return filepath.Dir(file)
+	}
+
+	for len(queue) > 0 {
+		file := queue[0]
+		queue = queue[1:]
+		forEachRef(docs[file], func(ref *yaml.Node, path string) {
+			targetFile, pointer := splitRef(ref.Value)
+			if isRemoteURL(targetFile) {
+				return
+			}
+			targetAbs := file
+			if targetFile != "" {
+				targetAbs = filepath.Join(baseDir(file), filepath.FromSlash(targetFile))
+			}
+			fail := func(message, suggestion string) {
+				report.Errors = append(report.Errors, resolutionError{
+					File:       reportPath(display(file)),
+					Line:       ref.Line,
+					Column:     ref.Column,
+					Path:       path,
+					Message:    fmt.Sprintf("$ref para %s: %s", ref.Value, message),
+					Suggestion: suggestion,
+				})
+			}
+
+			doc, loaded := docs[targetAbs]
+			if !loaded {
+				data, err := s.readSpecFile(targetAbs)
+				if err != nil {
+					if _, statErr := s.statInput(targetAbs); os.IsNotExist(statErr) {
+						name := filepath.Base(targetFile)
+						siblings := siblingFiles(s, targetAbs)
+						suggestion := ""
+						if closest := closestName(name, siblings); closest != "" {
+							suggestion = strings.TrimSuffix(targetFile, name) + closest
+							if pointer != "" {
+								suggestion += "#" + pointer
+							}
+						}
+						fail("o arquivo "+targetFile+" não existe"+suggestionSuffix(name, siblings), suggestion)
+					} else {
+						fail(err.Error(), "")
+					}
+					docs[targetAbs] = nil
+					return
+				}
+				parsed, err := parseSpec(data, display(targetAbs))
+				if err != nil {
+					fail(err.Error(), "")
+					docs[targetAbs] = nil
+					return
+				}
+				doc = documentContent(parsed)
+				docs[targetAbs] = doc
+				queue = append(queue, targetAbs)
+				files = append(files, targetAbs)
+			}
+			if doc == nil {
+				return // o arquivo já falhou e foi relatado na primeira referência
+			}
+			if message, fixed := missingPointer(doc, pointer); message != "" {
+				suggestion := ""
+				if fixed != "" {
+					suggestion = targetFile + "#" + fixed
+				}
+				fail(message, suggestion)
+			}
+		})
+	}
+	return files
+}
+
+// Conteúdo do documento (sem o nó DocumentNode)
+func documentContent(node *yaml.Node) *yaml.Node {
+	if node != nil && node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		return node.Content[0]
+	}
+	return node
+}
+
+// Chama visit para cada $ref do documento, com o caminho JSONPath do objeto que o
+// contém. Valores de exemplo (example e examples.*.value) são dados, não referências.
+func forEachRef(doc *yaml.Node, visit func(ref *yaml.Node, path string)) {
+	var walk func(node *yaml.Node, path string, keys []string)
+	walk = func(node *yaml.Node, path string, keys []string) {
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i].Value, node.Content[i+1]
+				if key == "$ref" && value.Kind == yaml.ScalarNode {
+					visit(value, path)
+					continue
+				}
+				if key == "example" || key == "value" && len(keys) >= 2 && keys[len(keys)-2] == "examples" {
+					continue
+				}
+				walk(value, joinPath(path, key), append(keys[:len(keys):len(keys)], key))
+			}
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				walk(item, fmt.Sprintf("%s[%d]", path, i), keys)
+			}
+		}
+	}
+	if doc != nil {
+		walk(doc, "$", nil)
+	}
+}
+
+// Confere um JSON Pointer e, quando um segmento não existe, descreve o problema com
+// as chaves existentes mais parecidas naquele ponto e retorna o ponteiro corrigido
+// com a sugestão (vazio sem sugestão); a mensagem é vazia quando o ponteiro é válido
+func missingPointer(doc *yaml.Node, pointer string) (message, fixed string) {
+	if pointer == "" || pointer == "/" {
+		return "", ""
+	}
+	node := doc
+	walked := "#"
+	raws := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for n, raw := range raws {
+		segment := unescapePointer(raw)
+		next, err := resolvePointer(node, "/"+raw)
+		if err != nil {
+			if node.Kind != yaml.MappingNode {
+				return fmt.Sprintf("%s não é um objeto", walked), ""
+			}
+			keys := make([]string, 0, len(node.Content)/2)
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				keys = append(keys, node.Content[i].Value)
+			}
+			where := "em " + walked
+			if walked == "#" {
+				where = "na raiz do documento"
+			}
+			if closest := closestName(segment, keys); closest != "" {
+				corrected := append(append(append([]string{}, raws[:n]...), escapePointer(closest)), raws[n+1:]...)
+				fixed = "/" + strings.Join(corrected, "/")
+			}
+			return fmt.Sprintf("%s não existe %s%s", segment, where, suggestionSuffix(segment, keys)), fixed
+		}
+		node = next
+		walked += "/" + raw
+	}
+	return "", ""
+}
+
+// Arquivos do mesmo diretório de path, candidatos a sugestão para um arquivo inexistente
+func siblingFiles(s *runSettings, path string) []string {
+	entries, err := s.readInputDir(filepath.Dir(path))
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	return names
+}
@@ -0,0 +1,61 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const refCheckSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas:
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/AccountIdentificaton'}
+        '400':
+          description: erro
+          content:
+            application/json:
+              schema: {$ref: 'schemas/eros.yaml#/Erro'}
+        '404':
+          description: erro
+          content:
+            application/json:
+              schema: {$ref: 'schemas/erros.yaml#/Eror'}
+components:
+  schemas:
+    AccountIdentification: {type: string}
+`
+
+// Cada $ref quebrado (componente, arquivo ou ponteiro no arquivo) é relatado uma vez,
+// no local da referência, com o nome existente mais parecido como sugestão
+func TestRefTargets(t *testing.T) {
+	result, err := Validate(context.Background(), nil, Options{Source: "api.yaml", FS: MemFS{
+		"api.yaml":           []byte(refCheckSpec),
+		"schemas/erros.yaml": []byte("Erro: {type: object}\n"),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range result.Violations {
+		if v.RuleID == "ref-resolution" {
+			got = append(got, fmt.Sprintf("%s:%d:%d %s [%s]", v.File, v.Line, v.Column, v.Message, v.Suggestion))
+		}
+	}
+	want := []string{
+		"api.yaml:11:30 $ref para #/components/schemas/AccountIdentificaton: AccountIdentificaton não existe em #/components/schemas (você quis dizer AccountIdentification?) [#/components/schemas/AccountIdentification]",
+		"api.yaml:16:30 $ref para schemas/eros.yaml#/Erro: o arquivo schemas/eros.yaml não existe (você quis dizer erros.yaml?) [schemas/erros.yaml#/Erro]",
+		"api.yaml:21:30 $ref para schemas/erros.yaml#/Eror: Eror não existe na raiz do documento (você quis dizer Erro?) [schemas/erros.yaml#/Erro]",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("violações:\n%s\nesperado:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
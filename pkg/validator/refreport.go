@@ -0,0 +1,305 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Arquivo do relatório de uso das referências (--ref-report); Markdown quando
+// termina em .md, senão JSON
+var refReportFile string
+
+// Local de um $ref no conjunto de arquivos da especificação
+type refLocation struct {
+	File string `json:"file"`
+	Path string `json:"path"`
+	Line int    `json:"line"`
+}
+
+// Componente com as referências que chegam até ele e as que saem dele
+type componentUsage struct {
+	Ref          string        `json:"ref"`
+	Defined      *refLocation  `json:"defined,omitempty"`
+	Inbound      []refLocation `json:"inbound"`
+	Outbound     []string      `json:"outbound"`
+	Dependents   int           `json:"transitiveDependents"`   // componentes que dependem dele, direta ou indiretamente
+	Dependencies int           `json:"transitiveDependencies"` // componentes dos quais ele depende, direta ou indiretamente
+	Recursive    bool          `json:"recursive,omitempty"`    // referencia a si mesmo
+}
+
+// Relatório de uso das referências, ordenado pelos componentes com mais dependentes
+type refUsageReport struct {
+	File       string           `json:"file"`
+	Components []componentUsage `json:"components"`
+}
+
+// Um $ref encontrado: onde ele está e para onde aponta (arquivo relativo à
+// especificação raiz + ponteiro; vazio para a própria raiz)
+type foundRef struct {
+	location      refLocation
+	file, pointer string // local do $ref
+	target        string
+}
+
+// Função para montar o relatório de uso a partir dos documentos indexados, antes
+// da resolução (que substitui os $refs pelo conteúdo). docs associa o caminho de
+// cada arquivo indexado à sua árvore; a raiz usa inputFile.
+func collectRefUsage(inputFile string, docs map[string]*yaml.Node) *refUsageReport {
+	rootAbs, _ := filepath.Abs(inputFile)
+	baseDir := filepath.Dir(rootAbs)
+	fileKey := func(file string) string {
+		abs, err := filepath.Abs(file)
+		if err != nil || abs == rootAbs {
+			return ""
+		}
+		if rel, err := filepath.Rel(baseDir, abs); err == nil {
+			return filepath.ToSlash(rel)
+		}
+		return filepath.ToSlash(abs)
+	}
+	rootTarget := func(ref string) string {
+		if file, pointer := splitRef(ref); file == filepath.Base(rootAbs) && pointer != "" {
+			return joinRef("", pointer)
+		}
+		return ref
+	}
+	displayFile := func(key string) string {
+		if key == "" {
+			return reportPath(inputFile)
+		}
+		return key
+	}
+
+	// Percorrer cada arquivo guardando os $refs com os caminhos JSONPath e JSON Pointer
+	var refs []foundRef
+	trees := map[string]*yaml.Node{}
+	files := make([]string, 0, len(docs))
+	for file := range docs {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		doc := docs[file]
+		if doc == nil {
+			continue
+		}
+		if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+			doc = doc.Content[0]
+		}
+		key := fileKey(file)
+		if _, ok := trees[key]; ok {
+			continue
+		}
+		trees[key] = doc
+		var walk func(node *yaml.Node, path, pointer string)
+		walk = func(node *yaml.Node, path, pointer string) {
+			switch node.Kind {
+			case yaml.MappingNode:
+				for i := 0; i+1 < len(node.Content); i += 2 {
+					k, v := node.Content[i], node.Content[i+1]
+					if k.Value == "$ref" && v.Kind == yaml.ScalarNode {
+						refs = append(refs, foundRef{
+							location: refLocation{File: displayFile(key), Path: path, Line: v.Line},
+							file:     key,
+							pointer:  pointer,
+							target:   rootTarget(refTarget(key, v.Value)),
+						})
+						continue
+					}
+					walk(v, joinPath(path, k.Value), pointer+"/"+escapePointer(k.Value))
+				}
+			case yaml.SequenceNode:
+				for i, item := range node.Content {
+					walk(item, fmt.Sprintf("%s[%d]", path, i), fmt.Sprintf("%s/%d", pointer, i))
+				}
+			}
+		}
+		walk(doc, "$", "")
+	}
+
+	// Componentes: os definidos em components no raiz e os alvos das referências
+	// que não estão dentro de outro componente
+	candidates := map[string]bool{}
+	if section := mappingValue(trees[""], "components"); section != nil && section.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(section.Content); i += 2 {
+			kind, entries := section.Content[i].Value, section.Content[i+1]
+			if entries.Kind != yaml.MappingNode || strings.HasPrefix(kind, "x-") {
+				continue
+			}
+			for j := 0; j+1 < len(entries.Content); j += 2 {
+				candidates["#/components/"+kind+"/"+escapePointer(entries.Content[j].Value)] = true
+			}
+		}
+	}
+	for _, r := range refs {
+		candidates[r.target] = true
+	}
+	containing := func(ref string) string {
+		best := ""
+		for c := range candidates {
+			if refContains(c, ref) && (best == "" || len(c) < len(best)) {
+				best = c
+			}
+		}
+		return best
+	}
+	usage := map[string]*componentUsage{}
+	for c := range candidates {
+		if containing(c) == c {
+			usage[c] = &componentUsage{Ref: c, Inbound: []refLocation{}, Outbound: []string{}}
+		}
+	}
+
+	// Ligar cada $ref ao componente que o contém e ao componente de destino
+	outbound := map[string]map[string]bool{}
+	for _, r := range refs {
+		target := containing(r.target)
+		if target == "" {
+			continue
+		}
+		usage[target].Inbound = append(usage[target].Inbound, r.location)
+		owner := containing(joinRef(r.file, r.pointer))
+		if owner == "" || usage[owner] == nil {
+			continue
+		}
+		if owner == target {
+			usage[owner].Recursive = true
+			continue
+		}
+		if outbound[owner] == nil {
+			outbound[owner] = map[string]bool{}
+		}
+		if !outbound[owner][target] {
+			outbound[owner][target] = true
+			usage[owner].Outbound = append(usage[owner].Outbound, target)
+		}
+	}
+
+	// Local de definição e dependências transitivas (ciclos não são contados duas vezes)
+	for ref, u := range usage {
+		file, pointer := splitRef(ref)
+		if tree := trees[file]; tree != nil {
+			if node, err := resolvePointer(tree, pointer); err == nil {
+				u.Defined = &refLocation{File: displayFile(file), Path: pointerToPath(pointer), Line: node.Line}
+			}
+		}
+		sort.Strings(u.Outbound)
+		reached := map[string]bool{ref: true}
+		queue := []string{ref}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for next := range outbound[current] {
+				if !reached[next] {
+					reached[next] = true
+					queue = append(queue, next)
+					usage[next].Dependents++
+				}
+			}
+		}
+		u.Dependencies = len(reached) - 1
+	}
+
+	report := &refUsageReport{File: reportPath(inputFile), Components: []componentUsage{}}
+	for _, u := range usage {
+		report.Components = append(report.Components, *u)
+	}
+	sort.Slice(report.Components, func(i, j int) bool {
+		a, b := report.Components[i], report.Components[j]
+		if a.Dependents != b.Dependents {
+			return a.Dependents > b.Dependents
+		}
+		if len(a.Inbound) != len(b.Inbound) {
+			return len(a.Inbound) > len(b.Inbound)
+		}
+		return a.Ref < b.Ref
+	})
+	return report
+}
+
+// Destino de um $ref escrito no arquivo file (relativo à raiz), no mesmo formato:
+// "#/ponteiro" para a raiz, "arquivo#/ponteiro" para os demais. URLs ficam como estão.
+func refTarget(file, ref string) string {
+	refFile, pointer := splitRef(ref)
+	switch {
+	case strings.HasPrefix(refFile, "http://") || strings.HasPrefix(refFile, "https://"):
+		return ref
+	case refFile != "":
+		refFile = filepath.ToSlash(filepath.Clean(filepath.Join(filepath.Dir(filepath.FromSlash(file)), filepath.FromSlash(refFile))))
+	default:
+		refFile = file
+	}
+	return joinRef(refFile, pointer)
+}
+
+func joinRef(file, pointer string) string {
+	if pointer == "" {
+		return file
+	}
+	return file + "#" + pointer
+}
+
+// Indica se o local ref está dentro do componente c (ou é o próprio componente)
+func refContains(c, ref string) bool {
+	cFile, cPointer := splitRef(c)
+	file, pointer := splitRef(ref)
+	return cFile == file && (pointer == cPointer || strings.HasPrefix(pointer, cPointer+"/"))
+}
+
+// Converte um JSON Pointer para a notação JSONPath usada nas mensagens
+func pointerToPath(pointer string) string {
+	path := "$"
+	if pointer == "" {
+		return path
+	}
+	for _, segment := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		path = joinPath(path, unescapePointer(segment))
+	}
+	return path
+}
+
+// Função para salvar o relatório de uso, em Markdown ou JSON pela extensão do arquivo
+func writeRefReport(path string, report *refUsageReport) error {
+	if !strings.EqualFold(filepath.Ext(path), ".md") {
+		return writeJSONReport(path, report)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Uso das referências: %s\n\n", report.File)
+	b.WriteString("| Componente | Referências diretas | Dependentes (transitivos) | Dependências (transitivas) |\n")
+	b.WriteString("|---|---:|---:|---:|\n")
+	for _, c := range report.Components {
+		fmt.Fprintf(&b, "| `%s` | %d | %d | %d |\n", c.Ref, len(c.Inbound), c.Dependents, c.Dependencies)
+	}
+	for _, c := range report.Components {
+		fmt.Fprintf(&b, "\n## `%s`\n\n", c.Ref)
+		if c.Defined != nil {
+			fmt.Fprintf(&b, "Definido em %s (%s, linha %d).\n\n", c.Defined.File, c.Defined.Path, c.Defined.Line)
+		}
+		if len(c.Inbound) == 0 {
+			b.WriteString("Nenhuma referência aponta para este componente.\n")
+		} else {
+			b.WriteString("Referenciado em:\n\n")
+			for _, l := range c.Inbound {
+				fmt.Fprintf(&b, "- %s:%d `%s`\n", l.File, l.Line, l.Path)
+			}
+		}
+		if len(c.Outbound) > 0 {
+			b.WriteString("\nReferencia:\n\n")
+			for _, ref := range c.Outbound {
+				fmt.Fprintf(&b, "- `%s`\n", ref)
+			}
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório de %s: %v", path, err)
+	}
+	if err := writeFileAtomic(path, []byte(b.String())); err != nil {
+		return fmt.Errorf("erro ao salvar relatório %s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/pb33f/libopenapi/index"
+	"gopkg.in/yaml.v3"
+)
+
+// Opções de busca de referências externas
+var (
+	refsBaseDir     string
+	allowRemoteRefs bool
+	remoteTimeout   = 30 * time.Second
+	failOnCircular  bool
+	keepRefs        stringList // padrões de $ref mantidos sem resolver (--keep-refs)
+)
+
+func registerRefFlags(fs *flag.FlagSet) {
+	fs.StringVar(&refsBaseDir, "base-dir", "", "diretório base para $refs relativos (padrão: o diretório da especificação)")
+	fs.BoolVar(&allowRemoteRefs, "allow-remote-refs", false, "permite resolver $refs https:// remotos")
+	fs.DurationVar(&remoteTimeout, "remote-timeout", remoteTimeout, "tempo máximo de cada busca de $ref remoto")
+	fs.BoolVar(&failOnCircular, "fail-on-circular", false, "trata referências circulares como erro")
+}
+
+// Função para criar o rolodex de uma especificação: os arquivos locais referenciados
+// (files, já conferidos por checkRefTargets) a partir do diretório base e, com
+// --allow-remote-refs, URLs remotas (com o cache e as opções de fetchRemote). Os
+// demais arquivos do diretório não são lidos: o rolodex percorreria o diretório
+// inteiro, e um arquivo que não passa nos limites derrubaria a indexação.
+func newRolodex(s *runSettings, inputFile string, rootNode *yaml.Node, files []string) (*index.Rolodex, error) {
+	absFile, err := filepath.Abs(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter o caminho de %s: %v", inputFile, err)
+	}
+	baseDir := s.baseDir
+	if baseDir == "" {
+		baseDir = filepath.Dir(absFile)
+	}
+	if baseDir, err = filepath.Abs(baseDir); err != nil {
+		return nil, fmt.Errorf("erro ao obter o diretório base %s: %v", s.baseDir, err)
+	}
+
+	// Configuração aberta: referências para outros arquivos são seguidas
+	indexConfig := index.CreateOpenAPIIndexConfig()
+	indexConfig.BasePath = baseDir
+	indexConfig.SpecAbsolutePath = absFile
+	indexConfig.AllowFileLookup = true
+	indexConfig.AllowRemoteLookup = s.allowRemoteRefs
+	// Os problemas das referências vêm do índice; o log da biblioteca iria para stdout
+	indexConfig.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	rolodex := index.NewRolodex(indexConfig)
+	rolodex.SetRootNode(rootNode)
+
+	// Sem filtro o rolodex lê todos os arquivos: sem arquivos referenciados, fica sem o FS local
+	var filters []string
+	for _, file := range files {
+		if rel, err := filepath.Rel(baseDir, file); err == nil && filepath.IsLocal(rel) {
+			filters = append(filters, filepath.ToSlash(rel))
+		}
+	}
+	if len(filters) > 0 {
+		dirFS, err := s.inputDirFS(baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao configurar o acesso a arquivos em %s: %v", baseDir, err)
+		}
+		localFS, err := index.NewLocalFSWithConfig(&index.LocalFSConfig{
+			BaseDirectory: baseDir,
+			DirFS:         decodingFS{fsys: dirFS, settings: s},
+			FileFilters:   filters,
+			IndexConfig:   indexConfig,
+			Logger:        indexConfig.Logger,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("erro ao configurar o acesso a arquivos em %s: %v", baseDir, err)
+		}
+		rolodex.AddLocalFS(baseDir, localFS)
+	}
+
+	if s.allowRemoteRefs {
+		indexConfig.RemoteURLHandler = s.remoteURLHandler
+		remoteFS, err := index.NewRemoteFSWithConfig(indexConfig)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao configurar o acesso a $refs remotos: %v", err)
+		}
+		rolodex.AddRemoteFS("", remoteFS)
+	}
+	return rolodex, nil
+}
+
+// Coleta os erros de referência e os ciclos de todos os índices do rolodex, com o arquivo de cada um
+func collectReferenceErrors(rolodex *index.Rolodex, inputFile string, report *resolutionReport) {
+	if root := rolodex.GetRootIndex(); root != nil {
+		for _, err := range root.GetReferenceIndexErrors() {
+			report.addErrors(inputFile, err)
+		}
+		for _, cycle := range root.GetCircularReferences() {
+			report.addCycle(inputFile, cycle)
+		}
+	}
+	for _, idx := range rolodex.GetIndexes() {
+		for _, err := range idx.GetReferenceIndexErrors() {
+			report.addErrors(idx.GetSpecAbsolutePath(), err)
+		}
+		for _, cycle := range idx.GetCircularReferences() {
+			report.addCycle(idx.GetSpecAbsolutePath(), cycle)
+		}
+	}
+}
+
+// Violação de um ciclo de referências; a severidade depende de --fail-on-circular
+func cycleViolation(s *runSettings, cycle string) Violation {
+	severity := "warning"
+	if s.failOnCircular {
+		severity = "error"
+	}
+	return Violation{RuleID: "circular-ref", Severity: severity, Message: "referência circular: " + cycle}
+}
+
+// Referência mantida como $ref: o nó é restaurado depois da resolução
+type keptRef struct {
+	node     *yaml.Node
+	original *yaml.Node
+}
+
+// Função para compilar os padrões de --keep-refs (glob sobre o $ref, ex.: #/components/schemas/Error*)
+func keepRefMatcher(patterns []string) func(ref string) bool {
+	var res []*regexp.Regexp
+	for _, p := range patterns {
+		res = append(res, regexp.MustCompile("^"+globToRegexp(p)+"$"))
+	}
+	return func(ref string) bool {
+		_, pointer := splitRef(ref)
+		for _, re := range res {
+			if re.MatchString(ref) || re.MatchString("#"+pointer) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Guarda os nós com $ref que casam com --keep-refs, antes que o resolver os substitua
+func collectKeptRefs(nodes []*yaml.Node, match func(ref string) bool) []keptRef {
+	var kept []keptRef
+	seen := map[*yaml.Node]bool{}
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		if node == nil || seen[node] {
+			return
+		}
+		seen[node] = true
+		if node.Kind == yaml.MappingNode {
+			if ref := mappingValue(node, "$ref"); ref != nil && ref.Kind == yaml.ScalarNode && match(ref.Value) {
+				kept = append(kept, keptRef{node: node, original: deepCopyNode(node)})
+				return
+			}
+		}
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+	for _, node := range nodes {
+		walk(node)
+	}
+	return kept
+}
+
+// Devolve os $refs mantidos ao documento resolvido
+func restoreKeptRefs(kept []keptRef) {
+	for _, k := range kept {
+		*k.node = *k.original
+	}
+}
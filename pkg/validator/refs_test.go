@@ -0,0 +1,79 @@
+package validator
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+const refsSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas:
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: 'schemas/conta.yaml#/Conta'}
+`
+
+// Só os arquivos referenciados são lidos: um vizinho acima do limite de tamanho ou
+// com YAML inválido não afeta a validação
+func TestUnreferencedFilesAreNotRead(t *testing.T) {
+	files := MemFS{
+		"api.yaml":           []byte(refsSpec),
+		"schemas/conta.yaml": []byte("Conta: {type: object, properties: {id: {type: string}}}\n"),
+		"grande.yaml":        []byte("a: " + strings.Repeat("x", 4096) + "\n"),
+		"quebrado.yaml":      []byte("a: [\n"),
+	}
+	result, err := Resolve(context.Background(), nil, Options{Source: "api.yaml", FS: files, MaxFileSize: 2048})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Errors) > 0 {
+		t.Errorf("erros inesperados: %q", result.Errors)
+	}
+	if !strings.Contains(string(result.Document), "id:") {
+		t.Errorf("o schema referenciado não foi resolvido:\n%s", result.Document)
+	}
+}
+
+// Um arquivo referenciado acima do limite é um erro de referência, não uma falha interna
+func TestReferencedFileOverLimit(t *testing.T) {
+	files := MemFS{
+		"api.yaml":           []byte(refsSpec),
+		"schemas/conta.yaml": []byte("Conta: {type: object, description: " + strings.Repeat("x", 4096) + "}\n"),
+	}
+	result, err := Resolve(context.Background(), nil, Options{Source: "api.yaml", FS: files, MaxFileSize: 2048})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Errors) == 0 {
+		t.Error("o arquivo acima do limite deveria ser relatado")
+	}
+}
+
+// O log da biblioteca sobre um $ref externo inexistente não vai para stdout, onde
+// corromperia as saídas JSON e SARIF
+func TestMissingExternalRefKeepsStdoutClean(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := os.Stdout
+	os.Stdout = w
+	_, resolveErr := Resolve(context.Background(), nil, Options{Source: "api.yaml", FS: MemFS{"api.yaml": []byte(refsSpec)}})
+	os.Stdout = saved
+	w.Close()
+	written, _ := io.ReadAll(r)
+	if resolveErr != nil {
+		t.Fatal(resolveErr)
+	}
+	if len(written) > 0 {
+		t.Errorf("saída inesperada em stdout: %s", written)
+	}
+}
@@ -0,0 +1,230 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pb33f/libopenapi/index"
+)
+
+// Versão do formato dos relatórios JSON (--format json, --report); muda quando um campo
+// existente muda de sentido, para que aggregate não some relatórios incompatíveis
+const reportSchemaVersion = 1
+
+// Relatório em JSON da execução (--format json)
+type jsonReport struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	Validation    *validationReport `json:"validation,omitempty"`
+	Resolution    *resolutionReport `json:"resolution,omitempty"`
+	Bundle        *bundleReport     `json:"bundle,omitempty"`
+	Split         []splitFile       `json:"split,omitempty"`
+}
+
+// Resumo da validação de um arquivo
+type validationReport struct {
+	File       string             `json:"file"`
+	Errors     int                `json:"errors"`
+	Warnings   int                `json:"warnings"`
+	Violations []Violation        `json:"violations"`
+	Coverage   *ruleCoverage      `json:"coverage,omitempty"` // com --coverage
+	Metrics    []operationMetrics `json:"metrics,omitempty"`  // complexidade dos schemas por operação
+	Maturity   *maturityReport    `json:"maturity,omitempty"` // operações por estágio de x-maturity
+
+	NotApplicable []string `json:"notApplicable,omitempty"` // regras com when ou scope que não se aplicam
+	Profile       string   `json:"profile,omitempty"`       // perfil de severidades aplicado
+
+	Duplicates []duplicateGroup `json:"duplicates,omitempty"` // schemas idênticos (--duplicates)
+
+	ExamplesMatrix []operationExamples `json:"examplesMatrix,omitempty"` // exemplos por resposta (--examples-matrix)
+
+	Cached bool `json:"cached,omitempty"` // resultado reaproveitado do cache de resultados
+}
+
+// Acrescenta ao resumo os detalhes da validação (cobertura, métricas, maturidade,
+// regras não aplicáveis, perfil de severidades, schemas duplicados e matriz de exemplos)
+func (r *validationReport) addDetails(details specDetails) {
+	r.Coverage = details.coverage
+	r.Metrics = details.metrics
+	r.Maturity = details.maturity
+	r.NotApplicable = details.notApplicable
+	r.Profile = details.profile
+	r.Duplicates = details.duplicates
+	r.ExamplesMatrix = details.examplesMatrix
+}
+
+// Monta o resumo da validação a partir das violações encontradas
+func newValidationReport(file string, violations []Violation) *validationReport {
+	report := &validationReport{File: reportPath(file), Violations: append([]Violation{}, violations...)}
+	report.Errors, report.Warnings = countViolations(violations)
+	return report
+}
+
+// Resumo da resolução de referências de um arquivo
+type resolutionReport struct {
+	File         string            `json:"file"`
+	RefsResolved map[string]int    `json:"refsResolved"`
+	RefsKept     int               `json:"refsKept"`
+	Stripped     map[string]int    `json:"stripped,omitempty"`
+	Artifact     *artifactInfo     `json:"artifact,omitempty"`
+	Expansion    *expansionReport  `json:"expansion,omitempty"`
+	Errors       []resolutionError `json:"errors"`
+	Cycles       []referenceCycle  `json:"circularReferences"`
+}
+
+// Ciclo de referências, do componente inicial até o ponto em que o caminho se repete
+type referenceCycle struct {
+	Chain    []cycleStep `json:"chain"`
+	Infinite bool        `json:"infinite"` // sem propriedade opcional que interrompa o ciclo
+}
+
+// Um componente do ciclo com a sua localização
+type cycleStep struct {
+	Name string `json:"name"`
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+}
+
+// Erro de referência com a localização no arquivo de origem
+type resolutionError struct {
+	File       string `json:"file"`
+	Line       int    `json:"line,omitempty"`
+	Column     int    `json:"column,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"` // $ref corrigido, quando há um destino parecido
+}
+
+// Registra os erros de indexação/resolução, desmembrando erros agrupados
+func (r *resolutionReport) addErrors(file string, err error) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			r.addErrors(file, e)
+		}
+		return
+	}
+
+	entry := resolutionError{File: reportPath(file), Message: err.Error()}
+	var resolvingErr *index.ResolvingError
+	var indexingErr *index.IndexingError
+	switch {
+	case errors.As(err, &resolvingErr) && resolvingErr.CircularReference != nil:
+		// Ciclos não são erros de resolução: são relatados à parte (--fail-on-circular)
+		r.addCycle(file, resolvingErr.CircularReference)
+		return
+	case errors.As(err, &resolvingErr):
+		if resolvingErr.ErrorRef != nil {
+			entry.Message = resolvingErr.ErrorRef.Error()
+		}
+		entry.Path = resolvingErr.Path
+		if resolvingErr.Node != nil {
+			entry.Line = resolvingErr.Node.Line
+			entry.Column = resolvingErr.Node.Column
+		}
+	case errors.As(err, &indexingErr):
+		if indexingErr.Err != nil {
+			entry.Message = indexingErr.Err.Error()
+		}
+		entry.Path = indexingErr.Path
+		if indexingErr.Node != nil {
+			entry.Line = indexingErr.Node.Line
+			entry.Column = indexingErr.Node.Column
+		}
+	}
+
+	// O rolodex e os índices podem relatar o mesmo erro; um $ref já relatado pela
+	// conferência dos destinos (checkRefTargets) não é repetido com a mensagem genérica
+	for _, existing := range r.Errors {
+		if existing.Message == entry.Message && existing.Line == entry.Line {
+			return
+		}
+		if entry.Line > 0 && existing.File == entry.File && existing.Line == entry.Line && strings.HasPrefix(existing.Message, "$ref para ") {
+			return
+		}
+	}
+	r.Errors = append(r.Errors, entry)
+}
+
+// Registra um ciclo de referências, ignorando ciclos já relatados por outro índice
+func (r *resolutionReport) addCycle(file string, result *index.CircularReferenceResult) {
+	cycle := referenceCycle{Chain: []cycleStep{}, Infinite: result.IsInfiniteLoop}
+	// A jornada já termina, em geral, no ponto em que o ciclo se fecha
+	steps := result.Journey
+	if loop := result.LoopPoint; loop != nil && (len(steps) == 0 || steps[len(steps)-1] == nil || steps[len(steps)-1].FullDefinition != loop.FullDefinition) {
+		steps = append(steps[:len(steps):len(steps)], loop)
+	}
+	for _, ref := range steps {
+		if ref == nil {
+			continue
+		}
+		step := cycleStep{Name: ref.Name, File: reportPath(file)}
+		if target, _ := splitRef(ref.FullDefinition); target != "" {
+			step.File = reportPath(target)
+		}
+		if ref.Node != nil {
+			step.Line = ref.Node.Line
+		}
+		cycle.Chain = append(cycle.Chain, step)
+	}
+	if len(cycle.Chain) == 0 {
+		return
+	}
+
+	for _, existing := range r.Cycles {
+		if existing.String() == cycle.String() {
+			return
+		}
+	}
+	r.Cycles = append(r.Cycles, cycle)
+}
+
+// Ciclo no formato A (arquivo:linha) → B (arquivo:linha) → A
+func (c referenceCycle) String() string {
+	parts := make([]string, len(c.Chain))
+	for i, step := range c.Chain {
+		switch {
+		case i == len(c.Chain)-1 && i > 0:
+			parts[i] = step.Name
+		case step.Line > 0:
+			parts[i] = fmt.Sprintf("%s (%s:%d)", step.Name, step.File, step.Line)
+		default:
+			parts[i] = fmt.Sprintf("%s (%s)", step.Name, step.File)
+		}
+	}
+	return strings.Join(parts, " → ")
+}
+
+// Erro no formato arquivo:linha:coluna: mensagem
+func (e resolutionError) String() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.File, e.Message)
+}
+
+// Função para imprimir o relatório JSON na saída padrão
+func printJSONReport(report interface{}) error {
+	encoder := newJSONEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("erro ao gerar relatório JSON: %v", err)
+	}
+	return nil
+}
+
+// Função para salvar um relatório JSON em arquivo
+func writeJSONReport(path string, report interface{}) error {
+	data, err := marshalJSON(report, "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao gerar relatório JSON: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório de %s: %v", path, err)
+	}
+	if err := writeFileAtomic(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("erro ao salvar relatório %s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Métodos em que a função requestBody exige required: true quando as opções não dizem
+var defaultBodyMethods = []string{"post", "put", "patch"}
+
+// Palavras das descrições que a função requiredKeywords procura quando as opções não dizem
+var (
+	defaultOptionalKeywords = []string{"opcional", "optional"}
+	defaultRequiredKeywords = []string{"obrigatório", "obrigatória", "mandatory"}
+)
+
+// Opções da função requestBody (functionOptions.methods)
+type bodyRequirement struct {
+	methods map[string]bool
+}
+
+// Opções da função requiredKeywords
+type keywordRequirement struct {
+	optional      []string
+	required      []string
+	caseSensitive bool
+}
+
+// Função para ler as opções da função requestBody: os métodos em que o corpo declarado
+// precisa ter required: true (padrão: post, put e patch)
+//
+//	then:
+//	  function: requestBody
+//	  functionOptions:
+//	    methods: [post, put, patch]
+func parseBodyOptions(options map[string]interface{}) (*bodyRequirement, []string) {
+	req := &bodyRequirement{methods: map[string]bool{}}
+	raw, ok := options["methods"]
+	if !ok {
+		for _, method := range defaultBodyMethods {
+			req.methods[method] = true
+		}
+		return req, nil
+	}
+	methods, problems := stringListOption(options, "methods")
+	for _, method := range methods {
+		method = strings.ToLower(method)
+		if !httpMethods[method] {
+			problems = append(problems, fmt.Sprintf("functionOptions.methods: %q não é um método HTTP (use get, put, post, delete, options, head, patch ou trace)", method))
+			continue
+		}
+		req.methods[method] = true
+	}
+	if len(methods) == 0 && len(problems) == 0 {
+		problems = append(problems, fmt.Sprintf("functionOptions.methods deve ter pelo menos um método, encontrado %s", ruleValueType(raw)))
+	}
+	return req, problems
+}
+
+// Função para ler as opções da função requiredKeywords: as palavras que indicam, na
+// descrição, um parâmetro opcional e um obrigatório, e se a busca diferencia maiúsculas
+// (padrão: não diferencia)
+//
+//	then:
+//	  function: requiredKeywords
+//	  functionOptions:
+//	    optional: [opcional, optional]
+//	    required: [obrigatório, obrigatória, mandatory]
+//	    caseSensitive: false
+func parseKeywordOptions(options map[string]interface{}) (*keywordRequirement, []string) {
+	req := &keywordRequirement{optional: defaultOptionalKeywords, required: defaultRequiredKeywords}
+	var problems []string
+	if _, ok := options["optional"]; ok {
+		var listProblems []string
+		req.optional, listProblems = stringListOption(options, "optional")
+		problems = append(problems, listProblems...)
+	}
+	if _, ok := options["required"]; ok {
+		var listProblems []string
+		req.required, listProblems = stringListOption(options, "required")
+		problems = append(problems, listProblems...)
+	}
+	if raw, ok := options["caseSensitive"]; ok {
+		if req.caseSensitive, ok = raw.(bool); !ok {
+			problems = append(problems, fmt.Sprintf("functionOptions.caseSensitive deve ser true ou false, encontrado %s", ruleValueType(raw)))
+		}
+	}
+	known := []string{"optional", "required", "caseSensitive"}
+	var unknown []string
+	for key := range options {
+		if key != "optional" && key != "required" && key != "caseSensitive" {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		problems = append(problems, fmt.Sprintf("functionOptions.%s desconhecida para a função requiredKeywords (use %s)%s", key, strings.Join(known, ", "), suggestionSuffix(key, known)))
+	}
+	return req, problems
+}
+
+// Lista de textos de functionOptions; aceita um texto só no lugar da lista
+func stringListOption(options map[string]interface{}, key string) ([]string, []string) {
+	switch value := options[key].(type) {
+	case string:
+		return []string{value}, nil
+	case []interface{}:
+		var list []string
+		var problems []string
+		for i, item := range value {
+			text, ok := item.(string)
+			if !ok || strings.TrimSpace(text) == "" {
+				problems = append(problems, fmt.Sprintf("functionOptions.%s[%d] deve ser um texto não vazio, encontrado %s", key, i, ruleValueType(item)))
+				continue
+			}
+			list = append(list, text)
+		}
+		return list, problems
+	default:
+		return nil, []string{fmt.Sprintf("functionOptions.%s deve ser uma lista de textos, encontrado %s", key, ruleValueType(options[key]))}
+	}
+}
+
+// Função para conferir o corpo da operação selecionada (given $.paths[*][*]): nos métodos
+// configurados, o requestBody declarado (ou o parâmetro in: body do Swagger 2.0) precisa
+// ter required: true, a não ser que a operação tenha x-optional-body: true. Operações sem
+// corpo declarado não são conferidas.
+func (r *compiledRule) requestBodyViolations(m pathMatch) []Violation {
+	if m.Key == nil || !r.body.methods[m.Key.Value] {
+		return nil
+	}
+	label, ok := operationLabel(m.Path)
+	if !ok || isTruthy(mappingValue(m.Node, "x-optional-body")) {
+		return nil
+	}
+	doc := documentContent(r.root)
+	body, bodyPath := mappingValue(m.Node, "requestBody"), joinPath(m.Path, "requestBody")
+	if body == nil {
+		// Swagger 2.0: o corpo é um parâmetro in: body
+		params := mappingValue(m.Node, "parameters")
+		if params != nil && params.Kind == yaml.SequenceNode {
+			for i, param := range params.Content {
+				if in := mappingValue(localRefTarget(doc, param), "in"); in != nil && in.Value == "body" {
+					body, bodyPath = param, fmt.Sprintf("%s[%d]", joinPath(m.Path, "parameters"), i)
+					break
+				}
+			}
+		}
+	}
+	target := localRefTarget(doc, body)
+	if target == nil || isTruthy(mappingValue(target, "required")) {
+		return nil
+	}
+	v := r.violation(bodyPath, body.Line, body)
+	v.Message = fmt.Sprintf("%s: o corpo de %s não tem required: true (marque a operação com x-optional-body: true se o corpo for mesmo opcional).", strings.TrimSuffix(r.description, "."), label)
+	return []Violation{v}
+}
+
+// Função para conferir o parâmetro selecionado (given $..parameters[*]): a descrição não
+// pode dizer que ele é opcional com required: true, nem que é obrigatório sem ele.
+// Parâmetros com $ref são conferidos onde estão definidos.
+func (r *compiledRule) requiredKeywordViolations(m pathMatch) []Violation {
+	param := m.Node
+	name, in := mappingValue(param, "name"), mappingValue(param, "in")
+	description := mappingValue(param, "description")
+	if name == nil || in == nil || description == nil || mappingValue(param, "$ref") != nil {
+		return nil
+	}
+	var keyword, problem string
+	if isTruthy(mappingValue(param, "required")) {
+		keyword, problem = findKeyword(description.Value, r.keywords.optional, r.keywords.caseSensitive), "tem required: true, mas a descrição diz %q"
+	} else {
+		keyword, problem = findKeyword(description.Value, r.keywords.required, r.keywords.caseSensitive), "não tem required: true, mas a descrição diz %q"
+	}
+	if keyword == "" {
+		return nil
+	}
+	v := r.violation(m.Path, param.Line, param)
+	v.Message = fmt.Sprintf("%s: o parâmetro %s (%s) "+problem+".", strings.TrimSuffix(r.description, "."), name.Value, in.Value, keyword)
+	return []Violation{v}
+}
+
+// Primeira palavra da lista que aparece no texto como palavra inteira (sem letras ou
+// números colados antes ou depois); vazio quando nenhuma aparece
+func findKeyword(text string, keywords []string, caseSensitive bool) string {
+	if !caseSensitive {
+		text = strings.ToLower(text)
+	}
+	runes := []rune(text)
+	for _, keyword := range keywords {
+		search := keyword
+		if !caseSensitive {
+			search = strings.ToLower(keyword)
+		}
+		want := []rune(search)
+		for i := 0; i+len(want) <= len(runes); i++ {
+			if string(runes[i:i+len(want)]) != search {
+				continue
+			}
+			before := i == 0 || !isWordRune(runes[i-1])
+			after := i+len(want) == len(runes) || !isWordRune(runes[i+len(want)])
+			if before && after {
+				return keyword
+			}
+		}
+	}
+	return ""
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Destino do nó quando ele é um $ref local; o próprio nó quando não é um $ref e nulo
+// quando o $ref aponta para outro arquivo ou não resolve
+func localRefTarget(doc, node *yaml.Node) *yaml.Node {
+	ref := mappingValue(node, "$ref")
+	if ref == nil {
+		return node
+	}
+	if file, _ := splitRef(ref.Value); file != "" {
+		return nil
+	}
+	target, _ := resolveSchemaRef("", doc, ref.Value)
+	return target
+}
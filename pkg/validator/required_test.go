@@ -0,0 +1,95 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+const requiredSpec = `openapi: 3.0.3
+info: {title: Pagamentos, version: 1.0.0}
+paths:
+  /pix:
+    post:
+      requestBody:
+        content: {application/json: {schema: {type: object}}}
+      responses: {'201': {description: criado}}
+    put:
+      requestBody: {$ref: '#/components/requestBodies/Pix'}
+      responses: {'200': {description: ok}}
+    patch:
+      x-optional-body: true
+      requestBody:
+        content: {application/json: {schema: {type: object}}}
+      responses: {'200': {description: ok}}
+    delete:
+      requestBody:
+        content: {application/json: {schema: {type: object}}}
+      responses: {'204': {description: removido}}
+    get:
+      parameters:
+        - {name: page, in: query, required: true, description: Página (opcional)}
+        - {name: x-fapi-auth-date, in: header, description: Cabeçalho OBRIGATÓRIO}
+        - {name: x-customer-user-agent, in: header, description: Não obrigatórios são ignorados}
+        - {name: pageSize, in: query, required: true, description: Obrigatório}
+      responses: {'200': {description: ok}}
+components:
+  requestBodies:
+    Pix:
+      required: true
+      content: {application/json: {schema: {type: object}}}
+`
+
+// Nos métodos configurados, o corpo declarado precisa de required: true; $refs locais
+// são seguidos e x-optional-body dispensa a operação
+func TestRequestBodyRequired(t *testing.T) {
+	rules := "rules:\n  corpo:\n    given: $.paths[*][*]\n    then: {function: requestBody}\n"
+	found := ruleViolations(t, requiredSpec, rules, "corpo")
+	if len(found) != 1 || !strings.Contains(found[0].Message, "o corpo de POST /pix não tem required: true") || found[0].Line != 7 {
+		t.Fatalf("esperada só a violação de POST /pix na linha 7: %v", found)
+	}
+	rules = "rules:\n  corpo:\n    given: $.paths[*][*]\n    then: {function: requestBody, functionOptions: {methods: [DELETE]}}\n"
+	found = ruleViolations(t, requiredSpec, rules, "corpo")
+	if len(found) != 1 || !strings.Contains(found[0].Message, "DELETE /pix") {
+		t.Errorf("com methods: [DELETE], esperada só a violação de DELETE /pix: %v", found)
+	}
+}
+
+// A descrição não pode contradizer o required, com a palavra inteira e sem diferenciar
+// maiúsculas por padrão
+func TestRequiredKeywords(t *testing.T) {
+	rules := "rules:\n  required-descricao:\n    given: $..parameters[*]\n    then: {function: requiredKeywords}\n"
+	found := ruleViolations(t, requiredSpec, rules, "required-descricao")
+	want := []string{
+		`o parâmetro page (query) tem required: true, mas a descrição diz "opcional"`,
+		`o parâmetro x-fapi-auth-date (header) não tem required: true, mas a descrição diz "obrigatório"`,
+	}
+	if len(found) != len(want) {
+		t.Fatalf("esperadas %d violações, encontradas %d: %v", len(want), len(found), found)
+	}
+	for i, v := range found {
+		if !strings.Contains(v.Message, want[i]) {
+			t.Errorf("violação %d: %q, esperado %q", i, v.Message, want[i])
+		}
+	}
+
+	rules = "rules:\n  required-descricao:\n    given: $..parameters[*]\n    then: {function: requiredKeywords, functionOptions: {required: [obrigatório], caseSensitive: true}}\n"
+	if found := ruleViolations(t, requiredSpec, rules, "required-descricao"); len(found) != 1 {
+		t.Errorf("com caseSensitive, OBRIGATÓRIO não deveria contar: %v", found)
+	}
+}
+
+func TestFindKeyword(t *testing.T) {
+	for _, tt := range []struct {
+		text, want string
+	}{
+		{"Campo opcional.", "opcional"},
+		{"Campo Opcional", "opcional"},
+		{"Campos opcionais", ""},
+		{"não-opcional", "opcional"},
+		{"", ""},
+	} {
+		if got := findKeyword(tt.text, defaultOptionalKeywords, false); got != tt.want {
+			t.Errorf("findKeyword(%q) = %q, esperado %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Nome padrão do arquivo resolvido: swagger.yaml -> swaggerResolve.yaml
+func defaultResolvedName(inputFile string) string {
+	base := filepath.Base(inputFile)
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "Resolve" + ext
+}
+
+// Flags do subcomando resolve
+type resolveOptions struct {
+	check        bool
+	bundle       bool
+	split        bool
+	outDir       string
+	format       string
+	output       string
+	outputFormat string
+	timeout      time.Duration
+}
+
+func (o *resolveOptions) register(fs *flag.FlagSet) {
+	fs.BoolVar(&o.check, "check", false, "apenas verifica se a resolução é possível, sem gravar arquivos")
+	fs.BoolVar(&o.bundle, "bundle", false, "traz os componentes de outros arquivos para components, mantendo os $refs internos")
+	fs.BoolVar(&o.split, "split", false, "divide a especificação em um arquivo raiz e um arquivo por componente em --out-dir")
+	fs.StringVar(&o.outDir, "out-dir", "", "diretório de saída do --split")
+	fs.StringVar(&o.format, "format", "text", "formato da saída: text ou json")
+	fs.StringVar(&o.output, "o", "", "arquivo de saída (padrão: <spec>Resolve com a extensão da especificação)")
+	fs.StringVar(&o.outputFormat, "output-format", "", "formato do documento gerado: yaml ou json (padrão: pela extensão do arquivo de saída)")
+	fs.DurationVar(&o.timeout, "timeout", defaultTimeout, "tempo máximo da execução (0 desativa)")
+	fs.Var(&stripTargets, "strip", "remove do resultado: examples, descriptions, extensões (ex.: 'x-internal*') ou expressões JSONPath, separados por vírgula; pode ser repetida")
+	fs.StringVar(&refReportFile, "ref-report", "", "salva o relatório de uso das referências (de onde cada componente é referenciado) em JSON ou, com extensão .md, em Markdown")
+	fs.Var(&keepRefs, "keep-refs", "mantém como $ref as referências que casam com o padrão (glob, ex.: '#/components/schemas/Error*'); pode ser repetida")
+	registerRefFlags(fs)
+	registerHTTPFlags(fs)
+	registerCacheFlags(fs)
+	registerEncodingFlag(fs)
+	registerSelectDocumentFlag(fs)
+	registerInputLimitFlag(fs)
+	registerOutputLimitFlags(fs)
+	registerProgressFlag(fs)
+	registerProfileMemFlag(fs)
+	registerOverlayFlags(fs)
+	registerConvertFlag(fs)
+	registerForceOutputFlag(fs)
+}
+
+var resolveCommand = &command{
+	Name:    "resolve",
+	Args:    "<spec.yaml>",
+	Summary: "resolve as referências ($ref) de uma especificação",
+	Description: `Indexa a especificação, resolve todas as referências e grava o documento
+resolvido em -o (padrão: <spec>Resolve.yaml). A saída é YAML ou JSON conforme
+--output-format ou a extensão do arquivo de saída; especificações em JSON são
+aceitas na entrada. Com --check, apenas verifica se
+a resolução (ou, com --bundle, o bundle) é possível e relata os erros de
+referência, sem gravar arquivos; --check não pode ser usado com --split.
+Com --bundle, em vez de substituir cada $ref pelo conteúdo, copia os
+componentes referenciados em outros arquivos para a seção components do
+documento raiz e aponta os $refs para eles, gerando um único arquivo. Com
+--split, faz o contrário: grava em --out-dir um arquivo raiz e um arquivo por
+componente (schemas/, parameters/, responses/...), com $refs relativos.
+
+Referências circulares (ex.: schemas recursivos) são relatadas como a cadeia
+de componentes envolvidos e mantidas como $ref na saída; com
+--fail-on-circular, elas fazem a execução falhar. Com --keep-refs, as
+referências que casam com o padrão são mantidas como $ref e as demais resolvidas.
+Com --strip (ou "strip" em .openapi-ci.yaml), os nós indicados são removidos do
+documento resolvido. Os overlays de --overlay são aplicados antes da resolução.
+Com --ref-report, grava também a lista de componentes com os locais (arquivo,
+JSONPath e linha) que os referenciam, as referências que saem de cada um e o
+número de dependentes transitivos, para avaliar o impacto de uma renomeação.
+
+O relatório JSON de --check traz a expansão do documento resolvido: o tamanho das
+fontes (a especificação e os arquivos referenciados), o do artefato, a razão entre
+eles e quantas vezes o conteúdo de cada componente foi duplicado. Com
+--max-expansion-ratio N, a resolução falha quando o artefato fica mais de N vezes
+maior que as fontes, indicando os componentes mais duplicados; --bundle ou
+--keep-refs mantêm esses componentes como $ref.`,
+	Examples: []string{
+		programName + " resolve swagger.yaml",
+		programName + " resolve -o openapi-resolvido.yaml swagger.yaml",
+		programName + " resolve --check --format json swagger.yaml",
+		programName + " resolve --bundle -o openapi-bundle.yaml swagger.yaml",
+		programName + " resolve --split --out-dir openapi-split swagger.yaml",
+		programName + " resolve -o openapi.json swagger.yaml",
+		programName + " resolve --check --ref-report refs.md swagger.yaml",
+		programName + " resolve --keep-refs '#/components/schemas/Error*' swagger.yaml",
+		programName + " resolve --strip examples,'x-internal*' -o openapi-parceiros.yaml swagger.yaml",
+		programName + " resolve --max-expansion-ratio 10 swagger.yaml",
+	},
+	Flags: func(fs *flag.FlagSet) { new(resolveOptions).register(fs) },
+	Run:   runResolve,
+}
+
+// Subcomando resolve: resolve as referências de uma especificação
+func runResolve(c *command, args []string) int {
+	opts := &resolveOptions{}
+	fs := c.newFlagSet()
+	opts.register(fs)
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	if err := checkOverlayFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkHTTPFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkEncodingFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkProgressFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkSelectDocumentFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkOutputLimitFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+
+	if fs.NArg() != 1 {
+		return c.usageError("esperado um argumento (especificação), recebidos %d", fs.NArg())
+	}
+	inputFile := fs.Arg(0)
+	if opts.output == "" {
+		opts.output = defaultResolvedName(inputFile)
+	}
+
+	ctx, cancel := newRunContext(opts.timeout)
+	defer cancel()
+
+	if _, err := outputFormatFor(opts.output, opts.outputFormat); err != nil {
+		return c.usageError("%v", err)
+	}
+	if len(stripTargets) == 0 {
+		config, err := loadProjectConfig(projectConfigFile)
+		if err != nil {
+			fmt.Fprintln(stdout, "❌ Erro ao ler a configuração:", err)
+			return exitFailure
+		}
+		if config != nil {
+			stripTargets = config.Strip
+		}
+	}
+	if _, err := parseStripTargets(stripTargets); err != nil {
+		return c.usageError("%v", err)
+	}
+
+	if opts.split != (opts.outDir != "") {
+		return c.usageError("--split e --out-dir devem ser usados juntos")
+	}
+	if (opts.bundle || opts.split) && refReportFile != "" {
+		return c.usageError("--ref-report não pode ser usado com --bundle ou --split")
+	}
+	if opts.split && opts.check {
+		return c.usageError("--check não pode ser usado com --split")
+	}
+	if opts.bundle || opts.split {
+		return runBundle(inputFile, opts.output, opts.outputFormat, opts.format, opts.outDir, opts.check)
+	}
+
+	if !opts.check {
+		if err := resolveOpenAPI(ctx, inputFile, opts.output, opts.outputFormat); err != nil {
+			if isCancellation(err) {
+				reportCancellation(err, opts.timeout)
+				return exitFailure
+			}
+			fmt.Fprintln(stdout, "❌ Erro ao processar", inputFile+":", err)
+			return exitFailure
+		}
+		return exitOK
+	}
+
+	spec, err := indexAndResolve(ctx, flagSettings(), inputFile)
+	if err != nil {
+		if isCancellation(err) {
+			reportCancellation(err, opts.timeout)
+			return exitFailure
+		}
+		fmt.Fprintln(stdout, "❌ Erro ao processar", inputFile+":", err)
+		return exitFailure
+	}
+	report := spec.report
+
+	// Conferir, sem gravar, o artefato que a resolução produziria
+	docFormat, _ := outputFormatFor(opts.output, opts.outputFormat)
+	resolved, err := spec.marshal(docFormat)
+	if err == nil {
+		report.Artifact = newArtifactInfo(opts.output, resolved)
+		report.Expansion = newExpansionReport(spec, len(resolved))
+		err = verifyArtifact(resolved, spec.inputProblems)
+	}
+	if err != nil {
+		report.Errors = append(report.Errors, resolutionError{File: opts.output, Message: err.Error()})
+	}
+	if report.Expansion != nil && report.Expansion.exceeded() {
+		report.Errors = append(report.Errors, resolutionError{File: opts.output, Message: expansionRatioError(report.Expansion).Error()})
+	}
+	if spec.refUsage != nil {
+		if err := writeRefReport(refReportFile, spec.refUsage); err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+	}
+
+	if opts.format == "json" {
+		if err := printJSONReport(jsonReport{SchemaVersion: reportSchemaVersion, Resolution: &report}); err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+	} else {
+		for _, e := range report.Errors {
+			fmt.Fprintln(stdout, "❌", e)
+		}
+		cyclesErr := reportCycles(report.Cycles)
+		total := 0
+		for _, n := range report.RefsResolved {
+			total += n
+		}
+		fmt.Fprintf(stdout, "🔎 %d referências resolvidas, %d mantidas como $ref, %d erros, %d referências circulares.\n", total, report.RefsKept, len(report.Errors), len(report.Cycles))
+		if cyclesErr != nil {
+			fmt.Fprintln(stdout, "❌", cyclesErr)
+		}
+		if report.Artifact != nil {
+			fmt.Fprintln(stdout, "📄", report.Artifact)
+		}
+		if maxExpansionRatio > 0 && report.Expansion != nil {
+			fmt.Fprintln(stdout, "📈 Expansão:", report.Expansion)
+		}
+		if spec.refUsage != nil {
+			fmt.Fprintln(stdout, "📄 Relatório de uso das referências salvo em:", refReportFile)
+		}
+	}
+
+	if len(report.Errors) > 0 || (failOnCircular && len(report.Cycles) > 0) {
+		return exitFailure
+	}
+	if opts.format != "json" {
+		fmt.Fprintln(stdout, "✅ Resolução verificada sem erros:", inputFile)
+	}
+	return exitOK
+}
+
+// Modo --bundle: gera um único arquivo com os componentes externos em components.
+// Com outDir (--split), o resultado é dividido em um arquivo por componente; com check
+// (--check), o bundle é montado e conferido sem ser gravado.
+func runBundle(inputFile, outputFile, outputFormat, format, outDir string, check bool) int {
+	tracker.enter("bundle", inputFile)
+	data, err := readSpecFile(inputFile)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao processar", inputFile+":", err)
+		return exitFailure
+	}
+	rootNode, err := parseRootSpec(flagSettings(), data, inputFile)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao processar", inputFile+":", err)
+		return exitFailure
+	}
+	inputProblems := structuralProblems(rootNode)
+	report, err := bundleOpenAPI(inputFile, rootNode)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao gerar o bundle de", inputFile+":", err)
+		return exitFailure
+	}
+
+	if outDir != "" {
+		return writeSplit(inputFile, rootNode, outDir, outputFormat, format, detectIndent(data), report)
+	}
+
+	docFormat, _ := outputFormatFor(outputFile, outputFormat)
+	bundled, err := marshalSpec(rootNode, docFormat, detectIndent(data), foldedScalarsOf(data, rootNode))
+	if err != nil {
+		fmt.Fprintln(stdout, "❌", err)
+		return exitFailure
+	}
+	if err := verifyArtifact(bundled, inputProblems); err != nil {
+		if check {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+		if !forceOutput {
+			fmt.Fprintf(stdout, "❌ %v; %s não foi gravado (use --force-output para gravá-lo mesmo assim)\n", err, outputFile)
+			return exitFailure
+		}
+		fmt.Fprintln(stdout, "⚠️ ", err, "(gravado por --force-output)")
+	}
+	if !check {
+		if err := writeFileAtomic(outputFile, bundled); err != nil {
+			fmt.Fprintln(stdout, "❌ Erro ao salvar arquivo do bundle:", err)
+			return exitFailure
+		}
+	}
+
+	if format == "json" {
+		if err := printJSONReport(jsonReport{SchemaVersion: reportSchemaVersion, Bundle: report}); err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+		return exitOK
+	}
+	for _, c := range report.Collisions {
+		fmt.Fprintf(stdout, "⚠️  Colisão de nome: %s de %s difere do componente existente (%s); renomeado para %s\n", c.Name, c.Source, c.Existing, c.Renamed)
+	}
+	fmt.Fprintf(stdout, "📦 %d componentes externos incorporados, %d colisões de nome.\n", len(report.Components), len(report.Collisions))
+	fmt.Fprintln(stdout, "📄", newArtifactInfo(outputFile, bundled))
+	if check {
+		fmt.Fprintln(stdout, "✅ Bundle verificado sem erros:", inputFile)
+		return exitOK
+	}
+	fmt.Fprintln(stdout, "✅ Bundle salvo em:", outputFile)
+	return exitOK
+}
+
+// Modo --split: grava o documento já com os componentes externos incorporados,
+// um arquivo por componente
+func writeSplit(inputFile string, rootNode *yaml.Node, outDir, outputFormat, format string, indent int, bundle *bundleReport) int {
+	docFormat, _ := outputFormatFor(inputFile, outputFormat)
+	files, err := splitOpenAPI(inputFile, rootNode, outDir, docFormat, indent)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao dividir", inputFile+":", err)
+		return exitFailure
+	}
+
+	if format == "json" {
+		if err := printJSONReport(jsonReport{SchemaVersion: reportSchemaVersion, Bundle: bundle, Split: files}); err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+		return exitOK
+	}
+	for _, c := range bundle.Collisions {
+		fmt.Fprintf(stdout, "⚠️  Colisão de nome: %s de %s difere do componente existente (%s); renomeado para %s\n", c.Name, c.Source, c.Existing, c.Renamed)
+	}
+	fmt.Fprintf(stdout, "📂 %d arquivos gravados em %s:\n", len(files), outDir)
+	for _, f := range files {
+		if f.Component != "" {
+			fmt.Fprintf(stdout, "   %s (%s)\n", f.Path, f.Component)
+		} else {
+			fmt.Fprintf(stdout, "   %s\n", f.Path)
+		}
+	}
+	return exitOK
+}
@@ -0,0 +1,243 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Copia a especificação multi (com os arquivos referenciados) para um diretório
+// temporário, trocando as quebras de linha por CRLF quando pedido
+func copyMultiFixture(t *testing.T, crlf bool) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range []string{"api.yaml", "schemas/account.yaml", "schemas/common.yaml"} {
+		data := mustReadFile(t, filepath.Join("testdata", "e2e", "multi", filepath.FromSlash(name)))
+		if crlf {
+			data = bytes.ReplaceAll(data, []byte("\n"), []byte("\r\n"))
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(target, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "api.yaml")
+}
+
+// Executa o resolve com os argumentos e devolve o artefato gravado
+func resolveArtifact(t *testing.T, spec string, args ...string) []byte {
+	t.Helper()
+	output := filepath.Join(t.TempDir(), "resolvido.yaml")
+	var out, errOut bytes.Buffer
+	if code := Run(append(append([]string{"resolve", "--no-cache", "-o", output}, args...), spec), &out, &errOut); code != exitOK {
+		t.Fatalf("exit %d\n%s%s", code, out.String(), errOut.String())
+	}
+	return mustReadFile(t, output)
+}
+
+// A mesma entrada gera sempre o mesmo artefato, byte a byte, na resolução completa e no
+// bundle (os componentes incorporados vêm de mapas), e a entrada com CRLF sai igual à
+// com LF
+func TestResolveIsDeterministic(t *testing.T) {
+	spec := copyMultiFixture(t, false)
+	crlf := copyMultiFixture(t, true)
+	for _, mode := range []struct {
+		name string
+		args []string
+	}{{"resolução", nil}, {"bundle", []string{"--bundle"}}} {
+		first := resolveArtifact(t, spec, mode.args...)
+		if bytes.Contains(first, []byte("\r")) {
+			t.Errorf("%s: artefato com \\r", mode.name)
+		}
+		for i := 1; i < 50; i++ {
+			if got := resolveArtifact(t, spec, mode.args...); !bytes.Equal(got, first) {
+				t.Fatalf("%s: a execução %d difere da primeira\n--- primeira\n%s\n--- execução %d\n%s", mode.name, i+1, first, i+1, got)
+			}
+		}
+		if got := resolveArtifact(t, crlf, mode.args...); !bytes.Equal(got, first) {
+			t.Errorf("%s: a entrada com CRLF gera outro artefato\n--- LF\n%s\n--- CRLF\n%s", mode.name, first, got)
+		}
+		if mode.args != nil && !strings.Contains(string(first), "#/components/") {
+			t.Errorf("%s: sem os componentes incorporados:\n%s", mode.name, first)
+		}
+	}
+}
+
+// resolve --check --bundle monta e confere o bundle sem gravá-lo; --check com --split
+// é erro de uso
+func TestResolveCheckBundle(t *testing.T) {
+	spec := copyMultiFixture(t, false)
+	output := filepath.Join(filepath.Dir(spec), "bundle.yaml")
+	var out, errOut bytes.Buffer
+	if code := Run([]string{"resolve", "--no-cache", "--check", "--bundle", "-o", output, spec}, &out, &errOut); code != exitOK {
+		t.Fatalf("exit %d\n%s%s", code, out.String(), errOut.String())
+	}
+	if !strings.Contains(out.String(), "✅ Bundle verificado sem erros") {
+		t.Errorf("saída sem a verificação:\n%s", out.String())
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("%s não deveria ter sido gravado: %v", output, err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(spec), "apiResolve.yaml")); !os.IsNotExist(err) {
+		t.Errorf("o artefato padrão não deveria ter sido gravado: %v", err)
+	}
+
+	out.Reset()
+	errOut.Reset()
+	outDir := filepath.Join(filepath.Dir(spec), "split")
+	if code := Run([]string{"resolve", "--check", "--split", "--out-dir", outDir, spec}, &out, &errOut); code != exitUsage || !strings.Contains(out.String()+errOut.String(), "--check não pode ser usado com --split") {
+		t.Errorf("exit %d, esperado erro de uso\n%s%s", code, out.String(), errOut.String())
+	}
+	if _, err := os.Stat(outDir); !os.IsNotExist(err) {
+		t.Errorf("%s não deveria ter sido criado: %v", outDir, err)
+	}
+}
+
+// Especificação com uma referência comum (Aviso), uma mantida por --keep-refs (Erro)
+// e uma que fecha um ciclo (Conta)
+const refCountSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas:
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Conta'}
+        '400':
+          description: erro
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Erro'}
+        '404':
+          description: aviso
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Aviso'}
+components:
+  schemas:
+    Conta:
+      type: object
+      properties:
+        subconta: {$ref: '#/components/schemas/Conta'}
+    Erro:
+      type: object
+      properties:
+        codigo: {type: string}
+    Aviso:
+      type: object
+      properties:
+        mensagem: {type: string}
+`
+
+// Só as referências substituídas contam como resolvidas: a mantida por --keep-refs e a
+// circular, que continuam como $ref no artefato, não entram
+func TestResolveRefCounts(t *testing.T) {
+	spec := writeTemp(t, t.TempDir(), "api.yaml", []byte(refCountSpec))
+	var out, errOut bytes.Buffer
+	if code := Run([]string{"resolve", "--no-cache", "--check", "--format", "json", "--keep-refs", "#/components/schemas/Erro", spec}, &out, &errOut); code != exitOK {
+		t.Fatalf("exit %d\n%s%s", code, out.String(), errOut.String())
+	}
+	var report struct {
+		Resolution resolutionReport `json:"resolution"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("%v\n%s", err, out.String())
+	}
+	r := report.Resolution
+	if len(r.RefsResolved) != 1 || r.RefsResolved[spec] != 1 || r.RefsKept != 1 || len(r.Cycles) != 1 {
+		t.Errorf("resolvidas %v, mantidas %d, ciclos %d; esperado 1 resolvida (Aviso), 1 mantida (Erro) e 1 ciclo (Conta)", r.RefsResolved, r.RefsKept, len(r.Cycles))
+	}
+}
+
+// A cadeia de um ciclo termina no componente que o fecha, sem repeti-lo
+func TestReferenceCycleChain(t *testing.T) {
+	spec := writeTemp(t, t.TempDir(), "api.yaml", []byte(`openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    A:
+      type: object
+      required: [b]
+      properties:
+        b: {$ref: '#/components/schemas/B'}
+    B:
+      type: object
+      required: [a]
+      properties:
+        a: {$ref: '#/components/schemas/A'}
+`))
+	s := flagSettings()
+	s.tracker = nil
+	resolved, err := indexAndResolve(context.Background(), s, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolved.report.Cycles) != 1 {
+		t.Fatalf("ciclos %+v, esperado um", resolved.report.Cycles)
+	}
+	file := reportPath(spec)
+	if got, want := resolved.report.Cycles[0].String(), "B ("+file+":12) → A ("+file+":7) → B"; got != want {
+		t.Errorf("cadeia %q, esperado %q", got, want)
+	}
+}
+
+// --check resolve sem gravar nada: o $ref quebrado sai com arquivo e linha, a execução
+// falha e o relatório JSON conta as referências resolvidas por arquivo
+func TestResolveCheckDryRun(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"comum.yaml": "Conta: {type: string}\n",
+		"api.yaml": `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas:
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: 'comum.yaml#/Conta'}
+        '404':
+          description: erro
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Inexistente'}
+components:
+  schemas: {}
+`,
+	})
+	spec := filepath.Join(dir, "api.yaml")
+	output := filepath.Join(dir, "resolvido.yaml")
+
+	code, out := runCommand(t, "resolve", "--no-cache", "--check", "-o", output, spec)
+	if code != exitFailure || !strings.Contains(out, reportPath(spec)+":16:30: $ref para #/components/schemas/Inexistente") {
+		t.Errorf("código %d, esperado %d com o erro em api.yaml:16:30\n%s", code, exitFailure, out)
+	}
+
+	code, out = runCommand(t, "resolve", "--no-cache", "--check", "--format", "json", "-o", output, spec)
+	var report struct {
+		Resolution resolutionReport `json:"resolution"`
+	}
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	r := report.Resolution
+	if code != exitFailure || r.RefsResolved[spec] != 1 || len(r.Errors) != 1 || r.Errors[0].Line != 16 {
+		t.Errorf("código %d, resolvidas %v, erros %+v", code, r.RefsResolved, r.Errors)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("--check gravou %s", output)
+	}
+}
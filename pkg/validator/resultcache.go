@@ -0,0 +1,179 @@
+package validator
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Versão da ferramenta, gravada na compilação (-ldflags "-X .../pkg/validator.toolVersion=v1.4.0");
+// sem ela, o cache de resultados usa o hash do próprio executável
+var toolVersion string
+
+var (
+	toolFingerprintOnce sync.Once
+	toolFingerprintText string
+)
+
+// Identificação da versão da ferramenta na chave do cache de resultados: um binário
+// novo não reaproveita os resultados de outro
+func toolFingerprint() string {
+	toolFingerprintOnce.Do(func() {
+		toolFingerprintText = toolVersion
+		if toolFingerprintText != "" {
+			return
+		}
+		toolFingerprintText = "dev"
+		if exe, err := os.Executable(); err == nil {
+			if data, err := os.ReadFile(exe); err == nil {
+				toolFingerprintText = contentHash(data)
+			}
+		}
+	})
+	return toolFingerprintText
+}
+
+// Opções do validate que entram na chave do cache de resultados (uma por linha,
+// nome=valor); vazio fora do validate, que não usa o cache de resultados
+var resultOptions string
+
+// Flags que não mudam o relatório de cada especificação: saída, execução, cache e
+// notificações. As regras entram na chave pelo conteúdo, não pelo nome do arquivo.
+var resultNeutralFlags = map[string]bool{
+	"cache-dir": true, "no-cache": true, "concurrency": true, "debug-bundle": true, "exclude": true,
+	"format": true, "print-paths": true, "report": true, "manifest": true, "rules": true,
+	"github-comment": true, "github-pr": true, "github-repo": true, "http-header": true, "http-retries": true,
+	"notify-on": true, "notify-report-url": true, "notify-retries": true, "notify-url": true,
+	"profile-mem": true, "progress": true, "remote-timeout": true, "timeout": true,
+}
+
+// Função para registrar as opções da execução que fazem parte da chave do cache de resultados
+func setResultOptions(fs *flag.FlagSet) {
+	var options []string
+	fs.VisitAll(func(f *flag.Flag) {
+		if !resultNeutralFlags[f.Name] {
+			options = append(options, f.Name+"="+f.Value.String())
+		}
+	})
+	resultOptions = strings.Join(options, "\n")
+}
+
+// Cache de resultados em uso: nulo sem cache, fora do validate e quando o resultado
+// depende de algo além dos arquivos locais (links conferidos pela rede, $refs remotos)
+func resultCache(s *runSettings) *diskCache {
+	if resultOptions == "" || s.checkLinks || s.allowRemoteRefs || s.changedScope != nil {
+		return nil
+	}
+	return s.activeCache()
+}
+
+// Entrada do cache de resultados: o relatório e os arquivos que a especificação
+// referencia, pelo hash do conteúdo
+type resultCacheEntry struct {
+	Deps   map[string]string `json:"deps"`
+	Report *validationReport `json:"report"`
+}
+
+// Chave do cache de resultados: a especificação (mesma chave do cache de índice), as
+// regras já com os extends aplicados, as opções, a configuração do projeto, o registro
+// de extensões e a versão da ferramenta. Um extends alterado muda as regras
+// carregadas e, com elas, a chave.
+func resultCacheKey(doc *specDocument, rules map[string]interface{}) string {
+	rulesData, _ := yaml.Marshal(rules)
+	config, _ := os.ReadFile(projectConfigFile)
+	registry := extensionsFile
+	if registry == "" {
+		if project, err := loadProjectConfig(projectConfigFile); err == nil && project != nil {
+			registry = project.Extensions
+		}
+	}
+	var extensions []byte
+	if registry != "" {
+		extensions, _ = os.ReadFile(registry)
+	}
+	return contentHash([]byte(indexCacheKey(doc)), rulesData, []byte(resultOptions), config, extensions, []byte(toolFingerprint()))
+}
+
+// Função para validar uma especificação e montar o resumo. Com o cache de resultados,
+// o relatório guardado é reaproveitado (com Cached) quando a chave é a mesma e nenhum
+// arquivo referenciado mudou; senão a validação roda e o relatório é guardado.
+func validateSpecReport(ctx context.Context, inputFile string, rules map[string]interface{}) (*validationReport, error) {
+	doc, err := openSpecDocument(flagSettings(), inputFile)
+	if err != nil {
+		return nil, err
+	}
+	cache := resultCache(doc.settings)
+	key := ""
+	var deps map[string]string
+	if cache != nil {
+		key = resultCacheKey(doc, rules)
+		var entry resultCacheEntry
+		if cache.getJSON("results", key, &entry) && entry.Report != nil && depsUnchanged(doc.settings, entry.Deps, rawHash) {
+			entry.Report.Cached = true
+			return entry.Report, nil
+		}
+		// A lista sai da árvore antes da validação, que pode resolvê-la no lugar
+		deps = specDependencies(doc.settings, doc.file, doc.root)
+	}
+
+	violations, details, err := validateDocument(ctx, doc, rules)
+	if err != nil {
+		return nil, err
+	}
+	report := newValidationReport(inputFile, violations)
+	report.addDetails(details)
+	cache.putJSON("results", key, resultCacheEntry{Deps: deps, Report: report})
+	return report, nil
+}
+
+// Função para listar os arquivos locais que a especificação referencia, direta ou
+// indiretamente, com o hash do conteúdo. Um arquivo que não existe entra com hash
+// vazio, e a entrada do cache não vale enquanto ele não existir.
+func specDependencies(s *runSettings, inputFile string, root *yaml.Node) map[string]string {
+	deps := map[string]string{}
+	rootAbs, _ := filepath.Abs(inputFile)
+	docs := map[string]*yaml.Node{rootAbs: documentContent(root)}
+	queue := []string{rootAbs}
+	for len(queue) > 0 {
+		file := queue[0]
+		queue = queue[1:]
+		dir := filepath.Dir(file)
+		if file == rootAbs {
+			dir = s.refsDir(rootAbs)
+		}
+		forEachRef(docs[file], func(ref *yaml.Node, path string) {
+			targetFile, _ := splitRef(ref.Value)
+			if targetFile == "" || isRemoteURL(targetFile) {
+				return
+			}
+			targetAbs := filepath.Join(dir, filepath.FromSlash(targetFile))
+			if _, seen := deps[targetAbs]; seen || targetAbs == rootAbs {
+				return
+			}
+			deps[targetAbs] = ""
+			data, err := s.readSource(targetAbs)
+			if err != nil {
+				return
+			}
+			deps[targetAbs] = rawHash(data, targetAbs)
+			if parsed, err := parseSpec(data, targetAbs); err == nil {
+				docs[targetAbs] = documentContent(parsed)
+				queue = append(queue, targetAbs)
+			}
+		})
+	}
+	return deps
+}
+
+// Indicação, no resumo em texto, de que o resultado veio do cache
+func cachedSuffix(report *validationReport) string {
+	if report.Cached {
+		return " (resultado em cache)"
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package validator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// O segundo validate da mesma especificação reaproveita o resultado; uma mudança num
+// arquivo referenciado ou nas opções da execução invalida a entrada
+func TestResultCache(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range multiFixture(t) {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	validate := func(args ...string) string {
+		t.Helper()
+		var out, errOut bytes.Buffer
+		args = append(append([]string{"validate", "--cache-dir", filepath.Join(dir, ".cache")}, args...), filepath.Join(dir, "api.yaml"))
+		if code := Run(args, &out, &errOut); code == exitUsage {
+			t.Fatalf("código %d\n%s%s", code, out.String(), errOut.String())
+		}
+		return out.String()
+	}
+
+	if out := validate(); strings.Contains(out, "(resultado em cache)") {
+		t.Fatalf("a primeira execução não tem resultado em cache:\n%s", out)
+	}
+	if out := validate(); !strings.Contains(out, "(resultado em cache)") {
+		t.Fatalf("a segunda execução deveria reaproveitar o resultado:\n%s", out)
+	}
+	if out := validate("--version-consistency", "off"); strings.Contains(out, "(resultado em cache)") {
+		t.Errorf("outras opções não deveriam reaproveitar o resultado:\n%s", out)
+	}
+
+	account := filepath.Join(dir, "schemas", "account.yaml")
+	data, err := os.ReadFile(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(account, append(data, []byte("# alterado\n")...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if out := validate(); strings.Contains(out, "(resultado em cache)") {
+		t.Errorf("com um arquivo referenciado alterado, o resultado não deveria vir do cache:\n%s", out)
+	}
+	if out := validate(); !strings.Contains(out, "(resultado em cache)") {
+		t.Errorf("a execução seguinte deveria reaproveitar o novo resultado:\n%s", out)
+	}
+}
@@ -0,0 +1,163 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Com --inline-reuse N, parâmetros e cabeçalhos definidos inline com a mesma
+// definição mais de N vezes são apontados como candidatos a components (0 desativa)
+var inlineReuseLimit int
+
+func registerInlineReuseFlag(fs *flag.FlagSet) {
+	fs.IntVar(&inlineReuseLimit, "inline-reuse", 0, "sugere levar para components os parâmetros e cabeçalhos definidos inline com a mesma definição mais de N vezes (0 desativa)")
+}
+
+func checkInlineReuseFlag() error {
+	if inlineReuseLimit < 0 {
+		return fmt.Errorf("valor inválido para --inline-reuse: %d", inlineReuseLimit)
+	}
+	return nil
+}
+
+// Cópias de uma mesma definição inline
+type inlineCopies struct {
+	kind   string // parameters ou headers
+	name   string
+	in     string
+	places []string
+	line   int
+	path   string
+	shape  string // chave do componente existente com a mesma definição (sem o nome, nos cabeçalhos)
+}
+
+// Função para agrupar as definições inline de parâmetros (dos path items e das
+// operações) e de cabeçalhos de resposta (das operações e de components.responses)
+// pela forma canônica, sem descrições e exemplos; cada grupo com mais de limit cópias
+// vira uma sugestão com todos os locais e o nome proposto para o componente, ou o
+// componente existente com a mesma definição
+func inlineReuseViolations(root *yaml.Node, limit int) []Violation {
+	doc := documentContent(root)
+	components := mappingValue(doc, "components")
+	canonical := newSchemaCanonicalizer(true)
+	fingerprint := func(kind, name string, node *yaml.Node) string {
+		return kind + "\x00" + name + "\x00" + canonical.form(node, false)
+	}
+
+	// Componentes existentes, pela definição, e nomes já usados. O nome de um
+	// cabeçalho é a chave em que ele é usado, e não o nome do componente: os
+	// cabeçalhos são comparados só pela definição
+	existing := map[string]string{}
+	taken := map[string]bool{}
+	for _, kind := range []string{"parameters", "headers"} {
+		forEachEntry(mappingValue(components, kind), func(name string, node *yaml.Node) {
+			taken[kind+"/"+name] = true
+			if mappingValue(node, "$ref") != nil {
+				return
+			}
+			key := ""
+			if kind == "parameters" {
+				if n := mappingValue(node, "name"); n != nil {
+					key = n.Value
+				}
+			}
+			if _, ok := existing[fingerprint(kind, key, node)]; !ok {
+				existing[fingerprint(kind, key, node)] = name
+			}
+		})
+	}
+
+	groups := map[string]*inlineCopies{}
+	var order []string
+	add := func(kind, name, in string, node *yaml.Node, path string) {
+		if node == nil || node.Kind != yaml.MappingNode || mappingValue(node, "$ref") != nil {
+			return
+		}
+		key := fingerprint(kind, name, node)
+		g := groups[key]
+		if g == nil {
+			g = &inlineCopies{kind: kind, name: name, in: in, line: node.Line, path: path, shape: key}
+			if kind == "headers" {
+				g.shape = fingerprint(kind, "", node)
+			}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.places = append(g.places, fmt.Sprintf("%s (linha %d)", path, node.Line))
+	}
+	addParameters := func(holder *yaml.Node, path string) {
+		params := mappingValue(holder, "parameters")
+		if params == nil || params.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, param := range params.Content {
+			name, in := mappingValue(param, "name"), mappingValue(param, "in")
+			if name != nil && in != nil {
+				add("parameters", name.Value, in.Value, param, fmt.Sprintf("%s[%d]", joinPath(path, "parameters"), i))
+			}
+		}
+	}
+	addHeaders := func(responses *yaml.Node, path string) {
+		forEachEntry(responses, func(status string, response *yaml.Node) {
+			headersPath := joinPath(joinPath(path, status), "headers")
+			forEachEntry(mappingValue(response, "headers"), func(name string, header *yaml.Node) {
+				add("headers", name, "header", header, joinPath(headersPath, name))
+			})
+		})
+	}
+	for _, section := range []string{"paths", "webhooks"} {
+		forEachEntry(mappingValue(doc, section), func(route string, item *yaml.Node) {
+			itemPath := joinPath(joinPath("$", section), route)
+			addParameters(item, itemPath)
+			forEachEntry(item, func(method string, operation *yaml.Node) {
+				if !httpMethods[method] {
+					return
+				}
+				operationPath := joinPath(itemPath, method)
+				addParameters(operation, operationPath)
+				addHeaders(mappingValue(operation, "responses"), joinPath(operationPath, "responses"))
+			})
+		})
+	}
+	addHeaders(mappingValue(components, "responses"), "$.components.responses")
+
+	var violations []Violation
+	for _, key := range order {
+		g := groups[key]
+		if len(g.places) <= limit {
+			continue
+		}
+		what := fmt.Sprintf("O parâmetro %s (%s)", g.name, g.in)
+		if g.kind == "headers" {
+			what = "O cabeçalho " + g.name
+		}
+		v := Violation{RuleID: "inline-reuse", Severity: "info", Message: fmt.Sprintf("%s é definido inline %d vezes com a mesma definição: %s.", what, len(g.places), strings.Join(g.places, ", ")), JSONPath: g.path, Line: g.line}
+		if name, ok := existing[g.shape]; ok {
+			v.Suggestion = fmt.Sprintf("Substitua as cópias por $ref: '#/components/%s/%s', que já tem a mesma definição.", g.kind, escapePointer(name))
+		} else {
+			name := proposedComponentName(g.name, g.kind, taken)
+			taken[g.kind+"/"+name] = true
+			v.Suggestion = fmt.Sprintf("Declare a definição em components.%s.%s e substitua as cópias por $ref: '#/components/%s/%s'.", g.kind, name, g.kind, escapePointer(name))
+		}
+		violations = append(violations, v)
+	}
+	return violations
+}
+
+// Nome proposto para o componente: o nome em lowerCamelCase, com sufixo numérico
+// quando já está em uso
+func proposedComponentName(name, kind string, taken map[string]bool) string {
+	base := lowerCamelCase(name)
+	if base == "" {
+		base = name
+	}
+	candidate := base
+	for n := 2; taken[kind+"/"+candidate]; n++ {
+		candidate = base + strconv.Itoa(n)
+	}
+	return candidate
+}
@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+const reuseSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas:
+    parameters:
+      - {name: page, in: query, description: Página, schema: {type: integer}}
+    get:
+      responses:
+        '200':
+          description: ok
+          headers:
+            x-fapi-interaction-id: {schema: {type: string}}
+  /cartoes:
+    get:
+      parameters:
+        - {name: page, in: query, description: Número da página, schema: {type: integer}}
+        - {name: page, in: header, schema: {type: integer}}
+      responses:
+        '200':
+          description: ok
+          headers:
+            x-fapi-interaction-id: {description: Id da interação, schema: {type: string}}
+components:
+  parameters:
+    page: {name: page, in: query, schema: {type: string}}
+  headers:
+    XFapiInteractionId: {schema: {type: string}}
+`
+
+// Definições inline iguais a menos de descrições são agrupadas; a sugestão aponta o
+// componente com a mesma definição ou propõe um nome livre
+func TestInlineReuse(t *testing.T) {
+	found := inlineReuseViolations(mustParseYAML(t, reuseSpec), 1)
+	if len(found) != 2 {
+		t.Fatalf("esperadas 2 violações, encontradas %d: %v", len(found), found)
+	}
+	param, header := found[0], found[1]
+	if !strings.Contains(param.Message, "O parâmetro page (query) é definido inline 2 vezes") ||
+		!strings.Contains(param.Message, "$.paths['/contas'].parameters[0] (linha 6), $.paths['/cartoes'].get.parameters[0] (linha 16)") {
+		t.Errorf("mensagem do parâmetro: %s", param.Message)
+	}
+	if !strings.Contains(param.Suggestion, "components.parameters.page2") {
+		t.Errorf("o nome page já está em uso com outra definição: %s", param.Suggestion)
+	}
+	if param.Line != 6 || param.Severity != "info" || param.RuleID != "inline-reuse" {
+		t.Errorf("violação do parâmetro: %+v", param)
+	}
+	if !strings.Contains(header.Message, "O cabeçalho x-fapi-interaction-id é definido inline 2 vezes") ||
+		!strings.Contains(header.Suggestion, "$ref: '#/components/headers/XFapiInteractionId', que já tem a mesma definição") {
+		t.Errorf("violação do cabeçalho: %+v", header)
+	}
+}
+
+// Só entram os grupos com mais cópias que o limite
+func TestInlineReuseLimit(t *testing.T) {
+	if found := inlineReuseViolations(mustParseYAML(t, reuseSpec), 2); len(found) != 0 {
+		t.Errorf("com limite 2, nenhuma definição passa do limite: %v", found)
+	}
+}
@@ -0,0 +1,304 @@
+package validator
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Com --strict, problemas que deixariam regras sem efeito (ajustes de regras que não
+// existem, extends sem regras) fazem a execução falhar em vez de apenas gerar aviso
+var strictRules bool
+
+func registerStrictFlag(fs *flag.FlagSet) {
+	fs.BoolVar(&strictRules, "strict", false, "falha quando o conjunto de regras tem ajustes de regras inexistentes ou extends sem regras")
+}
+
+// Funções de regra implementadas por applyFunction (naming, headers, requestBody,
+// requiredKeywords, servers, ordering, mediaTypes, identifiers e descriptions, pelos métodos de compiledRule)
+var ruleFunctions = map[string]bool{"truthy": true, "falsy": true, "defined": true, "undefined": true, "pattern": true, "schema": true, "naming": true, "headers": true, "requestBody": true, "requiredKeywords": true, "servers": true, "ordering": true, "mediaTypes": true, "identifiers": true, "descriptions": true}
+
+// Severidades aceitas nas regras
+var ruleSeverities = map[string]bool{"error": true, "warning": true, "info": true, "hint": true}
+
+// Conjunto de regras com estrutura inválida; a execução termina com o código de uso incorreto
+type invalidRulesError struct {
+	source   string
+	problems []string
+}
+
+func (e *invalidRulesError) Error() string {
+	return fmt.Sprintf("regras inválidas em %s:\n   - %s", e.source, strings.Join(e.problems, "\n   - "))
+}
+
+// Código de saída para uma falha ao carregar as regras
+func rulesExitCode(err error) int {
+	var invalid *invalidRulesError
+	if errors.As(err, &invalid) {
+		return exitUsage
+	}
+	return exitFailure
+}
+
+// Descreve o tipo encontrado em um campo das regras, para as mensagens de erro
+func ruleValueType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "vazio"
+	case string:
+		return "texto"
+	case bool:
+		return "booleano"
+	case int, float64:
+		return "número"
+	case []interface{}:
+		return "lista"
+	case map[string]interface{}:
+		return "objeto"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// Função para conferir a estrutura de cada regra já com extends aplicado: objeto com
+// given (JSONPath válido) e then (function conhecida, field e functionOptions
+// opcionais), when opcional, severidade conhecida e expressões de pattern que compilam
+func checkRules(source string, rules map[string]interface{}, origins map[string]string) error {
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	add := func(name, format string, args ...interface{}) {
+		prefix := fmt.Sprintf("regra %q: ", name)
+		if origin := origins[name]; origin != "" {
+			prefix = origin + ": " + prefix
+		}
+		problems = append(problems, prefix+fmt.Sprintf(format, args...))
+	}
+	for _, name := range names {
+		ruleData, ok := rules[name].(map[string]interface{})
+		if !ok {
+			add(name, "esperado um objeto com given e then (ou apenas a severidade, para ajustar uma regra herdada), encontrado %s", ruleValueType(rules[name]))
+			continue
+		}
+
+		if severity, ok := ruleData["severity"]; ok {
+			if s, isString := severity.(string); !isString || !ruleSeverities[s] {
+				add(name, "severity deve ser error, warning, info ou hint, encontrado %v", severity)
+			}
+		}
+		switch severities := ruleData["severities"].(type) {
+		case nil:
+		case map[string]interface{}:
+			profiles := make([]string, 0, len(severities))
+			for profile := range severities {
+				profiles = append(profiles, profile)
+			}
+			sort.Strings(profiles)
+			for _, profile := range profiles {
+				if s, isString := severities[profile].(string); !isString || !ruleSeverities[s] {
+					add(name, "severities.%s deve ser error, warning, info ou hint, encontrado %v", profile, severities[profile])
+				}
+			}
+		default:
+			add(name, "severities deve ser um objeto com a severidade de cada perfil (ex.: ga: error), encontrado %s", ruleValueType(severities))
+		}
+		if description, ok := ruleData["description"]; ok {
+			if _, isString := description.(string); !isString {
+				add(name, "description deve ser texto, encontrado %s", ruleValueType(description))
+			}
+		}
+		if suggestion, ok := ruleData["suggestion"]; ok {
+			if _, isString := suggestion.(string); !isString {
+				add(name, "suggestion deve ser texto, encontrado %s", ruleValueType(suggestion))
+			}
+		}
+
+		switch requirements := ruleData["x-requirement-id"].(type) {
+		case nil, string:
+		case []interface{}:
+			for _, id := range requirements {
+				if _, isString := id.(string); !isString {
+					add(name, "x-requirement-id deve ser um id ou uma lista de ids em texto, encontrado %s na lista", ruleValueType(id))
+				}
+			}
+		default:
+			add(name, "x-requirement-id deve ser um id ou uma lista de ids em texto, encontrado %s", ruleValueType(requirements))
+		}
+
+		switch fixable := ruleData["fixable"].(type) {
+		case nil:
+		case bool:
+			if fixable && ruleFixes[name] == nil {
+				add(name, "fixable: true, mas não há correção automática para esta regra (disponíveis: %s)", strings.Join(fixableRuleNames(), ", "))
+			}
+		default:
+			add(name, "fixable deve ser booleano, encontrado %s", ruleValueType(fixable))
+		}
+
+		given, isString := ruleData["given"].(string)
+		switch {
+		case ruleData["given"] == nil:
+			add(name, "given ausente (expressão JSONPath, ex.: \"$.info\")")
+		case !isString:
+			add(name, "given deve ser uma expressão JSONPath em texto, encontrado %s", ruleValueType(ruleData["given"]))
+		default:
+			if _, _, err := parseJSONPath(given); err != nil {
+				add(name, "given %q inválido: %v", given, err)
+			}
+		}
+
+		if _, err := parseRuleGuard(ruleData["when"]); err != nil {
+			add(name, "%v", err)
+		}
+		if _, err := parseRuleScope(ruleData["scope"]); err != nil {
+			add(name, "%v", err)
+		}
+		if _, err := parseRuleTargets(ruleData["targets"], given); err != nil {
+			add(name, "%v", err)
+		}
+
+		then, isMap := ruleData["then"].(map[string]interface{})
+		if !isMap {
+			if ruleData["then"] == nil {
+				add(name, "then ausente (objeto com function e, opcionalmente, field)")
+			} else {
+				add(name, "then deve ser um objeto com function e field, encontrado %s", ruleValueType(ruleData["then"]))
+			}
+			continue
+		}
+		function, isString := then["function"].(string)
+		switch {
+		case then["function"] == nil:
+			add(name, "then.function ausente (use truthy, falsy, defined, undefined, pattern, schema, naming, headers, requestBody, requiredKeywords, servers, ordering, mediaTypes, identifiers ou descriptions)")
+		case !isString || !ruleFunctions[function]:
+			add(name, "then.function %v desconhecida (use truthy, falsy, defined, undefined, pattern, schema, naming, headers, requestBody, requiredKeywords, servers, ordering, mediaTypes, identifiers ou descriptions)", then["function"])
+		}
+		if field, ok := then["field"]; ok {
+			if _, isString := field.(string); !isString {
+				add(name, "then.field deve ser texto, encontrado %s", ruleValueType(field))
+			}
+		}
+		options, hasOptions := then["functionOptions"]
+		optionsMap, isMap := options.(map[string]interface{})
+		if hasOptions && !isMap {
+			add(name, "then.functionOptions deve ser um objeto, encontrado %s", ruleValueType(options))
+		}
+		if function == "schema" {
+			if optionsMap["schema"] == nil {
+				add(name, "a função schema exige functionOptions.schema (nome de um schema embarcado, arquivo ou objeto)")
+			} else if _, err := ruleSchema(optionsMap["schema"]); err != nil {
+				add(name, "%v", err)
+			}
+		}
+		if function == "naming" {
+			if len(optionsMap) == 0 {
+				add(name, "a função naming exige functionOptions com o padrão de nome de cada seção de components (ex.: schemas: \"^[A-Z][A-Za-z0-9]*$\")")
+			}
+			_, namingProblems := parseNamingOptions(optionsMap)
+			for _, problem := range namingProblems {
+				add(name, "%s", problem)
+			}
+		}
+		if function == "headers" {
+			_, headerProblems := parseHeaderCatalogue(optionsMap)
+			for _, problem := range headerProblems {
+				add(name, "%s", problem)
+			}
+		}
+		if function == "requestBody" {
+			_, bodyProblems := parseBodyOptions(optionsMap)
+			for _, problem := range bodyProblems {
+				add(name, "%s", problem)
+			}
+		}
+		if function == "requiredKeywords" {
+			_, keywordProblems := parseKeywordOptions(optionsMap)
+			for _, problem := range keywordProblems {
+				add(name, "%s", problem)
+			}
+		}
+		if function == "servers" {
+			_, serverProblems := parseServerOptions(optionsMap)
+			for _, problem := range serverProblems {
+				add(name, "%s", problem)
+			}
+		}
+		if function == "ordering" {
+			_, orderingProblems := parseOrderingOptions(optionsMap)
+			for _, problem := range orderingProblems {
+				add(name, "%s", problem)
+			}
+		}
+		if function == "mediaTypes" {
+			_, mediaTypeProblems := parseMediaTypeOptions(optionsMap)
+			for _, problem := range mediaTypeProblems {
+				add(name, "%s", problem)
+			}
+		}
+		if function == "identifiers" {
+			_, identifierProblems := parseIdentifierOptions(optionsMap)
+			for _, problem := range identifierProblems {
+				add(name, "%s", problem)
+			}
+		}
+		if function == "descriptions" {
+			_, descriptionProblems := parseDescriptionOptions(optionsMap)
+			for _, problem := range descriptionProblems {
+				add(name, "%s", problem)
+			}
+		}
+		if function == "pattern" {
+			if optionsMap["match"] == nil && optionsMap["notMatch"] == nil {
+				add(name, "a função pattern exige functionOptions.match ou functionOptions.notMatch")
+			}
+			for _, key := range []string{"match", "notMatch"} {
+				value, ok := optionsMap[key]
+				if !ok {
+					continue
+				}
+				expr, isString := value.(string)
+				if !isString {
+					add(name, "functionOptions.%s deve ser uma expressão regular em texto, encontrado %s", key, ruleValueType(value))
+					continue
+				}
+				if _, err := regexp.Compile(expr); err != nil {
+					add(name, "functionOptions.%s %q não é uma expressão regular válida: %v", key, expr, err)
+				}
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return &invalidRulesError{source: source, problems: problems}
+	}
+	return nil
+}
+
+// Função para concluir o carregamento de um conjunto de regras: confere a estrutura e
+// trata os problemas que só são fatais com --strict (sem --strict, viram avisos)
+func finishRules(source string, rules map[string]interface{}, loader *ruleLoader) error {
+	err := checkRules(source, rules, loader.origins)
+	if len(loader.loose) == 0 {
+		return err
+	}
+	if !loader.settings.strictRules {
+		for _, problem := range loader.loose {
+			fmt.Fprintln(loader.settings.log, "⚠️ ", problem)
+		}
+		return err
+	}
+	var invalid *invalidRulesError
+	if errors.As(err, &invalid) {
+		invalid.problems = append(invalid.problems, loader.loose...)
+		return invalid
+	}
+	if err != nil {
+		return err
+	}
+	return &invalidRulesError{source: source, problems: loader.loose}
+}
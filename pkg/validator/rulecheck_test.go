@@ -0,0 +1,47 @@
+package validator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// O ajuste de uma regra que não existe e o extends sem regras são avisos; com --strict,
+// viram problemas das regras, cada um com arquivo e linha, e a execução sai com o
+// código de uso incorreto
+func TestStrictRules(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"vazio.yaml": "rules: {}\n",
+		"regras.yaml": `extends: [ofb, vazio.yaml]
+rules:
+  regra-inexistente: warning
+`,
+	})
+	spec := filepath.Join("testdata", "e2e", "valid", "api.yaml")
+	rules := filepath.Join(dir, "regras.yaml")
+	problems := []string{
+		rules + `:1: extends "vazio.yaml" não define nenhuma regra`,
+		rules + `:3: muda a severidade da regra "regra-inexistente", que não existe em nenhum extends`,
+	}
+
+	code, out := runCommand(t, "validate", "--no-cache", "--rules", rules, spec)
+	if code != exitOK {
+		t.Errorf("sem --strict: código %d\n%s", code, out)
+	}
+	for _, problem := range problems {
+		if !strings.Contains(out, "⚠️  "+problem) {
+			t.Errorf("sem --strict: saída sem o aviso %q:\n%s", problem, out)
+		}
+	}
+
+	code, out = runCommand(t, "validate", "--no-cache", "--strict", "--rules", rules, spec)
+	if code != exitUsage || !strings.Contains(out, "❌ Erro ao carregar as regras: regras inválidas em "+rules) {
+		t.Errorf("com --strict: código %d, esperado %d\n%s", code, exitUsage, out)
+	}
+	for _, problem := range problems {
+		if !strings.Contains(out, "   - "+problem) {
+			t.Errorf("com --strict: saída sem o problema %q:\n%s", problem, out)
+		}
+	}
+}
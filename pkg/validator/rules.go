@@ -0,0 +1,942 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Pacotes de regras que podem ser usados em "extends" pelo nome; a CLI registra o
+// pacote do Open Finance Brasil (ofb), embarcado no binário
+var builtinRulesets = map[string][]byte{}
+
+// RegisterRuleset torna um pacote de regras disponível pelo nome, em --rules e em
+// "extends". Deve ser chamada antes de qualquer validação.
+func RegisterRuleset(name string, data []byte) {
+	builtinRulesets[name] = data
+}
+
+// Hash dos pacotes embarcados, pelo nome e conteúdo de cada um
+func builtinRulesetsHash() []byte {
+	names := make([]string, 0, len(builtinRulesets))
+	for name := range builtinRulesets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([][]byte, 0, 2*len(names))
+	for _, name := range names {
+		parts = append(parts, []byte(name), builtinRulesets[name])
+	}
+	return []byte(contentHash(parts...))
+}
+
+// Estado do carregamento de um conjunto de regras: arquivos em processamento
+// (para detectar extends circular), hash de cada arquivo lido, onde cada regra foi
+// definida ("arquivo:linha") e os problemas que só são fatais com --strict
+type ruleLoader struct {
+	settings *runSettings
+
+	seen    map[string]bool
+	deps    map[string]string
+	origins map[string]string
+	loose   []string
+}
+
+func newRuleLoader(s *runSettings) *ruleLoader {
+	return &ruleLoader{settings: s, seen: map[string]bool{}, deps: map[string]string{}, origins: map[string]string{}}
+}
+
+// Entrada do cache de regras: as regras já interpretadas e os arquivos de que dependem
+type rulesCacheEntry struct {
+	Deps     map[string]string      `yaml:"deps"`
+	Rules    map[string]interface{} `yaml:"rules"`
+	Warnings []string               `yaml:"warnings,omitempty"` // avisos do carregamento, repetidos a cada uso
+}
+
+// Função para carregar o arquivo de regras (formato pb33f_rules.yaml)
+func loadRules(s *runSettings, rulesFile string) (map[string]interface{}, error) {
+	s.tracker.setRules(rulesFile)
+	data, err := s.readSource(rulesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// O cache só é válido se nenhum arquivo de extends mudou desde que foi gravado.
+	// O hash considera o conteúdo já com as variáveis de ambiente expandidas, todos os
+	// pacotes embarcados (qualquer um pode ser alcançado por extends) e a versão da
+	// ferramenta, que muda a interpretação das regras.
+	cache := s.activeCache()
+	key := contentHash([]byte(expandedHash(data, rulesFile)), []byte(rulesFile), builtinRulesetsHash(), []byte(toolFingerprint()), []byte(fmt.Sprint(s.strictRules)))
+	var entry rulesCacheEntry
+	if cache.getYAML("rules", key, &entry) && depsUnchanged(s, entry.Deps, expandedHash) {
+		for _, warning := range entry.Warnings {
+			fmt.Fprintln(s.log, "⚠️ ", warning)
+		}
+		return entry.Rules, nil
+	}
+
+	loader := newRuleLoader(s)
+	loader.seen[rulesFile] = true
+	rules, err := parseRuleset(data, rulesFile, sourceDir(rulesFile), loader)
+	if err != nil {
+		return nil, err
+	}
+	if err := finishRules(rulesFile, rules, loader); err != nil {
+		return nil, err
+	}
+	cache.putYAML("rules", key, rulesCacheEntry{Deps: loader.deps, Rules: rules, Warnings: loader.loose})
+	return rules, nil
+}
+
+// Entrada do cache de índice: erros de referência e os arquivos externos indexados
+type indexCacheEntry struct {
+	Deps   map[string]string `yaml:"deps"`
+	Errors []resolutionError `yaml:"errors"`
+	Cycles []string          `yaml:"cycles"`
+}
+
+// Chave do cache de índice: o conteúdo da especificação (já convertida e com os
+// overlays), o arquivo e o diretório base das referências
+func indexCacheKey(doc *specDocument) string {
+	return contentHash(doc.data, []byte(doc.file), []byte(doc.settings.baseDir), overlaysHash(doc.settings))
+}
+
+// Confere, pelo hash do conteúdo, se os arquivos de que uma entrada do cache depende continuam iguais
+func depsUnchanged(s *runSettings, deps map[string]string, hashOf func(data []byte, source string) string) bool {
+	for path, hash := range deps {
+		data, err := s.readSource(path)
+		if err != nil || hashOf(data, path) != hash {
+			return false
+		}
+	}
+	return true
+}
+
+// Hash do conteúdo como está no disco
+func rawHash(data []byte, source string) string {
+	return contentHash(data)
+}
+
+// Hash do conteúdo após a expansão de variáveis de ambiente
+func expandedHash(data []byte, source string) string {
+	expanded, err := expandEnv(data, source)
+	if err != nil {
+		return ""
+	}
+	return contentHash(expanded)
+}
+
+// Interpreta um conjunto de regras, aplicando primeiro os pacotes de "extends".
+// As regras do próprio arquivo substituem as herdadas com o mesmo nome; uma
+// entrada com apenas a severidade (ex.: "require-contact-info: off") ajusta a
+// regra herdada.
+func parseRuleset(data []byte, source, baseDir string, loader *ruleLoader) (map[string]interface{}, error) {
+	data, err := expandEnv(data, source)
+	if err != nil {
+		// Uma variável sem valor é um problema do arquivo de regras, como um campo inválido
+		var undefined *undefinedEnvError
+		if errors.As(err, &undefined) {
+			return nil, &invalidRulesError{source: source, problems: []string{undefined.problem()}}
+		}
+		return nil, err
+	}
+
+	var ruleset map[string]interface{}
+	if err := yaml.Unmarshal(data, &ruleset); err != nil {
+		return nil, yamlSyntaxError(data, source, err)
+	}
+
+	lines := ruleLines(data)
+	at := func(key string) string {
+		if line := lines[key]; line > 0 {
+			return fmt.Sprintf("%s:%d", source, line)
+		}
+		return source
+	}
+
+	// Arquivos do Spectral (.spectral.yaml) são traduzidos para o formato próprio; as
+	// regras sem equivalente ficam de fora com um aviso
+	if isSpectralRuleset(ruleset, source) {
+		var skipped []spectralSkip
+		ruleset, skipped = convertSpectralRuleset(ruleset)
+		for _, skip := range skipped {
+			if skip.rule == "" {
+				loader.loose = append(loader.loose, fmt.Sprintf("%s: %s", at(skip.key), skip.reason))
+				continue
+			}
+			loader.loose = append(loader.loose, fmt.Sprintf("%s: regra %q do Spectral ignorada: %s", at("rules:"+skip.rule), skip.rule, skip.reason))
+		}
+	}
+
+	rules := map[string]interface{}{}
+	targets, problem := extendsTargets(ruleset["extends"])
+	if problem != "" {
+		return nil, &invalidRulesError{source: source, problems: []string{fmt.Sprintf("%s: %s", at("extends"), problem)}}
+	}
+	for _, target := range targets {
+		inherited, err := loadExtends(target, baseDir, loader)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao carregar extends %q de %s: %w", target, source, err)
+		}
+		if len(inherited) == 0 {
+			loader.loose = append(loader.loose, fmt.Sprintf("%s: extends %q não define nenhuma regra", at("extends:"+target), target))
+		}
+		for name, rule := range inherited {
+			rules[name] = rule
+		}
+	}
+
+	own, ok := ruleset["rules"].(map[string]interface{})
+	if !ok && ruleset["rules"] != nil {
+		return nil, &invalidRulesError{source: source, problems: []string{fmt.Sprintf("a seção 'rules' deve ser um objeto com uma entrada por regra, encontrado %s", ruleValueType(ruleset["rules"]))}}
+	}
+	if !ok && ruleset["extends"] == nil {
+		return nil, &invalidRulesError{source: source, problems: []string{"o arquivo não possui a seção 'rules'"}}
+	}
+	names := make([]string, 0, len(own))
+	for name := range own {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rule := own[name]
+		base, inherited := rules[name].(map[string]interface{})
+		switch value := rule.(type) {
+		case string:
+			// Ajuste de uma regra herdada: "off" ou a nova severidade
+			if !inherited {
+				action := "desativa a regra"
+				if value != "off" {
+					action = "muda a severidade da regra"
+				}
+				loader.loose = append(loader.loose, fmt.Sprintf("%s: %s %q, que não existe em nenhum extends", at("rules:"+name), action, name))
+				continue
+			}
+			if value == "off" {
+				delete(rules, name)
+				continue
+			}
+			// A severidade ajustada vale em todos os perfis
+			adjusted := withRuleFields(base, map[string]interface{}{"severity": value})
+			delete(adjusted, "severities")
+			rules[name] = adjusted
+			continue
+		case map[string]interface{}:
+			if value["given"] == nil && inherited {
+				rules[name] = withRuleFields(base, value)
+				loader.origins[name] = at("rules:" + name)
+				continue
+			}
+			// Sem given nem then não é uma regra nova: é o ajuste de uma regra que não existe
+			if value["given"] == nil && value["then"] == nil {
+				loader.loose = append(loader.loose, fmt.Sprintf("%s: ajusta a regra %q, que não existe em nenhum extends", at("rules:"+name), name))
+				continue
+			}
+		}
+		rules[name] = rule
+		loader.origins[name] = at("rules:" + name)
+	}
+	return rules, nil
+}
+
+// Linhas das entradas de um arquivo de regras: "rules:<nome>" para cada regra e
+// "extends:<alvo>" para cada extends, para que os problemas apontem arquivo e linha
+func ruleLines(data []byte) map[string]int {
+	lines := map[string]int{}
+	var root yaml.Node
+	if yaml.Unmarshal(data, &root) != nil || len(root.Content) == 0 {
+		return lines
+	}
+	doc := root.Content[0]
+	if section := mappingValue(doc, "rules"); section != nil && section.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(section.Content); i += 2 {
+			lines["rules:"+section.Content[i].Value] = section.Content[i].Line
+		}
+	}
+	if extends := mappingValue(doc, "extends"); extends != nil {
+		lines["extends"] = extends.Line
+		targets := []*yaml.Node{extends}
+		if extends.Kind == yaml.SequenceNode {
+			targets = extends.Content
+		}
+		for _, t := range targets {
+			lines["extends:"+t.Value] = t.Line
+		}
+	}
+	return lines
+}
+
+// Carrega um alvo de "extends": nome de pacote embarcado, URL ou caminho relativo ao arquivo de regras
+func loadExtends(target, baseDir string, loader *ruleLoader) (map[string]interface{}, error) {
+	if data, ok := builtinRulesets[target]; ok {
+		return parseRuleset(data, target, baseDir, loader)
+	}
+
+	path := joinSource(baseDir, target)
+	if loader.seen[path] {
+		return nil, fmt.Errorf("extends circular envolvendo %s", path)
+	}
+	loader.seen[path] = true
+	defer delete(loader.seen, path)
+
+	data, err := loader.settings.readSource(path)
+	if err != nil {
+		return nil, err
+	}
+	loader.deps[path] = expandedHash(data, path)
+	return parseRuleset(data, path, sourceDir(path), loader)
+}
+
+// "extends" aceita um nome ou uma lista de nomes; qualquer outra entrada (número,
+// lista dentro da lista...) é descrita no problema retornado
+func extendsTargets(value interface{}) ([]string, string) {
+	switch v := value.(type) {
+	case nil:
+		return nil, ""
+	case string:
+		return []string{v}, ""
+	case []interface{}:
+		var targets []string
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Sprintf("a entrada %d de extends deve ser o nome de um pacote, uma URL ou um caminho, encontrado %s", i+1, describeExtendsEntry(item))
+			}
+			targets = append(targets, s)
+		}
+		return targets, ""
+	}
+	return nil, fmt.Sprintf("extends deve ser o nome de um pacote, uma URL, um caminho ou uma lista deles, encontrado %s", describeExtendsEntry(value))
+}
+
+// Tipo de uma entrada inválida de extends, com o valor quando é escalar
+func describeExtendsEntry(value interface{}) string {
+	switch value.(type) {
+	case []interface{}, map[string]interface{}:
+		return ruleValueType(value)
+	}
+	return fmt.Sprintf("%s (%v)", ruleValueType(value), value)
+}
+
+// Retorna uma cópia da regra com os campos informados sobrescritos
+func withRuleFields(base, fields map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(fields))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return merged
+}
+
+// Carrega um conjunto de regras pelo nome de um pacote embarcado ou pelo caminho do arquivo
+func loadRuleset(s *runSettings, nameOrPath string) (map[string]interface{}, error) {
+	if data, ok := builtinRulesets[nameOrPath]; ok {
+		// Um arquivo com o mesmo nome, informado explicitamente, substitui o pacote embarcado
+		if info, err := s.statInput(nameOrPath); err != nil || info.IsDir() {
+			return loadRulesData(s, data, nameOrPath, ".")
+		}
+		fmt.Fprintf(s.log, "ℹ️  Usando o arquivo %s no lugar do pacote embarcado de mesmo nome\n", nameOrPath)
+	}
+	return loadRules(s, nameOrPath)
+}
+
+// Carrega um conjunto de regras já lido (pacote embarcado ou Options.Rules), sem cache
+func loadRulesData(s *runSettings, data []byte, source, baseDir string) (map[string]interface{}, error) {
+	loader := newRuleLoader(s)
+	rules, err := parseRuleset(data, source, baseDir, loader)
+	if err != nil {
+		return nil, err
+	}
+	return rules, finishRules(source, rules, loader)
+}
+
+// Função para validar uma especificação já lida com as regras do arquivo ou do pacote
+// embarcado informado
+func validateDocumentWithRules(ctx context.Context, doc *specDocument, rulesFile string) ([]Violation, error) {
+	rules, err := loadRuleset(doc.settings, rulesFile)
+	if err != nil {
+		return nil, err
+	}
+	violations, _, err := validateDocument(ctx, doc, rules)
+	return violations, err
+}
+
+// Função para validar um arquivo OpenAPI com um conjunto de regras já carregado
+func validateOpenAPI(ctx context.Context, s *runSettings, inputFile string, rules map[string]interface{}) ([]Violation, error) {
+	violations, _, err := validateSpec(ctx, s, inputFile, rules)
+	return violations, err
+}
+
+// Resultados da validação além das violações, para o relatório JSON
+type specDetails struct {
+	coverage *ruleCoverage      // com --coverage
+	metrics  []operationMetrics // complexidade dos schemas por operação
+	maturity *maturityReport    // operações por estágio de x-maturity
+
+	duplicates []duplicateGroup // schemas idênticos, com --duplicates
+
+	examplesMatrix []operationExamples // exemplos por resposta, com --examples-matrix
+
+	notApplicable []string // regras com when ou scope que não se aplicam ao documento
+	profile       string   // perfil de severidades aplicado (--profile)
+}
+
+// Valida como validateOpenAPI e também retorna os detalhes para o relatório: a
+// cobertura das regras, com --coverage, e as métricas de complexidade, quando o
+// documento chega a ser resolvido
+func validateSpec(ctx context.Context, s *runSettings, inputFile string, rules map[string]interface{}) ([]Violation, specDetails, error) {
+	doc, err := openSpecDocument(s, inputFile)
+	if err != nil {
+		return nil, specDetails{}, err
+	}
+	return validateDocument(ctx, doc, rules)
+}
+
+// Função para validar uma especificação já lida; o índice e a resolução ficam no
+// documento e são reaproveitados por quem o usar depois (resolução, comparação)
+func validateDocument(ctx context.Context, doc *specDocument, rules map[string]interface{}) ([]Violation, specDetails, error) {
+	var details specDetails
+	inputFile, data, rootNode, s := doc.file, doc.data, doc.root, doc.settings
+	s.tracker.enter("validate", inputFile)
+
+	// Severidades do perfil da especificação (--profile ou info.version)
+	profile, err := specProfile(s.profile, rootNode, rules)
+	if err != nil {
+		return nil, details, err
+	}
+	rules = applyProfile(rules, profile)
+	details.profile = profile
+
+	violations := yamlHygieneViolations(data, s.strictYAML)
+	violations = append(violations, discriminatorViolations(inputFile, rootNode)...)
+	violations = append(violations, securityViolations(rootNode)...)
+	violations = append(violations, maturityViolations(rootNode)...)
+	violations = append(violations, operationLinkViolations(inputFile, rootNode)...)
+	violations = append(violations, pathConflictViolations(s, rootNode)...)
+	violations = append(violations, versionConsistencyViolations(s, inputFile, rootNode)...)
+	if s.inlineReuseLimit > 0 {
+		violations = append(violations, inlineReuseViolations(rootNode, s.inlineReuseLimit)...)
+	}
+	if registry, err := s.extensionRegistry(); err == nil && registry != nil {
+		violations = append(violations, extensionViolations(rootNode, registry, s.unregisteredExts)...)
+	}
+	details.maturity = maturitySummary(rootNode)
+
+	// Referências que o indexador não conseguiu localizar. O cache é indexado pelo
+	// conteúdo da especificação e vale enquanto os arquivos referenciados não mudarem;
+	// com $refs remotos não há como conferir, então o cache não é usado.
+	s.tracker.enter("index", inputFile)
+	cache := s.activeCache()
+	if s.allowRemoteRefs {
+		cache = nil
+	}
+	specKey := indexCacheKey(doc)
+	var entry indexCacheEntry
+	if !cache.getYAML("index", specKey, &entry) || !depsUnchanged(s, entry.Deps, rawHash) {
+		// Os arquivos locais referenciados entram mesmo quando o rolodex não os lista;
+		// a lista sai da árvore antes da resolução
+		deps := map[string]string{}
+		if cache != nil {
+			deps = specDependencies(s, inputFile, rootNode)
+		}
+		spec, err := doc.index(ctx)
+		if err != nil {
+			if isCancellation(err) {
+				return violations, details, err
+			}
+			return nil, details, err
+		}
+		// A resolução acrescenta os próprios erros ao relatório; o cache guarda só os do índice
+		report := resolutionReport{Errors: append([]resolutionError(nil), spec.report.Errors...), Cycles: append([]referenceCycle(nil), spec.report.Cycles...)}
+		entry = indexCacheEntry{Deps: deps, Errors: report.Errors, Cycles: []string{}}
+		for _, idx := range doc.rolodex.GetIndexes() {
+			if dep := idx.GetSpecAbsolutePath(); dep != "" {
+				if depData, err := s.readFile(dep); err == nil {
+					entry.Deps[dep] = rawHash(depData, dep)
+				}
+			}
+		}
+		for _, c := range report.Cycles {
+			entry.Cycles = append(entry.Cycles, c.String())
+		}
+		cache.putYAML("index", specKey, entry)
+	}
+	refErrors := entry.Errors
+	for _, refErr := range refErrors {
+		violations = append(violations, referenceViolation(refErr))
+	}
+	for _, cycle := range entry.Cycles {
+		violations = append(violations, cycleViolation(s, cycle))
+	}
+	s.tracker.addPartial(violations...)
+	s.tracker.enter("validate", inputFile)
+
+	// Ordena os nomes para que a saída seja estável entre execuções
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Swagger 2.0 sem conversão (--no-convert): regras exclusivas de OpenAPI 3 ficam de fora
+	reduced := isSwagger2(rootNode)
+	skipped := 0
+	defer func() {
+		if skipped > 0 {
+			fmt.Fprintf(s.log, "ℹ️  %s: %d regras exclusivas de OpenAPI 3 ignoradas (Swagger 2.0 com --no-convert)\n", inputFile, skipped)
+		}
+	}()
+
+	var recorder *coverageRecorder
+	if s.coverage {
+		recorder = newCoverageRecorder()
+	}
+	selected := make([]string, 0, len(names))
+	for _, name := range names {
+		ruleData, ok := rules[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if given, _ := ruleData["given"].(string); reduced && isOpenAPI3OnlyRule(given) {
+			skipped++
+			continue
+		}
+		selected = append(selected, name)
+	}
+	outcomes, err := evaluateRules(ctx, s, rootNode, selected, rules)
+	for i, outcome := range outcomes {
+		if !outcome.done {
+			continue
+		}
+		if outcome.notApplicable {
+			details.notApplicable = append(details.notApplicable, selected[i])
+		}
+		recorder.record(selected[i], outcome.matched)
+		s.tracker.addPartial(outcome.violations...)
+		violations = append(violations, outcome.violations...)
+	}
+	if err != nil {
+		return violations, details, err
+	}
+	details.coverage = recorder.result(rootNode)
+
+	if s.checkLinks {
+		s.tracker.enter("links", inputFile)
+		found := linkViolations(ctx, s, rootNode)
+		if err := ctx.Err(); err != nil {
+			return violations, details, err
+		}
+		s.tracker.addPartial(found...)
+		violations = append(violations, found...)
+	}
+
+	// Análises dos schemas no documento resolvido: combinações allOf impossíveis,
+	// métricas e limites de complexidade, com --validate-examples, exemplos contra
+	// os schemas, com --duplicates, schemas idênticos e a matriz de exemplos das respostas
+	s.tracker.enter("schemas", inputFile)
+	spec, err := doc.resolve(ctx)
+	if err != nil {
+		if isCancellation(err) {
+			return violations, details, err
+		}
+		// Falhas de resolução já aparecem nos erros do índice
+		if len(refErrors) == 0 {
+			violations = append(violations, Violation{RuleID: "schemas-analysis", Severity: "warning", Message: fmt.Sprintf("Os schemas não foram analisados: %v", err)})
+		}
+		return violations, details, nil
+	}
+	found := referencedRuleViolations(ctx, s, spec, selected, rules)
+	if err := ctx.Err(); err != nil {
+		return violations, details, err
+	}
+	found = append(found, allOfViolations(&spec.rootNode)...)
+	if s.validateExamples {
+		found = append(found, exampleViolations(&spec.rootNode)...)
+	}
+	details.metrics = complexityMetrics(rootNode, &spec.rootNode)
+	found = append(found, complexityViolations(s, &spec.rootNode, details.metrics)...)
+	if s.duplicates != "off" {
+		details.duplicates = findDuplicateSchemas(rootNode, &spec.rootNode, s.duplicates == "near")
+		found = append(found, duplicateViolations(details.duplicates)...)
+	}
+	if s.examplesMatrix || s.examplesMinCover > 0 {
+		matrix := examplesMatrixFor(&spec.rootNode)
+		if s.examplesMatrix {
+			details.examplesMatrix = matrix
+		}
+		found = append(found, examplesCoverageViolations(s, matrix)...)
+	}
+	found = spec.attributeOrigins(found)
+	s.tracker.addPartial(found...)
+	return append(violations, found...), details, nil
+}
+
+// Função para ler a especificação e retornar a árvore YAML
+func loadSpecNode(inputFile string) (*yaml.Node, error) {
+	data, err := readSpecFile(inputFile)
+	if err != nil {
+		return nil, err
+	}
+	return parseSpec(data, inputFile)
+}
+
+// Função para converter o conteúdo da especificação na árvore YAML. Especificações
+// em JSON são detectadas pelo conteúdo e têm a sintaxe conferida antes.
+func parseSpec(data []byte, source string) (*yaml.Node, error) {
+	return parseSpecDocument(data, source, 0)
+}
+
+// Aplica uma regra ao documento e retorna as violações encontradas, os caminhos dos
+// nós que o given da regra encontrou e se, pelo when ou pelo scope, a regra não se aplica
+func evaluateRule(scope *guardScope, name string, ruleData map[string]interface{}) ruleOutcome {
+	outcome := ruleOutcome{done: true}
+	rule := compileRule(name, ruleData)
+	if rule == nil {
+		return outcome
+	}
+	matches, ok := rule.query(scope)
+	switch {
+	case !ok:
+	case matches == nil && rule.conditional():
+		outcome.notApplicable = true
+	case len(matches) == 0:
+		outcome.violations = rule.missing()
+	default:
+		outcome.matched = make([]string, 0, len(matches))
+		for _, m := range matches {
+			outcome.matched = append(outcome.matched, m.Path)
+		}
+		outcome.violations = rule.check(matches)
+	}
+	return outcome
+}
+
+// Regra com os campos já lidos, para avaliar os resultados do given em partes
+type compiledRule struct {
+	name         string
+	given        string
+	severity     string
+	description  string
+	function     string
+	field        string
+	options      map[string]interface{}
+	suggestion   string
+	guard        *ruleGuard // when, quando a regra é condicional
+	paths        *pathScope // scope, quando a regra vale só para alguns paths
+	targets      []string   // targets, quando a regra cobre também webhooks ou callbacks
+	naming       []namingPattern
+	headers      []headerRequirement
+	body         *bodyRequirement
+	keywords     *keywordRequirement
+	servers      *serverRequirement
+	ordering     *orderingRequirement
+	mediaTypes   *mediaTypeRequirement
+	identifiers  *identifierRequirement
+	descriptions *descriptionRequirement
+	root         *yaml.Node // documento avaliado, para seguir os $refs locais (headers, requestBody)
+}
+
+// Lê os campos da regra; nulo quando a regra não tem given ou then
+func compileRule(name string, ruleData map[string]interface{}) *compiledRule {
+	given, _ := ruleData["given"].(string)
+	then, _ := ruleData["then"].(map[string]interface{})
+	if given == "" || then == nil {
+		return nil
+	}
+	rule := &compiledRule{name: name, given: given}
+	rule.severity, _ = ruleData["severity"].(string)
+	if rule.severity == "" {
+		rule.severity = "warning"
+	}
+	rule.description, _ = ruleData["description"].(string)
+	if strings.TrimSpace(rule.description) == "" {
+		// Sem description, a mensagem identifica a regra pelo nome
+		rule.description = "Regra " + name + " violada"
+	}
+	rule.function, _ = then["function"].(string)
+	rule.field, _ = then["field"].(string)
+	rule.options, _ = then["functionOptions"].(map[string]interface{})
+	rule.suggestion, _ = ruleData["suggestion"].(string)
+	// O when já foi conferido ao carregar as regras
+	rule.guard, _ = parseRuleGuard(ruleData["when"])
+	rule.paths, _ = parseRuleScope(ruleData["scope"])
+	rule.targets, _ = parseRuleTargets(ruleData["targets"], given)
+	if rule.function == "naming" {
+		rule.naming, _ = parseNamingOptions(rule.options)
+	}
+	if rule.function == "headers" {
+		rule.headers, _ = parseHeaderCatalogue(rule.options)
+	}
+	if rule.function == "requestBody" {
+		rule.body, _ = parseBodyOptions(rule.options)
+	}
+	if rule.function == "requiredKeywords" {
+		rule.keywords, _ = parseKeywordOptions(rule.options)
+	}
+	if rule.function == "servers" {
+		rule.servers, _ = parseServerOptions(rule.options)
+	}
+	if rule.function == "ordering" {
+		rule.ordering, _ = parseOrderingOptions(rule.options)
+	}
+	if rule.function == "mediaTypes" {
+		rule.mediaTypes, _ = parseMediaTypeOptions(rule.options)
+	}
+	if rule.function == "identifiers" {
+		rule.identifiers, _ = parseIdentifierOptions(rule.options)
+	}
+	if rule.function == "descriptions" {
+		rule.descriptions, _ = parseDescriptionOptions(rule.options)
+	}
+	return rule
+}
+
+// Os nós selecionados pelo given para os quais o when vale e que ficam no scope; falso
+// quando a expressão é inválida. Com when ou scope, nulo indica que a regra não se
+// aplica ao documento. Com --changed-only, ficam só os nós das regiões alteradas.
+func (r *compiledRule) query(scope *guardScope) ([]pathMatch, bool) {
+	r.root = scope.root
+	matches, ok := r.applicable(scope)
+	if ok && matches != nil && scope.changed != nil {
+		matches = scope.changed.matches(matches)
+	}
+	return matches, ok
+}
+
+func (r *compiledRule) applicable(scope *guardScope) ([]pathMatch, bool) {
+	if r.guard != nil && !r.guard.relative && !r.guard.holds(scope, nil) {
+		return nil, true
+	}
+	var matches []pathMatch
+	for _, given := range r.targetGivens() {
+		found, err := queryJSONPath(scope.root, given)
+		if err != nil {
+			return nil, false
+		}
+		matches = append(matches, found...)
+	}
+	relative := r.guard != nil && r.guard.relative
+	if !relative && r.paths == nil {
+		if matches == nil {
+			matches = []pathMatch{}
+		}
+		return matches, true
+	}
+	var applicable []pathMatch
+	for _, m := range matches {
+		if relative && !r.guard.holds(scope, m.Node) {
+			continue
+		}
+		if r.paths != nil {
+			if _, in := r.paths.match(m.Path); !in {
+				continue
+			}
+		}
+		applicable = append(applicable, m)
+	}
+	return applicable, true
+}
+
+// A regra tem when ou scope e pode não se aplicar ao documento
+func (r *compiledRule) conditional() bool {
+	return r.guard != nil || r.paths != nil
+}
+
+func (r *compiledRule) violation(path string, line int, value *yaml.Node) Violation {
+	v := Violation{RuleID: r.name, Severity: r.severity, Message: r.description, JSONPath: path, Line: line}
+	if r.paths != nil {
+		if pattern, in := r.paths.match(path); in {
+			v.Message = strings.TrimSpace(v.Message + " (escopo " + pattern + ")")
+		}
+	}
+	if r.targets != nil {
+		if context := targetContext(path); context != "" {
+			v.Message = strings.TrimSpace(v.Message + " (" + context + ")")
+		}
+	}
+	if r.suggestion != "" {
+		v.Suggestion = renderSuggestion(r.suggestion, path, r.field, value)
+	}
+	return v
+}
+
+// Um caminho definido que não existe no documento viola regras de presença
+func (r *compiledRule) missing() []Violation {
+	if r.function == "truthy" && isDefinitePath(r.given) {
+		return []Violation{r.violation(r.given, 0, nil)}
+	}
+	return nil
+}
+
+// Aplica a função da regra a cada nó selecionado, na ordem do given
+func (r *compiledRule) check(matches []pathMatch) []Violation {
+	var violations []Violation
+	for _, m := range matches {
+		target, path := m.Node, m.Path
+		if r.field != "" {
+			target = mappingValue(m.Node, r.field)
+			path = joinPath(m.Path, r.field)
+		}
+		// naming gera uma violação por componente, não uma por nó
+		if r.function == "naming" {
+			violations = append(violations, r.namingViolations(target, path)...)
+			continue
+		}
+		// headers gera uma violação por resposta e cabeçalho da operação
+		if r.function == "headers" {
+			violations = append(violations, r.headerViolations(m)...)
+			continue
+		}
+		// requestBody e requiredKeywords dão mensagens próprias para a operação e o parâmetro
+		if r.function == "requestBody" {
+			violations = append(violations, r.requestBodyViolations(m)...)
+			continue
+		}
+		if r.function == "requiredKeywords" {
+			violations = append(violations, r.requiredKeywordViolations(m)...)
+			continue
+		}
+		// servers compara os servidores dos três níveis do documento selecionado
+		if r.function == "servers" {
+			violations = append(violations, r.serverViolations(m)...)
+			continue
+		}
+		// mediaTypes confere os media types de todas as operações do documento selecionado
+		if r.function == "mediaTypes" {
+			violations = append(violations, r.mediaTypeViolations(m)...)
+			continue
+		}
+		// identifiers compara o parâmetro das consultas por id com o schema da resposta
+		if r.function == "identifiers" {
+			violations = append(violations, r.identifierViolations(m)...)
+			continue
+		}
+		// descriptions reúne as descrições do documento antes de procurar as repetidas
+		if r.function == "descriptions" {
+			violations = append(violations, r.descriptionViolations(m)...)
+			continue
+		}
+		// ordering aponta o primeiro elemento fora de ordem, não o nó inteiro
+		if r.function == "ordering" {
+			violations = append(violations, r.orderingViolations(target, path)...)
+			continue
+		}
+		if !applyFunction(r.function, target, r.options) {
+			line := m.Node.Line
+			if target != nil {
+				line = target.Line
+			}
+			violations = append(violations, r.violation(path, line, target))
+		}
+	}
+	return violations
+}
+
+// Preenche o modelo de sugestão da regra: {{path}} é o JSONPath da violação,
+// {{property}} o then.field e {{value}} o valor encontrado (vazio quando ausente)
+func renderSuggestion(template, path, field string, value *yaml.Node) string {
+	text := ""
+	if value != nil && value.Kind == yaml.ScalarNode {
+		text = value.Value
+	}
+	return strings.NewReplacer("{{path}}", path, "{{property}}", field, "{{value}}", text).Replace(template)
+}
+
+// Executa a função da regra sobre o nó; nó nulo representa valor ausente
+func applyFunction(function string, node *yaml.Node, options map[string]interface{}) bool {
+	switch function {
+	case "truthy":
+		return isTruthy(node)
+	case "falsy":
+		return !isTruthy(node)
+	case "defined":
+		return node != nil
+	case "undefined":
+		return node == nil
+	case "schema":
+		// O valor encontrado é conferido contra o schema; ausente não viola
+		return node == nil || matchesRuleSchema(node, options["schema"])
+	case "pattern":
+		if node == nil {
+			return true
+		}
+		// Listas de escalares (ex.: type: [string, "null"] em 3.1): cada item é conferido
+		if node.Kind == yaml.SequenceNode {
+			for _, item := range node.Content {
+				if item.Kind == yaml.ScalarNode && !applyFunction(function, item, options) {
+					return false
+				}
+			}
+			return true
+		}
+		if match, ok := options["match"].(string); ok {
+			re, err := regexp.Compile(match)
+			if err != nil || !re.MatchString(node.Value) {
+				return false
+			}
+		}
+		if notMatch, ok := options["notMatch"].(string); ok {
+			re, err := regexp.Compile(notMatch)
+			if err != nil || re.MatchString(node.Value) {
+				return false
+			}
+		}
+		return true
+	case "ordering":
+		req, _ := parseOrderingOptions(options)
+		_, _, _, found := req.firstOutOfOrder(req.elements(node))
+		return !found
+	}
+	// Funções desconhecidas não produzem violações
+	return true
+}
+
+// Um valor é verdadeiro quando existe e não é vazio, nulo ou false
+func isTruthy(node *yaml.Node) bool {
+	if node == nil {
+		return false
+	}
+	switch node.Kind {
+	case yaml.MappingNode, yaml.SequenceNode:
+		return len(node.Content) > 0
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			return false
+		}
+		v := strings.TrimSpace(node.Value)
+		return v != "" && v != "false" && v != "0"
+	}
+	return true
+}
+
+// Retorna o valor de uma chave em um mapping YAML
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// Conta as violações de severidade error e as demais
+func countViolations(violations []Violation) (errors, warnings int) {
+	for _, v := range violations {
+		if v.IsError() {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	return errors, warnings
+}
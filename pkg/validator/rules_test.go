@@ -0,0 +1,173 @@
+package validator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Conjuntos de regras malformados são erro de uso (código 3), com a entrada inválida
+// na mensagem
+func TestMalformedRulesets(t *testing.T) {
+	spec := mustReadFile(t, "testdata/e2e/valid/api.yaml")
+	for _, tt := range malformedRulesets {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Validate(context.Background(), spec, Options{Source: "api.yaml", Rules: []byte(tt.rules)})
+			if err == nil {
+				t.Fatal("as regras deveriam ser rejeitadas")
+			}
+			if code := rulesExitCode(err); code != exitUsage {
+				t.Errorf("código %d, esperado %d: %v", code, exitUsage, err)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("mensagem sem %q:\n%v", tt.message, err)
+			}
+		})
+	}
+}
+
+// Conjuntos de regras malformados, com o trecho esperado na mensagem de erro
+var malformedRulesets = []struct {
+	name, rules, message string
+}{
+	{"extends numérico", "extends: 5", "extends deve ser o nome de um pacote, uma URL, um caminho ou uma lista deles, encontrado número (5)"},
+	{"extends com lista na lista", "extends: [[1]]", "a entrada 1 de extends deve ser o nome de um pacote, uma URL ou um caminho, encontrado lista"},
+	{"extends com número na lista", "extends: [ofb, 2]", "a entrada 2 de extends deve ser o nome de um pacote, uma URL ou um caminho, encontrado número (2)"},
+	{"extends objeto", "extends: {ofb: all}", "encontrado objeto"},
+	{"rules em lista", "rules: [a, b]", "a seção 'rules' deve ser um objeto"},
+	{"sem rules", "description: nada", "o arquivo não possui a seção 'rules'"},
+	{"regra numérica", "rules: {a: 5}", `regra "a": esperado um objeto com given e then`},
+	{"then ausente", "rules: {a: {given: $.info}}", `regra "a": then ausente`},
+	{"severidade desconhecida", "rules: {a: {given: $.info, severity: grave, then: {field: title, function: truthy}}}", `regra "a": severity deve ser error, warning, info ou hint`},
+	{"variável não definida", "rules: {a: {description: '${OFBCI_TESTE_NAO_DEFINIDA}', given: $.info, then: {field: title, function: truthy}}}", "variáveis de ambiente não definidas: OFBCI_TESTE_NAO_DEFINIDA"},
+}
+
+// Nenhuma entrada faz a interpretação ou a compilação das regras entrar em pânico:
+// o que não é um conjunto de regras válido volta como erro (go test -fuzz FuzzParseRuleset)
+func FuzzParseRuleset(f *testing.F) {
+	for _, tt := range malformedRulesets {
+		f.Add([]byte(tt.rules))
+	}
+	f.Add([]byte("rules:\n  titulo:\n    given: $.info\n    then: {field: title, function: truthy}\n"))
+	f.Add([]byte("rules:\n  padrao:\n    given: $..properties[*]\n    then: {field: description, function: pattern, functionOptions: {match: '^[A-Z'}}\n"))
+	f.Add([]byte("extends: [ofb]\nrules: {operation-tags: off}\n"))
+
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte("openapi: 3.0.3\ninfo: {title: Contas, version: 1.0.0}\npaths: {}\n"), &root); err != nil {
+		f.Fatal(err)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Sem rede nem disco: os extends só alcançam os pacotes embarcados
+		s := flagSettings()
+		s.tracker = nil
+		s.noCache = true
+		s.offline = true
+		s.fsys = MemFS{}
+		s.root = t.TempDir()
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("pânico com %q: %v", data, r)
+			}
+		}()
+		rules, err := loadRulesData(s, data, "regras", ".")
+		if err != nil {
+			return
+		}
+		scope := newGuardScope(&root)
+		for name, rule := range rules {
+			ruleData, _ := rule.(map[string]interface{})
+			if compiled := compileRule(name, ruleData); compiled != nil {
+				if matches, ok := compiled.query(scope); ok {
+					compiled.check(matches)
+				}
+			}
+		}
+	})
+}
+
+// O cache de regras é invalidado quando muda um pacote embarcado alcançado por extends,
+// e não só o pacote ofb
+func TestRulesCacheTracksBuiltinRulesets(t *testing.T) {
+	savedDir := cacheDir
+	defer func() { cacheDir = savedDir }()
+	cacheDir = t.TempDir()
+	defer delete(builtinRulesets, "teste-cache")
+
+	dir := t.TempDir()
+	rulesFile := filepath.Join(dir, "regras.yaml")
+	if err := os.WriteFile(rulesFile, []byte("extends: teste-cache\nrules: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, severity := range []string{"warning", "error"} {
+		RegisterRuleset("teste-cache", []byte("rules:\n  titulo:\n    given: $.info\n    severity: "+severity+"\n    then: {field: title, function: truthy}\n"))
+		rules, err := loadRules(flagSettings(), rulesFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rule, _ := rules["titulo"].(map[string]interface{})
+		if rule["severity"] != severity {
+			t.Errorf("severidade %v, esperado %s (o cache guardou o pacote anterior)", rule["severity"], severity)
+		}
+	}
+}
+
+// As variáveis de ambiente são expandidas nos arquivos de regras locais, mas nunca no
+// conteúdo baixado de uma URL
+func TestRemoteRulesAreNotExpanded(t *testing.T) {
+	t.Setenv("OFBCI_TESTE_SEGREDO", "segredo")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("rules:\n  remota:\n    description: 'token ${OFBCI_TESTE_SEGREDO}'\n    given: $.info\n    then: {field: title, function: truthy}\n"))
+	}))
+	defer server.Close()
+
+	rulesFile := filepath.Join(t.TempDir(), "regras.yaml")
+	local := "extends: " + server.URL + "/regras.yaml\nrules:\n  local:\n    description: 'token ${OFBCI_TESTE_SEGREDO}'\n    given: $.info\n    then: {field: title, function: truthy}\n"
+	if err := os.WriteFile(rulesFile, []byte(local), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := flagSettings()
+	s.noCache = true
+	rules, err := loadRules(s, rulesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"local": "token segredo", "remota": "token ${OFBCI_TESTE_SEGREDO}"} {
+		rule, _ := rules[name].(map[string]interface{})
+		if rule["description"] != want {
+			t.Errorf("regra %s: description %q, esperado %q", name, rule["description"], want)
+		}
+	}
+}
+
+// Uma regra sem description é relatada pelo nome, e não com a mensagem vazia
+func TestRuleWithoutDescription(t *testing.T) {
+	rules := filepath.Join(t.TempDir(), "regras.yaml")
+	if err := os.WriteFile(rules, []byte("rules:\n  info-contato:\n    given: $.info\n    then: {field: x-contato, function: truthy}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code, out := runCommand(t, "validate", "--no-cache", "--rules", rules, filepath.Join("testdata", "e2e", "valid", "api.yaml"))
+	if want := "⚠️  [warning] Regra info-contato violada ($.info.x-contato, linha 3)\n"; code != exitOK || !strings.Contains(out, want) {
+		t.Errorf("código %d, saída sem %q:\n%s", code, want, out)
+	}
+}
+
+// Uma variável não definida no arquivo herdado por extends também é problema das
+// regras e sai com o código de uso incorreto
+func TestUndefinedEnvInExtends(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"base.yaml":   "rules:\n  titulo:\n    description: 'token ${OFBCI_TESTE_NAO_DEFINIDA}'\n    given: $.info\n    then: {field: title, function: truthy}\n",
+		"regras.yaml": "extends: base.yaml\nrules: {}\n",
+	})
+	code, out := runCommand(t, "validate", "--no-cache", "--rules", filepath.Join(dir, "regras.yaml"), filepath.Join("testdata", "e2e", "valid", "api.yaml"))
+	want := "regras inválidas em " + filepath.Join(dir, "base.yaml") + ":\n   - variáveis de ambiente não definidas: OFBCI_TESTE_NAO_DEFINIDA"
+	if code != exitUsage || !strings.Contains(out, want) {
+		t.Errorf("código %d, esperado %d com %q:\n%s", code, exitUsage, want, out)
+	}
+}
@@ -0,0 +1,249 @@
+package validator
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+var rulesCommand = &command{
+	Name:    "rules",
+	Args:    "list [--builtin] | show <nome> | convert [-o arquivo] <.spectral.yaml>",
+	Summary: "lista as regras efetivas e os pacotes e schemas embarcados",
+	Description: `'rules list' mostra as regras efetivas (nome, severidade e descrição), de
+--rules, da configuração do projeto ou do pacote ofb embarcado. Com --profile,
+a severidade é a do perfil (severities); sem ele, as regras com perfis mostram
+a severidade de cada um.
+
+'rules list --builtin' mostra os pacotes de regras e os schemas embarcados no
+binário, que podem ser usados pelo nome em --rules, em "extends" e na opção
+schema da função schema. Cada um é carregado e conferido; um recurso
+embarcado com problema faz o comando terminar com erro.
+
+'rules show <nome>' imprime o conteúdo de um pacote ou schema embarcado, para
+servir de ponto de partida de uma versão própria: um arquivo com o mesmo nome,
+informado explicitamente, tem precedência sobre o embarcado.
+
+'rules convert <.spectral.yaml>' traduz um conjunto de regras do Spectral para o
+formato próprio e imprime o resultado (ou grava em -o). O mesmo arquivo também
+pode ser usado diretamente em --rules: as severidades numéricas, given e then em
+lista e as funções casing, enumeration e length são traduzidas, e as regras com
+funções customizadas (JavaScript) ou sem equivalente ficam de fora com um aviso.
+O extends spectral:oas é ignorado; use extends: ofb no lugar.`,
+	Examples: []string{
+		programName + " rules list",
+		programName + " rules list --profile ga",
+		programName + " rules list --builtin",
+		programName + " rules show ofb-error > ofb-error",
+		programName + " rules convert -o regras.yaml .spectral.yaml",
+	},
+	Flags: func(fs *flag.FlagSet) { registerRulesListFlags(fs) },
+	Run:   runRules,
+}
+
+func registerRulesListFlags(fs *flag.FlagSet) (*string, *bool) {
+	rulesFile := fs.String("rules", "", "arquivo de regras ou nome de pacote embarcado (padrão: o da configuração ou ofb)")
+	builtin := fs.Bool("builtin", false, "lista os pacotes de regras e os schemas embarcados")
+	registerProfileFlag(fs)
+	registerEnvFlag(fs)
+	registerCacheFlags(fs)
+	return rulesFile, builtin
+}
+
+// Subcomando rules: list, show ou convert
+func runRules(c *command, args []string) int {
+	if len(args) == 0 {
+		return c.usageError("esperada uma ação: list, show ou convert")
+	}
+	switch args[0] {
+	case "list":
+		return runRulesList(c, args[1:])
+	case "show":
+		return runRulesShow(c, args[1:])
+	case "convert":
+		return runRulesConvert(c, args[1:])
+	}
+	return c.usageError("ação %q inválida para rules (use list, show ou convert)%s", args[0], suggestionSuffix(args[0], []string{"list", "show", "convert"}))
+}
+
+func runRulesList(c *command, args []string) int {
+	fs := c.newFlagSet()
+	rulesFile, builtin := registerRulesListFlags(fs)
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 0 {
+		return c.usageError("argumentos inesperados: %s", strings.Join(fs.Args(), " "))
+	}
+	if *builtin {
+		if *rulesFile != "" {
+			return c.usageError("--builtin não pode ser usado com --rules")
+		}
+		return listBuiltinAssets()
+	}
+
+	rules, source, err := loadExplainRules(*rulesFile)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao carregar as regras:", err)
+		return rulesExitCode(err)
+	}
+	// Sem especificação, auto não escolhe perfil: a lista mostra as severidades de cada um
+	profile := ""
+	if severityProfile != "auto" {
+		if profile, err = specProfile(severityProfile, nil, rules); err != nil {
+			return c.usageError("%v", err)
+		}
+		rules = applyProfile(rules, profile)
+	}
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(stdout, "📘 %d regras (%s)%s\n", len(names), source, profileSuffix(profile))
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		ruleData, _ := rules[name].(map[string]interface{})
+		severity, _ := ruleData["severity"].(string)
+		if severities, ok := ruleData["severities"].(map[string]interface{}); ok && profile == "" {
+			severity += " (" + profileSeverityList(severities) + ")"
+		}
+		description, _ := ruleData["description"].(string)
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name, severity, firstLine(description))
+	}
+	w.Flush()
+	return exitOK
+}
+
+// Função para listar os recursos embarcados, carregando e conferindo cada um: os
+// pacotes passam pela mesma verificação de --rules e os schemas precisam ser objetos
+// JSON válidos. Um recurso com problema indica um binário gerado com erro.
+func listBuiltinAssets() int {
+	failed := false
+	status := func(err error) string {
+		if err != nil {
+			failed = true
+			return "❌ " + firstLine(err.Error())
+		}
+		return "✅"
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(stdout, "📦 Pacotes de regras embarcados")
+	names := make([]string, 0, len(builtinRulesets))
+	for name := range builtinRulesets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rules, err := loadRulesData(flagSettings(), builtinRulesets[name], name, ".")
+		fmt.Fprintf(w, "  %s\t%d regras\t%s\n", name, len(rules), status(err))
+	}
+	w.Flush()
+
+	fmt.Fprintln(stdout, "📦 Schemas embarcados")
+	for _, name := range builtinSchemaNames() {
+		data, _ := builtinSchemaData(name)
+		err := checkSchemaAsset(data)
+		if err == nil {
+			_, err = ruleSchema(name)
+		}
+		fmt.Fprintf(w, "  %s\t%d bytes\t%s\n", name, len(data), status(err))
+	}
+	w.Flush()
+
+	if failed {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// Os schemas embarcados são JSON; o YAML aceitaria um arquivo JSON truncado ou com
+// vírgulas sobrando, que outras ferramentas recusariam
+func checkSchemaAsset(data []byte) error {
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return fmt.Errorf("JSON inválido: %v", err)
+	}
+	return nil
+}
+
+func runRulesShow(c *command, args []string) int {
+	fs := c.newFlagSet()
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		return c.usageError("esperado um argumento (nome do pacote ou schema embarcado), recebidos %d", fs.NArg())
+	}
+	name := fs.Arg(0)
+	data, ok := builtinRulesets[name]
+	if !ok {
+		data, ok = builtinSchemaData(name)
+	}
+	if !ok {
+		fmt.Fprintf(stdout, "❌ %q não é um pacote nem um schema embarcado (veja rules list --builtin).\n", name)
+		return exitFailure
+	}
+	stdout.Write(data)
+	return exitOK
+}
+
+// Função para traduzir um arquivo do Spectral para o formato próprio, a mesma tradução
+// feita ao usar o arquivo em --rules. As regras que ficaram de fora são listadas na
+// saída de erro; o arquivo gerado pode ser ajustado à mão a partir daí.
+func runRulesConvert(c *command, args []string) int {
+	fs := c.newFlagSet()
+	output := fs.String("o", "", "arquivo de saída (padrão: a saída padrão)")
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		return c.usageError("esperado um argumento (arquivo do Spectral), recebidos %d", fs.NArg())
+	}
+	source := fs.Arg(0)
+	data, err := readFile(source)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao ler o arquivo de regras:", err)
+		return exitFailure
+	}
+	var ruleset map[string]interface{}
+	if err := yaml.Unmarshal(data, &ruleset); err != nil {
+		fmt.Fprintln(stdout, "❌", yamlSyntaxError(data, source, err))
+		return exitFailure
+	}
+	if !isSpectralRuleset(ruleset, source) {
+		fmt.Fprintf(stderr, "ℹ️ %s não parece um arquivo do Spectral; a tradução foi feita mesmo assim.\n", source)
+	}
+	converted, skipped := convertSpectralRuleset(ruleset)
+	out, err := marshalYAML(converted)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao gerar as regras:", err)
+		return exitFailure
+	}
+	out = append([]byte(fmt.Sprintf("# Regras traduzidas de %s (%s rules convert)\n", filepath.Base(source), programName)), out...)
+
+	if *output == "" {
+		stdout.Write(out)
+	} else if err := writeFileAtomic(*output, out); err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao gravar as regras:", err)
+		return exitFailure
+	} else {
+		rules, _ := converted["rules"].(map[string]interface{})
+		fmt.Fprintf(stdout, "✅ %d regras traduzidas em %s\n", len(rules), *output)
+	}
+	for _, skip := range skipped {
+		if skip.rule == "" {
+			fmt.Fprintf(stderr, "⚠️ %s\n", skip.reason)
+			continue
+		}
+		fmt.Fprintf(stderr, "⚠️ Regra %q não traduzida: %s\n", skip.rule, skip.reason)
+	}
+	return exitOK
+}
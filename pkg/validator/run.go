@@ -0,0 +1,148 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Tempo máximo padrão de uma execução completa
+const defaultTimeout = 5 * time.Minute
+
+// Cria o contexto da execução, cancelado no tempo limite ou em SIGINT/SIGTERM
+func newRunContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	if timeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// Acompanha a fase e o arquivo em processamento e os resultados parciais,
+// para que um cancelamento possa dizer onde a execução parou. A CLI usa o do
+// processo (tracker), lido no diagnóstico de cancelamentos e erros internos; as
+// chamadas da API não têm um, e os métodos de um tracker nulo não fazem nada.
+type runTracker struct {
+	mu      sync.Mutex
+	phase   string
+	file    string
+	rules   string // arquivo de regras em uso, para o pacote de diagnóstico
+	partial []Violation
+}
+
+var tracker = &runTracker{}
+
+func (t *runTracker) enter(phase, file string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.phase, t.file = phase, file
+	memProfile.enter(phase, file)
+}
+
+func (t *runTracker) setRules(file string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rules = file
+}
+
+// Esquece a fase e os resultados parciais da execução anterior (Run)
+func (t *runTracker) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.phase, t.file, t.rules, t.partial = "", "", "", nil
+}
+
+func (t *runTracker) addPartial(violations ...Violation) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.partial = append(t.partial, violations...)
+}
+
+// Chave do contexto com as etapas em andamento de uma requisição (withPhaseGroup)
+type phaseGroupKey struct{}
+
+// Contexto que registra as etapas de runPhase no grupo retornado, inclusive as que
+// continuam depois de um cancelamento; o serve só libera a vaga da requisição quando o
+// grupo termina, para que as etapas abandonadas também contem em --max-concurrent
+func withPhaseGroup(ctx context.Context) (context.Context, *sync.WaitGroup) {
+	group := &sync.WaitGroup{}
+	return context.WithValue(ctx, phaseGroupKey{}, group), group
+}
+
+// Executa uma etapa que não aceita cancelamento (chamadas ao libopenapi),
+// retornando assim que o contexto for cancelado. A etapa abandonada termina sozinha,
+// com a própria configuração (runSettings); um contexto cancelado antes do início não
+// chega a executá-la.
+func runPhase(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	group, _ := ctx.Value(phaseGroupKey{}).(*sync.WaitGroup)
+	if group != nil {
+		group.Add(1)
+	}
+	done := make(chan error, 1)
+	panicked := make(chan phasePanic, 1)
+	go func() {
+		if group != nil {
+			defer group.Done()
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				panicked <- phasePanic{value: r, stack: debug.Stack()}
+			}
+		}()
+		done <- fn()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case p := <-panicked:
+		// Relançado aqui para que o tratamento de main o converta em erro interno
+		panic(p)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Indica se o erro vem do cancelamento da execução
+func isCancellation(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
+}
+
+// Imprime o diagnóstico de uma execução cancelada: fase, arquivo e resultados parciais
+func reportCancellation(err error, timeout time.Duration) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	reason := "interrompida pelo usuário"
+	if errors.Is(err, context.DeadlineExceeded) {
+		reason = fmt.Sprintf("tempo limite de %s excedido", timeout)
+	}
+	fmt.Fprintf(stdout, "⏱️  Execução cancelada (%s) durante a fase %q do arquivo %s.\n", reason, tracker.phase, tracker.file)
+	if len(tracker.partial) > 0 {
+		fmt.Fprintf(stdout, "Resultados parciais (%d violações até o momento):\n", len(tracker.partial))
+		for _, v := range tracker.partial {
+			printViolation(v)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Com o tempo limite esgotado, validate e resolve param e dizem a fase e o arquivo em
+// processamento
+func TestTimeoutReportsPhase(t *testing.T) {
+	spec := filepath.Join("testdata", "e2e", "violations", "api.yaml")
+	for _, args := range [][]string{{"validate"}, {"resolve", "-o", filepath.Join(t.TempDir(), "saida.yaml")}} {
+		t.Run(args[0], func(t *testing.T) {
+			code, out := runCommand(t, append(args, "--no-cache", "--timeout", "1ns", spec)...)
+			want := `⏱️  Execução cancelada (tempo limite de 1ns excedido) durante a fase "index" do arquivo ` + spec + "."
+			if code != exitFailure || !strings.Contains(out, want) {
+				t.Errorf("código %d, saída sem %q:\n%s", code, want, out)
+			}
+		})
+	}
+}
+
+// Uma etapa que não termina é abandonada quando o contexto expira
+func TestRunPhaseDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	block := make(chan struct{})
+	defer close(block)
+	if err := runPhase(ctx, func() error { <-block; return nil }); err != context.DeadlineExceeded {
+		t.Errorf("erro %v, esperado context.DeadlineExceeded", err)
+	}
+}
+
+// O cancelamento pelo usuário (Ctrl-C) mostra as violações encontradas até então
+func TestReportCancellationPartial(t *testing.T) {
+	var out bytes.Buffer
+	saved := stdout
+	stdout = &out
+	defer func() {
+		stdout = saved
+		tracker.reset()
+	}()
+	tracker.enter("rules", "api.yaml")
+	tracker.addPartial(Violation{RuleID: "only-https", Severity: "error", Message: "URL sem HTTPS", JSONPath: "$.servers[0].url", Line: 6})
+
+	reportCancellation(context.Canceled, time.Minute)
+	for _, want := range []string{
+		`⏱️  Execução cancelada (interrompida pelo usuário) durante a fase "rules" do arquivo api.yaml.`,
+		"Resultados parciais (1 violações até o momento):",
+		"[error] URL sem HTTPS ($.servers[0].url, linha 6)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("saída sem %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,519 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Limites do gerador de exemplos: propriedades opcionais só até optionalSampleDepth
+// níveis de objeto, listas com no máximo maxSampleItems itens e árvores com no máximo
+// maxSampleDepth níveis
+const (
+	optionalSampleDepth = 2
+	maxSampleItems      = 3
+	maxSampleDepth      = 16
+	maxPatternLength    = 256
+)
+
+// Valores de exemplo para os formatos de texto mais usados
+var formatSamples = map[string]string{
+	"date":      "2024-01-01",
+	"date-time": "2024-01-01T00:00:00Z",
+	"time":      "00:00:00",
+	"email":     "usuario@exemplo.com.br",
+	"uuid":      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"uri":       "https://exemplo.com.br",
+	"url":       "https://exemplo.com.br",
+	"hostname":  "exemplo.com.br",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"byte":      "c3RyaW5n",
+}
+
+// Gerador de valores de exemplo a partir de Schema Objects. Exemplos declarados
+// (example, examples, default, const, enum) têm prioridade; na falta deles o valor é
+// montado pelo tipo, respeitando obrigatórias, formatos, pattern e limites. Para
+// schemas recursivos, o $ref que fecha o ciclo não é seguido: a propriedade é omitida
+// e a lista fica vazia.
+type sampleGenerator struct {
+	validator *schemaValidator
+	request   bool                // exemplo de requisição: readOnly fica de fora (writeOnly nas respostas)
+	active    map[*yaml.Node]bool // schemas de $ref no caminho atual, para cortar ciclos
+	skipped   map[string][]string // obrigatórias omitidas (readOnly/writeOnly ou ciclo), por local
+	generated bool                // algum valor foi gerado a partir do schema, sem exemplo declarado
+	warnings  []string
+}
+
+func newSampleGenerator(validator *schemaValidator, request bool) *sampleGenerator {
+	return &sampleGenerator{validator: validator, request: request, active: map[*yaml.Node]bool{}, skipped: map[string][]string{}}
+}
+
+// Gera o valor de exemplo do schema e o confere contra ele; as divergências
+// (ex.: pattern que o gerador não cobre) viram avisos
+func (g *sampleGenerator) sample(schema *yaml.Node, label string) *yaml.Node {
+	value, ok := g.generate(schema, "$", 0)
+	if !ok {
+		g.warnings = append(g.warnings, label+": o schema é recursivo em todos os caminhos e nenhum exemplo foi gerado")
+		return nil
+	}
+	for _, e := range g.validator.validate(schema, value, "$") {
+		if e.keyword == "required" && g.omitted(e) {
+			continue
+		}
+		g.warnings = append(g.warnings, fmt.Sprintf("%s: o exemplo não corresponde ao schema em %s: %s", label, e.at, e.message))
+	}
+	return value
+}
+
+// Indica se a falha de required é de uma propriedade omitida de propósito
+func (g *sampleGenerator) omitted(e schemaError) bool {
+	for _, name := range g.skipped[e.at] {
+		if strings.HasSuffix(e.message, " "+name+" está ausente") {
+			return true
+		}
+	}
+	return false
+}
+
+// Valor de exemplo do schema no local at, com depth níveis de objeto e lista acima
+// dele; ok falso quando o ciclo foi cortado
+func (g *sampleGenerator) generate(schema *yaml.Node, at string, depth int) (*yaml.Node, bool) {
+	if schema == nil {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, true
+	}
+	// Schemas booleanos (3.1): true aceita qualquer valor, false nenhum
+	if schema.Kind == yaml.ScalarNode {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, schema.Value != "false"
+	}
+	if schema.Kind != yaml.MappingNode || depth > maxSampleDepth {
+		return nil, false
+	}
+	if ref := mappingValue(schema, "$ref"); ref != nil {
+		file, pointer := splitRef(ref.Value)
+		if file != "" {
+			return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, true
+		}
+		target, err := resolvePointer(g.validator.root, pointer)
+		if err != nil || g.active[target] {
+			return nil, false
+		}
+		g.active[target] = true
+		defer delete(g.active, target)
+		return g.generate(target, at, depth)
+	}
+
+	if value := declaredSample(schema); value != nil {
+		return deepCopyNode(value), true
+	}
+	if allOf := mappingValue(schema, "allOf"); allOf != nil && allOf.Kind == yaml.SequenceNode {
+		return g.generate(g.mergeAllOf(schema), at, depth)
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		branches := mappingValue(schema, key)
+		if branches == nil || branches.Kind != yaml.SequenceNode || len(branches.Content) == 0 {
+			continue
+		}
+		// A primeira alternativa cujo exemplo satisfaz o schema inteiro; senão, a primeira que gera algo
+		var first *yaml.Node
+		for _, branch := range branches.Content {
+			value, ok := g.generate(branch, at, depth)
+			if !ok {
+				continue
+			}
+			if len(g.validator.validate(schema, value, at)) == 0 {
+				return value, true
+			}
+			if first == nil {
+				first = value
+			}
+		}
+		if first != nil {
+			return first, true
+		}
+		return nil, false
+	}
+
+	g.generated = true
+	types, nullable := schemaTypes(schema, g.validator.v31)
+	kind := inferSampleType(schema)
+	if len(types) > 0 {
+		kind = types[0]
+	} else if nullable && kind == "" {
+		kind = "null"
+	}
+	switch kind {
+	case "object", "":
+		return g.object(schema, at, depth)
+	case "array":
+		return g.array(schema, at, depth)
+	case "string":
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: stringSample(schema)}, true
+	case "integer", "number":
+		value, tag := numberSample(schema, kind == "integer")
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}, true
+	case "boolean":
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"}, true
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, true
+}
+
+// Exemplo declarado no próprio schema: example, examples[0] (3.1), default, const ou o primeiro enum
+func declaredSample(schema *yaml.Node) *yaml.Node {
+	if example := mappingValue(schema, "example"); example != nil {
+		return example
+	}
+	if examples := mappingValue(schema, "examples"); examples != nil && examples.Kind == yaml.SequenceNode && len(examples.Content) > 0 {
+		return examples.Content[0]
+	}
+	for _, key := range []string{"default", "const"} {
+		if value := mappingValue(schema, key); value != nil {
+			return value
+		}
+	}
+	if enum := mappingValue(schema, "enum"); enum != nil && enum.Kind == yaml.SequenceNode && len(enum.Content) > 0 {
+		return enum.Content[0]
+	}
+	return nil
+}
+
+// Tipo de um schema sem type, pelas palavras-chave presentes
+func inferSampleType(schema *yaml.Node) string {
+	switch {
+	case mappingValue(schema, "properties") != nil || mappingValue(schema, "additionalProperties") != nil || mappingValue(schema, "required") != nil:
+		return "object"
+	case mappingValue(schema, "items") != nil:
+		return "array"
+	case mappingValue(schema, "pattern") != nil || mappingValue(schema, "format") != nil || mappingValue(schema, "minLength") != nil || mappingValue(schema, "maxLength") != nil:
+		return "string"
+	case mappingValue(schema, "minimum") != nil || mappingValue(schema, "maximum") != nil || mappingValue(schema, "multipleOf") != nil:
+		return "number"
+	}
+	return ""
+}
+
+// Combina as partes de um allOf em um único schema: properties somadas, required
+// unidos e, nas demais palavras-chave, a última parte prevalece
+func (g *sampleGenerator) mergeAllOf(schema *yaml.Node) *yaml.Node {
+	merged := newMapping(schema)
+	properties := newMapping(schema)
+	required := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	seen := map[string]bool{}
+	for _, part := range flattenAllOf(g.validator, schema, "", 0) {
+		for i := 0; i+1 < len(part.schema.Content); i += 2 {
+			key, value := part.schema.Content[i], part.schema.Content[i+1]
+			switch key.Value {
+			case "allOf", "$ref":
+			case "properties":
+				for j := 0; j+1 < len(value.Content); j += 2 {
+					setValue(properties, value.Content[j], value.Content[j+1])
+				}
+			case "required":
+				for _, name := range value.Content {
+					if !seen[name.Value] {
+						seen[name.Value] = true
+						required.Content = append(required.Content, name)
+					}
+				}
+			default:
+				setValue(merged, key, value)
+			}
+		}
+	}
+	if len(properties.Content) > 0 {
+		setValue(merged, keyLike(schema, "properties"), properties)
+	}
+	if len(required.Content) > 0 {
+		setValue(merged, keyLike(schema, "required"), required)
+	}
+	return merged
+}
+
+func (g *sampleGenerator) object(schema *yaml.Node, at string, depth int) (*yaml.Node, bool) {
+	value := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	required := map[string]bool{}
+	if list := mappingValue(schema, "required"); list != nil && list.Kind == yaml.SequenceNode {
+		for _, name := range list.Content {
+			required[name.Value] = true
+		}
+	}
+	properties := mappingValue(schema, "properties")
+	if properties != nil && properties.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(properties.Content); i += 2 {
+			name, property := properties.Content[i].Value, properties.Content[i+1]
+			if !required[name] && depth >= optionalSampleDepth {
+				continue
+			}
+			if g.hidden(property) {
+				if required[name] {
+					g.skipped[at] = append(g.skipped[at], name)
+				}
+				continue
+			}
+			item, ok := g.generate(property, joinPath(at, name), depth+1)
+			if !ok {
+				if required[name] {
+					g.skipped[at] = append(g.skipped[at], name)
+				}
+				continue
+			}
+			value.Content = append(value.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, item)
+		}
+	}
+
+	// Obrigatórias sem definição e minProperties são preenchidas por additionalProperties
+	additional := mappingValue(schema, "additionalProperties")
+	if additional == nil || additional.Kind != yaml.MappingNode {
+		return value, true
+	}
+	add := func(name string) bool {
+		item, ok := g.generate(additional, joinPath(at, name), depth+1)
+		if ok {
+			value.Content = append(value.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, item)
+		}
+		return ok
+	}
+	for name := range required {
+		if mappingValue(value, name) == nil && mappingValue(properties, name) == nil {
+			add(name)
+		}
+	}
+	minimum, _ := schemaNumber(schema, "minProperties")
+	for n := 1; float64(len(value.Content)/2) < minimum && n <= int(minimum); n++ {
+		if name := fmt.Sprintf("chave%d", n); mappingValue(value, name) == nil && !add(name) {
+			break
+		}
+	}
+	return value, true
+}
+
+// Propriedades que não aparecem no exemplo: readOnly na requisição e writeOnly na resposta
+func (g *sampleGenerator) hidden(property *yaml.Node) bool {
+	key := "writeOnly"
+	if g.request {
+		key = "readOnly"
+	}
+	flag := mappingValue(property, key)
+	return flag != nil && flag.Value == "true"
+}
+
+func (g *sampleGenerator) array(schema *yaml.Node, at string, depth int) (*yaml.Node, bool) {
+	value := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	count := 1
+	if n, ok := schemaNumber(schema, "minItems"); ok && n > 1 {
+		count = int(math.Min(n, maxSampleItems))
+	}
+	if n, ok := schemaNumber(schema, "maxItems"); ok && float64(count) > n {
+		count = int(n)
+	}
+	items := mappingValue(schema, "items")
+	unique := mappingValue(schema, "uniqueItems")
+	for i := 0; i < count; i++ {
+		item, ok := g.generate(items, fmt.Sprintf("%s[%d]", at, i), depth+1)
+		if !ok {
+			// Ciclo: a lista vazia encerra a recursão
+			return value, true
+		}
+		if i > 0 && unique != nil && unique.Value == "true" {
+			distinctSample(item, i)
+		}
+		value.Content = append(value.Content, item)
+	}
+	return value, true
+}
+
+// Diferencia o i-ésimo item escalar dos anteriores, para uniqueItems
+func distinctSample(item *yaml.Node, i int) {
+	if item.Kind != yaml.ScalarNode {
+		return
+	}
+	switch item.Tag {
+	case "!!str":
+		item.Value += strconv.Itoa(i + 1)
+	case "!!int", "!!float":
+		if n, err := strconv.ParseFloat(item.Value, 64); err == nil {
+			item.Value = strconv.FormatFloat(n+float64(i), 'f', -1, 64)
+		}
+	}
+}
+
+// Texto de exemplo: pelo pattern, pelo formato ou um valor neutro ajustado a minLength/maxLength
+func stringSample(schema *yaml.Node) string {
+	minLength, maxLength := -1, -1
+	if n, ok := schemaNumber(schema, "minLength"); ok {
+		minLength = int(n)
+	}
+	if n, ok := schemaNumber(schema, "maxLength"); ok {
+		maxLength = int(n)
+	}
+	if p := mappingValue(schema, "pattern"); p != nil {
+		if s, ok := patternSample(p.Value, minLength, maxLength); ok {
+			return s
+		}
+	}
+	if f := mappingValue(schema, "format"); f != nil {
+		if s, ok := formatSamples[f.Value]; ok {
+			return s
+		}
+	}
+	s := "string"
+	if minLength > len(s) {
+		s += strings.Repeat("a", minLength-len(s))
+	}
+	if maxLength >= 0 && len(s) > maxLength {
+		s = s[:maxLength]
+	}
+	return s
+}
+
+// Texto que casa com a expressão regular, montado pela árvore da expressão com
+// repetições limitadas. As repetições começam no mínimo e crescem até que o texto
+// respeite minLength/maxLength; ok falso quando a expressão não é suportada por Go
+// (ex.: lookahead) ou nenhuma tentativa casa.
+func patternSample(pattern string, minLength, maxLength int) (string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	matcher, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", false
+	}
+	for _, extra := range []int{0, 1, 2, 4, 8, 16, 32, 64} {
+		var b strings.Builder
+		writePatternSample(&b, re, extra)
+		s := b.String()
+		length := len([]rune(s))
+		if length < minLength || maxLength >= 0 && length > maxLength {
+			continue
+		}
+		if matcher.MatchString(s) {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+func writePatternSample(b *strings.Builder, re *syntax.Regexp, extra int) {
+	if b.Len() > maxPatternLength {
+		return
+	}
+	repeat := func(sub *syntax.Regexp, min, max int) {
+		n := min + extra
+		if max >= 0 && n > max {
+			n = max
+		}
+		for i := 0; i < n; i++ {
+			writePatternSample(b, sub, extra)
+		}
+	}
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		b.WriteRune(classSample(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteByte('a')
+	case syntax.OpCapture:
+		writePatternSample(b, re.Sub[0], extra)
+	case syntax.OpStar:
+		repeat(re.Sub[0], 0, -1)
+	case syntax.OpPlus:
+		repeat(re.Sub[0], 1, -1)
+	case syntax.OpQuest:
+		repeat(re.Sub[0], 0, 1)
+	case syntax.OpRepeat:
+		repeat(re.Sub[0], re.Min, re.Max)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			writePatternSample(b, sub, extra)
+		}
+	case syntax.OpAlternate:
+		writePatternSample(b, re.Sub[0], extra)
+	}
+	// Âncoras, fronteiras de palavra e o vazio não escrevem nada
+}
+
+// Caractere de uma classe, preferindo letras e dígitos comuns
+func classSample(ranges []rune) rune {
+	contains := func(r rune) bool {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if r >= ranges[i] && r <= ranges[i+1] {
+				return true
+			}
+		}
+		return false
+	}
+	for _, r := range "a0A" {
+		if contains(r) {
+			return r
+		}
+	}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1] && r < ranges[i]+256; r++ {
+			if r > ' ' && unicode.IsPrint(r) {
+				return r
+			}
+		}
+	}
+	if len(ranges) > 0 {
+		return ranges[0]
+	}
+	return 'a'
+}
+
+// Número de exemplo dentro de minimum/maximum (inclusive os exclusivos de 3.0 e 3.1)
+// e múltiplo de multipleOf
+func numberSample(schema *yaml.Node, integer bool) (string, string) {
+	step := 1.0
+	if !integer {
+		step = 0.5
+	}
+	low, high := math.Inf(-1), math.Inf(1)
+	if n, ok := schemaNumber(schema, "minimum"); ok {
+		low = n
+		if e := mappingValue(schema, "exclusiveMinimum"); e != nil && e.Value == "true" {
+			low += step
+		}
+	}
+	if n, ok := schemaNumber(schema, "exclusiveMinimum"); ok {
+		low = math.Max(low, n+step)
+	}
+	if n, ok := schemaNumber(schema, "maximum"); ok {
+		high = n
+		if e := mappingValue(schema, "exclusiveMaximum"); e != nil && e.Value == "true" {
+			high -= step
+		}
+	}
+	if n, ok := schemaNumber(schema, "exclusiveMaximum"); ok {
+		high = math.Min(high, n-step)
+	}
+
+	value := 0.0
+	switch {
+	case !math.IsInf(low, -1):
+		value = low
+	case !math.IsInf(high, 1) && high < 0:
+		value = high
+	}
+	if m, ok := schemaNumber(schema, "multipleOf"); ok && m > 0 {
+		value = math.Ceil(value/m) * m
+		if value > high {
+			value = math.Floor(high/m) * m
+		}
+	}
+	if integer {
+		value = math.Ceil(value)
+		return strconv.FormatFloat(value, 'f', -1, 64), "!!int"
+	}
+	if value == math.Trunc(value) {
+		return strconv.FormatFloat(value, 'f', 1, 64), "!!float"
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64), "!!float"
+}
@@ -0,0 +1,131 @@
+package validator
+
+import (
+	"fmt"
+)
+
+// Relatório SARIF 2.1.0 (--format sarif), o formato que o code scanning do GitHub
+// mostra junto das linhas do pull request
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+}
+
+type sarifLocation struct {
+	ID               int                    `json:"id,omitempty"`
+	PhysicalLocation sarifPhysical          `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+	Message          *sarifMessage          `json:"message,omitempty"`
+}
+
+type sarifPhysical struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           *sarifRegion  `json:"region,omitempty"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+// Nível SARIF para a severidade da violação
+var sarifLevels = map[string]string{"error": "error", "warning": "warning", "info": "note", "hint": "note"}
+
+// Função para montar o relatório SARIF das validações: um resultado por violação,
+// com a sugestão da regra como local relacionado, que o GitHub mostra junto do alerta
+func sarifReport(reports []*validationReport) sarifLog {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: programName, InformationURI: "https://github.com/OpenBanking-Brasil/OFB-CI-CD", Rules: []sarifRule{}}},
+		Results: []sarifResult{},
+	}
+	seen := map[string]bool{}
+	for _, report := range reports {
+		for _, v := range report.Violations {
+			ruleID := v.RuleID
+			if ruleID == "" {
+				ruleID = "validation"
+			}
+			if !seen[ruleID] {
+				seen[ruleID] = true
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: ruleID, ShortDescription: sarifMessage{Text: v.Message}})
+			}
+			file := report.File
+			if v.File != "" {
+				file = reportPath(v.File)
+			}
+			location := sarifLocation{PhysicalLocation: sarifPhysical{ArtifactLocation: sarifArtifact{URI: file}}}
+			if v.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: v.Line, StartColumn: v.Column}
+			}
+			if v.JSONPath != "" {
+				location.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: v.JSONPath}}
+			}
+			level := sarifLevels[v.Severity]
+			if level == "" {
+				level = "warning"
+			}
+			result := sarifResult{RuleID: ruleID, Level: level, Message: sarifMessage{Text: v.Message}, Locations: []sarifLocation{location}}
+			if v.Suggestion != "" {
+				result.Message.Text += "\nSugestão: " + v.Suggestion
+				related := location
+				related.ID = 1
+				related.LogicalLocations = nil
+				related.Message = &sarifMessage{Text: "Sugestão: " + v.Suggestion}
+				result.RelatedLocations = []sarifLocation{related}
+			}
+			run.Results = append(run.Results, result)
+		}
+	}
+	return sarifLog{Schema: "https://json.schemastore.org/sarif-2.1.0.json", Version: "2.1.0", Runs: []sarifRun{run}}
+}
+
+// Imprime o relatório SARIF das validações na saída padrão
+func printSARIF(reports []*validationReport) error {
+	encoder := newJSONEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(sarifReport(reports)); err != nil {
+		return fmt.Errorf("erro ao gerar relatório SARIF: %v", err)
+	}
+	return nil
+}
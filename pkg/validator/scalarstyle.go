@@ -0,0 +1,278 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// O yaml.v3 perde duas formas de escrever um escalar que as especificações usam muito:
+// um texto dobrado (>) sai com cada parágrafo numa linha só, sem as quebras de linha
+// da fonte, e um texto simples com ":" dentro de um mapping ou lista em fluxo
+// ({tokenUrl: https://...}) sai entre aspas, embora o YAML o aceite como está. Para
+// que a serialização não mude linhas que nada alterou, esses escalares são trocados
+// por uma marca antes da serialização e, no texto gerado, a marca volta a ser o
+// escalar como estava na fonte. Cada troca só é feita quando o texto, lido de novo,
+// dá o mesmo valor.
+
+// Prefixo das marcas; a troca não é feita quando algum valor do nó já o contém
+const scalarMarkPrefix = "ofbciscalar"
+
+// Escalar dobrado como escrito na fonte: o indicador (>, >- ou >+) e as linhas do
+// conteúdo sem o recuo, vazias nas linhas em branco
+type foldedScalar struct {
+	header string
+	lines  []string
+}
+
+// Escalares dobrados dos documentos, pelo nó
+type scalarSources map[*yaml.Node]*foldedScalar
+
+// Função para registrar o texto original dos escalares dobrados da árvore; load lê a
+// fonte em que os nós foram escritos e só é chamada quando a árvore tem algum
+func (sources scalarSources) collect(root *yaml.Node, load func() ([]byte, bool)) {
+	var folded []*yaml.Node
+	seen := map[*yaml.Node]bool{}
+	var visit func(n *yaml.Node)
+	visit = func(n *yaml.Node) {
+		if n == nil || seen[n] {
+			return
+		}
+		seen[n] = true
+		if n.Kind == yaml.ScalarNode && n.Style&yaml.FoldedStyle != 0 && sources[n] == nil {
+			folded = append(folded, n)
+		}
+		for _, child := range n.Content {
+			visit(child)
+		}
+	}
+	visit(root)
+	if len(folded) == 0 {
+		return
+	}
+	data, ok := load()
+	if !ok {
+		return
+	}
+	lines := strings.Split(strings.TrimSuffix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n"), "\n")
+	for _, n := range folded {
+		if f := foldedSource(lines, n); f != nil {
+			sources[n] = f
+		}
+	}
+}
+
+// Escalares dobrados de um documento lido de data
+func foldedScalarsOf(data []byte, root *yaml.Node) scalarSources {
+	sources := scalarSources{}
+	sources.collect(root, func() ([]byte, bool) { return data, true })
+	return sources
+}
+
+// Texto original do escalar dobrado na linha e coluna do nó; nulo quando o texto não
+// pode ser reaproveitado (recuo explícito, tabulações, comentários) ou não reproduz o valor
+func foldedSource(lines []string, n *yaml.Node) *foldedScalar {
+	if n.Line < 1 || n.Line > len(lines) || n.HeadComment != "" || n.LineComment != "" || n.FootComment != "" {
+		return nil
+	}
+	line := []rune(lines[n.Line-1])
+	if n.Column < 1 || n.Column > len(line) || line[n.Column-1] != '>' {
+		return nil
+	}
+	header := strings.Fields(string(line[n.Column-1:]))[0]
+	if header != ">" && header != ">-" && header != ">+" {
+		return nil
+	}
+
+	f := &foldedScalar{header: header}
+	indent := -1
+	for _, text := range lines[n.Line:] {
+		if strings.TrimLeft(text, " ") == "" {
+			f.lines = append(f.lines, "")
+			continue
+		}
+		spaces := len(text) - len(strings.TrimLeft(text, " "))
+		if text[spaces] == '\t' && indent < 0 {
+			return nil
+		}
+		if indent < 0 {
+			indent = spaces
+		}
+		if spaces < indent {
+			break
+		}
+		f.lines = append(f.lines, text[indent:])
+	}
+	if header != ">+" {
+		for len(f.lines) > 0 && f.lines[len(f.lines)-1] == "" {
+			f.lines = f.lines[:len(f.lines)-1]
+		}
+	}
+	if indent < 0 || len(f.lines) == 0 {
+		return nil
+	}
+
+	var check struct {
+		Value string `yaml:"v"`
+	}
+	if err := yaml.Unmarshal([]byte("v: "+f.render("  ")+"\n"), &check); err != nil || check.Value != n.Value {
+		return nil
+	}
+	return f
+}
+
+// Escalar com o conteúdo recuado por indent, sem a quebra de linha final
+func (f *foldedScalar) render(indent string) string {
+	var b strings.Builder
+	b.WriteString(f.header)
+	for _, line := range f.lines {
+		b.WriteByte('\n')
+		if line != "" {
+			b.WriteString(indent + line)
+		}
+	}
+	return b.String()
+}
+
+// Indica se o texto pode ser escrito sem aspas como valor de um mapping em fluxo
+func plainInFlow(value string) bool {
+	var check yaml.Node
+	if strings.ContainsAny(value, "\n\r") || yaml.Unmarshal([]byte("{v: "+value+"}"), &check) != nil || len(check.Content) == 0 {
+		return false
+	}
+	mapping := check.Content[0]
+	if mapping.Kind != yaml.MappingNode || len(mapping.Content) != 2 {
+		return false
+	}
+	v := mapping.Content[1]
+	return v.Kind == yaml.ScalarNode && v.Style == 0 && v.Tag == "!!str" && v.Value == value
+}
+
+// Função para trocar pelas marcas os escalares que o yaml.v3 escreveria de outro modo:
+// os dobrados com texto original em sources e os simples com ":" em coleções em
+// fluxo. Retorna a função que restaura o nó e a que troca as marcas no texto gerado
+// (indent é a indentação da serialização); nulas quando não há o que trocar.
+func protectScalars(node *yaml.Node, sources scalarSources, indent int) (restore func(), rewrite func([]byte) []byte) {
+	type marked struct {
+		node   *yaml.Node
+		value  string
+		style  yaml.Style
+		folded *foldedScalar
+	}
+	var scalars []marked
+	clash := false
+	seen := map[*yaml.Node]bool{}
+	var visit func(n *yaml.Node, flow bool)
+	visit = func(n *yaml.Node, flow bool) {
+		if n == nil || seen[n] {
+			return
+		}
+		seen[n] = true
+		clash = clash || strings.Contains(n.Value, scalarMarkPrefix)
+		switch {
+		case n.Kind == yaml.ScalarNode && n.Style&yaml.FoldedStyle != 0 && sources[n] != nil:
+			scalars = append(scalars, marked{node: n, folded: sources[n]})
+		case n.Kind == yaml.ScalarNode && flow && n.Style == 0 && (n.Tag == "" || n.Tag == "!!str") && strings.Contains(n.Value, ":") && plainInFlow(n.Value):
+			scalars = append(scalars, marked{node: n})
+		}
+		flow = flow || n.Style&yaml.FlowStyle != 0
+		for i, child := range n.Content {
+			// Só os valores dos mappings: uma chave com ":" fica como o yaml.v3 a escreve
+			if n.Kind == yaml.MappingNode && i%2 == 0 {
+				seen[child] = true
+				clash = clash || strings.Contains(child.Value, scalarMarkPrefix)
+				continue
+			}
+			visit(child, flow)
+		}
+	}
+	visit(node, false)
+	if len(scalars) == 0 || clash {
+		return nil, nil
+	}
+
+	var pairs []string
+	folded := map[string]*foldedScalar{}
+	for i := range scalars {
+		s := &scalars[i]
+		s.value, s.style = s.node.Value, s.node.Style
+		mark := fmt.Sprintf("%s%dz", scalarMarkPrefix, i)
+		if s.folded != nil {
+			folded[mark] = s.folded
+		} else {
+			pairs = append(pairs, mark, s.value)
+		}
+		s.node.Value, s.node.Style = mark, 0
+	}
+	restore = func() {
+		for _, s := range scalars {
+			s.node.Value, s.node.Style = s.value, s.style
+		}
+	}
+	replacer := strings.NewReplacer(pairs...)
+	rewrite = func(data []byte) []byte {
+		lines := strings.SplitAfter(string(data), "\n")
+		for i, line := range lines {
+			if !strings.Contains(line, scalarMarkPrefix) {
+				continue
+			}
+			line = replacer.Replace(line)
+			if start := strings.Index(line, scalarMarkPrefix); start >= 0 {
+				end := start + len(scalarMarkPrefix)
+				for end < len(line) && line[end] != 'z' {
+					end++
+				}
+				if f := folded[line[start:end+1]]; f != nil {
+					// Valor de uma chave: recuado em relação a ela; item de lista: na coluna do item
+					column := blockIndent(line)
+					if column < start {
+						column += indent
+					}
+					line = line[:start] + f.render(strings.Repeat(" ", column)) + line[end+1:]
+				}
+			}
+			lines[i] = line
+		}
+		return []byte(strings.Join(lines, ""))
+	}
+	return restore, rewrite
+}
+
+// Coluna do nó que começa na linha, depois do recuo e dos "- " das listas
+func blockIndent(line string) int {
+	i := 0
+	for i < len(line) {
+		switch {
+		case line[i] == ' ':
+			i++
+		case strings.HasPrefix(line[i:], "- "):
+			i += 2
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// Compara duas árvores YAML pela forma como seriam escritas: tipo, tag, estilo, valor,
+// âncoras e comentários de cada nó
+func sameTree(a, b *yaml.Node) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Kind != b.Kind || a.Style != b.Style || a.Tag != b.Tag || a.Value != b.Value || a.Anchor != b.Anchor ||
+		a.HeadComment != b.HeadComment || a.LineComment != b.LineComment || a.FootComment != b.FootComment ||
+		len(a.Content) != len(b.Content) || (a.Alias == nil) != (b.Alias == nil) {
+		return false
+	}
+	if a.Alias != nil && a.Alias.Anchor != b.Alias.Anchor {
+		return false
+	}
+	for i := range a.Content {
+		if !sameTree(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
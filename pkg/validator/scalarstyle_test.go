@@ -0,0 +1,107 @@
+package validator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Com $refs, o documento é serializado de novo, mas os textos dobrados (também os dos
+// arquivos referenciados) mantêm as quebras de linha da fonte e as URLs dos mappings
+// em fluxo continuam sem aspas
+func TestResolveKeepsScalarStyle(t *testing.T) {
+	files := MemFS{
+		"api.yaml": []byte(`openapi: 3.0.3
+info:
+  title: Contas
+  version: 1.0.0
+  description: >
+    Primeira linha do texto dobrado
+    e a segunda.
+
+    Outro parágrafo.
+  contact: {name: Equipe, url: https://contas.example.com/contato}
+paths:
+  /contas:
+    get:
+      responses:
+        '200':
+          $ref: './comum.yaml#/Ok'
+components:
+  securitySchemes:
+    oauth:
+      type: oauth2
+      flows:
+        clientCredentials: {tokenUrl: https://auth.example.com/token, scopes: {}}
+`),
+		"comum.yaml": []byte(`Ok:
+  description: >-
+    Resposta de sucesso
+    em duas linhas
+  content:
+    application/json:
+      examples:
+        - >
+          exemplo dobrado
+          numa lista
+`),
+	}
+	resolved, err := Resolve(context.Background(), nil, Options{Source: "api.yaml", FS: files})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"  description: >\n    Primeira linha do texto dobrado\n    e a segunda.\n\n    Outro parágrafo.\n  contact:",
+		"  contact: {name: Equipe, url: https://contas.example.com/contato}\n",
+		"          description: >-\n            Resposta de sucesso\n            em duas linhas\n",
+		"                - >\n                  exemplo dobrado\n                  numa lista\n",
+		"        clientCredentials: {tokenUrl: https://auth.example.com/token, scopes: {}}\n",
+	} {
+		if !strings.Contains(string(resolved.Document), want) {
+			t.Errorf("o documento resolvido não contém\n%s\n--- documento\n%s", want, resolved.Document)
+		}
+	}
+}
+
+// Os escalares só voltam à forma da fonte quando ela reproduz o valor
+func TestFoldedSource(t *testing.T) {
+	for _, tt := range []struct {
+		name, source string
+		kept         bool
+	}{
+		{"dobrado", "v: >\n  a\n  b\n", true},
+		{"sem a quebra final", "v: >-\n  a\n  b\n", true},
+		{"com as linhas em branco finais", "v: >+\n  a\n\n", true},
+		{"recuo explícito", "v: >2\n   a\n  b\n", false},
+		{"comentário no indicador", "v: > # nota\n  a\n", false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			root := mustParseYAML(t, tt.source)
+			sources := foldedScalarsOf([]byte(tt.source), root)
+			if kept := len(sources) == 1; kept != tt.kept {
+				t.Errorf("mantido = %v, esperado %v", kept, tt.kept)
+			}
+			out, err := marshalSpec(root, formatYAML, 2, sources)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.kept && string(out) != tt.source {
+				t.Errorf("saída\n%s\nesperado\n%s", out, tt.source)
+			}
+			if again := mustParseYAML(t, string(out)); !nodesEqual(again, root) {
+				t.Errorf("a saída não reproduz o valor:\n%s", out)
+			}
+		})
+	}
+}
+
+func mustParseYAML(t *testing.T, source string) *yaml.Node {
+	t.Helper()
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(source), &root); err != nil {
+		t.Fatal(err)
+	}
+	return &root
+}
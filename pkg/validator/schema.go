@@ -0,0 +1,461 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Validador de valores contra Schema Objects do OpenAPI (subconjunto do JSON Schema
+// usado nas especificações: tipos, enum/const, limites, pattern, format, objetos,
+// listas e composição). $refs locais que sobraram na árvore (ex.: ciclos) são
+// seguidos a partir de root; os demais são aceitos sem conferência.
+type schemaValidator struct {
+	root *yaml.Node
+	v31  bool
+}
+
+// Falha de um valor contra um schema: a palavra-chave violada e o local dentro do valor
+type schemaError struct {
+	keyword string
+	at      string
+	message string
+}
+
+// Limite de $refs seguidos em um mesmo caminho, para schemas recursivos
+const maxSchemaRefDepth = 32
+
+func newSchemaValidator(root *yaml.Node) *schemaValidator {
+	doc := root
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	return &schemaValidator{root: doc, v31: isOpenAPI31(root)}
+}
+
+// Confere value (no caminho at) contra schema e retorna as falhas encontradas
+func (v *schemaValidator) validate(schema, value *yaml.Node, at string) []schemaError {
+	return v.check(schema, value, at, 0)
+}
+
+func (v *schemaValidator) check(schema, value *yaml.Node, at string, depth int) []schemaError {
+	if schema == nil || value == nil {
+		return nil
+	}
+	if value.Kind == yaml.AliasNode && value.Alias != nil {
+		value = value.Alias
+	}
+	// Schemas booleanos (3.1): true aceita tudo e false nada
+	if schema.Kind == yaml.ScalarNode {
+		if schema.Value == "false" {
+			return []schemaError{{"false", at, "nenhum valor é permitido"}}
+		}
+		return nil
+	}
+	if schema.Kind != yaml.MappingNode {
+		return nil
+	}
+	if ref := mappingValue(schema, "$ref"); ref != nil {
+		file, pointer := splitRef(ref.Value)
+		if file != "" || depth >= maxSchemaRefDepth {
+			return nil
+		}
+		target, err := resolvePointer(v.root, pointer)
+		if err != nil {
+			return nil
+		}
+		errs := v.check(target, value, at, depth+1)
+		// Em 3.0 as irmãs do $ref são ignoradas; em 3.1 elas também se aplicam
+		if !v.v31 {
+			return errs
+		}
+		return append(errs, v.checkKeywords(schema, value, at, depth)...)
+	}
+	return v.checkKeywords(schema, value, at, depth)
+}
+
+func (v *schemaValidator) checkKeywords(schema, value *yaml.Node, at string, depth int) []schemaError {
+	var errs []schemaError
+	fail := func(keyword, format string, args ...interface{}) {
+		errs = append(errs, schemaError{keyword, at, fmt.Sprintf(format, args...)})
+	}
+	kind := valueKind(value)
+
+	types, nullable := schemaTypes(schema, v.v31)
+	if kind == "null" && (nullable || len(types) == 0 && mappingValue(schema, "type") == nil) {
+		return nil
+	}
+	if len(types) > 0 || mappingValue(schema, "type") != nil {
+		matched := false
+		for _, t := range types {
+			if kindMatches(kind, t, value) {
+				matched = true
+			}
+		}
+		if !matched {
+			expected := strings.Join(types, " ou ")
+			if nullable {
+				expected += " ou null"
+			}
+			fail("type", "esperado %s, encontrado %s", expected, kind)
+			return errs
+		}
+	}
+
+	if enum := mappingValue(schema, "enum"); enum != nil && enum.Kind == yaml.SequenceNode {
+		found := false
+		for _, item := range enum.Content {
+			if sameValue(item, value) {
+				found = true
+				break
+			}
+		}
+		if !found && !(kind == "null" && nullable) {
+			fail("enum", "%s não está entre os valores permitidos", describeValue(value))
+		}
+	}
+	if c := mappingValue(schema, "const"); c != nil && !sameValue(c, value) {
+		fail("const", "esperado %s, encontrado %s", describeValue(c), describeValue(value))
+	}
+
+	switch kind {
+	case "string":
+		length := len([]rune(value.Value))
+		if n, ok := schemaNumber(schema, "minLength"); ok && float64(length) < n {
+			fail("minLength", "o texto tem %d caracteres, mínimo %v", length, n)
+		}
+		if n, ok := schemaNumber(schema, "maxLength"); ok && float64(length) > n {
+			fail("maxLength", "o texto tem %d caracteres, máximo %v", length, n)
+		}
+		if p := mappingValue(schema, "pattern"); p != nil {
+			if re, err := regexp.Compile(p.Value); err == nil && !re.MatchString(value.Value) {
+				fail("pattern", "%s não corresponde a %s", describeValue(value), p.Value)
+			}
+		}
+		if f := mappingValue(schema, "format"); f != nil && !formatMatches(f.Value, value.Value) {
+			fail("format", "%s não é um %s válido", describeValue(value), f.Value)
+		}
+	case "integer", "number":
+		n, _ := strconv.ParseFloat(value.Value, 64)
+		minimum, hasMin := schemaNumber(schema, "minimum")
+		maximum, hasMax := schemaNumber(schema, "maximum")
+		exclusiveMin := mappingValue(schema, "exclusiveMinimum")
+		exclusiveMax := mappingValue(schema, "exclusiveMaximum")
+		// 3.0: exclusiveMinimum/exclusiveMaximum são booleanos que modificam minimum/maximum
+		if hasMin && (n < minimum || n == minimum && exclusiveMin != nil && exclusiveMin.Value == "true") {
+			fail("minimum", "%v é menor que o mínimo %v", value.Value, minimum)
+		}
+		if hasMax && (n > maximum || n == maximum && exclusiveMax != nil && exclusiveMax.Value == "true") {
+			fail("maximum", "%v é maior que o máximo %v", value.Value, maximum)
+		}
+		if m, ok := schemaNumber(schema, "exclusiveMinimum"); ok && n <= m {
+			fail("exclusiveMinimum", "%v deve ser maior que %v", value.Value, m)
+		}
+		if m, ok := schemaNumber(schema, "exclusiveMaximum"); ok && n >= m {
+			fail("exclusiveMaximum", "%v deve ser menor que %v", value.Value, m)
+		}
+		if m, ok := schemaNumber(schema, "multipleOf"); ok && m > 0 {
+			if q := n / m; math.Abs(q-math.Round(q)) > 1e-9 {
+				fail("multipleOf", "%v não é múltiplo de %v", value.Value, m)
+			}
+		}
+	case "array":
+		if n, ok := schemaNumber(schema, "minItems"); ok && float64(len(value.Content)) < n {
+			fail("minItems", "a lista tem %d itens, mínimo %v", len(value.Content), n)
+		}
+		if n, ok := schemaNumber(schema, "maxItems"); ok && float64(len(value.Content)) > n {
+			fail("maxItems", "a lista tem %d itens, máximo %v", len(value.Content), n)
+		}
+		if u := mappingValue(schema, "uniqueItems"); u != nil && u.Value == "true" {
+			for i := 1; i < len(value.Content); i++ {
+				for j := 0; j < i; j++ {
+					if sameValue(value.Content[i], value.Content[j]) {
+						fail("uniqueItems", "os itens %d e %d são iguais", j, i)
+					}
+				}
+			}
+		}
+		if items := mappingValue(schema, "items"); items != nil {
+			for i, item := range value.Content {
+				errs = append(errs, v.check(items, item, fmt.Sprintf("%s[%d]", at, i), depth)...)
+			}
+		}
+	case "object":
+		count := len(value.Content) / 2
+		if n, ok := schemaNumber(schema, "minProperties"); ok && float64(count) < n {
+			fail("minProperties", "o objeto tem %d propriedades, mínimo %v", count, n)
+		}
+		if n, ok := schemaNumber(schema, "maxProperties"); ok && float64(count) > n {
+			fail("maxProperties", "o objeto tem %d propriedades, máximo %v", count, n)
+		}
+		if required := mappingValue(schema, "required"); required != nil && required.Kind == yaml.SequenceNode {
+			for _, name := range required.Content {
+				if mappingValue(value, name.Value) == nil {
+					fail("required", "a propriedade obrigatória %s está ausente", name.Value)
+				}
+			}
+		}
+		properties := mappingValue(schema, "properties")
+		additional := mappingValue(schema, "additionalProperties")
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			name, item := value.Content[i].Value, value.Content[i+1]
+			itemAt := joinPath(at, name)
+			if property := mappingValue(properties, name); property != nil {
+				errs = append(errs, v.check(property, item, itemAt, depth)...)
+				continue
+			}
+			switch {
+			case additional == nil:
+			case additional.Kind == yaml.ScalarNode && additional.Value == "false":
+				errs = append(errs, schemaError{"additionalProperties", at, fmt.Sprintf("a propriedade %s não é permitida", name)})
+			case additional.Kind == yaml.MappingNode:
+				errs = append(errs, v.check(additional, item, itemAt, depth)...)
+			}
+		}
+	}
+
+	// Composição
+	if allOf := mappingValue(schema, "allOf"); allOf != nil {
+		for _, sub := range allOf.Content {
+			errs = append(errs, v.check(sub, value, at, depth)...)
+		}
+	}
+	if anyOf := mappingValue(schema, "anyOf"); anyOf != nil && len(anyOf.Content) > 0 {
+		matched := false
+		for _, sub := range anyOf.Content {
+			if len(v.check(sub, value, at, depth)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("anyOf", "o valor não corresponde a nenhuma das %d alternativas", len(anyOf.Content))
+		}
+	}
+	if one := mappingValue(schema, "oneOf"); one != nil && len(one.Content) > 0 {
+		matches := 0
+		for _, sub := range one.Content {
+			if len(v.check(sub, value, at, depth)) == 0 {
+				matches++
+			}
+		}
+		if matches == 0 {
+			fail("oneOf", "o valor não corresponde a nenhuma das %d alternativas", len(one.Content))
+		} else if matches > 1 {
+			fail("oneOf", "o valor corresponde a %d alternativas, deveria corresponder a exatamente uma", matches)
+		}
+	}
+	if not := mappingValue(schema, "not"); not != nil && len(v.check(not, value, at, depth)) == 0 {
+		fail("not", "o valor corresponde a um schema proibido")
+	}
+	return errs
+}
+
+// Tipo JSON de um valor da árvore YAML
+func valueKind(value *yaml.Node) string {
+	switch value.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch value.Tag {
+	case "!!null":
+		return "null"
+	case "!!bool":
+		return "boolean"
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	}
+	// !!str, !!timestamp (datas sem aspas) e demais: texto, como seriam em JSON
+	return "string"
+}
+
+// Indica se o tipo JSON do valor satisfaz o type do schema; 1.0 vale como integer
+func kindMatches(kind, schemaType string, value *yaml.Node) bool {
+	switch {
+	case kind == schemaType:
+		return true
+	case schemaType == "number" && kind == "integer":
+		return true
+	case schemaType == "integer" && kind == "number":
+		n, err := strconv.ParseFloat(value.Value, 64)
+		return err == nil && n == math.Trunc(n)
+	}
+	return false
+}
+
+// Valor numérico de uma palavra-chave do schema
+func schemaNumber(schema *yaml.Node, key string) (float64, bool) {
+	node := mappingValue(schema, key)
+	if node == nil || node.Kind != yaml.ScalarNode || (node.Tag != "!!int" && node.Tag != "!!float") {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(node.Value, 64)
+	return n, err == nil
+}
+
+// Compara dois valores pelo conteúdo, como em JSON (1 e 1.0 são iguais)
+func sameValue(a, b *yaml.Node) bool {
+	return reflect.DeepEqual(plainValue(a), plainValue(b))
+}
+
+func plainValue(node *yaml.Node) interface{} {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		m := map[string]interface{}{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			m[node.Content[i].Value] = plainValue(node.Content[i+1])
+		}
+		return m
+	case yaml.SequenceNode:
+		list := make([]interface{}, len(node.Content))
+		for i, item := range node.Content {
+			list[i] = plainValue(item)
+		}
+		return list
+	}
+	switch valueKind(node) {
+	case "null":
+		return nil
+	case "boolean":
+		return node.Value == "true"
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(node.Value, 64); err == nil {
+			return n
+		}
+	}
+	return node.Value
+}
+
+// Resumo de um valor para as mensagens
+func describeValue(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "objeto"
+	case yaml.SequenceNode:
+		return "lista"
+	}
+	if valueKind(node) == "string" {
+		return strconv.Quote(node.Value)
+	}
+	return node.Value
+}
+
+var (
+	uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	datePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+)
+
+// Confere os formatos mais usados; formatos desconhecidos são aceitos
+func formatMatches(format, value string) bool {
+	switch format {
+	case "date":
+		if !datePattern.MatchString(value) {
+			return false
+		}
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "uuid":
+		return uuidPattern.MatchString(value)
+	case "email":
+		addr, err := mail.ParseAddress(value)
+		return err == nil && addr.Address == value
+	case "ipv4":
+		ip := net.ParseIP(value)
+		return ip != nil && ip.To4() != nil && strings.Contains(value, ".")
+	case "ipv6":
+		ip := net.ParseIP(value)
+		return ip != nil && strings.Contains(value, ":")
+	}
+	return true
+}
+
+// Função para percorrer os schemas do documento: holder recebe cada objeto com schema
+// (parâmetros, cabeçalhos, media types) e visit cada schema, inclusive os internos
+// (properties, items, composição), uma vez por nó. Os schemas de components e
+// definitions são visitados primeiro, para que os caminhos apontem para o componente.
+func visitSchemas(root *yaml.Node, holder func(holder, schema *yaml.Node, path string), visit func(schema *yaml.Node, path string)) {
+	doc := root
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	seen := map[*yaml.Node]bool{}
+	var walkSchema func(schema *yaml.Node, path string)
+	walkSchema = func(schema *yaml.Node, path string) {
+		if schema == nil || schema.Kind != yaml.MappingNode || seen[schema] {
+			return
+		}
+		seen[schema] = true
+		visit(schema, path)
+		for i := 0; i+1 < len(schema.Content); i += 2 {
+			key, value := schema.Content[i].Value, schema.Content[i+1]
+			switch key {
+			case "properties", "patternProperties", "$defs":
+				for j := 0; j+1 < len(value.Content); j += 2 {
+					walkSchema(value.Content[j+1], joinPath(joinPath(path, key), value.Content[j].Value))
+				}
+			case "items", "additionalProperties", "not":
+				walkSchema(value, joinPath(path, key))
+			case "allOf", "anyOf", "oneOf", "prefixItems":
+				for j, sub := range value.Content {
+					walkSchema(sub, fmt.Sprintf("%s[%d]", joinPath(path, key), j))
+				}
+			}
+		}
+	}
+	walkDefinitions := func(section *yaml.Node, path string) {
+		if section == nil || section.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(section.Content); i += 2 {
+			walkSchema(section.Content[i+1], joinPath(path, section.Content[i].Value))
+		}
+	}
+	walkDefinitions(mappingValue(mappingValue(doc, "components"), "schemas"), "$.components.schemas")
+	walkDefinitions(mappingValue(doc, "definitions"), "$.definitions")
+
+	var walk func(node *yaml.Node, path string)
+	walk = func(node *yaml.Node, path string) {
+		switch node.Kind {
+		case yaml.MappingNode:
+			if schema := mappingValue(node, "schema"); schema != nil && holder != nil {
+				holder(node, schema, path)
+			}
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i].Value, node.Content[i+1]
+				switch {
+				case key == "schema":
+					walkSchema(value, joinPath(path, key))
+				case key == "schemas" && path == "$.components", key == "definitions" && path == "$":
+					// Já visitados
+				case key == "example" || key == "examples" || strings.HasPrefix(key, "x-"):
+					// Valores de exemplo e extensões não são percorridos
+				default:
+					walk(value, joinPath(path, key))
+				}
+			}
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				walk(item, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	}
+	walk(doc, "$")
+}
@@ -0,0 +1,98 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Escopo de uma regra (scope): padrões de path no estilo glob. A regra só avalia os
+// nós selecionados pelo given que ficam em um path do escopo; nós fora de paths (info,
+// components) ficam de fora. * casa um segmento e /** no fim casa o próprio prefixo e
+// tudo abaixo dele.
+//
+//	scope:
+//	  - "/accounts/**"
+//	  - "/opendata-*/**"
+type pathScope struct {
+	patterns []scopePattern
+}
+
+type scopePattern struct {
+	text string
+	re   *regexp.Regexp
+}
+
+// Função para ler o scope de uma regra (um padrão ou uma lista); nulo quando a regra
+// não tem escopo
+func parseRuleScope(raw interface{}) (*pathScope, error) {
+	var texts []string
+	switch value := raw.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		texts = []string{value}
+	case []interface{}:
+		for _, item := range value {
+			text, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("scope deve ser um padrão de path ou uma lista de padrões em texto, encontrado %s na lista", ruleValueType(item))
+			}
+			texts = append(texts, text)
+		}
+	default:
+		return nil, fmt.Errorf("scope deve ser um padrão de path ou uma lista de padrões em texto, encontrado %s", ruleValueType(raw))
+	}
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("scope não pode ser uma lista vazia")
+	}
+	scope := &pathScope{}
+	for _, text := range texts {
+		p, err := newScopePattern(text)
+		if err != nil {
+			return nil, fmt.Errorf("scope %v", err)
+		}
+		scope.patterns = append(scope.patterns, p)
+	}
+	return scope, nil
+}
+
+// Compila um padrão de path no estilo do scope (também usado pelas exceções das regras)
+func newScopePattern(text string) (scopePattern, error) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "/") {
+		return scopePattern{}, fmt.Errorf("%q deve começar com / (ex.: \"/accounts/**\")", text)
+	}
+	expr := "^" + globToRegexp(text) + "$"
+	if prefix := strings.TrimSuffix(text, "/**"); prefix != text {
+		expr = "^" + globToRegexp(prefix) + "(?:/.*)?$"
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return scopePattern{}, fmt.Errorf("%q inválido: %v", text, err)
+	}
+	return scopePattern{text: text, re: re}, nil
+}
+
+// Padrão do escopo que contém o path dono do JSONPath; falso fora do escopo
+func (s *pathScope) match(jsonPath string) (string, bool) {
+	owner, ok := owningOperation(jsonPath)
+	if !ok || owner.Webhook {
+		return "", false
+	}
+	for _, p := range s.patterns {
+		if p.re.MatchString(owner.Path) {
+			return p.text, true
+		}
+	}
+	return "", false
+}
+
+// Padrões do escopo, para as mensagens e o explain
+func (s *pathScope) String() string {
+	texts := make([]string, 0, len(s.patterns))
+	for _, p := range s.patterns {
+		texts = append(texts, p.text)
+	}
+	return strings.Join(texts, ", ")
+}
@@ -0,0 +1,95 @@
+package validator
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const scopeSpec = `openapi: 3.0.3
+info: {title: Open Finance, version: 1.0.0}
+paths:
+  /accounts/v2/accounts:
+    get:
+      responses:
+        '200': {description: ok}
+  /accounts:
+    get:
+      responses:
+        '200': {description: ok}
+  /opendata-loans/v1/loans:
+    get:
+      responses:
+        '200': {description: ok}
+  /payments/v3/pix:
+    post:
+      responses:
+        '201': {description: criado}
+`
+
+// Com scope, a regra só avalia os nós dos paths que casam com algum padrão: /** casa o
+// próprio prefixo e tudo abaixo dele, * casa um segmento
+func TestRuleScope(t *testing.T) {
+	tests := []struct {
+		scope string
+		want  []int
+	}{
+		{`"/accounts/**"`, []int{6, 10}},
+		{`["/opendata-*/**"]`, []int{14}},
+		{`"/*/v3/pix"`, []int{18}},
+		{`["/accounts/*/accounts", "/payments/**"]`, []int{6, 18}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			rules := "rules:\n  operacao-com-descricao:\n    given: $.paths[*][*]\n    scope: " + tt.scope + "\n    then: {field: description, function: truthy}\n"
+			var lines []int
+			for _, v := range ruleViolations(t, scopeSpec, rules, "operacao-com-descricao") {
+				lines = append(lines, v.Line)
+			}
+			if !equalInts(lines, tt.want) {
+				t.Errorf("violações nas linhas %v, esperado %v", lines, tt.want)
+			}
+		})
+	}
+}
+
+// Nós fora de paths não entram no escopo
+func TestRuleScopeOutsidePaths(t *testing.T) {
+	rules := "rules:\n  contato:\n    given: $.info\n    scope: /accounts/**\n    then: {field: contact, function: truthy}\n"
+	if found := ruleViolations(t, scopeSpec, rules, "contato"); len(found) != 0 {
+		t.Errorf("info não está em um path do escopo: %v", found)
+	}
+}
+
+// Escopos malformados são erro de uso
+func TestRuleScopeErrors(t *testing.T) {
+	tests := []struct {
+		scope, message string
+	}{
+		{"accounts/**", `scope "accounts/**" deve começar com /`},
+		{"[]", "scope não pode ser uma lista vazia"},
+		{"[/accounts, 5]", "encontrado número na lista"},
+		{"{a: b}", "scope deve ser um padrão de path ou uma lista de padrões em texto, encontrado objeto"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			rules := "rules:\n  a:\n    given: $.paths[*][*]\n    scope: " + tt.scope + "\n    then: {field: description, function: truthy}\n"
+			_, err := Validate(context.Background(), []byte(scopeSpec), Options{Source: "api.yaml", Rules: []byte(rules)})
+			if err == nil || rulesExitCode(err) != exitUsage || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("erro %v, esperado erro de uso com %q", err, tt.message)
+			}
+		})
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,110 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fluxos OAuth2 do OpenAPI 3, onde os escopos são declarados
+var oauthFlows = []string{"implicit", "password", "clientCredentials", "authorizationCode"}
+
+// Função para conferir as referências dos requisitos de segurança (globais e de cada
+// operação): cada esquema precisa existir em components.securitySchemes (ou
+// securityDefinitions no Swagger 2.0) e cada escopo precisa ser declarado pelos
+// fluxos do esquema oauth2; nos demais tipos a lista deve estar vazia (em 3.1,
+// openIdConnect e os demais tipos podem listar papéis)
+func securityViolations(root *yaml.Node) []Violation {
+	doc := root
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	schemes := mappingValue(mappingValue(doc, "components"), "securitySchemes")
+	section := "components.securitySchemes"
+	if isSwagger2(root) {
+		schemes, section = mappingValue(doc, "securityDefinitions"), "securityDefinitions"
+	}
+	var names []string
+	if schemes != nil && schemes.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(schemes.Content); i += 2 {
+			names = append(names, schemes.Content[i].Value)
+		}
+	}
+	v31 := isOpenAPI31(root)
+
+	var violations []Violation
+	check := func(security *yaml.Node, path, where string) {
+		if security == nil || security.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, requirement := range security.Content {
+			for j := 0; j+1 < len(requirement.Content); j += 2 {
+				name, scopes := requirement.Content[j], requirement.Content[j+1]
+				requirementPath := joinPath(fmt.Sprintf("%s[%d]", path, i), name.Value)
+				scheme := mappingValue(schemes, name.Value)
+				if scheme == nil {
+					violations = append(violations, Violation{RuleID: "security-scheme", Severity: "error", Message: fmt.Sprintf("%s usa o esquema de segurança %s, que não existe em %s%s.", where, name.Value, section, suggestionSuffix(name.Value, names)), JSONPath: requirementPath, Line: name.Line, Suggestion: closestName(name.Value, names)})
+					continue
+				}
+				declared, checkable := declaredScopes(scheme, v31)
+				if !checkable {
+					continue
+				}
+				for _, scope := range scopes.Content {
+					if declared[scope.Value] {
+						continue
+					}
+					if kind := mappingValue(scheme, "type"); kind == nil || kind.Value != "oauth2" {
+						violations = append(violations, Violation{RuleID: "security-scope", Severity: "error", Message: fmt.Sprintf("%s pede o escopo %s do esquema %s, que não é oauth2 e não declara escopos.", where, scope.Value, name.Value), JSONPath: requirementPath, Line: scope.Line})
+						continue
+					}
+					known := make([]string, 0, len(declared))
+					for s := range declared {
+						known = append(known, s)
+					}
+					sort.Strings(known)
+					violations = append(violations, Violation{RuleID: "security-scope", Severity: "error", Message: fmt.Sprintf("%s pede o escopo %s, que o esquema %s não declara%s.", where, scope.Value, name.Value, suggestionSuffix(scope.Value, known)), JSONPath: requirementPath, Line: scope.Line, Suggestion: closestName(scope.Value, known)})
+				}
+			}
+		}
+	}
+
+	check(mappingValue(doc, "security"), "$.security", "O requisito global de segurança")
+	for _, op := range listOperations(root) {
+		section := "paths"
+		if op.Webhook {
+			section = "webhooks"
+		}
+		operation := mappingValue(mappingValue(mappingValue(doc, section), op.Path), op.Method)
+		path := joinPath(joinPath(joinPath("$", section), op.Path), op.Method)
+		check(mappingValue(operation, "security"), joinPath(path, "security"), fmt.Sprintf("A operação %s %s", op.Method, op.displayPath()))
+	}
+	return violations
+}
+
+// Escopos declarados por um esquema de segurança. checkable é falso quando os escopos
+// não podem ser conferidos: openIdConnect (vêm do discovery) e, em 3.1, os tipos que
+// não são oauth2 (a lista traz papéis livres)
+func declaredScopes(scheme *yaml.Node, v31 bool) (map[string]bool, bool) {
+	declared := map[string]bool{}
+	kind := mappingValue(scheme, "type")
+	if kind == nil || kind.Value != "oauth2" {
+		return declared, !(v31 || kind != nil && kind.Value == "openIdConnect")
+	}
+	// Swagger 2.0: scopes direto no esquema
+	collect := func(scopes *yaml.Node) {
+		if scopes == nil || scopes.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(scopes.Content); i += 2 {
+			declared[scopes.Content[i].Value] = true
+		}
+	}
+	collect(mappingValue(scheme, "scopes"))
+	flows := mappingValue(scheme, "flows")
+	for _, flow := range oauthFlows {
+		collect(mappingValue(mappingValue(flows, flow), "scopes"))
+	}
+	return declared, true
+}
@@ -0,0 +1,58 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const securitySpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+security:
+  - OAuth2Security: [accounts]
+paths:
+  /contas:
+    get:
+      security:
+        - OpenId: [acounts, payments]
+        - ApiKey: [admin]
+        - Oidc: [openid, accounts]
+      responses:
+        '200': {description: ok}
+components:
+  securitySchemes:
+    OpenId:
+      type: oauth2
+      flows:
+        clientCredentials:
+          tokenUrl: https://auth.banco.com.br/token
+          scopes: {accounts: contas, resources: recursos}
+    ApiKey: {type: apiKey, in: header, name: x-api-key}
+    Oidc: {type: openIdConnect, openIdConnectUrl: https://auth.banco.com.br/.well-known/openid-configuration}
+`
+
+// O esquema inexistente, o escopo que o oauth2 não declara (com o nome mais próximo
+// como sugestão) e o escopo em um esquema que não é oauth2 são relatados no requisito;
+// os escopos de openIdConnect não são conferidos
+func TestSecurityViolations(t *testing.T) {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(securitySpec), &root); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range securityViolations(&root) {
+		got = append(got, fmt.Sprintf("%s %s:%d %s [%s]", v.RuleID, v.JSONPath, v.Line, v.Message, v.Suggestion))
+	}
+	want := []string{
+		"security-scheme $.security[0].OAuth2Security:4 O requisito global de segurança usa o esquema de segurança OAuth2Security, que não existe em components.securitySchemes. []",
+		"security-scope $.paths['/contas'].get.security[0].OpenId:9 A operação get /contas pede o escopo acounts, que o esquema OpenId não declara (você quis dizer accounts?). [accounts]",
+		"security-scope $.paths['/contas'].get.security[0].OpenId:9 A operação get /contas pede o escopo payments, que o esquema OpenId não declara. []",
+		"security-scope $.paths['/contas'].get.security[1].ApiKey:10 A operação get /contas pede o escopo admin do esquema ApiKey, que não é oauth2 e não declara escopos. []",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("violações:\n%s\nesperado:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,539 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Flags do subcomando serve
+type serveOptions struct {
+	listen        string
+	rulesets      stringList
+	maxBody       byteSize
+	timeout       time.Duration
+	maxConcurrent int
+	encoding      string // --encoding, já conferida
+}
+
+func (o *serveOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.listen, "listen", ":8080", "endereço do servidor HTTP")
+	fs.Var(&o.rulesets, "ruleset", "pacote de regras disponível nas requisições: nome de um pacote embarcado, caminho do arquivo ou nome=caminho; pode ser repetida (padrão: os pacotes embarcados)")
+	fs.Var(&o.maxBody, "max-body", "tamanho máximo do corpo de cada requisição (ex.: 20MB)")
+	fs.DurationVar(&o.timeout, "timeout", time.Minute, "tempo máximo de cada requisição")
+	fs.IntVar(&o.maxConcurrent, "max-concurrent", 4, "requisições atendidas ao mesmo tempo; as demais esperam até o --timeout")
+	registerEncodingFlag(fs)
+}
+
+var serveCommand = &command{
+	Name:    "serve",
+	Summary: "expõe validate, resolve e diff por HTTP",
+	Description: `Inicia um servidor HTTP para validar e resolver especificações enviadas
+por outros sistemas (ex.: o portal do desenvolvedor antes de aceitar uma versão).
+
+  POST /validate  valida a especificação e responde o relatório JSON do
+                  --format json (200 sem erros, 422 com violações de error)
+  POST /resolve   resolve as referências e responde o relatório da resolução
+                  com o documento resolvido em "document" (422 com erros)
+  POST /diff      compara a versão anterior (campo "base") com a nova (campo
+                  "spec") e responde as mudanças, com as que quebram os
+                  clientes marcadas em breaking (200 sem quebras, 422 com)
+  GET  /healthz   estado do servidor e pacotes de regras carregados
+  GET  /readyz    200 quando todos os pacotes de regras carregaram, 503 se algum falhou
+  GET  /metrics   métricas no formato do Prometheus: requisições e latência por
+                  endpoint, duração das validações, violações por severidade e
+                  buscas dos pacotes de regras
+
+A especificação vem no corpo da requisição ou, em multipart/form-data, no
+campo "spec"; arquivos adicionais no campo "ref" (com o caminho relativo como
+nome do arquivo) atendem os $refs externos. No /diff, os arquivos da versão
+anterior vão no campo "base-ref"; sem ele, as duas versões usam os de "ref".
+Nenhum arquivo do servidor é lido pelos $refs e referências remotas não são
+seguidas. O pacote de regras vem do parâmetro ou campo "ruleset" (padrão: o
+primeiro de --ruleset) e o formato do documento resolvido de "format" (yaml ou
+json).
+
+Os pacotes de regras são carregados uma vez, na inicialização; um pacote que não
+carrega não impede o servidor de subir, mas deixa /readyz em 503. Requisições
+acima de --max-body recebem 413, as que passam de --timeout recebem 504 e as
+que não conseguem vaga em --max-concurrent dentro do tempo limite recebem 503.
+Cada requisição tem a própria configuração, e até --max-concurrent são
+processadas ao mesmo tempo. Uma requisição que passa de --timeout responde 504
+na hora, mas a vaga só é liberada quando a indexação ou a resolução em
+andamento termina, para que o servidor não acumule trabalho além do limite.`,
+	Examples: []string{
+		programName + " serve --listen :8080",
+		programName + " serve --ruleset ofb --ruleset interno=regras/interno.yaml --max-body 20MB",
+		"curl --data-binary @swagger.yaml 'http://localhost:8080/validate?ruleset=ofb'",
+		"curl -F spec=@swagger.yaml -F ref=@schemas/common.yaml\\;filename=schemas/common.yaml http://localhost:8080/resolve",
+		"curl -F base=@oldSwagger.yaml -F spec=@swagger.yaml http://localhost:8080/diff",
+		"curl -F base=@old/swagger.yaml -F base-ref=@old/schemas/common.yaml\\;filename=schemas/common.yaml -F spec=@swagger.yaml -F ref=@schemas/common.yaml\\;filename=schemas/common.yaml http://localhost:8080/diff",
+	},
+	Flags: func(fs *flag.FlagSet) { (&serveOptions{}).register(fs) },
+	Run:   runServe,
+}
+
+// Tamanho padrão de --max-body
+const defaultMaxBody = 20 << 20
+
+// Servidor HTTP com os pacotes de regras carregados na inicialização
+type specServer struct {
+	rulesets map[string]map[string]interface{}
+	names    []string          // na ordem de --ruleset; o primeiro é o padrão
+	failed   map[string]string // pacotes que não carregaram, com o erro (/readyz)
+	metrics  *serverMetrics
+	maxBody  int64
+	timeout  time.Duration
+	encoding string // codificação das especificações recebidas (--encoding)
+	slots    chan struct{}
+	log      io.Writer
+}
+
+func runServe(c *command, args []string) int {
+	opts := &serveOptions{maxBody: defaultMaxBody}
+	fs := c.newFlagSet()
+	opts.register(fs)
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	if err := checkEncodingFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	opts.encoding = inputEncoding
+	if fs.NArg() != 0 {
+		return c.usageError("serve não recebe argumentos, recebidos %d", fs.NArg())
+	}
+	if opts.maxConcurrent < 1 {
+		return c.usageError("--max-concurrent deve ser pelo menos 1")
+	}
+	if opts.maxBody <= 0 {
+		return c.usageError("--max-body deve ser maior que zero")
+	}
+	if opts.timeout <= 0 {
+		return c.usageError("--timeout deve ser maior que zero")
+	}
+
+	server, err := newSpecServer(opts)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao carregar as regras:", err)
+		return exitUsage
+	}
+
+	ctx, stop := newRunContext(0)
+	defer stop()
+	httpServer := &http.Server{Addr: opts.listen, Handler: server.handler(), ReadHeaderTimeout: 10 * time.Second}
+	errCh := make(chan error, 1)
+	go func() { errCh <- httpServer.ListenAndServe() }()
+	fmt.Fprintf(stdout, "🔎 Servidor em %s com as regras: %s\n", opts.listen, strings.Join(server.loaded(), ", "))
+
+	select {
+	case err := <-errCh:
+		fmt.Fprintln(stdout, "❌ Erro no servidor HTTP:", err)
+		return exitFailure
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), opts.timeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		fmt.Fprintln(stdout, "⚠️  Requisições interrompidas no encerramento:", err)
+	}
+	fmt.Fprintln(stdout, "ℹ️  Servidor encerrado.")
+	return exitOK
+}
+
+// Carrega os pacotes de --ruleset (ou todos os embarcados)
+func newSpecServer(opts *serveOptions) (*specServer, error) {
+	specs := []string(opts.rulesets)
+	if len(specs) == 0 {
+		for name := range builtinRulesets {
+			specs = append(specs, name)
+		}
+		sort.Strings(specs)
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("nenhum pacote de regras embarcado; informe --ruleset")
+	}
+	s := &specServer{
+		rulesets: map[string]map[string]interface{}{},
+		failed:   map[string]string{},
+		metrics:  newServerMetrics(),
+		maxBody:  int64(opts.maxBody),
+		timeout:  opts.timeout,
+		encoding: opts.encoding,
+		slots:    make(chan struct{}, opts.maxConcurrent),
+		log:      stderr,
+	}
+	for _, spec := range specs {
+		name, file, ok := strings.Cut(spec, "=")
+		if !ok {
+			file = spec
+			if _, builtin := builtinRulesets[spec]; !builtin {
+				name = strings.TrimSuffix(filepath.Base(spec), filepath.Ext(spec))
+			}
+		}
+		if _, dup := s.rulesets[name]; dup || s.failed[name] != "" {
+			return nil, fmt.Errorf("pacote de regras %q informado mais de uma vez", name)
+		}
+		s.names = append(s.names, name)
+		// Um pacote inválido não impede a inicialização: o servidor fica no ar, mas
+		// /readyz responde 503 até que a configuração seja corrigida
+		// Os pacotes são carregados uma vez: sem cache em disco, como nas requisições
+		rules, err := loadRuleset(flagSettings().with(func(s *runSettings) { s.noCache = true }), file)
+		if err != nil {
+			fmt.Fprintf(stdout, "❌ Erro ao carregar o pacote de regras %s: %v\n", name, err)
+			s.failed[name] = err.Error()
+			continue
+		}
+		s.rulesets[name] = rules
+	}
+	return s, nil
+}
+
+func (s *specServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealth)
+	mux.HandleFunc("/readyz", s.handleReady)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/validate", s.post(s.handleValidate))
+	mux.HandleFunc("/resolve", s.post(s.handleResolve))
+	mux.HandleFunc("/diff", s.post(s.handleDiff))
+	return mux
+}
+
+// Erro de uma requisição com o status HTTP da resposta
+type httpError struct {
+	status  int
+	message string
+}
+
+func (e *httpError) Error() string { return e.message }
+
+func httpErrorf(status int, format string, args ...interface{}) *httpError {
+	return &httpError{status: status, message: fmt.Sprintf(format, args...)}
+}
+
+// Envolve um endpoint POST com o limite de corpo, o tempo limite, a vaga de
+// concorrência, a recuperação de pânicos e o registro da requisição
+func (s *specServer) post(handle func(ctx context.Context, r *http.Request) (int, interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		status, body, err := s.serve(w, r, handle)
+		if err != nil {
+			var he *httpError
+			if !errors.As(err, &he) {
+				he = &httpError{status: http.StatusInternalServerError, message: err.Error()}
+			}
+			status, body = he.status, map[string]string{"error": he.message}
+		}
+		writeJSON(w, status, body)
+		elapsed := time.Since(start)
+		s.metrics.observeRequest(r.URL.Path, status, elapsed)
+		fmt.Fprintf(s.log, "%s %s %d %s\n", r.Method, r.URL.Path, status, elapsed.Round(time.Millisecond))
+	}
+}
+
+func (s *specServer) serve(w http.ResponseWriter, r *http.Request, handle func(ctx context.Context, r *http.Request) (int, interface{}, error)) (status int, body interface{}, err error) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		return 0, nil, httpErrorf(http.StatusMethodNotAllowed, "use POST")
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+	r.Body = http.MaxBytesReader(w, r.Body, s.maxBody)
+
+	// A vaga só volta quando as etapas da requisição terminam: uma etapa do libopenapi
+	// abandonada no tempo limite continua ocupando CPU até o fim
+	ctx, phases := withPhaseGroup(ctx)
+	select {
+	case s.slots <- struct{}{}:
+		defer func() {
+			go func() {
+				phases.Wait()
+				<-s.slots
+			}()
+		}()
+	case <-ctx.Done():
+		w.Header().Set("Retry-After", "5")
+		return 0, nil, httpErrorf(http.StatusServiceUnavailable, "servidor ocupado; tente novamente")
+	}
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = httpErrorf(http.StatusInternalServerError, "erro interno: %v", rec)
+		}
+	}()
+
+	status, body, err = handle(ctx, r)
+	if err != nil && isCancellation(err) && ctx.Err() != nil {
+		err = httpErrorf(http.StatusGatewayTimeout, "tempo limite de %s atingido", s.timeout)
+	}
+	return status, body, err
+}
+
+// Nomes dos pacotes carregados, na ordem de --ruleset
+func (s *specServer) loaded() []string {
+	var names []string
+	for _, name := range s.names {
+		if _, ok := s.rulesets[name]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// GET /healthz: o processo está no ar
+func (s *specServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok", "rulesets": s.loaded()})
+}
+
+// GET /readyz: 200 quando todos os pacotes de regras carregaram, 503 caso contrário
+func (s *specServer) handleReady(w http.ResponseWriter, r *http.Request) {
+	if len(s.failed) > 0 {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{"status": "not ready", "rulesets": s.loaded(), "failed": s.failed})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ready", "rulesets": s.loaded()})
+}
+
+// GET /metrics: métricas no formato de texto do Prometheus
+func (s *specServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	s.metrics.write(w)
+}
+
+// POST /validate
+func (s *specServer) handleValidate(ctx context.Context, r *http.Request) (int, interface{}, error) {
+	upload, err := s.readUpload(r, "spec")
+	if err != nil {
+		return 0, nil, err
+	}
+	name := upload.field("ruleset", s.names[0])
+	rules, ok := s.rulesets[name]
+	if !ok {
+		if message, failed := s.failed[name]; failed {
+			s.metrics.observeRuleset(name, false)
+			return 0, nil, httpErrorf(http.StatusServiceUnavailable, "o pacote de regras %q não carregou na inicialização: %s", name, message)
+		}
+		// Nomes desconhecidos ficam num único rótulo, para não criar uma série por valor recebido
+		s.metrics.observeRuleset("", false)
+		return 0, nil, httpErrorf(http.StatusNotFound, "pacote de regras %q não carregado; disponíveis: %s", name, strings.Join(s.loaded(), ", "))
+	}
+	s.metrics.observeRuleset(name, true)
+	opts := upload.options(s.maxBody, s.encoding)
+	opts.rules = rules
+	start := time.Now()
+	result, err := Validate(ctx, upload.spec, opts)
+	if err != nil {
+		return 0, nil, requestFailure(err)
+	}
+	s.metrics.observeValidation(time.Since(start), result.Violations)
+	status := http.StatusOK
+	if !result.Valid() {
+		status = http.StatusUnprocessableEntity
+	}
+	return status, jsonReport{SchemaVersion: reportSchemaVersion, Validation: newValidationReport(upload.source, result.Violations)}, nil
+}
+
+// Resposta de POST /resolve: o relatório do resolve --check --format json com o documento
+type resolveResponse struct {
+	Resolution *resolutionReport `json:"resolution"`
+	Document   string            `json:"document,omitempty"`
+}
+
+// POST /resolve
+func (s *specServer) handleResolve(ctx context.Context, r *http.Request) (int, interface{}, error) {
+	upload, err := s.readUpload(r, "spec")
+	if err != nil {
+		return 0, nil, err
+	}
+	format, err := outputFormatFor(upload.source, upload.field("format", ""))
+	if err != nil {
+		return 0, nil, httpErrorf(http.StatusBadRequest, "%v", err)
+	}
+	resolved, data, err := resolveSpec(ctx, upload.spec, upload.options(s.maxBody, s.encoding), format)
+	if err != nil {
+		return 0, nil, requestFailure(err)
+	}
+	report := resolved.report
+	report.File = upload.source
+	status := http.StatusOK
+	if len(report.Errors) > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	return status, resolveResponse{Resolution: &report, Document: string(data)}, nil
+}
+
+// POST /diff: a versão anterior vem no campo "base", com os arquivos dos $refs em
+// "base-ref", e a nova em "spec", com os dela em "ref". Cada versão tem o próprio
+// sistema de arquivos; sem "base-ref", a anterior usa os arquivos de "ref".
+func (s *specServer) handleDiff(ctx context.Context, r *http.Request) (int, interface{}, error) {
+	upload, err := s.readUpload(r, "spec")
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(bytes.TrimSpace(upload.base)) == 0 {
+		return 0, nil, httpErrorf(http.StatusBadRequest, "versão anterior não informada: envie multipart/form-data com os campos \"base\" e \"spec\"")
+	}
+	opts := upload.options(s.maxBody, s.encoding)
+	if upload.baseFiles != nil {
+		opts.OldFS = upload.baseFiles
+	}
+	result, err := Diff(ctx, upload.base, upload.spec, opts)
+	if err != nil {
+		return 0, nil, requestFailure(err)
+	}
+	status := http.StatusOK
+	if result.Breaking > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	return status, result, nil
+}
+
+// Falha do validador numa requisição: cancelamentos seguem para o tempo limite e
+// o restante (YAML inválido, documento grande demais...) é erro da entrada
+func requestFailure(err error) error {
+	if isCancellation(err) {
+		return err
+	}
+	return httpErrorf(http.StatusBadRequest, "%v", err)
+}
+
+// Especificação recebida numa requisição, com os arquivos dos $refs externos
+type specUpload struct {
+	source string // nome da especificação nas mensagens
+	spec   []byte
+	base   []byte // versão anterior (POST /diff)
+	files  MemFS
+	// Arquivos dos $refs da versão anterior (campo base-ref); nulo sem o campo
+	baseFiles MemFS
+	values    map[string]string // campos do formulário e parâmetros da URL
+}
+
+func (u *specUpload) field(name, fallback string) string {
+	if v := u.values[name]; v != "" {
+		return v
+	}
+	return fallback
+}
+
+// Opções da API para a especificação recebida: os $refs só alcançam os arquivos enviados
+func (u *specUpload) options(maxBody int64, encoding string) Options {
+	return Options{Source: u.source, FS: u.files, MaxFileSize: maxBody, Encoding: encoding}
+}
+
+// Lê a especificação do corpo ou, em multipart/form-data, do campo specField
+func (s *specServer) readUpload(r *http.Request, specField string) (*specUpload, error) {
+	upload := &specUpload{source: "openapi.yaml", files: MemFS{}, values: map[string]string{}}
+	for key, values := range r.URL.Query() {
+		upload.values[key] = values[0]
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, bodyError(err, s.maxBody)
+		}
+		upload.spec = data
+		if name := upload.values["name"]; name != "" {
+			upload.source = path.Base(name)
+		}
+		return upload, upload.check()
+	}
+
+	if err := r.ParseMultipartForm(s.maxBody); err != nil {
+		return nil, bodyError(err, s.maxBody)
+	}
+	defer r.MultipartForm.RemoveAll()
+	for key, values := range r.MultipartForm.Value {
+		if key == specField && len(values) > 0 && upload.spec == nil {
+			upload.spec = []byte(values[0])
+			continue
+		}
+		if key == "base" && len(values) > 0 && upload.base == nil {
+			upload.base = []byte(values[0])
+			continue
+		}
+		upload.values[key] = values[0]
+	}
+	for key, headers := range r.MultipartForm.File {
+		for _, header := range headers {
+			f, err := header.Open()
+			if err != nil {
+				return nil, bodyError(err, s.maxBody)
+			}
+			data, err := io.ReadAll(f)
+			f.Close()
+			if err != nil {
+				return nil, bodyError(err, s.maxBody)
+			}
+			switch key {
+			case specField:
+				upload.spec = data
+				if name := path.Base(header.Filename); name != "." && name != "/" {
+					upload.source = name
+				}
+			case "base":
+				upload.base = data
+			case "ref", "base-ref":
+				name := path.Clean(filepath.ToSlash(partFilename(header)))
+				if !fs.ValidPath(name) {
+					return nil, httpErrorf(http.StatusBadRequest, "nome de arquivo inválido em %s: %q", key, partFilename(header))
+				}
+				if key == "ref" {
+					upload.files[name] = data
+					continue
+				}
+				if upload.baseFiles == nil {
+					upload.baseFiles = MemFS{}
+				}
+				upload.baseFiles[name] = data
+			}
+		}
+	}
+	return upload, upload.check()
+}
+
+// Nome do arquivo como enviado: FileHeader.Filename mantém só o último elemento do
+// caminho, e os arquivos dos $refs precisam dos diretórios (ex.: schemas/common.yaml)
+func partFilename(header *multipart.FileHeader) string {
+	if _, params, err := mime.ParseMediaType(header.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		return params["filename"]
+	}
+	return header.Filename
+}
+
+func (u *specUpload) check() error {
+	if len(bytes.TrimSpace(u.spec)) == 0 {
+		return httpErrorf(http.StatusBadRequest, "especificação não informada: envie o documento no corpo ou no campo \"spec\"")
+	}
+	return nil
+}
+
+// Erro ao ler o corpo: 413 acima de --max-body, 400 nos demais casos
+func bodyError(err error, maxBody int64) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return httpErrorf(http.StatusRequestEntityTooLarge, "requisição acima de --max-body %s", formatBytes(maxBody))
+	}
+	return httpErrorf(http.StatusBadRequest, "erro ao ler a requisição: %v", err)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	encoder := newJSONEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(body)
+}
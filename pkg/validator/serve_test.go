@@ -0,0 +1,262 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Servidor de teste com o pacote de regras ofb
+func newTestServer(t *testing.T, timeout time.Duration, maxConcurrent int) *specServer {
+	t.Helper()
+	s, err := newSpecServer(&serveOptions{rulesets: stringList{"ofb"}, maxBody: defaultMaxBody, timeout: timeout, maxConcurrent: maxConcurrent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.log = io.Discard
+	return s
+}
+
+// Corpo multipart/form-data com os campos informados
+func multipartBody(t *testing.T, fields map[string]string) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for name, content := range fields {
+		part, err := w.CreateFormFile(name, name+".yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(content))
+	}
+	w.Close()
+	return &body, w.FormDataContentType()
+}
+
+func TestServeDiff(t *testing.T) {
+	server := httptest.NewServer(newTestServer(t, time.Minute, 1).handler())
+	defer server.Close()
+
+	current := strings.Replace(diffOldSpec, "saldo: {type: number}", "saldo: {type: string}", 1)
+	body, contentType := multipartBody(t, map[string]string{"base": diffOldSpec, "spec": current})
+	resp, err := http.Post(server.URL+"/diff", contentType, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("status %d, esperado 422", resp.StatusCode)
+	}
+	var result DiffResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Breaking != 1 || !strings.Contains(result.Changes[0].Message, "saldo mudou de number para string") {
+		t.Errorf("resultado inesperado: %+v", result)
+	}
+
+	body, contentType = multipartBody(t, map[string]string{"base": diffOldSpec, "spec": diffOldSpec})
+	resp, err = http.Post(server.URL+"/diff", contentType, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("versões iguais: status %d, esperado 200", resp.StatusCode)
+	}
+
+	resp, err = http.Post(server.URL+"/diff", "application/yaml", strings.NewReader(diffOldSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("sem a versão anterior: status %d, esperado 400", resp.StatusCode)
+	}
+}
+
+// Cada versão do /diff usa os próprios arquivos: a mudança num arquivo referenciado
+// aparece mesmo com o arquivo raiz igual nas duas versões
+func TestServeDiffReferencedFiles(t *testing.T) {
+	server := httptest.NewServer(newTestServer(t, time.Minute, 1).handler())
+	defer server.Close()
+
+	old, current := multiFixture(t), multiFixture(t, "accountId:\n      type: string", "accountId:\n      type: integer")
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	add := func(field, name string, data []byte) {
+		part, err := w.CreateFormFile(field, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(data)
+	}
+	add("base", "api.yaml", old["api.yaml"])
+	add("spec", "api.yaml", current["api.yaml"])
+	for _, name := range []string{"schemas/account.yaml", "schemas/common.yaml"} {
+		add("base-ref", name, old[name])
+		add("ref", name, current[name])
+	}
+	w.Close()
+
+	resp, err := http.Post(server.URL+"/diff", w.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var result DiffResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusUnprocessableEntity || result.Breaking == 0 {
+		t.Fatalf("status %d, esperado 422 com a mudança de schemas/account.yaml: %+v", resp.StatusCode, result)
+	}
+	found := false
+	for _, c := range result.Changes {
+		found = found || strings.Contains(c.Message, "accountId")
+	}
+	if !found {
+		t.Errorf("a mudança de accountId não foi relatada: %+v", result.Changes)
+	}
+}
+
+// Uma etapa abandonada no tempo limite continua ocupando a vaga da requisição
+func TestServeHoldsSlotUntilAbandonedPhaseEnds(t *testing.T) {
+	s := newTestServer(t, 50*time.Millisecond, 1)
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	slow := s.post(func(ctx context.Context, r *http.Request) (int, interface{}, error) {
+		err := runPhase(ctx, func() error {
+			<-release
+			close(finished)
+			return nil
+		})
+		return http.StatusOK, nil, err
+	})
+
+	request := func() int {
+		w := httptest.NewRecorder()
+		slow(w, httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader("{}")))
+		return w.Code
+	}
+	if code := request(); code != http.StatusGatewayTimeout {
+		t.Fatalf("primeira requisição: status %d, esperado 504", code)
+	}
+	if code := request(); code != http.StatusServiceUnavailable {
+		t.Fatalf("com a etapa abandonada em andamento: status %d, esperado 503", code)
+	}
+
+	close(release)
+	<-finished
+	deadline := time.Now().Add(time.Second)
+	for len(s.slots) > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if len(s.slots) > 0 {
+		t.Fatal("a vaga não foi liberada depois que a etapa terminou")
+	}
+}
+
+func TestRunPhaseSkipsCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ran := false
+	if err := runPhase(ctx, func() error { ran = true; return nil }); err != context.Canceled {
+		t.Errorf("erro %v, esperado context.Canceled", err)
+	}
+	if ran {
+		t.Error("a etapa não deveria executar com o contexto já cancelado")
+	}
+}
+
+// Requisições simultâneas, canceladas no meio da indexação, não interferem nas seguintes
+// (rode com -race)
+func TestServeConcurrentTimeouts(t *testing.T) {
+	spec := mustReadFile(t, "testdata/e2e/valid/api.yaml")
+	timedOut := newTestServer(t, time.Millisecond, 4)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			timedOut.handler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(spec)))
+		}()
+	}
+	wg.Wait()
+
+	w := httptest.NewRecorder()
+	newTestServer(t, time.Minute, 4).handler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(spec)))
+	if w.Code != http.StatusOK {
+		t.Errorf("status %d, esperado 200: %s", w.Code, w.Body.String())
+	}
+}
+
+// As requisições não deixam estado no processo: as violações parciais de uma
+// validação ficam com ela, e o acompanhamento da CLI não cresce a cada requisição
+func TestServeDoesNotAccumulateState(t *testing.T) {
+	spec := mustReadFile(t, "testdata/e2e/violations/api.yaml")
+	handler := newTestServer(t, time.Minute, 4).handler()
+	for i := 0; i < 50; i++ {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(spec)))
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("requisição %d: status %d, esperado 422: %s", i, w.Code, w.Body.String())
+		}
+	}
+	tracker.mu.Lock()
+	partial, phase := len(tracker.partial), tracker.phase
+	tracker.mu.Unlock()
+	if partial > 0 || phase != "" {
+		t.Errorf("o acompanhamento da CLI guardou %d violações e a fase %q das requisições", partial, phase)
+	}
+}
+
+// serve --encoding vale para as especificações recebidas: as aspas curvas do
+// Windows-1252 só chegam ao documento resolvido com a codificação informada; na
+// detecção automática, viram caracteres de controle do ISO-8859-1, recusados pelo YAML
+func TestServeEncoding(t *testing.T) {
+	spec, err := charmap.Windows1252.NewEncoder().Bytes([]byte("openapi: 3.0.3\ninfo: {title: “Aspas”, version: 1.0.0}\npaths: {}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		encoding string
+		status   int
+	}{{"windows-1252", http.StatusOK}, {"auto", http.StatusBadRequest}} {
+		s, err := newSpecServer(&serveOptions{rulesets: stringList{"ofb"}, maxBody: defaultMaxBody, timeout: time.Minute, maxConcurrent: 1, encoding: tt.encoding})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.log = io.Discard
+		rec := httptest.NewRecorder()
+		s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/resolve", bytes.NewReader(spec)))
+		if rec.Code != tt.status {
+			t.Fatalf("--encoding %s: status %d, esperado %d\n%s", tt.encoding, rec.Code, tt.status, rec.Body.String())
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		var response resolveResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(response.Document, "“Aspas”") {
+			t.Errorf("--encoding %s: aspas não convertidas\n%s", tt.encoding, response.Document)
+		}
+	}
+
+	if _, err := Validate(context.Background(), spec, Options{Rules: []byte("rules: {}\n"), Encoding: "ebcdic"}); err == nil || !strings.Contains(err.Error(), "valor inválido para Options.Encoding") {
+		t.Errorf("Options.Encoding inválida: %v", err)
+	}
+}
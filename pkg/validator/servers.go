@@ -0,0 +1,157 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Extensão que justifica servers próprios em um path ou operação quando as opções não dizem
+const defaultServerJustification = "x-server-justification"
+
+// Opções da função servers
+type serverRequirement struct {
+	justification string         // extensão que libera servers próprios no path ou na operação
+	suffix        *regexp.Regexp // estrutura que o fim de todas as URLs precisa seguir; nulo não confere
+	suffixText    string
+}
+
+// Servidor declarado, com o nível em que apareceu
+type declaredServer struct {
+	url   string // com as variáveis substituídas pelos valores default
+	node  *yaml.Node
+	path  string
+	level string // raiz, do path /x ou da operação GET /x
+}
+
+// Função para ler as opções da função servers: a extensão que justifica servers no path
+// ou na operação (padrão: x-server-justification) e a expressão regular do fim comum das
+// URLs (suffix, sem $: a ferramenta ancora no fim)
+//
+//	then:
+//	  function: servers
+//	  functionOptions:
+//	    justification: x-server-justification
+//	    suffix: "/open-banking/[a-z0-9-]+/v[0-9]+"
+func parseServerOptions(options map[string]interface{}) (*serverRequirement, []string) {
+	req := &serverRequirement{justification: defaultServerJustification}
+	var problems []string
+	if raw, ok := options["justification"]; ok {
+		name, isString := raw.(string)
+		if !isString || !strings.HasPrefix(name, "x-") {
+			problems = append(problems, fmt.Sprintf("functionOptions.justification deve ser o nome de uma extensão (x-...), encontrado %v", raw))
+		} else {
+			req.justification = name
+		}
+	}
+	if raw, ok := options["suffix"]; ok {
+		expr, isString := raw.(string)
+		if !isString {
+			problems = append(problems, fmt.Sprintf("functionOptions.suffix deve ser uma expressão regular em texto, encontrado %s", ruleValueType(raw)))
+		} else if re, err := regexp.Compile("(?:" + strings.TrimSuffix(expr, "$") + ")/?$"); err != nil {
+			problems = append(problems, fmt.Sprintf("functionOptions.suffix %q não é uma expressão regular válida: %v", expr, err))
+		} else {
+			req.suffix, req.suffixText = re, expr
+		}
+	}
+	return req, problems
+}
+
+// Função para conferir os servers do documento (given $): servers no path ou na operação
+// diferentes dos da raiz precisam da extensão de justificativa no próprio nível ou no
+// path, e com suffix todas as URLs precisam terminar na estrutura e no mesmo trecho.
+// As mensagens dizem o nível em que o servidor foi declarado.
+func (r *compiledRule) serverViolations(m pathMatch) []Violation {
+	doc := m.Node
+	if doc == nil || doc.Kind != yaml.MappingNode {
+		return nil
+	}
+	description := strings.TrimSuffix(r.description, ".")
+	root := collectServers(mappingValue(doc, "servers"), joinPath(m.Path, "servers"), "raiz")
+	rootURLs := map[string]bool{}
+	for _, s := range root {
+		rootURLs[s.url] = true
+	}
+	all := append([]declaredServer{}, root...)
+
+	var violations []Violation
+	override := func(owner *yaml.Node, justified bool, servers []declaredServer) {
+		all = append(all, servers...)
+		if justified || isTruthy(mappingValue(owner, r.servers.justification)) {
+			return
+		}
+		for _, s := range servers {
+			if rootURLs[s.url] {
+				continue
+			}
+			v := r.violation(s.path, s.node.Line, s.node)
+			v.Message = fmt.Sprintf("%s: o servidor %s foi declarado no nível %s e não está entre os servers da raiz; justifique com %s ou use os servers da raiz.", description, s.url, s.level, r.servers.justification)
+			violations = append(violations, v)
+		}
+	}
+	forEachEntry(mappingValue(doc, "paths"), func(route string, item *yaml.Node) {
+		itemPath := joinPath(joinPath(m.Path, "paths"), route)
+		override(item, false, collectServers(mappingValue(item, "servers"), joinPath(itemPath, "servers"), "do path "+route))
+		justified := isTruthy(mappingValue(item, r.servers.justification))
+		forEachEntry(item, func(method string, operation *yaml.Node) {
+			if !httpMethods[method] {
+				return
+			}
+			level := "da operação " + strings.ToUpper(method) + " " + route
+			override(operation, justified, collectServers(mappingValue(operation, "servers"), joinPath(joinPath(itemPath, method), "servers"), level))
+		})
+	})
+
+	if r.servers.suffix == nil {
+		return violations
+	}
+	// O trecho final de referência é o do primeiro servidor que segue a estrutura
+	// (normalmente o da raiz); os que terminam de outro jeito são relatados
+	reference := ""
+	for _, s := range all {
+		if loc := r.servers.suffix.FindStringIndex(s.url); loc != nil {
+			reference = strings.TrimSuffix(s.url[loc[0]:loc[1]], "/")
+			break
+		}
+	}
+	for _, s := range all {
+		loc := r.servers.suffix.FindStringIndex(s.url)
+		var problem string
+		switch {
+		case loc == nil:
+			problem = fmt.Sprintf("não termina na estrutura esperada (%s)", r.servers.suffixText)
+		case strings.TrimSuffix(s.url[loc[0]:loc[1]], "/") != reference:
+			problem = fmt.Sprintf("termina em %s, diferente de %s dos demais servidores", strings.TrimSuffix(s.url[loc[0]:loc[1]], "/"), reference)
+		default:
+			continue
+		}
+		v := r.violation(s.path, s.node.Line, s.node)
+		v.Message = fmt.Sprintf("%s: o servidor %s, declarado no nível %s, %s.", description, s.url, s.level, problem)
+		violations = append(violations, v)
+	}
+	return violations
+}
+
+// Servidores de uma lista servers, com as variáveis trocadas pelos valores default
+func collectServers(list *yaml.Node, path, level string) []declaredServer {
+	if list == nil || list.Kind != yaml.SequenceNode {
+		return nil
+	}
+	var servers []declaredServer
+	for i, server := range list.Content {
+		url := mappingValue(server, "url")
+		if url == nil || url.Kind != yaml.ScalarNode {
+			continue
+		}
+		expanded := url.Value
+		forEachEntry(mappingValue(server, "variables"), func(name string, variable *yaml.Node) {
+			if def := mappingValue(variable, "default"); def != nil {
+				expanded = strings.ReplaceAll(expanded, "{"+name+"}", def.Value)
+			}
+		})
+		servers = append(servers, declaredServer{url: expanded, node: url, path: joinPath(fmt.Sprintf("%s[%d]", path, i), "url"), level: level})
+	}
+	return servers
+}
@@ -0,0 +1,72 @@
+package validator
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const serversSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+servers:
+  - url: https://api.banco.com.br/open-banking/accounts/v2
+  - url: https://{ambiente}.banco.com.br/open-banking/accounts/v2/
+    variables:
+      ambiente: {default: sandbox}
+paths:
+  /contas:
+    servers:
+      - url: https://api.banco.com.br/open-banking/accounts/v2
+    get:
+      servers:
+        - url: https://legado.banco.com.br/open-banking/accounts/v1
+      responses: {'200': {description: ok}}
+  /cartoes:
+    x-server-justification: cartões ficam em outro domínio
+    get:
+      servers:
+        - url: https://cartoes.banco.com.br/api
+      responses: {'200': {description: ok}}
+`
+
+// Servers no path ou na operação fora dos da raiz precisam de justificativa; a do path
+// vale para as operações dele. Com suffix, todas as URLs (com as variáveis trocadas
+// pelo default) precisam terminar na estrutura e no mesmo trecho.
+func TestServersConsistency(t *testing.T) {
+	rules := "rules:\n  servidores:\n    given: $\n    then: {function: servers}\n"
+	found := ruleViolations(t, serversSpec, rules, "servidores")
+	if len(found) != 1 || !strings.Contains(found[0].Message, "o servidor https://legado.banco.com.br/open-banking/accounts/v1 foi declarado no nível da operação GET /contas") || found[0].Line != 14 {
+		t.Fatalf("esperada só a violação do servidor de GET /contas na linha 14: %v", found)
+	}
+
+	rules = "rules:\n  servidores:\n    given: $\n    then: {function: servers, functionOptions: {suffix: \"/open-banking/[a-z-]+/v[0-9]+\"}}\n"
+	found = ruleViolations(t, serversSpec, rules, "servidores")
+	want := []string{
+		"o servidor https://legado.banco.com.br/open-banking/accounts/v1 foi declarado no nível da operação GET /contas",
+		"o servidor https://legado.banco.com.br/open-banking/accounts/v1, declarado no nível da operação GET /contas, termina em /open-banking/accounts/v1, diferente de /open-banking/accounts/v2",
+		"o servidor https://cartoes.banco.com.br/api, declarado no nível da operação GET /cartoes, não termina na estrutura esperada",
+	}
+	if len(found) != len(want) {
+		t.Fatalf("esperadas %d violações, encontradas %d: %v", len(want), len(found), found)
+	}
+	for i, v := range found {
+		if !strings.Contains(v.Message, want[i]) {
+			t.Errorf("violação %d: %q, esperado %q", i, v.Message, want[i])
+		}
+	}
+}
+
+// Opções inválidas da função servers são erro de uso
+func TestServersOptions(t *testing.T) {
+	for options, message := range map[string]string{
+		"{justification: justificativa}": "functionOptions.justification deve ser o nome de uma extensão",
+		"{suffix: 5}":                    "functionOptions.suffix deve ser uma expressão regular em texto",
+		`{suffix: "/v[0-9"}`:             `functionOptions.suffix "/v[0-9" não é uma expressão regular válida`,
+	} {
+		rules := "rules:\n  a:\n    given: $\n    then: {function: servers, functionOptions: " + options + "}\n"
+		_, err := Validate(context.Background(), []byte(serversSpec), Options{Source: "api.yaml", Rules: []byte(rules)})
+		if err == nil || rulesExitCode(err) != exitUsage || !strings.Contains(err.Error(), message) {
+			t.Errorf("%s: erro %v, esperado erro de uso com %q", options, err, message)
+		}
+	}
+}
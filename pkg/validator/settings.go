@@ -0,0 +1,182 @@
+package validator
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Configuração de uma execução, lida pelas etapas de leitura, indexação, resolução e
+// validação. A CLI monta a sua a partir das flags (flagSettings); cada chamada da API
+// monta uma a partir de Options, com os padrões das flags (defaultSettings). Essas
+// etapas não leem as flags diretamente: uma etapa abandonada depois de um
+// cancelamento continua com a própria configuração, sem afetar a chamada seguinte.
+// Só a gravação dos artefatos da CLI (--max-depth, --strip, --max-output-size,
+// --ref-report) e o cache de resultados continuam lendo as flags.
+type runSettings struct {
+	// Sistema de arquivos de onde vêm as entradas: especificações, arquivos de $refs,
+	// regras, extends e overlays. nil lê do disco; com Options.FS, os caminhos locais
+	// são relativos a root, que corresponde à raiz (".") do sistema de arquivos.
+	fsys fs.FS
+	root string
+	// Especificações recebidas em memória, pelo caminho absoluto
+	inline map[string][]byte
+
+	encoding         string    // --encoding
+	encodingWarned   *sync.Map // arquivos cuja conversão de codificação já foi avisada
+	maxFileSize      byteSize  // --max-file-size (0 = sem limite)
+	baseDir          string    // --base-dir
+	allowRemoteRefs  bool
+	noCache          bool
+	validateExamples bool
+	strictYAML       bool
+	strictRules      bool
+	document         int      // --select-document
+	overlays         []string // --overlay
+	overlayUnmatched string   // --overlay-unmatched
+	noConvert        bool     // --no-convert
+
+	// Busca dos $refs remotos
+	remoteTimeout time.Duration // --remote-timeout
+	httpHeaders   []string      // --http-header
+	httpRetries   int           // --http-retries
+	offline       bool          // --offline
+
+	// Resolução
+	keepRefs       []string // --keep-refs
+	refUsage       bool     // registrar o uso das referências (--ref-report)
+	failOnCircular bool     // --fail-on-circular
+
+	// Conferências da validação
+	profile            string  // --profile
+	pathConflicts      string  // --path-conflicts
+	pathOverlaps       string  // --path-overlaps
+	versionConsistency string  // --version-consistency
+	versionPathPattern string  // --version-path-pattern
+	versionPrerelease  string  // --version-prerelease
+	inlineReuseLimit   int     // --inline-reuse
+	extensions         string  // --extensions
+	unregisteredExts   string  // --unregistered-extensions
+	maxSchemaDepth     int     // --max-schema-depth
+	maxProperties      int     // --max-properties
+	duplicates         string  // --duplicates
+	examplesMatrix     bool    // --examples-matrix
+	examplesMinCover   float64 // --examples-min-coverage
+	examplesSeverity   string  // --examples-coverage-severity
+	coverage           bool    // --coverage
+	ruleConcurrency    int     // --concurrency
+
+	// Links conferidos pela rede (--check-links)
+	checkLinks       bool
+	linkTimeout      time.Duration
+	linkConcurrency  int
+	linkAllowedHosts []string
+
+	// Regiões alteradas em relação à versão anterior (--changed-only); nil valida tudo
+	changedScope *changedScope
+
+	log      io.Writer         // avisos e mensagens informativas (stderr na CLI)
+	progress *progressReporter // progresso da indexação e da resolução; nil não mostra
+	tracker  *runTracker       // fase e resultados parciais; nil não acompanha
+}
+
+// Configuração com os padrões das flags, capturada antes de qualquer linha de comando;
+// é a base das chamadas da API
+var defaultSettings = *flagSettings()
+
+// Configuração das flags da linha de comando
+func flagSettings() *runSettings {
+	return &runSettings{
+		encoding:         inputEncoding,
+		encodingWarned:   cliEncodingWarned,
+		maxFileSize:      maxFileSize,
+		baseDir:          refsBaseDir,
+		allowRemoteRefs:  allowRemoteRefs,
+		noCache:          noCache,
+		validateExamples: validateExamples,
+		strictYAML:       strictYAML,
+		strictRules:      strictRules,
+		document:         selectDocument,
+		overlays:         overlayFiles,
+		overlayUnmatched: overlayUnmatched,
+		noConvert:        noConvert,
+
+		remoteTimeout: remoteTimeout,
+		httpHeaders:   httpHeaders,
+		httpRetries:   httpRetries,
+		offline:       offline,
+
+		keepRefs:       keepRefs,
+		refUsage:       refReportFile != "",
+		failOnCircular: failOnCircular,
+
+		profile:            severityProfile,
+		pathConflicts:      pathConflicts,
+		pathOverlaps:       pathOverlaps,
+		versionConsistency: versionConsistency,
+		versionPathPattern: versionPathPattern,
+		versionPrerelease:  versionPrerelease,
+		inlineReuseLimit:   inlineReuseLimit,
+		extensions:         extensionsFile,
+		unregisteredExts:   unregisteredExtensions,
+		maxSchemaDepth:     maxSchemaDepth,
+		maxProperties:      maxProperties,
+		duplicates:         duplicateSchemas,
+		examplesMatrix:     examplesMatrix,
+		examplesMinCover:   examplesMinCoverage,
+		examplesSeverity:   examplesCoverageSeverity,
+		coverage:           reportCoverage,
+		ruleConcurrency:    ruleConcurrency,
+
+		checkLinks:       checkLinks,
+		linkTimeout:      linkTimeout,
+		linkConcurrency:  linkConcurrency,
+		linkAllowedHosts: linkAllowedHosts,
+
+		log:      stderr,
+		progress: newProgressReporter(stderr, progressMode),
+		tracker:  tracker,
+	}
+}
+
+// Cópia da configuração, para uma etapa que muda algum campo (ex.: a releitura da saída)
+func (s *runSettings) with(change func(*runSettings)) *runSettings {
+	copied := *s
+	change(&copied)
+	return &copied
+}
+
+// Especificação recebida em memória para o caminho, se houver
+func (s *runSettings) inlineSource(path string) ([]byte, bool) {
+	if len(s.inline) == 0 {
+		return nil, false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, false
+	}
+	data, ok := s.inline[abs]
+	return data, ok
+}
+
+// Diretório base dos $refs relativos do arquivo raiz: --base-dir ou o diretório dele
+func (s *runSettings) refsDir(rootAbs string) string {
+	if s.baseDir != "" {
+		if dir, err := filepath.Abs(s.baseDir); err == nil {
+			return dir
+		}
+	}
+	return filepath.Dir(rootAbs)
+}
+
+// Diretório correspondente à raiz de Options.FS: o diretório atual
+func workingRoot() string {
+	root, err := os.Getwd()
+	if err != nil {
+		return string(filepath.Separator)
+	}
+	return root
+}
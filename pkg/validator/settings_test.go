@@ -0,0 +1,56 @@
+package validator
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const conflictingPathsSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas/{contaId}:
+    get:
+      responses:
+        '200': {description: ok}
+  /contas/{id}:
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Conta'}
+components:
+  schemas:
+    Conta: {type: object}
+`
+
+// As chamadas da API usam os padrões das flags, e não os valores deixados por uma
+// linha de comando no mesmo processo
+func TestAPIIgnoresCommandLineFlags(t *testing.T) {
+	savedConflicts, savedKeep, savedMode := pathConflicts, keepRefs, progressMode
+	defer func() { pathConflicts, keepRefs, progressMode = savedConflicts, savedKeep, savedMode }()
+	pathConflicts, keepRefs, progressMode = "off", stringList{"#/components/schemas/*"}, "log"
+
+	files := MemFS{"api.yaml": []byte(conflictingPathsSpec)}
+	result, err := Validate(context.Background(), nil, Options{Source: "api.yaml", FS: files, Rules: []byte("rules: {}\n")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, v := range result.Violations {
+		found = found || v.RuleID == "path-conflict"
+	}
+	if !found {
+		t.Errorf("o conflito de paths deveria ser relatado com o padrão de --path-conflicts: %+v", result.Violations)
+	}
+
+	resolved, err := Resolve(context.Background(), nil, Options{Source: "api.yaml", FS: files})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(resolved.Document), "$ref") {
+		t.Errorf("--keep-refs da linha de comando não deveria valer para a API:\n%s", resolved.Document)
+	}
+}
@@ -0,0 +1,330 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Arquivo de snapshot usado quando --file não é informado
+const defaultSnapshotFile = "contract-snapshot.yaml"
+
+// Flags do subcomando snapshot
+type snapshotOptions struct {
+	file       string
+	operations stringList
+	timeout    time.Duration
+}
+
+func (o *snapshotOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.file, "file", defaultSnapshotFile, "arquivo do snapshot, gravado por create e lido por verify")
+	fs.Var(&o.operations, "operations", "operações incluídas no snapshot (create), como \"METHOD path\" separadas por vírgula; pode ser repetida")
+	fs.DurationVar(&o.timeout, "timeout", defaultTimeout, "tempo máximo da execução (0 desativa)")
+	registerRefFlags(fs)
+	registerHTTPFlags(fs)
+	registerEncodingFlag(fs)
+	registerSelectDocumentFlag(fs)
+	registerInputLimitFlag(fs)
+	registerOverlayFlags(fs)
+	registerConvertFlag(fs)
+}
+
+var snapshotCommand = &command{
+	Name:    "snapshot",
+	Args:    "create --operations <lista> <spec.yaml> | verify <spec.yaml>",
+	Summary: "congela e confere os schemas de operações críticas",
+	Description: `'snapshot create' resolve a especificação e grava em --file um hash de cada
+schema das operações de --operations: parâmetros (do path item e da
+operação), schema da requisição por media type e, para cada status, o schema
+da resposta por media type e os cabeçalhos. Os hashes são da forma canônica
+do documento resolvido: a ordem das chaves, os comentários e o estilo do YAML
+não contam, mas qualquer outra mudança, inclusive de descrição, conta.
+
+'snapshot verify' recalcula os hashes das operações do snapshot e falha
+quando algum mudou, indicando cada schema alterado, acrescentado ou
+removido. O arquivo do snapshot deve ser versionado junto com a
+especificação e regravado com create quando a mudança for intencional.`,
+	Examples: []string{
+		programName + " snapshot create --operations 'POST /payments/v3/pix/payments' swagger.yaml",
+		programName + " snapshot create --operations 'GET /accounts/v2/accounts,GET /accounts/v2/accounts/{accountId}' --file contratos.yaml swagger.yaml",
+		programName + " snapshot verify swagger.yaml",
+	},
+	Flags: func(fs *flag.FlagSet) { new(snapshotOptions).register(fs) },
+	Run:   runSnapshot,
+}
+
+// Conteúdo do arquivo de snapshot: os hashes dos schemas de cada operação
+type contractSnapshot struct {
+	Spec       string                       `yaml:"spec"`
+	Operations map[string]map[string]string `yaml:"operations"`
+}
+
+// Subcomando snapshot: create ou verify
+func runSnapshot(c *command, args []string) int {
+	if len(args) == 0 {
+		return c.usageError("esperada uma ação: create ou verify")
+	}
+	action := args[0]
+	if action != "create" && action != "verify" {
+		return c.usageError("ação %q inválida para snapshot (use create ou verify)", action)
+	}
+	opts := &snapshotOptions{}
+	fs := c.newFlagSet()
+	opts.register(fs)
+	if code, ok := c.parse(fs, args[1:]); !ok {
+		return code
+	}
+	if err := checkOverlayFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkHTTPFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkEncodingFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkSelectDocumentFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if fs.NArg() != 1 {
+		return c.usageError("esperado um argumento (especificação), recebidos %d", fs.NArg())
+	}
+	var operations []string
+	for _, value := range opts.operations {
+		for _, label := range strings.Split(value, ",") {
+			if label = strings.Join(strings.Fields(label), " "); label != "" {
+				operations = append(operations, label)
+			}
+		}
+	}
+	switch {
+	case action == "create" && len(operations) == 0:
+		return c.usageError("snapshot create exige --operations")
+	case action == "verify" && len(operations) > 0:
+		return c.usageError("--operations só vale com snapshot create; verify confere as operações do snapshot")
+	}
+
+	ctx, cancel := newRunContext(opts.timeout)
+	defer cancel()
+	inputFile := fs.Arg(0)
+	spec, code := resolveForSnapshot(ctx, inputFile, opts.timeout)
+	if spec == nil {
+		return code
+	}
+	if action == "create" {
+		return createSnapshot(&spec.rootNode, inputFile, operations, opts.file)
+	}
+	return verifySnapshot(&spec.rootNode, inputFile, opts.file)
+}
+
+// Resolve a especificação; com referências quebradas os hashes não seriam os do contrato
+func resolveForSnapshot(ctx context.Context, inputFile string, timeout time.Duration) (*resolvedSpec, int) {
+	spec, err := indexAndResolve(ctx, flagSettings(), inputFile)
+	if err != nil {
+		if isCancellation(err) {
+			reportCancellation(err, timeout)
+			return nil, exitFailure
+		}
+		fmt.Fprintln(stdout, "❌ Erro ao processar", inputFile+":", err)
+		return nil, exitFailure
+	}
+	if len(spec.report.Errors) > 0 {
+		for _, e := range spec.report.Errors {
+			fmt.Fprintln(stdout, "❌", e)
+		}
+		fmt.Fprintln(stdout, "❌ A especificação tem referências não resolvidas; os schemas não foram conferidos.")
+		return nil, exitFailure
+	}
+	return spec, exitOK
+}
+
+// Operações do documento pelo rótulo "METHOD path" (webhooks como "METHOD webhook:nome")
+func snapshotOperations(root *yaml.Node) (map[string]*yaml.Node, map[string]*yaml.Node, []string) {
+	doc := documentContent(root)
+	operations, items := map[string]*yaml.Node{}, map[string]*yaml.Node{}
+	var labels []string
+	for _, op := range listOperations(root) {
+		section := "paths"
+		if op.Webhook {
+			section = "webhooks"
+		}
+		item := mappingValue(mappingValue(doc, section), op.Path)
+		label := strings.ToUpper(op.Method) + " " + op.displayPath()
+		operations[label], items[label] = mappingValue(item, op.Method), item
+		labels = append(labels, label)
+	}
+	return operations, items, labels
+}
+
+// Função para calcular o hash da forma canônica de cada schema da operação, pela
+// localização: request.parameters.<in>.<nome>, request.body.<media type>,
+// responses.<status>.content.<media type> e responses.<status>.headers.<nome>
+// (responses.<status>.schema no Swagger 2.0 sem conversão)
+func operationSchemaHashes(item, operation *yaml.Node) map[string]string {
+	canonical := newSchemaCanonicalizer(false)
+	hashes := map[string]string{}
+	add := func(location string, node *yaml.Node) {
+		if node != nil {
+			hashes[location] = "sha256:" + contentHash([]byte(canonical.form(node, false)))
+		}
+	}
+	// Os parâmetros da operação substituem os do path item com o mesmo nome e local
+	for _, holder := range []*yaml.Node{item, operation} {
+		if params := mappingValue(holder, "parameters"); params != nil && params.Kind == yaml.SequenceNode {
+			for _, param := range params.Content {
+				name, in := mappingValue(param, "name"), mappingValue(param, "in")
+				if name != nil && in != nil {
+					add("request.parameters."+in.Value+"."+name.Value, param)
+				}
+			}
+		}
+	}
+	forEachEntry(mappingValue(mappingValue(operation, "requestBody"), "content"), func(media string, node *yaml.Node) {
+		add("request.body."+media, mappingValue(node, "schema"))
+	})
+	forEachEntry(mappingValue(operation, "responses"), func(status string, response *yaml.Node) {
+		add("responses."+status+".schema", mappingValue(response, "schema"))
+		forEachEntry(mappingValue(response, "content"), func(media string, node *yaml.Node) {
+			add("responses."+status+".content."+media, mappingValue(node, "schema"))
+		})
+		forEachEntry(mappingValue(response, "headers"), func(name string, node *yaml.Node) {
+			add("responses."+status+".headers."+name, node)
+		})
+	})
+	return hashes
+}
+
+// Percorre as chaves de um mapping YAML, na ordem do documento
+func forEachEntry(node *yaml.Node, visit func(key string, value *yaml.Node)) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		visit(node.Content[i].Value, node.Content[i+1])
+	}
+}
+
+// Modo create: grava os hashes das operações informadas
+func createSnapshot(root *yaml.Node, inputFile string, labels []string, file string) int {
+	operations, items, known := snapshotOperations(root)
+	snapshot := contractSnapshot{Spec: reportPath(inputFile), Operations: map[string]map[string]string{}}
+	for _, label := range labels {
+		key := normalizeOperationLabel(label)
+		operation := operations[key]
+		if operation == nil {
+			fmt.Fprintf(stdout, "❌ Operação %s não encontrada em %s%s\n", label, inputFile, suggestionSuffix(key, known))
+			return exitFailure
+		}
+		snapshot.Operations[key] = operationSchemaHashes(items[key], operation)
+	}
+	var buf bytes.Buffer
+	buf.WriteString("# Gerado por " + programName + " snapshot create; confira com " + programName + " snapshot verify\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(snapshot); err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao gerar o snapshot:", err)
+		return exitFailure
+	}
+	enc.Close()
+	if err := writeFileAtomic(file, buf.Bytes()); err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao salvar o snapshot:", err)
+		return exitFailure
+	}
+	schemas := 0
+	for _, hashes := range snapshot.Operations {
+		schemas += len(hashes)
+	}
+	fmt.Fprintf(stdout, "📄 Snapshot de %d operações (%d schemas) salvo em: %s\n", len(snapshot.Operations), schemas, file)
+	return exitOK
+}
+
+// Modo verify: recalcula os hashes e aponta os schemas que mudaram
+func verifySnapshot(root *yaml.Node, inputFile, file string) int {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao ler o snapshot:", err)
+		return exitFailure
+	}
+	var snapshot contractSnapshot
+	if err := yaml.Unmarshal(data, &snapshot); err != nil {
+		fmt.Fprintf(stdout, "❌ O snapshot %s não é um YAML válido: %v\n", file, err)
+		return exitFailure
+	}
+	if len(snapshot.Operations) == 0 {
+		fmt.Fprintf(stdout, "❌ O snapshot %s não tem operações; gere-o com snapshot create.\n", file)
+		return exitFailure
+	}
+
+	operations, items, _ := snapshotOperations(root)
+	labels := make([]string, 0, len(snapshot.Operations))
+	for label := range snapshot.Operations {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	changed := 0
+	for _, label := range labels {
+		operation := operations[label]
+		if operation == nil {
+			fmt.Fprintf(stdout, "❌ %s: a operação não existe mais em %s\n", label, inputFile)
+			changed++
+			continue
+		}
+		expected, current := snapshot.Operations[label], operationSchemaHashes(items[label], operation)
+		var diffs []string
+		for _, location := range sortedKeys(hashLocations(expected, current)) {
+			before, had := expected[location]
+			after, has := current[location]
+			switch {
+			case !had:
+				diffs = append(diffs, "+ "+location+" (acrescentado)")
+			case !has:
+				diffs = append(diffs, "- "+location+" (removido)")
+			case before != after:
+				diffs = append(diffs, "~ "+location+" (alterado)")
+			}
+		}
+		if len(diffs) == 0 {
+			fmt.Fprintf(stdout, "✅ %s: %d schemas iguais ao snapshot\n", label, len(expected))
+			continue
+		}
+		changed++
+		fmt.Fprintf(stdout, "❌ %s: %d schemas mudaram\n", label, len(diffs))
+		for _, diff := range diffs {
+			fmt.Fprintln(stdout, "   "+diff)
+		}
+	}
+	if changed > 0 {
+		fmt.Fprintf(stdout, "🔎 %d de %d operações mudaram em relação a %s; se a mudança é intencional, regrave com snapshot create.\n", changed, len(labels), file)
+		return exitFailure
+	}
+	fmt.Fprintf(stdout, "🔎 %d operações iguais ao snapshot %s.\n", len(labels), file)
+	return exitOK
+}
+
+// Localizações presentes no snapshot ou na especificação
+func hashLocations(a, b map[string]string) map[string]bool {
+	set := map[string]bool{}
+	for location := range a {
+		set[location] = true
+	}
+	for location := range b {
+		set[location] = true
+	}
+	return set
+}
+
+// Rótulo "METHOD path" com o método em maiúsculas e um único espaço
+func normalizeOperationLabel(label string) string {
+	method, path, found := strings.Cut(label, " ")
+	if !found {
+		return label
+	}
+	return strings.ToUpper(method) + " " + strings.TrimSpace(path)
+}
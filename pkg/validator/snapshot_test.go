@@ -0,0 +1,80 @@
+package validator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const snapshotSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas/{contaId}:
+    parameters:
+      - {name: contaId, in: path, required: true, schema: {type: string}}
+    get:
+      responses:
+        '200':
+          description: ok
+          headers:
+            x-fapi-interaction-id: {schema: {type: string}}
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Conta'}
+components:
+  schemas:
+    Conta:
+      type: object
+      properties:
+        numero: {type: string}
+`
+
+// Função para gravar a especificação e executar o subcomando snapshot, retornando o
+// código de saída e a saída padrão
+func runSnapshotCommand(t *testing.T, dir, spec string, args ...string) (int, string) {
+	t.Helper()
+	writeFiles(t, dir, map[string]string{"api.yaml": spec})
+	return runCommand(t, append(append([]string{"snapshot"}, args...), "--file", filepath.Join(dir, "snapshot.yaml"), filepath.Join(dir, "api.yaml"))...)
+}
+
+// verify passa com a mesma especificação, mesmo reescrita em outra ordem e estilo, e
+// aponta o schema alterado, acrescentado ou removido
+func TestSnapshotVerify(t *testing.T) {
+	dir := t.TempDir()
+	if code, out := runSnapshotCommand(t, dir, snapshotSpec, "create", "--operations", "get /contas/{contaId}"); code != exitOK {
+		t.Fatalf("create: código %d\n%s", code, out)
+	}
+	restyled := strings.Replace(snapshotSpec, "numero: {type: string}", "numero:\n          type: string", 1)
+	if code, out := runSnapshotCommand(t, dir, restyled, "verify"); code != exitOK || !strings.Contains(out, "GET /contas/{contaId}: 3 schemas iguais ao snapshot") {
+		t.Fatalf("verify com a mesma especificação: código %d\n%s", code, out)
+	}
+
+	tests := []struct {
+		name, from, to, diff string
+	}{
+		{"schema referenciado", "numero: {type: string}", "numero: {type: integer}", "~ responses.200.content.application/json (alterado)"},
+		{"cabeçalho removido", "          headers:\n            x-fapi-interaction-id: {schema: {type: string}}\n", "", "- responses.200.headers.x-fapi-interaction-id (removido)"},
+		{"parâmetro acrescentado", "    get:\n", "    get:\n      parameters:\n        - {name: page, in: query, schema: {type: integer}}\n", "+ request.parameters.query.page (acrescentado)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, out := runSnapshotCommand(t, dir, strings.Replace(snapshotSpec, tt.from, tt.to, 1), "verify")
+			if code != exitFailure || !strings.Contains(out, tt.diff) {
+				t.Errorf("código %d, esperado %d com %q:\n%s", code, exitFailure, tt.diff, out)
+			}
+		})
+	}
+}
+
+// Operação inexistente no create é falha, com a sugestão da mais próxima; verify não
+// aceita --operations
+func TestSnapshotErrors(t *testing.T) {
+	dir := t.TempDir()
+	code, out := runSnapshotCommand(t, dir, snapshotSpec, "create", "--operations", "GET /contas/{contaID}")
+	if code != exitFailure || !strings.Contains(out, "GET /contas/{contaId}") {
+		t.Errorf("create com operação inexistente: código %d\n%s", code, out)
+	}
+	if code, out := runSnapshotCommand(t, dir, snapshotSpec, "verify", "--operations", "GET /contas"); code != exitUsage {
+		t.Errorf("verify com --operations: código %d, esperado %d\n%s", code, exitUsage, out)
+	}
+}
@@ -0,0 +1,411 @@
+package validator
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Regra do Spectral que não foi traduzida, com o motivo
+type spectralSkip struct {
+	rule   string // vazio para problemas do arquivo (extends, overrides...)
+	reason string
+	key    string // entrada do arquivo dos problemas sem regra, no formato de ruleLines
+}
+
+// Severidades numéricas e nomes do Spectral; -1 e off desativam a regra
+var spectralSeverities = map[interface{}]string{
+	0: "error", 1: "warning", 2: "info", 3: "hint",
+	"error": "error", "warn": "warning", "warning": "warning", "info": "info", "information": "info", "hint": "hint",
+}
+
+// Expressões equivalentes aos tipos da função casing do Spectral
+var spectralCasings = map[string]string{
+	"flat":   `^[a-z][a-z0-9]*$`,
+	"camel":  `^[a-z][a-zA-Z0-9]*$`,
+	"pascal": `^[A-Z][a-zA-Z0-9]*$`,
+	"kebab":  `^[a-z][a-z0-9]*(-[a-z0-9]+)*$`,
+	"cobol":  `^[A-Z][A-Z0-9]*(-[A-Z0-9]+)*$`,
+	"snake":  `^[a-z][a-z0-9]*(_[a-z0-9]+)*$`,
+	"macro":  `^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`,
+}
+
+// Indica se o conjunto de regras está no formato do Spectral: arquivo .spectral.*,
+// extends de um pacote spectral:, funções customizadas, aliases ou overrides, ou uma
+// regra bem formada do Spectral (given com expressões e then com function) que usa
+// algo que o formato próprio não tem: severidade numérica, given ou then em lista.
+// Regras malformadas (um número no lugar da regra, given sem then) não contam: seguem
+// para a validação do formato próprio, que as relata como erro de uso.
+func isSpectralRuleset(ruleset map[string]interface{}, source string) bool {
+	if strings.Contains(filepath.Base(source), ".spectral.") {
+		return true
+	}
+	for _, key := range []string{"functions", "functionsDir", "aliases", "overrides", "formats"} {
+		if _, ok := ruleset[key]; ok {
+			return true
+		}
+	}
+	for _, target := range spectralExtendsTargets(ruleset["extends"]) {
+		if strings.HasPrefix(target, "spectral:") {
+			return true
+		}
+	}
+	rules, _ := ruleset["rules"].(map[string]interface{})
+	for _, rule := range rules {
+		ruleData, ok := rule.(map[string]interface{})
+		if !ok || !isSpectralGiven(ruleData["given"]) || !isSpectralThen(ruleData["then"]) {
+			continue
+		}
+		_, numeric := ruleData["severity"].(int)
+		_, givenList := ruleData["given"].([]interface{})
+		_, thenList := ruleData["then"].([]interface{})
+		if numeric || givenList || thenList {
+			return true
+		}
+	}
+	return false
+}
+
+// Indica se o given é uma expressão ou uma lista não vazia de expressões
+func isSpectralGiven(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return v != ""
+	case []interface{}:
+		for _, item := range v {
+			if given, ok := item.(string); !ok || given == "" {
+				return false
+			}
+		}
+		return len(v) > 0
+	}
+	return false
+}
+
+// Indica se o then é um objeto com function ou uma lista não vazia deles
+func isSpectralThen(value interface{}) bool {
+	items, ok := value.([]interface{})
+	if !ok {
+		items = []interface{}{value}
+	}
+	for _, item := range items {
+		then, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if function, ok := then["function"].(string); !ok || function == "" {
+			return false
+		}
+	}
+	return len(items) > 0
+}
+
+// Alvos do extends do Spectral: um nome, uma lista de nomes ou pares [nome, "all"|"recommended"|"off"]
+func spectralExtendsTargets(value interface{}) []string {
+	var targets []string
+	items, ok := value.([]interface{})
+	if !ok {
+		items = []interface{}{value}
+	}
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			targets = append(targets, v)
+		case []interface{}:
+			if len(v) > 0 {
+				if name, ok := v[0].(string); ok && (len(v) < 2 || v[1] != "off") {
+					targets = append(targets, name)
+				}
+			}
+		}
+	}
+	return targets
+}
+
+// Função para traduzir um conjunto de regras do Spectral para o formato próprio: as
+// funções truthy, falsy, defined, undefined, pattern, schema, casing, enumeration e
+// length têm equivalente; regras com outras funções (incluindo as customizadas em JS) ou
+// com recursos sem equivalente ficam de fora e são relatadas. Um given ou then em lista
+// vira uma regra por combinação (nome, nome-2, nome-3...).
+func convertSpectralRuleset(ruleset map[string]interface{}) (map[string]interface{}, []spectralSkip) {
+	var skipped []spectralSkip
+	converted := map[string]interface{}{}
+
+	var extends []interface{}
+	builtin := false
+	for _, target := range spectralExtendsTargets(ruleset["extends"]) {
+		if strings.HasPrefix(target, "spectral:") {
+			skipped = append(skipped, spectralSkip{key: "extends:" + target, reason: fmt.Sprintf("extends %q ignorado: as regras embutidas do Spectral não estão disponíveis (use extends: ofb para o pacote do Open Finance Brasil)", target)})
+			builtin = true
+			continue
+		}
+		extends = append(extends, target)
+	}
+	if len(extends) > 0 {
+		converted["extends"] = extends
+	}
+	if _, ok := ruleset["overrides"]; ok {
+		skipped = append(skipped, spectralSkip{key: "overrides", reason: "overrides ignorado: não há equivalente para ajustar regras por arquivo (use scope nas regras)"})
+	}
+	custom := map[string]bool{}
+	if functions, ok := ruleset["functions"].([]interface{}); ok {
+		for _, f := range functions {
+			if name, ok := f.(string); ok {
+				custom[name] = true
+			}
+		}
+	}
+	aliases, _ := ruleset["aliases"].(map[string]interface{})
+
+	own, _ := ruleset["rules"].(map[string]interface{})
+	names := make([]string, 0, len(own))
+	for name := range own {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	rules := map[string]interface{}{}
+	// Ajuste de uma regra herdada; sem outros extends além dos do Spectral, só pode
+	// ser de uma regra embutida do Spectral, que não existe aqui
+	adjust := func(name string, value interface{}) {
+		if builtin && len(extends) == 0 {
+			skipped = append(skipped, spectralSkip{rule: name, reason: "ajusta uma regra embutida do Spectral, que não está disponível"})
+			return
+		}
+		rules[name] = value
+	}
+	for _, name := range names {
+		switch value := own[name].(type) {
+		case map[string]interface{}:
+		case bool:
+			// true/false ligam ou desligam uma regra herdada
+			if !value {
+				adjust(name, "off")
+			}
+			continue
+		default:
+			// Ajuste de severidade de uma regra herdada ("off", "warn", 1...)
+			if severity, ok := spectralSeverity(value); ok {
+				adjust(name, severity)
+			} else {
+				skipped = append(skipped, spectralSkip{rule: name, reason: fmt.Sprintf("severidade %v desconhecida", value)})
+			}
+			continue
+		}
+		ruleData := own[name].(map[string]interface{})
+		severity := "warning"
+		if raw, ok := ruleData["severity"]; ok {
+			var known bool
+			if severity, known = spectralSeverity(raw); !known {
+				skipped = append(skipped, spectralSkip{rule: name, reason: fmt.Sprintf("severidade %v desconhecida", raw)})
+				continue
+			}
+		}
+		if severity == "off" {
+			// Uma regra nova desativada não faz nada; sem given, desativa a herdada
+			if ruleData["given"] == nil {
+				adjust(name, "off")
+			}
+			continue
+		}
+		if ruleData["given"] == nil && ruleData["then"] == nil {
+			// Só a severidade ou a descrição: ajuste de uma regra herdada
+			adjust(name, map[string]interface{}{"severity": severity})
+			continue
+		}
+
+		givens, reason := spectralGivens(ruleData["given"], aliases)
+		if reason != "" {
+			skipped = append(skipped, spectralSkip{rule: name, reason: reason})
+			continue
+		}
+		thens, _ := ruleData["then"].([]interface{})
+		if thens == nil {
+			thens = []interface{}{ruleData["then"]}
+		}
+		var translated []map[string]interface{}
+		for _, raw := range thens {
+			then, reason := spectralThen(raw, custom)
+			if reason != "" {
+				skipped = append(skipped, spectralSkip{rule: name, reason: reason})
+				translated = nil
+				break
+			}
+			translated = append(translated, then)
+		}
+		if translated == nil {
+			continue
+		}
+
+		description, _ := ruleData["description"].(string)
+		if message, _ := ruleData["message"].(string); description == "" && !strings.Contains(message, "{{") {
+			// A mensagem só serve de descrição sem os marcadores {{...}} do Spectral
+			description = message
+		}
+		if description == "" {
+			// As regras próprias são relatadas pela descrição
+			description = fmt.Sprintf("Regra %s (Spectral)", name)
+		}
+		n := 0
+		for _, given := range givens {
+			for _, then := range translated {
+				n++
+				rule := map[string]interface{}{"given": given, "then": then, "severity": severity, "description": description}
+				if url, ok := ruleData["documentationUrl"].(string); ok {
+					rule["suggestion"] = "Veja " + url
+				}
+				ruleName := name
+				if n > 1 {
+					ruleName = fmt.Sprintf("%s-%d", name, n)
+				}
+				rules[ruleName] = rule
+			}
+		}
+	}
+	converted["rules"] = rules
+	return converted, skipped
+}
+
+// Severidade do Spectral no formato próprio; off para as regras desativadas
+func spectralSeverity(value interface{}) (string, bool) {
+	if value == -1 || value == "off" || value == false {
+		return "off", true
+	}
+	severity, ok := spectralSeverities[value]
+	return severity, ok
+}
+
+// Expressões do given, com os aliases (#Nome) trocados pelas expressões que representam
+func spectralGivens(value interface{}, aliases map[string]interface{}) ([]string, string) {
+	var raw []interface{}
+	switch v := value.(type) {
+	case string:
+		raw = []interface{}{v}
+	case []interface{}:
+		raw = v
+	default:
+		return nil, fmt.Sprintf("given deve ser uma expressão JSONPath ou uma lista, encontrado %s", ruleValueType(value))
+	}
+	var givens []string
+	for _, item := range raw {
+		given, ok := item.(string)
+		if !ok {
+			return nil, fmt.Sprintf("given deve ter expressões JSONPath em texto, encontrado %s", ruleValueType(item))
+		}
+		if strings.HasPrefix(given, "#") {
+			name, rest := given[1:], ""
+			if i := strings.IndexAny(name, ".["); i >= 0 {
+				name, rest = name[:i], name[i:]
+			}
+			targets, reason := spectralGivens(aliases[name], nil)
+			if aliases[name] == nil || reason != "" {
+				return nil, fmt.Sprintf("given usa o alias %s, que não existe ou usa targets por formato (não suportado)", given)
+			}
+			for _, target := range targets {
+				givens = append(givens, target+rest)
+			}
+			continue
+		}
+		if _, _, err := parseJSONPath(given); err != nil {
+			return nil, fmt.Sprintf("given %q usa recursos de JSONPath não suportados (%v)", given, err)
+		}
+		givens = append(givens, given)
+	}
+	return givens, ""
+}
+
+// Tradução de um then do Spectral; o motivo vem preenchido quando não há equivalente
+func spectralThen(value interface{}, custom map[string]bool) (map[string]interface{}, string) {
+	then, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Sprintf("then deve ser um objeto com function, encontrado %s", ruleValueType(value))
+	}
+	function, _ := then["function"].(string)
+	options, _ := then["functionOptions"].(map[string]interface{})
+	result := map[string]interface{}{}
+	if field, ok := then["field"].(string); ok {
+		if strings.HasPrefix(field, "@key") {
+			return nil, "then.field @key (o nome da chave) não é suportado"
+		}
+		if strings.ContainsAny(field, ".[") {
+			return nil, fmt.Sprintf("then.field %q com caminho aninhado não é suportado (use o caminho completo no given)", field)
+		}
+		result["field"] = field
+	}
+
+	switch function {
+	case "truthy", "falsy", "defined", "undefined":
+		result["function"] = function
+	case "pattern":
+		translated := map[string]interface{}{}
+		for _, key := range []string{"match", "notMatch"} {
+			if expr, ok := options[key].(string); ok {
+				translated[key] = spectralRegexp(expr)
+			}
+		}
+		result["function"], result["functionOptions"] = "pattern", translated
+	case "schema":
+		if options["schema"] == nil {
+			return nil, "a função schema sem functionOptions.schema não é suportada"
+		}
+		result["function"], result["functionOptions"] = "schema", map[string]interface{}{"schema": options["schema"]}
+	case "casing":
+		kind, _ := options["type"].(string)
+		expr, ok := spectralCasings[kind]
+		if !ok {
+			return nil, fmt.Sprintf("casing com type %v desconhecido", options["type"])
+		}
+		if options["separator"] != nil {
+			return nil, "casing com separator não é suportado"
+		}
+		if disallow, _ := options["disallowDigits"].(bool); disallow {
+			expr = strings.NewReplacer("a-zA-Z0-9", "a-zA-Z", "a-z0-9", "a-z", "A-Z0-9", "A-Z").Replace(expr)
+		}
+		result["function"], result["functionOptions"] = "pattern", map[string]interface{}{"match": expr}
+	case "enumeration":
+		values, ok := options["values"].([]interface{})
+		if !ok {
+			return nil, "enumeration sem functionOptions.values não é suportada"
+		}
+		result["function"], result["functionOptions"] = "schema", map[string]interface{}{"schema": map[string]interface{}{"enum": values}}
+	case "length":
+		schema := map[string]interface{}{}
+		for key, keywords := range map[string][]string{"min": {"minLength", "minItems", "minProperties"}, "max": {"maxLength", "maxItems", "maxProperties"}} {
+			if limit, ok := options[key]; ok {
+				for _, keyword := range keywords {
+					schema[keyword] = limit
+				}
+			}
+		}
+		result["function"], result["functionOptions"] = "schema", map[string]interface{}{"schema": schema}
+	case "":
+		return nil, "then.function ausente"
+	default:
+		if custom[function] {
+			return nil, fmt.Sprintf("usa a função customizada %q (JavaScript), que não pode ser executada", function)
+		}
+		return nil, fmt.Sprintf("usa a função %q do Spectral, que não tem equivalente", function)
+	}
+	return result, ""
+}
+
+// Expressão regular do Spectral no formato do Go: "/expr/flags" perde as barras e a
+// flag i vira (?i); as demais flags não mudam o resultado de uma busca
+func spectralRegexp(expr string) string {
+	if !strings.HasPrefix(expr, "/") {
+		return expr
+	}
+	end := strings.LastIndex(expr, "/")
+	if end <= 0 {
+		return expr
+	}
+	body, flags := expr[1:end], expr[end+1:]
+	if regexp.MustCompile(`^[gimsuy]*$`).MatchString(flags) {
+		if strings.Contains(flags, "i") {
+			body = "(?i)" + body
+		}
+		return body
+	}
+	return expr
+}
@@ -0,0 +1,161 @@
+package validator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestIsSpectralRuleset(t *testing.T) {
+	tests := []struct {
+		name, source, ruleset string
+		want                  bool
+	}{
+		{"arquivo .spectral", ".spectral.yaml", "rules: {}", true},
+		{"extends do Spectral", "regras.yaml", "extends: spectral:oas\nrules: {}", true},
+		{"extends do Spectral com modo", "regras.yaml", "extends: [[spectral:oas, recommended]]", true},
+		{"funções customizadas", "regras.yaml", "functions: [minha]\nrules: {}", true},
+		{"given em lista", "regras.yaml", "rules: {a: {given: [$.info], then: {field: title, function: truthy}}}", true},
+		{"then em lista", "regras.yaml", "rules: {a: {given: $.info, then: [{field: title, function: truthy}]}}", true},
+		{"severidade numérica", "regras.yaml", "rules: {a: {given: $.info, severity: 0, then: {field: title, function: truthy}}}", true},
+		{"formato próprio", "regras.yaml", "rules: {a: {given: $.info, severity: error, then: {field: title, function: truthy}}}", false},
+		{"número no lugar da regra", "regras.yaml", "rules: {a: 5}", false},
+		{"booleano no lugar da regra", "regras.yaml", "rules: {a: true}", false},
+		{"given em lista de números", "regras.yaml", "rules: {a: {given: [1], then: {function: truthy}}}", false},
+		{"then em lista sem function", "regras.yaml", "rules: {a: {given: $.info, then: [{field: title}]}}", false},
+		{"severidade numérica sem then", "regras.yaml", "rules: {a: {given: $.info, severity: 0}}", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ruleset map[string]interface{}
+			if err := yaml.Unmarshal([]byte(tt.ruleset), &ruleset); err != nil {
+				t.Fatal(err)
+			}
+			if got := isSpectralRuleset(ruleset, tt.source); got != tt.want {
+				t.Errorf("isSpectralRuleset = %v, esperado %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Regras malformadas não são tratadas como Spectral: a validação do formato próprio
+// as rejeita com erro de uso
+func TestMalformedRulesAreNotSpectral(t *testing.T) {
+	spec := mustReadFile(t, "testdata/e2e/valid/api.yaml")
+	for _, rules := range []string{
+		"rules: {a: 5}",
+		"rules: {a: {given: [1], then: {function: truthy}}}",
+		"rules: {a: {given: $.info, then: [{field: title}]}}",
+		"rules: {a: {given: $.info, severity: 0}}",
+	} {
+		t.Run(rules, func(t *testing.T) {
+			_, err := Validate(context.Background(), spec, Options{Source: "api.yaml", Rules: []byte(rules)})
+			if err == nil {
+				t.Fatal("as regras malformadas deveriam ser rejeitadas")
+			}
+			if code := rulesExitCode(err); code != exitUsage {
+				t.Errorf("código %d, esperado %d: %v", code, exitUsage, err)
+			}
+		})
+	}
+}
+
+const spectralRuleset = `extends: [[spectral:oas, recommended]]
+functions: [minhaFuncao]
+aliases:
+  Operacoes: ["$.paths[*][*]"]
+rules:
+  operation-tags: off
+  info-contact: warn
+  operacao-com-resumo:
+    description: Operações precisam de summary.
+    severity: error
+    given: "#Operacoes"
+    then: {field: summary, function: truthy}
+  ids-camel:
+    message: "{{property}} fora do padrão"
+    documentationUrl: https://exemplo.com.br/regras
+    given: ["$.paths[*][*].operationId", "$.components.parameters[*].name"]
+    then: {function: casing, functionOptions: {type: camel}}
+  titulo:
+    severity: 1
+    given: $.info
+    then:
+      - {field: title, function: pattern, functionOptions: {match: "/^open finance/i"}}
+      - {field: description, function: length, functionOptions: {max: 10}}
+  customizada:
+    given: $
+    then: {function: minhaFuncao}
+  sem-equivalente:
+    given: $
+    then: {function: xor, functionOptions: {properties: [a, b]}}
+  chave:
+    given: $.paths
+    then: {field: "@key", function: truthy}
+`
+
+// As regras com equivalente são traduzidas (aliases, listas em given e then, casing,
+// pattern com flags, length, severidades do Spectral); as demais ficam de fora com o
+// motivo, assim como os ajustes de regras embutidas do Spectral
+func TestConvertSpectralRuleset(t *testing.T) {
+	var ruleset map[string]interface{}
+	if err := yaml.Unmarshal([]byte(spectralRuleset), &ruleset); err != nil {
+		t.Fatal(err)
+	}
+	converted, skipped := convertSpectralRuleset(ruleset)
+	if _, ok := converted["extends"]; ok {
+		t.Errorf("extends do Spectral não deveria ser mantido: %v", converted["extends"])
+	}
+	rules := converted["rules"].(map[string]interface{})
+	if len(rules) != 5 {
+		t.Errorf("esperadas 5 regras, encontradas %d: %v", len(rules), rules)
+	}
+	resumo, _ := rules["operacao-com-resumo"].(map[string]interface{})
+	if resumo["given"] != "$.paths[*][*]" || resumo["severity"] != "error" || resumo["description"] != "Operações precisam de summary." {
+		t.Errorf("operacao-com-resumo: %v", resumo)
+	}
+	ids, ids2 := rules["ids-camel"].(map[string]interface{}), rules["ids-camel-2"].(map[string]interface{})
+	if ids["given"] != "$.paths[*][*].operationId" || ids2["given"] != "$.components.parameters[*].name" ||
+		ids["severity"] != "warning" || ids["description"] != "Regra ids-camel (Spectral)" || ids["suggestion"] != "Veja https://exemplo.com.br/regras" {
+		t.Errorf("ids-camel: %v e %v", ids, ids2)
+	}
+	if then := ids["then"].(map[string]interface{}); then["function"] != "pattern" {
+		t.Errorf("casing deveria virar pattern: %v", then)
+	}
+	titulo, titulo2 := rules["titulo"].(map[string]interface{}), rules["titulo-2"].(map[string]interface{})
+	if titulo["severity"] != "warning" || titulo["then"].(map[string]interface{})["functionOptions"].(map[string]interface{})["match"] != "(?i)^open finance" {
+		t.Errorf("titulo: %v", titulo)
+	}
+	if schema := titulo2["then"].(map[string]interface{})["functionOptions"].(map[string]interface{})["schema"].(map[string]interface{}); schema["maxLength"] != 10 {
+		t.Errorf("length deveria virar schema com maxLength: %v", schema)
+	}
+
+	reasons := map[string]string{}
+	for _, skip := range skipped {
+		reasons[skip.rule+skip.key] = skip.reason
+	}
+	for key, want := range map[string]string{
+		"extends:spectral:oas": "as regras embutidas do Spectral não estão disponíveis",
+		"operation-tags":       "ajusta uma regra embutida do Spectral",
+		"info-contact":         "ajusta uma regra embutida do Spectral",
+		"customizada":          `usa a função customizada "minhaFuncao" (JavaScript)`,
+		"sem-equivalente":      `usa a função "xor" do Spectral, que não tem equivalente`,
+		"chave":                "then.field @key",
+	} {
+		if !strings.Contains(reasons[key], want) {
+			t.Errorf("%s: motivo %q, esperado %q", key, reasons[key], want)
+		}
+	}
+}
+
+// Um conjunto de regras do Spectral é avaliado como o formato próprio
+func TestSpectralRulesetValidates(t *testing.T) {
+	spec := "openapi: 3.0.3\ninfo: {title: Contas, version: 1.0.0}\npaths:\n  /contas:\n    get:\n      operationId: ListarContas\n      responses: {'200': {description: ok}}\n"
+	rules := "extends: spectral:oas\nrules:\n  ids-camel:\n    given: $.paths[*][*].operationId\n    then: {function: casing, functionOptions: {type: camel}}\n"
+	found := ruleViolations(t, spec, rules, "ids-camel")
+	if len(found) != 1 || found[0].Severity != "warning" || found[0].Line != 6 {
+		t.Errorf("esperada uma violação (warning) na linha 6: %v", found)
+	}
+}
@@ -0,0 +1,183 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Arquivo gravado pelo --split
+type splitFile struct {
+	Path      string `json:"path"`
+	Component string `json:"component,omitempty"`
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Nome de arquivo seguro para o componente; nomes que coincidem depois da limpeza
+// (inclusive só na caixa, em sistemas de arquivos que não a diferenciam) recebem sufixo
+func componentFileName(name, ext string, used map[string]bool) string {
+	base := strings.Trim(unsafeFileChars.ReplaceAllString(name, "_"), ".")
+	if base == "" {
+		base = "component"
+	}
+	candidate := base
+	for n := 2; used[strings.ToLower(candidate)]; n++ {
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate + ext
+}
+
+// Função para dividir a especificação em um arquivo raiz e um arquivo por componente
+// (schemas/, parameters/, responses/...), com os $refs reescritos para caminhos relativos.
+// O documento deve ter passado pelo bundle, para que todos os componentes estejam no raiz.
+func splitOpenAPI(inputFile string, rootNode *yaml.Node, outDir, format string, indent int) ([]splitFile, error) {
+	doc := rootNode
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	ext := ".yaml"
+	if format == formatJSON {
+		ext = ".json"
+	}
+	rootName := strings.TrimSuffix(filepath.Base(inputFile), filepath.Ext(inputFile)) + ext
+
+	// Arquivo de cada componente, em ordem de tipo e nome para que a nomeação seja estável
+	files := map[string]string{} // #/components/<tipo>/<nome> -> <tipo>/<arquivo>
+	type component struct {
+		pointer, file string
+		node          *yaml.Node
+	}
+	var components []component
+	section := mappingValue(doc, "components")
+	if section != nil && section.Kind == yaml.MappingNode {
+		var kinds []string
+		for i := 0; i+1 < len(section.Content); i += 2 {
+			if section.Content[i+1].Kind == yaml.MappingNode && !strings.HasPrefix(section.Content[i].Value, "x-") {
+				kinds = append(kinds, section.Content[i].Value)
+			}
+		}
+		sort.Strings(kinds)
+		for _, kind := range kinds {
+			entries := mappingValue(section, kind)
+			var names []string
+			for i := 0; i+1 < len(entries.Content); i += 2 {
+				names = append(names, entries.Content[i].Value)
+			}
+			sort.Strings(names)
+			used := map[string]bool{}
+			for _, name := range names {
+				pointer := "#/components/" + kind + "/" + escapePointer(name)
+				file := path.Join(kind, componentFileName(name, ext, used))
+				files[pointer] = file
+				components = append(components, component{pointer: pointer, file: file, node: mappingValue(entries, name)})
+			}
+		}
+	}
+
+	// Reescrever os $refs: dentro de cada componente, relativos à pasta do tipo
+	for _, c := range components {
+		rewriteSplitRefs(c.node, path.Dir(c.file), rootName, files)
+	}
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value != "components" {
+			rewriteSplitRefs(doc.Content[i+1], ".", rootName, files)
+		}
+	}
+
+	// Gravar os componentes e trocá-los no raiz por $refs para os arquivos
+	var written []splitFile
+	for _, c := range components {
+		// Em estilo de bloco: um arquivo YAML que começa com "{" é lido como JSON
+		node := deepCopyNode(c.node)
+		node.Style &^= yaml.FlowStyle
+		data, err := marshalSpec(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{node}}, format, indent, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeSplitFile(filepath.Join(outDir, filepath.FromSlash(c.file)), data); err != nil {
+			return nil, err
+		}
+		written = append(written, splitFile{Path: c.file, Component: c.pointer})
+		*c.node = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: "$ref"},
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: "./" + c.file},
+		}}
+	}
+
+	data, err := marshalSpec(rootNode, format, indent, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeSplitFile(filepath.Join(outDir, rootName), data); err != nil {
+		return nil, err
+	}
+	written = append([]splitFile{{Path: rootName}}, written...)
+	return written, nil
+}
+
+// Reescreve os $refs internos de um nó para os arquivos dos componentes; dir é a
+// pasta (relativa ao diretório de saída) do arquivo onde o nó será gravado
+func rewriteSplitRefs(node *yaml.Node, dir, rootName string, files map[string]string) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "$ref" && value.Kind == yaml.ScalarNode && strings.HasPrefix(value.Value, "#") {
+				value.Value = splitTargetRef(value.Value, dir, rootName, files)
+				continue
+			}
+			rewriteSplitRefs(value, dir, rootName, files)
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			rewriteSplitRefs(item, dir, rootName, files)
+		}
+	}
+}
+
+// $ref equivalente a um ponteiro interno a partir da pasta dir
+func splitTargetRef(ref, dir, rootName string, files map[string]string) string {
+	for pointer, file := range files {
+		if ref == pointer {
+			return relativeRef(dir, file)
+		}
+		if strings.HasPrefix(ref, pointer+"/") {
+			return relativeRef(dir, file) + "#" + strings.TrimPrefix(ref, pointer)
+		}
+	}
+	if dir == "." {
+		return ref
+	}
+	return relativeRef(dir, rootName) + ref
+}
+
+// Caminho relativo com / e prefixo ./ (ex.: ../parameters/Pagina.yaml, ./Conta.yaml)
+func relativeRef(fromDir, file string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(fromDir), filepath.FromSlash(file))
+	if err != nil {
+		return file
+	}
+	rel = filepath.ToSlash(rel)
+	if !strings.HasPrefix(rel, "../") {
+		rel = "./" + rel
+	}
+	return rel
+}
+
+func writeSplitFile(file string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório de %s: %v", file, err)
+	}
+	if err := writeFileAtomic(file, data); err != nil {
+		return fmt.Errorf("erro ao salvar %s: %v", file, err)
+	}
+	return nil
+}
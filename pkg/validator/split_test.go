@@ -0,0 +1,88 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Nomes que coincidem depois da limpeza, inclusive só na caixa, recebem sufixo
+func TestComponentFileName(t *testing.T) {
+	used := map[string]bool{}
+	var got []string
+	for _, name := range []string{"Conta Corrente", "Conta_Corrente", "conta_corrente", "..", "Pix/Cobrança"} {
+		got = append(got, componentFileName(name, ".yaml", used))
+	}
+	want := []string{"Conta_Corrente.yaml", "Conta_Corrente-2.yaml", "conta_corrente-3.yaml", "component.yaml", "Pix_Cobran_a.yaml"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("nomes %v, esperado %v", got, want)
+	}
+}
+
+// --split grava um arquivo por componente com $refs relativos, e o raiz gravado
+// resolve para o mesmo documento que a especificação original
+func TestResolveSplit(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"api.yaml": `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0}
+paths:
+  /contas:
+    get:
+      parameters:
+        - $ref: '#/components/parameters/pagina'
+      responses:
+        '200': {description: ok, content: {application/json: {schema: {$ref: '#/components/schemas/Conta Corrente'}}}}
+components:
+  parameters:
+    pagina: {name: page, in: query, schema: {type: integer}}
+  schemas:
+    Conta Corrente:
+      type: object
+      properties:
+        poupanca: {$ref: '#/components/schemas/Conta_Corrente'}
+    Conta_Corrente: {type: string}
+`})
+	spec := filepath.Join(dir, "api.yaml")
+	outDir := filepath.Join(dir, "split")
+
+	code, out := runCommand(t, "resolve", "--no-cache", "--split", "--out-dir", outDir, spec)
+	if code != exitOK {
+		t.Fatalf("código %d\n%s", code, out)
+	}
+	for _, want := range []string{
+		"📂 4 arquivos gravados em " + outDir,
+		"schemas/Conta_Corrente.yaml (#/components/schemas/Conta Corrente)",
+		"schemas/Conta_Corrente-2.yaml (#/components/schemas/Conta_Corrente)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("saída sem %q:\n%s", want, out)
+		}
+	}
+	if got := string(mustReadFile(t, filepath.Join(outDir, "schemas", "Conta_Corrente.yaml"))); !strings.Contains(got, "$ref: './Conta_Corrente-2.yaml'") {
+		t.Errorf("$ref do componente não é relativo à pasta schemas:\n%s", got)
+	}
+
+	original := resolveArtifact(t, spec)
+	split := resolveArtifact(t, filepath.Join(outDir, "api.yaml"))
+	var a, b interface{}
+	if err := yaml.Unmarshal(original, &a); err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal(split, &b); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("resolução do split difere da original:\n%s\n---\n%s", original, split)
+	}
+
+	if code, out := runCommand(t, "resolve", "--split", spec); code != exitUsage || !strings.Contains(out, "--split e --out-dir devem ser usados juntos") {
+		t.Errorf("código %d sem --out-dir\n%s", code, out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "apiResolve.yaml")); !os.IsNotExist(err) {
+		t.Error("o erro de uso gravou o artefato")
+	}
+}
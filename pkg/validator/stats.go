@@ -0,0 +1,311 @@
+package validator
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Flags do subcomando stats
+type statsOptions struct {
+	format  string
+	compare string
+	timeout time.Duration
+}
+
+func (o *statsOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.format, "format", "text", "formato da saída: text (tabela) ou json")
+	fs.StringVar(&o.compare, "compare", "", "especificação anterior: mostra também os valores dela e a diferença")
+	fs.DurationVar(&o.timeout, "timeout", defaultTimeout, "tempo máximo da execução (0 desativa)")
+	registerRefFlags(fs)
+	registerHTTPFlags(fs)
+	registerEncodingFlag(fs)
+	registerSelectDocumentFlag(fs)
+	registerInputLimitFlag(fs)
+	registerOverlayFlags(fs)
+	registerConvertFlag(fs)
+}
+
+var statsCommand = &command{
+	Name:    "stats",
+	Args:    "<spec.yaml>",
+	Summary: "mostra números da especificação (paths, operações, schemas, refs)",
+	Description: `Resolve a especificação e mostra números para acompanhar refatorações:
+paths, operações (no total e por método), schemas em components, média de
+propriedades por schema (somando as partes de allOf), $refs para outros
+arquivos, operações deprecated e schemas com enum. Não usa regras.
+
+Com --compare, a especificação anterior é medida da mesma forma e a tabela
+mostra os dois valores e a diferença de cada número.`,
+	Examples: []string{
+		programName + " stats swagger.yaml",
+		programName + " stats --format json swagger.yaml",
+		programName + " stats --compare swagger-antes.yaml swagger.yaml",
+	},
+	Flags: func(fs *flag.FlagSet) { new(statsOptions).register(fs) },
+	Run:   runStats,
+}
+
+// Números de uma especificação
+type specStats struct {
+	File               string         `json:"file"`
+	Paths              int            `json:"paths"`
+	Operations         int            `json:"operations"`
+	OperationsByMethod map[string]int `json:"operationsByMethod"`
+	Schemas            int            `json:"schemas"`
+	AverageProperties  float64        `json:"averageProperties"`
+	ExternalRefs       int            `json:"externalRefs"`
+	Deprecated         int            `json:"deprecatedOperations"`
+	Enums              int            `json:"enums"`
+}
+
+// Subcomando stats: números da especificação e, com --compare, a diferença
+func runStats(c *command, args []string) int {
+	opts := &statsOptions{}
+	fs := c.newFlagSet()
+	opts.register(fs)
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	if err := checkOverlayFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkHTTPFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkEncodingFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkSelectDocumentFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if opts.format != "text" && opts.format != "json" {
+		return c.usageError("formato %q inválido para stats (use text ou json)", opts.format)
+	}
+	if fs.NArg() != 1 {
+		return c.usageError("esperado um argumento (especificação), recebidos %d", fs.NArg())
+	}
+
+	ctx, cancel := newRunContext(opts.timeout)
+	defer cancel()
+
+	current, err := collectStats(ctx, fs.Arg(0))
+	if err != nil {
+		return statsError(fs.Arg(0), err, opts.timeout)
+	}
+	var previous *specStats
+	if opts.compare != "" {
+		if previous, err = collectStats(ctx, opts.compare); err != nil {
+			return statsError(opts.compare, err, opts.timeout)
+		}
+	}
+
+	if opts.format == "json" {
+		var report interface{} = current
+		if previous != nil {
+			delta := map[string]float64{}
+			for _, row := range statsRows(current, previous) {
+				delta[row.key] = math.Round((row.current-row.previous)*10) / 10
+			}
+			report = struct {
+				Current  *specStats         `json:"current"`
+				Previous *specStats         `json:"previous"`
+				Delta    map[string]float64 `json:"delta"`
+			}{current, previous, delta}
+		}
+		if err := printJSONReport(report); err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+		return exitOK
+	}
+	printStatsTable(current, previous)
+	return exitOK
+}
+
+func statsError(file string, err error, timeout time.Duration) int {
+	if isCancellation(err) {
+		reportCancellation(err, timeout)
+		return exitFailure
+	}
+	fmt.Fprintln(stdout, "❌ Erro ao processar", file+":", err)
+	return exitFailure
+}
+
+// Função para medir a especificação: as contagens vêm do documento resolvido e os
+// $refs externos, do documento original, antes da resolução
+func collectStats(ctx context.Context, inputFile string) (*specStats, error) {
+	source, err := openSpecDocument(flagSettings(), inputFile)
+	if err != nil {
+		return nil, err
+	}
+	original := source.root
+	spec, err := source.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if n := len(spec.report.Errors); n > 0 {
+		fmt.Fprintf(stderr, "⚠️  %s tem %d referências não resolvidas; os números consideram apenas o que foi resolvido\n", inputFile, n)
+	}
+	root := &spec.rootNode
+	doc := documentContent(root)
+
+	stats := &specStats{File: reportPath(inputFile), OperationsByMethod: map[string]int{}}
+	if paths := mappingValue(doc, "paths"); paths != nil && paths.Kind == yaml.MappingNode {
+		stats.Paths = len(paths.Content) / 2
+	}
+	for _, op := range listOperations(root) {
+		stats.Operations++
+		stats.OperationsByMethod[strings.ToUpper(op.Method)]++
+		section := "paths"
+		if op.Webhook {
+			section = "webhooks"
+		}
+		operation := mappingValue(mappingValue(mappingValue(doc, section), op.Path), op.Method)
+		if deprecated := mappingValue(operation, "deprecated"); deprecated != nil && deprecated.Value == "true" {
+			stats.Deprecated++
+		}
+	}
+
+	schemas := mappingValue(mappingValue(doc, "components"), "schemas")
+	if isSwagger2(root) {
+		schemas = mappingValue(doc, "definitions")
+	}
+	if schemas != nil && schemas.Kind == yaml.MappingNode {
+		validator := newSchemaValidator(root)
+		properties := 0
+		for i := 1; i < len(schemas.Content); i += 2 {
+			stats.Schemas++
+			for _, part := range flattenAllOf(validator, schemas.Content[i], "", 0) {
+				if p := mappingValue(part.schema, "properties"); p != nil && p.Kind == yaml.MappingNode {
+					properties += len(p.Content) / 2
+				}
+			}
+		}
+		if stats.Schemas > 0 {
+			stats.AverageProperties = math.Round(float64(properties)*10/float64(stats.Schemas)) / 10
+		}
+	}
+	visitSchemas(root, nil, func(schema *yaml.Node, path string) {
+		if mappingValue(schema, "enum") != nil {
+			stats.Enums++
+		}
+	})
+	stats.ExternalRefs = countExternalRefs(original)
+	return stats, nil
+}
+
+// Lê a especificação como a validação lê (conversão e overlays), sem resolver
+func loadRootSpec(inputFile string) (*yaml.Node, error) {
+	data, err := readSpecFile(inputFile)
+	if err != nil {
+		return nil, err
+	}
+	return parseRootSpec(flagSettings(), data, inputFile)
+}
+
+// $refs que apontam para outro arquivo ou URL
+func countExternalRefs(node *yaml.Node) int {
+	if node == nil {
+		return 0
+	}
+	count := 0
+	if node.Kind == yaml.MappingNode {
+		if ref := mappingValue(node, "$ref"); ref != nil && ref.Kind == yaml.ScalarNode {
+			if file, _ := splitRef(ref.Value); file != "" {
+				count++
+			}
+		}
+	}
+	for _, child := range node.Content {
+		count += countExternalRefs(child)
+	}
+	return count
+}
+
+// Linha da tabela: o mesmo número nas duas especificações
+type statRow struct {
+	label, key        string
+	current, previous float64
+}
+
+// Função para montar as linhas das duas especificações (previous pode ser nula); os
+// métodos aparecem quando existem em alguma delas
+func statsRows(current, previous *specStats) []statRow {
+	value := func(s *specStats, get func(*specStats) float64) float64 {
+		if s == nil {
+			return 0
+		}
+		return get(s)
+	}
+	row := func(label, key string, get func(*specStats) float64) statRow {
+		return statRow{label: label, key: key, current: value(current, get), previous: value(previous, get)}
+	}
+	rows := []statRow{
+		row("Paths", "paths", func(s *specStats) float64 { return float64(s.Paths) }),
+		row("Operações", "operations", func(s *specStats) float64 { return float64(s.Operations) }),
+	}
+	methods := map[string]bool{}
+	for _, s := range []*specStats{current, previous} {
+		if s != nil {
+			for method := range s.OperationsByMethod {
+				methods[method] = true
+			}
+		}
+	}
+	names := sortedKeys(methods)
+	sort.SliceStable(names, func(i, j int) bool {
+		return methodOrder[strings.ToLower(names[i])] < methodOrder[strings.ToLower(names[j])]
+	})
+	for _, method := range names {
+		method := method
+		rows = append(rows, row("  "+method, "operations."+method, func(s *specStats) float64 { return float64(s.OperationsByMethod[method]) }))
+	}
+	return append(rows,
+		row("Schemas", "schemas", func(s *specStats) float64 { return float64(s.Schemas) }),
+		row("Média de propriedades por schema", "averageProperties", func(s *specStats) float64 { return s.AverageProperties }),
+		row("$refs externos", "externalRefs", func(s *specStats) float64 { return float64(s.ExternalRefs) }),
+		row("Operações deprecated", "deprecatedOperations", func(s *specStats) float64 { return float64(s.Deprecated) }),
+		row("Schemas com enum", "enums", func(s *specStats) float64 { return float64(s.Enums) }),
+	)
+}
+
+func printStatsTable(current, previous *specStats) {
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	if previous == nil {
+		fmt.Fprintf(stdout, "📊 %s\n", current.File)
+		for _, row := range statsRows(current, nil) {
+			fmt.Fprintf(w, "%s\t%s\n", row.label, formatStat(row.current))
+		}
+		w.Flush()
+		return
+	}
+	fmt.Fprintf(stdout, "📊 %s comparada com %s\n", current.File, previous.File)
+	fmt.Fprintln(w, "\tAnterior\tAtual\tDiferença")
+	for _, row := range statsRows(current, previous) {
+		delta := math.Round((row.current-row.previous)*10) / 10
+		change := "="
+		if delta != 0 {
+			change = formatStat(delta)
+			if delta > 0 {
+				change = "+" + change
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", row.label, formatStat(row.previous), formatStat(row.current), change)
+	}
+	w.Flush()
+}
+
+func formatStat(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.1f", v)
+}
@@ -0,0 +1,216 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Nós removidos do documento resolvido (--strip ou "strip" em .openapi-ci.yaml).
+// Cada item é um tipo (examples, descriptions), um padrão de extensão (x-internal*)
+// ou uma expressão JSONPath ($.paths['/admin']).
+var stripTargets stringList
+
+// Chaves removidas por cada tipo
+var stripKinds = map[string][]string{
+	"examples":     {"example", "examples"},
+	"descriptions": {"description"},
+}
+
+// Resultado da remoção: quantidade de nós por item e avisos
+type stripResult struct {
+	Removed  map[string]int
+	Warnings []string
+}
+
+// Um item de --strip compilado
+type stripTarget struct {
+	name  string
+	keys  map[string]bool // tipos: chaves removidas
+	ext   *regexp.Regexp  // padrão de extensão
+	query string          // expressão JSONPath
+}
+
+// Função para interpretar os itens de --strip, separados por vírgula
+func parseStripTargets(items []string) ([]stripTarget, error) {
+	var targets []stripTarget
+	for _, item := range items {
+		for _, name := range strings.Split(item, ",") {
+			name = strings.TrimSpace(name)
+			switch {
+			case name == "":
+				continue
+			case strings.HasPrefix(name, "$"):
+				if _, _, err := parseJSONPath(name); err != nil {
+					return nil, err
+				}
+				targets = append(targets, stripTarget{name: name, query: name})
+			case strings.HasPrefix(name, "x-"):
+				targets = append(targets, stripTarget{name: name, ext: regexp.MustCompile("^" + globToRegexp(name) + "$")})
+			default:
+				keys, ok := stripKinds[name]
+				if !ok {
+					return nil, fmt.Errorf("item de --strip desconhecido %q (use examples, descriptions, um padrão x-... ou uma expressão JSONPath)", name)
+				}
+				target := stripTarget{name: name, keys: map[string]bool{}}
+				for _, k := range keys {
+					target.keys[k] = true
+				}
+				targets = append(targets, target)
+			}
+		}
+	}
+	return targets, nil
+}
+
+// Função para remover do documento os nós indicados em --strip
+func stripNodes(rootNode *yaml.Node, items []string) (*stripResult, error) {
+	targets, err := parseStripTargets(items)
+	if err != nil {
+		return nil, err
+	}
+	result := &stripResult{Removed: map[string]int{}}
+	doc := rootNode
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+
+	for _, target := range targets {
+		if target.query == "" {
+			result.Removed[target.name] += stripKeys(doc, target, nil)
+			continue
+		}
+		matches, err := queryJSONPath(rootNode, target.query)
+		if err != nil {
+			return nil, err
+		}
+		parents := parentIndex(doc)
+		for _, m := range matches {
+			if m.Key != nil {
+				warnRequired(parents, parents[m.Node], m.Key.Value, m.Path, result)
+			}
+			if removeNode(parents, m) {
+				result.Removed[target.name]++
+			}
+		}
+	}
+	return result, nil
+}
+
+// Remove as chaves que casam com o tipo ou o padrão; nomes de propriedades e de
+// componentes não são confundidos com campos (uma propriedade "description" fica)
+func stripKeys(node *yaml.Node, target stripTarget, path []string) int {
+	removed := 0
+	switch node.Kind {
+	case yaml.MappingNode:
+		names := isNameMap(path)
+		// Nova fatia: após a resolução, nós de $refs diferentes podem compartilhar o mesmo conteúdo
+		kept := make([]*yaml.Node, 0, len(node.Content))
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if !names && (target.keys[key.Value] || (target.ext != nil && target.ext.MatchString(key.Value))) {
+				removed++
+				continue
+			}
+			removed += stripKeys(value, target, append(path, key.Value))
+			kept = append(kept, key, value)
+		}
+		node.Content = kept
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			removed += stripKeys(item, target, path)
+		}
+	}
+	return removed
+}
+
+// Indica se as chaves do mapping no caminho são nomes escolhidos pelo autor
+func isNameMap(path []string) bool {
+	if len(path) == 0 {
+		return false
+	}
+	last := path[len(path)-1]
+	return last == "properties" || last == "patternProperties" ||
+		(len(path) == 2 && path[0] == "components")
+}
+
+// Mapeia cada nó para o nó que o contém
+func parentIndex(doc *yaml.Node) map[*yaml.Node]*yaml.Node {
+	parents := map[*yaml.Node]*yaml.Node{}
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		for _, child := range node.Content {
+			if _, ok := parents[child]; ok {
+				continue
+			}
+			parents[child] = node
+			walk(child)
+		}
+	}
+	walk(doc)
+	return parents
+}
+
+// Remove o nó encontrado pelo JSONPath do mapping ou da lista que o contém
+func removeNode(parents map[*yaml.Node]*yaml.Node, m pathMatch) bool {
+	parent := parents[m.Node]
+	if parent == nil {
+		return false
+	}
+	for i, child := range parent.Content {
+		if child != m.Node {
+			continue
+		}
+		switch parent.Kind {
+		case yaml.MappingNode:
+			if i == 0 || parent.Content[i-1] != m.Key {
+				continue
+			}
+			parent.Content = append(append([]*yaml.Node{}, parent.Content[:i-1]...), parent.Content[i+1:]...)
+		case yaml.SequenceNode:
+			parent.Content = append(append([]*yaml.Node{}, parent.Content[:i]...), parent.Content[i+1:]...)
+		default:
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// Avisa quando a chave removida de "properties" consta no "required" do schema
+func warnRequired(parents map[*yaml.Node]*yaml.Node, properties *yaml.Node, name, path string, result *stripResult) {
+	if properties == nil {
+		return
+	}
+	schema := parents[properties]
+	if schema == nil || schema.Kind != yaml.MappingNode || mappingValue(schema, "properties") != properties {
+		return
+	}
+	required := mappingValue(schema, "required")
+	if required == nil || required.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, item := range required.Content {
+		if item.Value == name {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("a propriedade %s removida em %s consta em required", name, path))
+			return
+		}
+	}
+}
+
+// Resumo no formato "examples=3, x-internal*=1", em ordem alfabética
+func (r *stripResult) String() string {
+	names := make([]string, 0, len(r.Removed))
+	for name := range r.Removed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s=%d", name, r.Removed[name])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,72 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const stripSpec = `openapi: 3.0.3
+info: {title: Contas, version: 1.0.0, description: TODO}
+x-internal-owner: squad-contas
+paths:
+  /contas:
+    get:
+      description: Lista as contas
+      x-internal-notes: revisar
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              example: {numero: '1'}
+              schema:
+                type: object
+                required: [numero, auditoria]
+                properties:
+                  numero: {type: string, example: '1'}
+                  description: {type: string}
+                  auditoria: {type: string}
+`
+
+// --strip remove os tipos, as extensões do padrão e os nós do JSONPath, contando cada
+// item; a propriedade chamada description fica e a remoção de uma propriedade
+// obrigatória gera um aviso
+func TestStripNodes(t *testing.T) {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(stripSpec), &root); err != nil {
+		t.Fatal(err)
+	}
+	query := "$.paths['/contas'].get.responses['200'].content['application/json'].schema.properties.auditoria"
+	result, err := stripNodes(&root, []string{"examples,descriptions", "x-internal*", query})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := result.String(), query+"=1, descriptions=3, examples=2, x-internal*=2"; got != want {
+		t.Errorf("removidos %q, esperado %q", got, want)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "a propriedade auditoria removida") {
+		t.Errorf("avisos %v", result.Warnings)
+	}
+
+	out, err := yaml.Marshal(&root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, gone := range []string{"example", "x-internal", "TODO", "Lista as contas", "auditoria:"} {
+		if strings.Contains(string(out), gone) {
+			t.Errorf("%q continua no documento:\n%s", gone, out)
+		}
+	}
+	if !strings.Contains(string(out), "description:") {
+		t.Errorf("a propriedade description foi removida:\n%s", out)
+	}
+}
+
+// Um item que não é tipo, extensão nem JSONPath é recusado
+func TestStripUnknownTarget(t *testing.T) {
+	if _, err := parseStripTargets([]string{"examples,comentarios"}); err == nil || !strings.Contains(err.Error(), `item de --strip desconhecido "comentarios"`) {
+		t.Errorf("erro %v", err)
+	}
+}
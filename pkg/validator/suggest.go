@@ -0,0 +1,59 @@
+package validator
+
+import "strings"
+
+// Função para sugerir, entre os nomes declarados, o mais parecido com um nome
+// desconhecido (distância de edição, sem diferenciar maiúsculas); vazio quando
+// nenhum é parecido o suficiente
+func closestName(name string, candidates []string) string {
+	best, bestDistance := "", -1
+	for _, candidate := range candidates {
+		d := editDistance(strings.ToLower(name), strings.ToLower(candidate))
+		if bestDistance < 0 || d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	// Aceita até metade do nome diferente; acima disso a sugestão mais confunde que ajuda.
+	// Com um único candidato, ele é sugerido mesmo assim (ex.: esquema renomeado).
+	if best == "" || (len(candidates) > 1 && bestDistance > (len([]rune(name))+1)/2) {
+		return ""
+	}
+	return best
+}
+
+// Sufixo " (você quis dizer X?)" para as mensagens, quando há sugestão
+func suggestionSuffix(name string, candidates []string) string {
+	if s := closestName(name, candidates); s != "" {
+		return " (você quis dizer " + s + "?)"
+	}
+	return ""
+}
+
+// Distância de Levenshtein entre dois textos
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current := make([]int, len(rb)+1)
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(prev[j]+1, current[j-1]+1), prev[j-1]+cost)
+		}
+		prev = current
+	}
+	return prev[len(rb)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
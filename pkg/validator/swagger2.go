@@ -0,0 +1,611 @@
+package validator
+
+import (
+	"flag"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Com --no-convert, especificações Swagger 2.0 não são convertidas e as regras
+// exclusivas de OpenAPI 3 são ignoradas
+var noConvert bool
+
+func registerConvertFlag(fs *flag.FlagSet) {
+	fs.BoolVar(&noConvert, "no-convert", false, "não converte especificações Swagger 2.0 para OpenAPI 3.0; regras exclusivas de OpenAPI 3 são ignoradas")
+}
+
+// Versão do OpenAPI gerada pela conversão
+const convertedOpenAPIVersion = "3.0.3"
+
+// Indica se o documento é Swagger 2.0
+func isSwagger2(rootNode *yaml.Node) bool {
+	doc := rootNode
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	version := mappingValue(doc, "swagger")
+	return version != nil && strings.HasPrefix(version.Value, "2")
+}
+
+// Trechos de given que só existem em OpenAPI 3
+var openAPI3OnlyPaths = []string{"$.components", "$.servers", "requestBody", "callbacks", "links", "webhooks"}
+
+// Indica se a regra depende de estruturas exclusivas de OpenAPI 3
+func isOpenAPI3OnlyRule(given string) bool {
+	for _, p := range openAPI3OnlyPaths {
+		if strings.Contains(given, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Conversão de Swagger 2.0 para OpenAPI 3.0, feita sobre a própria árvore: os nós
+// originais são reaproveitados sempre que possível, para que as violações continuem
+// apontando para as linhas do arquivo de origem
+type swaggerConverter struct {
+	doc            *yaml.Node
+	consumes       []string
+	produces       []string
+	bodyParameters map[string]bool       // parâmetros globais in: body (viram requestBodies)
+	parameters     map[string]*yaml.Node // parâmetros globais, para $refs de formData
+}
+
+// Função para converter um documento Swagger 2.0 em OpenAPI 3.0
+func convertSwagger2(rootNode *yaml.Node) {
+	doc := rootNode
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	c := &swaggerConverter{
+		doc:            doc,
+		consumes:       scalarList(mappingValue(doc, "consumes"), "application/json"),
+		produces:       scalarList(mappingValue(doc, "produces"), "application/json"),
+		bodyParameters: map[string]bool{},
+		parameters:     map[string]*yaml.Node{},
+	}
+	if params := mappingValue(doc, "parameters"); params != nil {
+		for i := 0; i+1 < len(params.Content); i += 2 {
+			c.parameters[params.Content[i].Value] = params.Content[i+1]
+			if in := mappingValue(params.Content[i+1], "in"); in != nil && in.Value == "body" {
+				c.bodyParameters[params.Content[i].Value] = true
+			}
+		}
+	}
+
+	components := newMapping(doc)
+	servers := c.servers()
+	var content []*yaml.Node
+	componentsAdded, serversAdded := false, false
+	addComponents := func(key *yaml.Node) {
+		if !componentsAdded {
+			content = append(content, keyLike(key, "components"), components)
+			componentsAdded = true
+		}
+	}
+	addServers := func(key *yaml.Node) {
+		if !serversAdded && servers != nil {
+			content = append(content, keyLike(key, "servers"), servers)
+			serversAdded = true
+		}
+	}
+
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		key, value := doc.Content[i], doc.Content[i+1]
+		switch key.Value {
+		case "swagger":
+			content = append(content, keyLike(key, "openapi"), scalarLike(value, convertedOpenAPIVersion))
+		case "host", "basePath", "schemes":
+			addServers(key)
+		case "consumes", "produces":
+		case "paths":
+			addServers(key)
+			c.convertPaths(value)
+			content = append(content, key, value)
+		case "definitions":
+			addComponents(key)
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				c.convertSchema(value.Content[j+1])
+			}
+			setValue(components, keyLike(key, "schemas"), value)
+		case "parameters":
+			addComponents(key)
+			params, bodies := newMapping(value), newMapping(value)
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				name, param := value.Content[j], value.Content[j+1]
+				if c.bodyParameters[name.Value] {
+					bodies.Content = append(bodies.Content, name, c.requestBody([]*yaml.Node{param}, c.consumes))
+				} else {
+					c.convertParameter(param)
+					params.Content = append(params.Content, name, param)
+				}
+			}
+			if len(params.Content) > 0 {
+				setValue(components, keyLike(key, "parameters"), params)
+			}
+			if len(bodies.Content) > 0 {
+				setValue(components, keyLike(key, "requestBodies"), bodies)
+			}
+		case "responses":
+			addComponents(key)
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				c.convertResponse(value.Content[j+1], c.produces)
+			}
+			setValue(components, keyLike(key, "responses"), value)
+		case "securityDefinitions":
+			addComponents(key)
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				convertSecurityScheme(value.Content[j+1])
+			}
+			setValue(components, keyLike(key, "securitySchemes"), value)
+		default:
+			content = append(content, key, value)
+		}
+	}
+	if !serversAdded && servers != nil {
+		content = append(content, keyLike(doc, "servers"), servers)
+	}
+	doc.Content = content
+	c.convertRefs(doc)
+}
+
+// Reescreve os $refs locais restantes (path items, exemplos, extensões)
+func (c *swaggerConverter) convertRefs(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "$ref" && value.Kind == yaml.ScalarNode {
+				value.Value = convertRef(value.Value, c.bodyParameters)
+				continue
+			}
+			c.convertRefs(value)
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			c.convertRefs(item)
+		}
+	}
+}
+
+// servers a partir de host, basePath e schemes; nil quando não há host nem basePath
+func (c *swaggerConverter) servers() *yaml.Node {
+	host := mappingValue(c.doc, "host")
+	basePath := mappingValue(c.doc, "basePath")
+	if host == nil && basePath == nil {
+		return nil
+	}
+	origin := host
+	if origin == nil {
+		origin = basePath
+	}
+	base := ""
+	if basePath != nil {
+		base = basePath.Value
+	}
+	servers := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: origin.Line, Column: origin.Column}
+	if host == nil {
+		servers.Content = append(servers.Content, serverNode(origin, base))
+		return servers
+	}
+	for _, scheme := range scalarList(mappingValue(c.doc, "schemes"), "https") {
+		servers.Content = append(servers.Content, serverNode(origin, scheme+"://"+host.Value+base))
+	}
+	return servers
+}
+
+func serverNode(origin *yaml.Node, url string) *yaml.Node {
+	server := newMapping(origin)
+	setValue(server, scalarLike(origin, "url"), scalarLike(origin, url))
+	return server
+}
+
+// Converte os path items e as operações
+func (c *swaggerConverter) convertPaths(paths *yaml.Node) {
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		item := paths.Content[i+1]
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		if params := mappingValue(item, "parameters"); params != nil {
+			c.convertParameterList(item, params, c.consumes)
+		}
+		for j := 0; j+1 < len(item.Content); j += 2 {
+			if httpMethods[item.Content[j].Value] {
+				c.convertOperation(item.Content[j+1])
+			}
+		}
+	}
+}
+
+func (c *swaggerConverter) convertOperation(op *yaml.Node) {
+	if op.Kind != yaml.MappingNode {
+		return
+	}
+	consumes := scalarList(removeKey(op, "consumes"), c.consumes...)
+	produces := scalarList(removeKey(op, "produces"), c.produces...)
+	if params := mappingValue(op, "parameters"); params != nil {
+		c.convertParameterList(op, params, consumes)
+	}
+	if responses := mappingValue(op, "responses"); responses != nil {
+		for i := 0; i+1 < len(responses.Content); i += 2 {
+			c.convertResponse(responses.Content[i+1], produces)
+		}
+	}
+}
+
+// Separa os parâmetros body e formData, que viram requestBody, dos demais
+func (c *swaggerConverter) convertParameterList(owner, params *yaml.Node, consumes []string) {
+	var kept, body []*yaml.Node
+	for _, param := range params.Content {
+		in := parameterLocation(param, c.parameters)
+		switch {
+		case in == "body" || in == "formData":
+			if ref := mappingValue(param, "$ref"); ref != nil && in == "body" {
+				// Parâmetro body global: vira referência para components.requestBodies
+				name := strings.TrimPrefix(ref.Value, "#/parameters/")
+				setValue(owner, keyLike(param, "requestBody"), refNode(param, "#/components/requestBodies/"+name))
+				continue
+			}
+			if ref := mappingValue(param, "$ref"); ref != nil {
+				param = deepCopyNode(c.parameters[strings.TrimPrefix(ref.Value, "#/parameters/")])
+			}
+			body = append(body, param)
+		default:
+			c.convertParameter(param)
+			kept = append(kept, param)
+		}
+	}
+	if len(body) > 0 {
+		setValue(owner, keyLike(params, "requestBody"), c.requestBody(body, consumes))
+	}
+	if len(kept) == 0 {
+		removeKey(owner, "parameters")
+		return
+	}
+	params.Content = kept
+}
+
+// Localização do parâmetro, seguindo $refs para os parâmetros globais
+func parameterLocation(param *yaml.Node, globals map[string]*yaml.Node) string {
+	if ref := mappingValue(param, "$ref"); ref != nil {
+		if global := globals[strings.TrimPrefix(ref.Value, "#/parameters/")]; global != nil {
+			param = global
+		} else {
+			return ""
+		}
+	}
+	if in := mappingValue(param, "in"); in != nil {
+		return in.Value
+	}
+	return ""
+}
+
+// requestBody a partir de um parâmetro body ou de parâmetros formData
+func (c *swaggerConverter) requestBody(params []*yaml.Node, consumes []string) *yaml.Node {
+	first := params[0]
+	body := newMapping(first)
+	content := newMapping(first)
+
+	if in := mappingValue(first, "in"); in != nil && in.Value == "body" {
+		if description := mappingValue(first, "description"); description != nil {
+			setValue(body, keyLike(first, "description"), description)
+		}
+		if required := mappingValue(first, "required"); required != nil {
+			setValue(body, keyLike(first, "required"), required)
+		}
+		schema := mappingValue(first, "schema")
+		if schema == nil {
+			schema = newMapping(first)
+		}
+		c.convertSchema(schema)
+		for i, mime := range consumes {
+			s := schema
+			if i > 0 {
+				s = deepCopyNode(schema)
+			}
+			media := newMapping(first)
+			setValue(media, keyLike(first, "schema"), s)
+			setValue(content, scalarLike(first, mime), media)
+		}
+		setValue(body, keyLike(first, "content"), content)
+		return body
+	}
+
+	// formData: um schema object com uma propriedade por parâmetro
+	schema := newMapping(first)
+	setValue(schema, keyLike(first, "type"), scalarLike(first, "object"))
+	properties := newMapping(first)
+	required := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: first.Line, Column: first.Column}
+	multipart := false
+	for _, param := range params {
+		name := mappingValue(param, "name")
+		if name == nil {
+			continue
+		}
+		if t := mappingValue(param, "type"); t != nil && t.Value == "file" {
+			multipart = true
+		}
+		if r := mappingValue(param, "required"); r != nil && r.Value == "true" {
+			required.Content = append(required.Content, scalarLike(name, name.Value))
+		}
+		property := parameterSchema(param)
+		if description := mappingValue(param, "description"); description != nil {
+			setValue(property, keyLike(param, "description"), description)
+		}
+		c.convertSchema(property)
+		setValue(properties, name, property)
+	}
+	setValue(schema, keyLike(first, "properties"), properties)
+	if len(required.Content) > 0 {
+		setValue(schema, keyLike(first, "required"), required)
+	}
+
+	mime := "application/x-www-form-urlencoded"
+	for _, m := range consumes {
+		if m == "multipart/form-data" {
+			multipart = true
+		}
+	}
+	if multipart {
+		mime = "multipart/form-data"
+	}
+	media := newMapping(first)
+	setValue(media, keyLike(first, "schema"), schema)
+	setValue(content, scalarLike(first, mime), media)
+	setValue(body, keyLike(first, "content"), content)
+	return body
+}
+
+// Campos de schema que em Swagger 2.0 ficam direto no parâmetro ou no header
+var parameterSchemaFields = map[string]bool{
+	"type": true, "format": true, "items": true, "default": true, "maximum": true,
+	"exclusiveMaximum": true, "minimum": true, "exclusiveMinimum": true, "maxLength": true,
+	"minLength": true, "pattern": true, "maxItems": true, "minItems": true,
+	"uniqueItems": true, "enum": true, "multipleOf": true,
+}
+
+// Retira os campos de schema do parâmetro e monta o schema correspondente
+func parameterSchema(param *yaml.Node) *yaml.Node {
+	schema := newMapping(param)
+	var kept []*yaml.Node
+	for i := 0; i+1 < len(param.Content); i += 2 {
+		key, value := param.Content[i], param.Content[i+1]
+		if parameterSchemaFields[key.Value] {
+			schema.Content = append(schema.Content, key, value)
+			continue
+		}
+		kept = append(kept, key, value)
+	}
+	param.Content = kept
+	return schema
+}
+
+// Converte um parâmetro que não é body nem formData
+func (c *swaggerConverter) convertParameter(param *yaml.Node) {
+	if ref := mappingValue(param, "$ref"); ref != nil {
+		ref.Value = convertRef(ref.Value, c.bodyParameters)
+		return
+	}
+	collection := removeKey(param, "collectionFormat")
+	if example := removeKey(param, "x-example"); example != nil {
+		setValue(param, keyLike(example, "example"), example)
+	}
+	schema := parameterSchema(param)
+	if items := mappingValue(schema, "items"); items != nil {
+		removeKey(items, "collectionFormat")
+	}
+	c.convertSchema(schema)
+	setValue(param, keyLike(param, "schema"), schema)
+
+	if collection != nil {
+		switch collection.Value {
+		case "multi":
+			setValue(param, keyLike(collection, "explode"), boolLike(collection, true))
+		case "csv":
+			setValue(param, keyLike(collection, "explode"), boolLike(collection, false))
+		case "ssv":
+			setValue(param, keyLike(collection, "style"), scalarLike(collection, "spaceDelimited"))
+		case "pipes":
+			setValue(param, keyLike(collection, "style"), scalarLike(collection, "pipeDelimited"))
+		}
+	}
+}
+
+// Converte uma resposta: schema e examples viram content, headers ganham schema
+func (c *swaggerConverter) convertResponse(response *yaml.Node, produces []string) {
+	if response.Kind != yaml.MappingNode {
+		return
+	}
+	if ref := mappingValue(response, "$ref"); ref != nil {
+		ref.Value = convertRef(ref.Value, c.bodyParameters)
+		return
+	}
+	schema := removeKey(response, "schema")
+	examples := removeKey(response, "examples")
+	if headers := mappingValue(response, "headers"); headers != nil {
+		for i := 0; i+1 < len(headers.Content); i += 2 {
+			header := headers.Content[i+1]
+			s := parameterSchema(header)
+			c.convertSchema(s)
+			setValue(header, keyLike(header, "schema"), s)
+		}
+	}
+	if schema == nil && examples == nil {
+		return
+	}
+
+	content := newMapping(response)
+	mimes := produces
+	for i, mime := range mimes {
+		media := newMapping(response)
+		if schema != nil {
+			s := schema
+			if i > 0 {
+				s = deepCopyNode(schema)
+			}
+			c.convertSchema(s)
+			setValue(media, keyLike(schema, "schema"), s)
+		}
+		if examples != nil {
+			if example := mappingValue(examples, mime); example != nil {
+				setValue(media, keyLike(example, "example"), example)
+			}
+		}
+		setValue(content, scalarLike(response, mime), media)
+	}
+	setValue(response, keyLike(response, "content"), content)
+}
+
+// Ajusta um schema: $refs, type: file, x-nullable e discriminator
+func (c *swaggerConverter) convertSchema(schema *yaml.Node) {
+	if schema == nil || schema.Kind != yaml.MappingNode {
+		return
+	}
+	if ref := mappingValue(schema, "$ref"); ref != nil {
+		ref.Value = convertRef(ref.Value, c.bodyParameters)
+		return
+	}
+	if t := mappingValue(schema, "type"); t != nil && t.Value == "file" {
+		t.Value = "string"
+		setValue(schema, keyLike(t, "format"), scalarLike(t, "binary"))
+	}
+	if nullable := removeKey(schema, "x-nullable"); nullable != nil {
+		setValue(schema, keyLike(nullable, "nullable"), nullable)
+	}
+	if d := mappingValue(schema, "discriminator"); d != nil && d.Kind == yaml.ScalarNode {
+		mapping := newMapping(d)
+		setValue(mapping, keyLike(d, "propertyName"), scalarLike(d, d.Value))
+		*d = *mapping
+	}
+	for _, key := range []string{"items", "additionalProperties", "not"} {
+		c.convertSchema(mappingValue(schema, key))
+	}
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		if list := mappingValue(schema, key); list != nil {
+			for _, item := range list.Content {
+				c.convertSchema(item)
+			}
+		}
+	}
+	if properties := mappingValue(schema, "properties"); properties != nil {
+		for i := 0; i+1 < len(properties.Content); i += 2 {
+			c.convertSchema(properties.Content[i+1])
+		}
+	}
+}
+
+// Converte um securityDefinition em securityScheme
+func convertSecurityScheme(scheme *yaml.Node) {
+	t := mappingValue(scheme, "type")
+	if t == nil {
+		return
+	}
+	switch t.Value {
+	case "basic":
+		t.Value = "http"
+		setValue(scheme, keyLike(t, "scheme"), scalarLike(t, "basic"))
+	case "oauth2":
+		flowName := removeKey(scheme, "flow")
+		if flowName == nil {
+			return
+		}
+		names := map[string]string{"implicit": "implicit", "password": "password", "application": "clientCredentials", "accessCode": "authorizationCode"}
+		flow := newMapping(flowName)
+		for _, field := range []string{"authorizationUrl", "tokenUrl", "scopes"} {
+			if value := removeKey(scheme, field); value != nil {
+				setValue(flow, keyLike(value, field), value)
+			}
+		}
+		if mappingValue(flow, "scopes") == nil {
+			setValue(flow, keyLike(flowName, "scopes"), newMapping(flowName))
+		}
+		flows := newMapping(flowName)
+		setValue(flows, scalarLike(flowName, names[flowName.Value]), flow)
+		setValue(scheme, keyLike(flowName, "flows"), flows)
+	}
+}
+
+// Aponta $refs locais para as novas posições em components
+func convertRef(ref string, bodyParameters map[string]bool) string {
+	switch {
+	case strings.HasPrefix(ref, "#/definitions/"):
+		return "#/components/schemas/" + strings.TrimPrefix(ref, "#/definitions/")
+	case strings.HasPrefix(ref, "#/parameters/"):
+		name := strings.TrimPrefix(ref, "#/parameters/")
+		if bodyParameters[name] {
+			return "#/components/requestBodies/" + name
+		}
+		return "#/components/parameters/" + name
+	case strings.HasPrefix(ref, "#/responses/"):
+		return "#/components/responses/" + strings.TrimPrefix(ref, "#/responses/")
+	}
+	return ref
+}
+
+// Valores de uma lista de escalares, ou o padrão quando a lista não existe
+func scalarList(node *yaml.Node, defaults ...string) []string {
+	if node == nil || node.Kind != yaml.SequenceNode || len(node.Content) == 0 {
+		return defaults
+	}
+	values := make([]string, 0, len(node.Content))
+	for _, item := range node.Content {
+		values = append(values, item.Value)
+	}
+	return values
+}
+
+// Novos nós herdam a posição do nó de origem, para que as violações apontem para ele
+func newMapping(origin *yaml.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: origin.Line, Column: origin.Column}
+}
+
+func scalarLike(origin *yaml.Node, value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Line: origin.Line, Column: origin.Column}
+}
+
+func keyLike(origin *yaml.Node, key string) *yaml.Node {
+	return scalarLike(origin, key)
+}
+
+func boolLike(origin *yaml.Node, value bool) *yaml.Node {
+	node := scalarLike(origin, "false")
+	node.Tag = "!!bool"
+	if value {
+		node.Value = "true"
+	}
+	return node
+}
+
+func refNode(origin *yaml.Node, ref string) *yaml.Node {
+	node := newMapping(origin)
+	setValue(node, keyLike(origin, "$ref"), scalarLike(origin, ref))
+	return node
+}
+
+// Define o valor de uma chave no mapping, substituindo o existente
+func setValue(mapping, key, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key.Value {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, key, value)
+}
+
+// Remove a chave do mapping e retorna o valor removido
+func removeKey(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			value := mapping.Content[i+1]
+			mapping.Content = append(mapping.Content[:i:i], mapping.Content[i+2:]...)
+			return value
+		}
+	}
+	return nil
+}
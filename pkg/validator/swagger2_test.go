@@ -0,0 +1,90 @@
+package validator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Converte a especificação Swagger 2.0 de testdata/swagger2 e compara o documento
+// gerado com o esperado em testdata/golden (regrave com -update)
+func TestConvertSwagger2(t *testing.T) {
+	for _, fixture := range []struct{ spec, golden string }{
+		{filepath.Join("testdata", "swagger2", "convert.yaml"), "swagger2-convert.yaml"},
+		{filepath.Join("testdata", "e2e", "swagger2", "api.yaml"), "swagger2-e2e.yaml"},
+	} {
+		root := mustParseYAML(t, string(mustReadFile(t, fixture.spec)))
+		if !isSwagger2(root) {
+			t.Fatalf("%s deveria ser Swagger 2.0", fixture.spec)
+		}
+		convertSwagger2(root)
+		if isSwagger2(root) {
+			t.Errorf("%s continua Swagger 2.0 depois da conversão", fixture.spec)
+		}
+		var buf bytes.Buffer
+		if err := encodeYAMLNode(&buf, root, 2, "", nil); err != nil {
+			t.Fatal(err)
+		}
+		compareGolden(t, filepath.Join("testdata", "golden", fixture.golden), buf.String())
+	}
+}
+
+// Os nós criados pela conversão ficam na linha do trecho de origem, para que as
+// violações apontem para o arquivo Swagger 2.0
+func TestConvertSwagger2KeepsLines(t *testing.T) {
+	root := mustParseYAML(t, string(mustReadFile(t, filepath.Join("testdata", "swagger2", "convert.yaml"))))
+	convertSwagger2(root)
+	doc := documentContent(root)
+	paths := mappingValue(doc, "paths")
+	for _, check := range []struct {
+		name string
+		line int
+		want int
+	}{
+		{"servers", mappingValue(doc, "servers").Line, 5},
+		{"components.schemas", mappingValue(mappingValue(doc, "components"), "schemas").Line, 75},
+		{"requestBody do POST (parâmetro body global)", mappingValue(mappingValue(mappingValue(paths, "/pagamentos"), "post"), "requestBody").Line, 58},
+		{"requestBody do PUT (formData)", mappingValue(mappingValue(mappingValue(paths, "/pagamentos/{id}/comprovante"), "put"), "requestBody").Line, 70},
+		{"schema do parâmetro de path", mappingValue(mappingValue(mappingValue(paths, "/pagamentos/{id}/comprovante"), "parameters").Content[0], "schema").Line, 66},
+	} {
+		if check.line != check.want {
+			t.Errorf("%s na linha %d, esperado %d", check.name, check.line, check.want)
+		}
+	}
+}
+
+// Com --no-convert, o Swagger 2.0 é validado como está e as regras exclusivas de
+// OpenAPI 3 ficam de fora
+func TestNoConvert(t *testing.T) {
+	dir := t.TempDir()
+	rules := filepath.Join(dir, "regras.yaml")
+	ruleset := `rules:
+  schemas-com-descricao:
+    description: Schemas precisam de descrição.
+    given: $.components.schemas[*]
+    then: {field: description, function: truthy}
+  definicoes-com-descricao:
+    description: Definições precisam de descrição.
+    given: $.definitions[*]
+    then: {field: description, function: truthy}
+`
+	if err := os.WriteFile(rules, []byte(ruleset), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	spec := filepath.Join("testdata", "swagger2", "convert.yaml")
+
+	var out, errOut bytes.Buffer
+	Run([]string{"validate", "--no-cache", "--rules", rules, spec}, &out, &errOut)
+	if converted := out.String(); !strings.Contains(converted, "Schemas precisam de descrição") || strings.Contains(converted, "Definições precisam") {
+		t.Errorf("convertido, só a regra de components.schemas deveria apontar:\n%s%s", converted, errOut.String())
+	}
+
+	out.Reset()
+	errOut.Reset()
+	Run([]string{"validate", "--no-cache", "--no-convert", "--rules", rules, spec}, &out, &errOut)
+	if kept := out.String(); strings.Contains(kept, "Schemas precisam de descrição") || !strings.Contains(kept, "Definições precisam de descrição") || !strings.Contains(kept, "linha 76") {
+		t.Errorf("com --no-convert, só a regra de definitions deveria apontar, na linha do arquivo:\n%s%s", kept, errOut.String())
+	}
+}
@@ -0,0 +1,148 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Seções de operações que uma regra com given em $.paths pode cobrir (targets). Sem
+// targets, só paths; webhooks (OpenAPI 3.1) e callbacks recebem o mesmo given com
+// $.paths trocado por $.webhooks e por $..callbacks[*] (os callbacks das operações e
+// os de components.callbacks).
+//
+//	given: "$.paths[*][*]"
+//	targets: [paths, webhooks, callbacks]
+var ruleTargetPrefixes = map[string]string{
+	"paths":     "$.paths",
+	"webhooks":  "$.webhooks",
+	"callbacks": "$..callbacks[*]",
+}
+
+var ruleTargetNames = []string{"paths", "webhooks", "callbacks"}
+
+// Função para ler o targets de uma regra (um nome ou uma lista); nulo quando a regra
+// não tem targets e vale só para paths
+func parseRuleTargets(raw interface{}, given string) ([]string, error) {
+	var names []string
+	switch value := raw.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		names = []string{value}
+	case []interface{}:
+		for _, item := range value {
+			name, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("targets deve ser uma lista com paths, webhooks ou callbacks, encontrado %s na lista", ruleValueType(item))
+			}
+			names = append(names, name)
+		}
+	default:
+		return nil, fmt.Errorf("targets deve ser uma lista com paths, webhooks ou callbacks, encontrado %s", ruleValueType(raw))
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("targets não pode ser uma lista vazia")
+	}
+	seen := map[string]bool{}
+	var targets []string
+	for _, name := range names {
+		if ruleTargetPrefixes[name] == "" {
+			return nil, fmt.Errorf("targets %q desconhecido (use paths, webhooks ou callbacks)%s", name, suggestionSuffix(name, ruleTargetNames))
+		}
+		if !seen[name] {
+			seen[name] = true
+			targets = append(targets, name)
+		}
+	}
+	if pathsSuffix(given) == nil {
+		return nil, fmt.Errorf("targets só vale para regras com given em $.paths (ex.: \"$.paths[*][*]\"), encontrado %q", given)
+	}
+	return targets, nil
+}
+
+// Trecho do given depois de $.paths; nulo quando o given não começa em $.paths
+func pathsSuffix(given string) *string {
+	rest := strings.TrimPrefix(given, "$.paths")
+	if rest == given || (rest != "" && rest[0] != '.' && rest[0] != '[') {
+		return nil
+	}
+	return &rest
+}
+
+// Expressões avaliadas para a regra: o given em cada seção de targets
+func (r *compiledRule) targetGivens() []string {
+	rest := pathsSuffix(r.given)
+	if r.targets == nil || rest == nil {
+		return []string{r.given}
+	}
+	givens := make([]string, 0, len(r.targets))
+	for _, target := range r.targets {
+		givens = append(givens, ruleTargetPrefixes[target]+*rest)
+	}
+	return givens
+}
+
+// Função para descrever onde fica um nó de webhook ou de callback, para as mensagens
+// das regras com targets: "webhook novoPagamento" ou "callback onEvento de POST
+// /pagamentos"; vazio para os nós de paths fora de callbacks
+func targetContext(path string) string {
+	segments, _, err := parseJSONPath(strings.TrimSuffix(path, "~"))
+	if err != nil || len(segments) < 2 {
+		return ""
+	}
+	// O callback mais interno é o que contém o nó
+	for k := len(segments) - 2; k >= 0; k-- {
+		if segments[k].name != "callbacks" || segments[k].isIndex || segments[k+1].isIndex {
+			continue
+		}
+		name := segments[k+1].name
+		if k == 1 && segments[0].name == "components" {
+			return "callback " + name + " de components"
+		}
+		prefix := segmentsPath(segments[:k])
+		if owner, ok := owningOperation(prefix); ok && owner.Method != "" && k == 3 {
+			return fmt.Sprintf("callback %s de %s %s", name, strings.ToUpper(owner.Method), owner.displayPath())
+		}
+		if outer := targetContext(prefix); outer != "" {
+			return "callback " + name + " de " + outer
+		}
+		return "callback " + name
+	}
+	if segments[0].name == "webhooks" && !segments[1].isIndex {
+		return "webhook " + segments[1].name
+	}
+	return ""
+}
+
+// JSONPath concreto dos segmentos
+func segmentsPath(segments []pathSegment) string {
+	path := "$"
+	for _, s := range segments {
+		if s.isIndex {
+			path += fmt.Sprintf("[%d]", s.index)
+			continue
+		}
+		path = joinPath(path, s.name)
+	}
+	return path
+}
+
+// Função para identificar a operação dona do JSONPath nas mensagens: método e path
+// (ou webhook:<nome>) e, dentro de um callback, método e expressão do callback com o
+// contexto (ex.: POST {$request.body#/url} (callback onEvento de POST /pagamentos))
+func operationLabel(path string) (string, bool) {
+	segments, _, err := parseJSONPath(strings.TrimSuffix(path, "~"))
+	if err != nil {
+		return "", false
+	}
+	for k := len(segments) - 4; k >= 0; k-- {
+		if segments[k].name == "callbacks" && !segments[k+2].isIndex && httpMethods[segments[k+3].name] {
+			return fmt.Sprintf("%s %s (%s)", strings.ToUpper(segments[k+3].name), segments[k+2].name, targetContext(path)), true
+		}
+	}
+	owner, ok := owningOperation(path)
+	if !ok || owner.Method == "" {
+		return "", false
+	}
+	return strings.ToUpper(owner.Method) + " " + owner.displayPath(), true
+}
@@ -0,0 +1,88 @@
+package validator
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const targetsSpec = `openapi: 3.1.0
+info: {title: Pagamentos, version: 1.0.0}
+paths:
+  /pagamentos:
+    post:
+      requestBody:
+        content: {application/json: {schema: {type: object}}}
+      callbacks:
+        onEvento:
+          '{$request.body#/url}':
+            post:
+              requestBody:
+                content: {application/json: {schema: {type: object}}}
+              responses: {'200': {description: ok}}
+      responses: {'201': {description: criado}}
+webhooks:
+  novoPagamento:
+    post:
+      requestBody:
+        content: {application/json: {schema: {type: object}}}
+      responses: {'200': {description: ok}}
+components:
+  callbacks:
+    Notificacao:
+      '{$request.body#/callbackUrl}':
+        put:
+          requestBody:
+            content: {application/json: {schema: {type: object}}}
+          responses: {'200': {description: ok}}
+`
+
+// Sem targets, a regra vale só para paths; com targets, também para webhooks e
+// callbacks (os das operações e os de components), com o contexto nas mensagens
+func TestRuleTargets(t *testing.T) {
+	rules := func(targets string) string {
+		return "rules:\n  corpo:\n    given: $.paths[*][*]\n" + targets + "    then: {function: requestBody}\n"
+	}
+	if found := ruleViolations(t, targetsSpec, rules(""), "corpo"); len(found) != 1 || !strings.Contains(found[0].Message, "o corpo de POST /pagamentos não") {
+		t.Errorf("sem targets, esperada só a violação de POST /pagamentos: %v", found)
+	}
+
+	found := ruleViolations(t, targetsSpec, rules("    targets: [paths, webhooks, callbacks]\n"), "corpo")
+	want := []string{
+		"o corpo de POST /pagamentos não",
+		"o corpo de POST webhook:novoPagamento não",
+		"o corpo de POST {$request.body#/url} (callback onEvento de POST /pagamentos) não",
+		"o corpo de PUT {$request.body#/callbackUrl} (callback Notificacao de components) não",
+	}
+	if len(found) != len(want) {
+		t.Fatalf("esperadas %d violações, encontradas %d: %v", len(want), len(found), found)
+	}
+	for _, w := range want {
+		ok := false
+		for _, v := range found {
+			ok = ok || strings.Contains(v.Message, w)
+		}
+		if !ok {
+			t.Errorf("nenhuma violação com %q: %v", w, found)
+		}
+	}
+}
+
+// targets malformado, desconhecido ou fora de um given em $.paths é erro de uso
+func TestRuleTargetsErrors(t *testing.T) {
+	for _, tt := range []struct {
+		given, targets, message string
+	}{
+		{"$.paths[*][*]", "[paths, webhook]", `targets "webhook" desconhecido (use paths, webhooks ou callbacks) (você quis dizer webhooks?)`},
+		{"$.paths[*][*]", "[]", "targets não pode ser uma lista vazia"},
+		{"$.paths[*][*]", "{paths: true}", "targets deve ser uma lista com paths, webhooks ou callbacks, encontrado objeto"},
+		{"$.info", "webhooks", `targets só vale para regras com given em $.paths (ex.: "$.paths[*][*]"), encontrado "$.info"`},
+		{"$.pathsX", "webhooks", "targets só vale para regras com given em $.paths"},
+	} {
+		rules := "rules:\n  a:\n    given: " + tt.given + "\n    targets: " + tt.targets + "\n    then: {field: description, function: truthy}\n"
+		_, err := Validate(context.Background(), []byte(targetsSpec), Options{Source: "api.yaml", Rules: []byte(rules)})
+		if err == nil || rulesExitCode(err) != exitUsage || !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("%s: erro %v, esperado erro de uso com %q", tt.targets, err, tt.message)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Toda saída da ferramenta é UTF-8 sem BOM e sem escapes desnecessários: o JSON não
+// troca <, > e & (comuns em descrições) por \u003c, \u003e e \u0026, e o YAML escreve
+// os acentos e os emoji como estão.
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Encoder JSON das saídas: UTF-8 sem escapar <, > e &
+func newJSONEncoder(w io.Writer) *json.Encoder {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder
+}
+
+// Serializa um valor em JSON como newJSONEncoder, com indentação opcional e sem a
+// quebra de linha final
+func marshalJSON(v interface{}, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := newJSONEncoder(&buf)
+	if indent != "" {
+		encoder.SetIndent("", indent)
+	}
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Serializa um valor em YAML como yaml.Marshal (indentação de 4), com os emoji
+// escritos como estão
+func marshalYAML(v interface{}) ([]byte, error) {
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return nil, err
+	}
+	// node.Encode passa pelo texto do yaml.v3, que já pôs entre aspas duplas os textos
+	// com emoji; sem o estilo, o encoder escolhe de novo, já com os emoji protegidos
+	clearSupplementaryQuoting(&node)
+	var buf bytes.Buffer
+	if err := encodeYAMLNode(&buf, &node, 4, "", nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// O yaml.v3 considera não imprimíveis os caracteres fora do plano básico (emoji, que
+// ocupam 4 bytes em UTF-8): o texto passa a ser escrito entre aspas duplas, com
+// escapes como \U0001F680. Antes da serialização, cada um desses caracteres é trocado
+// por um caractere de uso privado que não aparece no nó, e o texto gerado recebe o
+// original de volta; os valores do nó são restaurados em seguida. Sem esses
+// caracteres, restore e o replacer são nulos.
+func protectSupplementary(node *yaml.Node) (restore func(), replacer *strings.Replacer) {
+	var scalars []*yaml.Node
+	used := map[rune]bool{}
+	supplementary := map[rune]bool{}
+	seen := map[*yaml.Node]bool{}
+	var visit func(n *yaml.Node)
+	visit = func(n *yaml.Node) {
+		if n == nil || seen[n] {
+			return
+		}
+		seen[n] = true
+		found := false
+		for _, r := range n.Value {
+			switch {
+			case r > 0xFFFF:
+				supplementary[r], found = true, true
+			case r >= 0xE000 && r <= 0xF8FF:
+				used[r] = true
+			}
+		}
+		if found {
+			scalars = append(scalars, n)
+		}
+		for _, child := range n.Content {
+			visit(child)
+		}
+	}
+	visit(node)
+	if len(scalars) == 0 {
+		return nil, nil
+	}
+
+	placeholders := map[rune]rune{}
+	var pairs []string
+	next := rune(0xE000)
+	for r := range supplementary {
+		for used[next] && next < 0xF8FF {
+			next++
+		}
+		if used[next] {
+			// Sem caracteres de uso privado livres, o texto fica com os escapes do yaml.v3
+			return nil, nil
+		}
+		placeholders[r] = next
+		used[next] = true
+		pairs = append(pairs, string(next), string(r))
+	}
+	original := make([]string, len(scalars))
+	for i, n := range scalars {
+		original[i] = n.Value
+		n.Value = strings.Map(func(r rune) rune {
+			if p, ok := placeholders[r]; ok {
+				return p
+			}
+			return r
+		}, n.Value)
+	}
+	restore = func() {
+		for i, n := range scalars {
+			n.Value = original[i]
+		}
+	}
+	return restore, strings.NewReplacer(pairs...)
+}
+
+// Tira o estilo de aspas duplas dos escalares com caracteres fora do plano básico
+func clearSupplementaryQuoting(node *yaml.Node) {
+	if node == nil {
+		return
+	}
+	if node.Kind == yaml.ScalarNode && node.Style == yaml.DoubleQuotedStyle && strings.IndexFunc(node.Value, func(r rune) bool { return r > 0xFFFF }) >= 0 {
+		node.Style = 0
+	}
+	for _, child := range node.Content {
+		clearSupplementaryQuoting(child)
+	}
+}
+
+// Remove o BOM do início de um conteúdo a ser gravado; as saídas são sempre UTF-8 sem BOM
+func withoutBOM(data []byte) []byte {
+	for bytes.HasPrefix(data, utf8BOM) {
+		data = data[len(utf8BOM):]
+	}
+	return data
+}
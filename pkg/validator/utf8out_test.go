@@ -0,0 +1,119 @@
+package validator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Especificação com acentos, emoji e os caracteres que o JSON escaparia (<, > e &),
+// gravada com BOM
+const utf8Spec = "\xEF\xBB\xBFopenapi: 3.0.3\n" + `info:
+  title: Cartões de crédito 💳
+  version: 1.0.0
+  description: Consulta de faturas & <limites> — ação em produção 🚀
+paths:
+  /cartoes:
+    get:
+      summary: Lista os cartões
+      responses:
+        '200': {description: Operação concluída ✅}
+`
+
+const utf8Rules = `rules:
+  contato:
+    description: A API precisa de contato — é obrigatório <sempre> & 🚨
+    given: $.info
+    severity: warning
+    then: {field: contact, function: truthy}
+`
+
+var utf8Texts = []string{"Cartões de crédito 💳", "faturas & <limites> — ação em produção 🚀", "é obrigatório <sempre> & 🚨"}
+
+// Os textos com acentos, emoji e <, > e & chegam como estão, sem BOM e sem escapes, ao
+// JSON, ao SARIF e ao documento resolvido em YAML e em JSON
+func TestUTF8Outputs(t *testing.T) {
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "api.yaml")
+	rules := filepath.Join(dir, "regras.yaml")
+	if err := os.WriteFile(spec, []byte(utf8Spec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(rules, []byte(utf8Rules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(name string, output []byte, texts []string) {
+		t.Helper()
+		if bytes.HasPrefix(output, utf8BOM) {
+			t.Errorf("%s começa com BOM", name)
+		}
+		for _, escape := range []string{`\u00`, `\u003c`, `\u0026`, `\U0001F`, `\ud83d`} {
+			if bytes.Contains(output, []byte(escape)) {
+				t.Errorf("%s com o escape %s:\n%s", name, escape, output)
+			}
+		}
+		for _, text := range texts {
+			if !bytes.Contains(output, []byte(text)) {
+				t.Errorf("%s sem %q:\n%s", name, text, output)
+			}
+		}
+	}
+
+	for _, format := range []string{"json", "sarif"} {
+		var out, errOut bytes.Buffer
+		if code := Run([]string{"validate", "--no-cache", "--format", format, "--rules", rules, spec}, &out, &errOut); code != exitOK {
+			t.Fatalf("validate --format %s: exit %d\n%s%s", format, code, out.String(), errOut.String())
+		}
+		check("validate --format "+format, out.Bytes(), utf8Texts[2:])
+	}
+
+	for _, name := range []string{"resolvido.yaml", "resolvido.json"} {
+		output := filepath.Join(dir, name)
+		var out, errOut bytes.Buffer
+		if code := Run([]string{"resolve", "--no-cache", "-o", output, spec}, &out, &errOut); code != exitOK {
+			t.Fatalf("resolve -o %s: exit %d\n%s%s", name, code, out.String(), errOut.String())
+		}
+		check(name, mustReadFile(t, output), utf8Texts[:2])
+	}
+}
+
+// O emoji é escrito como está mesmo quando o texto já usa caracteres de uso privado,
+// e o nó volta com os valores originais
+func TestMarshalYAMLSupplementary(t *testing.T) {
+	data, err := marshalYAML(map[string]string{"titulo": "Pix 🚀 \uE000 fim"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "titulo: Pix 🚀 \uE000 fim\n"; string(data) != want {
+		t.Errorf("YAML %q, esperado %q", data, want)
+	}
+
+	node := mustParseYAML(t, "a: ação 🚀\n")
+	var buf bytes.Buffer
+	if err := encodeYAMLNode(&buf, node, 2, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "a: ação 🚀\n" {
+		t.Errorf("YAML %q, esperado o texto original", buf.String())
+	}
+	if value := node.Content[0].Content[1].Value; value != "ação 🚀" {
+		t.Errorf("nó alterado pela serialização: %q", value)
+	}
+}
+
+// marshalJSON não escapa <, > e &, e withoutBOM remove todos os BOMs do início
+func TestMarshalJSONAndBOM(t *testing.T) {
+	data, err := marshalJSON(map[string]string{"d": "<a> & ção"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"d":"<a> & ção"}`; string(data) != want {
+		t.Errorf("JSON %s, esperado %s", data, want)
+	}
+	twice := append(append(append([]byte{}, utf8BOM...), utf8BOM...), "ação"...)
+	if got := string(withoutBOM(twice)); got != "ação" {
+		t.Errorf("withoutBOM: %q", got)
+	}
+}
@@ -0,0 +1,374 @@
+package validator
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Flags do subcomando validate
+type validateOptions struct {
+	rules      string
+	format     string
+	printPaths bool
+	manifest   string
+	report     string
+	timeout    time.Duration
+	exclude    stringList
+}
+
+func (o *validateOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.rules, "rules", "", "arquivo de regras ou nome de pacote embarcado (padrão: o da configuração ou ofb)")
+	fs.StringVar(&o.format, "format", "text", "formato da saída: text, json ou sarif (jsonl com --print-paths)")
+	fs.BoolVar(&o.printPaths, "print-paths", false, "imprime uma linha por operação: METHOD path STATUS")
+	fs.StringVar(&o.manifest, "manifest", "", "manifesto com as APIs a validar")
+	fs.StringVar(&o.report, "report", "", "arquivo onde salvar o relatório agregado do manifesto ou diretório")
+	fs.DurationVar(&o.timeout, "timeout", defaultTimeout, "tempo máximo da execução (0 desativa)")
+	fs.Var(&o.exclude, "exclude", "padrão (estilo .gitignore) a ignorar ao validar um diretório; pode ser repetido")
+	registerEnvFlag(fs)
+	registerCacheFlags(fs)
+	registerStrictYAMLFlag(fs)
+	registerStrictFlag(fs)
+	registerExamplesFlag(fs)
+	registerCoverageFlag(fs)
+	registerFixFlags(fs)
+	registerComplexityFlags(fs)
+	registerDuplicatesFlag(fs)
+	registerInlineReuseFlag(fs)
+	registerExtensionFlags(fs)
+	registerPathConflictFlags(fs)
+	registerVersionFlags(fs)
+	registerExamplesMatrixFlags(fs)
+	registerLinkFlags(fs)
+	registerConcurrencyFlag(fs)
+	registerProgressFlag(fs)
+	registerProfileFlag(fs)
+	registerRefFlags(fs)
+	registerHTTPFlags(fs)
+	registerEncodingFlag(fs)
+	registerSelectDocumentFlag(fs)
+	registerInputLimitFlag(fs)
+	registerProfileMemFlag(fs)
+	registerOverlayFlags(fs)
+	registerConvertFlag(fs)
+	registerGitHubFlags(fs)
+	registerNotifyFlags(fs)
+}
+
+var validateCommand = &command{
+	Name:    "validate",
+	Args:    "<spec.yaml | diretório>",
+	Summary: "valida especificações com um conjunto de regras",
+	Description: `Valida uma especificação com as regras informadas em --rules, as da
+configuração do projeto ou o pacote ofb embarcado. Com um diretório, valida
+cada especificação encontrada, respeitando .openapiignore e --exclude. Com
+--manifest, valida as APIs listadas no manifesto, cada uma com o seu conjunto
+de regras.
+
+O resultado de cada especificação fica no cache (--cache-dir, padrão:
+openapi-ci no diretório de cache do usuário, ex.: ~/.cache/openapi-ci; use
+--cache-dir .openapi-ci-cache para mantê-lo no projeto) pelo conteúdo da
+especificação e dos arquivos que ela referencia, das regras já com os extends,
+das opções e da versão da ferramenta; na execução seguinte sem mudanças o
+relatório é reaproveitado e marcado como "resultado em cache" ("cached": true
+no JSON). --no-cache valida tudo de novo; com --check-links ou
+--allow-remote-refs o cache de resultados não é usado.
+
+--rules e os "extends" aceitam URLs https://, buscadas com cache em disco
+(revalidado por ETag/Last-Modified). Cabeçalhos extras vêm de --http-header e o
+token de OFBCI_HTTP_TOKEN é enviado como Authorization nas URLs https://; com
+--offline, apenas o cache é usado. Os pacotes embarcados (veja "rules list
+--builtin") são usados pelo nome; um arquivo existente com o mesmo nome tem
+precedência. Arquivos do Spectral (.spectral.yaml) são traduzidos ao carregar;
+as regras sem equivalente ficam de fora com um aviso (veja "rules convert").
+
+As operações com x-maturity (proposed, current ou deprecated) são conferidas
+sempre: valor conhecido, coerência com deprecated: true, x-sunset-date nas
+depreciadas e nenhuma proposed em versão GA (info.version sem sufixo de
+pré-lançamento). O resumo lista as operações de cada estágio.
+
+Os links das respostas também são conferidos sempre: operationId ou
+operationRef levam a uma operação do documento, os parâmetros existem na
+operação de destino e as expressões ($request.path.id,
+$response.body#/data/accountId) apontam para partes da requisição ou da
+resposta de origem.
+
+Os paths também são comparados entre si: templates equivalentes
+(/contas/{contaId} e /contas/{id}, ou que diferem só na barra final) são
+relatados com a severidade de --path-conflicts (padrão: error) e um segmento
+fixo na posição do parâmetro de outro path (/contas/saldos e /contas/{id}),
+que cada roteador resolve de um jeito, com a de --path-overlaps (padrão:
+warning). off desativa cada verificação.
+
+O major de info.version é comparado com o do caminho do arquivo
+(--version-path-pattern, padrão: um diretório v2/) e com o segmento de versão
+de cada URL de servidor (e do basePath no Swagger 2.0); uma divergência é
+relatada com a severidade de --version-consistency (padrão: warning), listando
+todas as fontes. Fontes sem versão ficam de fora, --version-path-pattern ''
+dispensa o caminho e as versões de pré-lançamento (2.0.0-rc.1) só são
+conferidas com --version-prerelease check.
+
+Com --examples-matrix, o relatório traz a matriz de exemplos das respostas:
+para cada operação, as combinações de código de resposta e media type com ao
+menos um exemplo (examples ou example do media type, ou example no próprio
+schema, com os $refs resolvidos), no resumo em texto, no JSON
+(examplesMatrix) e no comentário do PR. Com --examples-min-coverage N, as
+operações com menos de N% das combinações exemplificadas são relatadas com a
+severidade de --examples-coverage-severity (padrão: warning), listando as que
+faltam.
+
+As extensões (x-) são conferidas contra o registro de extensões (--extensions
+ou extensions em .openapi-ci.yaml; padrão: o registro ofb embarcado): cada
+extensão do registro precisa ter um valor válido para o schema dela e
+aparecer só nos locais permitidos (operation, pathItem, info, schema...). As
+extensões fora do registro são relatadas com a severidade de
+--unregistered-extensions (padrão: info; off desativa).
+
+Regras com severities têm uma severidade por perfil (ex.: consultation:
+warning, ga: error). --profile escolhe o perfil; com auto (padrão), versões de
+pré-lançamento ou 0.x usam consultation e as demais, ga.
+
+Com --duplicates exact, os schemas de components que ficam idênticos depois
+da resolução (chaves em qualquer ordem, sem a description do componente) são
+relatados em grupos, com a quantidade de $refs de cada um; near também agrupa
+os que diferem só em descrições, títulos e exemplos.
+
+Com --inline-reuse N, os parâmetros e cabeçalhos de resposta definidos inline
+com a mesma definição (sem contar descrições e exemplos) mais de N vezes são
+relatados com todos os locais e o nome sugerido em components, ou o componente
+que já tem a mesma definição.
+
+Com --fix, as regras marcadas com fixable: true que têm correção automática
+(only-https, schema-additional-properties, operation-tags e
+operation-id-casing) corrigem a especificação no próprio arquivo, preservando
+comentários e a ordem das chaves, e a validação roda sobre o arquivo
+corrigido. --fix-dry-run mostra o diff sem gravar.`,
+	Examples: []string{
+		programName + " validate swagger.yaml",
+		programName + " validate --rules pb33f_rules.yaml --format json swagger.yaml",
+		programName + " validate --examples-matrix --examples-min-coverage 80 swagger.yaml",
+		programName + " validate --rules .spectral.yaml swagger.yaml",
+		programName + " validate --print-paths --format jsonl swagger.yaml",
+		programName + " validate --format sarif swagger.yaml > resultados.sarif",
+		programName + " validate --coverage swagger.yaml",
+		programName + " validate --duplicates near --format json swagger.yaml",
+		programName + " validate --fix-dry-run swagger.yaml",
+		programName + " validate --inline-reuse 2 swagger.yaml",
+		programName + " validate --extensions extensoes.yaml --unregistered-extensions warning swagger.yaml",
+		programName + " validate --path-overlaps off swagger.yaml",
+		programName + " validate --version-path-pattern '/api-v(\\d+)/' swagger.yaml",
+		programName + " validate --check-links --link-allow-host intranet.banco.com.br swagger.yaml",
+		programName + " validate --exclude 'examples/**' specs/",
+		programName + " validate --manifest apis.yaml --report relatorio.json",
+		programName + " validate --rules https://raw.githubusercontent.com/org/regras/main/ofb.yaml --http-header 'Authorization: token ${GH_TOKEN}' swagger.yaml",
+	},
+	Flags: func(fs *flag.FlagSet) { new(validateOptions).register(fs) },
+	Run:   runValidate,
+}
+
+// Subcomando validate: valida uma especificação ou todas as APIs de um manifesto
+func runValidate(c *command, args []string) int {
+	opts := &validateOptions{}
+	fs := c.newFlagSet()
+	opts.register(fs)
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	if err := checkOverlayFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkHTTPFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkEncodingFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkProgressFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkDuplicatesFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkInlineReuseFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkExtensionFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkPathConflictFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkVersionFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkExamplesMatrixFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkSelectDocumentFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkGitHubFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkNotifyFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkFixFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkLinkFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkConcurrencyFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	fixing := fixSpecs || fixDryRun
+	setResultOptions(fs)
+
+	ctx, cancel := newRunContext(opts.timeout)
+	defer cancel()
+
+	if opts.manifest != "" {
+		if fixing {
+			return c.usageError("--fix aceita uma especificação, não um manifesto")
+		}
+		code := runManifest(ctx, opts.manifest, opts.report, opts.format)
+		if err := ctx.Err(); err != nil {
+			reportCancellation(err, opts.timeout)
+			return exitFailure
+		}
+		return code
+	}
+
+	if fs.NArg() != 1 {
+		return c.usageError("esperado um argumento (especificação ou diretório), recebidos %d", fs.NArg())
+	}
+	inputFile := fs.Arg(0)
+
+	ruleset := opts.rules
+	if ruleset == "" {
+		ruleset = "ofb"
+		if config, err := loadProjectConfig(projectConfigFile); err == nil && config != nil && config.Rules != "" {
+			ruleset = config.Rules
+		}
+	}
+
+	// Diretório: valida cada especificação encontrada, respeitando .openapiignore e --exclude
+	if info, err := os.Stat(inputFile); err == nil && info.IsDir() {
+		if fixing {
+			return c.usageError("--fix aceita uma especificação, não um diretório")
+		}
+		specs, err := discoverSpecs(inputFile, opts.exclude)
+		if err != nil {
+			fmt.Fprintln(stdout, "❌ Erro ao procurar especificações em", inputFile+":", err)
+			return exitFailure
+		}
+		if len(specs) == 0 {
+			fmt.Fprintln(stdout, "❌ Nenhuma especificação OpenAPI encontrada em", inputFile)
+			return exitFailure
+		}
+		var entries []manifestEntry
+		for _, spec := range specs {
+			entries = append(entries, manifestEntry{Name: spec, Spec: spec, Ruleset: ruleset})
+		}
+		code := runEntries(ctx, inputFile, entries, opts.report, opts.format)
+		if err := ctx.Err(); err != nil {
+			reportCancellation(err, opts.timeout)
+			return exitFailure
+		}
+		return code
+	}
+
+	rules, err := loadRuleset(flagSettings(), ruleset)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao carregar as regras:", err)
+		return rulesExitCode(err)
+	}
+
+	if fixing {
+		if err := fixSpec(inputFile, rules); err != nil {
+			fmt.Fprintln(stdout, "❌ Erro ao corrigir", inputFile+":", err)
+			return exitFailure
+		}
+	}
+
+	report, err := validateSpecReport(ctx, inputFile, rules)
+	if err != nil {
+		if isCancellation(err) {
+			reportCancellation(err, opts.timeout)
+			return exitFailure
+		}
+		fmt.Fprintln(stdout, "❌ Erro ao validar", inputFile+":", err)
+		notifyRun([]notificationSpec{notificationError(inputFile, err.Error())})
+		return exitFailure
+	}
+	violations := report.Violations
+	notifyRun([]notificationSpec{notificationFor(inputFile, violations)})
+	if githubComment {
+		commentOnPullRequest(validationMarkdown(githubCommentTitle, []*validationReport{report}, nil))
+	}
+
+	if opts.printPaths {
+		if err := printOperationStatus(inputFile, violations, opts.format); err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+	} else if opts.format == "json" {
+		if err := printJSONReport(jsonReport{SchemaVersion: reportSchemaVersion, Validation: report}); err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+	} else if opts.format == "sarif" {
+		if err := printSARIF([]*validationReport{report}); err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+	} else {
+		for _, v := range violations {
+			printViolation(v)
+		}
+		fmt.Fprintf(stdout, "🔎 %s: %d erros, %d avisos%s%s.\n", inputFile, report.Errors, report.Warnings, profileSuffix(report.Profile), cachedSuffix(report))
+		printCoverage(report.Coverage)
+		printMaturity(report.Maturity)
+		printExamplesMatrix(report.ExamplesMatrix)
+		printNotApplicable(report.NotApplicable)
+	}
+
+	if report.Errors > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// Imprime o status de cada operação: PASS quando nenhuma violação aponta para ela
+func printOperationStatus(inputFile string, violations []Violation, format string) error {
+	data, err := readSpecFile(inputFile)
+	if err != nil {
+		return err
+	}
+	rootNode, err := parseRootSpec(flagSettings(), data, inputFile)
+	if err != nil {
+		return err
+	}
+	grouped := groupViolationsByOperation(rootNode, violations)
+
+	encoder := newJSONEncoder(stdout)
+	for _, op := range listOperations(rootNode) {
+		status := "PASS"
+		if len(grouped[op]) > 0 {
+			status = "FAIL"
+		}
+		method := strings.ToUpper(op.Method)
+		if format == "jsonl" {
+			line := map[string]interface{}{"method": method, "path": op.displayPath(), "status": status, "violations": len(grouped[op])}
+			if err := encoder.Encode(line); err != nil {
+				return fmt.Errorf("erro ao gerar saída JSON: %v", err)
+			}
+			continue
+		}
+		fmt.Fprintln(stdout, method, op.displayPath(), status)
+	}
+	return nil
+}
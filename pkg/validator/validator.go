@@ -0,0 +1,1037 @@
+package validator
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pb33f/libopenapi/index"
+	"gopkg.in/yaml.v3"
+)
+
+// Função para converter para UTF-8, detectando a codificação pelo conteúdo
+func (s *runSettings) convertToUTF8(data []byte, source string) ([]byte, error) {
+	return s.decodeText(data, source, "auto")
+}
+
+// Função para ler um arquivo local, converter para UTF-8 e retornar os bytes
+func (s *runSettings) readFile(filePath string) ([]byte, error) {
+	data, err := s.readFileLimited(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o arquivo %s: %v", filePath, err)
+	}
+
+	// Converte para UTF-8 antes de processar
+	utf8Data, err := s.convertToUTF8(data, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return utf8Data, nil
+}
+
+// Função para ler uma especificação: como readFile, mas respeitando --encoding
+func (s *runSettings) readSpecFile(filePath string) ([]byte, error) {
+	data, err := s.readFileLimited(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o arquivo %s: %v", filePath, err)
+	}
+	return s.decodeText(data, filePath, s.encoding)
+}
+
+// Leituras dos comandos da CLI, com a configuração das flags
+func readFile(filePath string) ([]byte, error) {
+	return flagSettings().readFile(filePath)
+}
+
+func readSpecFile(filePath string) ([]byte, error) {
+	return flagSettings().readSpecFile(filePath)
+}
+
+// Função para gravar um arquivo de saída sem deixá-lo truncado: o conteúdo vai para
+// um arquivo temporário no mesmo diretório, que então substitui o destino
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(withoutBOM(data)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Função para serializar o documento em um arquivo temporário no diretório de path,
+// calculando tamanho e hash durante a escrita; o chamador renomeia ou remove o arquivo
+func encodeToTemp(path string, spec *resolvedSpec, format string) (string, *artifactInfo, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("erro ao salvar arquivo resolvido: %v", err)
+	}
+	artifact := newArtifactWriter()
+	buffered := bufio.NewWriterSize(tmp, 256<<10)
+	err = spec.encode(io.MultiWriter(buffered, artifact), format)
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", nil, fmt.Errorf("erro ao salvar arquivo resolvido: %v", err)
+	}
+	return tmp.Name(), artifact.info(path), nil
+}
+
+// Documento indexado e resolvido, com o resumo da resolução
+type resolvedSpec struct {
+	rootNode   yaml.Node
+	indent     int                          // indentação do arquivo de origem, mantida na saída
+	refNodes   map[*yaml.Node]*yaml.Node    // nós com $ref antes da resolução (--max-depth, tamanho e expansão da saída)
+	origins    map[*yaml.Node]string        // arquivo de origem dos nós vindos dos arquivos referenciados
+	refTargets map[*yaml.Node]refDefinition // destino de cada $ref, pelo nó do $ref
+	scalars    scalarSources                // texto original dos escalares dobrados, mantido na saída YAML
+	verbatim   []byte                       // entrada YAML sem $refs, gravada como está (veja sameTree)
+
+	sourceBytes int64 // tamanho da especificação e dos arquivos referenciados, antes da resolução
+
+	inputProblems []string        // problemas estruturais da entrada, para comparar com o artefato
+	refUsage      *refUsageReport // uso das referências antes da resolução (--ref-report)
+	report        resolutionReport
+}
+
+// Especificação lida uma única vez por execução e compartilhada pela validação, pela
+// resolução e pela comparação: o conteúdo, a árvore original (a das regras) e, sob
+// demanda, o índice e a resolução, que usam o mesmo rolodex sobre uma cópia da
+// árvore (a resolução substitui os $refs no lugar)
+type specDocument struct {
+	file     string
+	data     []byte
+	root     *yaml.Node
+	settings *runSettings
+
+	exclusive bool // a árvore original não é usada depois da resolução: dispensa a cópia
+
+	spec     *resolvedSpec
+	rolodex  *index.Rolodex
+	indexErr error // erro da indexação (cancelamento), devolvido de novo a cada chamada
+	resolved bool
+}
+
+// Função para ler a especificação (com conversão e overlays) para as etapas da execução
+func openSpecDocument(s *runSettings, inputFile string) (*specDocument, error) {
+	// Ler o arquivo e converter para UTF-8
+	data, err := s.readSpecFile(inputFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Criar um nó YAML a partir do arquivo (YAML ou JSON)
+	rootNode, err := parseRootSpec(s, data, inputFile)
+	if err != nil {
+		return nil, err
+	}
+	return &specDocument{file: inputFile, data: data, root: rootNode, settings: s}, nil
+}
+
+// Função para indexar as referências da especificação, uma vez; o resumo traz os
+// $refs quebrados e os ciclos encontrados pelo índice
+func (d *specDocument) index(ctx context.Context) (*resolvedSpec, error) {
+	if d.spec != nil || d.indexErr != nil {
+		return d.spec, d.indexErr
+	}
+	inputFile := d.file
+	d.settings.tracker.enter("index", inputFile)
+	tree := d.root
+	if !d.exclusive {
+		tree = deepCopyNode(d.root)
+	}
+	spec := &resolvedSpec{rootNode: *tree, indent: detectIndent(d.data), inputProblems: structuralProblems(d.root), report: resolutionReport{File: reportPath(inputFile), RefsResolved: map[string]int{}, Errors: []resolutionError{}, Cycles: []referenceCycle{}}}
+
+	// Conferir os destinos dos $refs antes da indexação, para relatar os quebrados com sugestões
+	files := checkRefTargets(d.settings, inputFile, &spec.rootNode, &spec.report)
+
+	// Criar um novo rolodex para gerenciar referências (locais e, se permitido, remotas).
+	// O progresso começa antes: o rolodex já lê os arquivos locais ao ser criado.
+	endProgress := d.settings.progress.begin("index", reportPath(inputFile), 0)
+	defer endProgress()
+	rolodex, err := newRolodex(d.settings, inputFile, &spec.rootNode, files)
+	if err != nil {
+		d.indexErr = err
+		return nil, err
+	}
+
+	// Indexar as referências do OpenAPI
+	var indexErr error
+	if err := runPhase(ctx, func() error {
+		indexErr = rolodex.IndexTheRolodex(ctx)
+		return nil
+	}); err != nil {
+		d.indexErr = err
+		return nil, err
+	}
+	if indexErr != nil {
+		spec.report.addErrors(inputFile, indexErr)
+	}
+	collectReferenceErrors(rolodex, inputFile, &spec.report)
+	d.spec, d.rolodex = spec, rolodex
+	return spec, nil
+}
+
+// Árvores indexadas pelo rolodex (a raiz e os arquivos referenciados), pelo caminho de
+// cada arquivo; só vale depois de index
+func (d *specDocument) indexedDocuments() map[string]*yaml.Node {
+	docs := map[string]*yaml.Node{d.file: &d.spec.rootNode}
+	for _, idx := range d.rolodex.GetIndexes() {
+		if path := idx.GetSpecAbsolutePath(); path != "" {
+			if _, ok := docs[path]; !ok {
+				docs[path] = idx.GetRootNode()
+			}
+		}
+	}
+	return docs
+}
+
+// Função para indexar e resolver as referências OpenAPI usando o rolodex, sem gravar nada
+func indexAndResolve(ctx context.Context, s *runSettings, inputFile string) (*resolvedSpec, error) {
+	doc, err := openSpecDocument(s, inputFile)
+	if err != nil {
+		return nil, err
+	}
+	doc.exclusive = true
+	return doc.resolve(ctx)
+}
+
+// Função para resolver as referências da especificação, uma vez, reaproveitando o
+// índice já montado pela validação
+func (d *specDocument) resolve(ctx context.Context) (*resolvedSpec, error) {
+	spec, err := d.index(ctx)
+	if err != nil || d.resolved {
+		return spec, err
+	}
+	d.resolved = true
+	inputFile, rolodex := d.file, d.rolodex
+
+	// Guardar os $refs que devem sobreviver à resolução (--keep-refs)
+	var kept []keptRef
+	if len(d.settings.keepRefs) > 0 {
+		nodes := []*yaml.Node{&spec.rootNode}
+		for _, idx := range rolodex.GetIndexes() {
+			nodes = append(nodes, idx.GetRootNode())
+		}
+		kept = collectKeptRefs(nodes, keepRefMatcher(d.settings.keepRefs))
+	}
+
+	// Registrar de onde cada componente é referenciado, enquanto os $refs existem (--ref-report)
+	if d.settings.refUsage {
+		spec.refUsage = collectRefUsage(inputFile, d.indexedDocuments())
+	}
+
+	// Guardar todos os nós com $ref para limitar a expansão e medir a saída
+	nodes := []*yaml.Node{&spec.rootNode}
+	for _, idx := range rolodex.GetIndexes() {
+		nodes = append(nodes, idx.GetRootNode())
+	}
+	spec.refNodes = map[*yaml.Node]*yaml.Node{}
+	for _, ref := range collectKeptRefs(nodes, func(string) bool { return true }) {
+		spec.refNodes[ref.node] = ref.original
+	}
+	spec.sourceBytes = d.sourceBytes()
+
+	// A origem dos nós só é conhecida antes que os $refs sejam trocados pelos destinos
+	spec.origins = recordNodeOrigins(inputFile, rolodex)
+	spec.refTargets = recordRefTargets(rolodex)
+	spec.scalars = d.foldedScalars()
+
+	// Sem $refs, o documento resolvido é a própria entrada: gravá-la como está mantém
+	// as linhas em branco, os espaços e a forma de cada escalar
+	if len(spec.refNodes) == 0 && !isJSONContent(d.data) {
+		if source, err := parseSpecDocument(d.data, inputFile, 0); err == nil && sameTree(source, &spec.rootNode) {
+			spec.verbatim = d.data
+		}
+	}
+
+	// Resolver todas as referências. O resolver mantém como $ref as referências que
+	// fecham um ciclo, em vez de expandi-las indefinidamente.
+	d.settings.tracker.enter("resolve", inputFile)
+	refs := 0
+	if root := rolodex.GetRootIndex(); root != nil {
+		refs += len(root.GetMappedReferences())
+	}
+	for _, idx := range rolodex.GetIndexes() {
+		refs += len(idx.GetMappedReferences())
+	}
+	endProgress := d.settings.progress.begin("resolve", reportPath(inputFile), refs)
+	err = runPhase(ctx, func() error {
+		rolodex.Resolve()
+		return nil
+	})
+	endProgress()
+	if err != nil {
+		return nil, err
+	}
+	for _, err := range rolodex.GetCaughtErrors() {
+		spec.report.addErrors(inputFile, err)
+	}
+	restoreKeptRefs(kept)
+	spec.report.RefsKept = len(kept)
+
+	// Contar as referências resolvidas por arquivo de origem, pela definição absoluta
+	// do destino: só entram as que o resolver substituiu, e não as mantidas como $ref
+	// (--keep-refs) nem as que fecham um ciclo
+	countResolved := func(file string, idx *index.SpecIndex) {
+		definitions := map[string]bool{}
+		for _, ref := range idx.GetRawReferencesSequenced() {
+			if ref.Node != nil && mappingValue(ref.Node, "$ref") == nil {
+				definitions[ref.FullDefinition] = true
+			}
+		}
+		if len(definitions) > 0 {
+			spec.report.RefsResolved[file] += len(definitions)
+		}
+	}
+	if root := rolodex.GetRootIndex(); root != nil {
+		countResolved(inputFile, root)
+	}
+	for _, idx := range rolodex.GetIndexes() {
+		countResolved(idx.GetSpecAbsolutePath(), idx)
+	}
+
+	return spec, nil
+}
+
+// Texto original dos escalares dobrados da especificação e dos arquivos locais
+// indexados pelo rolodex, lidos de novo só quando têm algum
+func (d *specDocument) foldedScalars() scalarSources {
+	sources := scalarSources{}
+	sources.collect(&d.spec.rootNode, func() ([]byte, bool) { return d.data, true })
+	root, _ := filepath.Abs(d.file)
+	for _, idx := range d.rolodex.GetIndexes() {
+		file := idx.GetSpecAbsolutePath()
+		if file == "" || file == root || strings.Contains(file, "://") {
+			continue
+		}
+		sources.collect(idx.GetRootNode(), func() ([]byte, bool) {
+			data, err := d.settings.readFile(file)
+			return data, err == nil
+		})
+	}
+	return sources
+}
+
+// Serializa o documento resolvido em w; em YAML, a entrada sem $refs é escrita como
+// está (veja verbatim)
+func (s *resolvedSpec) encode(w io.Writer, format string) error {
+	if format != formatYAML || s.verbatim == nil {
+		return encodeSpec(w, &s.rootNode, format, s.indent, s.scalars)
+	}
+	out := &lfWriter{w: w}
+	if _, err := out.Write(withoutBOM(s.verbatim)); err != nil {
+		return err
+	}
+	return out.flush()
+}
+
+// Como encode, em memória
+func (s *resolvedSpec) marshal(format string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := s.encode(&buf, format); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Tamanho da especificação (já convertida) somado ao dos arquivos locais indexados pelo
+// rolodex; os remotos, sem arquivo, não entram
+func (d *specDocument) sourceBytes() int64 {
+	total := int64(len(d.data))
+	root, _ := filepath.Abs(d.file)
+	for _, idx := range d.rolodex.GetIndexes() {
+		file := idx.GetSpecAbsolutePath()
+		if file == "" || file == root {
+			continue
+		}
+		if info, err := d.settings.statInput(file); err == nil && !info.IsDir() {
+			total += info.Size()
+		}
+	}
+	return total
+}
+
+// Documento resolvido pronto para ser gravado: já serializado em um arquivo
+// temporário no diretório do destino
+type resolvedOutput struct {
+	file     string
+	tmp      string
+	artifact *artifactInfo
+	spec     *resolvedSpec
+}
+
+// Remove o arquivo temporário de uma saída que não será gravada
+func (o *resolvedOutput) discard() {
+	if o.tmp != "" {
+		os.Remove(o.tmp)
+		o.tmp = ""
+	}
+}
+
+// Função para resolver as referências OpenAPI e salvar o YAML resolvido
+func resolveOpenAPI(ctx context.Context, inputFile, outputFile, outputFormat string) error {
+	out, err := prepareResolved(ctx, inputFile, outputFile, outputFormat)
+	if err != nil {
+		return err
+	}
+	return out.write()
+}
+
+// Função para resolver as referências e montar o documento de saída sem gravar nada,
+// para que todas as etapas terminem antes de qualquer arquivo ser escrito
+func prepareResolved(ctx context.Context, inputFile, outputFile, outputFormat string) (*resolvedOutput, error) {
+	if _, err := outputFormatFor(outputFile, outputFormat); err != nil {
+		return nil, err
+	}
+	doc, err := openSpecDocument(flagSettings(), inputFile)
+	if err != nil {
+		return nil, err
+	}
+	doc.exclusive = true
+	return prepareResolvedDocument(ctx, doc, outputFile, outputFormat)
+}
+
+// Como prepareResolved, para uma especificação já lida (e talvez já resolvida pela
+// validação); o documento resolvido é alterado no lugar (--max-depth, --strip)
+func prepareResolvedDocument(ctx context.Context, doc *specDocument, outputFile, outputFormat string) (*resolvedOutput, error) {
+	format, err := outputFormatFor(outputFile, outputFormat)
+	if err != nil {
+		return nil, err
+	}
+	inputFile := doc.file
+	spec, err := doc.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := reportCycles(spec.report.Cycles); err != nil {
+		return nil, err
+	}
+
+	// Limitar a profundidade de expansão dos $refs (--max-depth)
+	if maxRefDepth > 0 {
+		limitedRoot, limited := limitRefDepth(&spec.rootNode, spec.refNodes, maxRefDepth)
+		spec.rootNode = *limitedRoot
+		if limited > 0 {
+			fmt.Fprintf(stdout, "ℹ️  %d referências além da profundidade %d mantidas como $ref (--max-depth).\n", limited, maxRefDepth)
+		}
+	}
+
+	// Remover os nós indicados em --strip (artefato publicado para parceiros)
+	if len(stripTargets) > 0 {
+		result, err := stripNodes(&spec.rootNode, stripTargets)
+		if err != nil {
+			return nil, err
+		}
+		for _, warning := range result.Warnings {
+			fmt.Fprintln(stdout, "⚠️ ", warning)
+		}
+		fmt.Fprintln(stdout, "✂️  Nós removidos:", result)
+		spec.report.Stripped = result.Removed
+		if len(result.Removed) > 0 {
+			spec.verbatim = nil
+		}
+	}
+
+	// Abortar antes de serializar quando a estimativa já passa de --max-output-size
+	if maxOutputSize > 0 {
+		size, contributions := estimateOutputSize(&spec.rootNode, spec.refNodes)
+		if size > float64(maxOutputSize) {
+			return nil, outputSizeError(size, contributions)
+		}
+	}
+
+	// Serializar o documento resolvido (YAML ou JSON) direto em um arquivo temporário
+	// ao lado do destino, que só o substitui em write()
+	doc.settings.tracker.enter("serialize", inputFile)
+	tmp, artifact, err := encodeToTemp(outputFile, spec, format)
+	if err != nil {
+		return nil, err
+	}
+	out := &resolvedOutput{file: outputFile, tmp: tmp, artifact: artifact, spec: spec}
+	if maxOutputSize > 0 && int64(artifact.Bytes) > int64(maxOutputSize) {
+		_, contributions := estimateOutputSize(&spec.rootNode, spec.refNodes)
+		out.discard()
+		return nil, outputSizeError(float64(artifact.Bytes), contributions)
+	}
+
+	// Comparar o artefato com as fontes (--max-expansion-ratio)
+	spec.report.Expansion = newExpansionReport(spec, artifact.Bytes)
+	if spec.report.Expansion.exceeded() {
+		out.discard()
+		return nil, expansionRatioError(spec.report.Expansion)
+	}
+
+	// A árvore resolvida não é mais usada: liberá-la antes de ler o artefato de novo
+	spec.rootNode = yaml.Node{}
+	spec.refNodes = nil
+
+	// Conferir o artefato: deve ser lido de novo e não pode ser pior que a entrada.
+	// Com --force-output, o problema é apenas relatado e o arquivo é gravado.
+	doc.settings.tracker.enter("verify", inputFile)
+	if err := verifyArtifactFile(tmp, spec.inputProblems); err != nil {
+		if !forceOutput {
+			out.discard()
+			return nil, fmt.Errorf("%v; %s não foi gravado (use --force-output para gravá-lo mesmo assim)", err, outputFile)
+		}
+		fmt.Fprintln(stdout, "⚠️ ", err, "(gravado por --force-output)")
+	}
+	return out, nil
+}
+
+// Grava o documento resolvido e o relatório de uso das referências
+func (o *resolvedOutput) write() error {
+	if err := os.Rename(o.tmp, o.file); err != nil {
+		o.discard()
+		return fmt.Errorf("erro ao salvar arquivo resolvido: %v", err)
+	}
+	o.tmp = ""
+	fmt.Fprintln(stdout, "📄", o.artifact)
+	if maxExpansionRatio > 0 && o.spec.report.Expansion != nil {
+		fmt.Fprintln(stdout, "📈 Expansão:", o.spec.report.Expansion)
+	}
+	if o.spec.refUsage != nil {
+		if err := writeRefReport(refReportFile, o.spec.refUsage); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, "📄 Relatório de uso das referências salvo em:", refReportFile)
+	}
+
+	if o.spec.report.RefsKept > 0 {
+		total := 0
+		for _, n := range o.spec.report.RefsResolved {
+			total += n
+		}
+		fmt.Fprintf(stdout, "🔎 %d referências incorporadas, %d mantidas como $ref (--keep-refs).\n", total, o.spec.report.RefsKept)
+	}
+	fmt.Fprintln(stdout, "✅ Arquivo resolvido salvo em:", o.file)
+	return nil
+}
+
+// Imprime os ciclos de referência encontrados; com --fail-on-circular, eles impedem a resolução
+func reportCycles(cycles []referenceCycle) error {
+	for _, cycle := range cycles {
+		if failOnCircular {
+			fmt.Fprintln(stdout, "❌ Referência circular:", cycle)
+		} else {
+			fmt.Fprintln(stdout, "⚠️  Referência circular mantida como $ref:", cycle)
+		}
+	}
+	if failOnCircular && len(cycles) > 0 {
+		return fmt.Errorf("%d referências circulares encontradas (--fail-on-circular)", len(cycles))
+	}
+	return nil
+}
+
+// Função para obter a versão anterior da especificação a partir de uma ref do git. A
+// árvore da ref é extraída num diretório temporário, para que os $refs relativos da
+// versão anterior alcancem os arquivos da mesma ref, e o caminho retornado é o da
+// especificação dentro dele; cleanup remove o diretório. Retorna um caminho vazio
+// quando o arquivo não existe na ref (API nova).
+func fetchBaseSpec(baseRef, specFile string) (path string, cleanup func(), err error) {
+	out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("%s não está num repositório git: %v", specFile, err)
+	}
+	top := strings.TrimSpace(string(out))
+	abs, err := filepath.Abs(specFile)
+	if err == nil {
+		abs, err = filepath.EvalSymlinks(abs)
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("erro ao obter o caminho de %s: %v", specFile, err)
+	}
+	rel, err := filepath.Rel(top, abs)
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", nil, fmt.Errorf("%s está fora do repositório %s", specFile, top)
+	}
+	rel = filepath.ToSlash(rel)
+
+	// A ref precisa existir; o arquivo, não (o código de saída decide, não a mensagem do git)
+	if err := exec.Command("git", "-C", top, "rev-parse", "--verify", "--quiet", baseRef+"^{commit}").Run(); err != nil {
+		return "", nil, fmt.Errorf("ref %s não encontrada", baseRef)
+	}
+	if err := exec.Command("git", "-C", top, "cat-file", "-e", baseRef+":"+rel).Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return "", nil, nil
+		}
+		return "", nil, fmt.Errorf("erro ao procurar %s na ref %s: %v", specFile, baseRef, err)
+	}
+
+	dir, err := os.MkdirTemp("", "oldSwagger-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("erro ao criar diretório temporário: %v", err)
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+	if err := extractGitTree(top, baseRef, dir); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("erro ao extrair a ref %s: %v", baseRef, err)
+	}
+	return filepath.Join(dir, filepath.FromSlash(rel)), cleanup, nil
+}
+
+// Configuração da versão anterior extraída por fetchBaseSpec: --base-dir passa a
+// apontar para o diretório equivalente dentro da árvore da ref
+func baseSpecSettings(specFile, baseFile string) *runSettings {
+	s := flagSettings()
+	if s.baseDir == "" {
+		return s
+	}
+	specDir, err := filepath.Abs(filepath.Dir(specFile))
+	if err != nil {
+		return s
+	}
+	baseDir, err := filepath.Abs(s.baseDir)
+	if err != nil {
+		return s
+	}
+	if rel, err := filepath.Rel(specDir, baseDir); err == nil {
+		s.baseDir = filepath.Join(filepath.Dir(baseFile), rel)
+	}
+	return s
+}
+
+// Extrai os arquivos da ref (git archive) no diretório; links simbólicos são ignorados
+func extractGitTree(repo, ref, dir string) error {
+	cmd := exec.Command("git", "-C", repo, "archive", "--format=tar", ref)
+	var stderrBuf bytes.Buffer
+	cmd.Stderr = &stderrBuf
+	pipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	extractErr := extractTar(pipe, dir)
+	io.Copy(io.Discard, pipe)
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(stderrBuf.String()))
+	}
+	return extractErr
+}
+
+func extractTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.FromSlash(strings.TrimSuffix(header.Name, "/"))
+		if !filepath.IsLocal(name) {
+			continue
+		}
+		target := filepath.Join(dir, name)
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Grava os arquivos resolvidos mesmo com violações de severidade error ou
+// problemas no artefato (--force-output)
+var forceOutput bool
+
+func registerForceOutputFlag(fs *flag.FlagSet) {
+	fs.BoolVar(&forceOutput, "force-output", false, "grava os arquivos resolvidos mesmo quando a validação ou a conferência do artefato falham")
+}
+
+// Flags do comando raiz
+type rootOptions struct {
+	baseRef     string
+	outputDir   string
+	timeout     time.Duration
+	changedOnly bool
+}
+
+func (o *rootOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.baseRef, "base-ref", "", "ref do git de onde ler a versão anterior da especificação")
+	fs.StringVar(&o.outputDir, "output-dir", "", "diretório onde gravar oldSwaggerResolve.yaml e swaggerResolve.yaml (padrão: o diretório atual)")
+	fs.DurationVar(&o.timeout, "timeout", defaultTimeout, "tempo máximo da execução (0 desativa)")
+	fs.BoolVar(&o.changedOnly, "changed-only", false, "avalia as regras e relata as violações só nos paths e componentes que mudaram em relação à versão anterior")
+	registerEnvFlag(fs)
+	registerCacheFlags(fs)
+	registerStrictYAMLFlag(fs)
+	registerStrictFlag(fs)
+	registerExamplesFlag(fs)
+	registerComplexityFlags(fs)
+	registerDuplicatesFlag(fs)
+	registerInlineReuseFlag(fs)
+	registerExtensionFlags(fs)
+	registerPathConflictFlags(fs)
+	registerVersionFlags(fs)
+	registerConcurrencyFlag(fs)
+	registerProgressFlag(fs)
+	registerProfileFlag(fs)
+	registerRefFlags(fs)
+	registerHTTPFlags(fs)
+	registerEncodingFlag(fs)
+	registerSelectDocumentFlag(fs)
+	registerInputLimitFlag(fs)
+	registerOutputLimitFlags(fs)
+	registerProfileMemFlag(fs)
+	registerOverlayFlags(fs)
+	registerConvertFlag(fs)
+	registerForceOutputFlag(fs)
+	registerGitHubFlags(fs)
+	registerNotifyFlags(fs)
+}
+
+var rootCommand = &command{
+	Args: "[oldSwagger.yaml] swagger.yaml pb33f_rules.yaml",
+	Description: `Valida swagger.yaml com as regras de pb33f_rules.yaml e gera os arquivos
+resolvidos usados na comparação entre versões (oldSwaggerResolve.yaml e
+swaggerResolve.yaml, no diretório atual ou em --output-dir). Os arquivos só são
+gravados depois que a validação e a resolução das duas versões terminam, e não
+são gravados quando há violações de severidade error (exceto com
+--force-output). Com a versão anterior, as operações também não podem voltar no
+ciclo de vida de x-maturity (proposed → current → deprecated). Como em validate
+--rules, pb33f_rules.yaml pode ser o nome de um pacote embarcado (ex.: ofb).
+
+Com --changed-only, as regras só avaliam os paths, operações e componentes que
+mudaram em relação à versão anterior, e os componentes que eles referenciam;
+as violações fora dessas regiões não são relatadas. As duas versões são
+comparadas já resolvidas: a mudança num arquivo referenciado conta para as
+operações e componentes que o usam. O nível raiz (info,
+servers, tags, security) é conferido por inteiro e o resumo indica o escopo.
+
+Formas de uso:
+  com a versão anterior:  oldSwagger.yaml swagger.yaml pb33f_rules.yaml
+  API nova:               swagger.yaml pb33f_rules.yaml
+                          (com --base-ref, a versão anterior é lida do git)
+  sem argumentos:         usa spec, rules e baseRef de ` + projectConfigFile,
+	Examples: []string{
+		programName + " oldSwagger.yaml swagger.yaml pb33f_rules.yaml",
+		programName + " swagger.yaml pb33f_rules.yaml",
+		programName + " swagger.yaml ofb",
+		programName + " --base-ref origin/main swagger.yaml pb33f_rules.yaml",
+		programName + " --base-ref origin/main --changed-only swagger.yaml pb33f_rules.yaml",
+	},
+	Flags: func(fs *flag.FlagSet) { new(rootOptions).register(fs) },
+	Run:   runDefault,
+}
+
+// Main executa a linha de comando com os argumentos (sem o nome do programa) e
+// retorna o código de saída; um pânico vira erro interno (código 4) com o diagnóstico
+func Main(args []string) (code int) {
+	defer func() {
+		if r := recover(); r != nil {
+			code = reportPanic(r, args)
+		}
+	}()
+	defer memProfile.report()
+	if len(args) > 0 {
+		if args[0] == "help" {
+			return runHelp(args[1:])
+		}
+		if c := findCommand(args[0]); c != nil {
+			return c.Run(c, args[1:])
+		}
+	}
+	return rootCommand.Run(rootCommand, args)
+}
+
+// Run executa a linha de comando como Main, com as mensagens em out e errOut no lugar
+// de os.Stdout e os.Stderr. No fim, as flags e o estado da execução voltam ao que
+// eram, para que chamadas seguidas no mesmo processo (ex.: comparação da saída com
+// arquivos de referência) tenham o resultado de execuções separadas. As chamadas não
+// podem ser simultâneas.
+func Run(args []string, out, errOut io.Writer) int {
+	savedStdout, savedStderr := stdout, stderr
+	stdout, stderr = out, errOut
+	recordFlags = true
+	defer func() {
+		for i := len(flagRestores) - 1; i >= 0; i-- {
+			flagRestores[i]()
+		}
+		recordFlags, flagRestores = false, nil
+		stdout, stderr = savedStdout, savedStderr
+		tracker.reset()
+		cliEncodingWarned = &sync.Map{}
+	}()
+	return Main(args)
+}
+
+// Comando raiz: valida a especificação e gera os arquivos resolvidos para comparação
+func runDefault(c *command, args []string) int {
+	opts := &rootOptions{}
+	fs := c.newFlagSet()
+	opts.register(fs)
+	if code, ok := c.parse(fs, args); !ok {
+		return code
+	}
+	if err := checkOverlayFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkHTTPFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkEncodingFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkProgressFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkDuplicatesFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkInlineReuseFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkExtensionFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkPathConflictFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkVersionFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkConcurrencyFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkOutputLimitFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if opts.outputDir != "" {
+		if info, err := os.Stat(opts.outputDir); err != nil || !info.IsDir() {
+			return c.usageError("--output-dir %s não é um diretório existente", opts.outputDir)
+		}
+	}
+	if err := checkSelectDocumentFlag(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkGitHubFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+	if err := checkNotifyFlags(); err != nil {
+		return c.usageError("%v", err)
+	}
+
+	ctx, cancel := newRunContext(opts.timeout)
+	defer cancel()
+
+	var oldFile, newFile, rulesFile string
+	switch fs.NArg() {
+	case 3:
+		oldFile, newFile, rulesFile = fs.Arg(0), fs.Arg(1), fs.Arg(2)
+	case 2:
+		newFile, rulesFile = fs.Arg(0), fs.Arg(1)
+	case 0:
+		config, err := loadProjectConfig(projectConfigFile)
+		if err != nil {
+			fmt.Fprintln(stdout, "❌ Erro ao ler a configuração:", err)
+			return exitFailure
+		}
+		if config == nil || config.Spec == "" || config.Rules == "" {
+			return c.usageError("informe a especificação e o arquivo de regras, ou crie %s com '%s init'", projectConfigFile, programName)
+		}
+		newFile, rulesFile = config.Spec, config.Rules
+		if opts.baseRef == "" {
+			opts.baseRef = config.BaseRef
+		}
+	default:
+		return c.usageError("esperados 2 ou 3 argumentos, recebidos %d", fs.NArg())
+	}
+
+	if opts.changedOnly && oldFile == "" && opts.baseRef == "" {
+		return c.usageError("--changed-only exige a versão anterior (oldSwagger.yaml ou --base-ref)")
+	}
+	baseLabel := oldFile
+	oldSettings := flagSettings()
+	if oldFile == "" && opts.baseRef != "" {
+		baseLabel = opts.baseRef
+		baseFile, cleanup, err := fetchBaseSpec(opts.baseRef, newFile)
+		if err != nil {
+			fmt.Fprintln(stdout, "❌ Erro ao obter a versão anterior:", err)
+			return exitFailure
+		}
+		if baseFile != "" {
+			defer cleanup()
+			oldFile, oldSettings = baseFile, baseSpecSettings(newFile, baseFile)
+		} else {
+			fmt.Fprintf(stdout, "ℹ️  %s não existe na ref %s; seguindo sem comparação.\n", newFile, opts.baseRef)
+			if opts.changedOnly {
+				fmt.Fprintln(stdout, "ℹ️  Sem versão anterior, --changed-only não se aplica; a especificação será validada por inteiro.")
+			}
+		}
+	}
+
+	// Cada arquivo é lido e indexado uma única vez: a validação, a comparação do ciclo
+	// de vida e a resolução usam o mesmo documento
+	newDoc, err := openSpecDocument(flagSettings(), newFile)
+	if err != nil {
+		fmt.Fprintln(stdout, "❌ Erro ao validar", newFile+":", err)
+		notifyRun([]notificationSpec{notificationError(newFile, err.Error())})
+		return rulesExitCode(err)
+	}
+	var oldDoc *specDocument
+	if oldFile != "" {
+		if oldDoc, err = openSpecDocument(oldSettings, oldFile); err != nil {
+			fmt.Fprintln(stdout, "❌ Erro ao processar oldSwagger.yaml:", err)
+			return exitFailure
+		}
+		// A árvore original da versão anterior só é lida antes da resolução, exceto com
+		// --changed-only, que resolve as duas versões antes de validar
+		oldDoc.exclusive = !opts.changedOnly
+	}
+
+	// Com --changed-only, as regras e o relatório ficam nas regiões que mudaram
+	var scope *changedScope
+	if opts.changedOnly && oldDoc != nil {
+		oldSpec, err := oldDoc.resolve(ctx)
+		if err != nil {
+			return statsError(oldFile, err, opts.timeout)
+		}
+		newSpec, err := newDoc.resolve(ctx)
+		if err != nil {
+			return statsError(newFile, err, opts.timeout)
+		}
+		scope = computeChangedScope(&oldSpec.rootNode, &newSpec.rootNode, newDoc.root)
+		newDoc.settings.changedScope = scope
+	}
+
+	// Validar a especificação com as regras
+	violations, err := validateDocumentWithRules(ctx, newDoc, rulesFile)
+	if err != nil {
+		if isCancellation(err) {
+			reportCancellation(err, opts.timeout)
+			return exitFailure
+		}
+		fmt.Fprintln(stdout, "❌ Erro ao validar", newFile+":", err)
+		notifyRun([]notificationSpec{notificationError(newFile, err.Error())})
+		return rulesExitCode(err)
+	}
+	// Com a versão anterior, as operações não podem voltar no ciclo de vida (x-maturity)
+	maturity := maturitySummary(newDoc.root)
+	if oldDoc != nil {
+		violations = append(violations, maturityTransitionViolations(oldDoc.root, newDoc.root)...)
+	}
+	if scope != nil {
+		violations = scope.filter(violations)
+	}
+	failed := false
+	for _, v := range violations {
+		printViolation(v)
+		if v.IsError() {
+			failed = true
+		}
+	}
+	printMaturity(maturity)
+	if scope != nil {
+		fmt.Fprintln(stdout, scope.summary(baseLabel))
+	}
+	if githubComment {
+		commentOnPullRequest(validationMarkdown(githubCommentTitle, []*validationReport{newValidationReport(newFile, violations)}, nil))
+	}
+	notifyRun([]notificationSpec{notificationFor(newFile, violations)})
+
+	// Resolver os arquivos; nada é gravado antes que todas as etapas terminem
+	var outputs []*resolvedOutput
+	defer func() {
+		for _, out := range outputs {
+			out.discard()
+		}
+	}()
+	for _, target := range []struct {
+		doc           *specDocument
+		output, label string
+	}{
+		{oldDoc, "oldSwaggerResolve.yaml", "oldSwagger.yaml"},
+		{newDoc, "swaggerResolve.yaml", "swagger.yaml"},
+	} {
+		if target.doc == nil {
+			continue
+		}
+		out, err := prepareResolvedDocument(ctx, target.doc, filepath.Join(opts.outputDir, target.output), "")
+		if err != nil {
+			if isCancellation(err) {
+				reportCancellation(err, opts.timeout)
+				return exitFailure
+			}
+			fmt.Fprintln(stdout, "❌ Erro ao processar "+target.label+":", err)
+			return exitFailure
+		}
+		outputs = append(outputs, out)
+	}
+
+	if failed && !forceOutput {
+		fmt.Fprintln(stdout, "❌ A especificação possui violações de severidade error; os arquivos resolvidos não foram gravados (use --force-output para gravá-los mesmo assim).")
+		return exitFailure
+	}
+	for _, out := range outputs {
+		if err := out.write(); err != nil {
+			fmt.Fprintln(stdout, "❌", err)
+			return exitFailure
+		}
+	}
+
+	if failed {
+		fmt.Fprintln(stdout, "❌ A especificação possui violações de severidade error.")
+		return exitFailure
+	}
+
+	if oldFile == "" {
+		fmt.Fprintln(stdout, "🚀 OpenAPI validado e arquivo resolvido gerado com sucesso (sem versão anterior para comparação)!")
+		return exitOK
+	}
+	fmt.Fprintln(stdout, "🚀 OpenAPI validado e arquivos resolvidos gerados com sucesso!")
+	return exitOK
+}
@@ -0,0 +1,189 @@
+package validator
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Repositório git temporário como diretório atual; commit grava os arquivos e cria um commit
+func newGitRepo(t *testing.T) (commit func(files map[string]string)) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git não disponível")
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=teste", "-c", "user.email=teste@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	git("init", "-q")
+	return func(files map[string]string) {
+		t.Helper()
+		for name, content := range files {
+			if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		git("add", "-A")
+		git("commit", "-q", "--allow-empty", "-m", "commit")
+	}
+}
+
+func TestFetchBaseSpecResolvesRefsInBaseTree(t *testing.T) {
+	commit := newGitRepo(t)
+	commit(map[string]string{
+		"api/api.yaml":           refsSpec,
+		"api/schemas/conta.yaml": "Conta: {type: object, properties: {id: {type: string}}}\n",
+	})
+	// A árvore de trabalho muda depois do commit: a versão anterior não pode vê-la
+	if err := os.WriteFile("api/schemas/conta.yaml", []byte("Conta: {type: object, properties: {numero: {type: string}}}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, cleanup, err := fetchBaseSpec("HEAD", filepath.Join("api", "api.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	spec, err := indexAndResolve(context.Background(), flagSettings(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spec.report.Errors) > 0 {
+		t.Fatalf("erros ao resolver a versão anterior: %+v", spec.report.Errors)
+	}
+	out, err := yaml.Marshal(&spec.rootNode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "id:") || strings.Contains(string(out), "numero:") {
+		t.Errorf("a versão anterior deveria usar o schema da ref:\n%s", out)
+	}
+}
+
+// Arquivo que existe em HEAD mas não na ref base: API nova, sem comparação
+func TestFetchBaseSpecNewFile(t *testing.T) {
+	commit := newGitRepo(t)
+	commit(map[string]string{"README.md": "leia\n"})
+	commit(map[string]string{"api.yaml": refsSpec})
+
+	path, _, err := fetchBaseSpec("HEAD~1", "api.yaml")
+	if err != nil || path != "" {
+		t.Errorf("fetchBaseSpec = %q, %v; esperado caminho vazio sem erro", path, err)
+	}
+	if _, _, err := fetchBaseSpec("nao-existe", "api.yaml"); err == nil {
+		t.Error("uma ref inexistente deveria ser erro")
+	}
+}
+
+// Validação e resolução usam o mesmo documento: uma mudança no arquivo entre as duas
+// etapas não aparece no documento resolvido, e o índice é montado uma vez só
+func TestSharedSpecDocument(t *testing.T) {
+	spec := writeTemp(t, t.TempDir(), "api.yaml", mustReadFile(t, filepath.Join("testdata", "e2e", "valid", "api.yaml")))
+	s := flagSettings()
+	s.tracker = nil
+	s.noCache = true
+	rules, err := loadRuleset(s, "ofb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := openSpecDocument(s, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := validateDocument(context.Background(), doc, rules); err != nil {
+		t.Fatal(err)
+	}
+	indexed := doc.spec
+	if indexed == nil {
+		t.Fatal("a validação não deixou o índice no documento")
+	}
+	if err := os.WriteFile(spec, []byte("openapi: 3.0.3\ninfo: {title: Outra, version: 2.0.0}\npaths: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := doc.resolve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved != indexed {
+		t.Error("a resolução montou outro índice")
+	}
+	data, err := resolved.marshal(formatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "Outra") {
+		t.Errorf("a resolução leu o arquivo alterado depois da validação:\n%s", data)
+	}
+	// A árvore das regras continua com os $refs: a resolução trabalha em uma cópia
+	var refs int
+	var walk func(*yaml.Node)
+	walk = func(n *yaml.Node) {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Kind == yaml.MappingNode && n.Content[i].Value == "$ref" {
+				refs++
+			}
+		}
+		for _, child := range n.Content {
+			walk(child)
+		}
+	}
+	walk(doc.root)
+	if refs == 0 {
+		t.Error("a resolução alterou a árvore usada pelas regras")
+	}
+}
+
+// Validação seguida da resolução de uma especificação grande: com o documento e o
+// índice compartilhados, e lendo e indexando o arquivo de novo para resolver, como
+// antes (go test -bench SharedSpecDocument -benchmem)
+func BenchmarkSharedSpecDocument(b *testing.B) {
+	spec := filepath.Join(b.TempDir(), "api.yaml")
+	if err := os.WriteFile(spec, generateLargeSpec(300), 0o644); err != nil {
+		b.Fatal(err)
+	}
+	s := flagSettings()
+	s.tracker = nil
+	s.noCache = true
+	s.ruleConcurrency = 1
+	rules, err := loadRuleset(s, "ofb")
+	if err != nil {
+		b.Fatal(err)
+	}
+	ctx := context.Background()
+	run := func(b *testing.B, shared bool) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			doc, err := openSpecDocument(s, spec)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, _, err := validateDocument(ctx, doc, rules); err != nil {
+				b.Fatal(err)
+			}
+			if !shared {
+				if doc, err = openSpecDocument(s, spec); err != nil {
+					b.Fatal(err)
+				}
+			}
+			if _, err := doc.resolve(ctx); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.Run("compartilhado", func(b *testing.B) { run(b, true) })
+	b.Run("separado", func(b *testing.B) { run(b, false) })
+}
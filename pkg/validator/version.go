@@ -0,0 +1,126 @@
+package validator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Versões do OpenAPI entendidas pelo validador
+const (
+	openAPI30 = "3.0"
+	openAPI31 = "3.1"
+)
+
+// Função para identificar a versão do documento: "2.0", "3.0" ou "3.1". Retorna
+// erro para versões desconhecidas (ex.: 3.2), que seriam validadas com a semântica errada.
+// Documentos sem o campo openapi/swagger retornam a versão vazia.
+func specVersion(rootNode *yaml.Node) (string, error) {
+	doc := rootNode
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	if swagger := mappingValue(doc, "swagger"); swagger != nil {
+		if swagger.Value == "2.0" {
+			return "2.0", nil
+		}
+		return "", fmt.Errorf("versão swagger %q não suportada (suportadas: 2.0, 3.0.x e 3.1.x), linha %d", swagger.Value, swagger.Line)
+	}
+	version := mappingValue(doc, "openapi")
+	if version == nil {
+		return "", nil
+	}
+	for _, known := range []string{openAPI30, openAPI31} {
+		if version.Value == known || strings.HasPrefix(version.Value, known+".") {
+			return known, nil
+		}
+	}
+	return "", fmt.Errorf("versão OpenAPI %q não suportada (suportadas: 2.0, 3.0.x e 3.1.x), linha %d", version.Value, version.Line)
+}
+
+// Indica se o documento é OpenAPI 3.1
+func isOpenAPI31(rootNode *yaml.Node) bool {
+	version, _ := specVersion(rootNode)
+	return version == openAPI31
+}
+
+// Função para obter os tipos de um schema e se ele aceita null, conforme a versão:
+// em 3.0, type é uma string e a nulabilidade vem de nullable: true; em 3.1, type
+// pode ser uma lista e a nulabilidade é o tipo "null"
+func schemaTypes(schema *yaml.Node, v31 bool) ([]string, bool) {
+	t := mappingValue(schema, "type")
+	var types []string
+	nullable := false
+	switch {
+	case t == nil:
+	case t.Kind == yaml.SequenceNode && v31:
+		for _, item := range t.Content {
+			if item.Value == "null" {
+				nullable = true
+				continue
+			}
+			types = append(types, item.Value)
+		}
+	case t.Kind == yaml.ScalarNode:
+		if v31 && t.Value == "null" {
+			nullable = true
+		} else {
+			types = append(types, t.Value)
+		}
+	}
+	if !v31 {
+		if n := mappingValue(schema, "nullable"); n != nil && n.Value == "true" {
+			nullable = true
+		}
+	}
+	return types, nullable
+}
+
+// Função para conferir se o conteúdo parece um documento OpenAPI antes de aplicar as
+// regras: sem isso, um manifesto do Kubernetes ou um arquivo vazio passaria como
+// válido porque nenhuma regra encontra o que procurar
+func checkOpenAPIDocument(data []byte, rootNode *yaml.Node) error {
+	if len(bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))) == 0 {
+		if len(data) == 0 {
+			return fmt.Errorf("o arquivo está vazio")
+		}
+		return fmt.Errorf("o arquivo contém apenas espaços em branco")
+	}
+	doc := rootNode
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	switch doc.Kind {
+	case 0, yaml.DocumentNode:
+		return fmt.Errorf("o arquivo contém apenas comentários")
+	case yaml.SequenceNode:
+		return fmt.Errorf("isto não parece um documento OpenAPI: a raiz é uma lista com %d itens, esperado um objeto com openapi, info e paths", len(doc.Content))
+	case yaml.ScalarNode, yaml.AliasNode:
+		return fmt.Errorf("isto não parece um documento OpenAPI: a raiz é o valor %q, esperado um objeto com openapi, info e paths", doc.Value)
+	}
+
+	var missing []string
+	if mappingValue(doc, "openapi") == nil && mappingValue(doc, "swagger") == nil {
+		missing = append(missing, "o campo openapi (ou swagger)")
+	}
+	if mappingValue(doc, "paths") == nil && mappingValue(doc, "webhooks") == nil && mappingValue(doc, "components") == nil {
+		missing = append(missing, "uma seção paths, webhooks ou components")
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	var keys []string
+	for i := 0; i+1 < len(doc.Content) && len(keys) < 10; i += 2 {
+		keys = append(keys, doc.Content[i].Value)
+	}
+	found := "nenhuma chave"
+	if len(keys) > 0 {
+		found = "as chaves " + strings.Join(keys, ", ")
+		if len(doc.Content)/2 > len(keys) {
+			found += ", ..."
+		}
+	}
+	return fmt.Errorf("isto não parece um documento OpenAPI: falta %s; a raiz contém %s", strings.Join(missing, " e "), found)
+}
@@ -0,0 +1,61 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Violation é um problema encontrado na especificação, por uma regra do conjunto ou
+// por uma das conferências embutidas. Error() mantém o texto das versões anteriores,
+// em que as violações eram erros: "[severidade] mensagem (caminho, linha N)".
+type Violation struct {
+	RuleID     string `json:"ruleId,omitempty"`
+	Severity   string `json:"severity"` // error, warning, info ou hint
+	Message    string `json:"message"`
+	File       string `json:"file,omitempty"`
+	JSONPath   string `json:"path,omitempty"`
+	Line       int    `json:"line,omitempty"`
+	Column     int    `json:"column,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"` // correção sugerida (ex.: o nome existente mais próximo)
+}
+
+func (v Violation) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] ", v.Severity)
+	if v.File != "" {
+		// Com o arquivo, a posição vem antes da mensagem (arquivo:linha:coluna:) e o
+		// JSONPath fica apenas no campo
+		b.WriteString(v.File)
+		if v.Line > 0 {
+			fmt.Fprintf(&b, ":%d:%d", v.Line, v.Column)
+		}
+		b.WriteString(": ")
+	}
+	b.WriteString(v.Message)
+	switch {
+	case v.File != "" || v.JSONPath == "":
+	case v.Line > 0:
+		fmt.Fprintf(&b, " (%s, linha %d)", v.JSONPath, v.Line)
+	default:
+		fmt.Fprintf(&b, " (%s)", v.JSONPath)
+	}
+	return b.String()
+}
+
+// Imprime a violação no console, com a correção sugerida na linha de baixo
+func printViolation(v Violation) {
+	fmt.Fprintln(stdout, "⚠️ ", v)
+	if v.Suggestion != "" {
+		fmt.Fprintln(stdout, "   💡 Sugestão:", v.Suggestion)
+	}
+}
+
+// IsError indica se a violação tem severidade error
+func (v Violation) IsError() bool {
+	return v.Severity == "error"
+}
+
+// Violação de uma referência que o índice não conseguiu resolver
+func referenceViolation(e resolutionError) Violation {
+	return Violation{RuleID: "ref-resolution", Severity: "error", Message: e.Message, File: e.File, JSONPath: e.Path, Line: e.Line, Column: e.Column, Suggestion: e.Suggestion}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	_ "embed"
+	"os"
+
+	"github.com/OpenBanking-Brasil/OFB-CI-CD/pkg/validator"
+)
+
+// Pacote de regras do Open Finance Brasil embarcado no binário
+//
+//go:embed pb33f_rules.yaml
+var ofbRules []byte
+
+// Regras de ordenação opcionais (extends: [ofb, ofb-ordering])
+//
+//go:embed ofb_ordering.yaml
+var ofbOrderingRules []byte
+
+// Registro das extensões do Open Finance Brasil embarcado no binário
+//
+//go:embed ofb_extensions.yaml
+var ofbExtensions []byte
+
+func main() {
+	validator.RegisterRuleset("ofb", ofbRules)
+	validator.RegisterRuleset("ofb-ordering", ofbOrderingRules)
+	validator.RegisterExtensions("ofb", ofbExtensions)
+	os.Exit(validator.Main(os.Args[1:]))
+}